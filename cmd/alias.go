@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"delguard/internal/installer"
+
+	"github.com/spf13/cobra"
+)
+
+// aliasCmd 检查和修复install写入的命令别名
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "检查和修复rm、del等命令别名",
+	Long: `检查和修复 delguard install 写入的命令别名。
+
+示例:
+  delguard alias status          # 列出各shell的别名状态
+  delguard alias status --json   # 以JSON格式输出，供安装脚本检查
+  delguard alias repair          # 可执行文件移动后，将失效的别名指向当前程序`,
+}
+
+// aliasStatusCmd 列出各shell的别名状态
+var aliasStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "列出各shell配置文件和别名脚本中DelGuard别名的状态",
+	Long: `列出系统中找到的各shell的配置文件和别名脚本，报告其中是否有DelGuard配置块、
+调用的DelGuard路径、该路径是否存在以及是否为当前运行的程序；PowerShell配置文件还会检查能否解析。
+有已安装的别名失效时以非零状态退出。`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runAliasStatus,
+}
+
+// aliasRepairCmd 将失效的别名重新指向可执行文件
+var aliasRepairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "将指向已不存在的可执行文件的别名重新指向当前程序",
+	Long: `在原配置块和别名脚本中替换失效的DelGuard路径，不会重复写入配置块。
+默认指向当前运行的程序，可用 --target 指定其他路径。
+配置块缺少结束标记或重复时无法自动修复，需要手动修正后重新运行 delguard install --force。`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runAliasRepair,
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasStatusCmd)
+	aliasCmd.AddCommand(aliasRepairCmd)
+
+	aliasStatusCmd.Flags().Bool("json", false, "以JSON格式输出")
+	aliasRepairCmd.Flags().String("target", "", "别名指向的DelGuard可执行文件，默认为当前运行的程序")
+}
+
+func runAliasStatus(cmd *cobra.Command, args []string) error {
+	systemInstaller, err := installer.GetSystemInstaller()
+	if err != nil {
+		return fmt.Errorf("获取系统安装器失败: %v", err)
+	}
+	statuses := systemInstaller.Aliases()
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		if err := printJSON(statuses); err != nil {
+			return err
+		}
+	} else {
+		printAliasStatuses(statuses)
+	}
+	if broken := countBrokenAliases(statuses); broken > 0 {
+		return fmt.Errorf("%d 处别名已失效，运行 delguard alias repair 修复", broken)
+	}
+	return nil
+}
+
+// printAliasStatuses 逐个显示别名安装位置的状态
+func printAliasStatuses(statuses []installer.AliasStatus) {
+	if len(statuses) == 0 {
+		fmt.Println("ℹ️  未找到shell配置文件或别名脚本")
+		return
+	}
+	for _, status := range statuses {
+		icon := "✅"
+		switch {
+		case status.Broken():
+			icon = "❌"
+		case !status.Installed:
+			icon = "➖"
+		}
+		fmt.Printf("%s %-10s %s\n", icon, status.Shell, status.Path)
+		if !status.Installed {
+			fmt.Println("   配置块: 未安装")
+			continue
+		}
+		if status.Shell != "script" {
+			fmt.Println("   配置块: 已安装")
+		}
+		if status.Problem != "" {
+			fmt.Printf("   问题: %s\n", status.Problem)
+		}
+		if status.Target != "" {
+			switch {
+			case !status.TargetExists:
+				fmt.Printf("   目标: %s (不存在)\n", status.Target)
+			case status.Current:
+				fmt.Printf("   目标: %s (当前程序)\n", status.Target)
+			default:
+				fmt.Printf("   目标: %s (存在，与当前程序不同)\n", status.Target)
+			}
+		}
+		if status.ParseError != "" {
+			fmt.Printf("   解析错误: %s\n", status.ParseError)
+		}
+	}
+}
+
+// countBrokenAliases 统计已安装但失效的别名
+func countBrokenAliases(statuses []installer.AliasStatus) int {
+	broken := 0
+	for _, status := range statuses {
+		if status.Broken() {
+			broken++
+		}
+	}
+	return broken
+}
+
+func runAliasRepair(cmd *cobra.Command, args []string) error {
+	systemInstaller, err := installer.GetSystemInstaller()
+	if err != nil {
+		return fmt.Errorf("获取系统安装器失败: %v", err)
+	}
+
+	target, _ := cmd.Flags().GetString("target")
+	if target == "" {
+		if target, err = os.Executable(); err != nil {
+			return fmt.Errorf("获取当前可执行文件路径失败: %v", err)
+		}
+	}
+	if target, err = filepath.Abs(target); err != nil {
+		return fmt.Errorf("获取目标路径失败: %v", err)
+	}
+	if info, err := os.Stat(target); err != nil || info.IsDir() {
+		return fmt.Errorf("目标 %s 不是可执行文件", target)
+	}
+
+	repairs := systemInstaller.RepairAliases(target)
+	if len(repairs) == 0 {
+		fmt.Println("✅ 没有需要重新指向的别名")
+	}
+	for _, repair := range repairs {
+		if repair.Error != "" {
+			fmt.Printf("❌ %s: %s\n", repair.Path, repair.Error)
+			continue
+		}
+		fmt.Printf("🔧 %s: %s -> %s\n", repair.Path, repair.From, repair.To)
+	}
+
+	statuses := systemInstaller.Aliases()
+	if broken := countBrokenAliases(statuses); broken > 0 {
+		fmt.Println()
+		printAliasStatuses(statuses)
+		return fmt.Errorf("修复后仍有 %d 处别名失效", broken)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// auditCmd 导出删除回执中的操作记录
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "导出删除操作的审计记录",
+	Long: `逐个读取删除回执，按时间、操作和路径筛选后导出每个项目的处理记录，用于安全审查。
+记录来自 logging.report_enabled 启用后写入的回执，回执按 logging.max_age 清理。
+--since 支持天数（如 7d）、Go时长（如 12h）或日期（如 2024-01-31），为空时导出全部。
+--path 为通配符模式，包含路径分隔符时匹配完整路径，否则只匹配文件名。
+
+示例:
+  delguard audit --since 30d --op delete > audit.csv
+  delguard audit --since 2024-01-01 --path '*.key' --format json`,
+	RunE: runAudit,
+}
+
+// auditOperations 回执中可能出现的操作类型
+var auditOperations = []string{"delete", "shred", "purge", "prune"}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().String("since", "", "只导出此时间之后的操作")
+	auditCmd.Flags().String("op", "", "只导出指定操作: delete, shred, purge, prune")
+	auditCmd.Flags().String("path", "", "只导出路径匹配此通配符的项目")
+	auditCmd.Flags().String("format", "csv", "输出格式: csv, json")
+}
+
+// auditFilter 审计记录的筛选条件
+type auditFilter struct {
+	Operation string
+	Pattern   string
+}
+
+// match 判断记录是否符合筛选条件
+func (f auditFilter) match(record report.Record) bool {
+	if f.Operation != "" && record.Operation != f.Operation {
+		return false
+	}
+	if f.Pattern == "" {
+		return true
+	}
+	target := filepath.Base(record.Path)
+	if strings.ContainsAny(f.Pattern, `/\`) {
+		target = record.Path
+	}
+	matched, _ := filepath.Match(f.Pattern, target)
+	return matched
+}
+
+// auditWriter 逐条输出审计记录
+type auditWriter interface {
+	Write(record report.Record) error
+	Close() error
+}
+
+// csvAuditWriter 以CSV格式输出，第一行为列名
+type csvAuditWriter struct {
+	w *csv.Writer
+}
+
+func newCSVAuditWriter(out io.Writer) (*csvAuditWriter, error) {
+	w := csv.NewWriter(out)
+	if err := w.Write(report.RecordHeader); err != nil {
+		return nil, err
+	}
+	return &csvAuditWriter{w: w}, nil
+}
+
+func (c *csvAuditWriter) Write(record report.Record) error {
+	return c.w.Write(record.Fields())
+}
+
+func (c *csvAuditWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonAuditWriter 以JSON数组格式输出，每条记录写出后不再保留
+type jsonAuditWriter struct {
+	out   io.Writer
+	count int
+}
+
+func (j *jsonAuditWriter) Write(record report.Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "[\n  "
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.out, "%s%s", sep, data)
+	return err
+}
+
+func (j *jsonAuditWriter) Close() error {
+	if j.count == 0 {
+		_, err := fmt.Fprintln(j.out, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(j.out, "\n]")
+	return err
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	sinceValue, _ := cmd.Flags().GetString("since")
+	operation, _ := cmd.Flags().GetString("op")
+	pattern, _ := cmd.Flags().GetString("path")
+	format, _ := cmd.Flags().GetString("format")
+
+	var since time.Time
+	if sinceValue != "" {
+		var err error
+		if since, err = parseSince(sinceValue, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	filter := auditFilter{Operation: strings.ToLower(operation), Pattern: pattern}
+	if filter.Operation != "" && !slices.Contains(auditOperations, filter.Operation) {
+		return fmt.Errorf("未知的操作 %q，可选值: %s", operation, strings.Join(auditOperations, ", "))
+	}
+	if pattern != "" {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("无效的路径模式 %q: %v", pattern, err)
+		}
+	}
+
+	var writer auditWriter
+	switch strings.ToLower(format) {
+	case "csv":
+		w, err := newCSVAuditWriter(os.Stdout)
+		if err != nil {
+			return err
+		}
+		writer = w
+	case "json":
+		writer = &jsonAuditWriter{out: os.Stdout}
+	default:
+		return fmt.Errorf("不支持的输出格式 %q，可选值: csv, json", format)
+	}
+
+	matched := 0
+	err := report.Each(config.GetReportDir(), since, func(receipt *report.Receipt) error {
+		for _, record := range receipt.Records() {
+			if !filter.match(record) {
+				continue
+			}
+			matched++
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("写入审计记录失败: %v", err)
+			}
+		}
+		return nil
+	})
+	if closeErr := writer.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("写入审计记录失败: %v", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		fmt.Fprintln(os.Stderr, "📭 没有符合条件的审计记录")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/report"
+)
+
+// captureStdout 返回fn执行期间写入标准输出的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		output <- buf.String()
+	}()
+	defer func() {
+		os.Stdout = saved
+	}()
+	fn()
+	w.Close()
+	return <-output
+}
+
+// writeAuditFixture 在回执目录中写入两份回执：10天前的删除和1天前的粉碎
+func writeAuditFixture(t *testing.T) {
+	t.Helper()
+	dir := config.GetReportDir()
+	now := time.Now()
+	fixtures := []*report.Receipt{
+		{ID: "del00001", Operation: "delete", User: "alice", StartedAt: now.AddDate(0, 0, -10), Items: []report.Item{
+			{Path: "/home/alice/id_rsa.key", Size: 1679, Outcome: report.OutcomeTrashed, TrashPath: "/trash/id_rsa.key"},
+			{Path: "/home/alice/notes.txt", Size: 10, Outcome: report.OutcomeFailed, Reason: "权限不足"},
+		}},
+		{ID: "shr00001", Operation: "shred", User: "alice", StartedAt: now.AddDate(0, 0, -1), Items: []report.Item{
+			{Path: "/srv/certs/server.key", Size: 3243, Outcome: report.OutcomeShredded},
+		}},
+	}
+	for _, receipt := range fixtures {
+		receipt.Finish()
+		if _, err := receipt.Write(dir); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+}
+
+// runAuditWith 以指定标志运行audit命令，返回标准输出
+func runAuditWith(t *testing.T, flags map[string]string) string {
+	t.Helper()
+	for name, value := range flags {
+		if err := auditCmd.Flags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer resetAuditFlags(flags)
+	var runErr error
+	output := captureStdout(t, func() { runErr = runAudit(auditCmd, nil) })
+	if runErr != nil {
+		t.Fatalf("runAudit(%v): %v", flags, runErr)
+	}
+	return output
+}
+
+// resetAuditFlags 将flags中的标志恢复为默认值
+func resetAuditFlags(flags map[string]string) {
+	for name := range flags {
+		flag := auditCmd.Flags().Lookup(name)
+		flag.Value.Set(flag.DefValue)
+		flag.Changed = false
+	}
+}
+
+func TestAuditFilterMatch(t *testing.T) {
+	record := report.Record{Operation: "delete", Path: "/home/alice/id_rsa.key"}
+	tests := []struct {
+		name   string
+		filter auditFilter
+		want   bool
+	}{
+		{"no filter", auditFilter{}, true},
+		{"operation", auditFilter{Operation: "delete"}, true},
+		{"other operation", auditFilter{Operation: "shred"}, false},
+		{"base name glob", auditFilter{Pattern: "*.key"}, true},
+		{"base name miss", auditFilter{Pattern: "*.txt"}, false},
+		{"full path glob", auditFilter{Pattern: "/home/*/id_rsa.key"}, true},
+		{"full path needs the whole path", auditFilter{Pattern: "/home/*.key"}, false},
+		{"both", auditFilter{Operation: "delete", Pattern: "id_*"}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.match(record); got != tt.want {
+			t.Errorf("%s: match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuditExportsFilteredCSV(t *testing.T) {
+	initTempConfig(t)
+	t.Setenv("XDG_STATE_HOME", "")
+	writeAuditFixture(t)
+
+	rows, err := csv.NewReader(bytes.NewBufferString(runAuditWith(t, map[string]string{"path": "*.key"}))).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("CSV has %d rows, want the header and two .key records: %q", len(rows), rows)
+	}
+	for i, column := range report.RecordHeader {
+		if rows[0][i] != column {
+			t.Errorf("header column %d = %q, want %q", i, rows[0][i], column)
+		}
+	}
+	if rows[1][5] != "/home/alice/id_rsa.key" || rows[2][5] != "/srv/certs/server.key" {
+		t.Errorf("paths = %q, %q; want oldest first", rows[1][5], rows[2][5])
+	}
+
+	recent, err := csv.NewReader(bytes.NewBufferString(runAuditWith(t, map[string]string{"since": "3d"}))).ReadAll()
+	if err != nil || len(recent) != 2 || recent[1][2] != "shred" {
+		t.Errorf("--since 3d exported %q, %v; want only the shred", recent, err)
+	}
+}
+
+func TestAuditExportsJSONRecords(t *testing.T) {
+	initTempConfig(t)
+	t.Setenv("XDG_STATE_HOME", "")
+	writeAuditFixture(t)
+
+	var records []report.Record
+	output := runAuditWith(t, map[string]string{"op": "delete", "format": "json"})
+	if err := json.Unmarshal([]byte(output), &records); err != nil {
+		t.Fatalf("parse JSON: %v\n%s", err, output)
+	}
+	if len(records) != 2 {
+		t.Fatalf("exported %d records, want both delete items", len(records))
+	}
+	failed := records[1]
+	if failed.OperationID != "del00001" || failed.Outcome != report.OutcomeFailed || failed.Reason != "权限不足" || failed.User != "alice" {
+		t.Errorf("record = %+v", failed)
+	}
+
+	var empty []report.Record
+	output = runAuditWith(t, map[string]string{"op": "purge", "format": "json"})
+	if err := json.Unmarshal([]byte(output), &empty); err != nil || len(empty) != 0 {
+		t.Errorf("empty export = %q, %v; want []", output, err)
+	}
+}
+
+func TestAuditRejectsInvalidFlags(t *testing.T) {
+	initTempConfig(t)
+	for _, flags := range []map[string]string{{"op": "restore"}, {"format": "xml"}, {"path": "["}, {"since": "yesterday"}} {
+		for name, value := range flags {
+			auditCmd.Flags().Set(name, value)
+		}
+		if err := runAudit(auditCmd, nil); err == nil {
+			t.Errorf("runAudit(%v) succeeded", flags)
+		}
+		resetAuditFlags(flags)
+	}
+}
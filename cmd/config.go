@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/i18n"
 
 	"github.com/spf13/cobra"
 )
@@ -17,8 +23,62 @@ var configCmd = &cobra.Command{
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "显示当前配置",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		effective, _ := cmd.Flags().GetBool("effective")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if effective || asJSON {
+			return showEffectiveConfig(asJSON)
+		}
 		showConfig()
+		return nil
+	},
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "显示与默认值不同的配置项",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		return showConfigDiff(asJSON)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "校验配置文件",
+	Long: `校验配置文件并按严重级别输出问题报告。
+
+只有存在错误级别的问题时才以非零状态退出，警告和提示不影响退出状态，
+便于在CI中使用。未指定路径时校验当前使用的配置文件。`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return validateConfig(path, asJSON)
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [path]",
+	Short: "升级配置文件到当前版本",
+	Long: `将旧版本的配置文件逐步升级到当前的结构版本。
+
+使用 --check 只显示将要发生的变更，不写入文件。
+未指定路径时升级当前使用的配置文件。`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, _ := cmd.Flags().GetBool("check")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		path := config.ConfigFileUsed()
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return migrateConfig(path, check, asJSON)
 	},
 }
 
@@ -35,15 +95,25 @@ func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+
+	configShowCmd.Flags().Bool("effective", false, "显示所有配置项的生效值及来源")
+	configShowCmd.Flags().Bool("json", false, "以JSON格式输出")
+	configDiffCmd.Flags().Bool("json", false, "以JSON格式输出")
+	configValidateCmd.Flags().Bool("json", false, "以JSON格式输出")
+	configMigrateCmd.Flags().Bool("check", false, "只显示将要发生的变更，不写入文件")
+	configMigrateCmd.Flags().Bool("json", false, "以JSON格式输出")
 }
 
 func showConfig() {
-	if config.GlobalConfig == nil {
+	cfg := config.Current()
+	if cfg == nil {
 		fmt.Println("❌ 配置未初始化")
 		return
 	}
 
-	cfg := config.GlobalConfig
 	fmt.Println("📋 DelGuard 当前配置:")
 	fmt.Println()
 	fmt.Println("🗑️  回收站设置:")
@@ -62,35 +132,209 @@ func showConfig() {
 	fmt.Printf("   彩色输出: %v\n", cfg.UI.Color)
 }
 
+// showEffectiveConfig 显示每个配置项的生效值和来源
+func showEffectiveConfig(asJSON bool) error {
+	settings := config.EffectiveSettings()
+	if asJSON {
+		return printJSON(settings)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "配置项\t值\t来源")
+	fmt.Fprintln(w, "------\t--\t----")
+	for _, setting := range settings {
+		fmt.Fprintf(w, "%s\t%v\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return w.Flush()
+}
+
+// showConfigDiff 显示被覆盖的配置项
+func showConfigDiff(asJSON bool) error {
+	diffs := config.DiffFromDefaults()
+	if asJSON {
+		if diffs == nil {
+			diffs = []config.SettingDiff{}
+		}
+		return printJSON(diffs)
+	}
+
+	if len(diffs) == 0 {
+		fmt.Println("✅ 所有配置项均为默认值")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "配置项\t默认值\t当前值\t来源")
+	fmt.Fprintln(w, "------\t------\t------\t----")
+	for _, diff := range diffs {
+		def := "-"
+		if diff.Default != nil {
+			def = fmt.Sprint(diff.Default)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", diff.Key, def, diff.Value, diff.Source)
+	}
+	return w.Flush()
+}
+
+// validateConfig 校验配置并输出报告，存在错误时返回配置错误
+func validateConfig(path string, asJSON bool) error {
+	if path == "" {
+		path = config.ConfigFileUsed()
+	}
+
+	var result *config.ValidationResult
+	if path != "" {
+		var err error
+		result, err = config.ValidateFile(path)
+		if err != nil {
+			return errors.NewConfigError(path, err)
+		}
+	} else if cfg := config.Current(); cfg != nil {
+		result = cfg.Validate()
+	} else {
+		return errors.NewConfigError("配置未初始化", nil)
+	}
+
+	if asJSON {
+		if result.Issues == nil {
+			result.Issues = []config.ValidationIssue{}
+		}
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		showValidationReport(result)
+	}
+
+	if result.HasErrors() {
+		return errors.NewConfigError(i18n.Plural("config.errors_found", result.Count(config.LevelError)), nil)
+	}
+	return nil
+}
+
+// showValidationReport 按严重级别输出校验报告
+func showValidationReport(result *config.ValidationResult) {
+	if result.File != "" {
+		fmt.Printf("📋 配置文件: %s\n", result.File)
+	}
+
+	if len(result.Issues) == 0 {
+		fmt.Println("✅ 配置校验通过，未发现问题")
+		return
+	}
+
+	levels := []struct {
+		level config.ValidationLevel
+		title string
+		icon  string
+	}{
+		{config.LevelError, "错误", "❌"},
+		{config.LevelWarning, "警告", "⚠️ "},
+		{config.LevelInfo, "提示", "ℹ️ "},
+	}
+
+	for _, l := range levels {
+		if result.Count(l.level) == 0 {
+			continue
+		}
+		fmt.Println()
+		fmt.Printf("%s:\n", l.title)
+		for _, issue := range result.Issues {
+			if issue.Level == l.level {
+				fmt.Printf("  %s %s: %s\n", l.icon, issue.Key, issue.Message)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 %s, %s, %s\n",
+		i18n.Plural("count.errors", result.Count(config.LevelError)),
+		i18n.Plural("count.warnings", result.Count(config.LevelWarning)),
+		i18n.Plural("count.hints", result.Count(config.LevelInfo)))
+}
+
+// migrateConfig 升级配置文件并输出每一步的变更
+func migrateConfig(path string, check, asJSON bool) error {
+	if path == "" {
+		return errors.NewConfigError("未找到配置文件", nil)
+	}
+
+	report, err := config.MigrateFile(path, check)
+	if err != nil {
+		return errors.NewConfigError(path, err)
+	}
+
+	if asJSON {
+		if report.Steps == nil {
+			report.Steps = []config.MigrationStep{}
+		}
+		return printJSON(report)
+	}
+
+	fmt.Printf("📋 配置文件: %s\n", path)
+	if !report.Changed() {
+		fmt.Printf("✅ 配置文件已是最新版本 (%s)\n", report.ToVersion)
+		return nil
+	}
+
+	for _, step := range report.Steps {
+		fmt.Printf("🔄 %s → %s: %s\n", step.From, step.To, step.Description)
+		for _, change := range step.Changes {
+			fmt.Printf("   - %s\n", change)
+		}
+	}
+
+	if check {
+		fmt.Println("🔍 预览模式，未写入文件。去掉 --check 以执行迁移")
+	} else {
+		fmt.Printf("✅ 配置文件已从 %s 升级到 %s\n", report.FromVersion, report.ToVersion)
+	}
+	return nil
+}
+
+// printJSON 以缩进JSON格式输出到标准输出
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
 func setConfig(key, value string) {
-	if config.GlobalConfig == nil {
+	if config.Current() == nil {
 		fmt.Println("❌ 配置未初始化")
 		return
 	}
 
+	// 不修改当前配置，写入文件后由config.SaveValue整体替换为重新解析的实例
+	var persisted interface{}
 	switch key {
 	case "trash.auto_clean":
-		if value == "true" {
-			config.GlobalConfig.Trash.AutoClean = true
-		} else {
-			config.GlobalConfig.Trash.AutoClean = false
-		}
-		fmt.Printf("✅ 已设置 %s = %s\n", key, value)
+		persisted = value == "true"
 	case "ui.language":
-		config.GlobalConfig.UI.Language = value
-		fmt.Printf("✅ 已设置 %s = %s\n", key, value)
+		persisted = value
 	case "ui.color":
-		if value == "true" {
-			config.GlobalConfig.UI.Color = true
-		} else {
-			config.GlobalConfig.UI.Color = false
+		persisted = value == "true"
+	case "security.safe_mode":
+		mode := strings.ToLower(value)
+		if mode != config.SafeModeStrict && mode != config.SafeModeNormal && mode != config.SafeModeRelaxed {
+			fmt.Printf("❌ 未知的安全模式: %s，可选值: strict, normal, relaxed\n", value)
+			return
 		}
-		fmt.Printf("✅ 已设置 %s = %s\n", key, value)
+		persisted = mode
 	default:
 		fmt.Printf("❌ 未知的配置项: %s\n", key)
 		fmt.Println("支持的配置项:")
 		fmt.Println("  trash.auto_clean  - 自动清理回收站 (true/false)")
 		fmt.Println("  ui.language       - 界面语言 (zh/en)")
 		fmt.Println("  ui.color          - 彩色输出 (true/false)")
+		fmt.Println("  security.safe_mode - 安全模式 (strict/normal/relaxed)")
+		return
+	}
+
+	// 写入配置文件，与其他进程的并发修改互斥
+	if err := config.SaveValue(key, persisted); err != nil {
+		fmt.Printf("❌ 保存配置失败: %v\n", err)
+		return
 	}
+	fmt.Printf("✅ 已设置 %s = %s\n", key, value)
 }
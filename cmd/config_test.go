@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+)
+
+// initTempConfig 在临时HOME下加载默认配置，校验时未设置的项使用这些默认值
+func initTempConfig(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	config.Reset()
+	t.Cleanup(config.Reset)
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init: %v", err)
+	}
+	return home
+}
+
+func TestValidateConfigExitCodes(t *testing.T) {
+	dir := initTempConfig(t)
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{
+			name:    "valid",
+			content: "trash:\n  max_days: 30\n",
+			want:    errors.ExitOK,
+		},
+		{
+			name:    "warnings only",
+			content: "ui:\n  language: xx\nunknown_key: 1\n",
+			want:    errors.ExitOK,
+		},
+		{
+			name:    "error level problem",
+			content: "trash:\n  max_days: -1\n",
+			want:    errors.ExitConfig,
+		},
+		{
+			name:    "errors and warnings",
+			content: "trash:\n  compression_level: 12\nui:\n  language: xx\n",
+			want:    errors.ExitConfig,
+		},
+		{
+			name:    "unparsable file",
+			content: "trash: [unclosed\n",
+			want:    errors.ExitConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, asJSON := range []bool{false, true} {
+				err := validateConfig(path, asJSON)
+				if got := errors.ExitCode(err); got != tt.want {
+					t.Errorf("validateConfig(json=%v) exit code = %d (%v), want %d", asJSON, got, err, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfigMissingFile(t *testing.T) {
+	dir := initTempConfig(t)
+	err := validateConfig(filepath.Join(dir, "missing.yaml"), false)
+	if got := errors.ExitCode(err); got != errors.ExitConfig {
+		t.Errorf("exit code = %d (%v), want %d", got, err, errors.ExitConfig)
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/logger"
+	"delguard/internal/report"
+)
+
+// confirmConfig 确认提示的等待时长和超时后的默认回答
+type confirmConfig struct {
+	// Timeout 等待输入的最长时间，0表示一直等待
+	Timeout time.Duration
+	// Default 超时后的回答: yes, no, abort，永久删除和必须输入确认词的提示总是按no处理
+	Default string
+}
+
+// currentConfirmConfig 读取ui.confirm_timeout和ui.confirm_default
+func currentConfirmConfig() confirmConfig {
+	settings := confirmConfig{Default: "no"}
+	cfg := config.Current()
+	if cfg == nil {
+		return settings
+	}
+	ui := cfg.UI
+	settings.Timeout = time.Duration(ui.ConfirmTimeout) * time.Second
+	if ui.ConfirmDefault != "" {
+		settings.Default = strings.ToLower(ui.ConfirmDefault)
+	}
+	return settings
+}
+
+// confirmAnswered 本次调用中确认提示的回答方式，写入回执；有任何提示超时时为report.ConfirmedByTimeout
+var confirmAnswered string
+
+// confirmAborted ui.confirm_default为abort时提示超时，之后的提示不再等待输入，一律按否处理
+var confirmAborted bool
+
+// noteAnswer 记录确认提示的回答方式，超时优先于用户的明确回答
+func noteAnswer(source string) {
+	if confirmAnswered != report.ConfirmedByTimeout {
+		confirmAnswered = source
+	}
+}
+
+// timedOut 记录提示超时后按answer处理，日志中与用户的明确回答区分
+func timedOut(settings confirmConfig, answer string) {
+	noteAnswer(report.ConfirmedByTimeout)
+	logger.Infof("确认提示等待 %v 无输入，按默认回答: %s", settings.Timeout, answer)
+}
+
+// scanResult 一次读取的回答
+type scanResult struct {
+	response string
+	err      error
+}
+
+// pendingScan 上次提示超时后仍在等待的读取，下次提示直接使用其结果，避免两个读取争抢输入
+var pendingScan chan scanResult
+
+// readConfirmation 读取一行回答，设置了等待时长时超时返回expired
+func readConfirmation(settings confirmConfig) (response string, expired bool, err error) {
+	if settings.Timeout <= 0 {
+		_, err = fmt.Scanln(&response)
+		return response, false, err
+	}
+
+	if pendingScan == nil {
+		pendingScan = make(chan scanResult, 1)
+		go func(results chan<- scanResult) {
+			var response string
+			_, err := fmt.Scanln(&response)
+			results <- scanResult{response: response, err: err}
+		}(pendingScan)
+	}
+	select {
+	case result := <-pendingScan:
+		pendingScan = nil
+		return result.response, false, result.err
+	case <-time.After(settings.Timeout):
+		fmt.Println()
+		return "", true, nil
+	}
+}
+
+// skipAfterAbort 已中止时结束当前提示行并按否处理
+func skipAfterAbort() bool {
+	if confirmAborted {
+		fmt.Println()
+		fmt.Println("⏭️  已因确认超时中止，跳过")
+	}
+	return confirmAborted
+}
+
+// confirmAction 读取是/否回答，y或yes为是；超时时按ui.confirm_default回答，读取失败时返回错误
+// 只用于超时默认同意也无妨的提示，特殊文件、插件要求的确认和提权重试使用confirmDangerousAction
+func confirmAction() (bool, error) {
+	if skipAfterAbort() {
+		return false, nil
+	}
+	settings := currentConfirmConfig()
+	yes, expired, err := readYesNo(settings)
+	if err != nil || !expired {
+		return yes, err
+	}
+	timedOut(settings, settings.Default)
+	switch settings.Default {
+	case "yes":
+		fmt.Printf("⏱️  等待输入超时 (%v)，按默认回答: 是\n", settings.Timeout)
+		return true, nil
+	case "abort":
+		fmt.Printf("⏱️  等待输入超时 (%v)，中止操作\n", settings.Timeout)
+		confirmAborted = true
+	default:
+		fmt.Printf("⏱️  等待输入超时 (%v)，按默认回答: 否\n", settings.Timeout)
+	}
+	return false, nil
+}
+
+// readYesNo 读取是/否回答，y或yes为是，明确回答时记录回答方式
+func readYesNo(settings confirmConfig) (yes bool, expired bool, err error) {
+	response, expired, err := readConfirmation(settings)
+	if err != nil || expired {
+		return false, expired, err
+	}
+	noteAnswer(report.ConfirmedByUser)
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes", false, nil
+}
+
+// confirmDangerousAction 读取永久删除、保护插件要求确认和提权重试等需要用户明确同意的确认，
+// requireWord为true时必须完整输入DELETE
+// 超时总是按否处理，不受ui.confirm_default影响
+func confirmDangerousAction(requireWord bool) (bool, error) {
+	if requireWord {
+		return confirmCritical("DELETE")
+	}
+	if skipAfterAbort() {
+		return false, nil
+	}
+	settings := currentConfirmConfig()
+	yes, expired, err := readYesNo(settings)
+	if err != nil || !expired {
+		return yes, err
+	}
+	timedOut(settings, "no")
+	fmt.Printf("⏱️  等待输入超时 (%v)，视为取消\n", settings.Timeout)
+	return false, nil
+}
+
+// purgeConfirmWords 清空或清理回收站前需要完整输入的确认词，nil表示无需确认
+// 安全模式不遵循-f时忽略force；需要DELETE确认词或不允许永久删除的模式下总是要求输入DELETE
+func purgeConfirmWords(policy config.SafeModePolicy, force bool, words ...string) []string {
+	if force && policy.HonorForce {
+		return nil
+	}
+	if policy.RequireDeleteWord || !policy.AllowPermanentDelete {
+		return []string{"DELETE"}
+	}
+	return words
+}
+
+// confirmPurge 显示prompt并读取清空或清理回收站的确认，回答与words之一相同时为是
+// 不允许永久删除的安全模式下无法读取回答时返回错误，不会在脚本中被当作成功的取消
+func confirmPurge(policy config.SafeModePolicy, prompt string, words []string) (bool, error) {
+	fmt.Printf("%s 请输入 '%s' 确认: ", prompt, words[0])
+	confirmed, err := confirmCritical(words...)
+	if err == nil {
+		return confirmed, nil
+	}
+	if !policy.AllowPermanentDelete {
+		return false, errors.NewError(errors.ErrTypeValidation,
+			fmt.Sprintf("安全模式 %s 下永久删除回收站中的项目必须输入 DELETE 确认", policy.Mode), err)
+	}
+	return false, err
+}
+
+// confirmCritical 读取必须完整输入确认词的回答，回答与words之一相同时为是
+// 超时视为拒绝，不受ui.confirm_default影响
+func confirmCritical(words ...string) (bool, error) {
+	if skipAfterAbort() {
+		return false, nil
+	}
+	settings := currentConfirmConfig()
+	response, expired, err := readConfirmation(settings)
+	if err != nil {
+		return false, err
+	}
+	if expired {
+		timedOut(settings, "no")
+		fmt.Printf("⏱️  等待输入超时 (%v)，视为取消\n", settings.Timeout)
+		return false, nil
+	}
+	noteAnswer(report.ConfirmedByUser)
+	response = strings.TrimSpace(response)
+	for _, word := range words {
+		if response == word {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/plugin"
+	"delguard/internal/report"
+)
+
+func TestPurgeConfirmWords(t *testing.T) {
+	strict := config.NewSafeModePolicy(config.SafeModeStrict)
+	normal := config.NewSafeModePolicy(config.SafeModeNormal)
+	relaxed := config.NewSafeModePolicy(config.SafeModeRelaxed)
+	tests := []struct {
+		name   string
+		policy config.SafeModePolicy
+		force  bool
+		words  []string
+		want   []string
+	}{
+		{"strict ignores -f", strict, true, []string{"yes"}, []string{"DELETE"}},
+		{"strict requires DELETE", strict, false, []string{"yes"}, []string{"DELETE"}},
+		{"strict prompts for prune", strict, false, nil, []string{"DELETE"}},
+		{"normal honors -f", normal, true, []string{"yes"}, nil},
+		{"normal keeps command words", normal, false, []string{"yes", "YES"}, []string{"yes", "YES"}},
+		{"normal prune does not ask", normal, false, nil, nil},
+		{"relaxed honors -f", relaxed, true, []string{"yes"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := purgeConfirmWords(tt.policy, tt.force, tt.words...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("purgeConfirmWords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// withStdin 在fn执行期间用input替换标准输入
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString(input)
+	w.Close()
+	saved := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = saved
+		r.Close()
+	}()
+	fn()
+}
+
+func TestConfirmPurge(t *testing.T) {
+	strict := config.NewSafeModePolicy(config.SafeModeStrict)
+	normal := config.NewSafeModePolicy(config.SafeModeNormal)
+	tests := []struct {
+		name          string
+		policy        config.SafeModePolicy
+		input         string
+		wantConfirmed bool
+		wantErr       bool
+	}{
+		{"DELETE confirms", strict, "DELETE\n", true, false},
+		{"yes is not DELETE", strict, "yes\n", false, false},
+		{"strict refuses without input", strict, "", false, true},
+		{"normal without input", normal, "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var confirmed bool
+			var err error
+			withStdin(t, tt.input, func() {
+				confirmed, err = confirmPurge(tt.policy, "test", purgeConfirmWords(tt.policy, false, "yes"))
+			})
+			if confirmed != tt.wantConfirmed || (err != nil) != tt.wantErr {
+				t.Errorf("confirmPurge() = %v, %v", confirmed, err)
+			}
+		})
+	}
+}
+
+// withIdleStdin 在fn执行期间使用一个没有输入的标准输入，返回的函数向其写入回答
+// 结束时关闭输入并等待超时后仍在读取的goroutine退出，避免影响后续测试
+func withIdleStdin(t *testing.T, fn func(answer func(string))) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdin
+	os.Stdin = r
+	defer func() {
+		w.Close()
+		if pendingScan != nil {
+			<-pendingScan
+			pendingScan = nil
+		}
+		os.Stdin = saved
+		r.Close()
+		confirmAborted, confirmAnswered = false, ""
+	}()
+	fn(func(response string) { w.WriteString(response) })
+}
+
+// useConfirmTimeout 设置1秒的确认等待时间和超时后的默认回答
+func useConfirmTimeout(t *testing.T, answer string) {
+	t.Helper()
+	initTempConfig(t)
+	config.Current().UI.ConfirmTimeout = 1
+	config.Current().UI.ConfirmDefault = answer
+}
+
+func TestCurrentConfirmConfig(t *testing.T) {
+	initTempConfig(t)
+	if got := currentConfirmConfig(); got != (confirmConfig{Default: "no"}) {
+		t.Errorf("default confirmConfig = %+v, want no timeout and no", got)
+	}
+	config.Current().UI.ConfirmTimeout = 45
+	config.Current().UI.ConfirmDefault = "YES"
+	if got := currentConfirmConfig(); got != (confirmConfig{Timeout: 45 * time.Second, Default: "yes"}) {
+		t.Errorf("configured confirmConfig = %+v, want 45s and yes", got)
+	}
+}
+
+func TestConfirmActionTimeoutDefault(t *testing.T) {
+	for _, tt := range []struct {
+		answer string
+		want   bool
+	}{{"yes", true}, {"no", false}, {"", false}} {
+		t.Run("default "+tt.answer, func(t *testing.T) {
+			useConfirmTimeout(t, tt.answer)
+			withIdleStdin(t, func(func(string)) {
+				confirmed, err := confirmAction()
+				if err != nil || confirmed != tt.want {
+					t.Errorf("confirmAction() = %v, %v; want %v", confirmed, err, tt.want)
+				}
+				if confirmAnswered != report.ConfirmedByTimeout {
+					t.Errorf("confirmAnswered = %q, want %q", confirmAnswered, report.ConfirmedByTimeout)
+				}
+			})
+		})
+	}
+}
+
+func TestConfirmActionTimeoutAbortSkipsLaterPrompts(t *testing.T) {
+	useConfirmTimeout(t, "abort")
+	withIdleStdin(t, func(answer func(string)) {
+		if confirmed, err := confirmAction(); confirmed || err != nil {
+			t.Fatalf("confirmAction() = %v, %v; want no", confirmed, err)
+		}
+		// 中止后即使有回答也不再读取
+		answer("y\n")
+		started := time.Now()
+		for _, confirm := range []func() (bool, error){confirmAction, func() (bool, error) { return confirmCritical("DELETE") }} {
+			if confirmed, err := confirm(); confirmed || err != nil {
+				t.Errorf("prompt after abort = %v, %v; want no", confirmed, err)
+			}
+		}
+		if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+			t.Errorf("prompts after abort waited %v", elapsed)
+		}
+	})
+}
+
+func TestCriticalConfirmationIgnoresYesDefault(t *testing.T) {
+	useConfirmTimeout(t, "yes")
+	withIdleStdin(t, func(func(string)) {
+		if confirmed, err := confirmCritical("DELETE"); confirmed || err != nil {
+			t.Errorf("confirmCritical() = %v, %v; want no on timeout", confirmed, err)
+		}
+	})
+	withIdleStdin(t, func(func(string)) {
+		if confirmed, err := confirmDangerousAction(false); confirmed || err != nil {
+			t.Errorf("confirmDangerousAction() = %v, %v; want no on timeout", confirmed, err)
+		}
+	})
+}
+
+func TestLateAnswerGoesToTheNextPrompt(t *testing.T) {
+	useConfirmTimeout(t, "no")
+	withIdleStdin(t, func(answer func(string)) {
+		if confirmed, _ := confirmAction(); confirmed {
+			t.Fatal("confirmAction() = yes on timeout with default no")
+		}
+		// 超时后仍在等待的读取拿到这个回答，下一次提示直接使用它
+		answer("yes\n")
+		if confirmed, err := confirmAction(); !confirmed || err != nil {
+			t.Errorf("confirmAction() = %v, %v; want the late yes", confirmed, err)
+		}
+		if pendingScan != nil {
+			t.Error("pending read was not consumed")
+		}
+	})
+}
+
+// confirmPlugin 创建要求确认每次删除的保护规则插件，返回加载了它的Runner
+func confirmPlugin(t *testing.T) *plugin.Runner {
+	t.Helper()
+	return protectionPlugin(t, "protect-confirm.sh", "echo 'needs a human'\nexit 2\n")
+}
+
+// protectionPlugin 创建执行script的保护规则插件，返回加载了它的Runner
+func protectionPlugin(t *testing.T, name, script string) *plugin.Runner {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("sh unavailable: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config.Current().Integration.PluginDirectory = dir
+	runner, err := plugin.NewRunner(config.Current())
+	if err != nil || runner == nil {
+		t.Fatalf("NewRunner = %v, %v", runner, err)
+	}
+	return runner
+}
+
+// TestGatedPromptsIgnoreYesDefault 特殊文件、插件要求的确认和提权重试超时时不能按ui.confirm_default: yes同意
+func TestGatedPromptsIgnoreYesDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm func(t *testing.T) bool
+	}{
+		{"special file", func(t *testing.T) bool {
+			return confirmSpecialFile(errors.NewError(errors.ErrTypeSpecialFile, "命名管道: /srv/fifo", nil), false)
+		}},
+		{"plugin confirm", func(t *testing.T) bool {
+			runner := confirmPlugin(t)
+			allowed, err := checkProtectionPlugins(runner, writeTestFile(t, t.TempDir(), "guarded.txt"), false)
+			if err != nil {
+				t.Errorf("checkProtectionPlugins: %v", err)
+			}
+			return allowed
+		}},
+		{"elevation retry", func(t *testing.T) bool {
+			denied := []deniedItem{{item: report.Item{Path: `C:\Windows\locked.txt`}}}
+			_, retried := retryElevated(useFakeTrash(t), denied, elevatedOptions{}, true)
+			return retried
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfirmTimeout(t, "yes")
+			withIdleStdin(t, func(func(string)) {
+				var got bool
+				output := captureStdout(t, func() { got = tt.confirm(t) })
+				if got {
+					t.Errorf("timed out prompt was approved by the yes default:\n%s", output)
+				}
+				if !strings.Contains(output, "视为取消") {
+					t.Errorf("output = %q, want the timeout treated as a cancel", output)
+				}
+			})
+		})
+	}
+}
+
+// TestFailingPluginBlocksDelete 插件崩溃、超时或返回未知的退出码时不删除，即使指定了-y
+func TestFailingPluginBlocksDelete(t *testing.T) {
+	initTempConfig(t)
+	for _, script := range []string{"exit 3\n", "kill -9 $$\n", "exec /nonexistent/interpreter\n"} {
+		runner := protectionPlugin(t, "protect-broken.sh", script)
+		allowed, err := checkProtectionPlugins(runner, writeTestFile(t, t.TempDir(), "guarded.txt"), true)
+		if allowed {
+			t.Errorf("%q: failing plugin allowed the delete", script)
+		}
+		if !errors.IsType(err, errors.ErrTypeBlocked) || !strings.Contains(err.Error(), "插件执行失败") {
+			t.Errorf("%q: err = %v, want a blocked error naming the failure", script, err)
+		}
+	}
+}
@@ -6,19 +6,39 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
+	"delguard/internal/config"
+	"delguard/internal/errors"
 	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
+	"delguard/internal/lock"
+	"delguard/internal/logger"
+	"delguard/internal/plugin"
+	"delguard/internal/report"
 	"delguard/internal/security"
+	"delguard/internal/telemetry"
+	"delguard/internal/utils"
 )
 
 var deleteCmd = &cobra.Command{
 	Use:   "delete [files...]",
 	Short: "安全删除文件到回收站",
 	Long: `将指定的文件或目录安全地移动到系统回收站。
-支持多个文件同时删除，支持通配符模式。`,
+支持多个文件同时删除，支持通配符模式。
+空目录可以直接删除，非空目录需要 -r；--empty-only 只删除空目录（类似rmdir）。
+从浏览器或文件管理器粘贴的 file:// URL、带引号的路径和 ~user 形式的路径会被自动规范化。
+使用 --no-trash 可不经过回收站直接永久删除，需要输入 DELETE 确认，每个文件都会记入日志。
+security.safe_mode 为 strict 时 -f 和 -y 不会跳过确认，并禁止使用 --no-trash。
+位于Git仓库中时提示有未提交修改的已跟踪文件，删除 .git 目录需要输入 DELETE（-y 除外），security.git_awareness: off 时不检查。
+Windows上因权限不足（如ProgramData中其他用户的文件）删除失败时，会询问是否以管理员身份重试，
+同意后才会弹出UAC提示，回执中这些项目记为 elevated；windows.enable_uac_prompt: false 时不询问。
+文件名含换行或无效编码而无法输入时，可使用 --by-id <目录> <ID> 按inode（Windows上为文件ID）删除，
+ID为 "设备号:inode" 或只有inode，可通过 ls -i 或 stat -c '%d:%i' 查看。
+--exclude 和 --exclude-from 按.gitignore的格式排除条目，被排除的条目及其所在的目录保留，
+例如: delguard rm -r build/ --exclude-from .delguardignore --exclude '*.keep'`,
 	Aliases: []string{"del", "rm"},
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    runDelete,
@@ -28,72 +48,110 @@ func init() {
 	rootCmd.AddCommand(deleteCmd)
 	deleteCmd.Flags().BoolP("force", "f", false, "强制删除，不显示确认提示")
 	deleteCmd.Flags().BoolP("recursive", "r", false, "递归删除目录")
-	deleteCmd.Flags().BoolP("verbose", "v", false, "显示详细信息")
+	deleteCmd.Flags().Bool("empty-only", false, "只删除空目录，拒绝非空目录（类似rmdir）")
 	deleteCmd.Flags().BoolP("interactive", "i", false, "交互式删除，每个文件都询问")
 	deleteCmd.Flags().BoolP("dry-run", "n", false, "预览模式，显示将要删除的文件但不实际删除")
+	deleteCmd.Flags().Bool("json", false, "预览模式下以JSON格式输出")
+	deleteCmd.Flags().Bool("shred", false, "覆写文件内容后永久删除，不经过回收站")
+	deleteCmd.Flags().Bool("shred-links", false, "粉碎符号链接指向的文件（默认拒绝粉碎符号链接）")
+	deleteCmd.Flags().Int("passes", filesystem.DefaultShredPasses, "粉碎时的覆写遍数")
+	deleteCmd.Flags().Bool("no-trash", false, "不经过回收站直接永久删除，需要输入DELETE确认")
+	deleteCmd.Flags().BoolP("yes", "y", false, "粉碎或永久删除时跳过确认提示")
+	deleteCmd.Flags().BoolP("dereference", "L", false, "删除符号链接指向的目标，而不是链接本身")
+	deleteCmd.Flags().Bool("one-file-system", true, "跳过包含其他文件系统挂载点的目录，设为false时输入DELETE确认后一并删除挂载的内容")
+	deleteCmd.Flags().Bool("by-id", false, "按inode（Windows上为文件ID）删除: --by-id <目录> <ID>...")
+	deleteCmd.Flags().StringArray("exclude", nil, "排除匹配的条目，格式与.gitignore的一行相同，可多次指定")
+	deleteCmd.Flags().StringArray("exclude-from", nil, "从文件读取排除模式，格式与.gitignore相同，可多次指定")
+	deleteCmd.Flags().String("elevated-result", "", "以管理员身份重新运行时写入处理结果的文件（内部使用）")
+	deleteCmd.Flags().MarkHidden("elevated-result")
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
 	// 获取标志值
 	force, _ := cmd.Flags().GetBool("force")
 	recursive, _ := cmd.Flags().GetBool("recursive")
+	emptyOnly, _ := cmd.Flags().GetBool("empty-only")
 	interactive, _ := cmd.Flags().GetBool("interactive")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	verbose := viper.GetBool("verbose")
-	quiet := viper.GetBool("quiet")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	shred, _ := cmd.Flags().GetBool("shred")
+	shredLinks, _ := cmd.Flags().GetBool("shred-links")
+	passes, _ := cmd.Flags().GetInt("passes")
+	noTrash, _ := cmd.Flags().GetBool("no-trash")
+	yes, _ := cmd.Flags().GetBool("yes")
+	dereference, _ := cmd.Flags().GetBool("dereference")
+	byID, _ := cmd.Flags().GetBool("by-id")
+	oneFileSystem, _ := cmd.Flags().GetBool("one-file-system")
+	if cfg := config.Current(); !cmd.Flags().Changed("dereference") && cfg != nil {
+		dereference = cfg.Trash.DereferenceSymlinks
+	}
+	if shred && noTrash {
+		return errors.NewError(errors.ErrTypeUsage, "--shred 和 --no-trash 不能同时使用", nil)
+	}
+	// --elevated-result 会跳过所有确认，只接受retryElevated启动的已提权子进程
+	elevatedResult, _ := cmd.Flags().GetString("elevated-result")
+	if elevatedResult != "" {
+		if err := validateElevatedResult(elevatedResult, shred || noTrash); err != nil {
+			return err
+		}
+	}
+	level := currentOutputLevel()
+	verbose := level >= levelVerbose
+	quiet := level == levelMinimal
+
+	// 按security.safe_mode决定-f/-y是否生效以及是否允许不经过回收站的删除
+	policy := safeModePolicy()
+	if noTrash && !policy.AllowPermanentDelete {
+		return errors.NewError(errors.ErrTypeValidation, fmt.Sprintf("安全模式 %s 下不允许使用 --no-trash", policy.Mode), nil)
+	}
+	if force && !policy.HonorForce {
+		force = false
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "🔒 安全模式 %s: 忽略 -f，仍需确认并执行安全检查\n", policy.Mode)
+		}
+	}
+	if yes && !policy.HonorYes {
+		yes = false
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "🔒 安全模式 %s: 忽略 -y，仍需确认\n", policy.Mode)
+		}
+	}
+
+	// 未指定-f/-i时按trash.confirm_delete和trash.interactive决定确认方式
+	confirm := !force
+	if cfg := config.Current(); cfg != nil && !force && !interactive {
+		confirm = cfg.Trash.ConfirmDelete || !policy.HonorForce
+		interactive = cfg.Trash.Interactive
+	}
+
+	// 以管理员身份重新运行时，父进程已经完成了确认和安全检查
+	if elevatedResult != "" {
+		force, yes, interactive, confirm = true, true, false, false
+	}
+
+	filter, err := loadDeleteFilter(cmd)
+	if err != nil {
+		return err
+	}
 
 	// 获取回收站管理器
-	manager, err := filesystem.GetTrashManager()
+	manager, err := newTrashManager()
 	if err != nil {
-		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+		return errors.FromOS("初始化回收站管理器失败", err)
 	}
 
-	// 展开所有文件路径（处理通配符）
+	// 展开所有文件路径（处理通配符），--by-id 时按文件ID在目录中查找
 	var filesToDelete []string
-	for _, arg := range args {
-		// 清理路径，防止路径遍历攻击
-		cleanArg := filepath.Clean(arg)
-		
-		// 验证路径长度
-		if len(cleanArg) > 4096 {
-			if !quiet {
-				fmt.Fprintf(os.Stderr, "⚠️  警告: 路径过长 '%s'\n", cleanArg)
-			}
-			continue
-		}
-		
-		// 检查路径是否包含空字符
-		if strings.ContainsRune(cleanArg, 0) {
-			if !quiet {
-				fmt.Fprintf(os.Stderr, "⚠️  警告: 路径包含非法字符 '%s'\n", cleanArg)
-			}
-			continue
-		}
-		
-		matches, err := filepath.Glob(cleanArg)
-		if err != nil {
-			if !quiet {
-				fmt.Fprintf(os.Stderr, "⚠️  警告: 无法处理路径 '%s': %v\n", cleanArg, err)
-			}
-			continue
-		}
-
-		if len(matches) == 0 {
-			// 没有匹配的文件，检查是否是直接路径
-			if _, err := os.Stat(cleanArg); err == nil {
-				filesToDelete = append(filesToDelete, cleanArg)
-			} else {
-				if !quiet {
-					fmt.Fprintf(os.Stderr, "⚠️  警告: 文件不存在 '%s'\n", cleanArg)
-				}
-			}
-		} else {
-			filesToDelete = append(filesToDelete, matches...)
+	if byID {
+		if filesToDelete, err = resolveByID(args); err != nil {
+			return err
 		}
+	} else {
+		filesToDelete = expandDeleteArgs(args, level)
 	}
 
 	if len(filesToDelete) == 0 {
-		return fmt.Errorf("没有找到要删除的文件")
+		return errors.NewError(errors.ErrTypeFileNotFound, "没有找到要删除的文件", nil)
 	}
 
 	// 创建路径验证器
@@ -101,8 +159,17 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	
 	// 验证文件并过滤
 	var validFiles []string
+	var inTrashFiles []string
+	var remoteFiles []string
+	var rejectedDir error
+	var skippedMounts, crossingMounts []mountTarget
+	defer func() { printSkippedMounts(skippedMounts) }()
+	remotePolicy := remoteFilesystemPolicy()
+	if remotePolicy == config.RemoteDelete && !policy.AllowPermanentDelete {
+		remotePolicy = config.RemoteRefuse
+	}
 	for _, file := range filesToDelete {
-		absPath, err := filepath.Abs(file)
+		absPath, err := filesystem.AbsPath(file)
 		if err != nil {
 			if !quiet {
 				fmt.Fprintf(os.Stderr, "⚠️  警告: 无法获取绝对路径 '%s': %v\n", file, err)
@@ -110,154 +177,738 @@ func runDelete(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		// 符号链接默认删除链接本身，--dereference时改为删除其指向的目标
+		if absPath, err = deleteTarget(absPath, dereference); err != nil {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "⚠️  警告: 无法解析符号链接 '%s' 的目标: %v\n", file, err)
+			}
+			continue
+		}
+
+		// 已在回收站中的文件不再移入回收站，稍后询问是否永久删除
+		if filesystem.IsInsideTrash(manager, absPath) {
+			inTrashFiles = append(inTrashFiles, absPath)
+			continue
+		}
+
 		// 验证路径安全性
-		if err := validator.ValidateDeletePath(absPath); err != nil {
+		if err := validator.ValidateDeleteLinkPath(absPath); err != nil {
 			if !quiet {
 				fmt.Fprintf(os.Stderr, "⚠️  安全警告: %v\n", err)
 			}
 			continue
 		}
 
-		info, err := os.Stat(absPath)
+		info, err := filesystem.Lstat(absPath)
 		if err != nil {
 			if !quiet {
-				fmt.Fprintf(os.Stderr, "⚠️  警告: 无法访问文件 '%s': %v\n", file, err)
+				printStatError(file, err)
 			}
 			continue
 		}
 
-		// 检查是否为目录且未指定递归删除
-		if info.IsDir() && !recursive {
-			if !quiet {
-				fmt.Fprintf(os.Stderr, "⚠️  警告: '%s' 是目录，使用 -r 选项递归删除\n", file)
+		// 空目录可以直接删除，非空目录需要 -r，--empty-only 时只删除空目录
+		if info.IsDir() {
+			if err := checkDirectoryTarget(absPath, recursive, emptyOnly); err != nil {
+				rejectedDir = err
+				if !quiet {
+					fmt.Fprintf(os.Stderr, "⚠️  警告: %v\n", err)
+				}
+				continue
+			}
+
+			// 递归删除会进入目录中挂载的其他文件系统，默认跳过这样的目录
+			if elevatedResult == "" {
+				if err := guardNestedMounts(absPath, oneFileSystem, &skippedMounts, &crossingMounts); err != nil {
+					rejectedDir = err
+					continue
+				}
 			}
-			continue
 		}
 
-		// 检查是否为系统文件
+		// 系统文件需要-f；可以交互确认时也可以在确认时输入DELETE删除，粉碎和永久删除始终拒绝系统文件
+		escalate := !shred && !noTrash && stdinIsTerminal()
 		if isSystemFile(absPath) {
 			if !quiet {
 				fmt.Fprintf(os.Stderr, "⚠️  警告: '%s' 可能是系统文件，删除可能导致系统问题\n", file)
 			}
-			if !force {
+			if !force && !escalate {
+				continue
+			}
+		}
+
+		// 按security.check_selinux检查SELinux标签，受保护类型的文件与系统文件相同
+		if selinuxType, ok := protectedSELinuxLabel(absPath); ok {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "⚠️  警告: '%s' 的SELinux类型为 %s，删除可能导致系统问题\n", file, selinuxType)
+			}
+			if !force && !escalate {
+				continue
+			}
+		}
+
+		// 远程文件系统上的项目按security.remote_filesystems处理，移入回收站时进入降级模式
+		if !shred && !noTrash {
+			action, err := remoteAction(file, absPath, remotePolicy)
+			if err != nil {
+				telemetry.AddError(err)
+				fmt.Fprintf(os.Stderr, "⛔ %v\n", err)
+				continue
+			}
+			if action == config.RemoteDelete {
+				remoteFiles = append(remoteFiles, absPath)
 				continue
 			}
+			if action == config.RemoteWarn && !quiet {
+				printNetworkPlan(manager, file, absPath, verbose)
+			}
 		}
 
 		validFiles = append(validFiles, absPath)
 	}
 
-	if len(validFiles) == 0 {
-		return fmt.Errorf("没有有效的文件可以删除")
+	// 同一文件只处理一次，位于要删除的目录中的项目随目录一起移入回收站
+	validFiles = dedupeTargets(validFiles, verbose)
+	remoteFiles = dedupeTargets(remoteFiles, verbose)
+
+	// 被排除的条目保留，包含被排除条目的目录改为逐项删除其余内容
+	excluded := 0
+	if !filter.Empty() {
+		if validFiles, remoteFiles, excluded, err = applyDeleteFilter(filter, validFiles, remoteFiles); err != nil {
+			return err
+		}
+		if excluded > 0 && !quiet && !(dryRun && asJSON) {
+			fmt.Printf("🙈 已排除 %d 个条目\n", excluded)
+		}
+	}
+
+	// 处理已在回收站中的文件
+	if len(inTrashFiles) > 0 {
+		purgeTrashedFiles(manager, inTrashFiles, force, dryRun, quiet)
+		if len(validFiles) == 0 && len(remoteFiles) == 0 {
+			return nil
+		}
+	}
+
+	if len(validFiles) == 0 && len(remoteFiles) == 0 {
+		if rejectedDir != nil {
+			return rejectedDir
+		}
+		return errors.NewError(errors.ErrTypeValidation, "没有有效的文件可以删除", nil)
 	}
 
-	// 预览模式
+	// 预览模式，输出格式与restore --dry-run相同
 	if dryRun {
-		fmt.Println("🔍 预览模式 - 以下文件将被移动到回收站:")
-		for _, file := range validFiles {
-			info, _ := os.Stat(file)
-			fileType := "文件"
-			if info.IsDir() {
-				fileType = "目录"
-			}
-			fmt.Printf("  📄 %s (%s)\n", file, fileType)
+		report := planDelete(manager, validFiles, shred || noTrash)
+		report.Excluded = excluded
+		if noTrash {
+			report.Operation = "purge"
+		}
+		for _, item := range planDelete(manager, remoteFiles, true).Items {
+			item.PurgeRemote = true
+			report.Items = append(report.Items, item)
+		}
+		if plan, err := filesystem.PlanDeletion(append(validFiles, remoteFiles...)); err == nil {
+			report.Plan = plan
+		}
+		if asJSON {
+			return printJSON(report)
+		}
+		if shred {
+			printPreview(fmt.Sprintf("🔍 %s", i18n.Plural("delete.preview_shred", passes)), report)
+		} else if noTrash || len(validFiles) == 0 {
+			printPreview("🔍 预览模式 - 以下文件将被永久删除，不经过回收站:", report)
+		} else {
+			printPreview("🔍 预览模式 - 以下文件将被移动到回收站:", report)
+		}
+		if report.Plan != nil {
+			printDeletionPlan(report.Plan)
 		}
 		return nil
 	}
 
+	// 按security.git_awareness检查Git仓库，提示未提交的修改，删除.git目录需要输入DELETE
+	if !checkGitRepos(append(validFiles, remoteFiles...), yes, quiet) {
+		return nil
+	}
+
+	// --one-file-system=false 时包含挂载点的目标需要输入DELETE确认
+	if rejected := confirmCrossMounts(crossingMounts); len(rejected) > 0 {
+		validFiles = withoutPaths(validFiles, rejected)
+		remoteFiles = withoutPaths(remoteFiles, rejected)
+		if len(validFiles) == 0 && len(remoteFiles) == 0 {
+			return nil
+		}
+	}
+
+	plugins := loadProtectionPlugins(quiet)
+	defer plugins.SaveDecisions()
+
+	// 粉碎模式不经过回收站
+	if shred {
+		return shredFiles(validFiles, filesystem.ShredOptions{Passes: passes, FollowLinks: shredLinks}, plugins, yes, quiet)
+	}
+
+	// --no-trash不经过回收站直接删除，relaxed模式下-f也可以跳过确认
+	directYes := yes || (force && policy.ForceDirectDelete)
+	if noTrash {
+		return removePermanently(validFiles, plugins, directYes, quiet)
+	}
+
+	// 按security.remote_filesystems: delete，远程文件系统上的项目单独确认后永久删除
+	if len(remoteFiles) > 0 {
+		fmt.Printf("🌐 %s\n", i18n.Plural("remote.purge", len(remoteFiles)))
+		if err := removePermanently(remoteFiles, plugins, directYes, quiet); err != nil {
+			return err
+		}
+		if len(validFiles) == 0 {
+			return nil
+		}
+	}
+
+	// 受保护的目标按类别列出，需要更强确认的类别未通过确认时只跳过这些目标
+	if !force {
+		if validFiles = confirmProtectedTargets(validFiles, confirm && !interactive); len(validFiles) == 0 {
+			fmt.Println("❌ " + i18n.T("common.cancelled"))
+			return nil
+		}
+	}
+
 	// 确认删除
-	if !force && !interactive {
-		fmt.Printf("🗑️  将要删除 %d 个项目到回收站，确认吗? [y/N]: ", len(validFiles))
-		var response string
-		if _, err := fmt.Scanln(&response); err != nil {
+	if confirm && !interactive {
+		presentDeletionPlan(validFiles)
+		fmt.Printf("🗑️  %s", i18n.Plural("delete.confirm", len(validFiles)))
+		confirmed, err := confirmAction()
+		if err != nil {
 			log.Printf("读取输入时出错: %v", err)
-			fmt.Println("❌ 读取输入失败，操作已取消")
+			fmt.Println("❌ " + i18n.T("common.input_failed"))
 			return nil
 		}
-
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
-			fmt.Println("❌ 操作已取消")
+		if !confirmed {
+			fmt.Println("❌ " + i18n.T("common.cancelled"))
 			return nil
 		}
 	}
 
 	// 执行删除
 	successCount := 0
-	errorCount := 0
+	failures := errors.NewErrorCollector()
+	startedAt := time.Now()
+	operation := startOperation(cmd, "删除")
+	defer operation.Finish()
+	receipt := newReceipt("delete")
+	if elevatedResult != "" {
+		receipt = report.New("delete")
+	}
+
+	// 权限不足的项目在全部处理完后询问是否以管理员身份重试
+	offerElevation := elevationAvailable(elevatedResult)
+	var denied []deniedItem
+
+	// 删除前扫描仅在配置了scan_on_delete时启用
+	var scanner security.MalwareScanner
+	if cfg := config.Current(); cfg != nil && cfg.Security.ScanOnDelete {
+		scanner = newMalwareScanner(cfg)
+	}
 
 	// 批量处理优化
 	batchSize := 10
-	if len(validFiles) > batchSize {
-		fmt.Printf("🔄 正在批量处理 %d 个文件...\n", len(validFiles))
+	if len(validFiles) > batchSize && !quiet {
+		fmt.Printf("🔄 %s\n", i18n.Plural("delete.batch", len(validFiles)))
 	}
 
+	progress := newProgress(level)
 	for i, file := range validFiles {
 		// 显示进度
-		if len(validFiles) > batchSize && !quiet {
-			fmt.Printf("进度: %d/%d\r", i+1, len(validFiles))
+		if len(validFiles) > batchSize {
+			progress.Update(i+1, len(validFiles), file)
 		}
 
 		// 交互式确认
 		if interactive {
-			fmt.Printf("删除 '%s'? [y/N]: ", file)
-			var response string
-			if _, err := fmt.Scanln(&response); err != nil {
+			fmt.Print(i18n.T("delete.prompt", file))
+			confirmed, err := confirmAction()
+			if err != nil {
 				log.Printf("读取输入时出错: %v", err)
-				fmt.Println("❌ 读取输入失败，跳过此文件")
+				fmt.Println("❌ " + i18n.T("delete.input_skip"))
 				continue
 			}
-
-			response = strings.ToLower(strings.TrimSpace(response))
-			if response != "y" && response != "yes" {
+			if !confirmed {
+				receipt.Add(report.Item{Path: file, Outcome: report.OutcomeSkipped, Reason: "用户跳过"})
 				if verbose {
-					fmt.Printf("⏭️  跳过: %s\n", file)
+					fmt.Println("⏭️  " + i18n.T("delete.skipped", file))
 				}
 				continue
 			}
 		}
 
-		// 执行删除
-		err := manager.MoveToTrash(file)
+		// 同一路径可能被多个DelGuard进程同时删除，加锁后再处理
+		pathLock, err := lock.TryLockPath(file)
 		if err != nil {
-			errorCount++
+			if pid, held := lock.IsHeld(err); held {
+				receipt.Add(report.Item{Path: file, Outcome: report.OutcomeSkipped, Reason: fmt.Sprintf("正在被另一个DelGuard进程删除 (pid %d)", pid)})
+				if !quiet {
+					fmt.Printf("⏭️  跳过 '%s': 正在被另一个DelGuard进程删除 (pid %d)\n", file, pid)
+				}
+				continue
+			}
+			if verbose {
+				fmt.Fprintf(os.Stderr, "⚠️  无法为 '%s' 加锁，继续删除: %v\n", file, err)
+			}
+		}
+		if _, statErr := filesystem.Lstat(file); os.IsNotExist(statErr) {
+			pathLock.Unlock()
+			receipt.Add(report.Item{Path: file, Outcome: report.OutcomeSkipped, Reason: "已被其他进程删除"})
+			if !quiet {
+				fmt.Printf("⏭️  跳过 '%s': 已被其他进程删除\n", file)
+			}
+			continue
+		}
+
+		// 保护规则插件
+		if proceed, err := checkProtectionPlugins(plugins, file, force); !proceed {
+			pathLock.Unlock()
+			receipt.Add(skippedByPlugin(file, err))
+			if err != nil {
+				failures.Add(file, err)
+				if !quiet {
+					fmt.Fprintf(os.Stderr, "🛡️  %v\n", err)
+				}
+			}
+			continue
+		}
+
+		// 扫描被标记的文件仍会移入回收站，但恢复时将被拒绝
+		if scanner != nil {
+			if err := scanner.Scan(file); err != nil && !quiet {
+				if errors.IsType(err, errors.ErrTypeMalware) {
+					fmt.Fprintf(os.Stderr, "🦠 警告: %v\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "⚠️  扫描 '%s' 失败: %v\n", file, err)
+				}
+			}
+		}
+
+		// 执行删除
+		var size int64
+		isDir := false
+		if info, statErr := filesystem.Lstat(file); statErr == nil {
+			size = info.Size()
+			isDir = info.IsDir()
+		}
+		started := time.Now()
+		err = manager.MoveToTrash(file)
+		if warning, ok := filesystem.AsWarning(err); ok {
 			if !quiet {
-				fmt.Fprintf(os.Stderr, "❌ 删除失败 '%s': %v\n", file, err)
+				fmt.Fprintf(os.Stderr, "⚠️  警告: %v\n", warning)
 			}
+			err = nil
+		}
+		if errors.IsType(err, errors.ErrTypeSpecialFile) && confirmSpecialFile(err, force || !policy.PromptSpecialFiles) {
+			err = filesystem.TrashSpecialFile(manager, file)
+		}
+		elapsed := time.Since(started)
+		pathLock.Unlock()
+		if err != nil && offerElevation && isPermissionDenied(err) {
+			denied = append(denied, deniedItem{
+				item: report.Item{Path: file, Size: size, IsDirectory: isDir, Outcome: report.OutcomeFailed, Reason: err.Error()},
+				err:  err,
+			})
+		} else if err != nil {
+			// 静默模式下仍然输出错误
+			failures.Add(file, err)
+			receipt.Add(report.Item{Path: file, Size: size, IsDirectory: isDir, Outcome: report.OutcomeFailed, Reason: err.Error()})
+			fmt.Fprintln(os.Stderr, "❌ "+i18n.T("delete.failed", file, err))
 		} else {
 			successCount++
+			receipt.Add(report.Item{Path: file, Size: size, IsDirectory: isDir, Outcome: report.OutcomeTrashed})
+			operation.Add(1, size)
 			if verbose {
-				fmt.Printf("✅ 已移动到回收站: %s\n", file)
+				printTrashedFile(manager, file, elapsed, level)
 			}
 		}
 	}
 
-	// 显示结果摘要
-	if !quiet {
-		if len(validFiles) > 10 {
-			fmt.Println() // 添加换行
+	// 显示结果摘要，静默模式下也输出
+	progress.Done()
+	if len(denied) > 0 {
+		results := make(map[string]report.Item)
+		opts := elevatedOptions{Recursive: recursive, EmptyOnly: emptyOnly}
+		if items, retried := retryElevated(manager, denied, opts, quiet); retried {
+			for _, item := range items {
+				results[item.Path] = item
+			}
+		}
+		for _, d := range denied {
+			result, ok := results[d.item.Path]
+			if ok && result.Outcome == report.OutcomeTrashed {
+				successCount++
+				receipt.Add(result)
+				operation.Add(1, result.Size)
+				continue
+			}
+			err := d.err
+			if ok {
+				d.item = result
+				err = errors.NewError(errors.ErrTypePermissionDenied, "以管理员身份重试仍失败: "+result.Reason, nil)
+			}
+			failures.Add(d.item.Path, err)
+			receipt.Add(d.item)
+			fmt.Fprintln(os.Stderr, "❌ "+i18n.T("delete.failed", d.item.Path, err))
+		}
+	}
+	if successCount > 0 {
+		fmt.Printf("✅ %s\n", i18n.Plural("delete.done", successCount))
+	}
+	if failures.HasErrors() {
+		fmt.Printf("❌ %s\n", failures.Summary())
+	}
+	for _, failure := range failures.Errors() {
+		telemetry.AddError(failure.Err)
+	}
+	if elevatedResult != "" {
+		if err := writeElevatedResult(elevatedResult, receipt.Items); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  写入处理结果失败: %v\n", err)
+		}
+	} else {
+		saveReceipt(manager, receipt, quiet)
+	}
+	if successCount > 0 {
+		rotateTrash(manager, startedAt, quiet)
+	}
+
+	if failures.HasErrors() {
+		return errors.NewError(failures.Type(), "部分文件删除失败", nil)
+	}
+
+	return nil
+}
+
+// planDelete 计算删除预览，目标为回收站目录，粉碎时没有目标位置
+func planDelete(manager filesystem.TrashManager, files []string, shred bool) previewReport {
+	report := previewReport{Operation: "delete"}
+	trashPath := ""
+	if shred {
+		report.Operation = "shred"
+	} else if path, err := manager.GetTrashPath(); err == nil {
+		trashPath = path
+	}
+
+	required := make(map[string]*previewVolume)
+	for _, file := range files {
+		item := previewItem{Name: file, Source: file, Destination: trashPath}
+		if info, err := filesystem.Lstat(file); err == nil {
+			item.Size = info.Size()
+			item.IsDirectory = info.IsDir()
+			if info.IsDir() {
+				item.Size = filesystem.TreeSize(file)
+			}
+		}
+		report.addItem(item, required)
+	}
+	report.finish(required)
+	return report
+}
+
+// printTrashedFile 输出文件在回收站中的位置，调试级别额外输出耗时和回收站后端
+func printTrashedFile(manager filesystem.TrashManager, file string, elapsed time.Duration, level outputLevel) {
+	trashed, found := filesystem.FindTrashed(manager, file)
+	if !found {
+		// 系统回收站中可能无法定位刚移入的文件
+		fmt.Println("✅ " + i18n.T("delete.moved", file))
+		return
+	}
+
+	fmt.Println("✅ " + i18n.T("delete.moved_to", file, trashed.TrashPath))
+	if level >= levelDebug {
+		fmt.Printf("   ⏱️  %v, %s\n", elapsed.Round(time.Microsecond), filesystem.TrashBackend(trashed.TrashPath))
+	}
+}
+
+// shredFiles 覆写并永久删除文件，无论回收站设置如何都不会进入回收站
+// 操作不可恢复，因此只有--yes才能跳过确认，--force不会跳过
+func shredFiles(files []string, opts filesystem.ShredOptions, plugins *plugin.Runner, yes, quiet bool) error {
+	if opts.Passes <= 0 {
+		return errors.NewError(errors.ErrTypeUsage, "覆写遍数必须大于0", nil)
+	}
+
+	var targets []string
+	var skipped []report.Item
+	for _, file := range files {
+		// 系统关键文件即使指定了--force也不允许粉碎
+		if isSystemFile(file) {
+			fmt.Fprintf(os.Stderr, "🛡️  拒绝粉碎系统文件: %s\n", file)
+			skipped = append(skipped, report.Item{Path: file, Outcome: report.OutcomeSkipped, Reason: "系统文件"})
+			continue
+		}
+		if info, err := filesystem.Lstat(file); err == nil && info.Mode()&os.ModeSymlink != 0 && !opts.FollowLinks {
+			fmt.Fprintf(os.Stderr, "⚠️  跳过符号链接 '%s'，使用 --shred-links 粉碎其指向的文件\n", file)
+			skipped = append(skipped, report.Item{Path: file, Outcome: report.OutcomeSkipped, Reason: "符号链接"})
+			continue
+		}
+		targets = append(targets, file)
+	}
+
+	if len(targets) == 0 {
+		return errors.NewError(errors.ErrTypeValidation, "没有可以粉碎的文件", nil)
+	}
+
+	if !yes {
+		// safe_mode为strict时与永久删除相同，必须完整输入DELETE
+		requireWord := safeModePolicy().RequireDeleteWord
+		presentDeletionPlan(targets)
+		if requireWord {
+			fmt.Printf("🔥 %s", i18n.Plural("shred.confirm_word", len(targets), opts.Passes))
+		} else {
+			fmt.Printf("🔥 %s", i18n.Plural("shred.confirm", len(targets), opts.Passes))
+		}
+		confirmed, err := confirmDangerousAction(requireWord)
+		if err != nil {
+			log.Printf("读取输入时出错: %v", err)
+			fmt.Println("❌ " + i18n.T("common.input_failed"))
+			return nil
+		}
+		if !confirmed {
+			fmt.Println("❌ " + i18n.T("common.cancelled"))
+			return nil
+		}
+	}
+
+	successCount := 0
+	failures := errors.NewErrorCollector()
+	receipt := newReceipt("shred")
+	for _, item := range skipped {
+		receipt.Add(item)
+	}
+	for _, file := range targets {
+		if proceed, err := checkProtectionPlugins(plugins, file, yes); !proceed {
+			receipt.Add(skippedByPlugin(file, err))
+			if err != nil {
+				failures.Add(file, err)
+				if !quiet {
+					fmt.Fprintf(os.Stderr, "🛡️  %v\n", err)
+				}
+			}
+			continue
+		}
+		item := report.Item{Path: file}
+		if info, err := filesystem.Lstat(file); err == nil {
+			item.Size = info.Size()
+			item.IsDirectory = info.IsDir()
+			if info.IsDir() && receipt != nil {
+				item.Size = filesystem.TreeSize(file)
+			}
+		}
+		if err := filesystem.ShredPath(file, opts); err != nil {
+			failures.Add(file, err)
+			item.Outcome, item.Reason = report.OutcomeFailed, err.Error()
+			receipt.Add(item)
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "❌ 粉碎失败 '%s': %v\n", file, err)
+			}
+			continue
+		}
+		item.Outcome = report.OutcomeShredded
+		receipt.Add(item)
+		successCount++
+		if !quiet {
+			fmt.Printf("🔥 %s\n", i18n.Plural("shred.file", opts.Passes, file))
 		}
+	}
+
+	if !quiet {
 		if successCount > 0 {
-			fmt.Printf("✅ 成功删除 %d 个项目到回收站\n", successCount)
+			fmt.Printf("✅ %s\n", i18n.Plural("shred.done", successCount, opts.Passes))
 		}
-		if errorCount > 0 {
-			fmt.Printf("❌ %d 个项目删除失败\n", errorCount)
+		if failures.HasErrors() {
+			fmt.Printf("❌ %s\n", failures.Summary())
+		}
+	}
+	for _, failure := range failures.Errors() {
+		telemetry.AddError(failure.Err)
+	}
+	saveReceipt(nil, receipt, quiet)
+
+	if failures.HasErrors() {
+		return errors.NewError(failures.Type(), "部分文件粉碎失败", nil)
+	}
+	return nil
+}
+
+// deleteTarget 返回实际要删除的路径：符号链接默认删除链接本身，dereference为true时改为其最终指向的目标
+// 链接失效时无法解析目标，返回错误
+func deleteTarget(path string, dereference bool) (string, error) {
+	if !dereference {
+		return path, nil
+	}
+	if info, err := filesystem.Lstat(path); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return path, nil
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// confirmSpecialFile 命名管道等特殊文件无法移入回收站时，询问是否只记录删除信息并直接删除
+func confirmSpecialFile(err error, force bool) bool {
+	if force {
+		return true
+	}
+	fmt.Printf("⚠️  %v\n   %s\n", err, errors.GetErrorMessage(err))
+	fmt.Print("   是否继续? [y/N]: ")
+	confirmed, err := confirmDangerousAction(false)
+	if err != nil {
+		fmt.Println()
+		return false
+	}
+	return confirmed
+}
+
+// removePermanently 不经过回收站直接删除文件，每个文件都写入日志以便审计
+// 与粉碎相同，只有--yes才能跳过确认，且确认时必须完整输入DELETE
+func removePermanently(files []string, plugins *plugin.Runner, yes, quiet bool) error {
+	var targets []string
+	var skipped []report.Item
+	for _, file := range files {
+		// 系统关键文件即使指定了--force也不允许永久删除
+		if isSystemFile(file) {
+			fmt.Fprintf(os.Stderr, "🛡️  拒绝永久删除系统文件: %s\n", file)
+			skipped = append(skipped, report.Item{Path: file, Outcome: report.OutcomeSkipped, Reason: "系统文件"})
+			continue
+		}
+		targets = append(targets, file)
+	}
+
+	if len(targets) == 0 {
+		return errors.NewError(errors.ErrTypeValidation, "没有可以永久删除的文件", nil)
+	}
+
+	if !yes {
+		presentDeletionPlan(targets)
+		fmt.Printf("⚠️  %s", i18n.Plural("purge.confirm", len(targets)))
+		confirmed, err := confirmCritical("DELETE")
+		if err != nil {
+			log.Printf("读取输入时出错: %v", err)
+			fmt.Println("❌ " + i18n.T("common.input_failed"))
+			return nil
+		}
+		if !confirmed {
+			fmt.Println("❌ " + i18n.T("common.cancelled"))
+			return nil
+		}
+	}
+
+	successCount := 0
+	failures := errors.NewErrorCollector()
+	receipt := newReceipt("purge")
+	for _, item := range skipped {
+		receipt.Add(item)
+	}
+	for _, file := range targets {
+		if proceed, err := checkProtectionPlugins(plugins, file, yes); !proceed {
+			receipt.Add(skippedByPlugin(file, err))
+			if err != nil {
+				failures.Add(file, err)
+				if !quiet {
+					fmt.Fprintf(os.Stderr, "🛡️  %v\n", err)
+				}
+			}
+			continue
+		}
+		item := report.Item{Path: file}
+		if info, err := filesystem.Lstat(file); err == nil {
+			item.Size = info.Size()
+			item.IsDirectory = info.IsDir()
+			if info.IsDir() && receipt != nil {
+				item.Size = filesystem.TreeSize(file)
+			}
+		}
+		if err := os.RemoveAll(file); err != nil {
+			logger.Errorf("永久删除失败（未经过回收站）: %s: %v", file, err)
+			failures.Add(file, err)
+			item.Outcome, item.Reason = report.OutcomeFailed, err.Error()
+			receipt.Add(item)
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "❌ 永久删除失败 '%s': %v\n", file, err)
+			}
+			continue
+		}
+		logger.Infof("永久删除（未经过回收站，无法恢复）: %s", file)
+		item.Outcome, item.Reason = report.OutcomeDeleted, "未经过回收站，无法恢复"
+		receipt.Add(item)
+		successCount++
+		if !quiet {
+			fmt.Printf("💥 已永久删除: %s\n", file)
 		}
 	}
 
-	if errorCount > 0 {
-		return fmt.Errorf("部分文件删除失败")
+	if !quiet {
+		if successCount > 0 {
+			fmt.Printf("✅ %s\n", i18n.Plural("purge.done", successCount))
+		}
+		if failures.HasErrors() {
+			fmt.Printf("❌ %s\n", failures.Summary())
+		}
 	}
+	for _, failure := range failures.Errors() {
+		telemetry.AddError(failure.Err)
+	}
+	saveReceipt(nil, receipt, quiet)
 
+	if failures.HasErrors() {
+		return errors.NewError(failures.Type(), "部分文件永久删除失败", nil)
+	}
 	return nil
 }
 
+// loadProtectionPlugins 加载保护规则插件，没有插件时返回nil
+func loadProtectionPlugins(quiet bool) *plugin.Runner {
+	runner, err := plugin.NewRunner(config.Current())
+	if err != nil && !quiet {
+		fmt.Fprintf(os.Stderr, "⚠️  加载保护规则插件失败: %v\n", err)
+	}
+	return runner
+}
+
+// checkProtectionPlugins 调用保护规则插件，返回false表示不删除该文件
+// 插件阻止或执行失败时返回ErrTypeBlocked错误；插件要求确认时询问用户，assumeYes为true时视为同意
+func checkProtectionPlugins(runner *plugin.Runner, file string, assumeYes bool) (bool, error) {
+	decision := runner.Check(file)
+
+	switch decision.Decision {
+	case plugin.DecisionBlock:
+		return false, errors.NewBlockedError(file, decision.Plugin, decision.Reason)
+	case plugin.DecisionError:
+		return false, errors.NewBlockedError(file, decision.Plugin, "插件执行失败: "+decision.Reason)
+	case plugin.DecisionConfirm:
+		if assumeYes {
+			return true, nil
+		}
+		reason := ""
+		if decision.Reason != "" {
+			reason = fmt.Sprintf(" (%s)", decision.Reason)
+		}
+		fmt.Printf("🔌 插件 %s 要求确认删除 '%s'%s [y/N]: ", decision.Plugin, file, reason)
+		confirmed, err := confirmDangerousAction(false)
+		if err != nil {
+			fmt.Println("❌ " + i18n.T("delete.input_skip"))
+			return false, nil
+		}
+		return confirmed, nil
+	default:
+		return true, nil
+	}
+}
+
 // isSystemFile 检查是否为系统文件
 func isSystemFile(path string) bool {
+	return systemFileReason(path) != ""
+}
+
+// systemFileReason 返回路径被视为系统文件的原因，不是系统文件时返回空
+func systemFileReason(path string) string {
 	// 检查文件属性
 	_, err := os.Stat(path)
 	if err != nil {
-		return false
+		return ""
 	}
 	
 	// 检查文件名
@@ -269,7 +920,7 @@ func isSystemFile(path string) bool {
 	
 	for _, sysFile := range systemFiles {
 		if name == sysFile {
-			return true
+			return fmt.Sprintf("系统文件名 %s", sysFile)
 		}
 	}
 	
@@ -282,9 +933,375 @@ func isSystemFile(path string) bool {
 	
 	for _, sysDir := range systemDirs {
 		if strings.Contains(dir, sysDir) {
-			return true
+			return fmt.Sprintf("位于系统目录 %s 中", sysDir)
 		}
 	}
 	
-	return false
+	return ""
+}
+
+// rotateTrash 删除后按trash.rotation轮转回收站，本次删除的项目不会被轮转掉
+func rotateTrash(manager filesystem.TrashManager, since time.Time, quiet bool) {
+	result, err := filesystem.RotateTrash(manager, since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  回收站轮转失败: %v\n", err)
+		return
+	}
+	for _, message := range result.Errors {
+		fmt.Fprintf(os.Stderr, "⚠️  轮转删除失败 %s\n", message)
+	}
+	reportPruneChecks(result.Checks, quiet)
+	if len(result.Removed) > 0 && !quiet {
+		fmt.Printf("♻️  %s\n", i18n.Plural("rotate.done", len(result.Removed), filesystem.FormatFileSize(result.Bytes), result.Policy))
+	}
+	if !quiet {
+		printPruneVerification(result.Checks)
+	}
+}
+
+// purgeTrashedFiles 对已在回收站中的文件提供永久删除
+func purgeTrashedFiles(manager filesystem.TrashManager, files []string, force, dryRun, quiet bool) {
+	requireWord := safeModePolicy().RequireDeleteWord
+	for _, file := range files {
+		if dryRun {
+			fmt.Printf("🔍 '%s' 已在回收站中，将被永久删除\n", file)
+			continue
+		}
+
+		if !force {
+			if requireWord {
+				fmt.Printf("⚠️  '%s' 已在回收站中，输入 DELETE 确认永久删除: ", file)
+			} else {
+				fmt.Printf("⚠️  '%s' 已在回收站中，是否永久删除? [y/N]: ", file)
+			}
+			confirmed, err := confirmDangerousAction(requireWord)
+			if err != nil {
+				fmt.Println("❌ " + i18n.T("delete.input_skip"))
+				continue
+			}
+			if !confirmed {
+				if !quiet {
+					fmt.Println("⏭️  " + i18n.T("delete.skipped", file))
+				}
+				continue
+			}
+		}
+
+		if err := filesystem.RemoveFromTrash(manager, file); err != nil {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "❌ 永久删除失败 '%s': %v\n", file, err)
+			}
+			continue
+		}
+		if !quiet {
+			fmt.Printf("🔥 已永久删除: %s\n", file)
+		}
+	}
+}
+
+// printStatError 输出无法访问路径的原因，区分路径不存在与某一级目录无权访问
+func printStatError(path string, statErr error) {
+	err := filesystem.ResolveStatError(path, statErr)
+	switch {
+	case errors.IsType(err, errors.ErrTypeFileNotFound):
+		fmt.Fprintf(os.Stderr, "⚠️  警告: 文件不存在 '%s'\n", path)
+	case errors.IsType(err, errors.ErrTypePermissionDenied):
+		fmt.Fprintf(os.Stderr, "⚠️  警告: 无法访问 '%s': %s\n", path, errors.GetErrorMessage(err))
+	default:
+		fmt.Fprintf(os.Stderr, "⚠️  警告: 无法访问文件 '%s': %v\n", path, err)
+	}
+}
+
+// newMalwareScanner 创建恶意软件扫描器，未启用扫描时返回nil
+// 启用了扫描却找不到扫描命令时本次调用提示一次并且不扫描，静默模式下同样提示
+func newMalwareScanner(cfg *config.Config) security.MalwareScanner {
+	scanner, err := security.NewMalwareScanner(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v，本次不扫描\n   💡 %s\n", err, errors.GetErrorMessage(err))
+	}
+	return scanner
+}
+
+// safeModePolicy 返回security.safe_mode对应的行为，未加载配置时按normal处理
+func safeModePolicy() config.SafeModePolicy {
+	cfg := config.Current()
+	if cfg == nil {
+		return config.NewSafeModePolicy(config.SafeModeNormal)
+	}
+	return cfg.SafeModePolicy()
+}
+
+// protectedSELinuxLabel 启用security.check_selinux时，返回文件受保护的SELinux类型
+func protectedSELinuxLabel(path string) (string, bool) {
+	if cfg := config.Current(); cfg == nil || !cfg.Security.CheckSELinux {
+		return "", false
+	}
+	context, ok := filesystem.SELinuxContext(path)
+	if !ok {
+		return "", false
+	}
+	logger.Debugf("%s 的SELinux上下文: %s", path, context)
+	return security.ProtectedSELinuxType(context)
+}
+
+// checkGitRepos 提示要删除的项目中有未提交修改的已跟踪文件，删除.git目录时必须输入DELETE确认（-y除外）
+// security.git_awareness为off时不做任何检查；返回false表示用户取消了删除
+func checkGitRepos(files []string, yes, quiet bool) bool {
+	if cfg := config.Current(); cfg != nil && !cfg.GitAwarenessEnabled() {
+		return true
+	}
+
+	var roots, gitDirs []string
+	byRoot := make(map[string][]string)
+	for _, file := range files {
+		if security.IsGitDir(file) {
+			gitDirs = append(gitDirs, file)
+			continue
+		}
+		root, ok := security.GitRepoRoot(file)
+		if !ok {
+			continue
+		}
+		if _, seen := byRoot[root]; !seen {
+			roots = append(roots, root)
+		}
+		byRoot[root] = append(byRoot[root], file)
+	}
+
+	// git不可用或超时时不提示，不影响删除
+	if !quiet {
+		for _, root := range roots {
+			dirty, err := security.GitUncommitted(root, byRoot[root])
+			if err != nil || len(dirty) == 0 {
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", i18n.Plural("git.uncommitted", len(dirty), len(byRoot[root]), root))
+			for _, file := range dirty {
+				fmt.Fprintf(os.Stderr, "   %s\n", file)
+			}
+		}
+	}
+
+	if len(gitDirs) == 0 || yes {
+		return true
+	}
+	for _, dir := range gitDirs {
+		fmt.Printf("⚠️  '%s' 是Git仓库的.git目录，删除后仓库的提交历史和未推送的分支都将丢失\n", dir)
+	}
+	fmt.Print("输入 DELETE 确认删除: ")
+	if confirmed, _ := confirmCritical("DELETE"); !confirmed {
+		fmt.Println("❌ " + i18n.T("common.cancelled"))
+		return false
+	}
+	return true
+}
+
+// printNetworkPlan 提示网络文件系统上的项目将进入的回收站，verbose时列出降级模式的哈希和超时设置
+func printNetworkPlan(manager filesystem.TrashManager, file, absPath string, verbose bool) {
+	plan, ok := filesystem.PlanNetwork(manager, absPath)
+	if !ok {
+		return
+	}
+	where := fmt.Sprintf("'%s' 位于网络文件系统 %s (%s)", file, plan.Mount.MountPoint, plan.Mount.FSType)
+	switch {
+	case plan.ShareTrash != "":
+		fmt.Fprintf(os.Stderr, "🌐 %s，将移动到同一共享上的回收站 %s\n", where, plan.ShareTrash)
+	case plan.Copy:
+		fmt.Fprintf(os.Stderr, "🌐 %s，移动到本地回收站需要跨网络复制，可能较慢\n", where)
+	default:
+		fmt.Fprintf(os.Stderr, "🌐 %s，回收站位于同一文件系统\n", where)
+	}
+	if !verbose {
+		return
+	}
+	hashing := "不计算内容哈希"
+	if cfg := config.Current(); cfg != nil && cfg.Trash.NetworkHash {
+		hashing = "按trash.hash_on_delete计算内容哈希"
+	}
+	timeout := "不限制"
+	if plan.Timeout > 0 {
+		timeout = plan.Timeout.String()
+	}
+	fmt.Fprintf(os.Stderr, "   降级模式: %s，操作超时 %s，元数据记录文件系统类型 %s\n", hashing, timeout, plan.Mount.FSType)
+}
+
+// remoteMount 判断路径是否位于远程文件系统，测试时可替换为桩函数
+var remoteMount = filesystem.RemoteMount
+
+// remoteAction 按policy决定远程文件系统上的项目如何处理，本地项目返回空字符串
+// refuse时返回validation错误，delete时返回RemoteDelete，其余返回RemoteWarn
+func remoteAction(file, absPath, policy string) (string, error) {
+	mount, remote := remoteMount(absPath)
+	if !remote {
+		return "", nil
+	}
+	switch policy {
+	case config.RemoteRefuse:
+		return "", errors.NewValidationError(file, fmt.Sprintf("位于远程文件系统 %s (%s)", mount.MountPoint, mount.FSType))
+	case config.RemoteDelete:
+		return config.RemoteDelete, nil
+	default:
+		return config.RemoteWarn, nil
+	}
+}
+
+// remoteFilesystemPolicy 返回security.remote_filesystems配置的处理方式，默认提示后移动到回收站
+func remoteFilesystemPolicy() string {
+	cfg := config.Current()
+	if cfg == nil || cfg.Security.RemoteFilesystems == "" {
+		return config.RemoteWarn
+	}
+	return strings.ToLower(cfg.Security.RemoteFilesystems)
+}
+
+// expandDeleteArgs 规范化命令行参数并展开通配符，跳过无效或不存在的路径
+func expandDeleteArgs(args []string, level outputLevel) []string {
+	quiet := level == levelMinimal
+	var filesToDelete []string
+	for _, arg := range args {
+		// 规范化粘贴的路径（引号、file:// URL、~、末尾分隔符），之后的安全校验均针对规范化的结果
+		normalized, notes, err := utils.NormalizePathArg(arg)
+		if err != nil {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "⚠️  警告: %v\n", err)
+			}
+			continue
+		}
+		for _, note := range notes {
+			logger.Debugf("路径参数 %q: %s", arg, note)
+			if level >= levelDebug {
+				fmt.Fprintf(os.Stderr, "🔧 %s: %s\n", arg, note)
+			}
+		}
+
+		// 清理路径，防止路径遍历攻击
+		cleanArg := filepath.Clean(normalized)
+		
+		// 验证路径长度
+		if len(cleanArg) > 4096 {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "⚠️  警告: 路径过长 '%s'\n", cleanArg)
+			}
+			continue
+		}
+		
+		// 检查路径是否包含空字符
+		if strings.ContainsRune(cleanArg, 0) {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "⚠️  警告: 路径包含非法字符 '%s'\n", cleanArg)
+			}
+			continue
+		}
+		
+		matches, err := filepath.Glob(cleanArg)
+		if err != nil {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "⚠️  警告: 无法处理路径 '%s': %v\n", cleanArg, err)
+			}
+			continue
+		}
+
+		if len(matches) == 0 {
+			// 没有匹配的文件，检查是否是直接路径（包括失效的符号链接）
+			if _, err := filesystem.Lstat(cleanArg); err == nil {
+				filesToDelete = append(filesToDelete, cleanArg)
+			} else {
+				if !quiet {
+					printStatError(cleanArg, err)
+				}
+			}
+		} else {
+			filesToDelete = append(filesToDelete, matches...)
+		}
+	}
+	return filesToDelete
+}
+
+// resolveByID 将 --by-id 的参数 <目录> <ID>... 解析为文件路径，
+// 用于删除含换行、终端转义序列或无效编码等无法在命令行中输入的文件名
+func resolveByID(args []string) ([]string, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("--by-id 需要目录和文件ID: delguard rm --by-id <目录> <ID>...")
+	}
+	dir := filepath.Clean(args[0])
+	var paths []string
+	for _, id := range args[1:] {
+		path, err := filesystem.FindByID(dir, id)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debugf("文件ID %s 对应 %q", id, path)
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// checkDirectoryTarget 按rm的习惯检查要删除的目录：空目录可以直接删除，非空目录需要 -r，
+// --empty-only 时与rmdir相同，拒绝所有非空目录
+func checkDirectoryTarget(path string, recursive, emptyOnly bool) error {
+	empty, err := filesystem.IsEmptyDir(path)
+	if err != nil {
+		return errors.FromOS(fmt.Sprintf("读取目录 %s 失败", path), err)
+	}
+	switch {
+	case empty:
+		return nil
+	case emptyOnly:
+		return errors.NewValidationError(path, "目录不为空，--empty-only 只删除空目录")
+	case !recursive:
+		return errors.NewValidationError(path, "目录不为空，使用 -r 选项递归删除")
+	}
+	return nil
+}
+
+// loadDeleteFilter 读取 --exclude 和 --exclude-from 的模式，--exclude-from 的文件先于 --exclude 加入
+func loadDeleteFilter(cmd *cobra.Command) (*filesystem.FileFilter, error) {
+	patterns, _ := cmd.Flags().GetStringArray("exclude")
+	files, _ := cmd.Flags().GetStringArray("exclude-from")
+	filter := filesystem.NewFileFilter()
+	for _, file := range files {
+		if err := filter.LoadFile(file); err != nil {
+			if os.IsNotExist(err) {
+				return nil, errors.NewError(errors.ErrTypeFileNotFound, fmt.Sprintf("排除文件不存在: %s", file), err)
+			}
+			return nil, errors.NewError(errors.ErrTypeUsage, "读取排除文件失败", err)
+		}
+	}
+	for _, pattern := range patterns {
+		if err := filter.Add(pattern); err != nil {
+			return nil, errors.NewError(errors.ErrTypeUsage, "--exclude 模式错误", err)
+		}
+	}
+	return filter, nil
+}
+
+// applyDeleteFilter 按过滤器展开要移入回收站和要永久删除的目标，返回被排除的条目数
+func applyDeleteFilter(filter *filesystem.FileFilter, validFiles, remoteFiles []string) ([]string, []string, int, error) {
+	trashPlan, err := filesystem.ApplyExcludes(validFiles, filter)
+	if err != nil {
+		return nil, nil, 0, errors.FromOS("展开排除规则失败", err)
+	}
+	remotePlan, err := filesystem.ApplyExcludes(remoteFiles, filter)
+	if err != nil {
+		return nil, nil, 0, errors.FromOS("展开排除规则失败", err)
+	}
+	return trashPlan.Targets, remotePlan.Targets, trashPlan.Excluded + remotePlan.Excluded, nil
+}
+
+// dedupeTargets 去掉指向同一文件的重复参数和位于其他目标目录中的项目，详细模式下说明被忽略的参数
+func dedupeTargets(files []string, verbose bool) []string {
+	kept, skipped := filesystem.DedupeTargets(files)
+	if verbose {
+		for _, target := range skipped {
+			switch {
+			case target.Reason == filesystem.TargetNested:
+				fmt.Printf("🔁 跳过 '%s': 位于同样要删除的目录 '%s' 中\n", target.Path, target.Kept)
+			case target.Path == target.Kept:
+				fmt.Printf("🔁 跳过重复的参数 '%s'\n", target.Path)
+			default:
+				fmt.Printf("🔁 跳过 '%s': 与 '%s' 是同一个文件\n", target.Path, target.Kept)
+			}
+		}
+	}
+	return kept
 }
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"delguard/internal/config"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestProtectedSELinuxLabelFollowsConfig(t *testing.T) {
+	initTempConfig(t)
+	path := writeTestFile(t, t.TempDir(), "shadow")
+	if err := unix.Lsetxattr(path, "security.selinux", []byte("system_u:object_r:shadow_t:s0\x00"), 0); err != nil {
+		t.Skipf("cannot set security.selinux here: %v", err)
+	}
+
+	if selinuxType, ok := protectedSELinuxLabel(path); ok {
+		t.Errorf("check_selinux off: protectedSELinuxLabel() = %q, want no check", selinuxType)
+	}
+
+	config.Current().Security.CheckSELinux = true
+	if selinuxType, ok := protectedSELinuxLabel(path); !ok || selinuxType != "shadow_t" {
+		t.Errorf("check_selinux on: protectedSELinuxLabel() = %q, %v; want shadow_t", selinuxType, ok)
+	}
+}
@@ -0,0 +1,525 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/filesystem"
+	"delguard/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// useFakeTrash 让命令使用临时目录中的FakeTrashManager
+func useFakeTrash(t *testing.T) *filesystem.FakeTrashManager {
+	t.Helper()
+	manager, err := filesystem.NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := newTrashManager
+	newTrashManager = func() (filesystem.TrashManager, error) { return manager, nil }
+	t.Cleanup(func() { newTrashManager = saved })
+	return manager
+}
+
+func assertTrashEmpty(t *testing.T, manager filesystem.TrashManager) {
+	t.Helper()
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		t.Fatalf("ListTrashFiles: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("shred created %d trash entries", len(files))
+	}
+}
+
+func writeTestFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// 临时目录位于/tmp（macOS上为/var）下，会被runDelete的系统路径检查拒绝，
+// 因此直接调用校验之后的shredFiles
+
+func TestShredCreatesNoTrashEntry(t *testing.T) {
+	initTempConfig(t)
+	manager := useFakeTrash(t)
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "secret.txt")
+	tree := filepath.Join(dir, "tree")
+	if err := os.MkdirAll(filepath.Join(tree, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(tree, "nested"), "inner.txt")
+
+	if err := shredFiles([]string{file, tree}, filesystem.ShredOptions{Passes: 2}, nil, true, true); err != nil {
+		t.Fatalf("shredFiles: %v", err)
+	}
+	for _, path := range []string{file, tree} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists: %v", path, err)
+		}
+	}
+	assertTrashEmpty(t, manager)
+}
+
+func TestShredDeclinedKeepsFile(t *testing.T) {
+	initTempConfig(t)
+	manager := useFakeTrash(t)
+	file := writeTestFile(t, t.TempDir(), "secret.txt")
+
+	withStdin(t, "n\n", func() {
+		if err := shredFiles([]string{file}, filesystem.ShredOptions{Passes: 1}, nil, false, true); err != nil {
+			t.Fatalf("shredFiles: %v", err)
+		}
+	})
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("declined shred removed the file: %v", err)
+	}
+	assertTrashEmpty(t, manager)
+}
+
+func TestShredRejectsZeroPasses(t *testing.T) {
+	file := writeTestFile(t, t.TempDir(), "secret.txt")
+	if err := shredFiles([]string{file}, filesystem.ShredOptions{}, nil, true, true); err == nil {
+		t.Fatal("shredFiles accepted zero passes")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("file was removed: %v", err)
+	}
+}
+
+func TestShredSymlinks(t *testing.T) {
+	tests := []struct {
+		name       string
+		shredLinks bool
+		wantErr    bool
+		wantTarget bool
+	}{
+		{name: "refused by default", wantErr: true, wantTarget: true},
+		{name: "shred-links shreds the target", shredLinks: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTempConfig(t)
+			manager := useFakeTrash(t)
+			dir := t.TempDir()
+			target := writeTestFile(t, dir, "target.txt")
+			link := filepath.Join(dir, "link")
+			if err := os.Symlink(target, link); err != nil {
+				t.Skipf("symlinks unavailable: %v", err)
+			}
+
+			opts := filesystem.ShredOptions{Passes: 1, FollowLinks: tt.shredLinks}
+			err := shredFiles([]string{link}, opts, nil, true, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("shredFiles error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			_, linkErr := os.Lstat(link)
+			_, targetErr := os.Stat(target)
+			if tt.wantTarget {
+				if linkErr != nil || targetErr != nil {
+					t.Errorf("refused shred touched the link (%v) or target (%v)", linkErr, targetErr)
+				}
+			} else if !os.IsNotExist(linkErr) || !os.IsNotExist(targetErr) {
+				t.Errorf("link err = %v, target err = %v, want both removed", linkErr, targetErr)
+			}
+			assertTrashEmpty(t, manager)
+		})
+	}
+}
+
+func TestDeleteTarget(t *testing.T) {
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "target.txt")
+	link := filepath.Join(dir, "link")
+	chain := filepath.Join(dir, "chain")
+	broken := filepath.Join(dir, "broken")
+	if err := os.Symlink(file, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	for from, to := range map[string]string{chain: link, broken: filepath.Join(dir, "missing")} {
+		if err := os.Symlink(to, from); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		dereference bool
+		want        string
+		wantErr     bool
+	}{
+		{name: "regular file", path: file, want: file},
+		{name: "regular file with -L", path: file, dereference: true, want: file},
+		{name: "link itself by default", path: link, want: link},
+		{name: "link target with -L", path: link, dereference: true, want: resolved},
+		{name: "chained link resolves to the final target", path: chain, dereference: true, want: resolved},
+		{name: "broken link itself by default", path: broken, want: broken},
+		{name: "broken link with -L", path: broken, dereference: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := deleteTarget(tt.path, tt.dereference)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deleteTarget error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("deleteTarget = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// enableReceipts 在测试配置中开启操作回执，返回回执目录
+func enableReceipts(t *testing.T) string {
+	t.Helper()
+	config.Current().Logging.ReportEnabled = true
+	return config.GetReportDir()
+}
+
+func TestRemovePermanentlyBypassesTrashAndWritesReceipt(t *testing.T) {
+	initTempConfig(t)
+	reportDir := enableReceipts(t)
+	manager := useFakeTrash(t)
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "huge.tmp")
+	tree := filepath.Join(dir, "cache")
+	if err := os.MkdirAll(tree, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, tree, "chunk.bin")
+
+	if err := removePermanently([]string{file, tree}, nil, true, true); err != nil {
+		t.Fatalf("removePermanently: %v", err)
+	}
+	for _, path := range []string{file, tree} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists: %v", path, err)
+		}
+	}
+	assertTrashEmpty(t, manager)
+
+	receipts, err := report.List(reportDir, time.Time{})
+	if err != nil || len(receipts) != 1 {
+		t.Fatalf("report.List = %d receipts, %v; want 1", len(receipts), err)
+	}
+	receipt := receipts[0]
+	if receipt.Operation != "purge" || receipt.Summary.Deleted != 2 {
+		t.Errorf("receipt = %s with summary %+v, want purge of 2 items", receipt.Operation, receipt.Summary)
+	}
+	for _, item := range receipt.Items {
+		if item.Outcome != report.OutcomeDeleted || !strings.Contains(item.Reason, "无法恢复") {
+			t.Errorf("receipt item %s = %s (%s), want a permanent deletion", item.Path, item.Outcome, item.Reason)
+		}
+	}
+}
+
+func TestRemovePermanentlyRequiresTypedDelete(t *testing.T) {
+	for _, input := range []string{"y\n", "delete\n", "\n"} {
+		t.Run(strings.TrimSpace(input), func(t *testing.T) {
+			initTempConfig(t)
+			manager := useFakeTrash(t)
+			file := writeTestFile(t, t.TempDir(), "huge.tmp")
+
+			withStdin(t, input, func() {
+				if err := removePermanently([]string{file}, nil, false, true); err != nil {
+					t.Fatalf("removePermanently: %v", err)
+				}
+			})
+			if _, err := os.Stat(file); err != nil {
+				t.Errorf("answer %q removed the file: %v", input, err)
+			}
+			assertTrashEmpty(t, manager)
+		})
+	}
+
+	initTempConfig(t)
+	file := writeTestFile(t, t.TempDir(), "huge.tmp")
+	withStdin(t, "DELETE\n", func() {
+		if err := removePermanently([]string{file}, nil, false, true); err != nil {
+			t.Fatalf("removePermanently: %v", err)
+		}
+	})
+	if _, err := os.Lstat(file); !os.IsNotExist(err) {
+		t.Errorf("typing DELETE kept the file: %v", err)
+	}
+}
+
+func TestRemovePermanentlyRefusesSystemFiles(t *testing.T) {
+	initTempConfig(t)
+	dir := t.TempDir()
+	system := writeTestFile(t, dir, "desktop.ini")
+
+	if err := removePermanently([]string{system}, nil, true, true); err == nil {
+		t.Error("removePermanently of only system files succeeded")
+	}
+	if _, err := os.Stat(system); err != nil {
+		t.Errorf("system file was removed: %v", err)
+	}
+
+	other := writeTestFile(t, dir, "notes.txt")
+	if err := removePermanently([]string{system, other}, nil, true, true); err != nil {
+		t.Fatalf("removePermanently: %v", err)
+	}
+	if _, err := os.Stat(system); err != nil {
+		t.Errorf("system file was removed alongside other files: %v", err)
+	}
+	if _, err := os.Lstat(other); !os.IsNotExist(err) {
+		t.Errorf("regular file was kept: %v", err)
+	}
+}
+
+func TestRemoteAction(t *testing.T) {
+	saved := remoteMount
+	remoteMount = func(path string) (filesystem.MountInfo, bool) {
+		if strings.HasPrefix(path, "/mnt/nfs") {
+			return filesystem.MountInfo{MountPoint: "/mnt/nfs", FSType: "nfs4", Remote: true}, true
+		}
+		return filesystem.MountInfo{}, false
+	}
+	defer func() { remoteMount = saved }()
+
+	tests := []struct {
+		name    string
+		path    string
+		policy  string
+		want    string
+		wantErr bool
+	}{
+		{name: "local ignores policy", path: "/home/user/a.txt", policy: config.RemoteRefuse, want: ""},
+		{name: "warn", path: "/mnt/nfs/a.txt", policy: config.RemoteWarn, want: config.RemoteWarn},
+		{name: "unknown policy warns", path: "/mnt/nfs/a.txt", policy: "copy", want: config.RemoteWarn},
+		{name: "delete", path: "/mnt/nfs/a.txt", policy: config.RemoteDelete, want: config.RemoteDelete},
+		{name: "refuse", path: "/mnt/nfs/a.txt", policy: config.RemoteRefuse, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := remoteAction("a.txt", tt.path, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("remoteAction error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("remoteAction = %q, want %q", got, tt.want)
+			}
+			if err == nil {
+				return
+			}
+			if kind := errors.KindOf(err); kind != "validation" {
+				t.Errorf("refusal kind = %s, want validation", kind)
+			}
+			if !strings.Contains(err.Error(), "nfs4") {
+				t.Errorf("refusal %q does not name the filesystem", err)
+			}
+		})
+	}
+}
+
+func TestRemoteFilesystemPolicy(t *testing.T) {
+	initTempConfig(t)
+	if got := remoteFilesystemPolicy(); got != config.RemoteWarn {
+		t.Errorf("default policy = %q, want %q", got, config.RemoteWarn)
+	}
+	config.Current().Security.RemoteFilesystems = "Refuse"
+	if got := remoteFilesystemPolicy(); got != config.RemoteRefuse {
+		t.Errorf("policy = %q, want %q", got, config.RemoteRefuse)
+	}
+}
+
+func TestResolveByID(t *testing.T) {
+	dir := t.TempDir()
+	first := writeTestFile(t, dir, "first.txt")
+	second := writeTestFile(t, dir, "second.txt")
+	firstID, ok := filesystem.FileIDString(first)
+	if !ok {
+		t.Skip("file IDs unavailable")
+	}
+	secondID, _ := filesystem.FileIDString(second)
+
+	paths, err := resolveByID([]string{dir + string(filepath.Separator), firstID, secondID})
+	if err != nil {
+		t.Fatalf("resolveByID: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != first || paths[1] != second {
+		t.Errorf("resolveByID = %q, want %q and %q", paths, first, second)
+	}
+	if _, err := resolveByID([]string{dir}); err == nil {
+		t.Error("resolveByID without an id succeeded")
+	}
+	if _, err := resolveByID([]string{dir, firstID, "999999999:1"}); err == nil {
+		t.Error("resolveByID with an unknown id succeeded")
+	}
+}
+
+func TestDedupeTargetsReportsSkippedArguments(t *testing.T) {
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "report.txt")
+	nested := filepath.Join(dir, "sub", "inner.txt")
+	if err := os.MkdirAll(filepath.Dir(nested), 0755); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{file, file, nested, filepath.Join(dir, "sub")}
+
+	var kept []string
+	output := captureStdout(t, func() { kept = dedupeTargets(args, true) })
+	if want := []string{file, filepath.Join(dir, "sub")}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("dedupeTargets() = %q, want %q", kept, want)
+	}
+	for _, want := range []string{"跳过重复的参数 '" + file + "'", "位于同样要删除的目录 '" + filepath.Join(dir, "sub") + "' 中"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("verbose output is missing %q:\n%s", want, output)
+		}
+	}
+
+	if output := captureStdout(t, func() { dedupeTargets(args, false) }); output != "" {
+		t.Errorf("non-verbose output = %q, want nothing", output)
+	}
+}
+
+func TestCheckDirectoryTarget(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	full := filepath.Join(dir, "full")
+	if err := os.Mkdir(full, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, full, "kept.txt")
+
+	tests := []struct {
+		name      string
+		path      string
+		recursive bool
+		emptyOnly bool
+		wantErr   string
+	}{
+		{name: "empty directory without -r", path: empty},
+		{name: "empty directory with -r", path: empty, recursive: true},
+		{name: "empty directory with --empty-only", path: empty, emptyOnly: true},
+		{name: "non-empty directory without -r", path: full, wantErr: "-r"},
+		{name: "non-empty directory with -r", path: full, recursive: true},
+		{name: "non-empty directory with --empty-only", path: full, emptyOnly: true, wantErr: "--empty-only"},
+		{name: "--empty-only wins over -r", path: full, recursive: true, emptyOnly: true, wantErr: "--empty-only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDirectoryTarget(tt.path, tt.recursive, tt.emptyOnly)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkDirectoryTarget() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.IsType(err, errors.ErrTypeValidation) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkDirectoryTarget() = %v, want a validation error mentioning %s", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := checkDirectoryTarget(filepath.Join(dir, "missing"), true, false); errors.IsType(err, errors.ErrTypeValidation) || err == nil {
+		t.Errorf("missing directory: checkDirectoryTarget() = %v, want a read error", err)
+	}
+}
+
+// filterCommand 返回只带有排除参数的命令，避免修改deleteCmd的全局标志
+func filterCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().StringArray("exclude", nil, "")
+	cmd.Flags().StringArray("exclude-from", nil, "")
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestLoadDeleteFilter(t *testing.T) {
+	dir := t.TempDir()
+	ignore := filepath.Join(dir, ".delguardignore")
+	if err := os.WriteFile(ignore, []byte("*.txt\n!keep.txt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// --exclude 在文件之后加入，最后匹配的规则生效
+	filter, err := loadDeleteFilter(filterCommand(t, "--exclude-from", ignore, "--exclude", "keep.*"))
+	if err != nil {
+		t.Fatalf("loadDeleteFilter: %v", err)
+	}
+	if !filter.Excluded("a.txt", false) || !filter.Excluded("keep.txt", false) || filter.Excluded("a.md", false) {
+		t.Error("--exclude did not override the negated pattern from --exclude-from")
+	}
+
+	filter, err = loadDeleteFilter(filterCommand(t))
+	if err != nil || !filter.Empty() {
+		t.Errorf("loadDeleteFilter without flags = %v, %v; want an empty filter", filter, err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want errors.ErrorType
+	}{
+		{"missing file", []string{"--exclude-from", filepath.Join(dir, "missing")}, errors.ErrTypeFileNotFound},
+		{"bad pattern", []string{"--exclude", "[broken"}, errors.ErrTypeUsage},
+		{"empty directory pattern", []string{"--exclude", "/"}, errors.ErrTypeUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadDeleteFilter(filterCommand(t, tt.args...)); !errors.IsType(err, tt.want) {
+				t.Errorf("loadDeleteFilter(%q) = %v, want type %v", tt.args, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDeleteFilterCountsBothPlans(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local")
+	remote := filepath.Join(dir, "remote")
+	for _, target := range []string{local, remote} {
+		if err := os.Mkdir(target, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, local, "a.o")
+	writeTestFile(t, local, "b.c")
+	writeTestFile(t, remote, "c.o")
+	writeTestFile(t, remote, "d.c")
+	filter := filesystem.NewFileFilter()
+	if err := filter.Add("*.o"); err != nil {
+		t.Fatal(err)
+	}
+
+	trash, permanent, excluded, err := applyDeleteFilter(filter, []string{local}, []string{remote})
+	if err != nil {
+		t.Fatalf("applyDeleteFilter: %v", err)
+	}
+	if want := []string{filepath.Join(local, "b.c")}; !reflect.DeepEqual(trash, want) {
+		t.Errorf("trash targets = %q, want %q", trash, want)
+	}
+	if want := []string{filepath.Join(remote, "d.c")}; !reflect.DeepEqual(permanent, want) {
+		t.Errorf("permanent targets = %q, want %q", permanent, want)
+	}
+	if excluded != 2 {
+		t.Errorf("excluded = %d, want 2", excluded)
+	}
+}
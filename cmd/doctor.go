@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"delguard/internal/config"
+	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
+	"delguard/internal/installer"
+	"delguard/internal/lock"
+	"delguard/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// 检查结果状态
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorCmd 自我诊断命令
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "检查DelGuard的安装和运行环境",
+	Long: `逐项检查DelGuard的运行环境，并为发现的问题给出修复建议：
+• 配置文件能否解析并通过校验
+• 回收站目录是否存在且可写
+• 回收站元数据是否一致
+• 回收站和当前目录是否位于NFS/SMB/SSHFS等网络文件系统，以及网络文件系统上的降级模式
+• 命令别名是否已安装并指向当前可执行文件
+• shell/PowerShell配置文件中的DelGuard配置块
+• 系统回收站是否可访问
+• 日志文件是否可写
+• 被中断的操作遗留的路径锁
+
+任一项检查失败时以非零状态退出。提交问题时可附上 --json 的输出。
+
+示例:
+  delguard doctor
+  delguard doctor --json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDoctor,
+}
+
+// doctorCheck 单项检查的结果
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // 修复建议
+}
+
+// doctorReport 诊断报告，附带版本和平台信息便于提交问题
+type doctorReport struct {
+	Version string        `json:"version"`
+	OS      string        `json:"os"`
+	Arch    string        `json:"arch"`
+	Checks  []doctorCheck `json:"checks"`
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("json", false, "以JSON格式输出")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	report := doctorReport{
+		Version: rootCmd.Version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	}
+	report.Checks = append(report.Checks, checkConfigFile())
+
+	manager, err := newTrashManager()
+	if err != nil {
+		report.Checks = append(report.Checks, doctorCheck{
+			Name:    "回收站",
+			Status:  doctorFail,
+			Message: fmt.Sprintf("初始化回收站管理器失败: %v", err),
+			Hint:    "检查 --trash-dir 或配置中的回收站设置",
+		})
+	} else {
+		report.Checks = append(report.Checks, checkTrashDir(manager), checkTrashMetadata(manager), checkNetworkFS(manager))
+	}
+
+	if systemInstaller, err := installer.GetSystemInstaller(); err == nil {
+		diagnosis := systemInstaller.Diagnose()
+		report.Checks = append(report.Checks, checkAliases(diagnosis))
+		report.Checks = append(report.Checks, checkShellProfiles(diagnosis)...)
+	}
+	report.Checks = append(report.Checks, checkSystemTrash(), checkLogFile(), checkStaleLocks())
+
+	failed := 0
+	for i := range report.Checks {
+		switch report.Checks[i].Status {
+		case doctorPass:
+			report.Checks[i].Hint = ""
+		case doctorFail:
+			failed++
+		}
+	}
+
+	if asJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%s", i18n.Plural("doctor.failed", failed))
+	}
+	return nil
+}
+
+// printDoctorReport 逐项输出检查结果和修复建议
+func printDoctorReport(report doctorReport) {
+	fmt.Printf("🩺 DelGuard %s (%s/%s) 自我诊断\n\n", report.Version, report.OS, report.Arch)
+
+	counts := make(map[string]int)
+	for _, check := range report.Checks {
+		counts[check.Status]++
+		icon := "✅"
+		switch check.Status {
+		case doctorWarn:
+			icon = "⚠️ "
+		case doctorFail:
+			icon = "❌"
+		}
+		fmt.Printf("%s %s: %s\n", icon, check.Name, check.Message)
+		if check.Hint != "" {
+			fmt.Printf("   💡 %s\n", check.Hint)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 通过 %d，警告 %d，失败 %d\n", counts[doctorPass], counts[doctorWarn], counts[doctorFail])
+}
+
+// checkConfigFile 检查配置文件能否解析并通过校验
+func checkConfigFile() doctorCheck {
+	check := doctorCheck{Name: "配置文件"}
+	path := config.ConfigFileUsed()
+	if path == "" {
+		check.Status = doctorWarn
+		check.Message = "未找到配置文件，使用默认设置"
+		check.Hint = "运行 delguard init 创建配置文件"
+		return check
+	}
+
+	result, err := config.ValidateFile(path)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Hint = fmt.Sprintf("修正 %s 的YAML语法", path)
+		return check
+	}
+
+	check.Hint = "运行 delguard config validate 查看详情"
+	switch {
+	case result.HasErrors():
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("%s: %s", path, i18n.Plural("count.errors", result.Count(config.LevelError)))
+	case result.Count(config.LevelWarning) > 0:
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("%s: %s", path, i18n.Plural("count.warnings", result.Count(config.LevelWarning)))
+	default:
+		check.Status = doctorPass
+		check.Message = path
+	}
+	return check
+}
+
+// checkTrashDir 检查回收站目录存在且可写
+func checkTrashDir(manager filesystem.TrashManager) doctorCheck {
+	check := doctorCheck{Name: "回收站目录"}
+	trashPath, _ := manager.GetTrashPath()
+	if err := manager.ValidateTrash(); err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Hint = "检查回收站目录的权限和剩余空间，或使用 --trash-dir 指定其他位置"
+		return check
+	}
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("%s 可写", trashPath)
+	return check
+}
+
+// checkTrashMetadata 快速检查回收站文件与元数据是否一致，不做修复
+func checkTrashMetadata(manager filesystem.TrashManager) doctorCheck {
+	check := doctorCheck{Name: "回收站元数据", Hint: "运行 delguard trash verify --repair 修复"}
+	report, err := manager.VerifyTrash(false)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		return check
+	}
+	if !report.Healthy() {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("%d 条元数据没有对应文件，%d 个文件缺少元数据",
+			len(report.OrphanedMetadata), len(report.OrphanedFiles))
+		return check
+	}
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("检查了 %d 个项目，未发现问题", report.Checked)
+	return check
+}
+
+// checkNetworkFS 报告回收站和当前目录所在的文件系统，位于网络文件系统时说明降级模式下进入的回收站和超时
+// 回收站本身位于网络文件系统时给出警告，每次删除和恢复都要经过网络
+func checkNetworkFS(manager filesystem.TrashManager) doctorCheck {
+	check := doctorCheck{Name: "网络文件系统", Status: doctorPass}
+	var notes []string
+	if trashPath, err := manager.GetTrashPath(); err == nil {
+		if mount, remote := filesystem.RemoteMount(trashPath); remote {
+			check.Status = doctorWarn
+			check.Hint = "删除和恢复都要经过网络，可以用 --trash-dir 或 trash.use_system_trash: false 改用本地目录"
+			notes = append(notes, fmt.Sprintf("回收站位于网络文件系统 %s (%s)", mount.MountPoint, mount.FSType))
+		}
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		if plan, ok := filesystem.PlanNetwork(manager, cwd); ok {
+			note := fmt.Sprintf("当前目录位于网络文件系统 %s (%s)，删除", plan.Mount.MountPoint, plan.Mount.FSType)
+			switch {
+			case plan.ShareTrash != "":
+				note += "到同一共享上的回收站 " + plan.ShareTrash
+			case plan.Copy:
+				note += "时跨网络复制到本地回收站"
+			default:
+				note += "到同一文件系统上的回收站"
+			}
+			if plan.Timeout > 0 {
+				note += fmt.Sprintf("，超时 %v", plan.Timeout)
+			}
+			if cfg := config.Current(); cfg == nil || !cfg.Trash.NetworkHash {
+				note += "，不计算内容哈希"
+			}
+			notes = append(notes, note)
+		}
+	}
+
+	if shares := filesystem.KnownShareTrashes(); len(shares) > 0 {
+		for i, root := range shares {
+			if _, err := os.Stat(root); err != nil {
+				shares[i] = root + " (不可访问)"
+			}
+		}
+		notes = append(notes, "共享回收站: "+strings.Join(shares, ", "))
+	}
+
+	if len(notes) == 0 {
+		check.Message = "回收站和当前目录都位于本地文件系统"
+		return check
+	}
+	check.Message = strings.Join(notes, "; ")
+	return check
+}
+
+// checkAliases 检查命令别名是否已安装并指向当前运行的可执行文件
+func checkAliases(diagnosis installer.Diagnosis) doctorCheck {
+	check := doctorCheck{Name: "命令别名", Hint: "运行 delguard install 重新安装别名"}
+	if diagnosis.AliasScript == "" {
+		check.Status = doctorWarn
+		check.Message = "未安装"
+		return check
+	}
+	if _, err := os.Stat(diagnosis.AliasTarget); err != nil {
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("%s 指向的 %s 不存在", diagnosis.AliasScript, diagnosis.AliasTarget)
+		return check
+	}
+
+	// 比较前解析符号链接，大小写按所在卷的规则处理
+	executable, err := os.Executable()
+	if err == nil && !utils.SamePath(filesystem.ResolveExistingPath(executable), filesystem.ResolveExistingPath(diagnosis.AliasTarget)) {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("别名指向 %s，当前运行的是 %s", diagnosis.AliasTarget, executable)
+		return check
+	}
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("%s -> %s", diagnosis.AliasScript, diagnosis.AliasTarget)
+	return check
+}
+
+// checkShellProfiles 检查shell配置文件中的DelGuard配置块，Windows上还检查PowerShell语法
+func checkShellProfiles(diagnosis installer.Diagnosis) []doctorCheck {
+	name := "Shell配置"
+	if runtime.GOOS == "windows" {
+		name = "PowerShell配置"
+	}
+	if len(diagnosis.Profiles) == 0 {
+		return []doctorCheck{{Name: name, Status: doctorPass, Message: "未找到DelGuard配置块"}}
+	}
+
+	var checks []doctorCheck
+	for _, profile := range diagnosis.Profiles {
+		check := doctorCheck{Name: name, Status: doctorPass, Message: profile.Path}
+		if profile.Problem != "" {
+			check.Status = doctorFail
+			check.Message = fmt.Sprintf("%s: %s", profile.Path, profile.Problem)
+			check.Hint = "手动修正该文件后运行 delguard install 重写DelGuard配置块"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkSystemTrash 使用系统回收站时检查其是否可访问
+func checkSystemTrash() doctorCheck {
+	check := doctorCheck{Name: "系统回收站", Status: doctorPass}
+	if cfg := config.Current(); trashDirOverride != "" || (cfg != nil && !cfg.Trash.UseSystemTrash) {
+		check.Message = "未使用，删除的文件放入DelGuard专用回收站"
+		return check
+	}
+
+	path, err := filesystem.CheckSystemTrash()
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Hint = "检查系统回收站的访问权限（macOS需要为终端授予完全磁盘访问权限），或设置 trash.use_system_trash: false"
+		return check
+	}
+	check.Message = path
+	return check
+}
+
+// checkLogFile 检查日志文件可写
+func checkLogFile() doctorCheck {
+	check := doctorCheck{Name: "日志文件"}
+	path := config.GetDefaultLogPath()
+	if cfg := config.Current(); cfg != nil && cfg.Logging.File != "" {
+		path = cfg.Logging.File
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("无法写入 %s: %v", path, err)
+		check.Hint = "检查日志目录的权限，或修改 logging.file"
+		return check
+	}
+	file.Close()
+	check.Status = doctorPass
+	check.Message = fmt.Sprintf("%s 可写", path)
+	return check
+}
+
+// checkStaleLocks 检查被中断的删除操作遗留的路径锁
+func checkStaleLocks() doctorCheck {
+	check := doctorCheck{Name: "遗留的操作锁"}
+	stale, err := lock.StaleLocks()
+	if err != nil {
+		check.Status = doctorWarn
+		check.Message = err.Error()
+		return check
+	}
+	if len(stale) > 0 {
+		check.Status = doctorWarn
+		check.Message = fmt.Sprintf("%d 个锁的持有进程已退出，上次删除可能被中断: %s", len(stale), filepath.Dir(stale[0]))
+		check.Hint = "这些锁会在下次操作同一路径时自动回收，也可以手动删除"
+		return check
+	}
+	check.Status = doctorPass
+	check.Message = "无"
+	return check
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/filesystem"
+	"delguard/internal/report"
+	"delguard/internal/utils"
+)
+
+// deniedItem 因权限不足删除失败、可以以管理员身份重试的项目
+type deniedItem struct {
+	item report.Item
+	err  error
+}
+
+// elevationAvailable 判断权限不足时能否询问以管理员身份重试：
+// 仅限Windows，windows.enable_uac_prompt开启、当前未提权、不是提权后的子进程，且可以交互确认
+func elevationAvailable(elevatedResult string) bool {
+	if elevatedResult != "" || !utils.ElevationSupported() || utils.IsElevated() {
+		return false
+	}
+	if cfg := config.Current(); cfg == nil || !cfg.Windows.EnableUACPrompt {
+		return false
+	}
+	return stdinIsTerminal()
+}
+
+// elevatedOptions 父进程已经解析好、需要原样传给提权后子进程的设置
+type elevatedOptions struct {
+	ConfigFile string // 父进程使用的配置文件，子进程可能以另一个账户运行，默认配置文件不同
+	TrashDir   string // 父进程使用的回收站目录
+	Recursive  bool   // 父进程的-r，已检查过的非空目录需要它才能删除
+	EmptyOnly  bool   // 父进程的--empty-only
+}
+
+// elevatedArgs 返回以管理员身份重新运行delete的参数
+// 传入的路径已经由父进程展开、排除并解析过符号链接，子进程不再解引用
+func elevatedArgs(resultPath string, opts elevatedOptions, paths []string) []string {
+	args := []string{"delete", "--elevated-result", resultPath, "--dereference=false"}
+	if opts.ConfigFile != "" {
+		args = append(args, "--config", opts.ConfigFile)
+	}
+	if opts.TrashDir != "" {
+		args = append(args, "--trash-dir", opts.TrashDir)
+	}
+	switch {
+	case opts.EmptyOnly:
+		args = append(args, "--empty-only")
+	case opts.Recursive:
+		args = append(args, "--recursive")
+	}
+	args = append(args, "--")
+	return append(args, paths...)
+}
+
+// retryElevated 询问后以管理员身份重新运行delete删除权限不足的项目，返回子进程报告的处理结果
+// 用户拒绝、UAC提示被取消或子进程没有写出结果时返回false，调用方按原错误记录这些项目
+func retryElevated(manager filesystem.TrashManager, denied []deniedItem, opts elevatedOptions, quiet bool) ([]report.Item, bool) {
+	fmt.Printf("🔐 %d 个项目需要管理员权限才能删除:\n", len(denied))
+	for _, d := range denied {
+		fmt.Printf("   %s\n", d.item.Path)
+	}
+	fmt.Print("   是否以管理员身份重试? [y/N]: ")
+	confirmed, err := confirmDangerousAction(false)
+	if err != nil {
+		fmt.Println()
+		return nil, false
+	}
+	if !confirmed {
+		return nil, false
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  无法确定DelGuard程序路径: %v\n", err)
+		return nil, false
+	}
+	resultPath, err := createElevatedResult()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  创建结果文件失败: %v\n", err)
+		return nil, false
+	}
+	defer os.Remove(resultPath)
+
+	// 提权后可能以另一个管理员账户运行，明确传入配置文件和回收站目录使文件进入同一个回收站
+	opts.ConfigFile = config.ConfigFileUsed()
+	if location, isDir, err := filesystem.BrowseLocation(manager); err == nil && isDir {
+		opts.TrashDir = location
+	}
+	paths := make([]string, len(denied))
+	for i, d := range denied {
+		paths[i] = d.item.Path
+	}
+	args := elevatedArgs(resultPath, opts, paths)
+
+	if _, err := utils.RunElevated(exe, args); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		return nil, false
+	}
+	items, err := readElevatedResult(resultPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  读取管理员进程的结果失败: %v\n", err)
+		return nil, false
+	}
+	if !quiet {
+		fmt.Printf("🔐 已以管理员身份处理 %d 个项目\n", len(items))
+	}
+	return items, true
+}
+
+// elevatedResultPrefix 结果文件名的前缀，其后是父进程生成的随机nonce
+const elevatedResultPrefix = "delguard-elevated-"
+
+// createElevatedResult 在临时目录中创建结果文件，文件名和内容都是同一个随机nonce，
+// 子进程据此确认结果文件由retryElevated创建，而不是由用户在命令行中指定
+func createElevatedResult() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	nonce := hex.EncodeToString(buf)
+	path := filepath.Join(os.TempDir(), elevatedResultPrefix+nonce+".json")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(nonce); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// elevatedNonce 从结果文件名中取出nonce，文件名不是createElevatedResult生成的格式时返回false
+func elevatedNonce(path string) (string, bool) {
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, elevatedResultPrefix) || !strings.HasSuffix(name, ".json") {
+		return "", false
+	}
+	nonce := strings.TrimSuffix(strings.TrimPrefix(name, elevatedResultPrefix), ".json")
+	if decoded, err := hex.DecodeString(nonce); err != nil || len(decoded) != 16 {
+		return "", false
+	}
+	return nonce, true
+}
+
+// checkElevatedResult 检查结果文件带有父进程生成的nonce：文件名中的nonce与尚未写入结果的文件内容一致
+func checkElevatedResult(path string) error {
+	nonce, ok := elevatedNonce(path)
+	if !ok {
+		return fmt.Errorf("结果文件不是由DelGuard创建的: %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if string(data) != nonce {
+		return fmt.Errorf("结果文件与父进程生成的不一致: %s", path)
+	}
+	return nil
+}
+
+// validateElevatedResult 判断能否接受--elevated-result：只有Windows上已经提权、由retryElevated启动的子进程才跳过确认；
+// 提权重试只会移入回收站，同时指定了--shred或--no-trash时拒绝，子进程不能扩大自己的权限
+func validateElevatedResult(path string, permanent bool) error {
+	if permanent {
+		return errors.NewError(errors.ErrTypeUsage, "--elevated-result 不能与 --shred 或 --no-trash 同时使用", nil)
+	}
+	if !utils.ElevationSupported() || !utils.IsElevated() {
+		return errors.NewError(errors.ErrTypeUsage, "--elevated-result 仅供以管理员身份重新运行时内部使用", nil)
+	}
+	if err := checkElevatedResult(path); err != nil {
+		return errors.NewError(errors.ErrTypeValidation, "拒绝 --elevated-result", err)
+	}
+	return nil
+}
+
+// writeElevatedResult 提权后的子进程将各项目的处理结果写入父进程指定的文件
+func writeElevatedResult(path string, items []report.Item) error {
+	for i := range items {
+		items[i].Elevated = true
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// readElevatedResult 读取子进程写出的处理结果，子进程未写出结果时返回错误
+func readElevatedResult(path string) ([]report.Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if nonce, _ := elevatedNonce(path); len(data) == 0 || string(data) == nonce {
+		return nil, fmt.Errorf("管理员进程没有写出结果")
+	}
+	var items []report.Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// isPermissionDenied 判断删除失败是否由权限不足引起
+func isPermissionDenied(err error) bool {
+	return errors.IsType(err, errors.ErrTypePermissionDenied)
+}
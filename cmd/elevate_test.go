@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"delguard/internal/errors"
+	"delguard/internal/report"
+	"delguard/internal/utils"
+)
+
+func TestElevatedArgsForwardsResolvedSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		opts elevatedOptions
+		want []string
+	}{
+		{
+			name: "recursive with config and trash dir",
+			opts: elevatedOptions{ConfigFile: `C:\cfg\config.yaml`, TrashDir: `D:\trash`, Recursive: true},
+			want: []string{"delete", "--elevated-result", "result.json", "--dereference=false",
+				"--config", `C:\cfg\config.yaml`, "--trash-dir", `D:\trash`, "--recursive", "--", `C:\data\dir`},
+		},
+		{
+			name: "empty only wins over recursive",
+			opts: elevatedOptions{Recursive: true, EmptyOnly: true},
+			want: []string{"delete", "--elevated-result", "result.json", "--dereference=false",
+				"--empty-only", "--", `C:\data\dir`},
+		},
+		{
+			name: "plain file",
+			opts: elevatedOptions{},
+			want: []string{"delete", "--elevated-result", "result.json", "--dereference=false", "--", `C:\data\dir`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := elevatedArgs("result.json", tt.opts, []string{`C:\data\dir`})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("elevatedArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElevatedArgsParseAsDeleteFlags(t *testing.T) {
+	args := elevatedArgs("result.json", elevatedOptions{ConfigFile: "config.yaml", Recursive: true}, []string{"-dir-starting-with-dash"})
+
+	cmd, rest, err := rootCmd.Find(args)
+	if err != nil || cmd != deleteCmd {
+		t.Fatalf("Find(%q) = %v, %v", args, cmd, err)
+	}
+	flags := deleteCmd.Flags()
+	defer flags.Set("recursive", "false")
+	defer flags.Set("elevated-result", "")
+	if err := cmd.ParseFlags(rest); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if recursive, _ := flags.GetBool("recursive"); !recursive {
+		t.Error("--recursive was not forwarded")
+	}
+	if !flags.Changed("dereference") {
+		t.Error("--dereference=false must override trash.dereference_symlinks in the child")
+	}
+	if got := flags.Args(); !reflect.DeepEqual(got, []string{"-dir-starting-with-dash"}) {
+		t.Errorf("paths = %q", got)
+	}
+}
+
+func TestElevatedResultNonce(t *testing.T) {
+	path, err := createElevatedResult()
+	if err != nil {
+		t.Fatalf("createElevatedResult: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(path) })
+	if err := checkElevatedResult(path); err != nil {
+		t.Errorf("checkElevatedResult(parent file) = %v", err)
+	}
+	if _, err := readElevatedResult(path); err == nil {
+		t.Error("readElevatedResult accepted a file the child never wrote")
+	}
+
+	dir := t.TempDir()
+	forged := filepath.Join(dir, elevatedResultPrefix+"00112233445566778899aabbccddeeff.json")
+	if err := os.WriteFile(forged, []byte("ffeeddccbbaa99887766554433221100"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	userChosen := filepath.Join(dir, "r.json")
+	if err := os.WriteFile(userChosen, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{forged, userChosen, filepath.Join(dir, elevatedResultPrefix+"xyz.json")} {
+		if err := checkElevatedResult(bad); err == nil {
+			t.Errorf("checkElevatedResult(%s) accepted a file without the parent's nonce", bad)
+		}
+	}
+
+	items := []report.Item{{Path: `C:\data\locked.txt`, Outcome: "trashed"}}
+	if err := writeElevatedResult(path, items); err != nil {
+		t.Fatalf("writeElevatedResult: %v", err)
+	}
+	got, err := readElevatedResult(path)
+	if err != nil || len(got) != 1 || !got[0].Elevated {
+		t.Errorf("readElevatedResult() = %+v, %v; want the item marked elevated", got, err)
+	}
+}
+
+func TestValidateElevatedResult(t *testing.T) {
+	path, err := createElevatedResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(path) })
+
+	if err := validateElevatedResult(path, true); !errors.IsType(err, errors.ErrTypeUsage) {
+		t.Errorf("with --shred/--no-trash: %v, want a usage error", err)
+	}
+	err = validateElevatedResult(path, false)
+	if runtime.GOOS != "windows" || !utils.IsElevated() {
+		if !errors.IsType(err, errors.ErrTypeUsage) {
+			t.Errorf("unelevated child: %v, want a usage error", err)
+		}
+	} else if err != nil {
+		t.Errorf("elevated child with the parent's nonce: %v", err)
+	}
+}
+
+func TestDeleteRefusesElevatedResultWithShred(t *testing.T) {
+	initTempConfig(t)
+	useFakeTrash(t)
+	file := writeTestFile(t, t.TempDir(), "f.txt")
+	result := filepath.Join(t.TempDir(), "r.json")
+	flags := deleteCmd.Flags()
+	t.Cleanup(func() {
+		flags.Set("shred", "false")
+		flags.Set("elevated-result", "")
+	})
+	flags.Set("shred", "true")
+	flags.Set("elevated-result", result)
+
+	err := runDelete(deleteCmd, []string{file})
+	if !errors.IsType(err, errors.ErrTypeUsage) {
+		t.Errorf("runDelete() = %v, want a usage error", err)
+	}
+	if _, statErr := os.Stat(file); statErr != nil {
+		t.Errorf("file was removed: %v", statErr)
+	}
+}
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"time"
 
 	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
+	"delguard/internal/utils"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,12 +21,18 @@ var emptyCmd = &cobra.Command{
 	Aliases: []string{"clear", "purge"},
 	Short:   "清空回收站",
 	Long: `永久删除回收站中的所有文件和目录。
+已固定 (delguard trash pin) 的项目默认保留，使用 --include-pinned 一并删除。
 
 ⚠️  警告: 此操作不可逆，清空后的文件无法恢复！
 
+项目逐个删除，单个项目失败时继续处理其余项目，结束后汇总释放的空间和失败的项目。
+按 Ctrl+C 会在当前项目删除完成后停止，未处理的项目保留在回收站中。
+security.safe_mode 为 strict 时 -f 不会跳过确认，必须输入 DELETE。
+
 示例:
   delguard empty
   delguard empty --force    # 跳过确认提示
+  delguard empty --include-pinned
   delguard clear            # 别名
   delguard purge            # 别名`,
 	RunE: runEmpty,
@@ -33,16 +44,19 @@ func init() {
 	// 添加标志
 	emptyCmd.Flags().BoolP("force", "f", false, "强制清空，不显示确认提示")
 	emptyCmd.Flags().BoolP("dry-run", "n", false, "预览模式，显示将要删除的文件但不实际删除")
+	emptyCmd.Flags().Bool("include-pinned", false, "同时删除已固定的项目")
 }
 
 func runEmpty(cmd *cobra.Command, args []string) error {
 	// 获取标志值
 	force, _ := cmd.Flags().GetBool("force")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	includePinned, _ := cmd.Flags().GetBool("include-pinned")
 	quiet := viper.GetBool("quiet")
+	level := currentOutputLevel()
 
 	// 获取回收站管理器
-	manager, err := filesystem.GetTrashManager()
+	manager, err := newTrashManager()
 	if err != nil {
 		return fmt.Errorf("初始化回收站管理器失败: %v", err)
 	}
@@ -60,6 +74,26 @@ func runEmpty(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// 已固定的项目默认保留
+	pinnedCount := 0
+	if !includePinned {
+		var unpinned []filesystem.TrashFile
+		for _, file := range trashFiles {
+			if file.Pinned {
+				pinnedCount++
+			} else {
+				unpinned = append(unpinned, file)
+			}
+		}
+		trashFiles = unpinned
+	}
+	if pinnedCount > 0 && !quiet {
+		fmt.Printf("📌 %s\n", i18n.Plural("pin.kept", pinnedCount))
+	}
+	if len(trashFiles) == 0 {
+		return nil
+	}
+
 	// 计算统计信息
 	totalSize := int64(0)
 	fileCount := 0
@@ -80,7 +114,7 @@ func runEmpty(cmd *cobra.Command, args []string) error {
 
 	// 预览模式
 	if dryRun {
-		fmt.Printf("🔍 预览模式 - 将要永久删除 %d 个项目:\n", len(trashFiles))
+		fmt.Printf("🔍 %s\n", i18n.Plural("empty.preview", len(trashFiles)))
 		fmt.Printf("   📄 文件: %d个, 📁 目录: %d个, 总大小: %s\n", 
 			fileCount, dirCount, filesystem.FormatFileSize(totalSize))
 		
@@ -102,12 +136,12 @@ func runEmpty(cmd *cobra.Command, args []string) error {
 				typeIcon = "📁"
 			}
 			fmt.Printf("  %s %s (%s, 删除于: %s)\n", 
-				typeIcon, file.Name, filesystem.FormatFileSize(file.Size),
+				typeIcon, utils.SanitizeName(file.Name), filesystem.FormatFileSize(file.Size),
 				file.DeletedTime.Format("2006-01-02 15:04"))
 		}
 
 		if len(trashFiles) > 10 {
-			fmt.Printf("  ... 还有 %d 个项目\n", len(trashFiles)-10)
+			fmt.Printf("  %s\n", i18n.Plural("count.items_more", len(trashFiles)-10))
 		}
 
 		return nil
@@ -115,7 +149,7 @@ func runEmpty(cmd *cobra.Command, args []string) error {
 
 	// 显示警告信息
 	if !quiet {
-		fmt.Printf("⚠️  警告: 即将永久删除回收站中的 %d 个项目\n", len(trashFiles))
+		fmt.Printf("⚠️  %s\n", i18n.Plural("empty.warning", len(trashFiles)))
 		fmt.Printf("   📄 文件: %d个, 📁 目录: %d个, 总大小: %s\n", 
 			fileCount, dirCount, filesystem.FormatFileSize(totalSize))
 		if !oldestFile.IsZero() {
@@ -124,16 +158,22 @@ func runEmpty(cmd *cobra.Command, args []string) error {
 		fmt.Println("⚠️  此操作不可逆，删除后无法恢复！")
 	}
 
-	// 确认操作
-	if !force {
-		fmt.Print("确认要清空回收站吗? 请输入 'yes' 确认: ")
-		var response string
-		if _, err := fmt.Scanln(&response); err != nil {
-			fmt.Println("❌ 读取输入失败，操作已取消")
+	// 确认操作，严格安全模式下忽略-f并且必须输入DELETE
+	policy := safeModePolicy()
+	if force && !policy.HonorForce && !quiet {
+		fmt.Fprintf(os.Stderr, "🔒 安全模式 %s: 忽略 -f，仍需确认\n", policy.Mode)
+	}
+	if words := purgeConfirmWords(policy, force, "yes", "YES"); words != nil {
+		confirmed, err := confirmPurge(policy, "确认要清空回收站吗?", words)
+		if err != nil {
+			if !policy.AllowPermanentDelete {
+				return err
+			}
+			fmt.Println("❌ " + i18n.T("common.input_failed"))
 			return nil
 		}
-		if response != "yes" && response != "YES" {
-			fmt.Println("❌ 操作已取消")
+		if !confirmed {
+			fmt.Println("❌ " + i18n.T("common.cancelled"))
 			return nil
 		}
 	}
@@ -143,18 +183,39 @@ func runEmpty(cmd *cobra.Command, args []string) error {
 		fmt.Println("🗑️  正在清空回收站...")
 	}
 
-	err = manager.EmptyTrash()
-	if err != nil {
-		return fmt.Errorf("清空回收站失败: %v", err)
+	// Ctrl+C在两个项目之间停止，已删除项目的元数据已随之清理
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	operation := startOperation(cmd, "清空回收站")
+	progress := newProgress(level)
+	result := filesystem.EmptyItems(ctx, manager, trashFiles, func(p filesystem.EmptyProgress) {
+		progress.Update(p.Done, p.Total, "已释放 "+filesystem.FormatFileSize(p.Freed))
+	})
+	operation.Add(result.Removed, result.Freed)
+	operation.Finish()
+	progress.Done()
+
+	// 显示结果摘要，失败和取消在静默模式下也输出
+	if result.Removed > 0 && !quiet {
+		fmt.Printf("✅ %s\n", i18n.Plural("empty.done", result.Removed, filesystem.FormatFileSize(result.Freed)))
 	}
-
-	// 显示成功信息
-	if !quiet {
-		fmt.Printf("✅ 成功清空回收站，删除了 %d 个项目 (%s)\n",
-			len(trashFiles), filesystem.FormatFileSize(totalSize))
-		if len(trashFiles) > 0 {
-			fmt.Printf("   📄 文件: %d个, 📁 目录: %d个\n", fileCount, dirCount)
+	if result.Cancelled() {
+		remainingSize := int64(0)
+		for _, file := range result.Remaining {
+			remainingSize += file.Size
+		}
+		fmt.Fprintf(os.Stderr, "⏹️  已取消，%d 个项目仍在回收站中 (%s)\n",
+			len(result.Remaining), filesystem.FormatFileSize(remainingSize))
+	}
+	if result.Failures.HasErrors() {
+		for _, failure := range result.Failures.Errors() {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", utils.SanitizeName(failure.Path), failure.Err)
 		}
+		return result.Failures.Err()
+	}
+	if result.Cancelled() {
+		return fmt.Errorf("清空回收站已取消")
 	}
 
 	return nil
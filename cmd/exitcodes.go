@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"delguard/internal/errors"
+
+	"github.com/spf13/cobra"
+)
+
+// exitCodesCmd 列出进程退出码的约定
+var exitCodesCmd = &cobra.Command{
+	Use:   "exit-codes",
+	Short: "列出退出码及其对应的错误类型",
+	Long: `列出DelGuard使用的进程退出码、名称、含义以及对应的错误类型(kind)。
+退出码沿用BSD sysexits.h的约定，脚本可以据此区分失败原因，例如文件不存在(66)与权限不足(77)。
+错误类型名称与 --error-format json 输出中的kind字段相同。
+
+示例:
+  delguard exit-codes
+  delguard exit-codes --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		codes := errors.ExitCodes()
+		if asJSON {
+			return printJSON(codes)
+		}
+		for _, info := range codes {
+			fmt.Printf("%3d  %-12s %s\n", info.Code, info.Name, info.Description)
+			if len(info.Kinds) > 0 {
+				fmt.Printf("     %-12s kind: %s\n", "", strings.Join(info.Kinds, ", "))
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exitCodesCmd)
+	exitCodesCmd.Flags().Bool("json", false, "以JSON格式输出")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"delguard/internal/errors"
+)
+
+func TestExitCodesJSON(t *testing.T) {
+	exitCodesCmd.Flags().Set("json", "true")
+	t.Cleanup(func() { exitCodesCmd.Flags().Set("json", "false") })
+
+	output := captureStdout(t, func() {
+		if err := exitCodesCmd.RunE(exitCodesCmd, nil); err != nil {
+			t.Errorf("exit-codes --json: %v", err)
+		}
+	})
+	var infos []errors.ExitCodeInfo
+	if err := json.Unmarshal([]byte(output), &infos); err != nil {
+		t.Fatalf("exit-codes --json output is not JSON: %v\n%s", err, output)
+	}
+	codes := make(map[int]string)
+	for _, info := range infos {
+		codes[info.Code] = info.Name
+	}
+	for code, name := range map[int]string{errors.ExitOK: "ok", errors.ExitNoInput: "noinput", errors.ExitNoPerm: "noperm", errors.ExitUsage: "usage"} {
+		if codes[code] != name {
+			t.Errorf("code %d is named %q, want %q", code, codes[code], name)
+		}
+	}
+}
+
+func TestRestoreReturnsTypedErrors(t *testing.T) {
+	initTempConfig(t)
+	manager := useFakeTrash(t)
+	if err := manager.MoveToTrash(writeTestFile(t, t.TempDir(), "report.txt")); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	trashFiles, err := manager.ListTrashFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRestore(restoreCmd, nil); errors.ExitCode(err) != errors.ExitUsage {
+		t.Errorf("restore without arguments = %v (exit %d), want exit %d", err, errors.ExitCode(err), errors.ExitUsage)
+	}
+	if _, err := selectFilesToRestore(trashFiles, []string{"missing.txt"}, ""); errors.ExitCode(err) != errors.ExitNoInput {
+		t.Errorf("restore of a missing name = %v (exit %d), want exit %d", err, errors.ExitCode(err), errors.ExitNoInput)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 
+	"delguard/internal/i18n"
 	"delguard/internal/installer"
 
 	"github.com/spf13/cobra"
@@ -13,6 +14,7 @@ var (
 	systemWide   bool
 	forceInstall bool
 	installPath  string
+	allProfiles  bool
 )
 
 // installCmd represents the install command
@@ -27,7 +29,8 @@ var installCmd = &cobra.Command{
   delguard install                    # 用户级安装
   delguard install --system           # 系统级安装（需要管理员权限）
   delguard install --path /custom/path # 自定义安装路径
-  delguard install --force            # 强制安装，覆盖现有安装`,
+  delguard install --force            # 强制安装，覆盖现有安装
+  delguard install --all-profiles     # Windows: 写入所有PowerShell配置文件`,
 	RunE: runInstall,
 }
 
@@ -37,11 +40,12 @@ func init() {
 	installCmd.Flags().BoolVarP(&systemWide, "system", "s", false, "系统级安装（需要管理员权限）")
 	installCmd.Flags().BoolVarP(&forceInstall, "force", "f", false, "强制安装，覆盖现有安装")
 	installCmd.Flags().StringVarP(&installPath, "path", "p", "", "自定义安装路径")
+	installCmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "写入所有PowerShell配置文件（CurrentUserAllHosts和CurrentUserCurrentHost），仅用于Windows")
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
-	fmt.Printf("🚀 DelGuard 安装程序\n")
-	fmt.Printf("操作系统: %s %s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Println("🚀 " + i18n.T("install.title"))
+	fmt.Println(i18n.T("install.os", runtime.GOOS, runtime.GOARCH))
 	fmt.Println()
 
 	// 获取系统安装器
@@ -52,9 +56,9 @@ func runInstall(cmd *cobra.Command, args []string) error {
 
 	// 检查是否已安装
 	if systemInstaller.IsInstalled() && !forceInstall {
-		fmt.Println("⚠️ DelGuard已经安装")
-		fmt.Printf("安装路径: %s\n", systemInstaller.GetInstallPath())
-		fmt.Println("如需重新安装，请使用 --force 参数")
+		fmt.Println("⚠️ " + i18n.T("install.already"))
+		fmt.Println(i18n.T("install.path", systemInstaller.GetInstallPath()))
+		fmt.Println(i18n.T("install.use_force"))
 		return nil
 	}
 
@@ -72,26 +76,29 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	config.SystemWide = systemWide
 	config.ForceInstall = forceInstall
 
-	fmt.Println("📋 安装配置:")
-	fmt.Printf("  安装类型: %s\n", getInstallType(systemWide))
-	fmt.Printf("  安装路径: %s\n", config.InstallPath)
-	fmt.Printf("  备份路径: %s\n", config.BackupPath)
-	fmt.Printf("  目标命令: %v\n", installer.GetTargetCommands())
+	fmt.Println("📋 " + i18n.T("install.config"))
+	fmt.Println(i18n.T("install.type", getInstallType(systemWide)))
+	fmt.Println(i18n.T("install.install_path", config.InstallPath))
+	fmt.Println(i18n.T("install.backup_path", config.BackupPath))
+	fmt.Println(i18n.T("install.commands", installer.GetTargetCommands()))
 	fmt.Println()
 
 	// 确认安装
 	if !forceInstall {
-		fmt.Print("是否继续安装？ (y/N): ")
-		var response string
-		_, _ = fmt.Scanln(&response)
-		if response != "y" && response != "Y" && response != "yes" && response != "YES" {
-			fmt.Println("❌ 安装已取消")
+		fmt.Print(i18n.T("install.confirm"))
+		if confirmed, _ := confirmAction(); !confirmed {
+			fmt.Println("❌ " + i18n.T("install.cancelled"))
 			return nil
 		}
 	}
 
+	// PowerShell配置文件默认询问写入哪一个
+	if windowsInstaller, ok := systemInstaller.(*installer.WindowsInstaller); ok {
+		windowsInstaller.SetAllProfiles(allProfiles)
+	}
+
 	// 执行安装
-	fmt.Println("🔧 开始安装...")
+	fmt.Println("🔧 " + i18n.T("install.start"))
 	if err := systemInstaller.Install(); err != nil {
 		return fmt.Errorf("安装失败: %v", err)
 	}
@@ -104,39 +111,39 @@ func runInstall(cmd *cobra.Command, args []string) error {
 
 func getInstallType(systemWide bool) string {
 	if systemWide {
-		return "系统级安装"
+		return i18n.T("install.type_system")
 	}
-	return "用户级安装"
+	return i18n.T("install.type_user")
 }
 
 func showPostInstallInstructions() {
 	fmt.Println()
-	fmt.Println("🎉 安装完成！")
+	fmt.Println("🎉 " + i18n.T("install.done"))
 	fmt.Println()
-	fmt.Println("📝 使用说明:")
-	fmt.Println("  现在您可以使用以下命令安全删除文件:")
+	fmt.Println("📝 " + i18n.T("install.usage"))
+	fmt.Println(i18n.T("install.usage_intro"))
 
 	switch runtime.GOOS {
 	case "windows":
-		fmt.Println("    del file.txt        # 删除文件到回收站")
-		fmt.Println("    rmdir folder        # 删除目录到回收站")
-		fmt.Println("    delguard list       # 查看回收站文件")
-		fmt.Println("    delguard restore    # 恢复文件")
+		fmt.Println(i18n.T("install.usage_del"))
+		fmt.Println(i18n.T("install.usage_rmdir"))
+		fmt.Println(i18n.T("install.usage_list"))
+		fmt.Println(i18n.T("install.usage_restore"))
 	case "darwin", "linux":
-		fmt.Println("    rm file.txt         # 删除文件到回收站")
-		fmt.Println("    rm -r folder        # 删除目录到回收站")
-		fmt.Println("    delguard list       # 查看回收站文件")
-		fmt.Println("    delguard restore    # 恢复文件")
+		fmt.Println(i18n.T("install.usage_rm"))
+		fmt.Println(i18n.T("install.usage_rm_r"))
+		fmt.Println(i18n.T("install.usage_list"))
+		fmt.Println(i18n.T("install.usage_restore"))
 	}
 
 	fmt.Println()
-	fmt.Println("⚠️ 重要提示:")
-	fmt.Println("  - 请重新启动终端或重新加载配置文件")
-	fmt.Println("  - 如需永久删除文件，请使用: delguard delete --permanent")
-	fmt.Println("  - 如需卸载，请使用: delguard uninstall")
+	fmt.Println("⚠️ " + i18n.T("install.notes"))
+	fmt.Println(i18n.T("install.note_reload"))
+	fmt.Println(i18n.T("install.note_no_trash"))
+	fmt.Println(i18n.T("install.note_remove"))
 
 	if runtime.GOOS == "windows" {
-		fmt.Println("  - PowerShell用户请重新启动PowerShell")
-		fmt.Println("  - 或运行: . $PROFILE 重新加载配置")
+		fmt.Println(i18n.T("install.note_ps"))
+		fmt.Println(i18n.T("install.note_profile"))
 	}
 }
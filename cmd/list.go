@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -8,7 +10,10 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"delguard/internal/config"
 	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
+	"delguard/internal/utils"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -24,10 +29,15 @@ var listCmd = &cobra.Command{
 显示文件名、大小、删除时间等信息。
 支持按不同条件排序和过滤。
 
+在终端中每页显示50项，--limit 指定每页项目数，--offset 跳过前面的项目，--all 显示全部。
+列表只读取回收站索引，固定和损坏标记只为显示的项目读取。
+
 示例:
   delguard list
   delguard list --sort=size
   delguard list --filter="*.txt"
+  delguard list --offset 50          # 第二页
+  delguard list --all --json > trash.json
   delguard ls  # 别名`,
 	RunE: runList,
 }
@@ -41,7 +51,45 @@ func init() {
 	listCmd.Flags().StringP("filter", "f", "", "文件名过滤器（支持通配符）")
 	listCmd.Flags().BoolP("long", "l", false, "详细列表格式")
 	listCmd.Flags().Bool("human", true, "人类可读的文件大小格式")
-	listCmd.Flags().IntP("limit", "n", 0, "限制显示的文件数量（0表示无限制）")
+	listCmd.Flags().IntP("limit", "n", 0, "每页显示的项目数（0表示在终端中显示50项，否则不限制）")
+	listCmd.Flags().Int("offset", 0, "跳过排序后的前N个项目")
+	listCmd.Flags().BoolP("all", "a", false, "显示全部项目，不分页")
+	listCmd.Flags().Bool("json", false, "以JSON数组格式逐项输出")
+	listCmd.Flags().Bool("system-bin", false, "同时列出Windows系统回收站中的项目，覆盖配置中的trash.show_system_bin")
+}
+
+// withSystemBin 指定--system-bin或启用trash.show_system_bin时，在DelGuard回收站的项目之后追加
+// Windows系统回收站中的项目，list和restore使用相同的顺序，索引保持一致
+func withSystemBin(cmd *cobra.Command, files []filesystem.TrashFile) ([]filesystem.TrashFile, error) {
+	enabled, _ := cmd.Flags().GetBool("system-bin")
+	if cfg := config.Current(); !cmd.Flags().Changed("system-bin") && cfg != nil {
+		enabled = cfg.Trash.ShowSystemBin
+	}
+	if !enabled {
+		return files, nil
+	}
+	system, err := filesystem.SystemBinFiles()
+	if err != nil {
+		return files, err
+	}
+	return append(files, system...), nil
+}
+
+// defaultPageSize 标准输出为终端且未指定--limit时每页显示的项目数
+const defaultPageSize = 50
+
+// listEntry list --json 输出的单个项目
+type listEntry struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	OriginalPath string    `json:"original_path"`
+	TrashPath    string    `json:"trash_path"`
+	Size         int64     `json:"size"`
+	DeletedTime  time.Time `json:"deleted_time"`
+	IsDirectory  bool      `json:"is_directory"`
+	Pinned       bool      `json:"pinned"`
+	Corrupt      bool      `json:"corrupt"`
+	Source       string    `json:"source"` // delguard或system
 }
 
 func runList(cmd *cobra.Command, args []string) error {
@@ -52,23 +100,35 @@ func runList(cmd *cobra.Command, args []string) error {
 	longFormat, _ := cmd.Flags().GetBool("long")
 	humanReadable, _ := cmd.Flags().GetBool("human")
 	limit, _ := cmd.Flags().GetInt("limit")
+	offset, _ := cmd.Flags().GetInt("offset")
+	all, _ := cmd.Flags().GetBool("all")
+	asJSON, _ := cmd.Flags().GetBool("json")
 	quiet := viper.GetBool("quiet")
+	if limit < 0 || offset < 0 {
+		return fmt.Errorf("--limit 和 --offset 不能为负数")
+	}
 
 	// 获取回收站管理器
-	manager, err := filesystem.GetTrashManager()
+	manager, err := newTrashManager()
 	if err != nil {
 		return fmt.Errorf("初始化回收站管理器失败: %v", err)
 	}
 
-	// 获取回收站文件列表
-	trashFiles, err := manager.ListTrashFiles()
+	// 获取回收站文件列表，只读取索引，固定和损坏标记在显示前按需读取
+	trashFiles, flagsLoaded, err := filesystem.ListTrashIndex(manager)
 	if err != nil {
 		return fmt.Errorf("获取回收站文件列表失败: %v", err)
 	}
+	if trashFiles, err = withSystemBin(cmd, trashFiles); err != nil {
+		return err
+	}
 
 	if len(trashFiles) == 0 {
+		if asJSON {
+			return printListJSON(manager, nil, true)
+		}
 		if !quiet {
-			fmt.Println("🗑️  回收站是空的")
+			fmt.Println("🗑️  " + i18n.T("common.trash_empty"))
 		}
 		return nil
 	}
@@ -91,16 +151,26 @@ func runList(cmd *cobra.Command, args []string) error {
 	// 排序
 	sortTrashFiles(trashFiles, sortBy, reverse)
 
-	// 限制数量
-	if limit > 0 && len(trashFiles) > limit {
-		trashFiles = trashFiles[:limit]
+	// 分页：输出到终端时默认每页显示defaultPageSize项，--all显示全部
+	if all {
+		limit = 0
+	} else if limit == 0 && !asJSON && utils.IsTerminal(os.Stdout.Fd()) {
+		limit = defaultPageSize
+	}
+	page := pageOf(trashFiles, offset, limit)
+
+	if asJSON {
+		return printListJSON(manager, page, flagsLoaded)
+	}
+	if !flagsLoaded {
+		filesystem.LoadListFlags(manager, page)
 	}
 
 	// 显示文件列表
 	if longFormat {
-		displayLongFormat(trashFiles, humanReadable)
+		displayLongFormat(page, humanReadable)
 	} else {
-		displayShortFormat(trashFiles, humanReadable)
+		displayShortFormat(page, humanReadable)
 	}
 
 	// 显示统计信息
@@ -109,18 +179,82 @@ func runList(cmd *cobra.Command, args []string) error {
 		for _, file := range trashFiles {
 			totalSize += file.Size
 		}
-		fmt.Printf("\n📊 总计: %d 个项目", len(trashFiles))
+		fmt.Printf("\n📊 %s", i18n.Plural("list.total", len(trashFiles)))
 		if humanReadable {
 			fmt.Printf(", %s", filesystem.FormatFileSize(totalSize))
 		} else {
 			fmt.Printf(", %d 字节", totalSize)
 		}
 		fmt.Println()
+		if len(page) < len(trashFiles) {
+			fmt.Println("📄 " + pageFooter(offset, len(page), len(trashFiles)))
+		}
 	}
 
 	return nil
 }
 
+// pageOf 返回跳过offset项后最多limit项（0表示不限制）
+func pageOf(files []filesystem.TrashFile, offset, limit int) []filesystem.TrashFile {
+	if offset >= len(files) {
+		return nil
+	}
+	files = files[offset:]
+	if limit > 0 && len(files) > limit {
+		files = files[:limit]
+	}
+	return files
+}
+
+// pageFooter 返回分页显示时的提示
+func pageFooter(offset, shown, total int) string {
+	if shown == 0 {
+		return fmt.Sprintf("--offset %d 超出了项目总数 %d", offset, total)
+	}
+	footer := fmt.Sprintf("显示第 %d-%d 项，共 %d 项，使用 --all 显示全部", offset+1, offset+shown, total)
+	if next := offset + shown; next < total {
+		footer += fmt.Sprintf("，--offset %d 查看下一页", next)
+	}
+	return footer
+}
+
+// printListJSON 以JSON数组输出项目，逐项编码写出，flagsLoaded为false时逐项读取固定和损坏标记
+func printListJSON(manager filesystem.TrashManager, files []filesystem.TrashFile, flagsLoaded bool) error {
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("[")
+	for i := range files {
+		if !flagsLoaded {
+			filesystem.LoadListFlags(manager, files[i:i+1])
+		}
+		file := files[i]
+		data, err := json.Marshal(listEntry{
+			ID:           file.ID,
+			Name:         file.Name,
+			OriginalPath: file.OriginalPath,
+			TrashPath:    file.TrashPath,
+			Size:         file.Size,
+			DeletedTime:  file.DeletedTime,
+			IsDirectory:  file.IsDirectory,
+			Pinned:       file.Pinned,
+			Corrupt:      file.Corrupt,
+			Source:       file.Origin(),
+		})
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if i == 0 {
+			sep = "\n  "
+		}
+		fmt.Fprintf(out, "%s%s", sep, data)
+	}
+	if len(files) > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("]\n")
+	return out.Flush()
+}
+
 // sortTrashFiles 排序回收站文件
 func sortTrashFiles(files []filesystem.TrashFile, sortBy string, reverse bool) {
 	sort.Slice(files, func(i, j int) bool {
@@ -201,7 +335,7 @@ func displayLongFormat(files []filesystem.TrashFile, humanReadable bool) {
 		}
 
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			typeIcon, file.Name, sizeStr, timeStr, originalPath)
+			typeIcon, markedName(file), sizeStr, timeStr, originalPath)
 	}
 
 	w.Flush()
@@ -219,9 +353,9 @@ func displayShortFormat(files []filesystem.TrashFile, humanReadable bool) {
 		// 文件名（带图标）
 		var nameWithIcon string
 		if file.IsDirectory {
-			nameWithIcon = "📁 " + file.Name
+			nameWithIcon = "📁 " + markedName(file)
 		} else {
-			nameWithIcon = "📄 " + file.Name
+			nameWithIcon = "📄 " + markedName(file)
 		}
 
 		// 格式化大小
@@ -241,6 +375,21 @@ func displayShortFormat(files []filesystem.TrashFile, humanReadable bool) {
 	w.Flush()
 }
 
+// markedName 返回显示用的文件名，控制字符和无效编码被转义，已固定的项目附加📌标记，内容已损坏的项目附加⚠️标记
+func markedName(file filesystem.TrashFile) string {
+	name := utils.SanitizeName(file.Name)
+	if file.Pinned {
+		name += " 📌"
+	}
+	if file.Corrupt {
+		name += " ⚠️"
+	}
+	if file.Source == filesystem.SourceSystem {
+		name += " [系统回收站]"
+	}
+	return name
+}
+
 // formatRelativeTime 格式化相对时间
 func formatRelativeTime(t time.Time) string {
 	now := time.Now()
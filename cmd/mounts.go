@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"delguard/internal/errors"
+	"delguard/internal/filesystem"
+)
+
+// mountTarget 包含其他文件系统挂载点的删除目标
+type mountTarget struct {
+	Path   string
+	Mounts []string
+}
+
+// nestedMounts 查找目录中挂载的其他文件系统，测试时可替换为桩函数
+var nestedMounts = filesystem.NestedMounts
+
+// guardNestedMounts 检查目录中是否挂载了其他文件系统
+// --one-file-system时将目录记入skipped并返回错误，否则记入crossing，稍后要求输入DELETE确认
+func guardNestedMounts(dir string, oneFileSystem bool, skipped, crossing *[]mountTarget) error {
+	mounts, err := nestedMounts(dir)
+	if err != nil || len(mounts) == 0 {
+		return nil
+	}
+	target := mountTarget{Path: dir, Mounts: mounts}
+	if oneFileSystem {
+		*skipped = append(*skipped, target)
+		return errors.NewValidationError(dir, "包含其他文件系统的挂载点")
+	}
+	*crossing = append(*crossing, target)
+	return nil
+}
+
+// printSkippedMounts 列出因 --one-file-system 跳过的目标及其中的挂载点，静默模式下也输出
+func printSkippedMounts(skipped []mountTarget) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n💽 以下 %d 个目标包含其他文件系统的挂载点，已跳过:\n", len(skipped))
+	for _, target := range skipped {
+		fmt.Fprintf(os.Stderr, "   • %s\n", target.Path)
+		for _, mount := range target.Mounts {
+			fmt.Fprintf(os.Stderr, "     ↳ %s\n", mount)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "   💡 先卸载这些文件系统，或使用 --one-file-system=false 确认后一并删除挂载的内容")
+}
+
+// confirmCrossMounts 列出包含挂载点的目标，要求输入DELETE确认删除挂载的其他文件系统中的内容，
+// 返回未确认而需要跳过的目标
+func confirmCrossMounts(targets []mountTarget) map[string]bool {
+	if len(targets) == 0 {
+		return nil
+	}
+	fmt.Println("💽 以下目标包含其他文件系统的挂载点，挂载的内容也会被删除:")
+	for _, target := range targets {
+		fmt.Printf("   • %s\n", target.Path)
+		for _, mount := range target.Mounts {
+			fmt.Printf("     ↳ %s\n", mount)
+		}
+	}
+	fmt.Print("⚠️  输入 DELETE 确认跨越挂载点删除，其他输入将跳过这些目标: ")
+	if confirmed, _ := confirmCritical("DELETE"); confirmed {
+		return nil
+	}
+
+	rejected := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		rejected[target.Path] = true
+	}
+	fmt.Printf("⏭️  跳过 %d 个包含挂载点的目标\n", len(targets))
+	return rejected
+}
+
+// withoutPaths 返回files中不在rejected里的路径
+func withoutPaths(files []string, rejected map[string]bool) []string {
+	if len(rejected) == 0 {
+		return files
+	}
+	var kept []string
+	for _, file := range files {
+		if !rejected[file] {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
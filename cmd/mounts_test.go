@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"delguard/internal/errors"
+)
+
+// stubNestedMounts 在测试期间将mounts作为各目录中挂载的文件系统
+func stubNestedMounts(t *testing.T, mounts map[string][]string) {
+	t.Helper()
+	saved := nestedMounts
+	nestedMounts = func(dir string) ([]string, error) {
+		if dir == "/srv/broken" {
+			return nil, fmt.Errorf("permission denied")
+		}
+		return mounts[dir], nil
+	}
+	t.Cleanup(func() { nestedMounts = saved })
+}
+
+func TestGuardNestedMounts(t *testing.T) {
+	stubNestedMounts(t, map[string][]string{"/srv/data": {"/srv/data/nfs"}})
+
+	t.Run("skipped by default", func(t *testing.T) {
+		var skipped, crossing []mountTarget
+		err := guardNestedMounts("/srv/data", true, &skipped, &crossing)
+		if !errors.IsType(err, errors.ErrTypeValidation) {
+			t.Errorf("err = %v, want a validation error", err)
+		}
+		want := []mountTarget{{Path: "/srv/data", Mounts: []string{"/srv/data/nfs"}}}
+		if !reflect.DeepEqual(skipped, want) || len(crossing) != 0 {
+			t.Errorf("skipped = %v, crossing = %v; want the target skipped", skipped, crossing)
+		}
+	})
+
+	t.Run("crossing with --one-file-system=false", func(t *testing.T) {
+		var skipped, crossing []mountTarget
+		if err := guardNestedMounts("/srv/data", false, &skipped, &crossing); err != nil {
+			t.Errorf("err = %v, want the target kept for confirmation", err)
+		}
+		if len(skipped) != 0 || len(crossing) != 1 || crossing[0].Path != "/srv/data" {
+			t.Errorf("skipped = %v, crossing = %v; want the target awaiting confirmation", skipped, crossing)
+		}
+	})
+
+	for _, dir := range []string{"/srv/plain", "/srv/broken"} {
+		var skipped, crossing []mountTarget
+		if err := guardNestedMounts(dir, true, &skipped, &crossing); err != nil || len(skipped)+len(crossing) != 0 {
+			t.Errorf("%s: err = %v, skipped = %v, crossing = %v; want nothing recorded", dir, err, skipped, crossing)
+		}
+	}
+}
+
+func TestConfirmCrossMounts(t *testing.T) {
+	targets := []mountTarget{
+		{Path: "/srv/data", Mounts: []string{"/srv/data/nfs"}},
+		{Path: "/srv/media", Mounts: []string{"/srv/media/usb"}},
+	}
+	files := []string{"/srv/data", "/srv/notes.txt", "/srv/media"}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"DELETE\n", files},
+		{"yes\n", []string{"/srv/notes.txt"}},
+		{"delete\n", []string{"/srv/notes.txt"}},
+		{"", []string{"/srv/notes.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.input), func(t *testing.T) {
+			initTempConfig(t)
+			var rejected map[string]bool
+			var output string
+			withStdin(t, tt.input, func() {
+				output = captureStdout(t, func() { rejected = confirmCrossMounts(targets) })
+			})
+			if got := withoutPaths(files, rejected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(output, "/srv/media/usb") {
+				t.Errorf("output does not list the mounts:\n%s", output)
+			}
+		})
+	}
+
+	if rejected := confirmCrossMounts(nil); rejected != nil {
+		t.Errorf("confirmCrossMounts(nil) = %v, want nil without prompting", rejected)
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"strings"
+
+	"delguard/internal/config"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// outputLevel 命令输出的详细程度
+type outputLevel int
+
+const (
+	// levelMinimal 只输出错误和最终汇总
+	levelMinimal outputLevel = iota
+	// levelNormal 默认输出
+	levelNormal
+	// levelVerbose 额外输出每个文件的处理结果
+	levelVerbose
+	// levelDebug 额外输出每个文件的耗时和使用的回收站后端
+	levelDebug
+)
+
+// currentOutputLevel 根据-q、-v/-vv/--debug标志和ui.detail_level确定输出详细程度，标志优先
+func currentOutputLevel() outputLevel {
+	if viper.GetBool("quiet") {
+		return levelMinimal
+	}
+	if viper.GetBool("debug") {
+		return levelDebug
+	}
+	// verbose可能来自计数标志(-vv为2)或配置文件中的布尔值
+	switch verbosity := viper.GetInt("verbose"); {
+	case verbosity >= 2:
+		return levelDebug
+	case verbosity == 1:
+		return levelVerbose
+	}
+
+	if cfg := config.Current(); cfg != nil {
+		switch strings.ToLower(cfg.UI.DetailLevel) {
+		case "minimal":
+			return levelMinimal
+		case "verbose":
+			return levelVerbose
+		case "debug":
+			return levelDebug
+		}
+	}
+	return levelNormal
+}
+
+// logLevelFor 返回输出详细程度要求的最低日志级别，-v时为info，-vv/--debug时为debug
+func logLevelFor(level outputLevel) string {
+	switch {
+	case level >= levelDebug:
+		return "debug"
+	case level >= levelVerbose:
+		return "info"
+	}
+	return ""
+}
+
+// DebugOutput 是否为调试输出（-vv、--debug或ui.detail_level: debug），此时错误附带调用栈
+func DebugOutput() bool {
+	return currentOutputLevel() >= levelDebug
+}
+
+// errorFormats --error-format可选的错误输出格式
+var errorFormats = []string{"text", "json"}
+
+// errorFormat --error-format指定的错误输出格式，为空时随命令的输出格式
+var errorFormat string
+
+// activeCmd 本次调用执行的命令，用于决定错误输出格式和标注出错的操作
+var activeCmd *cobra.Command
+
+// ErrorFormatJSON 错误是否以单行JSON输出到stderr：--error-format json，
+// 或未指定--error-format时命令以JSON输出结果（--json、--format json）
+func ErrorFormatJSON() bool {
+	if errorFormat != "" {
+		return strings.ToLower(errorFormat) == "json"
+	}
+	if activeCmd == nil {
+		return false
+	}
+	if asJSON, err := activeCmd.Flags().GetBool("json"); err == nil && asJSON {
+		return true
+	}
+	format, err := activeCmd.Flags().GetString("format")
+	return err == nil && strings.ToLower(format) == "json"
+}
+
+// CurrentOperation 返回本次调用执行的命令路径（不含程序名），未执行命令时返回空
+func CurrentOperation() string {
+	if activeCmd == nil || activeCmd == rootCmd {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(activeCmd.CommandPath(), rootCmd.Name()))
+}
+
+// silenceForJSON 以JSON输出错误时关闭cobra自带的错误和用法输出，避免stderr中混入文本
+func silenceForJSON(cmd *cobra.Command) {
+	activeCmd = cmd
+	if ErrorFormatJSON() {
+		cmd.Root().SilenceErrors = true
+		cmd.Root().SilenceUsage = true
+		cmd.SilenceUsage = true
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"delguard/internal/config"
+
+	"github.com/spf13/viper"
+)
+
+// resetOutputFlags 将-q、-v和--debug恢复为未指定的状态
+// config.Reset会清空viper，因此重新绑定这些标志
+func resetOutputFlags(t *testing.T) {
+	t.Helper()
+	for name, value := range map[string]string{"quiet": "false", "verbose": "0", "debug": "false"} {
+		flag := rootCmd.PersistentFlags().Lookup(name)
+		if err := flag.Value.Set(value); err != nil {
+			t.Fatal(err)
+		}
+		flag.Changed = false
+		if err := viper.BindPFlag(name, flag); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOutputLevelFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		detailLevel string
+		want        outputLevel
+		wantLog     string
+	}{
+		{name: "default", want: levelNormal},
+		{name: "quiet", args: []string{"-q"}, want: levelMinimal},
+		{name: "verbose", args: []string{"-v"}, want: levelVerbose, wantLog: "info"},
+		{name: "very verbose", args: []string{"-vv"}, want: levelDebug, wantLog: "debug"},
+		{name: "repeated verbose", args: []string{"-v", "-v"}, want: levelDebug, wantLog: "debug"},
+		{name: "debug", args: []string{"--debug"}, want: levelDebug, wantLog: "debug"},
+		{name: "quiet wins", args: []string{"-q", "--debug"}, want: levelMinimal},
+		{name: "config minimal", detailLevel: "minimal", want: levelMinimal},
+		{name: "config verbose", detailLevel: "Verbose", want: levelVerbose, wantLog: "info"},
+		{name: "config debug", detailLevel: "debug", want: levelDebug, wantLog: "debug"},
+		{name: "flag over config", args: []string{"-v"}, detailLevel: "minimal", want: levelVerbose, wantLog: "info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTempConfig(t)
+			config.Current().UI.DetailLevel = tt.detailLevel
+			resetOutputFlags(t)
+			t.Cleanup(func() { resetOutputFlags(t) })
+			if err := rootCmd.PersistentFlags().Parse(tt.args); err != nil {
+				t.Fatalf("Parse(%q): %v", tt.args, err)
+			}
+
+			level := currentOutputLevel()
+			if level != tt.want {
+				t.Errorf("currentOutputLevel() = %d, want %d", level, tt.want)
+			}
+			if got := logLevelFor(level); got != tt.wantLog {
+				t.Errorf("logLevelFor(%d) = %q, want %q", level, got, tt.wantLog)
+			}
+			if got := DebugOutput(); got != (tt.want == levelDebug) {
+				t.Errorf("DebugOutput() = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"delguard/internal/utils"
+)
+
+// pickerPageSize 选择器一次显示的最多项目数
+const pickerPageSize = 15
+
+// pickerItem 选择器中的一项，按Label和Detail过滤
+type pickerItem struct {
+	Label  string
+	Detail string
+}
+
+// picker 可模糊过滤的多选列表的状态
+type picker struct {
+	title    string
+	items    []pickerItem
+	color    bool
+	query    []rune
+	visible  []int // 与查询匹配的项目下标，按得分排序
+	cursor   int   // 光标在visible中的位置
+	offset   int   // 第一行显示的visible位置
+	selected map[int]bool
+}
+
+// pickerAvailable 标准输入和输出都是终端时才能使用交互式选择器
+func pickerAvailable() bool {
+	return stdinIsTerminal() && utils.IsTerminal(os.Stdout.Fd())
+}
+
+// runPicker 在终端中显示选择器，返回选中项目在items中的下标，按items顺序排列
+// 用户取消时返回nil；未选中任何项目就按Enter时选中光标所在项目
+func runPicker(title string, items []pickerItem, color bool) ([]int, error) {
+	restoreInput, err := utils.MakeRaw(os.Stdin.Fd())
+	if err != nil {
+		return nil, fmt.Errorf("无法切换终端模式: %v", err)
+	}
+	defer restoreInput()
+	restoreOutput, err := utils.EnableANSI(os.Stdout.Fd())
+	if err != nil {
+		return nil, fmt.Errorf("无法切换终端模式: %v", err)
+	}
+	defer restoreOutput()
+
+	// 使用备用屏幕，退出后恢复原有的终端内容
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	p := &picker{title: title, items: items, color: color, selected: make(map[int]bool)}
+	p.filter()
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		p.render()
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return nil, err
+		}
+
+		switch r {
+		case 3: // Ctrl+C
+			return nil, nil
+		case 27: // Esc或方向键等转义序列
+			if reader.Buffered() == 0 {
+				return nil, nil
+			}
+			p.handleEscape(reader)
+		case '\r', '\n':
+			return p.result(), nil
+		case '\t':
+			p.toggle()
+		case 1: // Ctrl+A 选中或取消全部匹配项
+			p.toggleAll()
+		case 16: // Ctrl+P
+			p.move(-1)
+		case 14: // Ctrl+N
+			p.move(1)
+		case 127, 8: // Backspace
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case 21: // Ctrl+U 清空查询
+			p.query = nil
+			p.filter()
+		default:
+			if unicode.IsPrint(r) {
+				p.query = append(p.query, r)
+				p.filter()
+			}
+		}
+	}
+}
+
+// handleEscape 处理方向键和翻页键的转义序列，忽略其他序列
+func (p *picker) handleEscape(reader *bufio.Reader) {
+	if next, _ := reader.ReadByte(); next != '[' && next != 'O' {
+		return
+	}
+	key, _ := reader.ReadByte()
+	switch key {
+	case 'A':
+		p.move(-1)
+	case 'B':
+		p.move(1)
+	case '5', '6': // PageUp/PageDown，后跟'~'
+		reader.ReadByte()
+		if key == '5' {
+			p.move(-pickerPageSize)
+		} else {
+			p.move(pickerPageSize)
+		}
+	}
+}
+
+// filter 按当前查询重新计算匹配项目，有查询时按得分排序
+func (p *picker) filter() {
+	query := string(p.query)
+	scores := make(map[int]int)
+	p.visible = p.visible[:0]
+	for i, item := range p.items {
+		score, ok := utils.FuzzyScore(query, item.Label)
+		if detailScore, detailOK := utils.FuzzyScore(query, item.Detail); detailOK && (!ok || detailScore/2 > score) {
+			// 路径等附加信息中的匹配权重较低
+			score, ok = detailScore/2, true
+		}
+		if ok {
+			scores[i] = score
+			p.visible = append(p.visible, i)
+		}
+	}
+	if query != "" {
+		sort.SliceStable(p.visible, func(a, b int) bool {
+			return scores[p.visible[a]] > scores[p.visible[b]]
+		})
+	}
+	p.cursor, p.offset = 0, 0
+}
+
+// move 移动光标并保持光标在可见范围内
+func (p *picker) move(delta int) {
+	if len(p.visible) == 0 {
+		return
+	}
+	p.cursor += delta
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+	if p.cursor >= len(p.visible) {
+		p.cursor = len(p.visible) - 1
+	}
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+pickerPageSize {
+		p.offset = p.cursor - pickerPageSize + 1
+	}
+}
+
+// toggle 切换光标所在项目的选中状态并下移一行
+func (p *picker) toggle() {
+	if len(p.visible) == 0 {
+		return
+	}
+	index := p.visible[p.cursor]
+	if p.selected[index] {
+		delete(p.selected, index)
+	} else {
+		p.selected[index] = true
+	}
+	p.move(1)
+}
+
+// toggleAll 全部匹配项都已选中时取消选中，否则全部选中
+func (p *picker) toggleAll() {
+	allSelected := true
+	for _, index := range p.visible {
+		if !p.selected[index] {
+			allSelected = false
+			break
+		}
+	}
+	for _, index := range p.visible {
+		if allSelected {
+			delete(p.selected, index)
+		} else {
+			p.selected[index] = true
+		}
+	}
+}
+
+// result 返回选中的项目下标
+func (p *picker) result() []int {
+	if len(p.selected) == 0 {
+		if len(p.visible) == 0 {
+			return nil
+		}
+		return []int{p.visible[p.cursor]}
+	}
+	result := make([]int, 0, len(p.selected))
+	for index := range p.selected {
+		result = append(result, index)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// render 重绘整个选择器
+func (p *picker) render() {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "%s\n", p.title)
+	b.WriteString("   输入以过滤  ↑/↓ 移动  Tab 选择  Ctrl+A 全选  Enter 确认  Esc 取消\n\n")
+	fmt.Fprintf(&b, "🔎 %s\n", string(p.query))
+	fmt.Fprintf(&b, "   匹配 %d / %d 项，已选 %d 项\n\n", len(p.visible), len(p.items), len(p.selected))
+
+	end := p.offset + pickerPageSize
+	if end > len(p.visible) {
+		end = len(p.visible)
+	}
+	for row := p.offset; row < end; row++ {
+		index := p.visible[row]
+		item := p.items[index]
+		mark := "[ ]"
+		if p.selected[index] {
+			mark = "[x]"
+		}
+		pointer := "  "
+		if row == p.cursor {
+			pointer = "> "
+		}
+		line := fmt.Sprintf("%s%s %s", pointer, mark, item.Label)
+		switch {
+		case p.color && row == p.cursor:
+			line = "\x1b[7m" + line + "\x1b[0m"
+		case p.color && p.selected[index]:
+			line = "\x1b[32m" + line + "\x1b[0m"
+		}
+		b.WriteString(line)
+		if item.Detail != "" {
+			if p.color {
+				fmt.Fprintf(&b, "  \x1b[2m%s\x1b[0m", item.Detail)
+			} else {
+				fmt.Fprintf(&b, "  %s", item.Detail)
+			}
+		}
+		b.WriteString("\n")
+	}
+	if len(p.visible) == 0 {
+		b.WriteString("   没有匹配的项目\n")
+	} else if end < len(p.visible) {
+		fmt.Fprintf(&b, "   ... 还有 %d 项\n", len(p.visible)-end)
+	}
+	fmt.Print(b.String())
+}
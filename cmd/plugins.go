@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"delguard/internal/config"
+	"delguard/internal/i18n"
+	"delguard/internal/plugin"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginsCmd 插件管理命令
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "保护规则插件",
+	Long: `管理删除前调用的保护规则插件。
+
+插件目录 (integration.plugin_directory) 中名为 protect-*.sh 或 protect-*.exe 的程序
+会在每次删除前被调用，参数为待删除的路径，stdin为JSON格式的上下文。
+退出码 0 表示允许，1 表示阻止（stdout为原因），2 表示需要用户确认。`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出已发现的插件",
+	RunE:  runPluginsList,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.AddCommand(pluginsListCmd)
+}
+
+func runPluginsList(cmd *cobra.Command, args []string) error {
+	verbose := currentOutputLevel() >= levelVerbose
+
+	dir := plugin.Directory(config.Current())
+	if dir == "" {
+		fmt.Println("❌ 未配置插件目录 (integration.plugin_directory)")
+		return nil
+	}
+
+	plugins, err := plugin.Discover(dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📂 插件目录: %s\n", dir)
+	if len(plugins) == 0 {
+		fmt.Println("🔌 未发现插件")
+		return nil
+	}
+
+	var decisions map[string]plugin.Result
+	if verbose {
+		if decisions, err = plugin.LoadDecisions(); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	fmt.Printf("🔌 %s\n", i18n.Plural("plugins.count", len(plugins)))
+	for _, p := range plugins {
+		fmt.Printf("  • %s\n", p.Name)
+		if !verbose {
+			continue
+		}
+		fmt.Printf("      路径: %s\n", p.Path)
+		last, ok := decisions[p.Name]
+		if !ok {
+			fmt.Println("      最近判定: 无")
+			continue
+		}
+		fmt.Printf("      最近判定: %s %s (%s)\n", last.Decision, last.Path, last.Time.Format("2006-01-02 15:04:05"))
+		if last.Reason != "" {
+			fmt.Printf("      原因: %s\n", last.Reason)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
+	"delguard/internal/utils"
+)
+
+// previewReport 预览模式的处理计划，delete与restore的 --dry-run --json 共用此格式
+type previewReport struct {
+	Operation  string          `json:"operation"` // delete、shred或restore
+	Items      []previewItem   `json:"items"`
+	TotalBytes int64           `json:"total_bytes"` // 预计写入目标卷的字节数，同卷移动不计入
+	Conflicts  int             `json:"conflicts"`
+	Excluded   int             `json:"excluded,omitempty"` // 按 --exclude 和 --exclude-from 保留的条目数，只用于delete
+	Volumes    []previewVolume `json:"volumes"`
+	// Plan 删除目标展开后的统计，只用于delete和shred
+	Plan *filesystem.DeletionPlan `json:"plan,omitempty"`
+}
+
+// previewItem 单个项目的处理计划
+type previewItem struct {
+	Name        string           `json:"name"`
+	Source      string           `json:"source"`
+	Destination string           `json:"destination,omitempty"` // 粉碎时为空
+	Size        int64            `json:"size"`
+	IsDirectory bool             `json:"is_directory"`
+	CreateDirs  bool             `json:"create_dirs,omitempty"` // 目标的上级目录不存在，将被创建
+	Conflict    *previewConflict `json:"conflict,omitempty"`
+	Error       string           `json:"error,omitempty"`        // 无法确定目标位置的原因
+	PurgeRemote bool             `json:"purge_remote,omitempty"` // 位于远程文件系统，按配置将被永久删除
+}
+
+// previewConflict 目标位置已存在的文件
+type previewConflict struct {
+	Path            string    `json:"path"`
+	ExistingSize    int64     `json:"existing_size"`
+	ExistingModTime time.Time `json:"existing_mod_time"`
+	IncomingSize    int64     `json:"incoming_size"`
+	IncomingModTime time.Time `json:"incoming_mod_time"`
+	Resolution      string    `json:"resolution"` // rename或overwrite
+}
+
+// previewVolume 目标卷的空间检查结果
+type previewVolume struct {
+	Path       string `json:"path"`
+	Required   int64  `json:"required"`
+	Free       uint64 `json:"free"`
+	Sufficient bool   `json:"sufficient"`
+	Error      string `json:"error,omitempty"`
+}
+
+// addItem 记录一个项目；与来源不在同一卷时计入需要写入的字节数
+func (r *previewReport) addItem(item previewItem, required map[string]*previewVolume) {
+	if item.Conflict != nil {
+		r.Conflicts++
+	}
+	r.Items = append(r.Items, item)
+	if item.Destination == "" {
+		return
+	}
+
+	target, err := filesystem.VolumeOf(item.Destination)
+	if err != nil {
+		r.TotalBytes += item.Size
+		required[item.Destination] = &previewVolume{Path: filepath.Dir(item.Destination), Required: item.Size, Error: err.Error()}
+		return
+	}
+	if source, err := filesystem.VolumeOf(item.Source); err == nil && source.ID == target.ID {
+		return
+	}
+
+	r.TotalBytes += item.Size
+	volume, ok := required[target.ID]
+	if !ok {
+		volume = &previewVolume{Path: filepath.Dir(item.Destination), Free: target.Free}
+		required[target.ID] = volume
+	}
+	volume.Required += item.Size
+}
+
+// finish 汇总各目标卷的空间检查结果
+func (r *previewReport) finish(required map[string]*previewVolume) {
+	r.Volumes = []previewVolume{}
+	for _, volume := range required {
+		volume.Sufficient = volume.Error == "" && uint64(volume.Required) <= volume.Free
+		r.Volumes = append(r.Volumes, *volume)
+	}
+	sort.Slice(r.Volumes, func(i, j int) bool { return r.Volumes[i].Path < r.Volumes[j].Path })
+	if r.Items == nil {
+		r.Items = []previewItem{}
+	}
+}
+
+// newPreviewConflict 比较目标位置已存在的文件与将要写入的项目
+func newPreviewConflict(path string, existing os.FileInfo, incomingSize int64, incomingModTime time.Time, resolution string) *previewConflict {
+	existingSize := existing.Size()
+	if existing.IsDir() {
+		existingSize = filesystem.TreeSize(path)
+	}
+	return &previewConflict{
+		Path:            path,
+		ExistingSize:    existingSize,
+		ExistingModTime: existing.ModTime(),
+		IncomingSize:    incomingSize,
+		IncomingModTime: incomingModTime,
+		Resolution:      resolution,
+	}
+}
+
+// parentMissing 目标的上级目录不存在时返回true
+func parentMissing(path string) bool {
+	_, err := os.Stat(filepath.Dir(path))
+	return os.IsNotExist(err)
+}
+
+// printPreview 以文本形式输出预览计划
+func printPreview(header string, report previewReport) {
+	fmt.Println(header)
+	for i, item := range report.Items {
+		icon := "📄"
+		if item.IsDirectory {
+			icon = "📁"
+		}
+		if item.Error != "" {
+			fmt.Printf("  %d. %s %s ❌ %s\n", i+1, icon, item.Name, item.Error)
+			continue
+		}
+		if item.Destination == "" {
+			fmt.Printf("  %d. %s %s (%s)\n", i+1, icon, item.Name, utils.FormatSize(item.Size))
+		} else {
+			fmt.Printf("  %d. %s %s -> %s (%s)\n", i+1, icon, item.Name, item.Destination, utils.FormatSize(item.Size))
+		}
+		if item.CreateDirs {
+			fmt.Printf("     📂 将创建目录: %s\n", filepath.Dir(item.Destination))
+		}
+		if item.PurgeRemote {
+			fmt.Println("     🌐 位于远程文件系统，将被永久删除，不经过回收站")
+		}
+		if conflict := item.Conflict; conflict != nil {
+			fmt.Printf("     ⚠️  冲突: %s 已存在 (%s, %s)，回收站中的版本 (%s, %s)\n", conflict.Path,
+				utils.FormatSize(conflict.ExistingSize), conflict.ExistingModTime.Format("2006-01-02 15:04"),
+				utils.FormatSize(conflict.IncomingSize), conflict.IncomingModTime.Format("2006-01-02 15:04"))
+			if conflict.Resolution == "overwrite" {
+				fmt.Println("        将覆盖已存在的文件")
+			} else {
+				fmt.Printf("        将重命名为 %s\n", filepath.Base(item.Destination))
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("💾 预计写入: %s", utils.FormatSize(report.TotalBytes))
+	if report.Conflicts > 0 {
+		fmt.Printf("，%s", i18n.Plural("preview.conflicts", report.Conflicts))
+	}
+	fmt.Println()
+	for _, volume := range report.Volumes {
+		switch {
+		case volume.Error != "":
+			fmt.Printf("   ⚠️  无法检查 %s 的可用空间: %s\n", volume.Path, volume.Error)
+		case volume.Sufficient:
+			fmt.Printf("   ✅ %s 所在磁盘可用 %s，需要 %s\n", volume.Path, utils.FormatSize(int64(volume.Free)), utils.FormatSize(volume.Required))
+		default:
+			fmt.Printf("   ❌ %s 所在磁盘空间不足: 可用 %s，需要 %s\n", volume.Path, utils.FormatSize(int64(volume.Free)), utils.FormatSize(volume.Required))
+		}
+	}
+}
+
+// printDeletionPlan 输出删除目标展开后的文件数、总大小和需要注意的项目
+func printDeletionPlan(plan *filesystem.DeletionPlan) {
+	fmt.Printf("📋 影响范围: %s, %s, %s\n", i18n.Plural("count.files", int(plan.Files)),
+		i18n.Plural("count.dirs", int(plan.Directories)), formatUsage(plan.TotalSize, plan.DiskSize))
+	if plan.Truncated {
+		fmt.Println("   ⚠️  条目过多，统计已提前停止，实际数量更多")
+	}
+	if plan.IssueCount == 0 {
+		return
+	}
+
+	fmt.Printf("   ⚠️  %s\n", i18n.Plural("plan.issues", plan.IssueCount))
+	for _, issue := range plan.Issues {
+		icon := "⚠️ "
+		if issue.Kind == filesystem.IssueCritical {
+			icon = "🛡️ "
+		}
+		fmt.Printf("     %s %s (%s)\n", icon, issue.Path, issue.Message)
+	}
+	if hidden := plan.IssueCount - len(plan.Issues); hidden > 0 {
+		fmt.Printf("     %s\n", i18n.Plural("count.items_more", hidden))
+	}
+}
+
+// presentDeletionPlan 删除目标包含目录时在确认前输出影响范围
+func presentDeletionPlan(files []string) {
+	for _, file := range files {
+		if info, err := os.Lstat(file); err == nil && info.IsDir() {
+			if plan, err := filesystem.PlanDeletion(files); err == nil {
+				printDeletionPlan(plan)
+			}
+			return
+		}
+	}
+}
+
+// formatUsage 以实际占用的磁盘空间描述大小，与表观大小相差超过10%时同时显示表观大小
+func formatUsage(apparent, onDisk int64) string {
+	if !filesystem.UsageDiffers(apparent, onDisk) {
+		return "共 " + utils.FormatSize(onDisk)
+	}
+	return fmt.Sprintf("占用磁盘 %s（表观大小 %s）", utils.FormatSize(onDisk), utils.FormatSize(apparent))
+}
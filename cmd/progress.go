@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"delguard/internal/config"
+
+	"github.com/spf13/viper"
+)
+
+// 进度显示样式，由--progress或ui.progress_style设置
+const (
+	progressNone     = "none"
+	progressBar      = "bar"
+	progressSpinner  = "spinner"
+	progressDots     = "dots"
+	progressDetailed = "detailed"
+)
+
+// progressBarWidth bar样式进度条的宽度
+const progressBarWidth = 20
+
+// spinnerFrames spinner样式依次显示的字符
+var spinnerFrames = []string{"|", "/", "-", `\`}
+
+// progressReporter 显示批量操作的进度，除dots外都在同一行内用回车刷新
+// 样式为none时不输出任何内容，管道输出中不会出现回车符
+type progressReporter struct {
+	style   string
+	out     io.Writer
+	frame   int
+	lastLen int
+	printed bool
+}
+
+// newProgress 按输出详细程度和进度样式创建进度显示
+func newProgress(level outputLevel) *progressReporter {
+	return &progressReporter{style: progressStyle(level), out: os.Stdout}
+}
+
+// progressStyle 返回生效的进度样式：静默模式下总是none；未使用--progress且关闭了ui.progress_bar时为none，
+// 否则按--progress、ui.progress_style的优先级选择，默认为bar
+func progressStyle(level outputLevel) string {
+	if level == levelMinimal {
+		return progressNone
+	}
+	if cfg := config.Current(); !rootCmd.PersistentFlags().Changed("progress") && cfg != nil && !cfg.UI.ProgressBar {
+		return progressNone
+	}
+	style := strings.ToLower(viper.GetString("ui.progress_style"))
+	for _, known := range config.ProgressStyles {
+		if style == known {
+			return style
+		}
+	}
+	return progressBar
+}
+
+// Update 显示已处理done/total个项目，detail为当前项目的说明，只在detailed样式中显示
+func (p *progressReporter) Update(done, total int, detail string) {
+	var line string
+	switch p.style {
+	case progressNone:
+		return
+	case progressDots:
+		fmt.Fprint(p.out, ".")
+		p.printed = true
+		return
+	case progressSpinner:
+		line = fmt.Sprintf("%s %d/%d", spinnerFrames[p.frame%len(spinnerFrames)], done, total)
+		p.frame++
+	case progressDetailed:
+		line = fmt.Sprintf("进度: %d/%d (%d%%) %s", done, total, percent(done, total), detail)
+	default:
+		filled := progressBarWidth * done / max(total, 1)
+		line = fmt.Sprintf("[%s%s] %d%% %d/%d", strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled),
+			percent(done, total), done, total)
+	}
+
+	// 用空格覆盖上一次更长的输出
+	padding := ""
+	if width := len([]rune(line)); width < p.lastLen {
+		padding = strings.Repeat(" ", p.lastLen-width)
+	} else {
+		p.lastLen = width
+	}
+	fmt.Fprintf(p.out, "\r%s%s", line, padding)
+	p.printed = true
+}
+
+// Done 结束进度显示，输出过进度时换行
+func (p *progressReporter) Done() {
+	if p.printed {
+		fmt.Fprintln(p.out)
+		p.printed = false
+	}
+}
+
+// percent 计算百分比，total为0时返回100
+func percent(done, total int) int {
+	if total <= 0 {
+		return 100
+	}
+	return done * 100 / total
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"delguard/internal/config"
+
+	"github.com/spf13/viper"
+)
+
+// setProgressFlag 设置--progress，value为空时视为未指定；config.Reset会清空viper，因此重新绑定
+func setProgressFlag(t *testing.T, value string) {
+	t.Helper()
+	flag := rootCmd.PersistentFlags().Lookup("progress")
+	reset := func() {
+		flag.Value.Set("")
+		flag.Changed = false
+	}
+	reset()
+	t.Cleanup(reset)
+	if value != "" {
+		if err := rootCmd.PersistentFlags().Set("progress", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := viper.BindPFlag("ui.progress_style", flag); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProgressStyleSelection(t *testing.T) {
+	tests := []struct {
+		name        string
+		flag        string
+		configStyle string
+		progressBar bool
+		level       outputLevel
+		want        string
+	}{
+		{name: "default", progressBar: true, level: levelNormal, want: progressBar},
+		{name: "config spinner", configStyle: "spinner", progressBar: true, level: levelNormal, want: progressSpinner},
+		{name: "flag none", flag: "none", configStyle: "spinner", progressBar: true, level: levelNormal, want: progressNone},
+		{name: "flag bar", flag: "bar", progressBar: true, level: levelNormal, want: progressBar},
+		{name: "flag spinner", flag: "spinner", progressBar: true, level: levelNormal, want: progressSpinner},
+		{name: "flag dots", flag: "DOTS", progressBar: true, level: levelNormal, want: progressDots},
+		{name: "flag detailed over config", flag: "detailed", configStyle: "dots", progressBar: true, level: levelVerbose, want: progressDetailed},
+		{name: "progress bar disabled", configStyle: "spinner", level: levelNormal, want: progressNone},
+		{name: "flag over disabled progress bar", flag: "dots", level: levelNormal, want: progressDots},
+		{name: "quiet implies none", flag: "detailed", progressBar: true, level: levelMinimal, want: progressNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTempConfig(t)
+			config.Current().UI.ProgressBar = tt.progressBar
+			// 配置值必须低于标志的优先级，viper.Set会覆盖标志
+			viper.SetDefault("ui.progress_style", tt.configStyle)
+			setProgressFlag(t, tt.flag)
+
+			if got := progressStyle(tt.level); got != tt.want {
+				t.Errorf("progressStyle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// renderProgress 以style显示3个项目的进度并结束，返回全部输出
+func renderProgress(style string) string {
+	var out bytes.Buffer
+	progress := &progressReporter{style: style, out: &out}
+	for i, name := range []string{"long-file-name.txt", "b", "c"} {
+		progress.Update(i+1, 3, name)
+	}
+	progress.Done()
+	return out.String()
+}
+
+func TestProgressReporterStyles(t *testing.T) {
+	tests := []struct {
+		style string
+		want  []string
+	}{
+		{progressBar, []string{"\r[======              ] 33% 1/3", "\r[====================] 100% 3/3"}},
+		{progressSpinner, []string{"\r| 1/3", "\r/ 2/3", "\r- 3/3"}},
+		{progressDetailed, []string{"进度: 1/3 (33%) long-file-name.txt", "进度: 3/3 (100%) c"}},
+		{progressDots, []string{"...\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			output := renderProgress(tt.style)
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output %q is missing %q", output, want)
+				}
+			}
+			if !strings.HasSuffix(output, "\n") {
+				t.Errorf("output %q does not end the progress line", output)
+			}
+		})
+	}
+
+	// 较短的一行用空格覆盖上一行剩余的字符
+	if output := renderProgress(progressDetailed); !strings.Contains(output, "进度: 2/3 (66%) b"+strings.Repeat(" ", len("long-file-name.txt")-1)) {
+		t.Errorf("detailed output %q does not pad over the previous line", output)
+	}
+	if output := renderProgress(progressDots); strings.Contains(output, "\r") {
+		t.Errorf("dots output %q contains carriage returns", output)
+	}
+}
+
+func TestProgressNoneEmitsNothing(t *testing.T) {
+	if output := renderProgress(progressNone); output != "" {
+		t.Errorf("none style wrote %q", output)
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"delguard/internal/config"
+	"delguard/internal/filesystem"
+	"delguard/internal/paths"
+)
+
+// 删除目标的保护类别，按严重程度从高到低排列
+const (
+	protectSystem   = "system"   // 系统文件或受保护的SELinux类型
+	protectDelGuard = "delguard" // DelGuard自身的程序、配置或状态数据
+	protectHidden   = "hidden"   // 隐藏文件
+	protectClean    = "clean"    // 没有命中任何保护
+)
+
+// protectionCategory 保护类别的显示名称和确认方式
+type protectionCategory struct {
+	Name  string
+	Icon  string
+	Title string
+	// Word 删除此类别的目标时必须完整输入的确认词，为空时按普通方式确认
+	Word string
+}
+
+// protectionCategories 按严重程度排列的保护类别
+var protectionCategories = []protectionCategory{
+	{Name: protectSystem, Icon: "🛡️ ", Title: "系统关键", Word: "DELETE"},
+	{Name: protectDelGuard, Icon: "🔧", Title: "DelGuard自身", Word: "YES"},
+	{Name: protectHidden, Icon: "👻", Title: "隐藏"},
+	{Name: protectClean, Icon: "✅", Title: "普通"},
+}
+
+// protectionFinding 单个删除目标的保护检查结果
+type protectionFinding struct {
+	Path     string
+	Category string
+	Reason   string
+}
+
+// classifyTarget 检查删除目标命中的最严重的保护类别及具体原因
+func classifyTarget(path string) protectionFinding {
+	finding := protectionFinding{Path: path, Category: protectClean}
+	if reason := systemFileReason(path); reason != "" {
+		finding.Category, finding.Reason = protectSystem, reason
+		return finding
+	}
+	if selinuxType, ok := protectedSELinuxLabel(path); ok {
+		finding.Category, finding.Reason = protectSystem, fmt.Sprintf("SELinux类型 %s", selinuxType)
+		return finding
+	}
+	if reason := delguardDataReason(path); reason != "" {
+		finding.Category, finding.Reason = protectDelGuard, reason
+		return finding
+	}
+	if name := filepath.Base(path); strings.HasPrefix(name, ".") && name != "." && name != ".." {
+		finding.Category, finding.Reason = protectHidden, "以.开头的隐藏文件"
+	}
+	return finding
+}
+
+// delguardDataReason 检查路径是否为或包含DelGuard的程序、配置文件或状态目录，返回原因
+func delguardDataReason(path string) string {
+	type ownPath struct{ path, what string }
+	own := []ownPath{{paths.ConfigDir(), "DelGuard配置目录"}, {config.ConfigFileUsed(), "DelGuard配置文件"}}
+	if exe, err := os.Executable(); err == nil {
+		own = append(own, ownPath{exe, "DelGuard程序"})
+	}
+	if dir, err := paths.StateDir(); err == nil {
+		own = append(own, ownPath{dir, "DelGuard状态目录"})
+	}
+
+	for _, item := range own {
+		if item.path == "" {
+			continue
+		}
+		if filesystem.ContainsPath(path, item.path) {
+			if filepath.Clean(path) == filepath.Clean(item.path) {
+				return item.what
+			}
+			return fmt.Sprintf("包含%s %s", item.what, item.path)
+		}
+	}
+	return ""
+}
+
+// confirmProtectedTargets 删除目标命中保护时按类别列出每个目标及原因，
+// 需要更强确认的类别逐类要求输入确认词，未通过确认的类别被跳过，其余目标原样返回，之后按普通方式确认
+// 没有需要更强确认的目标且之后不会询问（prompting为false）时不输出任何内容
+func confirmProtectedTargets(files []string, prompting bool) []string {
+	groups := make(map[string][]protectionFinding)
+	protected, escalated := false, false
+	for _, file := range files {
+		finding := classifyTarget(file)
+		groups[finding.Category] = append(groups[finding.Category], finding)
+		protected = protected || finding.Category != protectClean
+		escalated = escalated || finding.Category == protectSystem || finding.Category == protectDelGuard
+	}
+	if !escalated && (!protected || !prompting) {
+		return files
+	}
+
+	fmt.Println("🔍 删除目标的保护检查:")
+	for _, category := range protectionCategories {
+		findings := groups[category.Name]
+		if len(findings) == 0 {
+			continue
+		}
+		fmt.Printf("   %s %s (%d):\n", category.Icon, category.Title, len(findings))
+		for _, finding := range findings {
+			if finding.Reason != "" {
+				fmt.Printf("     • %s — %s\n", finding.Path, finding.Reason)
+			} else {
+				fmt.Printf("     • %s\n", finding.Path)
+			}
+		}
+	}
+
+	rejected := make(map[string]bool)
+	for _, category := range protectionCategories {
+		findings := groups[category.Name]
+		if category.Word == "" || len(findings) == 0 {
+			continue
+		}
+		fmt.Printf("⚠️  删除以上 %d 个%s项目需要输入 %s 确认，其他输入将跳过这些项目: ", len(findings), category.Title, category.Word)
+		if confirmed, _ := confirmCritical(category.Word); confirmed {
+			continue
+		}
+		fmt.Printf("⏭️  跳过 %d 个%s项目\n", len(findings), category.Title)
+		for _, finding := range findings {
+			rejected[finding.Path] = true
+		}
+	}
+
+	var kept []string
+	for _, file := range files {
+		if !rejected[file] {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/filesystem"
+	"delguard/internal/report"
+	"delguard/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// reportCmd 删除回执命令
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "查看删除操作回执",
+	Long: `查看删除操作的回执。
+启用 logging.report_enabled 后，每次删除都会在状态目录的 reports 下写入一份回执
+（默认 ~/.delguard，设置XDG_STATE_HOME时为 $XDG_STATE_HOME/delguard），
+记录每个项目的大小、哈希（如可用）、执行用户和处理结果。
+回执与日志使用相同的保留天数 (logging.max_age)。`,
+}
+
+var reportShowCmd = &cobra.Command{
+	Use:   "show <操作ID>",
+	Short: "显示一次删除操作的回执",
+	Long: `显示指定操作的回执，操作ID可以是唯一的前缀。
+
+示例:
+  delguard report show 3f9a2c1d
+  delguard report show 3f9a --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReportShow,
+}
+
+var reportListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出删除操作回执",
+	Long: `列出指定时间范围内的删除操作回执。
+--since 支持天数（如 7d）、Go时长（如 12h）或日期（如 2024-01-31）。
+
+示例:
+  delguard report list
+  delguard report list --since 7d`,
+	RunE: runReportList,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportShowCmd)
+	reportCmd.AddCommand(reportListCmd)
+
+	reportShowCmd.Flags().Bool("json", false, "以JSON格式输出")
+	reportListCmd.Flags().String("since", "7d", "只列出此时间之后的操作")
+	reportListCmd.Flags().Bool("json", false, "以JSON格式输出")
+}
+
+func runReportShow(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	receipt, err := report.Load(config.GetReportDir(), args[0])
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return printJSON(receipt)
+	}
+
+	fmt.Printf("🧾 操作 %s (%s)\n", receipt.ID, receipt.Operation)
+	fmt.Printf("   用户: %s", receipt.User)
+	if receipt.Host != "" {
+		fmt.Printf("@%s", receipt.Host)
+	}
+	fmt.Println()
+	fmt.Printf("   时间: %s - %s\n", receipt.StartedAt.Format("2006-01-02 15:04:05"), receipt.FinishedAt.Format("15:04:05"))
+	fmt.Println()
+
+	for i, item := range receipt.Items {
+		icon := "📄"
+		if item.IsDirectory {
+			icon = "📁"
+		}
+		fmt.Printf("  %d. %s %s %s (%s)\n", i+1, outcomeIcon(item.Outcome), icon, item.Path, utils.FormatSize(item.Size))
+		if item.TrashPath != "" {
+			fmt.Printf("     -> %s\n", item.TrashPath)
+		}
+		if item.Hash != "" {
+			fmt.Printf("     SHA256: %s\n", item.Hash)
+		}
+		if item.Verify != "" {
+			fmt.Printf("     删除前校验: %s\n", item.Verify)
+		}
+		if item.Reason != "" {
+			fmt.Printf("     %s\n", item.Reason)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 %s\n", formatReceiptSummary(receipt.Summary))
+	return nil
+}
+
+func runReportList(cmd *cobra.Command, args []string) error {
+	sinceValue, _ := cmd.Flags().GetString("since")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	since, err := parseSince(sinceValue, time.Now())
+	if err != nil {
+		return err
+	}
+	receipts, err := report.List(config.GetReportDir(), since)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		if receipts == nil {
+			receipts = []*report.Receipt{}
+		}
+		return printJSON(receipts)
+	}
+
+	if len(receipts) == 0 {
+		fmt.Printf("📭 %s 之后没有删除回执\n", since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t时间\t操作\t用户\t结果")
+	for _, receipt := range receipts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", receipt.ID, receipt.StartedAt.Format("2006-01-02 15:04"),
+			receipt.Operation, receipt.User, formatReceiptSummary(receipt.Summary))
+	}
+	return w.Flush()
+}
+
+// newReceipt 启用logging.report_enabled时创建操作回执，否则返回nil
+func newReceipt(operation string) *report.Receipt {
+	if cfg := config.Current(); cfg == nil || !cfg.Logging.ReportEnabled {
+		return nil
+	}
+	return report.New(operation)
+}
+
+// saveReceipt 补充回收站位置和哈希后写入回执，并按日志保留天数清理旧回执
+// manager为nil时（粉碎）不查询回收站
+func saveReceipt(manager filesystem.TrashManager, receipt *report.Receipt, quiet bool) {
+	if receipt == nil {
+		return
+	}
+	if manager != nil {
+		fillTrashedDetails(manager, receipt)
+	}
+	receipt.Confirmation = confirmAnswered
+	receipt.Finish()
+
+	dir := config.GetReportDir()
+	path, err := receipt.Write(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  写入删除回执失败: %v\n", err)
+		return
+	}
+	if !quiet {
+		fmt.Printf("🧾 操作ID %s，回执: %s\n", receipt.ID, path)
+	}
+	maxAge := 0
+	if cfg := config.Current(); cfg != nil {
+		maxAge = cfg.Logging.MaxAge
+	}
+	if _, err := report.Prune(dir, maxAge); err != nil && !quiet {
+		fmt.Fprintf(os.Stderr, "⚠️  清理旧回执失败: %v\n", err)
+	}
+}
+
+// fillTrashedDetails 一次列出回收站，为已移入回收站的项目补充位置、大小和哈希
+func fillTrashedDetails(manager filesystem.TrashManager, receipt *report.Receipt) {
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return
+	}
+	latest := make(map[string]filesystem.TrashFile)
+	for _, file := range files {
+		if found, ok := latest[file.OriginalPath]; !ok || file.DeletedTime.After(found.DeletedTime) {
+			latest[file.OriginalPath] = file
+		}
+	}
+
+	for i := range receipt.Items {
+		item := &receipt.Items[i]
+		file, ok := latest[item.Path]
+		if item.Outcome != report.OutcomeTrashed || !ok {
+			continue
+		}
+		item.TrashPath = file.TrashPath
+		metadata := filesystem.LoadMetadata(manager, file)
+		item.Hash = metadata.Hash
+		if metadata.Size > 0 {
+			item.Size = metadata.Size
+		}
+	}
+}
+
+// skippedByPlugin 被保护规则插件阻止或未通过插件确认的项目
+func skippedByPlugin(file string, err error) report.Item {
+	reason := "未确认插件提示"
+	if err != nil {
+		reason = err.Error()
+	}
+	return report.Item{Path: file, Outcome: report.OutcomeSkipped, Reason: reason}
+}
+
+// outcomeIcon 返回处理结果对应的图标
+func outcomeIcon(outcome string) string {
+	switch outcome {
+	case report.OutcomeTrashed:
+		return "🗑️ "
+	case report.OutcomeShredded:
+		return "🔥"
+	case report.OutcomeDeleted:
+		return "💥"
+	case report.OutcomeFailed:
+		return "❌"
+	case report.OutcomeQuarantined:
+		return "☣️ "
+	default:
+		return "⏭️ "
+	}
+}
+
+// formatReceiptSummary 格式化回执汇总
+func formatReceiptSummary(summary report.Summary) string {
+	parts := []string{fmt.Sprintf("共 %d 项", summary.Total)}
+	if summary.Trashed > 0 {
+		parts = append(parts, fmt.Sprintf("移入回收站 %d", summary.Trashed))
+	}
+	if summary.Shredded > 0 {
+		parts = append(parts, fmt.Sprintf("粉碎 %d", summary.Shredded))
+	}
+	if summary.Deleted > 0 {
+		parts = append(parts, fmt.Sprintf("永久删除 %d", summary.Deleted))
+	}
+	if summary.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("失败 %d", summary.Failed))
+	}
+	if summary.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("跳过 %d", summary.Skipped))
+	}
+	if summary.Quarantined > 0 {
+		parts = append(parts, fmt.Sprintf("隔离 %d", summary.Quarantined))
+	}
+	return fmt.Sprintf("%s，%s", strings.Join(parts, "，"), utils.FormatSize(summary.Bytes))
+}
+
+// parseSince 解析时间范围：天数（7d）、Go时长（12h）或日期（2006-01-02）
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间范围 %q，请使用 7d、12h 或 2006-01-02 格式", value)
+}
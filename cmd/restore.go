@@ -4,13 +4,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"delguard/internal/config"
+	"delguard/internal/errors"
 	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
 	"delguard/internal/security"
+	"delguard/internal/utils"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // restoreCmd 恢复命令
@@ -28,20 +33,124 @@ var restoreCmd = &cobra.Command{
   delguard restore 1 2 3          # 按索引恢复
   delguard restore --all           # 恢复所有文件
   delguard restore file.txt --to=/path/to/restore
-  delguard recover file.txt        # 别名`,
+  delguard restore --from-file list.txt   # 按清单恢复
+  delguard recover file.txt        # 别名
+
+清单每行一个回收站ID或原始路径，以#开头的行为注释；
+写成 "ID -> 目标路径" 时恢复到指定位置，目标是目录时恢复到该目录下。
+恢复结束后逐行输出结果（已恢复、已跳过或失败）。
+
+恢复位置已存在文件且未使用 -f 时，按 restore.rename_pattern 改名（默认 {name}_{n}{ext}），
+改名后的位置会在输出和 --dry-run --json 的 destination 中给出。`,
 	RunE: runRestore,
 }
 
+// trashRestoreCmd 在可模糊过滤的列表中选择要恢复的项目
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore [文件名或索引...]",
+	Short: "从回收站恢复文件，-i 打开可模糊过滤的选择器",
+	Long: `从回收站恢复文件。使用 -i 且在终端中运行时打开选择器：
+输入字符按文件名和原始路径模糊过滤，↑/↓ 移动，Tab 选择多项，Ctrl+A 选择全部匹配项，
+Enter 将选中的项目恢复到原始位置，Esc 取消。
+
+指定了文件名或索引、使用 --all，或标准输入输出不是终端时，
+与 delguard restore 相同，按参数选择要恢复的文件。
+
+示例:
+  delguard trash restore -i
+  delguard trash restore -i --filter "*.go"   # 只列出匹配的项目
+  delguard trash restore file.txt`,
+	RunE: runTrashRestore,
+}
+
 func init() {
 	rootCmd.AddCommand(restoreCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
 
-	// 添加标志
-	restoreCmd.Flags().StringP("to", "t", "", "恢复到指定目录（默认恢复到原始位置）")
-	restoreCmd.Flags().BoolP("all", "a", false, "恢复所有文件")
-	restoreCmd.Flags().BoolP("force", "f", false, "强制恢复，覆盖已存在的文件")
-	restoreCmd.Flags().BoolP("interactive", "i", false, "交互式恢复，每个文件都询问")
-	restoreCmd.Flags().StringP("filter", "F", "", "按模式过滤要恢复的文件")
-	restoreCmd.Flags().BoolP("dry-run", "n", false, "预览模式，显示将要恢复的文件但不实际恢复")
+	addRestoreFlags(restoreCmd, "交互式恢复，每个文件都询问")
+	addRestoreFlags(trashRestoreCmd, "在终端中打开选择器选择要恢复的项目")
+}
+
+// addRestoreFlags 添加恢复命令共用的标志
+func addRestoreFlags(cmd *cobra.Command, interactiveUsage string) {
+	cmd.Flags().StringP("to", "t", "", "恢复到指定目录（默认恢复到原始位置）")
+	cmd.Flags().BoolP("all", "a", false, "恢复所有文件")
+	cmd.Flags().BoolP("force", "f", false, "强制恢复，覆盖已存在的文件")
+	cmd.Flags().BoolP("interactive", "i", false, interactiveUsage)
+	cmd.Flags().StringP("filter", "F", "", "按模式过滤要恢复的文件")
+	cmd.Flags().BoolP("dry-run", "n", false, "预览模式，显示将要恢复的文件但不实际恢复")
+	cmd.Flags().Bool("json", false, "预览模式下以JSON格式输出")
+	cmd.Flags().String("from-file", "", "按清单文件恢复，每行一个回收站ID或原始路径")
+	cmd.Flags().Bool("system-bin", false, "同时从Windows系统回收站中选择项目，索引与 list --system-bin 一致")
+}
+
+// runTrashRestore 在终端中用选择器选出项目，再按索引交给runRestore恢复
+// 不满足使用选择器的条件时直接按参数恢复
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	restoreAll, _ := cmd.Flags().GetBool("all")
+	filter, _ := cmd.Flags().GetString("filter")
+	if !interactive || restoreAll || len(args) > 0 || !pickerAvailable() {
+		return runRestore(cmd, args)
+	}
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return errors.FromOS("初始化回收站管理器失败", err)
+	}
+	// 与list相同只读取一次回收站列表，选择结果以其中的索引传给runRestore
+	trashFiles, err := manager.ListTrashFiles()
+	if err != nil {
+		return errors.FromOS("获取回收站文件列表失败", err)
+	}
+	if trashFiles, err = withSystemBin(cmd, trashFiles); err != nil {
+		return err
+	}
+
+	var candidates []int
+	var pickerItems []pickerItem
+	for i, item := range trashFiles {
+		if filter != "" {
+			if matched, err := matchPattern(item.Name, filter); err != nil {
+				return errors.NewError(errors.ErrTypeUsage, "过滤器模式错误", err)
+			} else if !matched {
+				continue
+			}
+		}
+		candidates = append(candidates, i+1)
+		pickerItems = append(pickerItems, pickerItem{
+			Label:  markedName(item),
+			Detail: fmt.Sprintf("%s  %s", item.OriginalPath, item.DeletedTime.Format("2006-01-02 15:04")),
+		})
+	}
+	if len(candidates) == 0 {
+		if filter != "" {
+			return errors.NewError(errors.ErrTypeFileNotFound, "没有找到匹配的文件", nil)
+		}
+		fmt.Println("🗑️  " + i18n.T("common.trash_empty"))
+		return nil
+	}
+
+	cfg := config.Current()
+	color := cfg != nil && cfg.UI.Color
+	chosen, err := runPicker("🔄 选择要恢复的项目", pickerItems, color)
+	if err != nil {
+		return err
+	}
+	if len(chosen) == 0 {
+		fmt.Println("❌ " + i18n.T("common.cancelled"))
+		return nil
+	}
+
+	indexArgs := make([]string, 0, len(chosen))
+	for _, c := range chosen {
+		indexArgs = append(indexArgs, strconv.Itoa(candidates[c]))
+	}
+
+	// 已在选择器中选定，不再逐个询问，过滤器也已应用
+	cmd.Flags().Set("interactive", "false")
+	cmd.Flags().Set("filter", "")
+	return runRestore(cmd, indexArgs)
 }
 
 func runRestore(cmd *cobra.Command, args []string) error {
@@ -52,35 +161,61 @@ func runRestore(cmd *cobra.Command, args []string) error {
 	interactive, _ := cmd.Flags().GetBool("interactive")
 	filter, _ := cmd.Flags().GetString("filter")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	verbose := viper.GetBool("verbose")
-	quiet := viper.GetBool("quiet")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	fromFile, _ := cmd.Flags().GetString("from-file")
+	level := currentOutputLevel()
+	verbose := level >= levelVerbose
+	quiet := level == levelMinimal
+
+	// safe_mode为strict时-f不会跳过确认，也不会覆盖已存在的文件
+	if policy := safeModePolicy(); force && !policy.HonorForce {
+		force = false
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "🔒 安全模式 %s: 忽略 -f，已存在的文件不会被覆盖\n", policy.Mode)
+		}
+	}
 
 	// 获取回收站管理器
-	manager, err := filesystem.GetTrashManager()
+	manager, err := newTrashManager()
 	if err != nil {
-		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+		return errors.FromOS("初始化回收站管理器失败", err)
 	}
 
 	// 获取回收站文件列表
 	trashFiles, err := manager.ListTrashFiles()
 	if err != nil {
-		return fmt.Errorf("获取回收站文件列表失败: %v", err)
+		return errors.FromOS("获取回收站文件列表失败", err)
+	}
+	if trashFiles, err = withSystemBin(cmd, trashFiles); err != nil {
+		return err
 	}
 
 	if len(trashFiles) == 0 {
 		if !quiet {
-			fmt.Println("🗑️  回收站是空的")
+			fmt.Println("🗑️  " + i18n.T("common.trash_empty"))
 		}
 		return nil
 	}
 
 	var filesToRestore []filesystem.TrashFile
+	var manifest *restoreManifest
+	var targets map[string]string
 
-	if restoreAll {
+	if fromFile != "" {
+		if restoreAll || len(args) > 0 || filter != "" {
+			return errors.NewError(errors.ErrTypeUsage, "--from-file 不能与文件参数、--all 或 --filter 同时使用", nil)
+		}
+		manifest, err = loadRestoreManifest(fromFile, trashFiles)
+		if err != nil {
+			return err
+		}
+		filesToRestore = manifest.files
+		targets = manifest.targets
+	} else if restoreAll {
 		// 恢复所有文件
 		filesToRestore = trashFiles
 	} else if len(args) == 0 && filter == "" {
-		return fmt.Errorf("请指定要恢复的文件名、索引或使用 --all 恢复所有文件")
+		return errors.NewError(errors.ErrTypeUsage, "请指定要恢复的文件名、索引或使用 --all 恢复所有文件", nil)
 	} else {
 		// 根据参数选择文件
 		filesToRestore, err = selectFilesToRestore(trashFiles, args, filter)
@@ -90,145 +225,281 @@ func runRestore(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(filesToRestore) == 0 {
-		return fmt.Errorf("没有找到匹配的文件")
+		if manifest != nil {
+			manifest.printReport()
+		}
+		return errors.NewError(errors.ErrTypeFileNotFound, "没有找到匹配的文件", nil)
 	}
 
-	// 预览模式
+	// 预览模式，不做任何修改
 	if dryRun {
-		fmt.Println("🔍 预览模式 - 以下文件将被恢复:")
-		for i, file := range filesToRestore {
-			restorePath := getRestorePath(file, targetDir)
-			fmt.Printf("  %d. 📄 %s -> %s\n", i+1, file.Name, restorePath)
+		report := planRestore(filesToRestore, targetDir, targets, force)
+		if asJSON {
+			return printJSON(report)
 		}
+		printPreview("🔍 预览模式 - 以下文件将被恢复:", report)
 		return nil
 	}
 
 	// 确认恢复
 	if !force && !interactive && len(filesToRestore) > 1 {
-		fmt.Printf("🔄 将要恢复 %d 个文件，确认吗? [y/N]: ", len(filesToRestore))
-		var response string
-		_, err := fmt.Scanln(&response)
+		fmt.Printf("🔄 %s", i18n.Plural("restore.confirm", len(filesToRestore)))
+		confirmed, err := confirmAction()
 		if err != nil {
 			// 处理输入错误
-			fmt.Println("❌ 读取输入失败，操作已取消")
+			fmt.Println("❌ " + i18n.T("common.input_failed"))
 			return nil
 		}
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
-			fmt.Println("❌ 操作已取消")
+		if !confirmed {
+			fmt.Println("❌ " + i18n.T("common.cancelled"))
 			return nil
 		}
 	}
 
 	// 创建路径验证器
 	validator := security.NewPathValidator()
+
+	// 创建恶意软件扫描器（未启用时为nil）
+	scanner := newMalwareScanner(config.Current())
 	
 	// 执行恢复
 	successCount := 0
-	errorCount := 0
+	failures := errors.NewErrorCollector()
+	if manifest != nil {
+		for _, item := range manifest.unresolved() {
+			failures.Add(item.Path, item.Err)
+		}
+	}
+	operation := startOperation(cmd, "恢复")
+	defer operation.Finish()
 
 	// 批量处理优化
 	batchSize := 10
-	if len(filesToRestore) > batchSize {
-		fmt.Printf("🔄 正在批量恢复 %d 个文件...\n", len(filesToRestore))
+	if len(filesToRestore) > batchSize && !quiet {
+		fmt.Printf("🔄 %s\n", i18n.Plural("restore.batch", len(filesToRestore)))
 	}
 
+	progress := newProgress(level)
 	for i, file := range filesToRestore {
 		// 显示进度
-		if len(filesToRestore) > batchSize && !quiet {
-			fmt.Printf("进度: %d/%d\r", i+1, len(filesToRestore))
+		if len(filesToRestore) > batchSize {
+			progress.Update(i+1, len(filesToRestore), file.Name)
+		}
+
+		// trash verify发现内容已损坏的项目不会被静默恢复
+		if file.Corrupt && !force {
+			err := errors.NewError(errors.ErrTypeIO, fmt.Sprintf("'%s' 的内容与删除时记录的哈希不一致", file.Name), nil)
+			err.Hint = "使用 --force 仍然恢复已损坏的内容"
+			failures.Add(file.Name, err)
+			manifest.record(file, manifestFailed, "", "内容已损坏")
+			fmt.Fprintf(os.Stderr, "⚠️  拒绝恢复: %v\n   %s\n", err, err.Hint)
+			continue
 		}
 
 		// 确定恢复路径
-		restorePath := getRestorePath(file, targetDir)
+		restorePath, err := resolveRestorePath(file, targetDir, targets)
+		if err != nil {
+			failures.Add(file.Name, err)
+			manifest.record(file, manifestFailed, "", err.Error())
+			fmt.Fprintln(os.Stderr, "❌ "+i18n.T("restore.failed", file.Name, err))
+			continue
+		}
 		
 		// 验证恢复路径安全性
 		if err := validator.ValidateRestorePath(restorePath); err != nil {
 			if !quiet {
 				fmt.Fprintf(os.Stderr, "⚠️  安全警告: %s - %v\n", file.Name, err)
 			}
+			manifest.record(file, manifestSkipped, "", err.Error())
 			continue
 		}
 
 		// 交互式确认
 		if interactive {
-			fmt.Printf("恢复 '%s' 到 '%s'? [y/N]: ", file.Name, restorePath)
-			var response string
-			_, err := fmt.Scanln(&response)
+			fmt.Print(i18n.T("restore.prompt", file.Name, restorePath))
+			confirmed, err := confirmAction()
 			if err != nil {
 				if verbose {
-					fmt.Printf("⏭️  跳过: %s (输入错误)\n", file.Name)
+					fmt.Println("⏭️  " + i18n.T("restore.skipped_input", file.Name))
 				}
+				manifest.record(file, manifestSkipped, "", "输入错误")
 				continue
 			}
-			response = strings.ToLower(strings.TrimSpace(response))
-			if response != "y" && response != "yes" {
+			if !confirmed {
 				if verbose {
-					fmt.Printf("⏭️  跳过: %s\n", file.Name)
+					fmt.Println("⏭️  " + i18n.T("restore.skipped", file.Name))
 				}
+				manifest.record(file, manifestSkipped, "", "未确认")
 				continue
 			}
 		}
 
 		// 检查目标文件是否已存在
 		if !force {
-			if _, err := os.Stat(restorePath); err == nil {
+			if existing, ok := filesystem.ExistingEntry(restorePath); ok {
+				if !quiet && existing != restorePath {
+					fmt.Fprintln(os.Stderr, "⚠️  "+i18n.T("restore.case_conflict", filepath.Base(restorePath), filepath.Base(existing)))
+				}
 				// 如果文件已存在，添加后缀
-				ext := filepath.Ext(restorePath)
-				base := restorePath[:len(restorePath)-len(ext)]
-				counter := 1
-				for {
-					newPath := fmt.Sprintf("%s_%d%s", base, counter, ext)
-					if _, err := os.Stat(newPath); os.IsNotExist(err) {
-						restorePath = newPath
-						break
+				restorePath = nextAvailablePath(restorePath)
+				if !quiet {
+					fmt.Fprintln(os.Stderr, "⚠️  "+i18n.T("restore.renamed", filepath.Base(restorePath)))
+				}
+			}
+		}
+
+		// 恢复前扫描文件
+		if scanner != nil {
+			if err := scanner.Scan(file.TrashPath); err != nil {
+				if errors.IsType(err, errors.ErrTypeMalware) {
+					failures.Add(file.Name, err)
+					manifest.record(file, manifestFailed, "", err.Error())
+					if !quiet {
+						fmt.Fprintf(os.Stderr, "🦠 拒绝恢复 '%s': %v\n", file.Name, err)
 					}
-					counter++
+					continue
 				}
 				if !quiet {
-					fmt.Fprintf(os.Stderr, "⚠️  文件已存在，重命名为: %s\n", filepath.Base(restorePath))
+					fmt.Fprintf(os.Stderr, "⚠️  扫描 '%s' 失败，继续恢复: %v\n", file.Name, err)
 				}
 			}
 		}
 
 		// 执行恢复
-		err := manager.RestoreFile(file, restorePath)
-		if err != nil {
-			errorCount++
+		started := time.Now()
+		err = manager.RestoreFile(file, restorePath)
+		if errors.IsType(err, errors.ErrTypeSpecialFile) {
 			if !quiet {
-				fmt.Fprintf(os.Stderr, "❌ 恢复失败 '%s': %v\n", file.Name, err)
+				fmt.Printf("⏭️  跳过 '%s': %s\n", file.Name, errors.GetErrorMessage(err))
 			}
+			manifest.record(file, manifestSkipped, "", errors.GetErrorMessage(err))
+			continue
+		}
+		if err != nil {
+			// 静默模式下仍然输出错误
+			failures.Add(file.Name, err)
+			manifest.record(file, manifestFailed, "", err.Error())
+			fmt.Fprintln(os.Stderr, "❌ "+i18n.T("restore.failed", file.Name, err))
 		} else {
 			successCount++
+			manifest.record(file, manifestRestored, restorePath, "")
+			operation.Add(1, file.Size)
 			if verbose {
-				fmt.Printf("✅ 已恢复: %s -> %s\n", file.Name, restorePath)
+				fmt.Println("✅ " + i18n.T("restore.restored_to", file.Name, restorePath))
+				if level >= levelDebug {
+					fmt.Printf("   ⏱️  %v, %s\n", time.Since(started).Round(time.Microsecond), filesystem.TrashBackend(file.TrashPath))
+				}
 			} else if !quiet {
-				fmt.Printf("✅ 已恢复: %s\n", file.Name)
+				fmt.Println("✅ " + i18n.T("restore.restored", file.Name))
 			}
 		}
 	}
 
-	// 显示结果摘要
+	// 显示结果摘要，静默模式下也输出
+	progress.Done()
 	if !quiet {
-		if len(filesToRestore) > 10 {
-			fmt.Println() // 换行
-		}
 		fmt.Println() // 换行
-		if successCount > 0 {
-			fmt.Printf("✅ 成功恢复 %d 个文件\n", successCount)
-		}
-		if errorCount > 0 {
-			fmt.Printf("❌ %d 个文件恢复失败\n", errorCount)
-		}
+	}
+	if manifest != nil {
+		manifest.printReport()
+		fmt.Println()
+	}
+	if successCount > 0 {
+		fmt.Printf("✅ %s\n", i18n.Plural("restore.done", successCount))
+	}
+	if failures.HasErrors() {
+		fmt.Printf("❌ %s\n", i18n.Plural("restore.failed", failures.Len()))
 	}
 
-	if errorCount > 0 {
-		return fmt.Errorf("部分文件恢复失败")
+	if failures.HasErrors() {
+		return errors.NewError(failures.Type(), "部分文件恢复失败", nil)
 	}
 
 	return nil
 }
 
+// nextAvailablePath 按restore.rename_pattern改名，返回第一个不存在的路径
+func nextAvailablePath(path string) string {
+	return nextUnplannedPath(path, nil)
+}
+
+// nextUnplannedPath 与nextAvailablePath相同，同时跳过planned中已被本批次其他项目占用的位置
+// planned的键经过utils.FoldPath处理，不区分大小写的卷上Foo.txt与foo.txt视为同一位置
+func nextUnplannedPath(path string, planned map[string]bool) string {
+	return filesystem.ConflictFreePath(path, func(candidate string) bool {
+		return planned[utils.FoldPath(candidate)]
+	})
+}
+
+// planRestore 计算每个项目的恢复位置、冲突和所需空间，不修改任何文件
+func planRestore(files []filesystem.TrashFile, targetDir string, targets map[string]string, force bool) previewReport {
+	report := previewReport{Operation: "restore"}
+	required := make(map[string]*previewVolume)
+	planned := make(map[string]bool)
+	for _, file := range files {
+		item := previewItem{
+			Name:        file.Name,
+			Source:      file.TrashPath,
+			Size:        file.Size,
+			IsDirectory: file.IsDirectory,
+		}
+		destination, err := resolveRestorePath(file, targetDir, targets)
+		if err != nil {
+			item.Error = err.Error()
+			report.addItem(item, required)
+			continue
+		}
+		item.Destination = destination
+		var incomingModTime time.Time
+		if info, err := os.Lstat(file.TrashPath); err == nil {
+			incomingModTime = info.ModTime()
+			if info.IsDir() {
+				item.Size = filesystem.TreeSize(file.TrashPath)
+			}
+		}
+
+		if existing, ok := existingEntryInfo(item.Destination); ok {
+			resolution := "overwrite"
+			if !force {
+				resolution = "rename"
+			}
+			item.Conflict = newPreviewConflict(existing.path, existing.info, item.Size, incomingModTime, resolution)
+			if !force {
+				item.Destination = nextUnplannedPath(item.Destination, planned)
+			}
+		} else if !force && planned[utils.FoldPath(item.Destination)] {
+			// 同一批次中的其他项目会先恢复到这里，例如不区分大小写的卷上的Foo.txt和foo.txt
+			item.Destination = nextUnplannedPath(item.Destination, planned)
+		}
+		planned[utils.FoldPath(item.Destination)] = true
+		item.CreateDirs = parentMissing(item.Destination)
+		report.addItem(item, required)
+	}
+	report.finish(required)
+	return report
+}
+
+// existingEntry 恢复位置上已存在的条目
+type existingEntry struct {
+	path string // 磁盘上的实际路径，不区分大小写的文件系统上可能与恢复位置只有大小写不同
+	info os.FileInfo
+}
+
+// existingEntryInfo 查找恢复位置上已存在的条目，符号链接取其指向的文件信息，悬空时取链接本身
+func existingEntryInfo(path string) (existingEntry, bool) {
+	existingPath, ok := filesystem.ExistingEntry(path)
+	if !ok {
+		return existingEntry{}, false
+	}
+	info, err := os.Stat(existingPath)
+	if err != nil {
+		if info, err = os.Lstat(existingPath); err != nil {
+			return existingEntry{}, false
+		}
+	}
+	return existingEntry{path: existingPath, info: info}, true
+}
+
 // selectFilesToRestore 选择要恢复的文件
 func selectFilesToRestore(trashFiles []filesystem.TrashFile, args []string, filter string) ([]filesystem.TrashFile, error) {
 	var selected []filesystem.TrashFile
@@ -238,7 +509,7 @@ func selectFilesToRestore(trashFiles []filesystem.TrashFile, args []string, filt
 		for _, file := range trashFiles {
 			matched, err := matchPattern(file.Name, filter)
 			if err != nil {
-				return nil, fmt.Errorf("过滤器模式错误: %v", err)
+				return nil, errors.NewError(errors.ErrTypeUsage, "过滤器模式错误", err)
 			}
 			if matched {
 				selected = append(selected, file)
@@ -277,7 +548,7 @@ func selectFilesToRestore(trashFiles []filesystem.TrashFile, args []string, filt
 		}
 
 		if !found {
-			return nil, fmt.Errorf("未找到文件: %s", arg)
+			return nil, errors.NewError(errors.ErrTypeFileNotFound, fmt.Sprintf("未找到文件: %s", arg), nil)
 		}
 	}
 
@@ -300,19 +571,27 @@ func parseIndex(s string, maxIndex int) int {
 	return idx
 }
 
-// getRestorePath 获取恢复路径
-func getRestorePath(file filesystem.TrashFile, targetDir string) string {
+// resolveRestorePath 获取恢复路径，targets中为该项目指定了位置（来自恢复清单）时优先使用
+func resolveRestorePath(file filesystem.TrashFile, targetDir string, targets map[string]string) (string, error) {
+	if target, ok := targets[file.TrashPath]; ok {
+		return target, nil
+	}
+	return getRestorePath(file, targetDir)
+}
+
+// getRestorePath 获取恢复路径，原始路径来自其他系统且无法对应到本机时返回错误
+func getRestorePath(file filesystem.TrashFile, targetDir string) (string, error) {
 	if targetDir != "" {
 		// 恢复到指定目录
-		return filepath.Join(targetDir, file.Name)
+		return filepath.Join(targetDir, file.Name), nil
 	}
 
-	// 恢复到原始位置
+	// 恢复到原始位置，在其他系统上删除的文件需要转换为本机路径
 	if file.OriginalPath != "" {
-		return file.OriginalPath
+		return filesystem.NativePath(file.OriginalPath)
 	}
 
 	// 如果没有原始路径信息，恢复到当前目录
 	currentDir, _ := os.Getwd()
-	return filepath.Join(currentDir, file.Name)
+	return filepath.Join(currentDir, file.Name), nil
 }
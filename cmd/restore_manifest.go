@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"delguard/internal/errors"
+	"delguard/internal/filesystem"
+	"delguard/internal/utils"
+)
+
+// manifestArrow 清单中分隔项目与恢复目标的记号
+const manifestArrow = "->"
+
+// 清单中每一行的处理结果
+const (
+	manifestRestored = "restored"
+	manifestSkipped  = "skipped"
+	manifestFailed   = "failed"
+)
+
+// manifestEntry 恢复清单中的一行
+type manifestEntry struct {
+	Line   int
+	Ref    string // 回收站ID或原始路径
+	Target string // "->" 之后指定的恢复位置，为空时按 --to 或原始位置恢复
+}
+
+// manifestResult 清单中一行的处理结果
+type manifestResult struct {
+	Status string
+	Name   string // 对应的回收站项目，无法对应时为空
+	Path   string // 恢复到的位置
+	Detail string
+}
+
+// restoreManifest 已对应到回收站项目的恢复清单
+type restoreManifest struct {
+	entries []manifestEntry
+	results []manifestResult
+	files   []filesystem.TrashFile
+	targets map[string]string // 回收站路径 -> 清单指定的恢复位置
+	entryOf map[string]int    // 回收站路径 -> 清单中的条目序号
+}
+
+// parseRestoreManifest 解析恢复清单：每行一个回收站ID或原始路径，
+// 可写成 "ID -> 目标路径" 指定恢复位置，空行和以#开头的行被忽略
+func parseRestoreManifest(r io.Reader) ([]manifestEntry, error) {
+	var entries []manifestEntry
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry := manifestEntry{Line: line, Ref: text}
+		if ref, target, ok := strings.Cut(text, manifestArrow); ok {
+			entry.Ref = strings.TrimSpace(ref)
+			entry.Target = strings.TrimSpace(target)
+			if entry.Target == "" {
+				return nil, errors.NewError(errors.ErrTypeValidation, fmt.Sprintf("清单第%d行: %s 之后缺少恢复目标", line, manifestArrow), nil)
+			}
+		}
+		if entry.Ref == "" {
+			return nil, errors.NewError(errors.ErrTypeValidation, fmt.Sprintf("清单第%d行: 缺少回收站ID或原始路径", line), nil)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.FromOS("读取清单失败", err)
+	}
+	return entries, nil
+}
+
+// loadRestoreManifest 读取清单文件并将每一行对应到回收站项目
+func loadRestoreManifest(path string, trashFiles []filesystem.TrashFile) (*restoreManifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.FromOS("打开清单文件失败", err)
+	}
+	defer file.Close()
+
+	entries, err := parseRestoreManifest(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.NewError(errors.ErrTypeValidation, fmt.Sprintf("清单中没有要恢复的项目: %s", path), nil)
+	}
+	return newRestoreManifest(entries, trashFiles)
+}
+
+// newRestoreManifest 将清单条目对应到回收站项目，找不到的条目记为失败，重复的条目记为跳过
+func newRestoreManifest(entries []manifestEntry, trashFiles []filesystem.TrashFile) (*restoreManifest, error) {
+	m := &restoreManifest{
+		entries: entries,
+		results: make([]manifestResult, len(entries)),
+		targets: make(map[string]string),
+		entryOf: make(map[string]int),
+	}
+	for i, entry := range entries {
+		file, ok := findManifestItem(trashFiles, entry.Ref)
+		if !ok {
+			m.results[i] = manifestResult{Status: manifestFailed, Detail: "回收站中没有该项目"}
+			continue
+		}
+		if first, seen := m.entryOf[file.TrashPath]; seen {
+			m.results[i] = manifestResult{Status: manifestSkipped, Name: file.Name,
+				Detail: fmt.Sprintf("与第%d行重复", entries[first].Line)}
+			continue
+		}
+
+		if entry.Target != "" {
+			target, err := manifestTarget(entry.Target, file)
+			if err != nil {
+				return nil, fmt.Errorf("清单第%d行: %v", entry.Line, err)
+			}
+			m.targets[file.TrashPath] = target
+		}
+		m.entryOf[file.TrashPath] = i
+		m.results[i] = manifestResult{Name: file.Name}
+		m.files = append(m.files, file)
+	}
+	return m, nil
+}
+
+// findManifestItem 按回收站ID或原始路径查找项目，同一路径被删除多次时取最近删除的版本
+func findManifestItem(trashFiles []filesystem.TrashFile, ref string) (filesystem.TrashFile, bool) {
+	for _, file := range trashFiles {
+		if file.ID != "" && file.ID == ref {
+			return file, true
+		}
+	}
+
+	wanted := filepath.Clean(ref)
+	if abs, err := filepath.Abs(ref); err == nil {
+		wanted = abs
+	}
+	var latest filesystem.TrashFile
+	found := false
+	for _, file := range trashFiles {
+		if file.OriginalPath == "" || !utils.SamePath(wanted, file.OriginalPath) {
+			continue
+		}
+		if !found || file.DeletedTime.After(latest.DeletedTime) {
+			latest = file
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// manifestTarget 计算清单指定的恢复位置，目标是已存在的目录或以路径分隔符结尾时恢复到该目录下
+func manifestTarget(target string, file filesystem.TrashFile) (string, error) {
+	intoDir := strings.HasSuffix(target, "/") || strings.HasSuffix(target, string(filepath.Separator))
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", fmt.Errorf("无法解析恢复目标 %s: %v", target, err)
+	}
+	if info, err := os.Stat(abs); err == nil && info.IsDir() {
+		intoDir = true
+	}
+	if intoDir {
+		return filepath.Join(abs, file.Name), nil
+	}
+	return abs, nil
+}
+
+// record 记录回收站项目对应的清单行的处理结果，m为nil时忽略
+func (m *restoreManifest) record(file filesystem.TrashFile, status string, path string, detail string) {
+	if m == nil {
+		return
+	}
+	if i, ok := m.entryOf[file.TrashPath]; ok {
+		m.results[i] = manifestResult{Status: status, Name: file.Name, Path: path, Detail: detail}
+	}
+}
+
+// unresolved 返回无法对应到回收站项目的清单行，作为恢复失败计入汇总
+func (m *restoreManifest) unresolved() []errors.CollectedError {
+	var items []errors.CollectedError
+	for i, result := range m.results {
+		if result.Status == manifestFailed && result.Name == "" {
+			items = append(items, errors.CollectedError{
+				Path: m.entries[i].Ref,
+				Err:  errors.NewFileNotFoundError(m.entries[i].Ref),
+			})
+		}
+	}
+	return items
+}
+
+// printReport 逐行输出清单的处理结果
+func (m *restoreManifest) printReport() {
+	fmt.Println("📋 清单恢复结果:")
+	for i, entry := range m.entries {
+		result := m.results[i]
+		name := result.Name
+		if name == "" {
+			name = entry.Ref
+		}
+
+		var line string
+		switch result.Status {
+		case manifestRestored:
+			line = fmt.Sprintf("✅ 已恢复 %s -> %s", name, result.Path)
+		case manifestSkipped:
+			line = fmt.Sprintf("⏭️  已跳过 %s", name)
+		case manifestFailed:
+			line = fmt.Sprintf("❌ 失败 %s", name)
+		default:
+			line = fmt.Sprintf("⏭️  未处理 %s", name)
+		}
+		if result.Detail != "" {
+			line += fmt.Sprintf(" (%s)", result.Detail)
+		}
+		fmt.Printf("  第%d行  %s\n", entry.Line, line)
+	}
+}
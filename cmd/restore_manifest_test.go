@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"delguard/internal/filesystem"
+)
+
+func TestParseRestoreManifest(t *testing.T) {
+	manifest := strings.Join([]string{
+		"# 需要恢复的报表",
+		"000001",
+		"",
+		"   /home/user/notes.txt   ",
+		"  # 缩进的注释",
+		"000002 -> /srv/restored/",
+		"/home/user/a.txt->/tmp/b.txt",
+	}, "\n")
+
+	entries, err := parseRestoreManifest(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("parseRestoreManifest: %v", err)
+	}
+	want := []manifestEntry{
+		{Line: 2, Ref: "000001"},
+		{Line: 4, Ref: "/home/user/notes.txt"},
+		{Line: 6, Ref: "000002", Target: "/srv/restored/"},
+		{Line: 7, Ref: "/home/user/a.txt", Target: "/tmp/b.txt"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestParseRestoreManifestRejectsIncompleteLines(t *testing.T) {
+	for _, manifest := range []string{"000001 ->", "# ok\n-> /tmp/target"} {
+		_, err := parseRestoreManifest(strings.NewReader(manifest))
+		if err == nil {
+			t.Errorf("manifest %q parsed without error", manifest)
+			continue
+		}
+		if !strings.Contains(err.Error(), "清单第") {
+			t.Errorf("error %q does not name the line", err)
+		}
+	}
+}
+
+func TestNewRestoreManifestResolvesEntries(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "notes.txt")
+	now := time.Now()
+	trashFiles := []filesystem.TrashFile{
+		{ID: "000001", Name: "report.pdf", OriginalPath: filepath.Join(dir, "report.pdf"), TrashPath: "/trash/1"},
+		{ID: "000002", Name: "notes.txt", OriginalPath: original, TrashPath: "/trash/2", DeletedTime: now.Add(-time.Hour)},
+		{ID: "000003", Name: "notes.txt", OriginalPath: original, TrashPath: "/trash/3", DeletedTime: now},
+	}
+	redirect := filepath.Join(dir, "elsewhere.pdf")
+	entries := []manifestEntry{
+		{Line: 1, Ref: "000001", Target: redirect},
+		{Line: 2, Ref: original, Target: dir + string(filepath.Separator)},
+		{Line: 3, Ref: "000003"},
+		{Line: 4, Ref: "missing"},
+	}
+
+	m, err := newRestoreManifest(entries, trashFiles)
+	if err != nil {
+		t.Fatalf("newRestoreManifest: %v", err)
+	}
+	if len(m.files) != 2 || m.files[0].ID != "000001" || m.files[1].ID != "000003" {
+		t.Fatalf("files = %+v, want 000001 and the latest notes.txt", m.files)
+	}
+	if got := m.targets["/trash/1"]; got != redirect {
+		t.Errorf("redirect target = %q, want %q", got, redirect)
+	}
+	if got, want := m.targets["/trash/3"], filepath.Join(dir, "notes.txt"); got != want {
+		t.Errorf("directory target = %q, want %q", got, want)
+	}
+	if m.results[2].Status != manifestSkipped || !strings.Contains(m.results[2].Detail, "第2行") {
+		t.Errorf("duplicate line result = %+v, want skipped as a repeat of line 2", m.results[2])
+	}
+	unresolved := m.unresolved()
+	if m.results[3].Status != manifestFailed || len(unresolved) != 1 || unresolved[0].Path != "missing" {
+		t.Errorf("missing line result = %+v, unresolved = %+v", m.results[3], unresolved)
+	}
+
+	m.record(trashFiles[0], manifestRestored, redirect, "")
+	m.record(trashFiles[1], manifestRestored, original, "")
+	if m.results[0].Status != manifestRestored || m.results[0].Path != redirect {
+		t.Errorf("recorded result = %+v", m.results[0])
+	}
+	if m.results[1].Status != "" {
+		t.Errorf("an item outside the manifest changed line 2: %+v", m.results[1])
+	}
+}
+
+func TestManifestTargetIntoExistingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filesystem.TrashFile{Name: "photo.jpg"}
+	tests := map[string]string{
+		dir:                               dir + string(filepath.Separator) + "photo.jpg",
+		filepath.Join(dir, "renamed.jpg"): filepath.Join(dir, "renamed.jpg"),
+		filepath.Join(dir, "new") + "/":   filepath.Join(dir, "new", "photo.jpg"),
+	}
+	for target, want := range tests {
+		got, err := manifestTarget(target, file)
+		if err != nil || got != want {
+			t.Errorf("manifestTarget(%q) = %q, %v; want %q", target, got, err, want)
+		}
+	}
+}
@@ -4,6 +4,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
+	"delguard/internal/logger"
+	"delguard/internal/notify"
+	"delguard/internal/security"
+	"delguard/internal/utils"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -11,6 +23,70 @@ import (
 
 var cfgFile string
 
+// trashDirOverride --trash-dir指定的本次调用使用的回收站目录
+var trashDirOverride string
+
+// langOverride --lang指定的本次调用使用的界面语言
+var langOverride string
+
+// flagConfigKeys 全局标志与其覆盖的配置项
+var flagConfigKeys = map[string]string{
+	"verbose":  "verbose",
+	"quiet":    "quiet",
+	"debug":    "debug",
+	"throttle": "performance.io_throttle",
+	"progress": "ui.progress_style",
+}
+
+// newTrashManager 创建命令使用的回收站管理器，测试时可替换为filesystem.FakeTrashManager
+var newTrashManager = func() (filesystem.TrashManager, error) {
+	// 标志可能覆盖配置文件中的限速设置，因此从viper读取
+	filesystem.SetMaintenanceThrottle(utils.NewThrottle(
+		viper.GetInt64("performance.io_throttle")*1024*1024,
+		time.Duration(viper.GetInt("performance.nice_delay"))*time.Millisecond,
+	))
+	filesystem.SetOperationTimeout(time.Duration(viper.GetInt("performance.timeout")) * time.Second)
+	filesystem.SetNetworkTimeout(time.Duration(viper.GetInt("performance.network_timeout")) * time.Second)
+	filesystem.SetCopyThrottle(utils.NewThrottle(viper.GetInt64("performance.copy_rate")*1024*1024, 0))
+	cfg := config.Current()
+	if cfg != nil {
+		filesystem.SetRetentionRules(cfg.Trash.RetentionRules)
+		filesystem.SetRenamePattern(cfg.Restore.RenamePattern)
+		filesystem.SetRestoreAttributes(cfg.Restore.PreserveTimes, cfg.Restore.PreserveOwner)
+		policy, err := filesystem.NewRotationPolicy(cfg.Trash)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  回收站轮转未启用: %v\n", err)
+		}
+		filesystem.SetRotationPolicy(policy)
+		filesystem.SetVerifyBeforePrune(cfg.Trash.VerifyBeforePrune, cfg.Performance.MaxConcurrent)
+	}
+	if trashDirOverride == "" {
+		return filesystem.NewTrashManager(cfg)
+	}
+	trashDir, err := security.NewPathValidator().ValidateTrashDir(trashDirOverride)
+	if err != nil {
+		return nil, errors.NewError(errors.ErrTypeInvalidPath, "--trash-dir 无效", err)
+	}
+	return filesystem.NewTrashManagerAt(cfg, trashDir)
+}
+
+// startedOperations 本次调用中开始的操作，用于匿名统计处理的项目数
+var startedOperations []*notify.Operation
+
+// startOperation 开始记录一次可能耗时较长的操作，结束时调用Finish按需发送桌面通知
+func startOperation(cmd *cobra.Command, kind string) *notify.Operation {
+	force, _ := cmd.Flags().GetBool("notify")
+	enabled := true
+	threshold := 30 * time.Second
+	if cfg := config.Current(); cfg != nil {
+		enabled = cfg.UI.Notifications
+		threshold = time.Duration(cfg.UI.NotifyThreshold) * time.Second
+	}
+	operation := notify.Start(kind, enabled, threshold, force)
+	startedOperations = append(startedOperations, operation)
+	return operation
+}
+
 // rootCmd 根命令
 var rootCmd = &cobra.Command{
 	Use:   "delguard",
@@ -24,20 +100,49 @@ var rootCmd = &cobra.Command{
 • 清空回收站
 • 跨平台支持 (Windows/macOS/Linux)`,
 	Version: "1.5.3",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		silenceForJSON(cmd)
+		offerFirstRunSetup(cmd)
+		offerTelemetryConsent(cmd)
+	},
 }
 
-// Execute 执行根命令
+// Execute 执行根命令，启用匿名统计时在命令执行期间后台发送之前的统计
 func Execute() error {
-	return rootCmd.Execute()
+	flushed := startTelemetryFlush()
+	cmd, err := rootCmd.ExecuteC()
+	activeCmd = cmd
+	if err == nil && confirmAborted {
+		aborted := errors.NewError(errors.ErrTypeTimeout, "等待确认超时，已按 ui.confirm_default: abort 中止操作", nil)
+		aborted.Hint = "请在 ui.confirm_timeout 秒内回答确认提示，或调整 ui.confirm_timeout、ui.confirm_default"
+		err = aborted
+	}
+	recordTelemetry(cmd, err)
+	<-flushed
+	return err
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	// 标志解析失败时不会执行PersistentPreRun，在这里同样按错误输出格式关闭cobra的文本输出，
+	// 并按用法错误返回，使退出码为64
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		silenceForJSON(cmd)
+		return errors.NewError(errors.ErrTypeUsage, err.Error(), nil)
+	})
+
 	// 全局标志
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "配置文件路径 (默认: $HOME/.delguard.yaml)")
-	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "详细输出")
-	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "静默模式")
+	rootCmd.PersistentFlags().CountP("verbose", "v", "详细输出，-vv显示每个文件的耗时和回收站后端")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "静默模式，只输出错误和最终汇总")
+	rootCmd.PersistentFlags().Bool("debug", false, "调试输出，等同于-vv，同时记录debug级别日志，出错时显示调用栈")
+	rootCmd.PersistentFlags().Int("throttle", 0, "维护任务的I/O速率上限(MB/s)，覆盖配置中的performance.io_throttle")
+	rootCmd.PersistentFlags().String("progress", "", "进度样式: none, bar, spinner, dots, detailed，覆盖配置中的ui.progress_style，none不输出进度")
+	rootCmd.PersistentFlags().Bool("notify", false, "操作完成后发送桌面通知，无论耗时长短")
+	rootCmd.PersistentFlags().StringVar(&trashDirOverride, "trash-dir", "", "本次调用使用的回收站目录，覆盖配置的回收站位置，不存在时自动创建")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "", "错误输出格式: text, json，json时每个错误以单行JSON输出到stderr，默认随命令的--json")
+	rootCmd.PersistentFlags().StringVar(&langOverride, "lang", "", "界面语言 (zh-CN/en-US)，覆盖环境变量DELGUARD_LANGUAGE和配置中的ui.language")
 
 	// 绑定标志到viper
 	if err := viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose")); err != nil {
@@ -46,10 +151,21 @@ func init() {
 	if err := viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet")); err != nil {
 		log.Printf("绑定quiet标志失败: %v", err)
 	}
+	if err := viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug")); err != nil {
+		log.Printf("绑定debug标志失败: %v", err)
+	}
+	if err := viper.BindPFlag("performance.io_throttle", rootCmd.PersistentFlags().Lookup("throttle")); err != nil {
+		log.Printf("绑定throttle标志失败: %v", err)
+	}
+	if err := viper.BindPFlag("ui.progress_style", rootCmd.PersistentFlags().Lookup("progress")); err != nil {
+		log.Printf("绑定progress标志失败: %v", err)
+	}
 }
 
 // initConfig 初始化配置
 func initConfig() {
+	applyLanguage()
+
 	if cfgFile != "" {
 		// 使用指定的配置文件
 		viper.SetConfigFile(cfgFile)
@@ -64,13 +180,46 @@ func initConfig() {
 		viper.SetConfigName(".delguard")
 	}
 
-	// 读取环境变量
-	viper.AutomaticEnv()
+	// 读取DELGUARD_前缀的环境变量，例如DELGUARD_TRASH_MAX_DAYS
+	config.BindEnv()
 
-	// 如果找到配置文件，则读取它
+	if style, _ := rootCmd.PersistentFlags().GetString("progress"); style != "" && !slices.Contains(config.ProgressStyles, strings.ToLower(style)) {
+		cobra.CheckErr(fmt.Errorf("未知的进度样式 %q，可选值: %s", style, strings.Join(config.ProgressStyles, ", ")))
+	}
+
+	if errorFormat != "" && !slices.Contains(errorFormats, strings.ToLower(errorFormat)) {
+		cobra.CheckErr(fmt.Errorf("未知的错误输出格式 %q，可选值: %s", errorFormat, strings.Join(errorFormats, ", ")))
+	}
+
+	// 记录由命令行标志显式设置的配置项，用于追溯配置来源
+	for flag, key := range flagConfigKeys {
+		if rootCmd.PersistentFlags().Changed(flag) {
+			config.MarkFlagOverride(key)
+		}
+	}
+
+	// 如果找到配置文件，则读取它，并重新解码当前配置使其中的设置（包括安全模式）生效
 	if err := viper.ReadInConfig(); err == nil {
-		if viper.GetBool("verbose") {
+		cobra.CheckErr(config.Reload())
+		if currentOutputLevel() >= levelVerbose {
 			fmt.Fprintln(os.Stderr, "使用配置文件:", viper.ConfigFileUsed())
 		}
 	}
+
+	// -v/-vv/--debug 和 ui.detail_level 同时提高日志级别
+	if level := logLevelFor(currentOutputLevel()); level != "" {
+		logger.EnableLevel(level)
+	}
+}
+
+// applyLanguage 按 --lang、环境变量DELGUARD_LANGUAGE、配置中的ui.language 的优先级设置界面语言
+func applyLanguage() {
+	lang := langOverride
+	if lang == "" {
+		lang = os.Getenv("DELGUARD_LANGUAGE")
+	}
+	if cfg := config.Current(); lang == "" && cfg != nil {
+		lang = cfg.UI.Language
+	}
+	i18n.SetLanguage(lang)
 }
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/i18n"
+)
+
+func TestApplyLanguagePrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		flag   string
+		env    string
+		config string
+		want   string
+	}{
+		{name: "default", want: i18n.LangZH},
+		{name: "config", config: "en-US", want: i18n.LangEN},
+		{name: "env over config", env: "zh-CN", config: "en-US", want: i18n.LangZH},
+		{name: "flag over env", flag: "en", env: "zh-CN", config: "zh-CN", want: i18n.LangEN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTempConfig(t)
+			config.Current().UI.Language = tt.config
+			t.Setenv("DELGUARD_LANGUAGE", tt.env)
+			savedFlag, savedLang := langOverride, i18n.Language()
+			langOverride = tt.flag
+			defer func() {
+				langOverride = savedFlag
+				i18n.SetLanguage(savedLang)
+			}()
+
+			applyLanguage()
+			if got := i18n.Language(); got != tt.want {
+				t.Errorf("language = %s, want %s", got, tt.want)
+			}
+			if got, want := i18n.Plural("delete.done", 2), i18n.PluralIn(tt.want, "delete.done", 2); got != want {
+				t.Errorf("delete summary = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestConfigFlagSafeModeIsEnforced(t *testing.T) {
+	initTempConfig(t)
+	resetOutputFlags(t)
+	strict := filepath.Join(t.TempDir(), "strict.yaml")
+	if err := os.WriteFile(strict, []byte("security:\n  safe_mode: strict\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved := cfgFile
+	cfgFile = strict
+	t.Cleanup(func() { cfgFile = saved })
+
+	initConfig()
+	if got := config.Current().Security.SafeMode; got != config.SafeModeStrict {
+		t.Fatalf("safe_mode = %q after --config, want %q", got, config.SafeModeStrict)
+	}
+
+	useFakeTrash(t)
+	file := writeTestFile(t, t.TempDir(), "f.txt")
+	flags := deleteCmd.Flags()
+	t.Cleanup(func() {
+		flags.Set("no-trash", "false")
+		flags.Set("yes", "false")
+	})
+	flags.Set("no-trash", "true")
+	flags.Set("yes", "true")
+	if err := runDelete(deleteCmd, []string{file}); !errors.IsType(err, errors.ErrTypeValidation) {
+		t.Errorf("rm --no-trash -y under strict = %v, want a validation error", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("file was deleted: %v", err)
+	}
+}
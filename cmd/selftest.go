@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"delguard/internal/errors"
+	"delguard/internal/filesystem"
+	"delguard/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// selftestCmd 在临时目录中验证删除和恢复的完整流程
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "在临时目录中验证删除到回收站和恢复的完整流程",
+	Long: `在临时目录中创建测试文件，通过当前使用的回收站删除、在回收站列表中核对元数据、
+恢复后比对内容哈希，最后清理临时文件，逐步报告结果。
+用于在新机器上确认DelGuard可用，或排查平台相关的问题（例如系统策略阻止移入回收站）。
+使用 --bench 重复执行 --count 次删除和恢复并统计耗时。
+
+任一步骤失败时以非零状态退出。
+
+示例:
+  delguard selftest
+  delguard selftest --trash-dir /tmp/dg-trash
+  delguard selftest --bench --count 50
+  delguard selftest --json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runSelftest,
+}
+
+// selftestSize 测试文件的大小
+const selftestSize = 64 * 1024
+
+// selftestStep 单个步骤的结果
+type selftestStep struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Message  string        `json:"message"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// selftestTiming 基准测试中一种操作的耗时统计
+type selftestTiming struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min_ns"`
+	Avg   time.Duration `json:"avg_ns"`
+	Max   time.Duration `json:"max_ns"`
+}
+
+// selftestReport 自检结果
+type selftestReport struct {
+	OS      string                    `json:"os"`
+	Arch    string                    `json:"arch"`
+	Backend string                    `json:"backend"`
+	Steps   []selftestStep            `json:"steps"`
+	Bench   map[string]selftestTiming `json:"bench,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().Bool("bench", false, "重复删除和恢复并统计耗时")
+	selftestCmd.Flags().Int("count", 20, "--bench 重复的次数")
+	selftestCmd.Flags().Bool("json", false, "以JSON格式输出")
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	bench, _ := cmd.Flags().GetBool("bench")
+	count, _ := cmd.Flags().GetInt("count")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if bench && count < 1 {
+		return fmt.Errorf("--count 必须大于0: %d", count)
+	}
+
+	report := selftestReport{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	manager, err := newTrashManager()
+	if err != nil {
+		report.Steps = append(report.Steps, selftestStep{Name: "初始化回收站", Status: doctorFail, Message: err.Error()})
+		return finishSelftest(report, asJSON)
+	}
+	if trashPath, err := manager.GetTrashPath(); err == nil {
+		report.Backend = filesystem.TrashBackend(trashPath)
+	}
+
+	// 使用解析过符号链接的路径，与回收站记录的原始路径比对
+	dir, err := os.MkdirTemp("", "delguard-selftest-")
+	if err == nil {
+		dir, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		report.Steps = append(report.Steps, selftestStep{Name: "创建临时目录", Status: doctorFail, Message: err.Error()})
+		return finishSelftest(report, asJSON)
+	}
+	defer os.RemoveAll(dir)
+
+	report.Steps = selftestRoundTrip(manager, filepath.Join(dir, "selftest.bin"))
+	if bench && !selftestFailed(report) {
+		timings, err := selftestBench(manager, dir, count)
+		step := selftestStep{Name: "基准测试", Status: doctorPass, Message: fmt.Sprintf("完成 %d 次删除和恢复", count)}
+		if err != nil {
+			step.Status = doctorFail
+			step.Message = err.Error()
+		}
+		report.Steps = append(report.Steps, step)
+		report.Bench = timings
+	}
+	return finishSelftest(report, asJSON)
+}
+
+// selftestRoundTrip 创建测试文件，删除到回收站、核对列表中的元数据、恢复并比对内容，某一步失败时跳过后续步骤
+// 无论成功与否，测试文件都不会留在回收站中
+func selftestRoundTrip(manager filesystem.TrashManager, path string) []selftestStep {
+	var steps []selftestStep
+	run := func(name string, fn func() (string, error)) bool {
+		started := time.Now()
+		message, err := fn()
+		step := selftestStep{Name: name, Status: doctorPass, Message: message, Duration: time.Since(started)}
+		if err != nil {
+			step.Status = doctorFail
+			step.Message = err.Error()
+		}
+		steps = append(steps, step)
+		return err == nil
+	}
+
+	var hash string
+	var item filesystem.TrashFile
+	inTrash := false
+	defer func() {
+		if inTrash {
+			filesystem.RemoveFromTrash(manager, item.TrashPath)
+		}
+	}()
+
+	ok := run("创建测试文件", func() (string, error) {
+		var err error
+		hash, err = writeSelftestFile(path)
+		return fmt.Sprintf("%s (%s)", path, filesystem.FormatFileSize(selftestSize)), err
+	}) && run("删除到回收站", func() (string, error) {
+		if err := manager.MoveToTrash(path); err != nil {
+			return "", err
+		}
+		if _, err := os.Lstat(path); err == nil {
+			return "", fmt.Errorf("删除后原位置仍存在 %s", path)
+		}
+		return "已移出原位置", nil
+	}) && run("回收站列表", func() (string, error) {
+		var err error
+		item, err = findSelftestItem(manager, path)
+		if err != nil {
+			return "", err
+		}
+		inTrash = true
+		return fmt.Sprintf("%s，删除于 %s", item.TrashPath, item.DeletedTime.Format("15:04:05")), checkSelftestItem(item)
+	}) && run("恢复", func() (string, error) {
+		if err := manager.RestoreFile(item, path); err != nil {
+			return "", err
+		}
+		inTrash = false
+		return "已恢复到原位置", nil
+	})
+	if ok {
+		run("校验内容", func() (string, error) {
+			restored, err := hashSelftestFile(path)
+			if err != nil {
+				return "", err
+			}
+			if restored != hash {
+				return "", fmt.Errorf("恢复后的SHA256 %s 与原文件 %s 不一致", restored, hash)
+			}
+			return "SHA256 " + hash[:16] + "... 一致", nil
+		})
+	}
+	return steps
+}
+
+// selftestBench 重复count次删除和恢复，分别统计耗时
+func selftestBench(manager filesystem.TrashManager, dir string, count int) (map[string]selftestTiming, error) {
+	var deletes, restores []time.Duration
+	for i := 0; i < count; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("bench-%d.bin", i))
+		if _, err := writeSelftestFile(path); err != nil {
+			return nil, err
+		}
+
+		started := time.Now()
+		if err := manager.MoveToTrash(path); err != nil {
+			return nil, fmt.Errorf("第 %d 次删除失败: %v", i+1, err)
+		}
+		deletes = append(deletes, time.Since(started))
+
+		item, err := findSelftestItem(manager, path)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 次: %v", i+1, err)
+		}
+		started = time.Now()
+		if err := manager.RestoreFile(item, path); err != nil {
+			filesystem.RemoveFromTrash(manager, item.TrashPath)
+			return nil, fmt.Errorf("第 %d 次恢复失败: %v", i+1, err)
+		}
+		restores = append(restores, time.Since(started))
+		os.Remove(path)
+	}
+	return map[string]selftestTiming{
+		"delete":  newSelftestTiming(deletes),
+		"restore": newSelftestTiming(restores),
+	}, nil
+}
+
+// newSelftestTiming 统计最短、平均和最长耗时
+func newSelftestTiming(durations []time.Duration) selftestTiming {
+	timing := selftestTiming{Count: len(durations)}
+	var total time.Duration
+	for i, d := range durations {
+		if i == 0 || d < timing.Min {
+			timing.Min = d
+		}
+		if d > timing.Max {
+			timing.Max = d
+		}
+		total += d
+	}
+	if len(durations) > 0 {
+		timing.Avg = total / time.Duration(len(durations))
+	}
+	return timing
+}
+
+// writeSelftestFile 写入随机内容的测试文件，返回内容的SHA256
+func writeSelftestFile(path string) (string, error) {
+	data := make([]byte, selftestSize)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// hashSelftestFile 计算文件内容的SHA256
+func hashSelftestFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// findSelftestItem 在回收站列表中查找原始路径为path的项目
+func findSelftestItem(manager filesystem.TrashManager, path string) (filesystem.TrashFile, error) {
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return filesystem.TrashFile{}, fmt.Errorf("获取回收站文件列表失败: %v", err)
+	}
+	for _, file := range files {
+		if file.OriginalPath != "" && utils.SamePath(filesystem.ResolveExistingPath(file.OriginalPath), filesystem.ResolveExistingPath(path)) {
+			return file, nil
+		}
+	}
+	return filesystem.TrashFile{}, fmt.Errorf("回收站列表中没有原始路径为 %s 的项目", path)
+}
+
+// checkSelftestItem 核对回收站列表中记录的元数据
+func checkSelftestItem(item filesystem.TrashFile) error {
+	switch {
+	case item.Name != "selftest.bin":
+		return fmt.Errorf("记录的文件名为 %q，应为 selftest.bin", item.Name)
+	case item.IsDirectory:
+		return fmt.Errorf("测试文件被记录为目录")
+	case item.Size != selftestSize:
+		return fmt.Errorf("记录的大小为 %d 字节，应为 %d 字节", item.Size, selftestSize)
+	case time.Since(item.DeletedTime) > time.Hour || time.Until(item.DeletedTime) > time.Minute:
+		return fmt.Errorf("记录的删除时间 %s 与当前时间不符", item.DeletedTime.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// selftestFailed 是否有步骤失败
+func selftestFailed(report selftestReport) bool {
+	for _, step := range report.Steps {
+		if step.Status == doctorFail {
+			return true
+		}
+	}
+	return false
+}
+
+// finishSelftest 输出结果，有步骤失败时返回验证错误
+func finishSelftest(report selftestReport, asJSON bool) error {
+	if asJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printSelftestReport(report)
+	}
+	if selftestFailed(report) {
+		return errors.NewError(errors.ErrTypeValidation, "自检失败", nil)
+	}
+	return nil
+}
+
+// printSelftestReport 逐步输出自检结果
+func printSelftestReport(report selftestReport) {
+	fmt.Printf("🧪 DelGuard 自检 (%s/%s", report.OS, report.Arch)
+	if report.Backend != "" {
+		fmt.Printf(", %s", report.Backend)
+	}
+	fmt.Println(")")
+	fmt.Println()
+
+	for _, step := range report.Steps {
+		icon := "✅"
+		if step.Status == doctorFail {
+			icon = "❌"
+		}
+		fmt.Printf("%s %s: %s", icon, step.Name, step.Message)
+		if step.Duration > 0 {
+			fmt.Printf(" (%v)", step.Duration.Round(time.Microsecond))
+		}
+		fmt.Println()
+	}
+
+	if len(report.Bench) > 0 {
+		fmt.Println()
+		for _, op := range []string{"delete", "restore"} {
+			timing := report.Bench[op]
+			fmt.Printf("⏱️  %-7s %d 次: 最短 %v, 平均 %v, 最长 %v\n", op, timing.Count,
+				timing.Min.Round(time.Microsecond), timing.Avg.Round(time.Microsecond), timing.Max.Round(time.Microsecond))
+		}
+	}
+}
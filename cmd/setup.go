@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"delguard/internal/config"
+	"delguard/internal/i18n"
+	"delguard/internal/installer"
+	"delguard/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// setupCmd 首次运行设置向导
+var setupCmd = &cobra.Command{
+	Use:   "init",
+	Short: "运行初始设置向导",
+	Long: `逐项询问常用设置并写入配置文件：删除确认方式、是否安装shell别名、
+回收站容量上限、保留天数和界面语言。每个问题直接回车即使用默认值。
+首次运行且没有配置文件时，DelGuard会在终端中主动提供此向导。
+
+示例:
+  delguard init
+  delguard init --defaults    # 不提问，全部使用默认值，适合自动化部署`,
+	Args: cobra.NoArgs,
+	RunE: runSetup,
+}
+
+// setupAnswers 向导收集的设置
+type setupAnswers struct {
+	ConfirmMode    string // once、each或never
+	InstallAliases bool
+	MaxSize        string
+	MaxDays        int
+	Language       string
+}
+
+// confirmModes 删除确认方式的可选值
+var confirmModes = []string{"once", "each", "never"}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+	setupCmd.Flags().Bool("defaults", false, "不提问，全部使用默认值")
+}
+
+func runSetup(cmd *cobra.Command, args []string) error {
+	useDefaults, _ := cmd.Flags().GetBool("defaults")
+	answers := defaultSetupAnswers()
+	if !useDefaults {
+		fmt.Println("🛡️  DelGuard 初始设置，直接回车使用方括号中的默认值")
+		fmt.Println()
+		answers = askSetupQuestions(bufio.NewReader(os.Stdin), answers)
+	}
+	return applySetup(answers)
+}
+
+// defaultSetupAnswers 向导的默认答案，与配置默认值一致
+func defaultSetupAnswers() setupAnswers {
+	return setupAnswers{
+		ConfirmMode: "once",
+		MaxSize:     "1GB",
+		MaxDays:     30,
+		Language:    "zh-CN",
+	}
+}
+
+// askSetupQuestions 依次询问各项设置，输入无效时重新询问
+func askSetupQuestions(reader *bufio.Reader, answers setupAnswers) setupAnswers {
+	answers.ConfirmMode = askChoice(reader, "删除时如何确认？once=每次确认一次，each=逐个文件确认，never=不确认", confirmModes, answers.ConfirmMode)
+	answers.InstallAliases = askYesNo(reader, "是否安装shell别名 (rm/rmdir 改为调用DelGuard)？", answers.InstallAliases)
+	for {
+		value := askString(reader, "回收站容量上限 (如 500MB、2GB)", answers.MaxSize)
+		if _, err := utils.ParseSize(value); err == nil {
+			answers.MaxSize = value
+			break
+		}
+		fmt.Printf("   ❌ 无法解析大小: %s\n", value)
+	}
+	for {
+		value := askString(reader, "文件在回收站中保留的天数", strconv.Itoa(answers.MaxDays))
+		if days, err := strconv.Atoi(value); err == nil && days > 0 {
+			answers.MaxDays = days
+			break
+		}
+		fmt.Printf("   ❌ 请输入大于0的整数: %s\n", value)
+	}
+	answers.Language = askChoice(reader, "界面语言", []string{"zh-CN", "en-US"}, answers.Language)
+	fmt.Println()
+	return answers
+}
+
+// applySetup 写入配置文件，按需安装别名，并输出设置摘要
+func applySetup(answers setupAnswers) error {
+	values := map[string]interface{}{
+		"trash.confirm_delete": answers.ConfirmMode != "never",
+		"trash.interactive":    answers.ConfirmMode == "each",
+		"trash.max_size":       answers.MaxSize,
+		"trash.max_days":       answers.MaxDays,
+		"ui.language":          answers.Language,
+		"install.create_alias": answers.InstallAliases,
+	}
+	path, err := config.SaveWithVersion(values)
+	if err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	i18n.SetLanguage(answers.Language)
+
+	aliasStatus := "未安装"
+	if answers.InstallAliases {
+		aliasStatus = "已安装"
+		systemInstaller, err := installer.GetSystemInstaller()
+		if err == nil {
+			err = systemInstaller.Install()
+		}
+		if err != nil {
+			aliasStatus = "安装失败"
+			fmt.Fprintf(os.Stderr, "⚠️  安装别名失败: %v，可稍后运行 delguard install 重试\n", err)
+		}
+	}
+
+	fmt.Println("✅ 初始设置完成")
+	fmt.Printf("   删除确认: %s\n", describeConfirmMode(answers.ConfirmMode))
+	fmt.Printf("   shell别名: %s\n", aliasStatus)
+	fmt.Printf("   回收站容量上限: %s\n", answers.MaxSize)
+	fmt.Printf("   保留天数: %d 天\n", answers.MaxDays)
+	fmt.Printf("   界面语言: %s\n", answers.Language)
+	fmt.Printf("📄 配置文件: %s\n", path)
+	return nil
+}
+
+// describeConfirmMode 返回确认方式的说明
+func describeConfirmMode(mode string) string {
+	switch mode {
+	case "each":
+		return "逐个文件确认"
+	case "never":
+		return "不确认"
+	default:
+		return "每次删除确认一次"
+	}
+}
+
+// offerFirstRunSetup 首次运行且标准输入为终端时询问是否运行设置向导
+func offerFirstRunSetup(cmd *cobra.Command) {
+	if !config.IsFirstRun() || cmd == setupCmd || currentOutputLevel() == levelMinimal || !stdinIsTerminal() {
+		return
+	}
+	reader := bufio.NewReader(os.Stdin)
+	if !askYesNo(reader, "👋 首次运行DelGuard，是否现在进行初始设置？", true) {
+		fmt.Println("   可随时运行 delguard init 进行设置")
+		fmt.Println()
+		return
+	}
+	fmt.Println()
+	answers := askSetupQuestions(reader, defaultSetupAnswers())
+	if err := applySetup(answers); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	}
+	fmt.Println()
+}
+
+// stdinIsTerminal 检查标准输入是否为终端
+func stdinIsTerminal() bool {
+	return utils.IsTerminal(os.Stdin.Fd())
+}
+
+// askString 询问一个值，直接回车或读取失败时返回默认值
+func askString(reader *bufio.Reader, question, def string) string {
+	fmt.Printf("%s [%s]: ", question, def)
+	line, err := reader.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" || (err != nil && err != io.EOF) {
+		if err == io.EOF {
+			fmt.Println()
+		}
+		return def
+	}
+	return line
+}
+
+// askChoice 询问并返回choices中的一项，输入无效时重新询问
+func askChoice(reader *bufio.Reader, question string, choices []string, def string) string {
+	for {
+		value := askString(reader, fmt.Sprintf("%s (%s)", question, strings.Join(choices, "/")), def)
+		for _, choice := range choices {
+			if strings.EqualFold(value, choice) {
+				return choice
+			}
+		}
+		fmt.Printf("   ❌ 请输入 %s 之一\n", strings.Join(choices, "、"))
+	}
+}
+
+// askYesNo 询问是/否问题
+func askYesNo(reader *bufio.Reader, question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Printf("%s [%s]: ", question, hint)
+	line, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	default:
+		return def
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"runtime"
 
 	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
 
 	"github.com/spf13/cobra"
 )
@@ -48,7 +49,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Printf("📦 DelGuard版本: %s\n", rootCmd.Version)
 
 	// 获取回收站管理器
-	manager, err := filesystem.GetTrashManager()
+	manager, err := newTrashManager()
 	if err != nil {
 		fmt.Printf("❌ 回收站管理器初始化失败: %v\n", err)
 		return nil
@@ -70,6 +71,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		fileCount := 0
 		dirCount := 0
 		totalSize := int64(0)
+		pinnedCount := 0
 
 		for _, file := range trashFiles {
 			if file.IsDirectory {
@@ -77,6 +79,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			} else {
 				fileCount++
 			}
+			if file.Pinned {
+				pinnedCount++
+			}
 			totalSize += file.Size
 		}
 
@@ -84,6 +89,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("   • 文件数量: %d\n", fileCount)
 		fmt.Printf("   • 目录数量: %d\n", dirCount)
 		fmt.Printf("   • 总计大小: %s\n", filesystem.FormatFileSize(totalSize))
+		if pinnedCount > 0 {
+			fmt.Printf("   • 已固定: %d\n", pinnedCount)
+		}
 
 		if detailed && len(trashFiles) > 0 {
 			fmt.Printf("\n📋 最近删除的文件:\n")
@@ -99,13 +107,13 @@ func runStatus(cmd *cobra.Command, args []string) error {
 					typeIcon = "📁"
 				}
 				fmt.Printf("   %s %s (%s, %s)\n",
-					typeIcon, file.Name,
+					typeIcon, markedName(file),
 					filesystem.FormatFileSize(file.Size),
 					file.DeletedTime.Format("2006-01-02 15:04"))
 			}
 
 			if len(trashFiles) > 5 {
-				fmt.Printf("   ... 还有 %d 个项目\n", len(trashFiles)-5)
+				fmt.Printf("   %s\n", i18n.Plural("count.items_more", len(trashFiles)-5))
 			}
 		}
 	}
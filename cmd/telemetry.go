@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/telemetry"
+	"delguard/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// telemetryCmd 匿名使用统计命令
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "管理匿名使用统计",
+	Long: `管理匿名使用统计。统计默认关闭，只有运行 delguard telemetry enable 后才会记录。
+配置了 telemetry.endpoint 时，首次在终端中运行DelGuard会询问一次是否同意，回答保存在配置文件中。
+
+启用后只记录按天聚合的匿名数据：命令名称、执行次数、处理的项目数、错误类型、
+DelGuard版本和操作系统，不包含任何路径、文件名或命令参数。
+统计先保存在本地状态目录的 telemetry.queue（默认 ~/.delguard，设置XDG_STATE_HOME时为 $XDG_STATE_HOME/delguard），
+每天最多发送一次到 telemetry.endpoint，发送在命令执行期间于后台进行，失败时按退避间隔重试，
+包括重试最多占用2秒，失败时不会提示也不影响命令结果，统计留待下次发送。
+
+示例:
+  delguard telemetry show-pending   # 查看将要发送的内容
+  delguard telemetry enable
+  delguard telemetry off            # 关闭，撤销同意并删除本地未发送的统计`,
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "显示统计是否启用及待发送的数量",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryStatus,
+}
+
+var telemetryEnableCmd = &cobra.Command{
+	Use:     "enable",
+	Aliases: []string{"on"},
+	Short:   "启用匿名使用统计",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTelemetryEnabled(true)
+	},
+}
+
+var telemetryDisableCmd = &cobra.Command{
+	Use:     "disable",
+	Aliases: []string{"off"},
+	Short:   "关闭匿名使用统计，撤销同意并删除未发送的统计",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTelemetryEnabled(false)
+	},
+}
+
+var telemetryShowPendingCmd = &cobra.Command{
+	Use:   "show-pending",
+	Short: "显示本地队列中将要发送的统计",
+	Long: `以发送时的JSON格式原样显示本地队列中的统计。
+统计未启用且队列为空时，显示一条示例以说明启用后会记录哪些内容。`,
+	Args: cobra.NoArgs,
+	RunE: runTelemetryShowPending,
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryStatusCmd)
+	telemetryCmd.AddCommand(telemetryEnableCmd)
+	telemetryCmd.AddCommand(telemetryDisableCmd)
+	telemetryCmd.AddCommand(telemetryShowPendingCmd)
+}
+
+func runTelemetryStatus(cmd *cobra.Command, args []string) error {
+	if telemetryEnabled() {
+		fmt.Println("📡 匿名使用统计: 已启用")
+	} else {
+		fmt.Println("📡 匿名使用统计: 未启用")
+	}
+	if cfg := config.Current(); cfg != nil && cfg.Telemetry.ConsentTime != "" {
+		fmt.Printf("   同意时间: %s\n", cfg.Telemetry.ConsentTime)
+	}
+
+	endpoint := telemetryEndpoint()
+	if endpoint == "" {
+		endpoint = "未配置，统计只保存在本地"
+	}
+	fmt.Printf("   发送地址: %s\n", endpoint)
+
+	if path, err := telemetry.QueuePath(); err == nil {
+		fmt.Printf("   本地队列: %s\n", path)
+	}
+	events, err := telemetry.Pending()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("   待发送: %d 条\n", len(events))
+	return nil
+}
+
+// setTelemetryEnabled 启用时记录同意时间，关闭时撤销同意并删除本地队列
+func setTelemetryEnabled(enabled bool) error {
+	if err := saveTelemetryConsent(enabled); err != nil {
+		return err
+	}
+
+	if !enabled {
+		if err := telemetry.Clear(); err != nil {
+			return err
+		}
+		fmt.Println("✅ 已关闭匿名使用统计，撤销了同意并删除了本地未发送的统计")
+		return nil
+	}
+
+	fmt.Println("✅ 已启用匿名使用统计，感谢支持！")
+	fmt.Println("   只记录命令名称、执行次数、项目数、错误类型、版本和操作系统，不含路径或文件名")
+	fmt.Println("   可随时运行 delguard telemetry show-pending 查看将要发送的内容")
+	if telemetryEndpoint() == "" {
+		fmt.Println("⚠️  未配置 telemetry.endpoint，统计只会保存在本地")
+	}
+	return nil
+}
+
+// saveTelemetryConsent 将用户对匿名统计的选择写入配置文件，同意时记录时间，拒绝或关闭时清除，
+// 并标记为已询问，之后不再提示；每写入一项当前配置都整体替换为重新解析的实例
+func saveTelemetryConsent(enabled bool) error {
+	consentTime := ""
+	if enabled {
+		consentTime = time.Now().Format(time.RFC3339)
+	}
+	// 先写入已询问的标记，中途失败时最多是不再提示，不会在用户未同意时启用统计
+	for _, setting := range []struct {
+		key   string
+		value interface{}
+	}{
+		{"telemetry.prompted", true},
+		{"telemetry.consent_time", consentTime},
+		{"telemetry.enabled", enabled},
+	} {
+		if err := config.SaveValue(setting.key, setting.value); err != nil {
+			return fmt.Errorf("保存配置失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// offerTelemetryConsent 配置了发送地址但从未询问过时，在终端中询问一次是否同意发送匿名统计，默认不同意
+// 统计命令本身、JSON输出和静默输出时不询问；已经手动启用的视为同意
+func offerTelemetryConsent(cmd *cobra.Command) {
+	cfg := config.Current()
+	if cfg == nil || telemetryEndpoint() == "" {
+		return
+	}
+	settings := cfg.Telemetry
+	if settings.Prompted || settings.Enabled {
+		return
+	}
+	if cmd == telemetryCmd || cmd.Parent() == telemetryCmd || ErrorFormatJSON() || currentOutputLevel() == levelMinimal {
+		return
+	}
+	if !stdinIsTerminal() || !utils.IsTerminal(os.Stdout.Fd()) {
+		return
+	}
+
+	fmt.Println("📡 DelGuard可以发送匿名使用统计，帮助改进程序")
+	fmt.Println("   只包含按天汇总的命令次数、项目数、错误类型、版本和操作系统，不含任何路径、文件名或命令参数")
+	fmt.Printf("   发送地址: %s\n", telemetryEndpoint())
+	enabled := askYesNo(bufio.NewReader(os.Stdin), "是否同意发送匿名使用统计？", false)
+	if err := saveTelemetryConsent(enabled); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+	if enabled {
+		fmt.Println("   ✅ 已启用，可随时运行 delguard telemetry off 关闭")
+	} else {
+		fmt.Println("   未启用，之后不再询问，可随时运行 delguard telemetry enable 启用")
+	}
+	fmt.Println()
+}
+
+func runTelemetryShowPending(cmd *cobra.Command, args []string) error {
+	events, err := telemetry.Pending()
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		if telemetryEnabled() {
+			fmt.Println("📭 没有待发送的统计")
+			return nil
+		}
+		fmt.Println("📭 统计未启用，没有待发送的统计。启用后每条统计的内容如下例:")
+		example := telemetry.NewEvent(rootCmd.Version, "delete", time.Now())
+		example.Runs = 3
+		example.Items = 12
+		example.Errors = map[string]int{"permission_denied": 1}
+		events = []telemetry.Event{example}
+	}
+
+	payload, err := telemetry.Payload(events)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(payload))
+	return nil
+}
+
+// telemetryEnabled 用户是否启用了匿名统计
+func telemetryEnabled() bool {
+	cfg := config.Current()
+	return cfg != nil && cfg.Telemetry.Enabled
+}
+
+// telemetryEndpoint 配置的统计发送地址
+func telemetryEndpoint() string {
+	cfg := config.Current()
+	if cfg == nil {
+		return ""
+	}
+	return cfg.Telemetry.Endpoint
+}
+
+// startTelemetryFlush 启用统计时在后台发送之前的统计，返回的channel在发送结束后关闭
+// 统计只通过HTTPS发送
+func startTelemetryFlush() <-chan struct{} {
+	if !telemetryEnabled() || !strings.HasPrefix(telemetryEndpoint(), "https://") {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return telemetry.StartFlush(telemetryEndpoint())
+}
+
+// recordTelemetry 启用统计时将本次调用记入本地队列，记录失败时只在调试输出中提示
+func recordTelemetry(cmd *cobra.Command, err error) {
+	if !telemetryEnabled() || cmd == nil {
+		return
+	}
+	for _, operation := range startedOperations {
+		telemetry.AddItems(operation.Items())
+	}
+	if err != nil {
+		telemetry.AddCommandError(err)
+	}
+
+	// 只记录已注册的命令路径，不含任何参数
+	command := strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()))
+	if command == "" {
+		command = rootCmd.Name()
+	}
+	if recordErr := telemetry.Record(rootCmd.Version, command); recordErr != nil && currentOutputLevel() == levelDebug {
+		fmt.Fprintf(os.Stderr, "记录使用统计失败: %v\n", recordErr)
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"delguard/internal/config"
+	"delguard/internal/telemetry"
+)
+
+func TestTelemetryOffPurgesConsentAndQueue(t *testing.T) {
+	initTempConfig(t)
+	t.Setenv("XDG_STATE_HOME", "")
+
+	captureStdout(t, func() {
+		if err := setTelemetryEnabled(true); err != nil {
+			t.Fatalf("enable: %v", err)
+		}
+	})
+	settings := config.Current().Telemetry
+	if !settings.Enabled || !settings.Prompted || settings.ConsentTime == "" {
+		t.Fatalf("after enable telemetry = %+v, want consent recorded", settings)
+	}
+	if err := telemetry.Record("1.2.3", "delete"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	captureStdout(t, func() {
+		if err := setTelemetryEnabled(false); err != nil {
+			t.Fatalf("disable: %v", err)
+		}
+	})
+	settings = config.Current().Telemetry
+	if settings.Enabled || settings.ConsentTime != "" || !settings.Prompted {
+		t.Errorf("after off telemetry = %+v, want consent cleared and no further prompt", settings)
+	}
+	path, err := telemetry.QueuePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("queue %s still exists after off: %v", path, err)
+	}
+}
@@ -0,0 +1,868 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/filesystem"
+	"delguard/internal/i18n"
+	"delguard/internal/logger"
+	"delguard/internal/notify"
+	"delguard/internal/report"
+	"delguard/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// trashCmd 回收站维护命令
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "回收站维护",
+	Long:  "查看回收站统计信息并执行维护操作",
+}
+
+var trashStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "显示回收站统计信息",
+	Long: `按原始目录、扩展名和删除时长分组显示回收站占用，
+并根据近30天的删除速率估算何时达到容量上限 (trash.max_size)。
+
+示例:
+  delguard trash stats
+  delguard trash stats --json`,
+	RunE: runTrashStats,
+}
+
+var trashDuCmd = &cobra.Command{
+	Use:     "du",
+	Aliases: []string{"size"},
+	Short:   "按原始目录汇总回收站占用",
+	Long: `按文件删除前所在的目录汇总回收站占用的空间，
+列出可回收空间最多的目录，便于决定清理哪些内容。
+
+示例:
+  delguard trash du
+  delguard trash du --top 20`,
+	RunE: runTrashDu,
+}
+
+var trashVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "检查回收站元数据和内容完整性",
+	Long: `检查回收站中没有对应文件的元数据，以及没有任何元数据的文件。
+使用 --repair 删除过期的元数据，并以文件修改时间为删除时间为缺少元数据的文件补建元数据。
+
+同时重新计算文件的SHA256并与删除时记录的哈希比对，结果记入元数据。
+默认只校验从未校验过或上次校验超过 trash.verify_interval 天的项目，--all 校验全部。
+删除时没有记录哈希的文件在首次校验时记录基线哈希。
+哈希不一致的项目在列表中以⚠️标记，恢复时需要 --force。
+
+示例:
+  delguard trash verify
+  delguard trash verify --all
+  delguard trash verify --repair`,
+	RunE: runTrashVerify,
+}
+
+var trashPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "清理超过保留期限的回收站项目",
+	Long: `永久删除超过保留期限的回收站项目。
+保留期限按 trash.retention_rules 中与原始路径匹配的第一条规则确定，
+都不匹配时使用 trash.max_days。已固定的项目默认保留。
+使用专用回收站 (trash.use_system_trash: false) 时，启用 trash.prune_to_system_bin 或 --to-system-bin
+可将过期项目移入系统回收站，由系统的保留策略最终删除；系统回收站不可用时项目保留在回收站中。
+启用 trash.verify_before_prune 时，永久删除前重新计算哈希并与记录比对，内容损坏的项目移入回收站下的
+.quarantine 目录而不是删除，结果写入操作回执 (logging.report_enabled)。
+security.safe_mode 为 strict 时清理前列出项目并要求输入 DELETE 确认，无法读取输入时报错退出。
+
+示例:
+  delguard trash prune --dry-run
+  delguard trash prune --explain
+  delguard trash prune --include-pinned`,
+	RunE: runTrashPrune,
+}
+
+var trashCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "压缩回收站中较早删除的文件",
+	Long: `用gzip压缩删除超过 trash.compact_after_days 天的文件以节省空间，压缩级别由 trash.compression_level 决定。
+压缩后的文件保留原来的名称，恢复、校验和移入系统回收站时自动解压；trash stats 同时显示原始大小和实际占用。
+目录、符号链接、已经是gzip格式的文件和压缩后没有变小的文件保持不变。
+先压缩到临时文件并写入磁盘，再替换原文件，中途中断不会丢失内容。
+
+示例:
+  delguard trash compact --dry-run
+  delguard trash compact --days 30`,
+	Args: cobra.NoArgs,
+	RunE: runTrashCompact,
+}
+
+var trashExportCmd = &cobra.Command{
+	Use:   "export [文件名或索引...]",
+	Short: "将回收站项目导出为归档",
+	Long: `将回收站中的项目连同元数据打包为一个归档文件，便于在其他机器上检查或导入。
+归档格式由输出文件的扩展名决定：.zip 为zip格式，其他为tar.gz格式。
+导出不会从回收站中移除项目。
+
+示例:
+  delguard trash export 1 2 -o review.tar.gz
+  delguard trash export --all -o trash.zip`,
+	RunE: runTrashExport,
+}
+
+var trashImportCmd = &cobra.Command{
+	Use:   "import <归档文件>",
+	Short: "将导出的归档导入回收站",
+	Long: `将 'delguard trash export' 生成的归档导入本地回收站，保留原始路径和删除时间。
+导入前会校验每个项目的哈希，不匹配的项目不会导入。
+
+示例:
+  delguard trash import review.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrashImport,
+}
+
+var trashPinCmd = &cobra.Command{
+	Use:   "pin <文件名或索引...>",
+	Short: "固定回收站项目，使其不被自动清理",
+	Long: `固定回收站中的项目，把回收站当作临时归档使用。
+已固定的项目不会被过期清理 (trash prune)、按数量或容量的轮转以及 delguard empty 删除，
+除非这些命令指定了 --include-pinned。固定的项目仍可正常恢复。
+索引与 delguard restore 使用的相同。
+
+示例:
+  delguard trash pin 3
+  delguard trash pin report.pdf`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTrashPin(cmd, args, true)
+	},
+}
+
+var trashUnpinCmd = &cobra.Command{
+	Use:   "unpin <文件名或索引...>",
+	Short: "取消固定回收站项目",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTrashPin(cmd, args, false)
+	},
+}
+
+var trashOpenCmd = &cobra.Command{
+	Use:   "open",
+	Short: "在文件管理器中打开回收站",
+	Long: `在系统文件管理器中打开当前使用的回收站：Windows使用资源管理器（使用系统回收站时打开回收站），
+macOS使用Finder，Linux使用xdg-open打开默认的文件管理器。
+没有图形界面时（例如通过SSH连接）会显示回收站所在的目录，可以改用 delguard list 查看。`,
+	Args: cobra.NoArgs,
+	RunE: runTrashOpen,
+}
+
+// fileManagerRunner 启动文件管理器的命令执行器，测试时可替换
+var fileManagerRunner utils.CommandRunner = utils.LaunchRunner{}
+
+// originUsage 单个原始目录的占用
+type originUsage struct {
+	Directory string `json:"directory"`
+	Size      int64  `json:"size"`
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashStatsCmd)
+	trashCmd.AddCommand(trashDuCmd)
+	trashCmd.AddCommand(trashVerifyCmd)
+	trashCmd.AddCommand(trashPruneCmd)
+	trashCmd.AddCommand(trashCompactCmd)
+	trashCmd.AddCommand(trashExportCmd)
+	trashCmd.AddCommand(trashImportCmd)
+	trashCmd.AddCommand(trashPinCmd)
+	trashCmd.AddCommand(trashUnpinCmd)
+	trashCmd.AddCommand(trashOpenCmd)
+
+	trashStatsCmd.Flags().Bool("json", false, "以JSON格式输出")
+	trashDuCmd.Flags().IntP("top", "n", 10, "显示占用最多的前N个目录，0表示全部")
+	trashDuCmd.Flags().Bool("json", false, "以JSON格式输出")
+	trashVerifyCmd.Flags().Bool("repair", false, "删除过期元数据并为缺少元数据的文件补建元数据")
+	trashVerifyCmd.Flags().Bool("json", false, "以JSON格式输出")
+	trashVerifyCmd.Flags().Bool("all", false, "忽略校验间隔，重新校验所有项目的内容")
+	trashPruneCmd.Flags().Bool("explain", false, "显示每个被清理项目命中的保留规则")
+	trashPruneCmd.Flags().BoolP("dry-run", "n", false, "只列出将被清理的项目，不实际删除")
+	trashPruneCmd.Flags().Int("days", -1, "覆盖trash.max_days作为默认保留天数")
+	trashPruneCmd.Flags().Bool("include-pinned", false, "同时清理已固定的项目")
+	trashPruneCmd.Flags().Bool("to-system-bin", false, "将过期项目移入系统回收站而不是永久删除（覆盖trash.prune_to_system_bin）")
+	trashCompactCmd.Flags().Int("days", -1, "覆盖trash.compact_after_days，压缩删除超过此天数的文件")
+	trashCompactCmd.Flags().BoolP("dry-run", "n", false, "只统计可以压缩的文件，不实际压缩")
+	trashCompactCmd.Flags().Bool("json", false, "以JSON格式输出")
+	trashExportCmd.Flags().StringP("output", "o", "", "归档文件路径（.zip 或 .tar.gz）")
+	trashExportCmd.Flags().BoolP("all", "a", false, "导出回收站中的所有项目")
+	trashExportCmd.Flags().StringP("filter", "F", "", "按模式过滤要导出的项目")
+	trashExportCmd.MarkFlagRequired("output")
+}
+
+func runTrashStats(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+
+	var quota int64
+	if cfg := config.Current(); cfg != nil && cfg.Trash.MaxSize != "" {
+		if quota, err = utils.ParseSize(cfg.Trash.MaxSize); err != nil {
+			return fmt.Errorf("解析回收站容量上限失败: %v", err)
+		}
+	}
+
+	stats, err := filesystem.ComputeTrashStats(manager, quota, time.Now())
+	if err != nil {
+		return fmt.Errorf("获取回收站统计失败: %v", err)
+	}
+
+	if asJSON {
+		return printJSON(stats)
+	}
+
+	fmt.Println("📊 回收站统计")
+	fmt.Printf("   • 项目总数: %d (文件 %d, 目录 %d)\n", stats.TotalFiles, stats.Files, stats.Directories)
+	fmt.Printf("   • 总计大小: %s\n", utils.FormatSize(stats.TotalSize))
+	if filesystem.UsageDiffers(stats.TotalSize, stats.DiskSize) {
+		fmt.Printf("   • 实际占用: %s (稀疏文件、硬链接和已压缩的文件按实际分配的空间计算)\n", utils.FormatSize(stats.DiskSize))
+	}
+	if !stats.OldestFile.IsZero() {
+		fmt.Printf("   • 最早删除: %s\n", stats.OldestFile.Format("2006-01-02 15:04"))
+	}
+	if stats.PinnedFiles > 0 {
+		fmt.Printf("   • 📌 已固定: %d 项 (%s)，不计入可回收空间\n", stats.PinnedFiles, utils.FormatSize(stats.PinnedSize))
+		fmt.Printf("   • 可回收: %s\n", utils.FormatSize(stats.TotalSize-stats.PinnedSize))
+	}
+
+	if stats.TotalFiles == 0 {
+		return nil
+	}
+
+	printStatsBuckets("📁 按原始目录:", stats.ByDirectory)
+	printStatsBuckets("🏷️  按扩展名:", stats.ByExtension)
+	printStatsBuckets("⏳ 按删除时长:", stats.ByAge)
+
+	fmt.Println()
+	fmt.Println("💾 容量:")
+	fmt.Printf("   • 近30天平均每天: %s\n", utils.FormatSize(stats.DailyRate))
+	if stats.QuotaSize <= 0 {
+		fmt.Println("   • 未设置容量上限")
+		return nil
+	}
+	fmt.Printf("   • 已使用: %.1f%% (%s / %s)\n", stats.QuotaUsedPercent,
+		utils.FormatSize(stats.DiskSize), utils.FormatSize(stats.QuotaSize))
+	switch {
+	case stats.DiskSize >= stats.QuotaSize:
+		fmt.Println("   • ⚠️  已达到容量上限")
+	case !stats.QuotaFullDate.IsZero():
+		fmt.Printf("   • 预计在 %s 达到上限\n", stats.QuotaFullDate.Format("2006-01-02"))
+	case stats.DailyRate > 0:
+		fmt.Println("   • 按近期速率，10年内不会达到上限")
+	default:
+		fmt.Println("   • 近30天没有新的删除，暂不会达到上限")
+	}
+
+	return nil
+}
+
+func runTrashDu(cmd *cobra.Command, args []string) error {
+	top, _ := cmd.Flags().GetInt("top")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+
+	usage, err := filesystem.TrashUsageByOrigin(manager)
+	if err != nil {
+		return fmt.Errorf("统计回收站占用失败: %v", err)
+	}
+
+	var total int64
+	origins := make([]originUsage, 0, len(usage))
+	for dir, size := range usage {
+		origins = append(origins, originUsage{Directory: dir, Size: size})
+		total += size
+	}
+	sort.Slice(origins, func(i, j int) bool {
+		if origins[i].Size != origins[j].Size {
+			return origins[i].Size > origins[j].Size
+		}
+		return origins[i].Directory < origins[j].Directory
+	})
+
+	shown := origins
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+	}
+
+	if asJSON {
+		return printJSON(shown)
+	}
+
+	if len(origins) == 0 {
+		fmt.Println("🗑️  回收站为空")
+		return nil
+	}
+
+	fmt.Printf("📊 %s\n", i18n.Plural("du.header", len(origins), utils.FormatSize(total)))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, origin := range shown {
+		percent := 0.0
+		if total > 0 {
+			percent = float64(origin.Size) / float64(total) * 100
+		}
+		fmt.Fprintf(w, "   %s\t%5.1f%%\t%s\n", utils.FormatSize(origin.Size), percent, origin.Directory)
+	}
+	w.Flush()
+
+	if len(shown) < len(origins) {
+		fmt.Printf("   %s\n", i18n.Plural("du.more", len(origins)-len(shown)))
+	}
+
+	return nil
+}
+
+// concurrencyFor 返回处理path所在存储上的文件时的并发数及原因
+// performance.max_concurrent大于0时使用配置值，否则按固态硬盘、机械硬盘和网络文件系统自动选择
+func concurrencyFor(path string) (int, string) {
+	if cfg := config.Current(); cfg != nil && cfg.Performance.MaxConcurrent > 0 {
+		return cfg.Performance.MaxConcurrent, "performance.max_concurrent"
+	}
+	workers, storage := filesystem.AdaptiveWorkers(path)
+	return workers, storage.Reason
+}
+
+func runTrashVerify(cmd *cobra.Command, args []string) error {
+	repair, _ := cmd.Flags().GetBool("repair")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	verifyAll, _ := cmd.Flags().GetBool("all")
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+
+	report, err := manager.VerifyTrash(repair)
+	if err != nil {
+		return fmt.Errorf("检查回收站失败: %v", err)
+	}
+
+	opts := filesystem.IntegrityOptions{All: verifyAll, Interval: 30 * 24 * time.Hour, Workers: 1}
+	if cfg := config.Current(); cfg != nil {
+		opts.Interval = time.Duration(cfg.Trash.VerifyInterval) * 24 * time.Hour
+	}
+	if trashPath, err := manager.GetTrashPath(); err == nil {
+		var reason string
+		opts.Workers, reason = concurrencyFor(trashPath)
+		if currentOutputLevel() >= levelVerbose {
+			fmt.Fprintf(os.Stderr, "⚙️  使用 %d 个并发任务: %s\n", opts.Workers, reason)
+		}
+	}
+	integrity, err := filesystem.VerifyIntegrity(manager, opts)
+	if err != nil {
+		return fmt.Errorf("校验回收站内容失败: %v", err)
+	}
+	report.Integrity = &integrity
+
+	if asJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printVerifyReport(report, repair)
+		printIntegrityReport(report.Integrity)
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%s", i18n.Plural("verify.repair_errors", len(report.Errors)))
+	}
+	if len(integrity.Corrupt) > 0 {
+		return errors.NewError(errors.ErrTypeIO, i18n.Plural("verify.corrupt", len(integrity.Corrupt)), nil)
+	}
+	if !repair && !report.Healthy() {
+		return fmt.Errorf("%s", i18n.Plural("verify.problems", len(report.OrphanedMetadata)+len(report.OrphanedFiles)))
+	}
+	return nil
+}
+
+func runTrashPrune(cmd *cobra.Command, args []string) error {
+	explain, _ := cmd.Flags().GetBool("explain")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	days, _ := cmd.Flags().GetInt("days")
+	includePinned, _ := cmd.Flags().GetBool("include-pinned")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	toSystemBin, _ := cmd.Flags().GetBool("to-system-bin")
+	cfg := config.Current()
+	if !cmd.Flags().Changed("to-system-bin") && cfg != nil {
+		toSystemBin = cfg.Trash.PruneToSystemBin
+	}
+
+	if days < 0 {
+		days = 30
+		if cfg != nil {
+			days = cfg.Trash.MaxDays
+		}
+	}
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+
+	expired, err := filesystem.PlanPrune(manager, days, time.Now(), true)
+	if err != nil {
+		return fmt.Errorf("读取回收站失败: %v", err)
+	}
+
+	// CleanOldFiles总是跳过已固定的项目，--include-pinned时另行删除
+	var candidates, pinned []filesystem.PruneCandidate
+	for _, candidate := range expired {
+		if candidate.File.Pinned {
+			pinned = append(pinned, candidate)
+		}
+		if !candidate.File.Pinned || includePinned {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(pinned) > 0 && !includePinned && !quiet {
+		fmt.Printf("📌 %s\n", i18n.Plural("pin.kept", len(pinned)))
+	}
+	if len(candidates) == 0 {
+		if !quiet {
+			fmt.Println("✅ 没有超过保留期限的项目")
+		}
+		return nil
+	}
+
+	var totalSize int64
+	for _, candidate := range candidates {
+		totalSize += candidate.File.Size
+	}
+
+	if dryRun {
+		fmt.Printf("🔍 %s\n", i18n.Plural("prune.preview", len(candidates), utils.FormatSize(totalSize)))
+		printPruneCandidates(candidates, explain)
+		return nil
+	}
+
+	// 清理默认不询问，严格安全模式下永久删除回收站中的项目必须输入DELETE
+	policy := safeModePolicy()
+	if words := purgeConfirmWords(policy, false); words != nil {
+		fmt.Printf("⚠️  %s\n", i18n.Plural("prune.confirm", len(candidates), utils.FormatSize(totalSize)))
+		printPruneCandidates(candidates, explain)
+		confirmed, err := confirmPurge(policy, "确认要清理以上项目吗?", words)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("❌ " + i18n.T("common.cancelled"))
+			return nil
+		}
+	}
+
+	operation := startOperation(cmd, "清理回收站")
+	if toSystemBin || filesystem.VerifyBeforePrune() {
+		return pruneItems(manager, candidates, toSystemBin, operation, quiet, explain)
+	}
+	if err := manager.CleanOldFiles(days); err != nil {
+		return fmt.Errorf("清理回收站失败: %v", err)
+	}
+	if includePinned {
+		for _, candidate := range pinned {
+			if err := filesystem.RemoveFromTrash(manager, candidate.File.TrashPath); err != nil {
+				return fmt.Errorf("清理回收站失败: %v", err)
+			}
+		}
+	}
+	operation.Add(len(candidates), totalSize)
+	operation.Finish()
+
+	if !quiet {
+		fmt.Printf("✅ %s\n", i18n.Plural("prune.done", len(candidates), utils.FormatSize(totalSize)))
+		if explain {
+			printPruneCandidates(candidates, true)
+		}
+	}
+	return nil
+}
+
+// pruneItems 逐个清理过期项目：toSystemBin为true时移入系统回收站，系统回收站不可用时不清理任何项目；
+// 永久删除前按trash.verify_before_prune校验内容
+func pruneItems(manager filesystem.TrashManager, candidates []filesystem.PruneCandidate, toSystemBin bool, operation *notify.Operation, quiet, explain bool) error {
+	files := make([]filesystem.TrashFile, len(candidates))
+	for i, candidate := range candidates {
+		files[i] = candidate.File
+	}
+
+	result, err := filesystem.PruneItems(manager, files, toSystemBin)
+	operation.Add(result.ToSystemBin+result.Destroyed, result.Size)
+	operation.Finish()
+	reportPruneChecks(result.Checks, quiet)
+	if err != nil {
+		return fmt.Errorf("清理回收站失败: %v", err)
+	}
+
+	if !quiet {
+		if toSystemBin {
+			total := result.ToSystemBin + result.Destroyed
+			fmt.Printf("✅ %s\n", i18n.Plural("prune.done_bin", total, utils.FormatSize(result.Size), result.ToSystemBin, result.Destroyed))
+		} else {
+			fmt.Printf("✅ %s\n", i18n.Plural("prune.done", result.Destroyed, utils.FormatSize(result.Size)))
+		}
+		printPruneVerification(result.Checks)
+		if explain {
+			printPruneCandidates(candidates, true)
+		}
+	}
+	return nil
+}
+
+// reportPruneChecks 永久删除前校验的结果写入日志和操作回执，内容损坏而隔离或无法校验的项目给出警告
+// checks为nil（未启用trash.verify_before_prune）时不做任何事
+func reportPruneChecks(checks filesystem.PruneChecks, quiet bool) {
+	if checks == nil {
+		return
+	}
+
+	receipt := newReceipt("prune")
+	for _, check := range checks {
+		name := check.File.OriginalPath
+		if name == "" {
+			name = check.File.Name
+		}
+		item := report.Item{
+			Path:        name,
+			Size:        check.File.Size,
+			IsDirectory: check.File.IsDirectory,
+			Hash:        check.Hash,
+			TrashPath:   check.File.TrashPath,
+			Verify:      check.Result,
+		}
+		switch check.Outcome {
+		case filesystem.PruneDestroyed:
+			item.Outcome = report.OutcomeDeleted
+			logger.Infof("永久删除前校验 %s: %s", check.Result, name)
+		case filesystem.PruneQuarantined:
+			item.Outcome, item.TrashPath = report.OutcomeQuarantined, check.Quarantine
+			item.Reason = "内容与删除时记录的哈希不一致"
+			logger.Errorf("永久删除前校验发现内容损坏，已隔离: %s -> %s", name, check.Quarantine)
+			fmt.Fprintf(os.Stderr, "☣️  内容已损坏，已隔离而未删除: %s -> %s\n", name, check.Quarantine)
+		case filesystem.PruneKept:
+			item.Outcome, item.Reason = report.OutcomeFailed, check.Err.Error()
+			logger.Errorf("永久删除前校验后保留 %s: %v", name, check.Err)
+			// 删除失败已由调用方报告，这里只报告校验和隔离失败
+			if check.Result == "" || check.Result == filesystem.VerifyCorrupt {
+				fmt.Fprintf(os.Stderr, "⚠️  未删除 %s: %v\n", name, check.Err)
+			}
+		default:
+			item.Outcome, item.Reason = report.OutcomeSkipped, "清理中止，未处理"
+		}
+		receipt.Add(item)
+	}
+	if len(checks.Quarantined()) > 0 {
+		fmt.Fprintln(os.Stderr, "⚠️  回收站中的内容损坏可能意味着磁盘故障，原文件或其备份也可能已经损坏，请检查磁盘状态并核对原文件")
+	}
+	saveReceipt(nil, receipt, quiet)
+}
+
+// printPruneVerification 输出永久删除前校验的汇总，未启用校验时不输出
+func printPruneVerification(checks filesystem.PruneChecks) {
+	if checks == nil {
+		return
+	}
+	fmt.Printf("🔎 %s\n", i18n.Plural("prune.verified", len(checks), checks.Verified(), len(checks.Quarantined())))
+}
+
+// printPruneCandidates 列出清理的项目，explain为true时显示命中的保留规则
+func printPruneCandidates(candidates []filesystem.PruneCandidate, explain bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, candidate := range candidates {
+		name := candidate.File.OriginalPath
+		if name == "" {
+			name = candidate.File.Name
+		}
+		deleted := candidate.File.DeletedTime.Format("2006-01-02")
+		if explain {
+			fmt.Fprintf(w, "   %s\t%s\t%s\n", name, deleted, candidate)
+		} else {
+			fmt.Fprintf(w, "   %s\t%s\n", name, deleted)
+		}
+	}
+	w.Flush()
+}
+
+// printVerifyReport 输出回收站校验结果
+func printVerifyReport(report filesystem.TrashVerifyReport, repair bool) {
+	fmt.Printf("🔍 %s\n", i18n.Plural("verify.checked", report.Checked))
+	if report.Healthy() {
+		fmt.Println("✅ 元数据与回收站文件一致")
+		return
+	}
+
+	if len(report.OrphanedMetadata) > 0 {
+		fmt.Printf("\n⚠️  %s\n", i18n.Plural("verify.orphan_meta", len(report.OrphanedMetadata)))
+		for _, path := range report.OrphanedMetadata {
+			fmt.Printf("   %s\n", path)
+		}
+	}
+	if len(report.OrphanedFiles) > 0 {
+		fmt.Printf("\n⚠️  %s\n", i18n.Plural("verify.orphan_files", len(report.OrphanedFiles)))
+		for _, path := range report.OrphanedFiles {
+			fmt.Printf("   %s\n", path)
+		}
+	}
+
+	if repair {
+		fmt.Printf("\n🔧 %s\n🔧 %s\n", i18n.Plural("verify.removed", report.RemovedMetadata), i18n.Plural("verify.created", report.CreatedMetadata))
+		for _, msg := range report.Errors {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", msg)
+		}
+	}
+}
+
+// printIntegrityReport 输出内容校验结果
+func printIntegrityReport(report *filesystem.IntegrityReport) {
+	fmt.Println()
+	fmt.Printf("🔐 内容校验: 本次校验 %d 项，跳过 %d 项（在校验间隔内）\n", report.Checked, report.Skipped)
+	fmt.Printf("   ✅ 一致 %d   ⚠️  损坏 %d   ➖ 无哈希 %d\n", report.OK, len(report.Corrupt), report.Unhashed)
+	for _, path := range report.Corrupt {
+		fmt.Printf("   ⚠️  %s\n", path)
+	}
+	for _, msg := range report.Errors {
+		fmt.Fprintf(os.Stderr, "   ❌ %s\n", msg)
+	}
+}
+
+// printStatsBuckets 以表格形式输出分组统计
+func printStatsBuckets(title string, buckets []filesystem.StatsBucket) {
+	if len(buckets) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, bucket := range buckets {
+		fmt.Fprintf(w, "   %s\t%d 项\t%s\n", bucket.Name, bucket.Count, utils.FormatSize(bucket.Size))
+	}
+	w.Flush()
+}
+
+// runTrashPin 固定或取消固定选中的回收站项目
+func runTrashPin(cmd *cobra.Command, args []string, pinned bool) error {
+	quiet := currentOutputLevel() == levelMinimal
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+
+	trashFiles, err := manager.ListTrashFiles()
+	if err != nil {
+		return fmt.Errorf("获取回收站文件列表失败: %v", err)
+	}
+	if len(trashFiles) == 0 {
+		return fmt.Errorf("回收站是空的")
+	}
+
+	selected, err := selectFilesToRestore(trashFiles, args, "")
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, file := range selected {
+		if file.Pinned == pinned {
+			continue
+		}
+		if err := filesystem.SetPinned(manager, file, pinned); err != nil {
+			return fmt.Errorf("更新 %s 失败: %v", file.Name, err)
+		}
+		changed++
+	}
+
+	if !quiet {
+		key := "pin.done"
+		if !pinned {
+			key = "unpin.done"
+		}
+		fmt.Printf("📌 %s\n", i18n.Plural(key, changed))
+	}
+	return nil
+}
+
+func runTrashExport(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	exportAll, _ := cmd.Flags().GetBool("all")
+	filter, _ := cmd.Flags().GetString("filter")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+
+	trashFiles, err := manager.ListTrashFiles()
+	if err != nil {
+		return fmt.Errorf("获取回收站文件列表失败: %v", err)
+	}
+	if len(trashFiles) == 0 {
+		return fmt.Errorf("回收站是空的")
+	}
+
+	selected := trashFiles
+	if !exportAll {
+		if len(args) == 0 && filter == "" {
+			return fmt.Errorf("请指定要导出的文件名、索引或使用 --all 导出所有项目")
+		}
+		if selected, err = selectFilesToRestore(trashFiles, args, filter); err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("没有找到匹配的文件")
+		}
+	}
+
+	ids := make([]string, 0, len(selected))
+	var totalSize int64
+	for _, file := range selected {
+		ids = append(ids, file.ID)
+		totalSize += file.Size
+	}
+
+	operation := startOperation(cmd, "导出回收站")
+	if err := filesystem.ExportTrash(manager, ids, output); err != nil {
+		return fmt.Errorf("导出失败: %v", err)
+	}
+	operation.Add(len(ids), totalSize)
+	operation.Finish()
+
+	if !quiet {
+		fmt.Printf("📦 %s\n", i18n.Plural("archive.exported", len(ids), output))
+	}
+	return nil
+}
+
+func runTrashImport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+
+	operation := startOperation(cmd, "导入回收站")
+	imported, err := filesystem.ImportTrash(manager, args[0])
+	operation.Add(imported, 0)
+	operation.Finish()
+
+	if imported > 0 && !quiet {
+		fmt.Printf("📥 %s\n", i18n.Plural("archive.imported", imported))
+	}
+	if err != nil {
+		return fmt.Errorf("导入失败: %v", err)
+	}
+	return nil
+}
+
+func runTrashOpen(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return err
+	}
+	location, isDir, err := filesystem.BrowseLocation(manager)
+	if err != nil {
+		return fmt.Errorf("获取回收站路径失败: %v", err)
+	}
+	if !utils.GUIAvailable() {
+		return fmt.Errorf("没有可用的图形界面（例如通过SSH连接），无法打开文件管理器\n   回收站位于: %s\n   可以使用 delguard list 查看回收站中的项目", location)
+	}
+	if isDir {
+		if _, err := os.Stat(location); os.IsNotExist(err) {
+			return fmt.Errorf("回收站目录 %s 还不存在，回收站中没有项目", location)
+		}
+	}
+
+	name, launchArgs := utils.FileManagerCommand(runtime.GOOS, location)
+	if _, err := fileManagerRunner.Run(name, launchArgs...); err != nil {
+		// explorer成功打开窗口时也会返回退出码1
+		if _, exited := err.(*exec.ExitError); !exited || runtime.GOOS != "windows" {
+			return fmt.Errorf("启动文件管理器 %s 失败: %v", name, err)
+		}
+	}
+	if !quiet {
+		fmt.Printf("📂 已在文件管理器中打开回收站: %s\n", location)
+	}
+	return nil
+}
+
+func runTrashCompact(cmd *cobra.Command, args []string) error {
+	days, _ := cmd.Flags().GetInt("days")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	opts := filesystem.CompactOptions{Level: 6, DryRun: dryRun}
+	if cfg := config.Current(); cfg != nil {
+		opts.Level = cfg.Trash.CompressionLevel
+		if days < 0 {
+			days = cfg.Trash.CompactAfterDays
+		}
+	}
+	if days < 0 {
+		days = 14
+	}
+	opts.OlderThan = time.Duration(days) * 24 * time.Hour
+
+	manager, err := newTrashManager()
+	if err != nil {
+		return fmt.Errorf("初始化回收站管理器失败: %v", err)
+	}
+	report, err := filesystem.CompactTrash(manager, opts)
+	if err != nil {
+		return fmt.Errorf("压缩回收站失败: %v", err)
+	}
+
+	if asJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		switch {
+		case report.Compacted == 0:
+			fmt.Printf("📭 没有删除超过 %d 天且需要压缩的文件\n", days)
+		case dryRun:
+			fmt.Printf("🔍 预览模式 - 可以压缩 %d 个文件，共 %s\n", report.Compacted, utils.FormatSize(report.BytesBefore))
+		default:
+			fmt.Printf("🗜️  已压缩 %d 个文件: %s → %s，节省 %s\n", report.Compacted,
+				utils.FormatSize(report.BytesBefore), utils.FormatSize(report.BytesAfter),
+				utils.FormatSize(report.BytesBefore-report.BytesAfter))
+		}
+		if report.StaleTemps > 0 {
+			fmt.Printf("🧹 清理了 %d 个之前中断的压缩留下的临时文件\n", report.StaleTemps)
+		}
+		for _, e := range report.Errors {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", e)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d 个文件压缩失败", len(report.Errors))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"delguard/internal/config"
+	"delguard/internal/utils"
+)
+
+func TestConcurrencyFor(t *testing.T) {
+	initTempConfig(t)
+	dir := t.TempDir()
+
+	config.Current().Performance.MaxConcurrent = 3
+	if workers, reason := concurrencyFor(dir); workers != 3 || reason != "performance.max_concurrent" {
+		t.Errorf("explicit max_concurrent: concurrencyFor() = %d, %q; want 3 from config", workers, reason)
+	}
+
+	config.Current().Performance.MaxConcurrent = 0
+	if workers, reason := concurrencyFor(dir); workers <= 0 || reason == "" || reason == "performance.max_concurrent" {
+		t.Errorf("automatic: concurrencyFor() = %d, %q; want a storage-based decision", workers, reason)
+	}
+}
+
+// launchRecorder 记录启动的命令而不真正执行
+type launchRecorder struct {
+	calls [][]string
+}
+
+func (r *launchRecorder) Run(name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	return nil, nil
+}
+
+// useLaunchRecorder 测试期间用launchRecorder替换文件管理器的启动
+func useLaunchRecorder(t *testing.T) *launchRecorder {
+	t.Helper()
+	recorder := &launchRecorder{}
+	saved := fileManagerRunner
+	fileManagerRunner = recorder
+	t.Cleanup(func() { fileManagerRunner = saved })
+	return recorder
+}
+
+func TestTrashOpenLaunchesFileManagerAtTrashPath(t *testing.T) {
+	initTempConfig(t)
+	t.Setenv("DISPLAY", ":0")
+	manager := useFakeTrash(t)
+	recorder := useLaunchRecorder(t)
+	trashPath, err := manager.GetTrashPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runTrashOpen(trashOpenCmd, nil); err != nil {
+		t.Fatalf("runTrashOpen: %v", err)
+	}
+	name, args := utils.FileManagerCommand(runtime.GOOS, trashPath)
+	if want := [][]string{append([]string{name}, args...)}; !reflect.DeepEqual(recorder.calls, want) {
+		t.Errorf("launched %q, want %q", recorder.calls, want)
+	}
+}
+
+func TestTrashOpenMissingTrashDirectory(t *testing.T) {
+	initTempConfig(t)
+	t.Setenv("DISPLAY", ":0")
+	manager := useFakeTrash(t)
+	recorder := useLaunchRecorder(t)
+	trashPath, _ := manager.GetTrashPath()
+	if err := os.Remove(trashPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runTrashOpen(trashOpenCmd, nil); err == nil || !strings.Contains(err.Error(), "还不存在") {
+		t.Errorf("runTrashOpen() = %v, want the missing trash directory reported", err)
+	}
+	if len(recorder.calls) > 0 {
+		t.Errorf("launched %q for a missing trash directory", recorder.calls)
+	}
+}
+
+func TestTrashOpenWithoutGUI(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("a graphical session is always assumed on", runtime.GOOS)
+	}
+	initTempConfig(t)
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	manager := useFakeTrash(t)
+	recorder := useLaunchRecorder(t)
+	trashPath, _ := manager.GetTrashPath()
+
+	err := runTrashOpen(trashOpenCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), trashPath) || !strings.Contains(err.Error(), "delguard list") {
+		t.Errorf("runTrashOpen() = %v, want the trash path and a hint to use list", err)
+	}
+	if len(recorder.calls) > 0 {
+		t.Errorf("launched %q without a graphical session", recorder.calls)
+	}
+}
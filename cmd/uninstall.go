@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 
+	"delguard/internal/i18n"
 	"delguard/internal/installer"
 
 	"github.com/spf13/cobra"
@@ -37,8 +38,8 @@ func init() {
 }
 
 func runUninstall(cmd *cobra.Command, args []string) error {
-	fmt.Printf("🗑️ DelGuard 卸载程序\n")
-	fmt.Printf("操作系统: %s %s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Println("🗑️ " + i18n.T("uninstall.title"))
+	fmt.Println(i18n.T("install.os", runtime.GOOS, runtime.GOARCH))
 	fmt.Println()
 
 	// 获取系统安装器
@@ -49,40 +50,38 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 
 	// 检查是否已安装
 	if !systemInstaller.IsInstalled() {
-		fmt.Println("ℹ️ DelGuard未安装或已被卸载")
+		fmt.Println("ℹ️ " + i18n.T("uninstall.not_installed"))
 		return nil
 	}
 
 	// 显示卸载信息
-	fmt.Println("📋 卸载信息:")
-	fmt.Printf("  安装路径: %s\n", systemInstaller.GetInstallPath())
-	fmt.Printf("  目标命令: %v\n", installer.GetTargetCommands())
+	fmt.Println("📋 " + i18n.T("uninstall.info"))
+	fmt.Println(i18n.T("install.install_path", systemInstaller.GetInstallPath()))
+	fmt.Println(i18n.T("install.commands", installer.GetTargetCommands()))
 	if keepConfig {
-		fmt.Println("  配置文件: 将保留")
+		fmt.Println(i18n.T("uninstall.keep_config"))
 	} else {
-		fmt.Println("  配置文件: 将删除")
+		fmt.Println(i18n.T("uninstall.remove_config"))
 	}
 	fmt.Println()
 
 	// 警告信息
-	fmt.Println("⚠️ 警告:")
-	fmt.Println("  卸载后，删除命令将恢复为系统默认行为（永久删除）")
-	fmt.Println("  请确保您了解这一变化的影响")
+	fmt.Println("⚠️ " + i18n.T("uninstall.warning"))
+	fmt.Println(i18n.T("uninstall.warn_default"))
+	fmt.Println(i18n.T("uninstall.warn_understand"))
 	fmt.Println()
 
 	// 确认卸载
 	if !forceUninstall {
-		fmt.Print("是否继续卸载？ (y/N): ")
-		var response string
-		fmt.Scanln(&response)
-		if response != "y" && response != "Y" && response != "yes" && response != "YES" {
-			fmt.Println("❌ 卸载已取消")
+		fmt.Print(i18n.T("uninstall.confirm"))
+		if confirmed, _ := confirmAction(); !confirmed {
+			fmt.Println("❌ " + i18n.T("uninstall.cancelled"))
 			return nil
 		}
 	}
 
 	// 执行卸载
-	fmt.Println("🔧 开始卸载...")
+	fmt.Println("🔧 " + i18n.T("uninstall.start"))
 	if err := systemInstaller.Uninstall(); err != nil {
 		return fmt.Errorf("卸载失败: %v", err)
 	}
@@ -95,22 +94,22 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 
 func showPostUninstallInstructions() {
 	fmt.Println()
-	fmt.Println("✅ 卸载完成！")
+	fmt.Println("✅ " + i18n.T("uninstall.done"))
 	fmt.Println()
-	fmt.Println("📝 重要提示:")
+	fmt.Println("📝 " + i18n.T("uninstall.notes"))
 
 	switch runtime.GOOS {
 	case "windows":
-		fmt.Println("  - 删除命令已恢复为Windows默认行为")
-		fmt.Println("  - del、rmdir命令现在将永久删除文件")
-		fmt.Println("  - 请重新启动PowerShell以完全清除别名")
+		fmt.Println(i18n.T("uninstall.note_windows"))
+		fmt.Println(i18n.T("uninstall.note_del"))
+		fmt.Println(i18n.T("uninstall.note_ps"))
 	case "darwin", "linux":
-		fmt.Println("  - 删除命令已恢复为系统默认行为")
-		fmt.Println("  - rm命令现在将永久删除文件")
-		fmt.Println("  - 请重新启动终端或重新加载shell配置")
+		fmt.Println(i18n.T("uninstall.note_unix"))
+		fmt.Println(i18n.T("uninstall.note_rm"))
+		fmt.Println(i18n.T("uninstall.note_reload"))
 	}
 
-	fmt.Println("  - 如需重新安装，请使用: delguard install")
+	fmt.Println(i18n.T("uninstall.note_reinstall"))
 	fmt.Println()
-	fmt.Println("🙏 感谢使用DelGuard！")
+	fmt.Println("🙏 " + i18n.T("uninstall.thanks"))
 }
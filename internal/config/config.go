@@ -6,6 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"delguard/internal/paths"
+	"delguard/internal/utils"
 
 	"github.com/spf13/viper"
 )
@@ -13,11 +18,15 @@ import (
 // Config 全局配置结构
 type Config struct {
 	Trash       TrashConfig       `yaml:"trash" mapstructure:"trash"`
+	Restore     RestoreConfig     `yaml:"restore" mapstructure:"restore"`
 	Logging     LoggingConfig     `yaml:"logging" mapstructure:"logging"`
 	UI          UIConfig          `yaml:"ui" mapstructure:"ui"`
 	Install     InstallConfig     `yaml:"install" mapstructure:"install"`
 	Security    SecurityConfig    `yaml:"security" mapstructure:"security"`
 	Performance PerformanceConfig `yaml:"performance" mapstructure:"performance"`
+	Integration IntegrationConfig `yaml:"integration" mapstructure:"integration"`
+	Telemetry   TelemetryConfig   `yaml:"telemetry" mapstructure:"telemetry"`
+	Windows     WindowsConfig     `yaml:"windows" mapstructure:"windows"`
 }
 
 // TrashConfig 回收站配置
@@ -27,6 +36,55 @@ type TrashConfig struct {
 	ConfirmDelete bool   `yaml:"confirm_delete" mapstructure:"confirm_delete"`
 	MaxSize       string `yaml:"max_size" mapstructure:"max_size"`
 	UseSystemTrash bool   `yaml:"use_system_trash" mapstructure:"use_system_trash"`
+	PreserveXattrs bool   `yaml:"preserve_xattrs" mapstructure:"preserve_xattrs"`
+	// DereferenceSymlinks 删除符号链接时作用于其指向的目标，而不是链接本身
+	DereferenceSymlinks bool `yaml:"dereference_symlinks" mapstructure:"dereference_symlinks"`
+	// Interactive 未指定-f/-i时逐个文件确认删除
+	Interactive bool `yaml:"interactive" mapstructure:"interactive"`
+	// Rotation 每次删除后的回收站轮转策略：none、count（超过MaxItems）或size（超过MaxSize），超出时删除最旧的项目
+	Rotation string `yaml:"rotation" mapstructure:"rotation"`
+	// MaxItems 按数量轮转时回收站中保留的最多项目数
+	MaxItems int `yaml:"max_items" mapstructure:"max_items"`
+	// VerifyInterval trash verify再次校验同一项目内容的间隔天数，--all时忽略
+	VerifyInterval int `yaml:"verify_interval" mapstructure:"verify_interval"`
+	// HashOnDelete 删除到DelGuard专用回收站时记录内容哈希；跨驱动器复制时随复制计算，
+	// 同一驱动器上重命名时需要额外读取一次文件，关闭后由trash verify首次校验时记录
+	HashOnDelete bool `yaml:"hash_on_delete" mapstructure:"hash_on_delete"`
+	// PruneToSystemBin trash prune将过期项目移入系统回收站而不是永久删除，只对DelGuard专用回收站有效
+	PruneToSystemBin bool `yaml:"prune_to_system_bin" mapstructure:"prune_to_system_bin"`
+	// VerifyBeforePrune 轮转和清理永久删除项目前重新计算哈希并与元数据比对，内容损坏的项目移入隔离目录而不是删除
+	VerifyBeforePrune bool `yaml:"verify_before_prune" mapstructure:"verify_before_prune"`
+	// ShowSystemBin list和restore同时列出Windows系统回收站中由资源管理器等删除的项目
+	ShowSystemBin bool `yaml:"show_system_bin" mapstructure:"show_system_bin"`
+	// NetworkTrash NFS/SMB/SSHFS等网络文件系统上的项目使用的回收站: share（同一共享上的回收站，只需重命名）
+	// 或local（本地回收站，需要跨网络复制）；使用专用回收站或--trash-dir时总是local
+	NetworkTrash string `yaml:"network_trash" mapstructure:"network_trash"`
+	// NetworkHash 网络文件系统上的项目也按HashOnDelete计算内容哈希，默认跳过以免再通过网络读取一遍
+	NetworkHash bool `yaml:"network_hash" mapstructure:"network_hash"`
+	// CompactAfterDays trash compact压缩删除超过此天数的文件
+	CompactAfterDays int `yaml:"compact_after_days" mapstructure:"compact_after_days"`
+	// CompressionLevel trash compact使用的gzip压缩级别(1-9)
+	CompressionLevel int `yaml:"compression_level" mapstructure:"compression_level"`
+	// RetentionRules 按原始位置设置的保留天数，按顺序匹配，第一个匹配的规则生效，都不匹配时使用MaxDays
+	RetentionRules []RetentionRule `yaml:"retention_rules" mapstructure:"retention_rules"`
+}
+
+// RetentionRule 单条保留规则
+type RetentionRule struct {
+	// Path 匹配原始路径的glob模式，匹配文件本身或其任一上级目录即视为命中，支持~
+	Path string `yaml:"path" mapstructure:"path"`
+	// Days 保留天数
+	Days int `yaml:"days" mapstructure:"days"`
+}
+
+// RestoreConfig 恢复设置
+type RestoreConfig struct {
+	// RenamePattern 恢复位置已存在文件时的改名模式，可用占位符 {name} {ext} {n} {timestamp}
+	RenamePattern string `yaml:"rename_pattern" mapstructure:"rename_pattern"`
+	// PreserveTimes 恢复时写回删除时记录的修改和访问时间
+	PreserveTimes bool `yaml:"preserve_times" mapstructure:"preserve_times"`
+	// PreserveOwner 以root运行时恢复删除时记录的所有者和组
+	PreserveOwner bool `yaml:"preserve_owner" mapstructure:"preserve_owner"`
 }
 
 // LoggingConfig 日志配置
@@ -36,6 +94,8 @@ type LoggingConfig struct {
 	MaxSize  int    `yaml:"max_size" mapstructure:"max_size"`
 	MaxAge   int    `yaml:"max_age" mapstructure:"max_age"`
 	Compress bool   `yaml:"compress" mapstructure:"compress"`
+	// ReportEnabled 每次删除后在状态目录的reports下写入操作回执，按MaxAge清理
+	ReportEnabled bool `yaml:"report_enabled" mapstructure:"report_enabled"`
 }
 
 // UIConfig 界面配置
@@ -44,6 +104,19 @@ type UIConfig struct {
 	Color       bool   `yaml:"color" mapstructure:"color"`
 	Unicode     bool   `yaml:"unicode" mapstructure:"unicode"`
 	ProgressBar bool   `yaml:"progress_bar" mapstructure:"progress_bar"`
+	// ProgressStyle 进度显示样式: none, bar, spinner, dots, detailed，--progress标志优先
+	ProgressStyle string `yaml:"progress_style" mapstructure:"progress_style"`
+	// Notifications 耗时操作完成后发送桌面通知
+	Notifications bool `yaml:"notifications" mapstructure:"notifications"`
+	// NotifyThreshold 触发通知的最短耗时(秒)
+	NotifyThreshold int `yaml:"notify_threshold" mapstructure:"notify_threshold"`
+	// DetailLevel 默认输出详细程度: minimal, normal, verbose, debug，-q/-v标志优先
+	DetailLevel string `yaml:"detail_level" mapstructure:"detail_level"`
+	// ConfirmTimeout 确认提示等待输入的最长时间(秒)，0表示一直等待
+	ConfirmTimeout int `yaml:"confirm_timeout" mapstructure:"confirm_timeout"`
+	// ConfirmDefault 确认提示超时后的回答: yes, no, abort(中止本次操作并以非零状态退出)，
+	// 永久删除和需要输入DELETE等确认词的提示超时总是取消
+	ConfirmDefault string `yaml:"confirm_default" mapstructure:"confirm_default"`
 }
 
 // InstallConfig 安装配置
@@ -60,17 +133,101 @@ type SecurityConfig struct {
 	MaxPathLength    int      `yaml:"max_path_length" mapstructure:"max_path_length"`
 	AllowedExtensions []string `yaml:"allowed_extensions" mapstructure:"allowed_extensions"`
 	BlockedExtensions []string `yaml:"blocked_extensions" mapstructure:"blocked_extensions"`
+	VirusScan         bool     `yaml:"virus_scan" mapstructure:"virus_scan"`
+	ScanOnDelete      bool     `yaml:"scan_on_delete" mapstructure:"scan_on_delete"`
+	ScanTimeout       int      `yaml:"scan_timeout" mapstructure:"scan_timeout"`
+	ScanMaxSize       string   `yaml:"scan_max_size" mapstructure:"scan_max_size"`
+	// RemoteFilesystems 删除NFS/SMB等远程文件系统上的项目时的处理方式: warn, refuse, delete
+	RemoteFilesystems string `yaml:"remote_filesystems" mapstructure:"remote_filesystems"`
+	// SafeMode 安全模式: strict, normal, relaxed，为空时由strict_mode决定
+	SafeMode string `yaml:"safe_mode" mapstructure:"safe_mode"`
+	// CheckSELinux 删除前读取SELinux标签，shadow_t等受保护类型的文件按系统文件处理，需要-f才能删除
+	CheckSELinux bool `yaml:"check_selinux" mapstructure:"check_selinux"`
+	// GitAwareness 删除Git仓库中的项目前提示未提交的修改，删除.git目录需要输入DELETE确认: on, off
+	GitAwareness string `yaml:"git_awareness" mapstructure:"git_awareness"`
+}
+
+// GitAwarenessEnabled 返回是否检查要删除的项目所在的Git仓库，git_awareness为off时跳过检查以加快删除
+func (c *Config) GitAwarenessEnabled() bool {
+	switch strings.ToLower(c.Security.GitAwareness) {
+	case "off", "false", "0", "no":
+		return false
+	}
+	return true
 }
 
+// 远程文件系统上的项目的处理方式
+const (
+	// RemoteWarn 提示后移动到回收站，回收站位于同一共享还是本机由trash.network_trash决定
+	RemoteWarn = "warn"
+	// RemoteRefuse 拒绝删除
+	RemoteRefuse = "refuse"
+	// RemoteDelete 输入DELETE确认后永久删除，不复制到本地回收站
+	RemoteDelete = "delete"
+)
+
 // PerformanceConfig 性能设置
 type PerformanceConfig struct {
 	BatchSize     int `yaml:"batch_size" mapstructure:"batch_size"`
 	BufferSize    int `yaml:"buffer_size" mapstructure:"buffer_size"`
+	// MaxConcurrent 并行处理文件的数量，0表示按存储类型自动选择（固态硬盘8、机械硬盘2、网络文件系统1）
 	MaxConcurrent int `yaml:"max_concurrent" mapstructure:"max_concurrent"`
+	// IOThrottle 后台维护任务的I/O速率上限(MB/s)，0表示不限制
+	IOThrottle int `yaml:"io_throttle" mapstructure:"io_throttle"`
+	// NiceDelay 后台维护任务每处理一个文件后的休眠时间(毫秒)
+	NiceDelay int `yaml:"nice_delay" mapstructure:"nice_delay"`
+	// Timeout 单个文件移动、恢复或跨设备复制的超时时间(秒)，0表示不限制
+	Timeout int `yaml:"timeout" mapstructure:"timeout"`
+	// NetworkTimeout 涉及网络文件系统的文件操作的超时时间(秒)，短于Timeout时使用Timeout，0表示不限制
+	NetworkTimeout int `yaml:"network_timeout" mapstructure:"network_timeout"`
+	// CopyRate 跨设备移动到回收站和恢复时复制文件内容的速率上限(MB/s)，0表示不限制
+	CopyRate int `yaml:"copy_rate" mapstructure:"copy_rate"`
+}
+
+// IntegrationConfig 外部集成设置
+type IntegrationConfig struct {
+	// ExternalCommands 外部命令模板，例如 virus_scan: "clamscan --no-summary {file}"
+	ExternalCommands map[string]string `yaml:"external_commands" mapstructure:"external_commands"`
+	// PluginDirectory 保护规则插件目录，其中的protect-*.sh/protect-*.exe会在每次删除前调用
+	PluginDirectory string `yaml:"plugin_directory" mapstructure:"plugin_directory"`
+	// HookTimeout 单个插件的最长执行时间(秒)
+	HookTimeout int `yaml:"hook_timeout" mapstructure:"hook_timeout"`
 }
 
-// GlobalConfig 全局配置实例
-var GlobalConfig *Config
+// TelemetryConfig 匿名使用统计设置，默认关闭，只有用户主动启用后才会记录和发送
+type TelemetryConfig struct {
+	// Enabled 是否记录并发送匿名统计
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Endpoint 接收统计的HTTPS地址，为空时统计只保存在本地队列中
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+	// Prompted 是否已经询问过用户是否同意发送统计，询问过后不再提示
+	Prompted bool `yaml:"prompted" mapstructure:"prompted"`
+	// ConsentTime 用户同意发送统计的时间(RFC3339)，关闭统计时清除
+	ConsentTime string `yaml:"consent_time" mapstructure:"consent_time"`
+}
+
+// WindowsConfig Windows专用设置
+type WindowsConfig struct {
+	// EnableUACPrompt 因权限不足删除失败时是否询问以管理员身份重试，只有用户明确同意后才会请求提权
+	EnableUACPrompt bool `yaml:"enable_uac_prompt" mapstructure:"enable_uac_prompt"`
+}
+
+// current 当前生效的配置，只会整体替换为新解析的实例，读取时无需加锁
+var current atomic.Pointer[Config]
+
+// Current 返回当前生效的配置快照，未初始化时返回nil
+// 配置重新加载后返回新的实例，已取得的快照保持不变；调用方不应修改返回的实例
+func Current() *Config {
+	return current.Load()
+}
+
+// firstRun 本次启动时配置文件不存在，已创建默认配置
+var firstRun bool
+
+// IsFirstRun 返回本次启动是否为首次运行（启动时没有配置文件）
+func IsFirstRun() bool {
+	return firstRun
+}
 
 // Init 初始化配置
 func Init() error {
@@ -85,6 +242,8 @@ func Init() error {
 
 	// 设置默认值
 	setDefaults()
+	// 按DELGUARD_前缀读取环境变量
+	BindEnv()
 
 	// 尝试读取配置文件
 	if err := viper.ReadInConfig(); err != nil {
@@ -93,16 +252,30 @@ func Init() error {
 			if err := createDefaultConfig(configDir); err != nil {
 				return fmt.Errorf("创建默认配置失败: %v", err)
 			}
+			firstRun = true
 		} else {
 			return fmt.Errorf("读取配置文件失败: %v", err)
 		}
+	} else {
+		recordConfigFile(viper.ConfigFileUsed())
+
+		// 旧版本配置文件先升级再解析
+		if !viper.InConfig("schema_version") || viper.GetString("schema_version") != SchemaVersion {
+			migrateConfigFile(viper.ConfigFileUsed())
+			if err := viper.ReadInConfig(); err != nil {
+				return fmt.Errorf("读取配置文件失败: %v", err)
+			}
+		}
 	}
 
 	// 解析配置到结构体
-	GlobalConfig = &Config{}
-	if err := viper.Unmarshal(GlobalConfig); err != nil {
-		return fmt.Errorf("解析配置失败: %v", err)
+	cfg, err := decodeConfig()
+	if err != nil {
+		return err
 	}
+	configMu.Lock()
+	current.Store(cfg)
+	configMu.Unlock()
 
 	return nil
 }
@@ -110,46 +283,101 @@ func Init() error {
 // setDefaults 设置默认配置值
 func setDefaults() {
 	// 回收站配置默认值
-	viper.SetDefault("trash.auto_clean", false)
-	viper.SetDefault("trash.max_days", 30)
-	viper.SetDefault("trash.confirm_delete", true)
-	viper.SetDefault("trash.max_size", "1GB")
-	viper.SetDefault("trash.use_system_trash", true)
+	setDefault("trash.auto_clean", false)
+	setDefault("trash.max_days", 30)
+	setDefault("trash.confirm_delete", true)
+	setDefault("trash.interactive", false)
+	setDefault("trash.dereference_symlinks", false)
+	setDefault("trash.max_size", "1GB")
+	setDefault("trash.rotation", "none")
+	setDefault("trash.max_items", 0)
+	setDefault("trash.verify_interval", 30)
+	setDefault("trash.hash_on_delete", true)
+	setDefault("trash.prune_to_system_bin", false)
+	setDefault("trash.verify_before_prune", false)
+	setDefault("trash.show_system_bin", false)
+	setDefault("trash.network_trash", "share")
+	setDefault("trash.network_hash", false)
+	setDefault("trash.compact_after_days", 14)
+	setDefault("trash.compression_level", 6)
+	setDefault("trash.use_system_trash", true)
+	setDefault("trash.preserve_xattrs", true)
+	setDefault("trash.retention_rules", []RetentionRule{})
+
+	// 恢复配置默认值
+	setDefault("restore.rename_pattern", utils.DefaultRenamePattern)
+	setDefault("restore.preserve_times", true)
+	setDefault("restore.preserve_owner", true)
 
 	// 日志配置默认值
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.file", getDefaultLogPath())
-	viper.SetDefault("logging.max_size", 10)
-	viper.SetDefault("logging.max_age", 7)
-	viper.SetDefault("logging.compress", true)
+	setDefault("logging.level", "info")
+	setDefault("logging.file", getDefaultLogPath())
+	setDefault("logging.max_size", 10)
+	setDefault("logging.max_age", 7)
+	setDefault("logging.compress", true)
+	setDefault("logging.report_enabled", false)
 
 	// UI配置默认值
-	viper.SetDefault("ui.language", "zh-CN")
-	viper.SetDefault("ui.color", true)
-	viper.SetDefault("ui.unicode", true)
-	viper.SetDefault("ui.progress_bar", true)
+	setDefault("ui.language", "zh-CN")
+	setDefault("ui.color", true)
+	setDefault("ui.unicode", true)
+	setDefault("ui.progress_bar", true)
+	setDefault("ui.progress_style", "bar")
+	setDefault("ui.notifications", true)
+	setDefault("ui.notify_threshold", 30)
+	setDefault("ui.detail_level", "normal")
+	setDefault("ui.confirm_timeout", 0)
+	setDefault("ui.confirm_default", "no")
 
 	// 安装配置默认值
-	viper.SetDefault("install.system_wide", true)
-	viper.SetDefault("install.install_dir", getDefaultInstallDir())
-	viper.SetDefault("install.create_alias", true)
-	viper.SetDefault("install.backup_original", true)
+	setDefault("install.system_wide", true)
+	setDefault("install.install_dir", getDefaultInstallDir())
+	setDefault("install.create_alias", true)
+	setDefault("install.backup_original", true)
 
 	// 安全设置默认值
-	viper.SetDefault("security.strict_mode", false)
-	viper.SetDefault("security.max_path_length", 4096)
-	viper.SetDefault("security.allowed_extensions", []string{"*"})
-	viper.SetDefault("security.blocked_extensions", []string{".sys", ".dll", ".exe", ".msi"})
+	setDefault("security.strict_mode", false)
+	setDefault("security.max_path_length", 4096)
+	setDefault("security.allowed_extensions", []string{"*"})
+	setDefault("security.blocked_extensions", []string{".sys", ".dll", ".exe", ".msi"})
+	setDefault("security.virus_scan", false)
+	setDefault("security.scan_on_delete", false)
+	setDefault("security.scan_timeout", 60)
+	setDefault("security.scan_max_size", "100MB")
+	setDefault("security.remote_filesystems", RemoteWarn)
+	setDefault("security.safe_mode", "")
+	setDefault("security.check_selinux", false)
+	setDefault("security.git_awareness", "on")
 
 	// 性能设置默认值
-	viper.SetDefault("performance.batch_size", 10)
-	viper.SetDefault("performance.buffer_size", 8192)
-	viper.SetDefault("performance.max_concurrent", 5)
+	setDefault("performance.batch_size", 10)
+	setDefault("performance.buffer_size", 8192)
+	setDefault("performance.max_concurrent", 0)
+	setDefault("performance.io_throttle", 0)
+	setDefault("performance.nice_delay", 0)
+	setDefault("performance.timeout", 30)
+	setDefault("performance.network_timeout", 300)
+	setDefault("performance.copy_rate", 0)
+
+	// 集成设置默认值
+	setDefault("integration.external_commands", map[string]string{})
+	setDefault("integration.plugin_directory", getDefaultPluginDir())
+	setDefault("integration.hook_timeout", 10)
+
+	// 匿名统计默认关闭
+	setDefault("telemetry.enabled", false)
+	setDefault("telemetry.endpoint", "")
+	setDefault("telemetry.prompted", false)
+	setDefault("telemetry.consent_time", "")
+
+	// Windows设置默认值
+	setDefault("windows.enable_uac_prompt", true)
 	
 	// 其他全局配置
-	viper.SetDefault("verbose", false)
-	viper.SetDefault("force", false)
-	viper.SetDefault("quiet", false)
+	setDefault("schema_version", SchemaVersion)
+	setDefault("verbose", false)
+	setDefault("force", false)
+	setDefault("quiet", false)
 }
 
 // createDefaultConfig 创建默认配置文件
@@ -161,21 +389,22 @@ func createDefaultConfig(configDir string) error {
 	}
 
 	configFile := filepath.Join(configDir, "config.yaml")
-	return viper.WriteConfigAs(configFile)
+	fileLock, err := lockConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+
+	// 其他进程可能已抢先创建了配置文件
+	if _, err := os.Stat(configFile); err == nil {
+		return nil
+	}
+	return writeConfigAtomic(viper.GetViper(), configFile)
 }
 
 // getConfigDir 获取配置目录
 func getConfigDir() string {
-	var configDir string
-	switch runtime.GOOS {
-	case "windows":
-		configDir = filepath.Join(os.Getenv("APPDATA"), "DelGuard")
-	case "darwin":
-		configDir = filepath.Join(os.Getenv("HOME"), ".config", "delguard")
-	default:
-		configDir = filepath.Join(os.Getenv("HOME"), ".config", "delguard")
-	}
-	return configDir
+	return paths.ConfigDir()
 }
 
 // getDefaultInstallDir 获取默认安装目录
@@ -188,18 +417,27 @@ func getDefaultInstallDir() string {
 	}
 }
 
-// getDefaultLogPath 获取默认日志路径
-func getDefaultLogPath() string {
-	var logDir string
-	switch runtime.GOOS {
-	case "windows":
-		logDir = filepath.Join(os.Getenv("APPDATA"), "DelGuard", "logs")
-	case "darwin":
-		logDir = filepath.Join(os.Getenv("HOME"), "Library", "Logs", "DelGuard")
-	default:
-		logDir = filepath.Join(os.Getenv("HOME"), ".local", "share", "delguard", "logs")
+// getDefaultPluginDir 获取默认插件目录
+func getDefaultPluginDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
 	}
+	return filepath.Join(homeDir, ".delguard", "plugins")
+}
+
+// GetReportDir 获取删除回执目录
+func GetReportDir() string {
+	stateDir, err := paths.StateDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(stateDir, "reports")
+}
 
+// getDefaultLogPath 获取默认日志路径
+func getDefaultLogPath() string {
+	logDir := paths.LogDir()
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		log.Printf("创建日志目录失败: %v", err)
 		// 回退到临时目录
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SchemaVersion 当前配置文件的结构版本
+const SchemaVersion = "1.1"
+
+// legacySchemaVersion 没有schema_version字段的配置文件视为该版本
+const legacySchemaVersion = "1.0"
+
+// Migration 配置文件结构的单步升级
+// Apply直接修改从文件读取的原始配置项，返回对所做变更的描述
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	Apply       func(raw map[string]interface{}) ([]string, error)
+}
+
+// MigrationStep 已执行（或预览）的单步升级
+type MigrationStep struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Description string   `json:"description"`
+	Changes     []string `json:"changes"`
+}
+
+// MigrationReport 配置迁移结果
+type MigrationReport struct {
+	File        string          `json:"file"`
+	FromVersion string          `json:"from_version"`
+	ToVersion   string          `json:"to_version"`
+	Steps       []MigrationStep `json:"steps"`
+	Written     bool            `json:"written"`
+}
+
+// Changed 是否需要（或已经）修改配置文件
+func (r *MigrationReport) Changed() bool {
+	return len(r.Steps) > 0
+}
+
+// migrations 按版本顺序注册的升级步骤
+var migrations = []Migration{
+	{
+		From:        "1.0",
+		To:          "1.1",
+		Description: "统一回收站配置项名称",
+		Apply:       migrateTrashKeys,
+	},
+}
+
+// RegisterMigration 注册新的升级步骤，From必须与已有步骤的To衔接
+func RegisterMigration(m Migration) {
+	migrations = append(migrations, m)
+}
+
+// MigrateFile 将配置文件逐步升级到SchemaVersion
+// dryRun为true时只报告将要发生的变更，不写入文件
+func MigrateFile(path string, dryRun bool) (*MigrationReport, error) {
+	fileLock, err := lockConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fileLock.Unlock()
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	raw := v.AllSettings()
+	report, err := runMigrations(raw)
+	if err != nil {
+		return nil, err
+	}
+	report.File = path
+
+	if dryRun || !report.Changed() {
+		return report, nil
+	}
+
+	migrated := viper.New()
+	if err := migrated.MergeConfigMap(raw); err != nil {
+		return nil, fmt.Errorf("生成迁移后的配置失败: %v", err)
+	}
+	if err := writeConfigAtomic(migrated, path); err != nil {
+		return nil, err
+	}
+	report.Written = true
+
+	return report, nil
+}
+
+// runMigrations 在原始配置项上依次执行升级步骤
+func runMigrations(raw map[string]interface{}) (*MigrationReport, error) {
+	version := legacySchemaVersion
+	if value, ok := lookupKey(raw, "schema_version"); ok {
+		version = fmt.Sprint(value)
+	}
+
+	report := &MigrationReport{FromVersion: version, ToVersion: SchemaVersion}
+	if compareVersions(version, SchemaVersion) > 0 {
+		return nil, fmt.Errorf("配置文件版本 %s 高于当前支持的版本 %s，请升级DelGuard", version, SchemaVersion)
+	}
+
+	for version != SchemaVersion {
+		migration, ok := findMigration(version)
+		if !ok {
+			return nil, fmt.Errorf("没有从版本 %s 升级的迁移步骤", version)
+		}
+
+		changes, err := migration.Apply(raw)
+		if err != nil {
+			return nil, fmt.Errorf("迁移 %s → %s 失败: %v", migration.From, migration.To, err)
+		}
+		setKey(raw, "schema_version", migration.To)
+		changes = append(changes, fmt.Sprintf("schema_version: %s → %s", migration.From, migration.To))
+
+		report.Steps = append(report.Steps, MigrationStep{
+			From:        migration.From,
+			To:          migration.To,
+			Description: migration.Description,
+			Changes:     changes,
+		})
+		version = migration.To
+	}
+
+	return report, nil
+}
+
+// migrateConfigFile Init时自动升级旧版本配置文件，失败时保留原文件继续运行
+func migrateConfigFile(path string) {
+	report, err := MigrateFile(path, false)
+	if err != nil {
+		log.Printf("配置迁移失败，继续使用原配置: %v", err)
+		return
+	}
+	for _, step := range report.Steps {
+		log.Printf("配置迁移 %s → %s (%s): %s", step.From, step.To, step.Description, strings.Join(step.Changes, "; "))
+	}
+}
+
+// findMigration 查找从指定版本出发的升级步骤
+func findMigration(from string) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.From == from {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// migrateTrashKeys 早期示例配置使用max_age/auto_cleanup，程序实际读取的是max_days/auto_clean
+func migrateTrashKeys(raw map[string]interface{}) ([]string, error) {
+	var changes []string
+	for _, rename := range [][2]string{
+		{"trash.max_age", "trash.max_days"},
+		{"trash.auto_cleanup", "trash.auto_clean"},
+	} {
+		if change, ok := renameKey(raw, rename[0], rename[1]); ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// renameKey 重命名配置项，新旧名称同时存在时保留新名称的值
+func renameKey(raw map[string]interface{}, oldKey, newKey string) (string, bool) {
+	value, ok := lookupKey(raw, oldKey)
+	if !ok {
+		return "", false
+	}
+	deleteKey(raw, oldKey)
+
+	if _, exists := lookupKey(raw, newKey); exists {
+		return fmt.Sprintf("删除 %s（已存在 %s）", oldKey, newKey), true
+	}
+	setKey(raw, newKey, value)
+	return fmt.Sprintf("%s → %s", oldKey, newKey), true
+}
+
+// lookupKey 按点分路径在嵌套配置中查找值，用于判断配置项是否存在于文件中
+func lookupKey(raw map[string]interface{}, key string) (interface{}, bool) {
+	parts := strings.Split(key, ".")
+	current := raw
+	for i, part := range parts {
+		value, ok := current[part]
+		if !ok {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// setKey 按点分路径设置值，缺少的中间层级会被创建
+func setKey(raw map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	current := raw
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+// deleteKey 按点分路径删除值
+func deleteKey(raw map[string]interface{}, key string) {
+	parts := strings.Split(key, ".")
+	current := raw
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+}
+
+// compareVersions 比较点分版本号，返回-1、0或1
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// legacyFixture 没有schema_version的早期配置文件，使用旧的回收站配置项名称
+const legacyFixture = `trash:
+  max_age: 14
+  auto_cleanup: true
+  max_size: 2GB
+ui:
+  language: en-US
+`
+
+// v09Fixture 0.9版本的配置文件，日志配置位于顶层的log_level
+const v09Fixture = `schema_version: "0.9"
+log_level: debug
+trash:
+  max_age: 7
+`
+
+func writeFixture(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readFixture(t *testing.T, path string) *viper.Viper {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("ReadInConfig: %v", err)
+	}
+	return v
+}
+
+// withMigrations 在测试期间替换已注册的升级步骤
+func withMigrations(t *testing.T, steps ...Migration) {
+	t.Helper()
+	saved := migrations
+	migrations = steps
+	t.Cleanup(func() { migrations = saved })
+}
+
+func TestMigrateLegacyFixture(t *testing.T) {
+	path := writeFixture(t, legacyFixture)
+
+	report, err := MigrateFile(path, false)
+	if err != nil {
+		t.Fatalf("MigrateFile: %v", err)
+	}
+	if report.FromVersion != legacySchemaVersion || report.ToVersion != SchemaVersion || !report.Written {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Steps) != 1 || len(report.Steps[0].Changes) != 3 {
+		t.Fatalf("steps = %+v, want one step with two renames and the version bump", report.Steps)
+	}
+
+	v := readFixture(t, path)
+	if got := v.GetString("schema_version"); got != SchemaVersion {
+		t.Errorf("schema_version = %q, want %q", got, SchemaVersion)
+	}
+	if v.GetInt("trash.max_days") != 14 || !v.GetBool("trash.auto_clean") {
+		t.Errorf("renamed keys = %v, %v", v.Get("trash.max_days"), v.Get("trash.auto_clean"))
+	}
+	if v.IsSet("trash.max_age") || v.IsSet("trash.auto_cleanup") {
+		t.Error("old keys were kept")
+	}
+	if v.GetString("trash.max_size") != "2GB" || v.GetString("ui.language") != "en-US" {
+		t.Error("unrelated keys were lost")
+	}
+
+	// 已是当前版本的文件不再变更
+	again, err := MigrateFile(path, false)
+	if err != nil || again.Changed() || again.Written {
+		t.Errorf("second MigrateFile = %+v, %v", again, err)
+	}
+}
+
+func TestMigrateCheckDoesNotWrite(t *testing.T) {
+	path := writeFixture(t, legacyFixture)
+
+	report, err := MigrateFile(path, true)
+	if err != nil {
+		t.Fatalf("MigrateFile: %v", err)
+	}
+	if !report.Changed() || report.Written {
+		t.Errorf("report = %+v, want changes that were not written", report)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacyFixture {
+		t.Errorf("--check modified the file:\n%s", data)
+	}
+}
+
+func TestMigrateChainsFrom09(t *testing.T) {
+	withMigrations(t,
+		Migration{
+			From:        "0.9",
+			To:          "1.0",
+			Description: "日志级别移入logging",
+			Apply: func(raw map[string]interface{}) ([]string, error) {
+				change, ok := renameKey(raw, "log_level", "logging.level")
+				if !ok {
+					return nil, nil
+				}
+				return []string{change}, nil
+			},
+		},
+		migrations[0],
+	)
+	path := writeFixture(t, v09Fixture)
+
+	report, err := MigrateFile(path, false)
+	if err != nil {
+		t.Fatalf("MigrateFile: %v", err)
+	}
+	var versions [][2]string
+	for _, step := range report.Steps {
+		versions = append(versions, [2]string{step.From, step.To})
+	}
+	if want := [][2]string{{"0.9", "1.0"}, {"1.0", SchemaVersion}}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("steps = %v, want %v", versions, want)
+	}
+
+	v := readFixture(t, path)
+	if v.GetString("schema_version") != SchemaVersion || v.GetString("logging.level") != "debug" || v.GetInt("trash.max_days") != 7 {
+		t.Errorf("migrated config = %v", v.AllSettings())
+	}
+	if v.IsSet("log_level") || v.IsSet("trash.max_age") {
+		t.Errorf("old keys were kept: %v", v.AllSettings())
+	}
+}
+
+func TestMigrateRejectsUnknownVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"newer than supported", "schema_version: \"9.0\"\n"},
+		{"no migration path", v09Fixture},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFixture(t, tt.content)
+			if _, err := MigrateFile(path, false); err == nil {
+				t.Fatal("MigrateFile succeeded")
+			}
+			data, _ := os.ReadFile(path)
+			if string(data) != tt.content {
+				t.Errorf("file was modified:\n%s", data)
+			}
+		})
+	}
+}
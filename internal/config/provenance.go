@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// 配置值来源
+const (
+	SourceDefault = "default"
+	SourceFlag    = "flag"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// Setting 单个配置项的生效值及其来源
+type Setting struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// SettingDiff 与默认值不同的配置项
+type SettingDiff struct {
+	Key     string      `json:"key"`
+	Default interface{} `json:"default"`
+	Value   interface{} `json:"value"`
+	Source  string      `json:"source"`
+}
+
+// EnvPrefix 配置项对应的环境变量前缀，例如trash.max_days对应DELGUARD_TRASH_MAX_DAYS
+const EnvPrefix = "DELGUARD"
+
+// envKeyReplacer 将配置键名中的点替换为环境变量名中的下划线
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+var (
+	provenanceMu  sync.RWMutex
+	defaultValues = make(map[string]interface{})
+	flagOverrides = make(map[string]bool)
+	loadedFile    string
+)
+
+// setDefault 设置默认值并记录下来，用于追溯配置来源
+func setDefault(key string, value interface{}) {
+	provenanceMu.Lock()
+	defaultValues[key] = value
+	provenanceMu.Unlock()
+
+	viper.SetDefault(key, value)
+}
+
+// recordConfigFile 记录Init时读取的配置文件路径
+// 命令行初始化会重新设置配置文件名，之后viper.ConfigFileUsed()不再指向该文件
+func recordConfigFile(path string) {
+	provenanceMu.Lock()
+	loadedFile = path
+	provenanceMu.Unlock()
+}
+
+// ConfigFileUsed 返回当前生效的配置文件路径
+func ConfigFileUsed() string {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path
+	}
+	provenanceMu.RLock()
+	defer provenanceMu.RUnlock()
+	return loadedFile
+}
+
+// BindEnv 让viper按EnvPrefix和envKeyReplacer从环境变量读取配置项，SourceOf按同样的规则追溯来源
+func BindEnv() {
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
+	viper.AutomaticEnv()
+}
+
+// EnvName 返回配置项对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// MarkFlagOverride 标记某个配置项由命令行标志显式设置
+func MarkFlagOverride(key string) {
+	provenanceMu.Lock()
+	flagOverrides[strings.ToLower(key)] = true
+	provenanceMu.Unlock()
+}
+
+// SourceOf 返回配置项当前值的来源: flag / env:<VAR> / file:<path> / default
+func SourceOf(key string) string {
+	key = strings.ToLower(key)
+
+	provenanceMu.RLock()
+	fromFlag := flagOverrides[key]
+	provenanceMu.RUnlock()
+	if fromFlag {
+		return SourceFlag
+	}
+
+	envName := EnvName(key)
+	if _, ok := os.LookupEnv(envName); ok {
+		return SourceEnv + ":" + envName
+	}
+
+	if viper.InConfig(key) {
+		return SourceFile + ":" + ConfigFileUsed()
+	}
+
+	return SourceDefault
+}
+
+// EffectiveSettings 返回所有配置项的生效值及来源，按键名排序
+func EffectiveSettings() []Setting {
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  viper.Get(key),
+			Source: SourceOf(key),
+		})
+	}
+
+	return settings
+}
+
+// DiffFromDefaults 返回生效值与默认值不同的配置项
+func DiffFromDefaults() []SettingDiff {
+	provenanceMu.RLock()
+	defaults := make(map[string]interface{}, len(defaultValues))
+	for key, value := range defaultValues {
+		defaults[key] = value
+	}
+	provenanceMu.RUnlock()
+
+	var diffs []SettingDiff
+	for _, setting := range EffectiveSettings() {
+		def, hasDefault := defaults[setting.Key]
+		// 切片等类型在配置文件中会被解析为[]interface{}，统一按文本比较
+		if hasDefault && fmt.Sprint(def) == fmt.Sprint(setting.Value) {
+			continue
+		}
+		diffs = append(diffs, SettingDiff{
+			Key:     setting.Key,
+			Default: def,
+			Value:   setting.Value,
+			Source:  setting.Source,
+		})
+	}
+
+	return diffs
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for key, want := range map[string]string{
+		"trash.max_days":         "DELGUARD_TRASH_MAX_DAYS",
+		"security.safe_mode":     "DELGUARD_SECURITY_SAFE_MODE",
+		"Telemetry.Consent_Time": "DELGUARD_TELEMETRY_CONSENT_TIME",
+	} {
+		if got := EnvName(key); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSourceOf(t *testing.T) {
+	path := useTempConfig(t, "en-US")
+	// 未加前缀或未替换点号的变量名不是配置项对应的环境变量
+	t.Setenv("TRASH.MAX_DAYS", "1")
+	t.Setenv("TRASH_MAX_DAYS", "2")
+	t.Setenv("DELGUARD_SECURITY_SAFE_MODE", "strict")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	MarkFlagOverride("ui.color")
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"security.safe_mode", "env:DELGUARD_SECURITY_SAFE_MODE"},
+		{"ui.language", "file:" + path},
+		{"ui.color", SourceFlag},
+		{"trash.max_days", SourceDefault},
+	}
+	for _, tt := range tests {
+		if got := SourceOf(tt.key); got != tt.want {
+			t.Errorf("SourceOf(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	if got := Current().Security.SafeMode; got != SafeModeStrict {
+		t.Errorf("safe_mode = %q, want the value from DELGUARD_SECURITY_SAFE_MODE", got)
+	}
+	if got := Current().Trash.MaxDays; got != 30 {
+		t.Errorf("max_days = %d, want the default 30", got)
+	}
+
+	found := false
+	for _, setting := range EffectiveSettings() {
+		if setting.Key == "security.safe_mode" {
+			found = true
+			if setting.Value != "strict" || !strings.HasPrefix(setting.Source, SourceEnv+":") {
+				t.Errorf("effective setting = %+v, want strict from the environment", setting)
+			}
+		}
+	}
+	if !found {
+		t.Error("security.safe_mode missing from EffectiveSettings")
+	}
+}
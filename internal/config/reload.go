@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"delguard/internal/utils"
+
+	"github.com/spf13/viper"
+)
+
+// configMu 串行化对当前配置的重新加载和替换
+// 配置只会整体替换为新解析的实例，已通过Current取得旧实例的调用方继续使用旧的完整快照
+var configMu sync.Mutex
+
+// decodeConfig 将viper中的当前配置解析为新的Config实例
+func decodeConfig() (*Config, error) {
+	cfg := &Config{}
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %v", err)
+	}
+	// 无效的改名模式不影响其他配置，回退到默认模式
+	if err := utils.ValidateRenamePattern(cfg.Restore.RenamePattern); err != nil {
+		log.Printf("restore.rename_pattern 无效，使用默认模式 %s: %v", utils.DefaultRenamePattern, err)
+		cfg.Restore.RenamePattern = utils.DefaultRenamePattern
+	}
+	return cfg, nil
+}
+
+// Reload 重新读取当前配置文件并替换当前配置，读取或解析失败时保留原配置
+// 供常驻进程在配置文件变化后使用；默认值和命令行标志的覆盖保持不变
+func Reload() error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	if path := ConfigFileUsed(); path != "" {
+		viper.SetConfigFile(path)
+		if err := viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("读取配置文件失败: %v", err)
+		}
+	}
+	cfg, err := decodeConfig()
+	if err != nil {
+		return err
+	}
+	current.Store(cfg)
+	return nil
+}
+
+// Reset 清除已加载的配置、配置来源记录和viper中的全部设置（包括命令行标志的绑定），之后需要重新调用Init
+// 用于在同一进程中多次初始化配置的场景，例如测试之间避免共享状态
+func Reset() {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	current.Store(nil)
+	firstRun = false
+
+	provenanceMu.Lock()
+	defaultValues = make(map[string]interface{})
+	flagOverrides = make(map[string]bool)
+	loadedFile = ""
+	provenanceMu.Unlock()
+
+	viper.Reset()
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"delguard/internal/paths"
+)
+
+// useTempConfig 将配置目录指向临时目录并写入ui.language为language的配置文件，测试结束后清除已加载的配置
+func useTempConfig(t *testing.T, language string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	Reset()
+	t.Cleanup(Reset)
+
+	path := filepath.Join(paths.ConfigDir(), "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeLanguage(t, path, language)
+	return path
+}
+
+func writeLanguage(t *testing.T, path, language string) {
+	t.Helper()
+	data := fmt.Sprintf("schema_version: %q\nui:\n  language: %s\n", SchemaVersion, language)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangedFileIsReReadAfterReset(t *testing.T) {
+	path := useTempConfig(t, "en-US")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if got := Current().UI.Language; got != "en-US" {
+		t.Fatalf("language = %q, want en-US", got)
+	}
+
+	writeLanguage(t, path, "zh-CN")
+	Reset()
+	if Current() != nil {
+		t.Fatal("Reset must clear the current config")
+	}
+	if err := Init(); err != nil {
+		t.Fatalf("Init after Reset: %v", err)
+	}
+	if got := Current().UI.Language; got != "zh-CN" {
+		t.Errorf("language after Reset = %q, want zh-CN", got)
+	}
+}
+
+func TestReloadKeepsEarlierSnapshot(t *testing.T) {
+	path := useTempConfig(t, "en-US")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	before := Current()
+
+	writeLanguage(t, path, "zh-CN")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := Current().UI.Language; got != "zh-CN" {
+		t.Errorf("language after Reload = %q, want zh-CN", got)
+	}
+	if before.UI.Language != "en-US" {
+		t.Errorf("snapshot taken before Reload changed to %q", before.UI.Language)
+	}
+}
+
+func TestReloadWhileReading(t *testing.T) {
+	path := useTempConfig(t, "en-US")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if language := Current().UI.Language; language != "en-US" && language != "zh-CN" {
+					t.Errorf("read a partially loaded config: language = %q", language)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		language := "en-US"
+		if i%2 == 0 {
+			language = "zh-CN"
+		}
+		writeLanguage(t, path, language)
+		if err := Reload(); err != nil {
+			t.Errorf("Reload: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,84 @@
+package config
+
+import "strings"
+
+// 安全模式，由security.safe_mode配置
+const (
+	// SafeModeStrict 严格模式：-f/-y不能跳过确认和安全检查，禁止不经过回收站的删除
+	SafeModeStrict = "strict"
+	// SafeModeNormal 默认模式
+	SafeModeNormal = "normal"
+	// SafeModeRelaxed 宽松模式：特殊文件不再询问，-f可以跳过永久删除的确认
+	SafeModeRelaxed = "relaxed"
+)
+
+// SafeModePolicy 安全模式对应的具体行为，删除、恢复和清空回收站的命令只根据这些字段决定如何处理
+//
+//	                               strict        normal        relaxed
+//	-f 跳过删除/恢复确认                否            是            是
+//	-f 删除系统文件、同意插件的确认请求     否            是            是
+//	restore -f 覆盖已存在的文件          否（改名）     是            是
+//	-y 跳过粉碎/永久删除的确认            否            是            是
+//	--no-trash、remote_filesystems=delete 拒绝      输入DELETE     输入DELETE或-f
+//	粉碎、永久删除回收站中的文件           输入DELETE     y确认         y确认
+//	empty、trash prune的确认(-f除外)     输入DELETE     命令默认       命令默认
+//	特殊文件无法移入回收站时询问           是            是（-f除外）   否
+type SafeModePolicy struct {
+	Mode string
+	// HonorForce -f 是否生效：跳过确认、删除系统文件、同意保护插件的确认请求、恢复时覆盖
+	HonorForce bool
+	// HonorYes -y 是否可以跳过粉碎和永久删除的确认
+	HonorYes bool
+	// AllowPermanentDelete 是否允许--no-trash和security.remote_filesystems: delete
+	AllowPermanentDelete bool
+	// RequireDeleteWord 粉碎和永久删除回收站中的文件时是否必须输入DELETE确认
+	RequireDeleteWord bool
+	// ForceDirectDelete -f 是否同样可以跳过--no-trash的DELETE确认
+	ForceDirectDelete bool
+	// PromptSpecialFiles 命名管道等特殊文件无法移入回收站时是否询问
+	PromptSpecialFiles bool
+}
+
+// NewSafeModePolicy 返回安全模式对应的行为，未知的模式按normal处理
+func NewSafeModePolicy(mode string) SafeModePolicy {
+	switch strings.ToLower(mode) {
+	case SafeModeStrict:
+		return SafeModePolicy{
+			Mode:               SafeModeStrict,
+			RequireDeleteWord:  true,
+			PromptSpecialFiles: true,
+		}
+	case SafeModeRelaxed:
+		return SafeModePolicy{
+			Mode:                 SafeModeRelaxed,
+			HonorForce:           true,
+			HonorYes:             true,
+			AllowPermanentDelete: true,
+			ForceDirectDelete:    true,
+		}
+	default:
+		return SafeModePolicy{
+			Mode:                 SafeModeNormal,
+			HonorForce:           true,
+			HonorYes:             true,
+			AllowPermanentDelete: true,
+			PromptSpecialFiles:   true,
+		}
+	}
+}
+
+// SafeMode 返回生效的安全模式，未配置safe_mode时兼容旧的strict_mode选项
+func (c *Config) SafeMode() string {
+	if c.Security.SafeMode != "" {
+		return strings.ToLower(c.Security.SafeMode)
+	}
+	if c.Security.StrictMode {
+		return SafeModeStrict
+	}
+	return SafeModeNormal
+}
+
+// SafeModePolicy 返回当前配置的安全模式对应的行为
+func (c *Config) SafeModePolicy() SafeModePolicy {
+	return NewSafeModePolicy(c.SafeMode())
+}
@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestNewSafeModePolicy(t *testing.T) {
+	tests := []struct {
+		mode string
+		want SafeModePolicy
+	}{
+		{SafeModeStrict, SafeModePolicy{Mode: SafeModeStrict, RequireDeleteWord: true, PromptSpecialFiles: true}},
+		{"STRICT", SafeModePolicy{Mode: SafeModeStrict, RequireDeleteWord: true, PromptSpecialFiles: true}},
+		{SafeModeNormal, SafeModePolicy{Mode: SafeModeNormal, HonorForce: true, HonorYes: true, AllowPermanentDelete: true, PromptSpecialFiles: true}},
+		{"unknown", SafeModePolicy{Mode: SafeModeNormal, HonorForce: true, HonorYes: true, AllowPermanentDelete: true, PromptSpecialFiles: true}},
+		{SafeModeRelaxed, SafeModePolicy{Mode: SafeModeRelaxed, HonorForce: true, HonorYes: true, AllowPermanentDelete: true, ForceDirectDelete: true}},
+	}
+	for _, tt := range tests {
+		if got := NewSafeModePolicy(tt.mode); got != tt.want {
+			t.Errorf("NewSafeModePolicy(%q) = %+v, want %+v", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestStrictModeBlocksNoTrash(t *testing.T) {
+	policy := NewSafeModePolicy(SafeModeStrict)
+	if policy.AllowPermanentDelete {
+		t.Error("strict mode must refuse --no-trash")
+	}
+	if policy.HonorForce || policy.HonorYes {
+		t.Error("strict mode must not let -f or -y skip confirmation")
+	}
+}
+
+func TestSafeModeFallsBackToStrictMode(t *testing.T) {
+	tests := []struct {
+		safeMode   string
+		strictMode bool
+		want       string
+	}{
+		{"", false, SafeModeNormal},
+		{"", true, SafeModeStrict},
+		{"Relaxed", true, SafeModeRelaxed},
+	}
+	for _, tt := range tests {
+		var c Config
+		c.Security.SafeMode = tt.safeMode
+		c.Security.StrictMode = tt.strictMode
+		if got := c.SafeMode(); got != tt.want {
+			t.Errorf("SafeMode(%q, strict_mode=%v) = %q, want %q", tt.safeMode, tt.strictMode, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"delguard/internal/lock"
+
+	"github.com/spf13/viper"
+)
+
+// configLockTimeout 等待其他进程释放配置文件锁的最长时间
+const configLockTimeout = 5 * time.Second
+
+// UpdateFile 在文件锁保护下读取配置文件、修改并原子写回
+// 多个DelGuard进程同时修改配置时按顺序执行，不会互相覆盖或写出损坏的文件
+func UpdateFile(path string, update func(v *viper.Viper) error) error {
+	fileLock, err := lockConfigFile(path)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if _, err := os.Stat(path); err == nil {
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("读取配置文件失败: %v", err)
+		}
+	}
+
+	if err := update(v); err != nil {
+		return err
+	}
+
+	return writeConfigAtomic(v, path)
+}
+
+// SaveValue 将单个配置项写入当前配置文件，只修改该项，不会把默认值或命令行参数写入文件
+func SaveValue(key string, value interface{}) error {
+	path := ConfigFileUsed()
+	if path == "" {
+		path = filepath.Join(getConfigDir(), "config.yaml")
+	}
+
+	if err := UpdateFile(path, func(v *viper.Viper) error {
+		v.Set(key, value)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// 同步到当前进程的配置
+	viper.Set(key, value)
+	recordConfigFile(path)
+	return refreshCurrent()
+}
+
+// SaveWithVersion 将多个配置项连同当前schema_version一次写入配置文件，返回配置文件路径
+func SaveWithVersion(values map[string]interface{}) (string, error) {
+	path := ConfigFileUsed()
+	if path == "" {
+		path = filepath.Join(getConfigDir(), "config.yaml")
+	}
+
+	if err := UpdateFile(path, func(v *viper.Viper) error {
+		for key, value := range values {
+			v.Set(key, value)
+		}
+		v.Set("schema_version", SchemaVersion)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	// 同步到当前进程的配置
+	for key, value := range values {
+		viper.Set(key, value)
+	}
+	recordConfigFile(path)
+	return path, refreshCurrent()
+}
+
+// refreshCurrent 将viper中更新后的配置解析为新的实例后整体替换当前配置，
+// 其他goroutine不会读到只更新了一部分的配置；尚未加载配置时不做任何事
+func refreshCurrent() error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	if current.Load() == nil {
+		return nil
+	}
+	cfg, err := decodeConfig()
+	if err != nil {
+		return err
+	}
+	current.Store(cfg)
+	return nil
+}
+
+// lockConfigFile 获取配置文件对应的锁
+func lockConfigFile(path string) (*lock.FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	fileLock, err := lock.Lock(path+".lock", configLockTimeout)
+	if err != nil {
+		if pid, held := lock.IsHeld(err); held {
+			return nil, fmt.Errorf("配置文件正被其他进程修改 (pid %d)，请稍后重试", pid)
+		}
+		return nil, fmt.Errorf("锁定配置文件失败: %v", err)
+	}
+	return fileLock, nil
+}
+
+// writeConfigAtomic 先写入同目录下的临时文件并刷盘，再重命名覆盖目标文件
+// 写入中途崩溃时原文件保持完整
+func writeConfigAtomic(v *viper.Viper, path string) error {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+
+	// 临时文件保留原扩展名，viper据此选择输出格式
+	tempFile, err := os.CreateTemp(dir, "."+base+".*"+filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("创建临时配置文件失败: %v", err)
+	}
+	tempPath := tempFile.Name()
+	tempFile.Close()
+
+	success := false
+	defer func() {
+		if !success {
+			os.Remove(tempPath)
+		}
+	}()
+
+	if err := v.WriteConfigAs(tempPath); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tempPath, mode); err != nil {
+		return fmt.Errorf("设置配置文件权限失败: %v", err)
+	}
+
+	if err := syncFile(tempPath); err != nil {
+		return fmt.Errorf("刷新配置文件失败: %v", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("替换配置文件失败: %v", err)
+	}
+	success = true
+
+	// 目录项刷盘失败不影响结果，Windows上无法打开目录
+	syncFile(dir)
+	return nil
+}
+
+// syncFile 将文件内容刷到磁盘
+func syncFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
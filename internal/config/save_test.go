@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestUpdateFileConcurrentWriters(t *testing.T) {
+	const writers, writes = 6, 15
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := UpdateFile(path, func(v *viper.Viper) error {
+		v.Set("stress.last", "")
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateFile: %v", err)
+	}
+
+	var done atomic.Bool
+	readerErr := make(chan error, 1)
+	go func() {
+		defer close(readerErr)
+		for !done.Load() {
+			v := viper.New()
+			v.SetConfigFile(path)
+			if err := v.ReadInConfig(); err != nil {
+				readerErr <- err
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*writes)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			key := fmt.Sprintf("stress.writer%d", w)
+			for i := 1; i <= writes; i++ {
+				i := i
+				if err := UpdateFile(path, func(v *viper.Viper) error {
+					v.Set(key, i)
+					v.Set("stress.last", key)
+					return nil
+				}); err != nil {
+					errs <- fmt.Errorf("%s write %d: %v", key, i, err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	done.Store(true)
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if err := <-readerErr; err != nil {
+		t.Fatalf("配置文件在并发写入期间无法解析: %v", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("ReadInConfig: %v", err)
+	}
+	// 读取、修改和写回在同一把锁内完成，任何写入者的修改都不会被其他写入者覆盖
+	for w := 0; w < writers; w++ {
+		key := fmt.Sprintf("stress.writer%d", w)
+		if got := v.GetInt(key); got != writes {
+			t.Errorf("%s = %d, want %d", key, got, writes)
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".config.yaml.*")); len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
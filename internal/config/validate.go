@@ -0,0 +1,322 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"delguard/internal/utils"
+
+	"github.com/spf13/viper"
+)
+
+// ValidationLevel 配置问题的严重级别
+type ValidationLevel string
+
+const (
+	// LevelError 错误，配置无法正常使用
+	LevelError ValidationLevel = "error"
+	// LevelWarning 警告，配置可用但可能不符合预期
+	LevelWarning ValidationLevel = "warning"
+	// LevelInfo 提示信息
+	LevelInfo ValidationLevel = "info"
+)
+
+// ValidationIssue 单个配置问题
+type ValidationIssue struct {
+	Level   ValidationLevel `json:"level"`
+	Key     string          `json:"key"`
+	Message string          `json:"message"`
+}
+
+// ValidationResult 配置校验结果
+type ValidationResult struct {
+	File   string            `json:"file,omitempty"`
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// validLogLevels 支持的日志级别
+var validLogLevels = []string{"debug", "info", "warn", "warning", "error"}
+
+// knownLanguages 已有翻译的界面语言
+var knownLanguages = []string{"zh-CN", "zh", "en-US", "en"}
+
+// validDetailLevels 可用的输出详细程度
+var validDetailLevels = []string{"minimal", "normal", "verbose", "debug"}
+
+// networkTrashModes 网络文件系统上的项目可选的回收站位置
+var networkTrashModes = []string{"share", "local"}
+
+// confirmDefaults 确认提示超时后可选的回答
+var confirmDefaults = []string{"yes", "no", "abort"}
+
+// ProgressStyles 可用的进度显示样式，none不显示进度
+var ProgressStyles = []string{"none", "bar", "spinner", "dots", "detailed"}
+
+// add 添加一个问题
+func (r *ValidationResult) add(level ValidationLevel, key, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, ValidationIssue{
+		Level:   level,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Count 统计指定级别的问题数量
+func (r *ValidationResult) Count(level ValidationLevel) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors 是否存在错误级别的问题
+func (r *ValidationResult) HasErrors() bool {
+	return r.Count(LevelError) > 0
+}
+
+// Validate 校验配置值，返回按严重级别分类的问题列表
+func (c *Config) Validate() *ValidationResult {
+	result := &ValidationResult{}
+
+	// 回收站设置
+	if c.Trash.MaxDays < 0 {
+		result.add(LevelError, "trash.max_days", "保留天数不能为负数: %d", c.Trash.MaxDays)
+	} else if c.Trash.MaxDays == 0 && c.Trash.AutoClean {
+		result.add(LevelWarning, "trash.max_days", "保留天数为0且启用了自动清理，文件删除后会立即被清理")
+	}
+	for i, rule := range c.Trash.RetentionRules {
+		key := fmt.Sprintf("trash.retention_rules[%d]", i)
+		if rule.Path == "" {
+			result.add(LevelError, key, "保留规则缺少path")
+		} else if _, err := filepath.Match(rule.Path, ""); err != nil {
+			result.add(LevelError, key, "无效的路径模式 %q: %v", rule.Path, err)
+		}
+		if rule.Days < 0 {
+			result.add(LevelError, key, "保留天数不能为负数: %d", rule.Days)
+		}
+	}
+	if c.Trash.MaxSize != "" {
+		if _, err := utils.ParseSize(c.Trash.MaxSize); err != nil {
+			result.add(LevelError, "trash.max_size", "无效的容量: %v", err)
+		}
+	}
+	switch strings.ToLower(c.Trash.Rotation) {
+	case "", "none", "size":
+	case "count":
+		if c.Trash.MaxItems <= 0 {
+			result.add(LevelError, "trash.max_items", "按数量轮转 (trash.rotation: count) 需要大于0的项目上限，当前为 %d", c.Trash.MaxItems)
+		}
+	default:
+		result.add(LevelError, "trash.rotation", "未知的轮转策略 %q，可选值: none, count, size", c.Trash.Rotation)
+	}
+	if c.Trash.MaxItems < 0 {
+		result.add(LevelError, "trash.max_items", "项目上限不能为负数: %d", c.Trash.MaxItems)
+	}
+	if c.Trash.VerifyInterval < 0 {
+		result.add(LevelError, "trash.verify_interval", "校验间隔不能为负数: %d", c.Trash.VerifyInterval)
+	}
+	if c.Trash.CompactAfterDays < 0 {
+		result.add(LevelError, "trash.compact_after_days", "压缩天数不能为负数: %d", c.Trash.CompactAfterDays)
+	}
+	if c.Trash.CompressionLevel < 1 || c.Trash.CompressionLevel > 9 {
+		result.add(LevelError, "trash.compression_level", "压缩级别必须在1到9之间，当前为 %d", c.Trash.CompressionLevel)
+	}
+	if c.Trash.NetworkTrash != "" && !containsFold(networkTrashModes, c.Trash.NetworkTrash) {
+		result.add(LevelError, "trash.network_trash", "未知的网络文件系统回收站位置 %q，可选值: %s", c.Trash.NetworkTrash, strings.Join(networkTrashModes, ", "))
+	}
+
+	// 恢复设置
+	if err := utils.ValidateRenamePattern(c.Restore.RenamePattern); err != nil {
+		result.add(LevelError, "restore.rename_pattern", "%v", err)
+	}
+
+	// 日志设置
+	if !containsFold(validLogLevels, c.Logging.Level) {
+		result.add(LevelError, "logging.level", "未知的日志级别 %q，可选值: %s", c.Logging.Level, strings.Join(validLogLevels, ", "))
+	}
+	if c.Logging.MaxSize <= 0 {
+		result.add(LevelWarning, "logging.max_size", "日志文件大小上限应大于0，当前为 %d", c.Logging.MaxSize)
+	}
+	if c.Logging.MaxAge < 0 {
+		result.add(LevelError, "logging.max_age", "日志保留天数不能为负数: %d", c.Logging.MaxAge)
+	}
+	if c.Logging.File == "" {
+		result.add(LevelInfo, "logging.file", "未设置日志文件，将使用默认路径")
+	}
+
+	// 界面设置
+	if c.UI.Language != "" && !containsFold(knownLanguages, c.UI.Language) {
+		result.add(LevelWarning, "ui.language", "界面语言 %q 没有对应翻译，将使用默认语言", c.UI.Language)
+	}
+	if c.UI.DetailLevel != "" && !containsFold(validDetailLevels, c.UI.DetailLevel) {
+		result.add(LevelError, "ui.detail_level", "未知的输出详细程度 %q，可选值: %s", c.UI.DetailLevel, strings.Join(validDetailLevels, ", "))
+	}
+	if c.UI.ProgressStyle != "" && !containsFold(ProgressStyles, c.UI.ProgressStyle) {
+		result.add(LevelError, "ui.progress_style", "未知的进度样式 %q，可选值: %s", c.UI.ProgressStyle, strings.Join(ProgressStyles, ", "))
+	}
+
+	if c.UI.Notifications && c.UI.NotifyThreshold < 0 {
+		result.add(LevelError, "ui.notify_threshold", "通知阈值不能为负数: %d", c.UI.NotifyThreshold)
+	}
+	if c.UI.ConfirmTimeout < 0 {
+		result.add(LevelError, "ui.confirm_timeout", "确认等待时间不能为负数: %d", c.UI.ConfirmTimeout)
+	}
+	if c.UI.ConfirmDefault != "" && !containsFold(confirmDefaults, c.UI.ConfirmDefault) {
+		result.add(LevelError, "ui.confirm_default", "未知的确认默认回答 %q，可选值: %s", c.UI.ConfirmDefault, strings.Join(confirmDefaults, ", "))
+	}
+
+	// 安全设置
+	if c.Security.MaxPathLength <= 0 {
+		result.add(LevelError, "security.max_path_length", "最大路径长度必须大于0，当前为 %d", c.Security.MaxPathLength)
+	} else if c.Security.MaxPathLength > 32767 {
+		result.add(LevelWarning, "security.max_path_length", "最大路径长度 %d 超过了任何平台支持的上限", c.Security.MaxPathLength)
+	}
+	for _, ext := range c.Security.BlockedExtensions {
+		if ext != "*" && containsFold(c.Security.AllowedExtensions, ext) {
+			result.add(LevelWarning, "security.blocked_extensions", "扩展名 %s 同时出现在允许和禁止列表中，将按禁止处理", ext)
+		}
+	}
+	if c.Security.ScanMaxSize != "" {
+		if _, err := utils.ParseSize(c.Security.ScanMaxSize); err != nil {
+			result.add(LevelError, "security.scan_max_size", "无效的扫描大小上限: %v", err)
+		}
+	}
+	if c.Security.VirusScan {
+		if c.Security.ScanTimeout <= 0 {
+			result.add(LevelWarning, "security.scan_timeout", "扫描超时应大于0，将使用默认的60秒")
+		}
+		if c.Integration.ExternalCommands["virus_scan"] == "" {
+			result.add(LevelInfo, "integration.external_commands.virus_scan", "未配置扫描命令，将自动检测系统中的扫描程序")
+		}
+	} else if c.Security.ScanOnDelete {
+		result.add(LevelWarning, "security.scan_on_delete", "未启用virus_scan，scan_on_delete不会生效")
+	}
+	switch strings.ToLower(c.Security.RemoteFilesystems) {
+	case "", RemoteWarn, RemoteRefuse, RemoteDelete:
+	default:
+		result.add(LevelError, "security.remote_filesystems", "未知的远程文件系统处理方式 %q，可选值: warn, refuse, delete", c.Security.RemoteFilesystems)
+	}
+	switch strings.ToLower(c.Security.GitAwareness) {
+	case "", "on", "off", "true", "false", "0", "1", "yes", "no":
+	default:
+		result.add(LevelError, "security.git_awareness", "未知的Git仓库检查设置 %q，可选值: on, off", c.Security.GitAwareness)
+	}
+	switch strings.ToLower(c.Security.SafeMode) {
+	case "", SafeModeStrict, SafeModeNormal, SafeModeRelaxed:
+		if c.Security.SafeMode != "" && c.Security.StrictMode && strings.ToLower(c.Security.SafeMode) != SafeModeStrict {
+			result.add(LevelWarning, "security.strict_mode", "已配置safe_mode: %s，strict_mode不再生效", c.Security.SafeMode)
+		}
+	default:
+		result.add(LevelError, "security.safe_mode", "未知的安全模式 %q，可选值: strict, normal, relaxed", c.Security.SafeMode)
+	}
+
+	// 集成设置
+	if c.Integration.HookTimeout <= 0 {
+		result.add(LevelWarning, "integration.hook_timeout", "插件超时应大于0，将使用默认的10秒")
+	}
+
+	// 匿名统计
+	if c.Telemetry.Endpoint != "" {
+		if endpoint, err := url.Parse(c.Telemetry.Endpoint); err != nil || endpoint.Host == "" {
+			result.add(LevelError, "telemetry.endpoint", "无效的地址: %s", c.Telemetry.Endpoint)
+		} else if endpoint.Scheme != "https" {
+			result.add(LevelError, "telemetry.endpoint", "统计只能通过HTTPS发送: %s", c.Telemetry.Endpoint)
+		}
+	} else if c.Telemetry.Enabled {
+		result.add(LevelInfo, "telemetry.endpoint", "未配置发送地址，统计只保存在本地队列中")
+	}
+
+	// 性能设置
+	if c.Performance.BatchSize <= 0 {
+		result.add(LevelError, "performance.batch_size", "批量大小必须大于0，当前为 %d", c.Performance.BatchSize)
+	}
+	if c.Performance.BufferSize <= 0 {
+		result.add(LevelError, "performance.buffer_size", "缓冲区大小必须大于0，当前为 %d", c.Performance.BufferSize)
+	}
+	if c.Performance.MaxConcurrent < 0 {
+		result.add(LevelError, "performance.max_concurrent", "最大并发数不能为负数，0表示自动选择，当前为 %d", c.Performance.MaxConcurrent)
+	}
+	if c.Performance.IOThrottle < 0 {
+		result.add(LevelError, "performance.io_throttle", "I/O速率上限不能为负数: %d", c.Performance.IOThrottle)
+	}
+	if c.Performance.NiceDelay < 0 {
+		result.add(LevelError, "performance.nice_delay", "休眠时间不能为负数: %d", c.Performance.NiceDelay)
+	}
+	if c.Performance.Timeout < 0 {
+		result.add(LevelError, "performance.timeout", "超时时间不能为负数: %d", c.Performance.Timeout)
+	}
+	if c.Performance.NetworkTimeout < 0 {
+		result.add(LevelError, "performance.network_timeout", "超时时间不能为负数: %d", c.Performance.NetworkTimeout)
+	}
+	if c.Performance.CopyRate < 0 {
+		result.add(LevelError, "performance.copy_rate", "复制速率上限不能为负数: %d", c.Performance.CopyRate)
+	}
+
+	return result
+}
+
+// ValidateFile 读取并校验指定的配置文件，未设置的项使用默认值
+// 文件无法读取或解析时返回错误
+func ValidateFile(path string) (*ValidationResult, error) {
+	v := viper.New()
+	provenanceMu.RLock()
+	for key, value := range defaultValues {
+		v.SetDefault(key, value)
+	}
+	provenanceMu.RUnlock()
+
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %v", err)
+	}
+
+	result := cfg.Validate()
+	result.File = path
+
+	// 拼写错误的配置项会被静默忽略，单独提示
+	var unknown []string
+	for _, key := range v.AllKeys() {
+		if !v.InConfig(key) || isKnownKey(key) {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		result.add(LevelWarning, key, "未知的配置项，将被忽略")
+	}
+
+	return result, nil
+}
+
+// isKnownKey 检查配置项是否有对应的默认值定义
+func isKnownKey(key string) bool {
+	if strings.HasPrefix(key, "integration.external_commands.") {
+		return true
+	}
+	provenanceMu.RLock()
+	defer provenanceMu.RUnlock()
+	_, ok := defaultValues[key]
+	return ok
+}
+
+// containsFold 不区分大小写地检查列表中是否包含指定值
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
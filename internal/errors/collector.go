@@ -0,0 +1,182 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"delguard/internal/i18n"
+)
+
+// CollectedError 批量操作中单个项目的错误
+type CollectedError struct {
+	Path string
+	Err  error
+}
+
+// ErrorCollector 收集批量操作中的错误，单个项目失败不会中止整个批次
+type ErrorCollector struct {
+	mu    sync.Mutex
+	items []CollectedError
+}
+
+// NewErrorCollector 创建错误收集器
+func NewErrorCollector() *ErrorCollector {
+	return &ErrorCollector{}
+}
+
+// Add 记录一个项目的错误，err为nil时忽略
+func (c *ErrorCollector) Add(path string, err error) {
+	if err == nil {
+		return
+	}
+	c.mu.Lock()
+	c.items = append(c.items, CollectedError{Path: path, Err: err})
+	c.mu.Unlock()
+}
+
+// Len 返回已收集的错误数量
+func (c *ErrorCollector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// HasErrors 是否收集到错误
+func (c *ErrorCollector) HasErrors() bool {
+	return c.Len() > 0
+}
+
+// Errors 返回已收集错误的副本
+func (c *ErrorCollector) Errors() []CollectedError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	items := make([]CollectedError, len(c.items))
+	copy(items, c.items)
+	return items
+}
+
+// Summary 按错误类型汇总，例如 "3 个项目失败 (权限不足 2, 其他 1)"
+func (c *ErrorCollector) Summary() string {
+	items := c.Errors()
+	if len(items) == 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, item := range items {
+		counts[typeLabel(classify(item.Err))]++
+	}
+
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if counts[labels[i]] != counts[labels[j]] {
+			return counts[labels[i]] > counts[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%s %d", label, counts[label])
+	}
+	return i18n.Plural("errors.summary", len(items), strings.Join(parts, ", "))
+}
+
+// Err 将收集到的错误合并为一个错误，没有错误时返回nil
+// 所有错误类型相同时保留该类型
+func (c *ErrorCollector) Err() error {
+	items := c.Errors()
+	if len(items) == 0 {
+		return nil
+	}
+	if len(items) == 1 {
+		if _, ok := items[0].Err.(*DelGuardError); ok {
+			return items[0].Err
+		}
+	}
+
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("%s: %v", item.Path, item.Err))
+	}
+
+	const maxListed = 5
+	if len(lines) > maxListed {
+		lines = append(lines[:maxListed], i18n.Plural("errors.more", len(items)-maxListed))
+	}
+	return NewError(c.Type(), c.Summary(), fmt.Errorf("%s", strings.Join(lines, "; ")))
+}
+
+// Type 所有错误类型相同时返回该类型，类型不一致或没有错误时返回ErrTypeUnknown
+func (c *ErrorCollector) Type() ErrorType {
+	items := c.Errors()
+	if len(items) == 0 {
+		return ErrTypeUnknown
+	}
+	errType := classify(items[0].Err)
+	for _, item := range items[1:] {
+		if classify(item.Err) != errType {
+			return ErrTypeUnknown
+		}
+	}
+	return errType
+}
+
+// classify 判断错误所属的类型
+func classify(err error) ErrorType {
+	if delErr, ok := err.(*DelGuardError); ok {
+		return delErr.Type
+	}
+	switch {
+	case matchesAny(err, diskFullErrnos):
+		return ErrTypeDiskFull
+	case os.IsPermission(err):
+		return ErrTypePermissionDenied
+	case os.IsNotExist(err):
+		return ErrTypeFileNotFound
+	default:
+		return ErrTypeUnknown
+	}
+}
+
+// typeLabel 错误类型的简短名称，用于汇总显示
+func typeLabel(errType ErrorType) string {
+	switch errType {
+	case ErrTypeFileNotFound:
+		return "文件不存在"
+	case ErrTypePermissionDenied:
+		return "权限不足"
+	case ErrTypeInvalidPath:
+		return "路径无效"
+	case ErrTypeTrashFull:
+		return "回收站已满"
+	case ErrTypeMalware:
+		return "安全扫描拒绝"
+	case ErrTypeAlreadyInTrash:
+		return "已在回收站中"
+	case ErrTypeDiskFull:
+		return "磁盘空间不足"
+	case ErrTypeBlocked:
+		return "被保护规则阻止"
+	case ErrTypeTimeout:
+		return "超时"
+	case ErrTypeTransient:
+		return "暂时失败"
+	case ErrTypeValidation:
+		return "策略拒绝"
+	case ErrTypeIO:
+		return "读写失败"
+	case ErrTypeSpecialFile:
+		return "特殊文件"
+	case ErrTypeUsage:
+		return "用法错误"
+	default:
+		return "其他"
+	}
+}
@@ -0,0 +1,162 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// 进程退出码，取值沿用BSD sysexits.h的约定，便于脚本区分失败原因
+const (
+	ExitOK          = 0
+	ExitFailure     = 1  // 未分类的失败
+	ExitUsage       = 64 // 命令行参数或标志使用错误
+	ExitDataErr     = 65 // 被保护规则、安全扫描或删除策略拒绝
+	ExitNoInput     = 66 // 文件不存在或路径无效
+	ExitUnavailable = 69 // 网络不可用
+	ExitIOErr       = 74 // 读写失败或磁盘空间不足
+	ExitTempFail    = 75 // 超时或暂时性错误，稍后重试可能成功
+	ExitNoPerm      = 77 // 权限不足
+	ExitConfig      = 78 // 配置错误
+)
+
+// exitCodes 错误类型对应的退出码，未列出的类型使用ExitFailure
+var exitCodes = map[ErrorType]int{
+	ErrTypeFileNotFound:     ExitNoInput,
+	ErrTypeInvalidPath:      ExitNoInput,
+	ErrTypePermissionDenied: ExitNoPerm,
+	ErrTypeTrashFull:        ExitIOErr,
+	ErrTypeDiskFull:         ExitIOErr,
+	ErrTypeIO:               ExitIOErr,
+	ErrTypeConfigError:      ExitConfig,
+	ErrTypeNetworkError:     ExitUnavailable,
+	ErrTypeMalware:          ExitDataErr,
+	ErrTypeBlocked:          ExitDataErr,
+	ErrTypeValidation:       ExitDataErr,
+	ErrTypeTimeout:          ExitTempFail,
+	ErrTypeTransient:        ExitTempFail,
+	ErrTypeUsage:            ExitUsage,
+}
+
+// ExitCodeInfo 一个退出码的名称、含义和对应的错误类型，供脚本查询退出码约定
+type ExitCodeInfo struct {
+	Code        int      `json:"code"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Kinds       []string `json:"kinds"`
+}
+
+// exitCodeDocs 退出码的名称和说明，名称沿用sysexits.h去掉EX_前缀的小写形式
+var exitCodeDocs = []ExitCodeInfo{
+	{Code: ExitOK, Name: "ok", Description: "成功"},
+	{Code: ExitFailure, Name: "failure", Description: "未分类的失败，或批量操作中多个项目的失败原因不同"},
+	{Code: ExitUsage, Name: "usage", Description: "命令行参数或标志使用错误"},
+	{Code: ExitDataErr, Name: "dataerr", Description: "被保护规则、安全扫描或删除策略拒绝"},
+	{Code: ExitNoInput, Name: "noinput", Description: "文件不存在或路径无效"},
+	{Code: ExitUnavailable, Name: "unavailable", Description: "网络不可用"},
+	{Code: ExitIOErr, Name: "ioerr", Description: "读写失败、回收站已满或磁盘空间不足"},
+	{Code: ExitTempFail, Name: "tempfail", Description: "超时或暂时性错误，稍后重试可能成功"},
+	{Code: ExitNoPerm, Name: "noperm", Description: "权限不足"},
+	{Code: ExitConfig, Name: "config", Description: "配置错误"},
+}
+
+// ExitCode 返回错误类型对应的进程退出码，未单独分配退出码的类型返回ExitFailure
+func (t ErrorType) ExitCode() int {
+	if code, ok := exitCodes[t]; ok {
+		return code
+	}
+	return ExitFailure
+}
+
+// ExitCodes 返回所有退出码及其对应的错误类型名称，按退出码排序
+func ExitCodes() []ExitCodeInfo {
+	infos := make([]ExitCodeInfo, 0, len(exitCodeDocs))
+	for _, doc := range exitCodeDocs {
+		info := doc
+		info.Kinds = []string{}
+		if info.Code != ExitOK {
+			for _, errType := range AllErrorTypes() {
+				if errType.ExitCode() == info.Code {
+					info.Kinds = append(info.Kinds, errType.Kind())
+				}
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Code < infos[j].Code })
+	return infos
+}
+
+// ExitCode 返回错误对应的进程退出码，取错误链中第一个DelGuard错误的类型
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var delErr *DelGuardError
+	if stderrors.As(err, &delErr) {
+		return delErr.Type.ExitCode()
+	}
+	return ExitFailure
+}
+
+// FormatErrorForDisplay 将错误格式化为面向用户的文本，能够分类的错误在下一行附上处理建议
+func FormatErrorForDisplay(err error) string {
+	var delErr *DelGuardError
+	if !stderrors.As(err, &delErr) || (delErr.Type == ErrTypeUnknown && delErr.Hint == "") {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s\n💡 %s", err.Error(), GetErrorMessage(delErr))
+}
+
+// jsonError 机器可读的错误输出，每个错误一行
+type jsonError struct {
+	Kind      string `json:"kind"`
+	ExitCode  int    `json:"exit_code"`
+	Op        string `json:"op,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Message   string `json:"message"`
+	Advice    string `json:"advice,omitempty"`
+	Timestamp string `json:"timestamp"`
+	Stack     string `json:"stack,omitempty"`
+}
+
+// FormatErrorJSON 将错误格式化为单行JSON，供脚本解析stderr
+// op为出错的命令，withStack为true时附上创建错误时的调用栈；不含DelGuard错误时kind为unknown
+func FormatErrorJSON(err error, op string, withStack bool) string {
+	out := jsonError{
+		Kind:      KindOf(err),
+		ExitCode:  ExitCode(err),
+		Op:        op,
+		Message:   err.Error(),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	var delErr *DelGuardError
+	if stderrors.As(err, &delErr) {
+		out.Path = delErr.Path
+		if delErr.Type != ErrTypeUnknown || delErr.Hint != "" {
+			out.Advice = GetErrorMessage(delErr)
+		}
+	}
+	if out.Path == "" {
+		var pathErr *os.PathError
+		var linkErr *os.LinkError
+		if stderrors.As(err, &pathErr) {
+			out.Path = pathErr.Path
+		} else if stderrors.As(err, &linkErr) {
+			out.Path = linkErr.Old
+		}
+	}
+	if withStack {
+		out.Stack = StackOf(err)
+	}
+
+	data, marshalErr := json.Marshal(out)
+	if marshalErr != nil {
+		return fmt.Sprintf(`{"kind":"unknown","exit_code":%d,"message":%q}`, out.ExitCode, out.Message)
+	}
+	return string(data)
+}
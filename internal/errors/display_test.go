@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// stableExitCodes 已公开的错误类型与退出码，脚本依赖这些取值，修改需要同时更新文档
+var stableExitCodes = map[string]int{
+	"unknown":           ExitFailure,
+	"file_not_found":    ExitNoInput,
+	"permission_denied": ExitNoPerm,
+	"invalid_path":      ExitNoInput,
+	"trash_full":        ExitIOErr,
+	"config_error":      ExitConfig,
+	"network_error":     ExitUnavailable,
+	"malware":           ExitDataErr,
+	"already_in_trash":  ExitFailure,
+	"disk_full":         ExitIOErr,
+	"blocked":           ExitDataErr,
+	"timeout":           ExitTempFail,
+	"transient":         ExitTempFail,
+	"validation":        ExitDataErr,
+	"io":                ExitIOErr,
+	"special_file":      ExitFailure,
+	"usage":             ExitUsage,
+}
+
+func TestExitCodeContract(t *testing.T) {
+	kinds := make(map[string]bool)
+	for _, errType := range AllErrorTypes() {
+		kind := errType.Kind()
+		if kinds[kind] {
+			t.Errorf("kind %q is used by more than one error type", kind)
+		}
+		kinds[kind] = true
+
+		want, ok := stableExitCodes[kind]
+		if !ok {
+			t.Errorf("kind %q has no documented exit code, add it to stableExitCodes", kind)
+			continue
+		}
+		if got := errType.ExitCode(); got != want {
+			t.Errorf("%s exits with %d, want %d", kind, got, want)
+		}
+		if got := ExitCode(fmt.Errorf("wrapped: %w", NewError(errType, "failed", nil))); got != want {
+			t.Errorf("wrapped %s exits with %d, want %d", kind, got, want)
+		}
+	}
+	for kind := range stableExitCodes {
+		if !kinds[kind] {
+			t.Errorf("documented kind %q no longer exists", kind)
+		}
+	}
+}
+
+func TestExitCodesListing(t *testing.T) {
+	infos := ExitCodes()
+	if !sort.SliceIsSorted(infos, func(i, j int) bool { return infos[i].Code < infos[j].Code }) {
+		t.Error("ExitCodes() is not sorted by code")
+	}
+
+	codes := make(map[int]bool)
+	names := make(map[string]bool)
+	listed := make(map[string]int)
+	for _, info := range infos {
+		if codes[info.Code] || names[info.Name] {
+			t.Errorf("exit code %d (%s) is listed twice", info.Code, info.Name)
+		}
+		codes[info.Code], names[info.Name] = true, true
+		if info.Description == "" {
+			t.Errorf("exit code %d has no description", info.Code)
+		}
+		if info.Kinds == nil {
+			t.Errorf("exit code %d has nil kinds, want an empty list in JSON", info.Code)
+		}
+		for _, kind := range info.Kinds {
+			listed[kind] = info.Code
+		}
+	}
+	if len(infos[0].Kinds) != 0 || infos[0].Code != ExitOK {
+		t.Errorf("first entry = %+v, want ok without kinds", infos[0])
+	}
+
+	// 每种错误类型恰好出现在它的退出码下，退出码本身都有文档
+	for _, errType := range AllErrorTypes() {
+		code := errType.ExitCode()
+		if !codes[code] {
+			t.Errorf("%s exits with undocumented code %d", errType.Kind(), code)
+		}
+		if got, ok := listed[errType.Kind()]; !ok || got != code {
+			t.Errorf("%s is listed under %d, want %d", errType.Kind(), got, code)
+		}
+	}
+}
+
+func TestExitCodeOfPlainErrors(t *testing.T) {
+	if got := ExitCode(nil); got != ExitOK {
+		t.Errorf("ExitCode(nil) = %d, want %d", got, ExitOK)
+	}
+	if got := ExitCode(fmt.Errorf("plain")); got != ExitFailure {
+		t.Errorf("ExitCode(plain) = %d, want %d", got, ExitFailure)
+	}
+}
@@ -3,6 +3,8 @@ package errors
 import (
 	"fmt"
 	"runtime"
+	"sort"
+	"time"
 )
 
 // ErrorType 错误类型
@@ -23,6 +25,26 @@ const (
 	ErrTypeConfigError
 	// ErrTypeNetworkError 网络错误
 	ErrTypeNetworkError
+	// ErrTypeMalware 检测到恶意软件
+	ErrTypeMalware
+	// ErrTypeAlreadyInTrash 文件已在回收站中
+	ErrTypeAlreadyInTrash
+	// ErrTypeDiskFull 磁盘空间不足
+	ErrTypeDiskFull
+	// ErrTypeBlocked 被保护规则阻止
+	ErrTypeBlocked
+	// ErrTypeTimeout 操作超时
+	ErrTypeTimeout
+	// ErrTypeTransient 暂时性错误，重试可能成功
+	ErrTypeTransient
+	// ErrTypeValidation 不满足删除策略，被拒绝操作
+	ErrTypeValidation
+	// ErrTypeIO 读写文件失败，例如跨设备移动或复制中断
+	ErrTypeIO
+	// ErrTypeSpecialFile 命名管道、套接字或设备文件无法复制到回收站
+	ErrTypeSpecialFile
+	// ErrTypeUsage 命令行参数或标志使用错误
+	ErrTypeUsage
 )
 
 // DelGuardError DelGuard自定义错误
@@ -32,6 +54,14 @@ type DelGuardError struct {
 	Cause   error
 	File    string
 	Line    int
+	// Component 路径中无法访问的那一级目录，用于提示真正出错的位置
+	Component string
+	// Hint 针对具体失败原因的处理建议，非空时优先于按类型给出的通用提示
+	Hint string
+	// Path 出错的文件路径，用于机器可读的错误输出
+	Path string
+	// stack 创建错误时的调用栈，--debug时随错误输出
+	stack []uintptr
 }
 
 // Error 实现error接口
@@ -56,22 +86,37 @@ func NewError(errType ErrorType, message string, cause error) *DelGuardError {
 		Cause:   cause,
 		File:    file,
 		Line:    line,
+		stack:   callers(),
 	}
 }
 
 // NewFileNotFoundError 创建文件未找到错误
 func NewFileNotFoundError(path string) *DelGuardError {
-	return NewError(ErrTypeFileNotFound, fmt.Sprintf("文件未找到: %s", path), nil)
+	err := NewError(ErrTypeFileNotFound, fmt.Sprintf("文件未找到: %s", path), nil)
+	err.Path = path
+	return err
 }
 
 // NewPermissionDeniedError 创建权限拒绝错误
 func NewPermissionDeniedError(path string) *DelGuardError {
-	return NewError(ErrTypePermissionDenied, fmt.Sprintf("权限不足: %s", path), nil)
+	err := NewError(ErrTypePermissionDenied, fmt.Sprintf("权限不足: %s", path), nil)
+	err.Path = path
+	return err
+}
+
+// NewComponentPermissionError 创建路径中某一级目录无法访问导致的权限错误
+func NewComponentPermissionError(path string, component string) *DelGuardError {
+	err := NewError(ErrTypePermissionDenied, fmt.Sprintf("无法读取 %s (权限不足): %s", component, path), nil)
+	err.Component = component
+	err.Path = path
+	return err
 }
 
 // NewInvalidPathError 创建无效路径错误
 func NewInvalidPathError(path string) *DelGuardError {
-	return NewError(ErrTypeInvalidPath, fmt.Sprintf("无效路径: %s", path), nil)
+	err := NewError(ErrTypeInvalidPath, fmt.Sprintf("无效路径: %s", path), nil)
+	err.Path = path
+	return err
 }
 
 // NewTrashFullError 创建回收站已满错误
@@ -89,6 +134,126 @@ func NewNetworkError(message string, cause error) *DelGuardError {
 	return NewError(ErrTypeNetworkError, fmt.Sprintf("网络错误: %s", message), cause)
 }
 
+// NewMalwareError 创建恶意软件错误
+func NewMalwareError(path string, detail string) *DelGuardError {
+	err := NewError(ErrTypeMalware, fmt.Sprintf("检测到恶意软件: %s (%s)", path, detail), nil)
+	err.Path = path
+	return err
+}
+
+// NewAlreadyInTrashError 创建文件已在回收站中错误
+func NewAlreadyInTrashError(path string) *DelGuardError {
+	err := NewError(ErrTypeAlreadyInTrash, fmt.Sprintf("文件已在回收站中: %s", path), nil)
+	err.Path = path
+	return err
+}
+
+// NewDiskFullError 创建磁盘空间不足错误
+func NewDiskFullError(path string) *DelGuardError {
+	err := NewError(ErrTypeDiskFull, fmt.Sprintf("磁盘空间不足: %s", path), nil)
+	err.Path = path
+	return err
+}
+
+// NewPartialMoveError 创建跨文件系统移动只部分完成的错误：回收站中已有完整副本，但源位置的项目未能全部删除
+func NewPartialMoveError(path string, trashPath string, cause error) *DelGuardError {
+	err := NewError(ErrTypeIO, fmt.Sprintf("已复制到回收站，但删除源位置时部分失败: %s", path), cause)
+	err.Path = path
+	err.Hint = fmt.Sprintf("完整副本保留在回收站中: %s，源位置剩余的项目可以手动删除", trashPath)
+	return err
+}
+
+// NewBlockedError 创建被保护规则阻止的错误
+func NewBlockedError(path string, rule string, reason string) *DelGuardError {
+	message := fmt.Sprintf("被保护规则 %s 阻止: %s", rule, path)
+	if reason != "" {
+		message = fmt.Sprintf("被保护规则 %s 阻止: %s (%s)", rule, path, reason)
+	}
+	err := NewError(ErrTypeBlocked, message, nil)
+	err.Path = path
+	return err
+}
+
+// NewTimeoutError 创建操作超时错误
+func NewTimeoutError(path string, timeout time.Duration) *DelGuardError {
+	err := NewError(ErrTypeTimeout, fmt.Sprintf("操作超时 (%v): %s", timeout, path), nil)
+	err.Path = path
+	return err
+}
+
+// NewTransientError 创建暂时性错误，WithRetryContext会重试此类错误
+func NewTransientError(message string, cause error) *DelGuardError {
+	return NewError(ErrTypeTransient, message, cause)
+}
+
+// NewValidationError 创建不满足删除策略的错误
+func NewValidationError(path string, reason string) *DelGuardError {
+	err := NewError(ErrTypeValidation, fmt.Sprintf("拒绝删除 %s: %s", path, reason), nil)
+	err.Path = path
+	return err
+}
+
+// NewSpecialFileError 创建特殊文件无法复制到回收站的错误
+func NewSpecialFileError(path string, kind string) *DelGuardError {
+	err := NewError(ErrTypeSpecialFile, fmt.Sprintf("%s无法跨文件系统移动到回收站: %s", kind, path), nil)
+	err.Path = path
+	return err
+}
+
+// errorKinds 错误类型的稳定名称，用于匿名统计
+var errorKinds = map[ErrorType]string{
+	ErrTypeUnknown:          "unknown",
+	ErrTypeFileNotFound:     "file_not_found",
+	ErrTypePermissionDenied: "permission_denied",
+	ErrTypeInvalidPath:      "invalid_path",
+	ErrTypeTrashFull:        "trash_full",
+	ErrTypeConfigError:      "config_error",
+	ErrTypeNetworkError:     "network_error",
+	ErrTypeMalware:          "malware",
+	ErrTypeAlreadyInTrash:   "already_in_trash",
+	ErrTypeDiskFull:         "disk_full",
+	ErrTypeBlocked:          "blocked",
+	ErrTypeTimeout:          "timeout",
+	ErrTypeTransient:        "transient",
+	ErrTypeValidation:       "validation",
+	ErrTypeIO:               "io",
+	ErrTypeSpecialFile:      "special_file",
+	ErrTypeUsage:            "usage",
+}
+
+// Kind 返回错误类型的稳定名称
+func (t ErrorType) Kind() string {
+	if kind, ok := errorKinds[t]; ok {
+		return kind
+	}
+	return "unknown"
+}
+
+// AllErrorTypes 按定义顺序返回所有错误类型
+func AllErrorTypes() []ErrorType {
+	types := make([]ErrorType, 0, len(errorKinds))
+	for errType := range errorKinds {
+		types = append(types, errType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// KindOf 返回错误链中第一个DelGuard错误的类型名称，不含DelGuard错误时返回unknown
+func KindOf(err error) string {
+	for err != nil {
+		if delErr, ok := err.(*DelGuardError); ok {
+			return delErr.Type.Kind()
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = unwrapper.Unwrap()
+	}
+	return "unknown"
+}
+
 // IsType 检查错误类型
 func IsType(err error, errType ErrorType) bool {
 	if delErr, ok := err.(*DelGuardError); ok {
@@ -100,10 +265,16 @@ func IsType(err error, errType ErrorType) bool {
 // GetErrorMessage 获取用户友好的错误消息
 func GetErrorMessage(err error) string {
 	if delErr, ok := err.(*DelGuardError); ok {
+		if delErr.Hint != "" {
+			return delErr.Hint
+		}
 		switch delErr.Type {
 		case ErrTypeFileNotFound:
 			return "指定的文件或目录不存在"
 		case ErrTypePermissionDenied:
+			if delErr.Component != "" {
+				return fmt.Sprintf("无法读取 %s (权限不足)，请检查该目录的权限或使用管理员权限运行", delErr.Component)
+			}
 			return "权限不足，请检查文件权限或使用管理员权限运行"
 		case ErrTypeInvalidPath:
 			return "路径格式无效"
@@ -113,6 +284,26 @@ func GetErrorMessage(err error) string {
 			return "配置文件错误，请检查配置"
 		case ErrTypeNetworkError:
 			return "网络连接失败，请检查网络设置"
+		case ErrTypeMalware:
+			return "文件被安全扫描标记为恶意软件，已拒绝操作"
+		case ErrTypeDiskFull:
+			return "磁盘空间不足，请释放空间后重试"
+		case ErrTypeAlreadyInTrash:
+			return "文件已在回收站中，如需彻底删除请使用永久删除"
+		case ErrTypeBlocked:
+			return "操作被保护规则插件阻止"
+		case ErrTypeTimeout:
+			return "操作超时，可能是网络挂载点无响应，可调整performance.timeout后重试"
+		case ErrTypeTransient:
+			return "系统暂时繁忙，请稍后重试"
+		case ErrTypeValidation:
+			return "不满足删除策略，已拒绝操作"
+		case ErrTypeIO:
+			return "读写文件失败，请检查磁盘状态后重试"
+		case ErrTypeSpecialFile:
+			return "命名管道、套接字和设备文件没有可以保存的内容，确认后只记录删除信息并直接删除"
+		case ErrTypeUsage:
+			return "命令用法错误，使用 --help 查看可用的参数和标志"
 		default:
 			return delErr.Message
 		}
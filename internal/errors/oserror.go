@@ -0,0 +1,53 @@
+package errors
+
+import (
+	stderrors "errors"
+	"os"
+)
+
+// FromOS 按系统调用错误的类型包装文件操作失败，使提示、汇总和退出码能区分磁盘已满、权限不足等情况
+// err已经是DelGuard错误时保留原类型；无法识别的错误按读写失败处理
+func FromOS(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var delErr *DelGuardError
+	if stderrors.As(err, &delErr) {
+		wrapped := NewError(delErr.Type, message, err)
+		wrapped.Component = delErr.Component
+		wrapped.Hint = delErr.Hint
+		wrapped.Path = delErr.Path
+		return wrapped
+	}
+
+	switch {
+	case matchesAny(err, diskFullErrnos):
+		return NewError(ErrTypeDiskFull, message, err)
+	case os.IsPermission(err):
+		return NewError(ErrTypePermissionDenied, message, err)
+	case os.IsNotExist(err):
+		return NewError(ErrTypeFileNotFound, message, err)
+	case IsCrossDevice(err):
+		wrapped := NewError(ErrTypeIO, message, err)
+		wrapped.Hint = "源文件与目标位于不同的设备，无法直接移动；请确认目标卷可写且空间充足，或在源文件所在的卷上使用回收站"
+		return wrapped
+	default:
+		return NewError(ErrTypeIO, message, err)
+	}
+}
+
+// IsCrossDevice 检查错误是否由跨设备移动（rename无法跨文件系统）引起
+func IsCrossDevice(err error) bool {
+	return matchesAny(err, crossDeviceErrnos)
+}
+
+// matchesAny 检查错误链中是否包含指定的系统错误码之一
+func matchesAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if stderrors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
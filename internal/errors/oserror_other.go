@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package errors
+
+// diskFullErrnos 其他平台不识别磁盘已满的错误码
+var diskFullErrnos []error
+
+// crossDeviceErrnos 其他平台不识别跨设备移动的错误码
+var crossDeviceErrnos []error
@@ -0,0 +1,91 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFromOSClassifiesSystemErrors(t *testing.T) {
+	pathErr := func(err error) error {
+		return &os.PathError{Op: "write", Path: "/data/file", Err: err}
+	}
+	tests := []struct {
+		name     string
+		err      error
+		want     ErrorType
+		wantExit int
+		wantHint string
+	}{
+		{"disk full", pathErr(diskFullErrnos[0]), ErrTypeDiskFull, ExitIOErr, ""},
+		{"permission", pathErr(os.ErrPermission), ErrTypePermissionDenied, ExitNoPerm, ""},
+		{"not found", pathErr(os.ErrNotExist), ErrTypeFileNotFound, ExitNoInput, ""},
+		{"cross device", &os.LinkError{Op: "rename", Old: "/a", New: "/b", Err: crossDeviceErrnos[0]}, ErrTypeIO, ExitIOErr, "不同的设备"},
+		{"wrapped", fmt.Errorf("copy: %w", pathErr(diskFullErrnos[0])), ErrTypeDiskFull, ExitIOErr, ""},
+		{"unknown", fmt.Errorf("boom"), ErrTypeIO, ExitIOErr, ""},
+		{"already classified", NewTimeoutError("/data/file", 0), ErrTypeTimeout, ExitTempFail, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromOS("移动到回收站失败", tt.err)
+			if !IsType(err, tt.want) {
+				t.Fatalf("FromOS(%v) kind = %s, want %s", tt.err, KindOf(err), tt.want.Kind())
+			}
+			if code := ExitCode(err); code != tt.wantExit {
+				t.Errorf("ExitCode = %d, want %d", code, tt.wantExit)
+			}
+			if !strings.Contains(GetErrorMessage(err), tt.wantHint) {
+				t.Errorf("advice = %q, want it to mention %q", GetErrorMessage(err), tt.wantHint)
+			}
+		})
+	}
+	if FromOS("无关", nil) != nil {
+		t.Error("FromOS(nil) != nil")
+	}
+}
+
+func TestFromOSKeepsPathAndHint(t *testing.T) {
+	inner := NewPermissionDeniedError("/data/locked")
+	inner.Hint = "请解锁"
+	err := FromOS("恢复失败", inner)
+	delErr, ok := err.(*DelGuardError)
+	if !ok || delErr.Path != inner.Path || delErr.Hint != inner.Hint {
+		t.Errorf("FromOS rewrapped %+v as %+v", inner, err)
+	}
+}
+
+func TestFormatErrorForDisplay(t *testing.T) {
+	plain := fmt.Errorf("plain failure")
+	if got := FormatErrorForDisplay(plain); got != "plain failure" {
+		t.Errorf("unclassified error = %q", got)
+	}
+	err := FromOS("移动到回收站失败", &os.PathError{Op: "write", Path: "/data/file", Err: diskFullErrnos[0]})
+	got := FormatErrorForDisplay(err)
+	lines := strings.Split(got, "\n")
+	if len(lines) != 2 || lines[0] != err.Error() || !strings.Contains(lines[1], "磁盘空间不足") {
+		t.Errorf("FormatErrorForDisplay = %q, want the error and disk full advice", got)
+	}
+}
+
+func TestCollectorKeepsCommonType(t *testing.T) {
+	denied := FromOS("删除失败", os.ErrPermission)
+	full := FromOS("删除失败", diskFullErrnos[0])
+
+	same := NewErrorCollector()
+	same.Add("/a", denied)
+	same.Add("/b", &os.PathError{Op: "open", Path: "/b", Err: os.ErrPermission})
+	if code := ExitCode(same.Err()); code != ExitNoPerm {
+		t.Errorf("collector with matching failures exits %d, want %d", code, ExitNoPerm)
+	}
+
+	mixed := NewErrorCollector()
+	mixed.Add("/a", denied)
+	mixed.Add("/b", full)
+	if code := ExitCode(mixed.Err()); code != ExitFailure {
+		t.Errorf("collector with mixed failures exits %d, want %d", code, ExitFailure)
+	}
+	if NewErrorCollector().Err() != nil {
+		t.Error("empty collector returned an error")
+	}
+}
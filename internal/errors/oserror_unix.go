@@ -0,0 +1,11 @@
+//go:build linux || darwin
+
+package errors
+
+import "syscall"
+
+// diskFullErrnos 表示磁盘空间或配额不足的系统错误码
+var diskFullErrnos = []error{syscall.ENOSPC, syscall.EDQUOT}
+
+// crossDeviceErrnos 表示rename跨越文件系统的系统错误码
+var crossDeviceErrnos = []error{syscall.EXDEV}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package errors
+
+import "syscall"
+
+const (
+	errorHandleDiskFull = syscall.Errno(39)  // ERROR_HANDLE_DISK_FULL
+	errorDiskFull       = syscall.Errno(112) // ERROR_DISK_FULL
+	errorNotSameDevice  = syscall.Errno(17)  // ERROR_NOT_SAME_DEVICE
+)
+
+// diskFullErrnos 表示磁盘空间不足的系统错误码
+var diskFullErrnos = []error{errorDiskFull, errorHandleDiskFull}
+
+// crossDeviceErrnos 表示MoveFile跨越卷的系统错误码
+var crossDeviceErrnos = []error{errorNotSameDevice}
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy 重试策略，每次失败后等待时间加倍，不超过MaxDelay
+type RetryPolicy struct {
+	Attempts int           // 最多尝试次数（包含第一次）
+	Delay    time.Duration // 第一次重试前的等待时间
+	MaxDelay time.Duration // 单次等待时间上限，0表示不限制
+}
+
+// WithRetryContext 执行fn，只有暂时性错误(ErrTypeTransient)才会按策略重试
+// 权限不足等其他错误立即返回；ctx取消时停止等待并返回最后一次的错误
+func WithRetryContext(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	attempts := policy.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := policy.Delay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !IsType(err, ErrTypeTransient) || attempt >= attempts {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
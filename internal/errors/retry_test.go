@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithRetryContext(t *testing.T) {
+	transient := NewTransientError("busy", nil)
+	denied := NewError(ErrTypePermissionDenied, "denied", nil)
+	policy := RetryPolicy{Attempts: 3, Delay: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		results   []error
+		wantErr   error
+		wantCalls int
+	}{
+		{"succeeds after two transient failures", []error{transient, transient, nil}, nil, 3},
+		{"permission errors are not retried", []error{denied, nil}, denied, 1},
+		{"stops after the last attempt", []error{transient, transient, transient, nil}, transient, 3},
+		{"first try succeeds", []error{nil}, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := WithRetryContext(context.Background(), policy, func() error {
+				calls++
+				return tt.results[calls-1]
+			})
+			if err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithRetryContextStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	started := time.Now()
+	err := WithRetryContext(ctx, RetryPolicy{Attempts: 5, Delay: time.Hour}, func() error {
+		calls++
+		cancel()
+		return NewTransientError("busy", nil)
+	})
+	if !IsType(err, ErrTypeTransient) || calls != 1 {
+		t.Errorf("WithRetryContext = %v after %d calls, want the transient error after 1", err, calls)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("waited %v after cancellation", elapsed)
+	}
+}
@@ -0,0 +1,44 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth 记录调用栈的最大层数
+const maxStackDepth = 32
+
+// callers 记录调用NewError的位置开始的调用栈
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// StackString 返回创建错误时的调用栈，每一层为函数名和下一行缩进的文件位置，未记录调用栈时返回空
+func (e *DelGuardError) StackString() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// StackOf 返回错误链中第一个DelGuardError的调用栈，用于调试输出
+func StackOf(err error) string {
+	var delErr *DelGuardError
+	if !stderrors.As(err, &delErr) {
+		return ""
+	}
+	return delErr.StackString()
+}
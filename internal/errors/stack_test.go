@@ -0,0 +1,30 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStackOf(t *testing.T) {
+	err := NewFileNotFoundError("/data/report.txt")
+	stack := err.StackString()
+	if !strings.Contains(stack, "TestStackOf") || !strings.Contains(stack, "stack_test.go") {
+		t.Errorf("stack does not lead back to the caller:\n%s", stack)
+	}
+	if strings.Contains(stack, "errors.callers") {
+		t.Errorf("stack includes the recording helper:\n%s", stack)
+	}
+
+	wrapped := fmt.Errorf("删除失败: %w", err)
+	if got := StackOf(wrapped); got != stack {
+		t.Errorf("StackOf(wrapped) = %q, want the inner error's stack", got)
+	}
+	if got := StackOf(io.EOF); got != "" {
+		t.Errorf("StackOf(io.EOF) = %q, want empty", got)
+	}
+	if got := (&DelGuardError{Type: ErrTypeUnknown}).StackString(); got != "" {
+		t.Errorf("StackString() without a recorded stack = %q, want empty", got)
+	}
+}
@@ -0,0 +1,507 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// 归档内的布局：manifest.json记录每个项目的元数据和哈希，files/<名称>为项目内容
+const (
+	archiveManifestName = "manifest.json"
+	archiveFilesDir     = "files"
+	archiveVersion      = 1
+)
+
+// ArchiveManifest 回收站归档清单
+type ArchiveManifest struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Items      []ArchiveItem `json:"items"`
+}
+
+// ArchiveItem 归档中的单个回收站项目
+type ArchiveItem struct {
+	Name     string        `json:"name"` // files目录下的名称
+	Hash     string        `json:"hash"` // 内容的SHA256，目录按相对路径和文件内容计算
+	Metadata TrashMetadata `json:"metadata"`
+}
+
+// archiveWriter 归档写入器，屏蔽tar.gz与zip的差异
+type archiveWriter interface {
+	add(name string, info os.FileInfo, src string) error
+	addBytes(name string, data []byte) error
+	Close() error
+}
+
+// IsZipArchive 根据扩展名判断归档格式，.zip以外的都按tar.gz处理
+func IsZipArchive(archivePath string) bool {
+	return strings.EqualFold(filepath.Ext(archivePath), ".zip")
+}
+
+// ExportTrash 将ids指定的回收站项目及其元数据打包到archivePath，回收站中的项目保持不变
+func ExportTrash(manager TrashManager, ids []string, archivePath string) error {
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return errors.FromOS("读取回收站失败", err)
+	}
+	byID := make(map[string]TrashFile, len(files))
+	for _, file := range files {
+		byID[file.ID] = file
+	}
+
+	selected := make([]TrashFile, 0, len(ids))
+	for _, id := range ids {
+		file, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("回收站中没有该项目: %s", id)
+		}
+		selected = append(selected, file)
+	}
+
+	// 先写入临时文件，完成后再改名，避免中途失败留下不完整的归档
+	tempPath := archivePath + ".tmp"
+	out, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.FromOS("创建归档失败", err)
+	}
+	defer os.Remove(tempPath)
+
+	var writer archiveWriter
+	if IsZipArchive(archivePath) {
+		writer = &zipArchiveWriter{zw: zip.NewWriter(out)}
+	} else {
+		gz := gzip.NewWriter(out)
+		writer = &tarArchiveWriter{gz: gz, tw: tar.NewWriter(gz)}
+	}
+
+	manifest := ArchiveManifest{Version: archiveVersion, ExportedAt: time.Now(), Items: []ArchiveItem{}}
+	for i, file := range selected {
+		name := fmt.Sprintf("%04d", i+1)
+		hash, err := hashTree(file.TrashPath)
+		if err != nil {
+			writer.Close()
+			out.Close()
+			return fmt.Errorf("计算哈希失败 %s: %v", file.Name, err)
+		}
+		if err := addTree(writer, path.Join(archiveFilesDir, name), file.TrashPath); err != nil {
+			writer.Close()
+			out.Close()
+			return fmt.Errorf("写入归档失败 %s: %v", file.Name, err)
+		}
+		manifest.Items = append(manifest.Items, ArchiveItem{
+			Name:     name,
+			Hash:     hash,
+			Metadata: LoadMetadata(manager, file),
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = writer.addBytes(archiveManifestName, data)
+	}
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.FromOS("写入归档失败", err)
+	}
+
+	if err := os.Rename(tempPath, archivePath); err != nil {
+		return errors.FromOS("保存归档失败", err)
+	}
+	return nil
+}
+
+// ImportTrash 将归档中的项目放入本地回收站，保留原始路径和删除时间
+// 哈希不匹配的项目不会导入，返回成功导入的项目数
+func ImportTrash(manager TrashManager, archivePath string) (int, error) {
+	staging, err := os.MkdirTemp("", "delguard-import-")
+	if err != nil {
+		return 0, errors.FromOS("创建临时目录失败", err)
+	}
+	defer removeAllWritable(staging)
+
+	if IsZipArchive(archivePath) {
+		err = extractZip(archivePath, staging)
+	} else {
+		err = extractTarGz(archivePath, staging)
+	}
+	if err != nil {
+		return 0, errors.FromOS("解压归档失败", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(staging, archiveManifestName))
+	if err != nil {
+		return 0, fmt.Errorf("归档中缺少清单: %v", err)
+	}
+	var manifest ArchiveManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return 0, fmt.Errorf("解析清单失败: %v", err)
+	}
+	if manifest.Version > archiveVersion {
+		return 0, fmt.Errorf("不支持的归档版本: %d", manifest.Version)
+	}
+
+	imported := 0
+	var failures []string
+	for _, item := range manifest.Items {
+		if item.Name == "" || item.Name != filepath.Base(item.Name) || item.Name == ".." {
+			failures = append(failures, fmt.Sprintf("非法的项目名称: %q", item.Name))
+			continue
+		}
+		metadata := item.Metadata
+		label := metadata.OriginalPath
+		if label == "" {
+			label = item.Name
+		}
+
+		staged := filepath.Join(staging, archiveFilesDir, item.Name)
+		hash, err := hashTree(staged)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: 读取内容失败: %v", label, err))
+			continue
+		}
+		if hash != item.Hash {
+			failures = append(failures, fmt.Sprintf("%s: 哈希校验失败", label))
+			continue
+		}
+
+		// 清单来自外部，文件名只取最后一段
+		if metadata.FileName == "" {
+			metadata.FileName = filepath.Base(metadata.OriginalPath)
+		} else {
+			metadata.FileName = filepath.Base(filepath.FromSlash(metadata.FileName))
+		}
+		if metadata.DeletedTime.IsZero() {
+			metadata.DeletedTime = time.Now()
+		}
+		if err := manager.ImportFile(staged, metadata); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+		imported++
+	}
+
+	if len(failures) > 0 {
+		return imported, fmt.Errorf("%d 个项目导入失败: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return imported, nil
+}
+
+// hashTree 计算文件或目录内容的SHA256，目录按相对路径顺序依次计入路径、链接目标和文件内容
+func hashTree(root string) (string, error) {
+	hasher := sha256.New()
+	err := filepath.Walk(root, func(current string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(current)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hasher, "L %s %s\n", rel, filepath.ToSlash(target))
+		case info.IsDir():
+			fmt.Fprintf(hasher, "D %s\n", rel)
+		case !info.Mode().IsRegular():
+			return fmt.Errorf("不支持导出特殊文件: %s", current)
+		default:
+			fmt.Fprintf(hasher, "F %s %d\n", rel, info.Size())
+			file, err := os.Open(current)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(hasher, file)
+			file.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// addTree 将文件或目录递归写入归档，name为归档内的路径
+func addTree(writer archiveWriter, name string, root string) error {
+	return filepath.Walk(root, func(current string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		return writer.add(path.Join(name, filepath.ToSlash(rel)), info, current)
+	})
+}
+
+// tarArchiveWriter tar.gz格式的归档写入器
+type tarArchiveWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w *tarArchiveWriter) add(name string, info os.FileInfo, src string) error {
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		link = target
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w.tw, file)
+	return err
+}
+
+func (w *tarArchiveWriter) addBytes(name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+func (w *tarArchiveWriter) Close() error {
+	err := w.tw.Close()
+	if gzErr := w.gz.Close(); err == nil {
+		err = gzErr
+	}
+	return err
+}
+
+// zipArchiveWriter zip格式的归档写入器，符号链接以链接目标作为内容保存
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) add(name string, info os.FileInfo, src string) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	switch {
+	case info.IsDir():
+		header.Name += "/"
+	case info.Mode().IsRegular():
+		header.Method = zip.Deflate
+	}
+	entry, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		_, err = entry.Write([]byte(target))
+		return err
+	case info.Mode().IsRegular():
+		file, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(entry, file)
+		return err
+	}
+	return nil
+}
+
+func (w *zipArchiveWriter) addBytes(name string, data []byte) error {
+	entry, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(data)
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
+
+// archiveTarget 将归档内的路径转换为解压目录中的路径
+// 拒绝绝对路径、越出解压目录的路径，以及经由已解压的符号链接写入的路径
+func archiveTarget(dest, name string) (string, error) {
+	cleaned := path.Clean(strings.TrimSuffix(name, "/"))
+	if cleaned == "." {
+		return dest, nil
+	}
+	if path.IsAbs(cleaned) || filepath.VolumeName(filepath.FromSlash(cleaned)) != "" {
+		return "", fmt.Errorf("归档中包含非法路径: %s", name)
+	}
+	target := filepath.Join(dest, filepath.FromSlash(cleaned))
+	rel, err := filepath.Rel(dest, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("归档中包含非法路径: %s", name)
+	}
+
+	parent := dest
+	components := strings.Split(rel, string(filepath.Separator))
+	for _, component := range components[:len(components)-1] {
+		parent = filepath.Join(parent, component)
+		if info, err := os.Lstat(parent); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("归档中包含非法路径: %s", name)
+		}
+	}
+	return target, nil
+}
+
+// extractTarGz 解压tar.gz归档到dest
+func extractTarGz(archivePath, dest string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveTarget(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeSymlink:
+			err = os.Symlink(header.Linkname, target)
+		case tar.TypeReg:
+			err = extractFile(tr, target, os.FileMode(header.Mode).Perm())
+		default:
+			err = fmt.Errorf("不支持的归档项目类型: %s", header.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip 解压zip归档到dest
+func extractZip(archivePath, dest string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.File {
+		target, err := archiveTarget(dest, entry.Name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		mode := entry.Mode()
+		if mode.IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		src, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		if mode&os.ModeSymlink != 0 {
+			var linkTarget []byte
+			linkTarget, err = io.ReadAll(src)
+			if err == nil {
+				err = os.Symlink(string(linkTarget), target)
+			}
+		} else {
+			err = extractFile(src, target, mode.Perm())
+		}
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractFile 将src的内容写入新文件target
+func extractFile(src io.Reader, target string, perm os.FileMode) error {
+	// 保证解压后可读写，原始权限由元数据在恢复时还原
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
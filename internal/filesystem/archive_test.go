@@ -0,0 +1,178 @@
+package filesystem
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		for _, format := range []string{"tar.gz", "zip"} {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				source := newManager(t)
+				file := writeContractFile(t, "notes.txt", "remember")
+				tree := filepath.Join(t.TempDir(), "project")
+				if err := os.MkdirAll(filepath.Join(tree, "src"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(tree, "src", "main.go"), []byte("package main\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				for _, path := range []string{file, tree} {
+					if err := source.MoveToTrash(path); err != nil {
+						t.Fatalf("MoveToTrash: %v", err)
+					}
+				}
+				trashed, err := source.ListTrashFiles()
+				if err != nil {
+					t.Fatal(err)
+				}
+				var ids []string
+				for _, item := range trashed {
+					ids = append(ids, item.ID)
+				}
+
+				archive := filepath.Join(t.TempDir(), "export."+format)
+				if err := ExportTrash(source, ids, archive); err != nil {
+					t.Fatalf("ExportTrash: %v", err)
+				}
+				if _, err := os.Stat(archive + ".tmp"); !os.IsNotExist(err) {
+					t.Errorf("temporary archive left behind: %v", err)
+				}
+				if after, _ := source.ListTrashFiles(); len(after) != len(trashed) {
+					t.Errorf("export changed the source trash: %d items, want %d", len(after), len(trashed))
+				}
+
+				target := newManager(t)
+				imported, err := ImportTrash(target, archive)
+				if err != nil || imported != 2 {
+					t.Fatalf("ImportTrash = %d, %v", imported, err)
+				}
+
+				items, err := target.ListTrashFiles()
+				if err != nil {
+					t.Fatal(err)
+				}
+				byPath := make(map[string]TrashFile)
+				for _, item := range items {
+					byPath[item.OriginalPath] = item
+				}
+				notes, ok := byPath[file]
+				if !ok || notes.Name != "notes.txt" {
+					t.Fatalf("imported items = %v, want %s with its original name", byPath, file)
+				}
+				if data, err := os.ReadFile(notes.TrashPath); err != nil || string(data) != "remember" {
+					t.Errorf("imported content = %q, %v", data, err)
+				}
+				project, ok := byPath[tree]
+				if !ok || !project.IsDirectory {
+					t.Fatalf("imported items = %v, want directory %s", byPath, tree)
+				}
+				if data, err := os.ReadFile(filepath.Join(project.TrashPath, "src", "main.go")); err != nil || string(data) != "package main\n" {
+					t.Errorf("imported tree content = %q, %v", data, err)
+				}
+			})
+		}
+	}
+}
+
+func TestExportUnknownID(t *testing.T) {
+	manager, err := NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "export.tar.gz")
+	if err := ExportTrash(manager, []string{"missing"}, archive); err == nil {
+		t.Fatal("ExportTrash accepted an unknown id")
+	}
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Errorf("archive was created: %v", err)
+	}
+}
+
+// writeZip 写入包含给定条目的zip归档
+func writeZip(t *testing.T, entries map[string]string) string {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), "crafted.zip")
+	out, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(out)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+	return archive
+}
+
+func TestImportRejectsTamperedItems(t *testing.T) {
+	manager, err := NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	good, err := hashTree(writeContractFile(t, "good", "original"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := ArchiveManifest{Version: archiveVersion, Items: []ArchiveItem{
+		{Name: "0001", Hash: good, Metadata: TrashMetadata{OriginalPath: filepath.Join(t.TempDir(), "good.txt")}},
+		{Name: "0002", Hash: good, Metadata: TrashMetadata{OriginalPath: filepath.Join(t.TempDir(), "tampered.txt")}},
+		{Name: "..", Hash: good},
+	}}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := writeZip(t, map[string]string{
+		archiveManifestName: string(data),
+		"files/0001":        "original",
+		"files/0002":        "modified",
+	})
+
+	imported, err := ImportTrash(manager, archive)
+	if imported != 1 || err == nil || !strings.Contains(err.Error(), "哈希校验失败") {
+		t.Fatalf("ImportTrash = %d, %v; want only the intact item imported", imported, err)
+	}
+	items, _ := manager.ListTrashFiles()
+	if len(items) != 1 || items[0].Name != "good.txt" {
+		t.Errorf("trash after import = %v", items)
+	}
+}
+
+func TestImportRejectsEscapingPaths(t *testing.T) {
+	manager, err := NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"../escape", "files/../../escape", "/abs/escape"} {
+		archive := writeZip(t, map[string]string{name: "x", archiveManifestName: `{"version":1,"items":[]}`})
+		if _, err := ImportTrash(manager, archive); err == nil {
+			t.Errorf("ImportTrash accepted an entry named %q", name)
+		}
+	}
+}
+
+func TestArchiveTargetRejectsSymlinkedParents(t *testing.T) {
+	dest := t.TempDir()
+	if err := os.Symlink(t.TempDir(), filepath.Join(dest, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if _, err := archiveTarget(dest, "link/file"); err == nil {
+		t.Error("archiveTarget allowed writing through an extracted symlink")
+	}
+	if got, err := archiveTarget(dest, "files/0001/"); err != nil || got != filepath.Join(dest, "files", "0001") {
+		t.Errorf("archiveTarget = %q, %v", got, err)
+	}
+}
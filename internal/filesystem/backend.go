@@ -0,0 +1,104 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// BackendEnvVar 指定回收站后端的环境变量，未设置时按操作系统选择
+// 以 -tags delguard_memory 构建的测试版本可设为memory，删除的文件只进入本次调用的临时回收站，
+// 不会触碰真实回收站；正式版本不包含memory后端，避免误设后删除的文件无法从回收站找回
+const BackendEnvVar = "DELGUARD_TRASH_BACKEND"
+
+// MemoryBackend 元数据只保存在内存中的测试后端名称，只有以 -tags delguard_memory 构建时可用
+const MemoryBackend = "memory"
+
+// BackendOptions 创建回收站管理器时由配置和命令行决定的参数
+type BackendOptions struct {
+	UseSystemTrash bool   // 使用系统回收站，为false时使用TrashRoot下的DelGuard专用回收站
+	PreserveXattrs bool   // 保存并恢复扩展属性
+	HashOnDelete   bool   // 移动到回收站时没有经过复制的文件也计算内容哈希
+	NetworkTrash   string // 网络文件系统上的项目使用的回收站: NetworkTrashShare或NetworkTrashLocal
+	NetworkHash    bool   // 网络文件系统上的项目也按HashOnDelete计算内容哈希
+	TrashDir       string // --trash-dir指定的回收站目录，为空时使用配置决定的位置
+	TrashRoot      string // DelGuard专用回收站根目录，使用系统回收站时为空
+}
+
+// BackendFactory 按参数创建回收站管理器
+type BackendFactory func(opts BackendOptions) (TrashManager, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{
+		"windows": newWindowsBackend,
+		"darwin":  newDarwinBackend,
+		"linux":   newLinuxBackend,
+	}
+)
+
+// RegisterBackend 注册回收站后端，已存在同名后端时替换
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = factory
+}
+
+// BackendNames 返回已注册的后端名称
+func BackendNames() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupBackend 查找已注册的后端
+func lookupBackend(name string) (BackendFactory, bool) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	factory, ok := backends[name]
+	return factory, ok
+}
+
+// newWindowsBackend 创建Windows回收站管理器
+func newWindowsBackend(opts BackendOptions) (TrashManager, error) {
+	manager := NewWindowsTrashManager()
+	manager.useSystemTrash = opts.UseSystemTrash
+	manager.preserveXattrs = opts.PreserveXattrs
+	manager.hashOnDelete = opts.HashOnDelete
+	manager.networkHash = opts.NetworkHash
+	manager.trashDir = opts.TrashDir
+	manager.networkTrash = opts.NetworkTrash
+	if opts.TrashDir != "" {
+		manager.networkTrash = NetworkTrashLocal
+	}
+	return manager, nil
+}
+
+// newDarwinBackend 创建macOS回收站管理器
+func newDarwinBackend(opts BackendOptions) (TrashManager, error) {
+	manager := NewDarwinTrashManager()
+	manager.preserveXattrs = opts.PreserveXattrs
+	if !opts.UseSystemTrash {
+		manager.trashPath = opts.TrashRoot
+	}
+	return manager, nil
+}
+
+// newLinuxBackend 创建Linux回收站管理器
+func newLinuxBackend(opts BackendOptions) (TrashManager, error) {
+	manager := NewLinuxTrashManager()
+	manager.preserveXattrs = opts.PreserveXattrs
+	manager.networkTrash = opts.NetworkTrash
+	if !opts.UseSystemTrash {
+		// 专用回收站同样采用XDG的files/info目录结构，共享上的.Trash-<uid>属于系统回收站，不再使用
+		manager.trashPath = filepath.Join(opts.TrashRoot, "files")
+		manager.infoPath = filepath.Join(opts.TrashRoot, "info")
+		manager.networkTrash = NetworkTrashLocal
+	}
+	return manager, nil
+}
@@ -0,0 +1,28 @@
+//go:build delguard_memory
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+)
+
+// memory后端只在以 -tags delguard_memory 构建时注册，供CI和测试使用
+func init() {
+	RegisterBackend(MemoryBackend, newMemoryBackend)
+}
+
+// newMemoryBackend 创建元数据只保存在内存中的回收站，每次调用都从空回收站开始
+// 文件移动到系统临时目录（指定--trash-dir时为该目录）下新建的目录中，调用结束后保留以便检查
+func newMemoryBackend(opts BackendOptions) (TrashManager, error) {
+	if opts.TrashDir != "" {
+		if err := os.MkdirAll(opts.TrashDir, 0755); err != nil {
+			return nil, fmt.Errorf("创建回收站目录失败: %v", err)
+		}
+	}
+	root, err := os.MkdirTemp(opts.TrashDir, "delguard-memory-trash-")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时回收站失败: %v", err)
+	}
+	return NewFakeTrashManager(root)
+}
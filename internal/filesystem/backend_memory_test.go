@@ -0,0 +1,36 @@
+//go:build delguard_memory
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryBackendSelectedByEnv(t *testing.T) {
+	t.Setenv(BackendEnvVar, MemoryBackend)
+	trashDir := t.TempDir()
+	manager, err := NewTrashManagerAt(nil, trashDir)
+	if err != nil {
+		t.Fatalf("NewTrashManagerAt: %v", err)
+	}
+	if _, ok := manager.(*FakeTrashManager); !ok {
+		t.Fatalf("%s=%s selected %T", BackendEnvVar, MemoryBackend, manager)
+	}
+
+	path := filepath.Join(t.TempDir(), "ci.txt")
+	if err := os.WriteFile(path, []byte("ci"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	trashPath, err := manager.GetTrashPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(filepath.Dir(trashPath)) != trashDir && filepath.Dir(trashPath) != trashDir {
+		t.Errorf("memory trash %s is not under --trash-dir %s", trashPath, trashDir)
+	}
+}
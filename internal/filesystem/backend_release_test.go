@@ -0,0 +1,18 @@
+//go:build !delguard_memory
+
+package filesystem
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMemoryBackendIsNotInReleaseBuilds(t *testing.T) {
+	if _, ok := lookupBackend(MemoryBackend); ok {
+		t.Fatal("the memory backend must only be registered with -tags delguard_memory")
+	}
+	t.Setenv(BackendEnvVar, MemoryBackend)
+	if _, err := NewTrashManager(nil); err == nil || !strings.Contains(err.Error(), "delguard_memory") {
+		t.Errorf("NewTrashManager with %s=%s: %v", BackendEnvVar, MemoryBackend, err)
+	}
+}
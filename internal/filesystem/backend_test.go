@@ -0,0 +1,159 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// contractBackends 返回需要满足同一套行为约定的回收站实现：内存中的FakeTrashManager，
+// 以及当前操作系统的后端（使用临时目录下的专用回收站，不触碰真实回收站）
+func contractBackends(t *testing.T) map[string]func(t *testing.T) TrashManager {
+	return map[string]func(t *testing.T) TrashManager{
+		"fake": func(t *testing.T) TrashManager {
+			manager, err := NewFakeTrashManager(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return manager
+		},
+		runtime.GOOS: func(t *testing.T) TrashManager {
+			factory, ok := lookupBackend(runtime.GOOS)
+			if !ok {
+				t.Skipf("no backend for %s", runtime.GOOS)
+			}
+			root := t.TempDir()
+			manager, err := factory(BackendOptions{TrashDir: root, TrashRoot: root})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return manager
+		},
+	}
+}
+
+// writeContractFile 在临时目录中创建内容为content的文件
+func writeContractFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// onlyTrashFile 返回回收站中唯一的项目
+func onlyTrashFile(t *testing.T, manager TrashManager) TrashFile {
+	t.Helper()
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		t.Fatalf("ListTrashFiles: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("ListTrashFiles returned %d items, want 1", len(files))
+	}
+	return files[0]
+}
+
+func TestBackendContract(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Run("move lists the original path", func(t *testing.T) {
+				manager := newManager(t)
+				path := writeContractFile(t, "report.txt", "hello")
+				if err := manager.MoveToTrash(path); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+				if _, err := os.Lstat(path); !os.IsNotExist(err) {
+					t.Errorf("source still exists after MoveToTrash: %v", err)
+				}
+				file := onlyTrashFile(t, manager)
+				if file.OriginalPath != path || file.Name != "report.txt" || file.Size != 5 || file.IsDirectory {
+					t.Errorf("listed %+v", file)
+				}
+				if time.Since(file.DeletedTime) > time.Minute {
+					t.Errorf("DeletedTime = %v", file.DeletedTime)
+				}
+				if manager.IsEmpty() {
+					t.Error("IsEmpty() = true after MoveToTrash")
+				}
+			})
+
+			t.Run("restore puts the content back", func(t *testing.T) {
+				manager := newManager(t)
+				path := writeContractFile(t, "notes.txt", "keep me")
+				if err := manager.MoveToTrash(path); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+				if err := manager.RestoreFile(onlyTrashFile(t, manager), path); err != nil {
+					t.Fatalf("RestoreFile: %v", err)
+				}
+				data, err := os.ReadFile(path)
+				if err != nil || string(data) != "keep me" {
+					t.Errorf("restored content = %q, %v", data, err)
+				}
+				if files, _ := manager.ListTrashFiles(); len(files) != 0 {
+					t.Errorf("%d items left in trash after restore", len(files))
+				}
+			})
+
+			t.Run("directories keep their tree", func(t *testing.T) {
+				manager := newManager(t)
+				dir := filepath.Join(t.TempDir(), "project")
+				if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := manager.MoveToTrash(dir); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+				file := onlyTrashFile(t, manager)
+				if !file.IsDirectory {
+					t.Error("IsDirectory = false for a directory")
+				}
+				if err := manager.RestoreFile(file, dir); err != nil {
+					t.Fatalf("RestoreFile: %v", err)
+				}
+				if data, err := os.ReadFile(filepath.Join(dir, "src", "main.go")); err != nil || string(data) != "package main" {
+					t.Errorf("restored tree content = %q, %v", data, err)
+				}
+			})
+
+			t.Run("stats and empty", func(t *testing.T) {
+				manager := newManager(t)
+				for _, name := range []string{"a.txt", "b.txt"} {
+					if err := manager.MoveToTrash(writeContractFile(t, name, "1234")); err != nil {
+						t.Fatalf("MoveToTrash: %v", err)
+					}
+				}
+				stats, err := manager.GetTrashStats()
+				if err != nil {
+					t.Fatalf("GetTrashStats: %v", err)
+				}
+				if stats.TotalFiles != 2 || stats.TotalSize != 8 {
+					t.Errorf("stats = %+v, want 2 files of 8 bytes", stats)
+				}
+				if err := manager.EmptyTrash(); err != nil {
+					t.Fatalf("EmptyTrash: %v", err)
+				}
+				if !manager.IsEmpty() {
+					t.Error("IsEmpty() = false after EmptyTrash")
+				}
+			})
+
+			t.Run("validate a healthy trash", func(t *testing.T) {
+				manager := newManager(t)
+				if err := manager.MoveToTrash(writeContractFile(t, "c.txt", "x")); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+				if err := manager.ValidateTrash(); err != nil {
+					t.Errorf("ValidateTrash: %v", err)
+				}
+			})
+		})
+	}
+}
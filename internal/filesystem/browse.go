@@ -0,0 +1,15 @@
+package filesystem
+
+// windowsRecycleBinFolder explorer中表示系统回收站的外壳位置
+const windowsRecycleBinFolder = "shell:RecycleBinFolder"
+
+// BrowseLocation 返回在文件管理器中查看回收站时应打开的位置
+// Windows上使用系统回收站时为回收站外壳文件夹，否则为GetTrashPath返回的目录，
+// 第二个返回值表示该位置是否为文件系统中的目录
+func BrowseLocation(manager TrashManager) (string, bool, error) {
+	if windows, ok := manager.(*WindowsTrashManager); ok && windows.useSystemTrash && windows.CanUseSystemRecycleBin() {
+		return windowsRecycleBinFolder, false, nil
+	}
+	path, err := manager.GetTrashPath()
+	return path, true, err
+}
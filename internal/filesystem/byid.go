@@ -0,0 +1,106 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"delguard/internal/errors"
+	"delguard/internal/utils"
+)
+
+// String 以 "卷:索引" 的形式返回文件标识，Unix上为 "设备号:inode"
+func (id fileID) String() string {
+	return fmt.Sprintf("%d:%d", id.volume, id.index)
+}
+
+// FileIDString 返回路径对应文件的标识，可作为 rm --by-id 的参数，无法获取时返回false
+func FileIDString(path string) (string, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", false
+	}
+	id, _, ok := fileIdentity(path, info)
+	if !ok {
+		return "", false
+	}
+	return id.String(), true
+}
+
+// parseFileID 解析 "卷:索引" 或只有索引的文件标识，只有索引时hasVolume为false
+// 数字支持0x前缀的十六进制，便于直接使用 fsutil file queryfileid 的输出
+func parseFileID(value string) (id fileID, hasVolume bool, err error) {
+	volume, index, hasVolume := strings.Cut(strings.TrimSpace(value), ":")
+	if !hasVolume {
+		index = volume
+	}
+	if id.index, err = strconv.ParseUint(index, 0, 64); err != nil {
+		return fileID{}, false, fmt.Errorf("无效的文件ID %q", value)
+	}
+	if hasVolume {
+		if id.volume, err = strconv.ParseUint(volume, 0, 64); err != nil {
+			return fileID{}, false, fmt.Errorf("无效的文件ID %q", value)
+		}
+	}
+	return id, hasVolume, nil
+}
+
+// FindByID 在目录中按文件标识查找条目，用于删除无法在命令行中输入的文件名
+// id为 "设备号:inode"（Windows上为 "卷序列号:文件索引"）或只有inode/文件索引
+// 只查找目录的直接子项，不跟随符号链接；只给出索引且匹配多个条目时返回错误
+func FindByID(dir string, id string) (string, error) {
+	wanted, hasVolume, err := parseFileID(id)
+	if err != nil {
+		return "", errors.NewError(errors.ErrTypeInvalidPath, err.Error(), nil)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", errors.FromOS(fmt.Sprintf("读取目录失败: %s", dir), err)
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue
+		}
+		found, _, ok := fileIdentity(path, info)
+		if !ok {
+			return "", errors.NewError(errors.ErrTypeUnknown,
+				fmt.Sprintf("当前平台不支持按文件ID查找: %s", runtime.GOOS), nil)
+		}
+		if found.index == wanted.index && (!hasVolume || found.volume == wanted.volume) {
+			matches = append(matches, path)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		err := errors.NewError(errors.ErrTypeFileNotFound,
+			fmt.Sprintf("目录 %s 中没有ID为 %s 的文件", dir, id), nil)
+		err.Hint = fileIDHint()
+		return "", err
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, match := range matches {
+			names[i] = utils.SanitizeName(filepath.Base(match))
+		}
+		return "", errors.NewError(errors.ErrTypeInvalidPath,
+			fmt.Sprintf("ID %s 对应多个文件: %s，请使用 \"设备号:inode\" 形式", id, strings.Join(names, ", ")), nil)
+	}
+}
+
+// fileIDHint 返回查看文件ID的方法
+func fileIDHint() string {
+	if runtime.GOOS == "windows" {
+		return "使用 fsutil file queryfileid <文件> 查看文件ID"
+	}
+	return "使用 ls -i 或 stat -c '%d:%i' 查看inode"
+}
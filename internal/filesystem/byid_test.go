@@ -0,0 +1,74 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"delguard/internal/errors"
+)
+
+func TestParseFileID(t *testing.T) {
+	tests := []struct {
+		value     string
+		want      fileID
+		hasVolume bool
+		wantErr   bool
+	}{
+		{value: "2049:131075", want: fileID{volume: 2049, index: 131075}, hasVolume: true},
+		{value: " 131075 ", want: fileID{index: 131075}},
+		{value: "0x1a2b:0x00000000000f3c2a", want: fileID{volume: 0x1a2b, index: 0xf3c2a}, hasVolume: true},
+		{value: "dev:131075", wantErr: true},
+		{value: "2049:", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		id, hasVolume, err := parseFileID(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseFileID(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (id != tt.want || hasVolume != tt.hasVolume) {
+			t.Errorf("parseFileID(%q) = %+v, %v; want %+v, %v", tt.value, id, hasVolume, tt.want, tt.hasVolume)
+		}
+	}
+}
+
+func TestFindByID(t *testing.T) {
+	dir := t.TempDir()
+	target := writeContractFile(t, "target.txt", "x")
+	other := filepath.Join(dir, "other.txt")
+	wanted := filepath.Join(dir, "wanted.txt")
+	for _, path := range []string{other, wanted} {
+		if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, ok := FileIDString(wanted)
+	if !ok {
+		t.Skip("file IDs unavailable on this platform")
+	}
+	full, _, _ := parseFileID(id)
+
+	for _, value := range []string{id, strconv.FormatUint(full.index, 10), "0x" + strconv.FormatUint(full.index, 16)} {
+		got, err := FindByID(dir, value)
+		if err != nil || got != wanted {
+			t.Errorf("FindByID(%q) = %q, %v; want %q", value, got, err, wanted)
+		}
+	}
+
+	// 其他目录中的文件即使存在也不会匹配
+	targetID, _ := FileIDString(target)
+	_, err := FindByID(dir, targetID)
+	if !errors.IsType(err, errors.ErrTypeFileNotFound) {
+		t.Errorf("FindByID of a file in another directory = %v, want file not found", err)
+	}
+	wrongVolume := fileID{volume: full.volume + 1, index: full.index}
+	if _, err := FindByID(dir, wrongVolume.String()); !errors.IsType(err, errors.ErrTypeFileNotFound) {
+		t.Errorf("FindByID with another volume = %v, want file not found", err)
+	}
+	if _, err := FindByID(dir, "not-an-id"); !errors.IsType(err, errors.ErrTypeInvalidPath) {
+		t.Errorf("FindByID with an invalid id = %v, want invalid path", err)
+	}
+}
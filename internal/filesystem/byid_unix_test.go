@@ -0,0 +1,58 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"delguard/internal/utils"
+)
+
+func TestTrashFileWithUnprintableNameByID(t *testing.T) {
+	const name = "odd\nname\x01\x1b[0m.txt"
+	for backend, newManager := range contractBackends(t) {
+		t.Run(backend, func(t *testing.T) {
+			manager := newManager(t)
+			dir := t.TempDir()
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte("payload"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "neighbour.txt"), []byte("keep"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			id, ok := FileIDString(path)
+			if !ok {
+				t.Skip("file IDs unavailable")
+			}
+
+			found, err := FindByID(dir, id)
+			if err != nil || found != path {
+				t.Fatalf("FindByID(%s) = %q, %v; want %q", id, found, err, path)
+			}
+			if err := manager.MoveToTrash(found); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+			if _, err := os.Lstat(filepath.Join(dir, "neighbour.txt")); err != nil {
+				t.Errorf("neighbouring file was touched: %v", err)
+			}
+
+			item := onlyTrashFile(t, manager)
+			if item.OriginalPath != path || item.Name != name {
+				t.Errorf("listed %q (%q), want the original name back", item.OriginalPath, item.Name)
+			}
+			if got := LoadMetadata(manager, item).DisplayName; got != utils.SanitizeName(name) {
+				t.Errorf("DisplayName = %q, want %q", got, utils.SanitizeName(name))
+			}
+
+			if err := manager.RestoreFile(item, ""); err != nil {
+				t.Fatalf("RestoreFile: %v", err)
+			}
+			if data, err := os.ReadFile(path); err != nil || string(data) != "payload" {
+				t.Errorf("restored %q, %v; want the original content at the original name", data, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"delguard/internal/utils"
+)
+
+// stubCaseFolding 在测试期间模拟目标文件系统是否区分大小写
+func stubCaseFolding(t *testing.T, insensitive bool) {
+	t.Helper()
+	saved := caseInsensitiveFS
+	caseInsensitiveFS = func(string) bool { return insensitive }
+	t.Cleanup(func() { caseInsensitiveFS = saved })
+}
+
+// caseFoldDir 创建包含readme.txt的临时目录
+func caseFoldDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "readme.txt"), []byte("existing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestExistingEntryCaseInsensitive(t *testing.T) {
+	stubCaseFolding(t, true)
+	dir := caseFoldDir(t)
+
+	tests := []struct {
+		name       string
+		want       string
+		wantExists bool
+	}{
+		{"readme.txt", "readme.txt", true},
+		{"ReadMe.txt", "readme.txt", true},
+		{"README.TXT", "readme.txt", true},
+		{"readme.md", "readme.md", false},
+	}
+	for _, tt := range tests {
+		got, exists := ExistingEntry(filepath.Join(dir, tt.name))
+		if exists != tt.wantExists || got != filepath.Join(dir, tt.want) {
+			t.Errorf("ExistingEntry(%s) = %s, %v; want %s, %v", tt.name, got, exists, tt.want, tt.wantExists)
+		}
+	}
+}
+
+func TestExistingEntryCaseSensitive(t *testing.T) {
+	dir := caseFoldDir(t)
+	if utils.CaseInsensitive(dir) {
+		t.Skip("the temporary directory is on a case-insensitive filesystem")
+	}
+	stubCaseFolding(t, false)
+
+	if got, exists := ExistingEntry(filepath.Join(dir, "ReadMe.txt")); exists {
+		t.Errorf("ExistingEntry(ReadMe.txt) = %s, true; want no conflict on a case-sensitive filesystem", got)
+	}
+	if _, exists := ExistingEntry(filepath.Join(dir, "readme.txt")); !exists {
+		t.Error("ExistingEntry(readme.txt) = false for an existing file")
+	}
+}
+
+func TestConflictFreePathSkipsCaseOnlyMatches(t *testing.T) {
+	stubCaseFolding(t, true)
+	dir := caseFoldDir(t)
+	first := ConflictFreePath(filepath.Join(dir, "ReadMe.txt"), nil)
+	if err := os.WriteFile(filepath.Join(dir, strings.ToLower(filepath.Base(first))), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 只有大小写不同的已存在文件也占用该名称
+	second := ConflictFreePath(filepath.Join(dir, "ReadMe.txt"), nil)
+	if strings.EqualFold(first, second) {
+		t.Errorf("ConflictFreePath() = %s again although %s exists in lower case", second, strings.ToLower(filepath.Base(first)))
+	}
+}
+
+func TestFakeRestoreReportsCaseOnlyConflict(t *testing.T) {
+	stubCaseFolding(t, true)
+	dir := caseFoldDir(t)
+	manager, err := NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeContractFile(t, "ReadMe.txt", "incoming")
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+
+	err = manager.RestoreFile(onlyTrashFile(t, manager), filepath.Join(dir, "ReadMe.txt"))
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "readme.txt")) {
+		t.Errorf("RestoreFile() = %v, want a conflict naming the existing readme.txt", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "readme.txt")); string(data) != "existing" {
+		t.Errorf("existing file was overwritten with %q", data)
+	}
+}
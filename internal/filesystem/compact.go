@@ -0,0 +1,302 @@
+package filesystem
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// compactMinSize 小于此大小的文件压缩后节省的空间不足以抵消gzip头部，不压缩
+const compactMinSize = 4 * 1024
+
+// compactTempSuffix 压缩过程中的临时文件后缀，临时文件以点开头，不会出现在回收站列表中
+const compactTempSuffix = ".dgz.tmp"
+
+// gzipMagic gzip数据的前两个字节
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// CompactOptions 回收站压缩选项
+type CompactOptions struct {
+	// OlderThan 删除时间早于此时长的项目才会被压缩
+	OlderThan time.Duration
+	// Level gzip压缩级别(1-9)
+	Level int
+	// DryRun 只统计可以压缩的项目，不实际压缩
+	DryRun bool
+}
+
+// CompactReport 回收站压缩结果
+type CompactReport struct {
+	Compacted   int      `json:"compacted"`             // 本次压缩的项目数
+	Skipped     int      `json:"skipped"`               // 已压缩、压缩后没有变小或不支持压缩的项目数
+	BytesBefore int64    `json:"bytes_before"`          // 被压缩项目压缩前的大小
+	BytesAfter  int64    `json:"bytes_after"`           // 被压缩项目压缩后的大小，预览时为0
+	StaleTemps  int      `json:"stale_temps,omitempty"` // 清理的之前中断的压缩留下的临时文件数
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// CompactTrash 用gzip压缩回收站中删除时间早于OlderThan的文件，压缩后的内容仍使用原来的名称，
+// 元数据中记录compressed，Size保持为压缩前的大小，恢复和校验时透明解压
+// 目录、符号链接、特殊文件的占位项目、已经是gzip格式的文件以及压缩后没有变小的文件保持不变
+func CompactTrash(manager TrashManager, opts CompactOptions) (CompactReport, error) {
+	report := CompactReport{}
+
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return report, err
+	}
+	if !opts.DryRun {
+		report.StaleTemps = removeStaleCompactTemps(manager, files)
+	}
+
+	cutoff := time.Now().Add(-opts.OlderThan)
+	throttle := MaintenanceThrottle()
+	for _, file := range files {
+		if file.IsDirectory || file.DeletedTime.IsZero() || !file.DeletedTime.Before(cutoff) {
+			continue
+		}
+		metadata := LoadMetadata(manager, file)
+		if file.Compressed || metadata.Compressed || metadata.LinkTarget != "" || metadata.SpecialType != "" {
+			report.Skipped++
+			continue
+		}
+		info, err := os.Lstat(file.TrashPath)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", file.Name, err))
+			continue
+		}
+		if !info.Mode().IsRegular() || info.Size() < compactMinSize || isGzipFile(file.TrashPath) {
+			report.Skipped++
+			continue
+		}
+
+		if opts.DryRun {
+			report.Compacted++
+			report.BytesBefore += info.Size()
+			continue
+		}
+
+		compressedSize, err := compactFile(manager, file, metadata, opts.Level)
+		throttle.Pause()
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", file.Name, err))
+			continue
+		}
+		if compressedSize < 0 {
+			report.Skipped++
+			continue
+		}
+		report.Compacted++
+		report.BytesBefore += info.Size()
+		report.BytesAfter += compressedSize
+	}
+	return report, nil
+}
+
+// compactFile 压缩单个回收站文件，返回压缩后的大小，压缩后没有变小时返回-1且不修改文件
+// 为保证中途崩溃不会丢失内容：先压缩到同目录的临时文件并fsync，再在元数据中记录compressed，
+// 最后用rename替换原文件。元数据已记录但替换未完成时，内容仍是未压缩的原文件，
+// 读取时按gzip头部判断，因此不会被误当作压缩数据解压
+func compactFile(manager TrashManager, file TrashFile, metadata TrashMetadata, level int) (int64, error) {
+	tempPath := filepath.Join(filepath.Dir(file.TrashPath), "."+filepath.Base(file.TrashPath)+compactTempSuffix)
+	size, hash, err := gzipToTemp(file.TrashPath, tempPath, level)
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, err
+	}
+	info, err := os.Lstat(file.TrashPath)
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, err
+	}
+	if size >= info.Size() {
+		os.Remove(tempPath)
+		return -1, nil
+	}
+
+	// 删除时没有记录哈希的文件顺便记录压缩前内容的哈希，之后的校验与之比对
+	if metadata.Hash == "" {
+		metadata.Hash = hash
+	}
+	if metadata.Size == 0 {
+		metadata.Size = info.Size()
+	}
+	metadata.Compressed = true
+	if err := saveMetadata(manager, file, metadata); err != nil {
+		os.Remove(tempPath)
+		return 0, err
+	}
+	if err := os.Rename(tempPath, file.TrashPath); err != nil {
+		os.Remove(tempPath)
+		return 0, err
+	}
+	return size, nil
+}
+
+// isCompactTemp 判断回收站目录中的文件是否为压缩或解压过程中的临时文件
+func isCompactTemp(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, compactTempSuffix)
+}
+
+// removeStaleCompactTemps 删除回收站各目录中中断的压缩留下的临时文件，返回删除的数量
+// 压缩完成前原文件保持不变，临时文件总是可以丢弃；比inFlightGrace更新的临时文件可能属于正在进行的压缩，保留
+func removeStaleCompactTemps(manager TrashManager, files []TrashFile) int {
+	dirs := make(map[string]bool)
+	if trashPath, err := manager.GetTrashPath(); err == nil {
+		dirs[trashPath] = true
+	}
+	for _, file := range files {
+		dirs[filepath.Dir(file.TrashPath)] = true
+	}
+
+	removed := 0
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !isCompactTemp(entry.Name()) {
+				continue
+			}
+			if info, err := entry.Info(); err != nil || time.Since(info.ModTime()) < inFlightGrace {
+				continue
+			}
+			if os.Remove(filepath.Join(dir, entry.Name())) == nil {
+				removed++
+			}
+		}
+	}
+	return removed
+}
+
+// gzipToTemp 将src压缩写入tempPath并fsync，返回压缩后的大小和压缩前内容的SHA256
+func gzipToTemp(src, tempPath string, level int) (int64, string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, "", err
+	}
+	gz, err := gzip.NewWriterLevel(out, level)
+	if err != nil {
+		out.Close()
+		return 0, "", err
+	}
+
+	hasher := sha256.New()
+	_, err = io.Copy(gz, io.TeeReader(MaintenanceThrottle().Reader(in), hasher))
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	info, err := os.Stat(tempPath)
+	if err != nil {
+		return 0, "", err
+	}
+	return info.Size(), hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// isGzipFile 检查文件是否以gzip头部开始
+func isGzipFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, len(gzipMagic))
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return header[0] == gzipMagic[0] && header[1] == gzipMagic[1]
+}
+
+// openPayload 打开回收站项目的内容，元数据记录为已压缩且内容确实是gzip格式时返回解压后的数据流
+func openPayload(path string, metadata *TrashMetadata) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil || !metadata.Compressed {
+		return file, nil
+	}
+
+	buffered := bufio.NewReader(file)
+	if header, err := buffered.Peek(len(gzipMagic)); err != nil || header[0] != gzipMagic[0] || header[1] != gzipMagic[1] {
+		return readCloser{Reader: buffered, Closer: file}, nil
+	}
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("解压 %s 失败: %v", filepath.Base(path), err)
+	}
+	return readCloser{Reader: gz, Closer: file}, nil
+}
+
+// readCloser 组合数据流和需要关闭的文件
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// expandPayload 将已压缩的回收站项目解压写到targetPath，写入完成并fsync后才删除回收站中的压缩文件
+// targetPath与trashPath相同时原地解压；内容不是gzip格式（压缩中途崩溃，替换尚未完成）时直接移动
+func expandPayload(trashPath, targetPath string) error {
+	if !isGzipFile(trashPath) {
+		if targetPath == trashPath {
+			return nil
+		}
+		return os.Rename(trashPath, targetPath)
+	}
+
+	in, err := openPayload(trashPath, &TrashMetadata{Compressed: true})
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tempPath := filepath.Join(filepath.Dir(targetPath), "."+filepath.Base(targetPath)+compactTempSuffix)
+	out, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, targetPath)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if targetPath == trashPath {
+		return nil
+	}
+	return os.Remove(trashPath)
+}
@@ -0,0 +1,161 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompactTrashRestoresTransparently(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			for file, content := range map[string]string{"big.log": compressibleContent, "small.txt": "tiny"} {
+				if err := manager.MoveToTrash(writeContractFile(t, file, content)); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+			}
+
+			report := compactAll(t, manager)
+			if report.Compacted != 1 || report.Skipped != 1 || report.BytesAfter >= report.BytesBefore {
+				t.Fatalf("CompactTrash = %+v, want the large file compacted and the small one skipped", report)
+			}
+			file := trashFileNamed(t, manager, "big.log")
+			if !isGzipFile(file.TrashPath) {
+				t.Fatal("trash content is not gzip after compacting")
+			}
+			if !file.Compressed || file.Size != int64(len(compressibleContent)) {
+				t.Errorf("listed item = compressed %v, size %d; want compressed with the original size", file.Compressed, file.Size)
+			}
+
+			// 已压缩的项目不会被再次压缩
+			if again := compactAll(t, manager); again.Compacted != 0 {
+				t.Errorf("second CompactTrash = %+v, want nothing compacted", again)
+			}
+
+			target := filepath.Join(t.TempDir(), "big.log")
+			if err := manager.RestoreFile(file, target); err != nil {
+				t.Fatalf("RestoreFile: %v", err)
+			}
+			if data, err := os.ReadFile(target); err != nil || string(data) != compressibleContent {
+				t.Errorf("restored %d bytes (%v), want the decompressed original", len(data), err)
+			}
+			if entries, _ := filepath.Glob(filepath.Join(filepath.Dir(target), "*"+compactTempSuffix)); len(entries) != 0 {
+				t.Errorf("restore left temporary files %q", entries)
+			}
+		})
+	}
+}
+
+func TestCompactTrashDryRunLeavesFiles(t *testing.T) {
+	manager := osRotationManager(t)
+	if err := manager.MoveToTrash(writeContractFile(t, "big.log", compressibleContent)); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	report, err := CompactTrash(manager, CompactOptions{OlderThan: -time.Hour, Level: 1, DryRun: true})
+	if err != nil {
+		t.Fatalf("CompactTrash: %v", err)
+	}
+	file := onlyTrashFile(t, manager)
+	if report.Compacted != 1 || report.BytesAfter != 0 || isGzipFile(file.TrashPath) || file.Compressed {
+		t.Errorf("dry run = %+v, compressed %v; want the file counted but unchanged", report, file.Compressed)
+	}
+}
+
+// 压缩在记录元数据后、替换原文件前中断时，回收站中仍是未压缩的原文件
+func TestRestoreAfterInterruptedCompaction(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			if err := manager.MoveToTrash(writeContractFile(t, "big.log", compressibleContent)); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+			file := onlyTrashFile(t, manager)
+			metadata := LoadMetadata(manager, file)
+			metadata.Compressed = true
+			if err := manager.WriteItemMetadata(file, metadata); err != nil {
+				t.Fatalf("WriteItemMetadata: %v", err)
+			}
+
+			target := filepath.Join(t.TempDir(), "big.log")
+			if err := manager.RestoreFile(onlyTrashFile(t, manager), target); err != nil {
+				t.Fatalf("RestoreFile: %v", err)
+			}
+			if data, err := os.ReadFile(target); err != nil || string(data) != compressibleContent {
+				t.Errorf("restored %d bytes (%v), want the original", len(data), err)
+			}
+		})
+	}
+}
+
+func TestCompactTrashRemovesStaleTemps(t *testing.T) {
+	manager := osRotationManager(t)
+	trashPath, err := manager.GetTrashPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(trashPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(trashPath, ".crashed.log"+compactTempSuffix)
+	fresh := filepath.Join(trashPath, ".running.log"+compactTempSuffix)
+	for _, path := range []string{stale, fresh} {
+		if err := os.WriteFile(path, gzipBytes(t, "partial"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * inFlightGrace)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// 临时文件不是回收站项目
+	if files, err := manager.ListTrashFiles(); err != nil || len(files) != 0 {
+		t.Errorf("ListTrashFiles = %v, %v; want the temporary files hidden", files, err)
+	}
+	if !manager.IsEmpty() {
+		t.Error("IsEmpty() = false with only temporary files in the trash")
+	}
+
+	report := compactAll(t, manager)
+	if report.StaleTemps != 1 {
+		t.Errorf("CompactTrash = %+v, want one stale temporary file removed", report)
+	}
+	if _, err := os.Lstat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale temporary file still exists: %v", err)
+	}
+	// 新的临时文件可能属于正在进行的压缩
+	if _, err := os.Lstat(fresh); err != nil {
+		t.Errorf("in-flight temporary file removed: %v", err)
+	}
+}
+
+func TestVerifyTrashIgnoresCompactTemps(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			if err := manager.MoveToTrash(writeContractFile(t, "kept.txt", "kept")); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+			file := onlyTrashFile(t, manager)
+			temp := filepath.Join(filepath.Dir(file.TrashPath), "."+filepath.Base(file.TrashPath)+compactTempSuffix)
+			if err := os.WriteFile(temp, gzipBytes(t, "kept"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			trashPath, err := manager.GetTrashPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ageTree(t, filepath.Dir(trashPath))
+
+			report, err := manager.VerifyTrash(false)
+			if err != nil {
+				t.Fatalf("VerifyTrash: %v", err)
+			}
+			if !report.Healthy() {
+				t.Errorf("VerifyTrash = %+v, want the temporary file ignored", report)
+			}
+		})
+	}
+}
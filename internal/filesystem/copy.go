@@ -0,0 +1,201 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"delguard/internal/errors"
+)
+
+// reflinkSupport 缓存各目标目录是否支持reflink(CoW克隆)，避免每个文件都重复探测
+var (
+	reflinkMu      sync.Mutex
+	reflinkSupport = make(map[string]bool)
+)
+
+// copyFileData 复制单个文件内容，优先尝试reflink，失败后回退到流式复制
+func copyFileData(ctx context.Context, src, dst string, mode os.FileMode) error {
+	if tryReflink(src, dst) {
+		return nil
+	}
+	return streamCopyFile(ctx, src, dst, mode)
+}
+
+// tryReflink 尝试以reflink方式克隆文件，成功且大小一致时返回true
+func tryReflink(src, dst string) bool {
+	key := filepath.Dir(dst)
+
+	reflinkMu.Lock()
+	supported, known := reflinkSupport[key]
+	reflinkMu.Unlock()
+	if known && !supported {
+		return false
+	}
+
+	if err := cloneFile(src, dst); err != nil {
+		// 只缓存"文件系统不支持"这类与源文件无关的结果
+		if isReflinkUnsupported(err) {
+			reflinkMu.Lock()
+			reflinkSupport[key] = false
+			reflinkMu.Unlock()
+		}
+		return false
+	}
+
+	// 校验克隆结果，大小不一致时放弃克隆结果
+	srcInfo, srcErr := os.Stat(src)
+	dstInfo, dstErr := os.Stat(dst)
+	if srcErr != nil || dstErr != nil || srcInfo.Size() != dstInfo.Size() {
+		os.Remove(dst)
+		return false
+	}
+
+	reflinkMu.Lock()
+	reflinkSupport[key] = true
+	reflinkMu.Unlock()
+	return true
+}
+
+// streamCopyFile 分块复制文件并校验大小，按performance.copy_rate限速，失败或超时时删除不完整的目标文件
+func streamCopyFile(ctx context.Context, src, dst string, mode os.FileMode) (err error) {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return errors.FromOS("无法打开源文件", err)
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return errors.FromOS("无法创建目标文件", err)
+	}
+	defer func() {
+		dstFile.Close()
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	written, err := copyWithContext(ctx, dstFile, CopyThrottle().Reader(srcFile))
+	if err != nil {
+		return errors.FromOS("文件复制失败", err)
+	}
+
+	info, err := srcFile.Stat()
+	if err == nil && written != info.Size() {
+		return fmt.Errorf("文件复制不完整: 期望 %d 字节, 实际 %d 字节", info.Size(), written)
+	}
+
+	// 确保数据写入磁盘
+	if err := dstFile.Sync(); err != nil {
+		return errors.FromOS("数据同步失败", err)
+	}
+
+	return nil
+}
+
+// copyTree 递归复制文件或目录，普通文件优先使用reflink
+func copyTree(ctx context.Context, src, dst string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if IsSpecialFile(info.Mode()) {
+		return errors.NewSpecialFileError(src, SpecialFileKind(info.Mode()))
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.IsDir():
+		// 只读目录先以可写权限创建，复制完内容后再恢复原权限
+		if err := os.MkdirAll(dst, info.Mode().Perm()|0700); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			// 目录中的特殊文件无法复制，跳过会使它们随源目录一起被删除，整个移动改为失败
+			if IsSpecialFile(entry.Type()) {
+				return nestedSpecialFileError(filepath.Join(src, entry.Name()), SpecialFileKind(entry.Type()))
+			}
+			if err := copyTree(ctx, filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return os.Chmod(dst, info.Mode()&restorableModeBits)
+	default:
+		return copyFileData(ctx, src, dst, info.Mode())
+	}
+}
+
+// nestedSpecialFileError 目录中含有特殊文件、无法跨文件系统移动时的错误
+// 与单独删除的特殊文件不同，不能只为整个目录留下删除记录，因此不使用ErrTypeSpecialFile
+func nestedSpecialFileError(path, kind string) error {
+	err := errors.NewError(errors.ErrTypeIO, fmt.Sprintf("目录中的%s无法跨文件系统移动到回收站: %s", kind, path), nil)
+	err.Path = path
+	err.Hint = "请先单独删除目录中的命名管道、套接字或设备文件，再删除该目录"
+	return err
+}
+
+// moveAcrossDevices 重命名文件或目录，目标位于其他文件系统时回退到复制后删除源路径
+// 用于从回收站恢复，调用方已确认目标不存在，复制失败时删除不完整的目标
+func moveAcrossDevices(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.IsCrossDevice(err) {
+		return err
+	}
+
+	ctx, cancel := newOperationContext(src, dst)
+	defer cancel()
+	if err := copyTree(ctx, src, dst); err != nil {
+		os.RemoveAll(dst)
+		if timeoutErr := timeoutError(ctx, src); timeoutErr != nil {
+			return timeoutErr
+		}
+		return err
+	}
+	return removeAllWritable(src)
+}
+
+// removeMovedSource 跨文件系统复制完成后删除源路径，测试时可替换为只删除一部分的桩函数
+var removeMovedSource = removeAllWritable
+
+// moveIntoTrash 将文件或目录移动到回收站中的目标位置，跨文件系统时回退到复制后删除
+func moveIntoTrash(src, dst string) error {
+	err := renameWritable(src, dst)
+	if err == nil {
+		return nil
+	}
+	// 权限不足、文件被占用或空间不足时复制同样会失败，只有跨文件系统时才回退
+	if !errors.IsCrossDevice(err) {
+		return errors.FromOS("移动到回收站失败", err)
+	}
+
+	ctx, cancel := newOperationContext(src, dst)
+	defer cancel()
+	if err := copyTree(ctx, src, dst); err != nil {
+		os.RemoveAll(dst)
+		if timeoutErr := timeoutError(ctx, src); timeoutErr != nil {
+			return timeoutErr
+		}
+		return errors.FromOS("移动到回收站失败", err)
+	}
+	// 复制完成后回收站中的是唯一完整的副本，删除源路径失败时保留它
+	if err := removeMovedSource(src); err != nil {
+		return errors.NewPartialMoveError(src, dst, err)
+	}
+	return nil
+}
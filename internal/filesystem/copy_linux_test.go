@@ -0,0 +1,203 @@
+package filesystem
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"delguard/internal/errors"
+)
+
+func TestStreamCopyClassifiesFailures(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	if err := os.WriteFile(src, make([]byte, 64*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skipf("/dev/full unavailable: %v", err)
+	}
+	// 经由符号链接写入/dev/full，每次写入都返回ENOSPC；失败后删除的是链接而不是设备
+	full := filepath.Join(dir, "full")
+	if err := os.Symlink("/dev/full", full); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		dst  string
+		want errors.ErrorType
+	}{
+		{"disk full", src, full, errors.ErrTypeDiskFull},
+		{"missing source", filepath.Join(dir, "missing"), filepath.Join(dir, "out"), errors.ErrTypeFileNotFound},
+		{"missing destination directory", src, filepath.Join(dir, "no", "out"), errors.ErrTypeFileNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := streamCopyFile(context.Background(), tt.src, tt.dst, 0644)
+			if !errors.IsType(err, tt.want) {
+				t.Errorf("streamCopyFile error = %v (%s), want %s", err, errors.KindOf(err), tt.want.Kind())
+			}
+		})
+	}
+	if _, err := os.Lstat(full); !os.IsNotExist(err) {
+		t.Errorf("failed copy left its destination behind: %v", err)
+	}
+}
+
+// otherDeviceDir 返回与临时目录位于不同文件系统的目录，没有时跳过测试
+func otherDeviceDir(t *testing.T) string {
+	t.Helper()
+	base, err := os.MkdirTemp("/dev/shm", "delguard-test-")
+	if err != nil {
+		t.Skipf("/dev/shm unavailable: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(base) })
+	probe := filepath.Join(base, "probe")
+	if err := os.WriteFile(probe, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(probe, filepath.Join(t.TempDir(), "probe")); err == nil || !errors.IsCrossDevice(err) {
+		t.Skip("/dev/shm is on the same filesystem as the temporary directory")
+	}
+	return base
+}
+
+func TestMoveAcrossDevicesCopiesTree(t *testing.T) {
+	src := filepath.Join(otherDeviceDir(t), "restored")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "data.txt"), []byte("payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "restored")
+	if err := moveAcrossDevices(src, dst); err != nil {
+		t.Fatalf("moveAcrossDevices: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "sub", "data.txt")); err != nil || string(data) != "payload" {
+		t.Errorf("moved content = %q, %v", data, err)
+	}
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Errorf("source still exists after a cross-device move: %v", err)
+	}
+}
+
+// TestMoveToTrashAcrossDevicesKeepsCopyWhenSourceRemovalFails 源目录只删除了一部分时，
+// 回收站中的完整副本和.trashinfo必须保留，否则已删除的文件就彻底丢失了
+func TestMoveToTrashAcrossDevicesKeepsCopyWhenSourceRemovalFails(t *testing.T) {
+	root := otherDeviceDir(t)
+	manager, err := newLinuxBackend(BackendOptions{TrashDir: root, TrashRoot: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved := removeMovedSource
+	removeMovedSource = func(path string) error {
+		os.Remove(filepath.Join(path, "a.txt"))
+		return &os.PathError{Op: "unlinkat", Path: filepath.Join(path, "b.txt"), Err: os.ErrPermission}
+	}
+	t.Cleanup(func() { removeMovedSource = saved })
+
+	err = manager.MoveToTrash(src)
+	var partial *errors.DelGuardError
+	if !stderrors.As(err, &partial) || partial.Type != errors.ErrTypeIO || !strings.Contains(partial.Hint, root) {
+		t.Fatalf("MoveToTrash = %v, want a partial move error pointing at the trash copy", err)
+	}
+	file := onlyTrashFile(t, manager)
+	if file.OriginalPath != src {
+		t.Errorf("OriginalPath = %q, want %q", file.OriginalPath, src)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if data, err := os.ReadFile(filepath.Join(file.TrashPath, name)); err != nil || string(data) != name {
+			t.Errorf("trash copy of %s = %q, %v", name, data, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "info", filepath.Base(file.TrashPath)+".trashinfo")); err != nil {
+		t.Errorf(".trashinfo was removed: %v", err)
+	}
+}
+
+func TestMoveToTrashWarnsWhenShareTrashUnavailable(t *testing.T) {
+	useTempHome(t)
+	share := t.TempDir()
+	stubMounts(t, func(path string) (MountInfo, error) {
+		if isSubPath(share, path) {
+			return MountInfo{MountPoint: share, FSType: "nfs4", Remote: true}, nil
+		}
+		return MountInfo{MountPoint: string(filepath.Separator), FSType: "ext4"}, nil
+	})
+	// 共享根目录下同名的普通文件使共享回收站无法创建
+	shareTrash := filepath.Join(share, fmt.Sprintf(".Trash-%d", os.Getuid()))
+	if err := os.WriteFile(shareTrash, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	manager := &LinuxTrashManager{
+		trashPath:    filepath.Join(root, "files"),
+		infoPath:     filepath.Join(root, "info"),
+		networkTrash: NetworkTrashShare,
+	}
+	path := filepath.Join(share, "report.txt")
+	if err := os.WriteFile(path, []byte("report"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := manager.MoveToTrash(path)
+	warning, ok := AsWarning(err)
+	if !ok {
+		t.Fatalf("MoveToTrash = %v, want a warning about the share trash", err)
+	}
+	if warning.Path != path || !strings.Contains(warning.Error(), shareTrash) {
+		t.Errorf("warning = %v", warning)
+	}
+	if file := onlyTrashFile(t, manager); !isSubPath(root, file.TrashPath) {
+		t.Errorf("trashed into %s, want the local trash %s", file.TrashPath, root)
+	}
+}
+
+func TestMoveIntoTrashAcrossDevicesRefusesNestedSpecialFiles(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "with-pipe")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "data.txt"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pipe := filepath.Join(src, "pipe")
+	if err := syscall.Mkfifo(pipe, 0644); err != nil {
+		t.Fatalf("Mkfifo: %v", err)
+	}
+	dst := filepath.Join(otherDeviceDir(t), "with-pipe")
+
+	err := moveIntoTrash(src, dst)
+	if err == nil {
+		t.Fatal("moveIntoTrash dropped a named pipe inside the directory")
+	}
+	if errors.IsType(err, errors.ErrTypeSpecialFile) || !strings.Contains(err.Error(), "pipe") {
+		t.Errorf("error = %v, want a failed move naming the pipe", err)
+	}
+	for _, path := range []string{pipe, filepath.Join(src, "data.txt")} {
+		if _, err := os.Lstat(path); err != nil {
+			t.Errorf("source entry %s is gone: %v", path, err)
+		}
+	}
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial copy left at %s: %v", dst, err)
+	}
+}
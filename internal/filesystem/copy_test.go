@@ -0,0 +1,153 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCloneSource 在dir中创建跨越多个簇且末尾不足一簇的源文件，返回路径和内容
+func writeCloneSource(t *testing.T, dir string) (string, []byte) {
+	t.Helper()
+	data := make([]byte, 3*65536+1234)
+	rand.New(rand.NewSource(1)).Read(data)
+	src := filepath.Join(dir, "source.bin")
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return src, data
+}
+
+// assertContent 检查path的内容与want一致
+func assertContent(t *testing.T, path string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: %d bytes differ from the %d source bytes", path, len(got), len(want))
+	}
+}
+
+// forgetReflinkSupport 清除dir的reflink探测缓存，使测试之间互不影响
+func forgetReflinkSupport(t *testing.T, dir string) {
+	t.Cleanup(func() {
+		reflinkMu.Lock()
+		delete(reflinkSupport, dir)
+		reflinkMu.Unlock()
+	})
+}
+
+func TestCopyFileDataFallsBackWhenReflinkUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	src, data := writeCloneSource(t, dir)
+	target := filepath.Join(dir, "target")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	forgetReflinkSupport(t, target)
+	reflinkMu.Lock()
+	reflinkSupport[target] = false
+	reflinkMu.Unlock()
+
+	dst := filepath.Join(target, "copy.bin")
+	if err := copyFileData(context.Background(), src, dst, 0644); err != nil {
+		t.Fatalf("copyFileData: %v", err)
+	}
+	assertContent(t, dst, data)
+}
+
+func TestTryReflinkCachesUnsupportedTarget(t *testing.T) {
+	dir := t.TempDir()
+	src, data := writeCloneSource(t, dir)
+	forgetReflinkSupport(t, dir)
+
+	err := cloneFile(src, filepath.Join(dir, "probe.bin"))
+	if err == nil {
+		t.Skip("the temporary directory supports reflink")
+	}
+	if !isReflinkUnsupported(err) {
+		t.Skipf("clone failed for a reason unrelated to the target: %v", err)
+	}
+
+	dst := filepath.Join(dir, "copy.bin")
+	if tryReflink(src, dst) {
+		t.Fatal("tryReflink succeeded on a target without reflink support")
+	}
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Errorf("failed clone left %s behind: %v", dst, err)
+	}
+	reflinkMu.Lock()
+	supported, known := reflinkSupport[dir]
+	reflinkMu.Unlock()
+	if !known || supported {
+		t.Errorf("reflink support for %s cached as %v (known %v), want unsupported", dir, supported, known)
+	}
+	if err := copyFileData(context.Background(), src, dst, 0644); err != nil {
+		t.Fatalf("copyFileData: %v", err)
+	}
+	assertContent(t, dst, data)
+}
+
+// testCloneIn 在dir中克隆文件并校验内容，dir为空时使用临时目录；目标不支持reflink时跳过
+func testCloneIn(t *testing.T, dir string) {
+	if dir == "" {
+		dir = t.TempDir()
+	} else {
+		var err error
+		if dir, err = os.MkdirTemp(dir, "delguard-reflink-"); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+	}
+	src, data := writeCloneSource(t, dir)
+	forgetReflinkSupport(t, dir)
+
+	dst := filepath.Join(dir, "clone.bin")
+	if err := cloneFile(src, dst); err != nil {
+		if _, statErr := os.Lstat(dst); !os.IsNotExist(statErr) {
+			t.Errorf("failed clone left %s behind: %v", dst, statErr)
+		}
+		t.Skipf("reflink not available in %s: %v", dir, err)
+	}
+	assertContent(t, dst, data)
+
+	again := filepath.Join(dir, "again.bin")
+	if !tryReflink(src, again) {
+		t.Fatal("tryReflink failed after cloneFile succeeded")
+	}
+	assertContent(t, again, data)
+}
+
+// TestMoveIntoTrashOnlyCopiesAcrossDevices 重命名因跨文件系统以外的原因失败时不能回退到复制后删除
+func TestMoveIntoTrashOnlyCopiesAcrossDevices(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 目标是非空目录，重命名失败；复制却可以成功并把源目录合并进去
+	dst := filepath.Join(t.TempDir(), "occupied")
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dst, "other.txt"), []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := moveIntoTrash(src, dst); err == nil {
+		t.Fatal("moveIntoTrash onto an occupied directory succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(src, "main.go")); err != nil {
+		t.Errorf("source was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "main.go")); !os.IsNotExist(err) {
+		t.Errorf("source was copied into the occupied directory: %v", err)
+	}
+}
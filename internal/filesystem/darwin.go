@@ -1,18 +1,21 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"delguard/internal/errors"
 )
 
 // DarwinTrashManager macOS Trash管理器
 type DarwinTrashManager struct {
-	trashPath string
+	trashPath      string
+	preserveXattrs bool
 }
 
 // NewDarwinTrashManager 创建macOS Trash管理器
@@ -21,7 +24,8 @@ func NewDarwinTrashManager() *DarwinTrashManager {
 	trashPath := filepath.Join(homeDir, ".Trash")
 
 	return &DarwinTrashManager{
-		trashPath: trashPath,
+		trashPath:      trashPath,
+		preserveXattrs: true,
 	}
 }
 
@@ -33,49 +37,47 @@ func (d *DarwinTrashManager) MoveToTrash(filePath string) error {
 		return fmt.Errorf("路径转换失败: %v", err)
 	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("文件不存在: %s", absPath)
+	// 检查文件是否存在，失效的符号链接同样可以删除
+	if _, err := os.Lstat(absPath); os.IsNotExist(err) {
+		return errors.NewFileNotFoundError(absPath)
+	}
+
+	// 拒绝重复删除回收站中的文件
+	if err := ValidateTrashSource(d, absPath); err != nil {
+		return err
 	}
 
 	// 确保Trash目录存在
 	if err := os.MkdirAll(d.trashPath, 0755); err != nil {
-		return fmt.Errorf("创建Trash目录失败: %v", err)
+		return errors.FromOS("创建Trash目录失败", err)
 	}
 
 	// 创建元数据目录
 	metadataDir := filepath.Join(d.trashPath, ".delguard_metadata")
 	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("创建元数据目录失败: %v", err)
+		return errors.FromOS("创建元数据目录失败", err)
 	}
 
-	// 获取文件信息
-	fileInfo, err := os.Stat(absPath)
+	// 获取文件信息，符号链接记录链接本身
+	fileInfo, err := os.Lstat(absPath)
 	if err != nil {
-		return fmt.Errorf("获取文件信息失败: %v", err)
+		return errors.FromOS("获取文件信息失败", err)
 	}
 
-	// 生成唯一的文件名
+	// 回收站中的文件名由ID生成，原始文件名只保存在元数据中
 	fileName := filepath.Base(absPath)
-	baseName := fileName[:len(fileName)-len(filepath.Ext(fileName))]
-	ext := filepath.Ext(fileName)
-	timestamp := time.Now().Format("20060102_150405")
-	uniqueName := fmt.Sprintf("%s_%s%s", baseName, timestamp, ext)
-	targetPath := filepath.Join(d.trashPath, uniqueName)
-
-	// 如果目标文件已存在，添加更多随机字符
-	counter := 1
-	for {
-		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
-			break
-		}
-		uniqueName = fmt.Sprintf("%s_%s_%d%s", baseName, timestamp, counter, ext)
-		targetPath = filepath.Join(d.trashPath, uniqueName)
-		counter++
+	uniqueName, err := reserveTrashName(d.trashPath, fileName, func(name string) string {
+		return filepath.Join(metadataDir, name+".json")
+	})
+	if err != nil {
+		return err
 	}
+	targetPath := filepath.Join(d.trashPath, uniqueName)
+	metadataFile := filepath.Join(metadataDir, uniqueName+".json")
 
 	// 创建元数据
 	metadata := TrashMetadata{
+		ID:           uniqueName,
 		OriginalPath: absPath,
 		DeletedTime:  time.Now(),
 		FileName:     fileName,
@@ -84,26 +86,71 @@ func (d *DarwinTrashManager) MoveToTrash(filePath string) error {
 		Permissions:  fileInfo.Mode().String(),
 		SystemTrash:  false,
 	}
-	
-	metadataFile := filepath.Join(metadataDir, uniqueName+".json")
+	captureFileAttributes(&metadata, fileInfo)
+	captureLinkTarget(&metadata, absPath, fileInfo)
+	if d.preserveXattrs {
+		if err := captureExtendedAttributes(&metadata, absPath); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  警告: %s: %v\n", absPath, err)
+		}
+	}
+
 	if err := d.writeJSONMetadata(metadataFile, metadata); err != nil {
-		return fmt.Errorf("创建元数据文件失败: %v", err)
+		os.Remove(metadataFile)
+		return errors.FromOS("创建元数据文件失败", err)
 	}
 
 	// 移动文件到Trash
-	err = os.Rename(absPath, targetPath)
+	err = renameWritable(absPath, targetPath)
 	if err != nil {
 		// 如果重命名失败，尝试复制后删除
-		if copyErr := d.copyAndRemove(absPath, targetPath); copyErr != nil {
+		ctx, cancel := newOperationContext(absPath, targetPath)
+		defer cancel()
+		if copyErr := d.copyAndRemove(ctx, absPath, targetPath); copyErr != nil {
+			if err := timeoutError(ctx, absPath); err != nil {
+				// 目录按文件逐个移动，已移入回收站的部分保留元数据以便恢复
+				if _, statErr := os.Lstat(targetPath); statErr != nil {
+					os.Remove(metadataFile)
+				}
+				return err
+			}
 			// 清理元数据文件
 			os.Remove(metadataFile)
-			return fmt.Errorf("移动到Trash失败: %v", copyErr)
+			return errors.FromOS("移动到Trash失败", copyErr)
 		}
 	}
 
 	return nil
 }
 
+// ImportFile 将sourcePath按给定元数据放入macOS Trash
+func (d *DarwinTrashManager) ImportFile(sourcePath string, metadata TrashMetadata) error {
+	metadataDir := filepath.Join(d.trashPath, ".delguard_metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return errors.FromOS("创建元数据目录失败", err)
+	}
+
+	uniqueName, err := reserveTrashName(d.trashPath, metadata.FileName, func(name string) string {
+		return filepath.Join(metadataDir, name+".json")
+	})
+	if err != nil {
+		return err
+	}
+	targetPath := filepath.Join(d.trashPath, uniqueName)
+	metadataFile := filepath.Join(metadataDir, uniqueName+".json")
+
+	metadata.ID = uniqueName
+	metadata.SystemTrash = false
+	if err := d.writeJSONMetadata(metadataFile, metadata); err != nil {
+		os.Remove(metadataFile)
+		return errors.FromOS("创建元数据文件失败", err)
+	}
+	if err := moveIntoTrash(sourcePath, targetPath); err != nil {
+		os.Remove(metadataFile)
+		return err
+	}
+	return nil
+}
+
 // GetTrashPath 获取macOS Trash路径
 func (d *DarwinTrashManager) GetTrashPath() (string, error) {
 	return d.trashPath, nil
@@ -118,7 +165,7 @@ func (d *DarwinTrashManager) ListTrashContents() ([]TrashItem, error) {
 
 	entries, err := os.ReadDir(d.trashPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取Trash失败: %v", err)
+		return nil, errors.FromOS("读取Trash失败", err)
 	}
 
 	metadataDir := filepath.Join(d.trashPath, ".delguard_metadata")
@@ -136,21 +183,40 @@ func (d *DarwinTrashManager) ListTrashContents() ([]TrashItem, error) {
 			continue // 跳过无法获取信息的文件
 		}
 
-		// 尝试读取对应的元数据文件获取原始路径
+		// 尝试读取对应的元数据文件获取原始路径和原始文件名
 		metadataFile := filepath.Join(metadataDir, entry.Name()+".json")
-		originalPath, deletedTime := d.readJSONMetadata(metadataFile)
+		displayName := entry.Name()
+		var originalPath string
+		var deletedTime time.Time
+		size := info.Size()
+		pinned, corrupt, compressed := false, false, false
+		if metadata, err := d.readJSONMetadata(metadataFile); err == nil {
+			originalPath = metadata.nativeOriginalPath()
+			deletedTime = metadata.DeletedTime
+			pinned = metadata.Pinned
+			corrupt = metadata.VerifyResult == VerifyCorrupt
+			if metadata.Compressed && metadata.Size > 0 {
+				compressed, size = true, metadata.Size
+			}
+			if metadata.FileName != "" {
+				displayName = metadata.FileName
+			}
+		}
 		
 		if deletedTime.IsZero() {
 			deletedTime = info.ModTime() // 使用修改时间作为回退
 		}
 
 		trashItem := TrashItem{
-			Name:         entry.Name(),
+			Name:         displayName,
 			OriginalPath: originalPath,
 			Path:         fullPath,
-			Size:         info.Size(),
+			Size:         size,
 			DeletedTime:  deletedTime,
 			IsDirectory:  entry.IsDir(),
+			Pinned:       pinned,
+			Corrupt:      corrupt,
+			Compressed:   compressed,
 		}
 
 		trashItems = append(trashItems, trashItem)
@@ -161,35 +227,19 @@ func (d *DarwinTrashManager) ListTrashContents() ([]TrashItem, error) {
 
 // RestoreFromTrash 从回收站恢复文件
 func (d *DarwinTrashManager) RestoreFromTrash(fileName string, originalPath string) error {
-	if originalPath == "" {
-		return fmt.Errorf("macOS需要指定恢复路径")
-	}
-
-	trashFilePath := filepath.Join(d.trashPath, fileName)
-
-	// 检查文件是否存在于回收站
-	if _, err := os.Stat(trashFilePath); os.IsNotExist(err) {
-		return fmt.Errorf("文件不存在于回收站: %s", fileName)
-	}
-
-	// 确保目标目录存在
-	targetDir := filepath.Dir(originalPath)
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("创建目标目录失败: %v", err)
-	}
-
-	// 检查目标文件是否已存在
-	if _, err := os.Stat(originalPath); err == nil {
-		return fmt.Errorf("目标文件已存在: %s", originalPath)
+	files, err := d.ListTrashFiles()
+	if err != nil {
+		return err
 	}
 
-	// 移动文件从Trash到目标位置
-	err := os.Rename(trashFilePath, originalPath)
-	if err != nil {
-		return fmt.Errorf("恢复文件失败: %v", err)
+	// 优先按原始文件名匹配，其次按回收站中的文件名匹配
+	for _, file := range files {
+		if file.Name == fileName || file.ID == fileName {
+			return d.RestoreFile(file, originalPath)
+		}
 	}
 
-	return nil
+	return fmt.Errorf("文件不存在于回收站: %s", fileName)
 }
 
 // GetStats 获取回收站统计信息
@@ -206,12 +256,19 @@ func (d *DarwinTrashManager) GetStats() (*TrashStats, error) {
 
 	if len(files) > 0 {
 		stats.OldestFile = files[0].DeletedTime
+		paths := make([]string, 0, len(files))
 		for _, file := range files {
 			stats.TotalSize += file.Size
+			paths = append(paths, file.Path)
+			if file.Pinned {
+				stats.PinnedFiles++
+				stats.PinnedSize += file.Size
+			}
 			if file.DeletedTime.Before(stats.OldestFile) {
 				stats.OldestFile = file.DeletedTime
 			}
 		}
+		stats.DiskSize = trashDiskSize(paths)
 	}
 
 	return stats, nil
@@ -219,36 +276,13 @@ func (d *DarwinTrashManager) GetStats() (*TrashStats, error) {
 
 // Clear 清空回收站
 func (d *DarwinTrashManager) Clear() error {
-	// 检查回收站目录是否存在
-	if _, err := os.Stat(d.trashPath); os.IsNotExist(err) {
-		return nil // 回收站已经是空的
-	}
-
-	entries, err := os.ReadDir(d.trashPath)
-	if err != nil {
-		return fmt.Errorf("读取回收站失败: %v", err)
-	}
-
-	// 删除所有文件和目录
-	for _, entry := range entries {
-		fullPath := filepath.Join(d.trashPath, entry.Name())
-		err := os.RemoveAll(fullPath)
-		if err != nil {
-			return fmt.Errorf("删除文件失败 %s: %v", fullPath, err)
-		}
-	}
-
-	return nil
+	// 逐个删除文件和目录及其元数据，单个项目失败时继续
+	return emptyTrashDir(d.trashPath, isMetadataDir)
 }
 
 // IsEmpty 检查回收站是否为空
 func (d *DarwinTrashManager) IsEmpty() bool {
-	entries, err := os.ReadDir(d.trashPath)
-	if err != nil {
-		return true
-	}
-
-	return len(entries) == 0
+	return trashDirEmpty(d.trashPath)
 }
 
 // GetTrashStats 获取回收站统计信息（原有方法）
@@ -263,19 +297,22 @@ func (d *DarwinTrashManager) EmptyTrash() error {
 
 // CleanOldFiles 清理过期文件
 func (d *DarwinTrashManager) CleanOldFiles(maxDays int) error {
+	throttle := MaintenanceThrottle()
 	files, err := d.ListTrashContents()
 	if err != nil {
 		return err
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -maxDays)
+	// 按原始位置匹配保留规则，未匹配时使用maxDays
+	now := time.Now()
 
 	for _, file := range files {
-		if file.DeletedTime.Before(cutoffTime) {
-			fullPath := filepath.Join(d.trashPath, file.Name)
-			if err := os.RemoveAll(fullPath); err != nil {
+		if !file.Pinned && isExpired(file.OriginalPath, file.DeletedTime, maxDays, now) {
+			fullPath := file.Path
+			if err := RemoveFromTrash(d, fullPath); err != nil {
 				return fmt.Errorf("清理过期文件失败 %s: %v", fullPath, err)
 			}
+			throttle.Pause()
 		}
 	}
 
@@ -287,14 +324,14 @@ func (d *DarwinTrashManager) ValidateTrash() error {
 	// 检查回收站目录是否存在，不存在则创建
 	if _, err := os.Stat(d.trashPath); os.IsNotExist(err) {
 		if err := os.MkdirAll(d.trashPath, 0755); err != nil {
-			return fmt.Errorf("创建回收站目录失败: %v", err)
+			return errors.FromOS("创建回收站目录失败", err)
 		}
 	}
 
 	// 创建元数据目录
 	metadataDir := filepath.Join(d.trashPath, ".delguard_metadata")
 	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("创建元数据目录失败: %v", err)
+		return errors.FromOS("创建元数据目录失败", err)
 	}
 
 	// 检查目录权限
@@ -311,7 +348,7 @@ func (d *DarwinTrashManager) ValidateTrash() error {
 
 // writeJSONMetadata 写入JSON格式的元数据文件
 func (d *DarwinTrashManager) writeJSONMetadata(metadataFile string, metadata TrashMetadata) error {
-	data, err := json.MarshalIndent(metadata, "", "  ")
+	data, err := json.MarshalIndent(withPortablePath(metadata), "", "  ")
 	if err != nil {
 		return fmt.Errorf("序列化元数据失败: %v", err)
 	}
@@ -320,22 +357,28 @@ func (d *DarwinTrashManager) writeJSONMetadata(metadataFile string, metadata Tra
 }
 
 // readJSONMetadata 读取JSON格式的元数据文件
-func (d *DarwinTrashManager) readJSONMetadata(metadataFile string) (string, time.Time) {
-	data, err := os.ReadFile(metadataFile)
-	if err != nil {
-		return "", time.Time{}
-	}
-	
-	var metadata TrashMetadata
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		return "", time.Time{}
-	}
-	
-	return metadata.OriginalPath, metadata.DeletedTime
+func (d *DarwinTrashManager) readJSONMetadata(metadataFile string) (*TrashMetadata, error) {
+	return readTrashMetadata(metadataFile)
+}
+
+// VerifyTrash 检查没有对应文件的元数据，专用回收站中还检查缺少元数据的文件
+func (d *DarwinTrashManager) VerifyTrash(repair bool) (TrashVerifyReport, error) {
+	return verifyTrashLayout(trashLayout{
+		filesDir: d.trashPath,
+		sources:  []metadataSource{{dir: filepath.Join(d.trashPath, ".delguard_metadata"), suffix: ".json"}},
+		// 系统Trash中由Finder删除的文件没有DelGuard元数据，不视为孤立文件
+		checkFiles: filepath.Base(d.trashPath) != ".Trash",
+		write:      d.writeJSONMetadata,
+	}, repair)
 }
 
 // copyAndRemove 复制文件后删除源文件（用于跨设备移动）
-func (d *DarwinTrashManager) copyAndRemove(src, dst string) error {
+func (d *DarwinTrashManager) copyAndRemove(ctx context.Context, src, dst string) error {
+	// 打开命名管道会一直阻塞，特殊文件交由调用方处理
+	if err := specialFileError(src); err != nil {
+		return err
+	}
+
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return err
@@ -350,51 +393,41 @@ func (d *DarwinTrashManager) copyAndRemove(src, dst string) error {
 
 	if info.IsDir() {
 		// 复制目录
-		return d.copyDirectory(src, dst)
+		return d.copyDirectory(ctx, src, dst)
 	}
 
 	// 复制文件
-	return d.copyFile(src, dst)
+	return d.copyFile(ctx, src, dst)
 }
 
-// copyFile 复制单个文件
-func (d *DarwinTrashManager) copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Create(dst)
+// copyFile 复制单个文件，APFS上优先使用clonefile
+func (d *DarwinTrashManager) copyFile(ctx context.Context, src, dst string) error {
+	info, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
 
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
+	if err := copyFileData(ctx, src, dst, info.Mode()); err != nil {
 		return err
 	}
 
-	// 确保数据写入磁盘
-	dstFile.Sync()
-
 	// 删除源文件
 	return os.Remove(src)
 }
 
 // copyDirectory 递归复制目录
-func (d *DarwinTrashManager) copyDirectory(src, dst string) error {
+func (d *DarwinTrashManager) copyDirectory(ctx context.Context, src, dst string) error {
 	// 获取源目录信息
 	srcInfo, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
 
-	// 创建目标目录
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+	// 创建目标目录，只读目录先以可写权限创建，复制完内容后再恢复原权限
+	if err := os.MkdirAll(dst, srcInfo.Mode().Perm()|0700); err != nil {
 		return err
 	}
+	defer os.Chmod(dst, srcInfo.Mode()&restorableModeBits)
 
 	// 读取源目录内容
 	entries, err := os.ReadDir(src)
@@ -407,21 +440,25 @@ func (d *DarwinTrashManager) copyDirectory(src, dst string) error {
 		srcPath := filepath.Join(src, entry.Name())
 		dstPath := filepath.Join(dst, entry.Name())
 
+		// 特殊文件没有内容，不复制，随源目录一起删除
+		if IsSpecialFile(entry.Type()) {
+			continue
+		}
 		if entry.IsDir() {
 			// 递归复制子目录
-			if err := d.copyDirectory(srcPath, dstPath); err != nil {
+			if err := d.copyDirectory(ctx, srcPath, dstPath); err != nil {
 				return err
 			}
 		} else {
 			// 复制文件
-			if err := d.copyFile(srcPath, dstPath); err != nil {
+			if err := d.copyFile(ctx, srcPath, dstPath); err != nil {
 				return err
 			}
 		}
 	}
 
 	// 删除源目录
-	return os.RemoveAll(src)
+	return removeAllWritable(src)
 }
 
 // ListTrashFiles 列出回收站中的文件（兼容原有接口）
@@ -434,12 +471,16 @@ func (d *DarwinTrashManager) ListTrashFiles() ([]TrashFile, error) {
 	files := make([]TrashFile, len(items))
 	for i, item := range items {
 		files[i] = TrashFile{
+			ID:           filepath.Base(item.Path),
 			Name:         item.Name,
 			OriginalPath: item.OriginalPath,
 			TrashPath:    item.Path,
 			Size:         item.Size,
 			DeletedTime:  item.DeletedTime,
 			IsDirectory:  item.IsDirectory,
+			Pinned:       item.Pinned,
+			Corrupt:      item.Corrupt,
+			Compressed:   item.Compressed,
 		}
 	}
 
@@ -448,5 +489,73 @@ func (d *DarwinTrashManager) ListTrashFiles() ([]TrashFile, error) {
 
 // RestoreFile 从回收站恢复文件（兼容原有接口）
 func (d *DarwinTrashManager) RestoreFile(trashFile TrashFile, targetPath string) error {
-	return d.RestoreFromTrash(trashFile.Name, targetPath)
+	if targetPath == "" {
+		targetPath = trashFile.OriginalPath
+	}
+	if targetPath == "" {
+		return fmt.Errorf("macOS需要指定恢复路径")
+	}
+
+	// 不允许恢复到回收站内部
+	if err := ValidateRestoreTarget(d, targetPath); err != nil {
+		return err
+	}
+
+	// 检查文件是否存在于回收站
+	if _, err := os.Stat(trashFile.TrashPath); os.IsNotExist(err) {
+		return fmt.Errorf("文件不存在于回收站: %s", trashFile.Name)
+	}
+
+	// 确保目标目录存在
+	targetDir := filepath.Dir(targetPath)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return errors.FromOS("创建目标目录失败", err)
+	}
+
+	// 检查目标文件是否已存在，不区分大小写的文件系统上只有大小写不同的文件也算
+	if existing, ok := ExistingEntry(targetPath); ok {
+		return fmt.Errorf("目标文件已存在: %s", existing)
+	}
+
+	metadataFile := filepath.Join(d.trashPath, ".delguard_metadata", filepath.Base(trashFile.TrashPath)+".json")
+	metadata, _ := d.readJSONMetadata(metadataFile)
+
+	// 移动文件从Trash到目标位置
+	if err := moveOutOfTrash(trashFile.TrashPath, targetPath, metadata); err != nil {
+		return errors.FromOS("恢复文件失败", err)
+	}
+
+	// 恢复权限、所有者和时间戳
+	if err := applyFileAttributes(targetPath, metadata); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  警告: %s: %v\n", targetPath, err)
+	}
+
+	// 清理对应的元数据文件
+	os.Remove(metadataFile)
+
+	return nil
+}
+
+// systemBinUnavailable 专用回收站可以将清理的项目交给~/.Trash，当前已是~/.Trash或无法访问时返回错误
+func (d *DarwinTrashManager) systemBinUnavailable() error {
+	if d.trashPath == NewDarwinTrashManager().trashPath {
+		return fmt.Errorf("当前回收站已是系统回收站")
+	}
+	_, err := CheckSystemTrash()
+	return err
+}
+
+// moveToSystemBin 将专用回收站中的项目移入~/.Trash，元数据记录原始路径
+func (d *DarwinTrashManager) moveToSystemBin(file TrashFile, metadata TrashMetadata) error {
+	return NewDarwinTrashManager().ImportFile(file.TrashPath, metadata)
+}
+
+// ReadItemMetadata 读取回收站项目旁的JSON元数据
+func (d *DarwinTrashManager) ReadItemMetadata(file TrashFile) (TrashMetadata, bool) {
+	return readItemMetadata(file)
+}
+
+// WriteItemMetadata 将元数据写入回收站项目旁的JSON元数据文件
+func (d *DarwinTrashManager) WriteItemMetadata(file TrashFile, metadata TrashMetadata) error {
+	return storeMetadata(d, file, metadata)
 }
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package filesystem
+
+import "fmt"
+
+// deviceID 当前平台不支持获取设备号
+func deviceID(path string) (uint64, error) {
+	return 0, fmt.Errorf("当前平台不支持获取设备号")
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// deviceID 返回路径所在文件系统的设备号，不跟随符号链接
+func deviceID(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("无法获取 %s 的设备号", path)
+	}
+	return uint64(stat.Dev), nil
+}
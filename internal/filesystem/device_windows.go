@@ -0,0 +1,28 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// deviceID 返回路径所在卷的序列号，文件夹中挂载的卷与所在的卷序列号不同
+func deviceID(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	handle, err := windows.CreateFile(pathPtr, 0,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(handle)
+
+	var info windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(handle, &info); err != nil {
+		return 0, err
+	}
+	return uint64(info.VolumeSerialNumber), nil
+}
@@ -0,0 +1,87 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// significantUsageDiff 表观大小与实际占用相差超过该比例时同时显示两者
+const significantUsageDiff = 0.10
+
+// fileID 文件在卷上的唯一标识，用于识别指向同一数据的硬链接
+type fileID struct {
+	volume uint64
+	index  uint64
+}
+
+// diskUsage 统计一次操作涉及的磁盘占用，同一文件的多个硬链接只计一次实际占用
+type diskUsage struct {
+	seen map[fileID]bool
+}
+
+// newDiskUsage 创建磁盘占用统计
+func newDiskUsage() *diskUsage {
+	return &diskUsage{seen: make(map[fileID]bool)}
+}
+
+// add 返回单个条目的表观大小和实际占用的磁盘空间
+// 稀疏文件和压缩文件按已分配的块计算，已统计过的硬链接实际占用为0
+func (u *diskUsage) add(path string, info os.FileInfo) (int64, int64) {
+	if info.IsDir() {
+		return 0, 0
+	}
+	if id, ok := hardlinkID(path, info); ok {
+		if u.seen[id] {
+			return info.Size(), 0
+		}
+		u.seen[id] = true
+	}
+	return info.Size(), allocatedSize(path, info)
+}
+
+// tree 返回文件或目录中所有文件的表观大小和实际占用，不跟随符号链接
+func (u *diskUsage) tree(root string) (int64, int64) {
+	var apparent, onDisk int64
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil {
+			a, d := u.add(path, info)
+			apparent += a
+			onDisk += d
+		}
+		return nil
+	})
+	return apparent, onDisk
+}
+
+// UsageDiffers 表观大小与实际占用相差是否超过10%，超过时应同时显示两者
+func UsageDiffers(apparent, onDisk int64) bool {
+	larger, smaller := apparent, onDisk
+	if smaller > larger {
+		larger, smaller = smaller, larger
+	}
+	return larger > 0 && float64(larger-smaller) > float64(larger)*significantUsageDiff
+}
+
+// trashDiskSize 统计回收站项目实际占用的磁盘空间，项目之间的硬链接只计一次
+func trashDiskSize(paths []string) int64 {
+	usage := newDiskUsage()
+	var total int64
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		_, onDisk := usage.tree(path)
+		total += onDisk
+	}
+	return total
+}
+
+// measureDiskUsage 将项目的Size替换为实际占用的磁盘空间，供按容量计算的轮转使用
+func measureDiskUsage(files []TrashFile) {
+	usage := newDiskUsage()
+	for i := range files {
+		if files[i].TrashPath != "" {
+			_, files[i].Size = usage.tree(files[i].TrashPath)
+		}
+	}
+}
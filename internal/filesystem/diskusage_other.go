@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+
+package filesystem
+
+import "os"
+
+// allocatedSize 当前平台无法获取已分配的块，使用表观大小
+func allocatedSize(path string, info os.FileInfo) int64 {
+	return info.Size()
+}
+
+// hardlinkID 当前平台不识别硬链接
+func hardlinkID(path string, info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// fileIdentity 当前平台无法获取文件的唯一标识
+func fileIdentity(path string, info os.FileInfo) (fileID, uint64, bool) {
+	return fileID{}, 0, false
+}
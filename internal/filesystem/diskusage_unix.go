@@ -0,0 +1,35 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"os"
+	"syscall"
+)
+
+// allocatedSize 按已分配的块计算文件实际占用的磁盘空间（st_blocks以512字节为单位）
+func allocatedSize(path string, info os.FileInfo) int64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size()
+	}
+	return int64(stat.Blocks) * 512
+}
+
+// hardlinkID 返回有多个硬链接的文件的设备号和inode，只有一个链接时返回false
+func hardlinkID(path string, info os.FileInfo) (fileID, bool) {
+	id, links, ok := fileIdentity(path, info)
+	if !ok || links <= 1 {
+		return fileID{}, false
+	}
+	return id, true
+}
+
+// fileIdentity 返回文件的设备号、inode和硬链接数
+func fileIdentity(path string, info os.FileInfo) (fileID, uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, 0, false
+	}
+	return fileID{volume: uint64(stat.Dev), index: uint64(stat.Ino)}, uint64(stat.Nlink), true
+}
@@ -0,0 +1,65 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// getCompressedFileSize kernel32.dll中的GetCompressedFileSizeW，返回稀疏和压缩文件实际占用的空间
+var getCompressedFileSize = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetCompressedFileSizeW")
+
+// invalidFileSize GetCompressedFileSizeW失败时返回的低32位
+const invalidFileSize = 0xFFFFFFFF
+
+// allocatedSize 通过GetCompressedFileSizeW获取文件实际占用的磁盘空间，失败时使用表观大小
+func allocatedSize(path string, info os.FileInfo) int64 {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return info.Size()
+	}
+	var high uint32
+	low, _, callErr := getCompressedFileSize.Call(uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&high)))
+	// 低32位为0xFFFFFFFF且设置了错误码时才是失败，否则是合法的大小
+	if uint32(low) == invalidFileSize && callErr != windows.ERROR_SUCCESS {
+		return info.Size()
+	}
+	return int64(high)<<32 | int64(uint32(low))
+}
+
+// hardlinkID 返回有多个硬链接的文件的卷序列号和文件索引，只有一个链接时返回false
+func hardlinkID(path string, info os.FileInfo) (fileID, bool) {
+	id, links, ok := fileIdentity(path, info)
+	if !ok || links <= 1 {
+		return fileID{}, false
+	}
+	return id, true
+}
+
+// fileIdentity 通过GetFileInformationByHandle返回文件的卷序列号、文件索引和硬链接数
+// 以FILE_READ_ATTRIBUTES打开，不跟随符号链接和其他重分析点
+func fileIdentity(path string, info os.FileInfo) (fileID, uint64, bool) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return fileID{}, 0, false
+	}
+	handle, err := windows.CreateFile(pathPtr, windows.FILE_READ_ATTRIBUTES,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		return fileID{}, 0, false
+	}
+	defer windows.CloseHandle(handle)
+
+	var data windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(handle, &data); err != nil {
+		return fileID{}, 0, false
+	}
+	return fileID{
+		volume: uint64(data.VolumeSerialNumber),
+		index:  uint64(data.FileIndexHigh)<<32 | uint64(data.FileIndexLow),
+	}, uint64(data.NumberOfLinks), true
+}
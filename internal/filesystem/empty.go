@@ -0,0 +1,93 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"delguard/internal/errors"
+)
+
+// EmptyProgress 逐项清空回收站的进度
+type EmptyProgress struct {
+	Done  int   // 已处理的项目数，包括删除失败的项目
+	Total int   // 项目总数
+	Freed int64 // 已释放的字节数
+}
+
+// EmptyReport 逐项清空回收站的结果
+type EmptyReport struct {
+	Removed   int
+	Freed     int64
+	Remaining []TrashFile // 被取消时尚未处理的项目
+	Failures  *errors.ErrorCollector
+}
+
+// Cancelled 清空是否在处理完所有项目之前被取消
+func (r EmptyReport) Cancelled() bool {
+	return len(r.Remaining) > 0
+}
+
+// EmptyItems 逐个永久删除回收站项目，每个项目删除后立即清理其元数据，回收站状态始终与实际内容一致
+// 单个项目失败时记录错误并继续；ctx被取消时在两个项目之间停止，未处理的项目记入Remaining
+// progress不为nil时在每个项目处理后调用
+func EmptyItems(ctx context.Context, manager TrashManager, files []TrashFile, progress func(EmptyProgress)) EmptyReport {
+	report := EmptyReport{Failures: errors.NewErrorCollector()}
+	for i, file := range files {
+		if ctx.Err() != nil {
+			report.Remaining = files[i:]
+			break
+		}
+		if err := RemoveFromTrash(manager, file.TrashPath); err != nil {
+			report.Failures.Add(file.Name, err)
+		} else {
+			report.Removed++
+			report.Freed += file.Size
+		}
+		if progress != nil {
+			progress(EmptyProgress{Done: i + 1, Total: len(files), Freed: report.Freed})
+		}
+	}
+	return report
+}
+
+// isMetadataDir 判断是否为回收站中存放DelGuard元数据或隔离项目的目录，清空回收站时保留
+func isMetadataDir(name string) bool {
+	return name == ".delguard_metadata" || name == QuarantineDirName
+}
+
+// trashDirEmpty 回收站目录中除元数据、隔离目录和压缩临时文件外是否没有项目，目录无法读取时视为空
+func trashDirEmpty(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return true
+	}
+	for _, entry := range entries {
+		if !isMetadataDir(entry.Name()) && !isCompactTemp(entry.Name()) {
+			return false
+		}
+	}
+	return true
+}
+
+// emptyTrashDir 逐个删除回收站目录中的项目，每个项目删除后立即清理其.trashinfo和元数据，
+// 单个项目失败时继续处理其余项目并汇总返回错误，skip返回true的项目不删除
+func emptyTrashDir(dir string, skip func(name string) bool) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil // 回收站已经是空的
+	}
+	if err != nil {
+		return errors.FromOS("读取回收站失败", err)
+	}
+
+	failures := errors.NewErrorCollector()
+	for _, entry := range entries {
+		if skip(entry.Name()) {
+			continue
+		}
+		fullPath := filepath.Join(dir, entry.Name())
+		failures.Add(fullPath, removeTrashEntry(fullPath))
+	}
+	return failures.Err()
+}
@@ -0,0 +1,554 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// FakeTrashManager 基于临时目录和内存元数据的回收站管理器
+// 用于测试删除/恢复流程，不会触碰真实的系统回收站
+type FakeTrashManager struct {
+	mu      sync.Mutex
+	root    string
+	ownRoot bool
+	entries map[string]*fakeTrashEntry
+	nextID  int
+	now     func() time.Time
+
+	// Quota 回收站容量上限（字节），0表示不限制
+	Quota int64
+	// OnMoveToTrash 在移动前调用，返回非nil错误时模拟移动失败
+	OnMoveToTrash func(filePath string) error
+	// OnRestore 在恢复前调用，返回非nil错误时模拟恢复失败
+	OnRestore func(trashFile TrashFile, targetPath string) error
+	// OnClear 在清空前调用，返回非nil错误时模拟清空失败
+	OnClear func() error
+}
+
+// fakeTrashEntry 回收站中的单个项目
+type fakeTrashEntry struct {
+	file     TrashFile
+	metadata TrashMetadata
+}
+
+// NewFakeTrashManager 创建测试用回收站管理器，root为空时自动创建临时目录
+func NewFakeTrashManager(root string) (*FakeTrashManager, error) {
+	ownRoot := false
+	if root == "" {
+		tempDir, err := os.MkdirTemp("", "delguard-fake-trash-")
+		if err != nil {
+			return nil, errors.FromOS("创建临时回收站失败", err)
+		}
+		root = tempDir
+		ownRoot = true
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "files"), 0755); err != nil {
+		return nil, errors.FromOS("创建回收站目录失败", err)
+	}
+
+	return &FakeTrashManager{
+		root:    root,
+		ownRoot: ownRoot,
+		entries: make(map[string]*fakeTrashEntry),
+		now:     time.Now,
+	}, nil
+}
+
+// SetClock 替换时间来源，便于生成确定的删除时间
+func (f *FakeTrashManager) SetClock(now func() time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Cleanup 删除自动创建的临时目录
+func (f *FakeTrashManager) Cleanup() error {
+	if !f.ownRoot {
+		return nil
+	}
+	return os.RemoveAll(f.root)
+}
+
+// Metadata 获取指定项目的元数据
+func (f *FakeTrashManager) Metadata(id string) (TrashMetadata, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[id]
+	if !ok {
+		return TrashMetadata{}, false
+	}
+	return entry.metadata, true
+}
+
+// SetPinned 固定或取消固定测试回收站中的项目
+func (f *FakeTrashManager) SetPinned(id string, pinned bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[id]
+	if !ok {
+		return fmt.Errorf("文件不存在于回收站: %s", id)
+	}
+	entry.file.Pinned = pinned
+	entry.metadata.Pinned = pinned
+	return nil
+}
+
+// ReadItemMetadata 读取测试回收站中项目的元数据
+func (f *FakeTrashManager) ReadItemMetadata(file TrashFile) (TrashMetadata, bool) {
+	return f.Metadata(file.ID)
+}
+
+// WriteItemMetadata 更新测试回收站中项目的元数据，元数据只保存在内存中
+func (f *FakeTrashManager) WriteItemMetadata(file TrashFile, metadata TrashMetadata) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[file.ID]
+	if !ok {
+		return fmt.Errorf("文件不存在于回收站: %s", file.ID)
+	}
+	entry.metadata = metadata
+	applyListFlags(&entry.file, &metadata)
+	return nil
+}
+
+// MoveToTrash 将文件移动到测试回收站
+func (f *FakeTrashManager) MoveToTrash(filePath string) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("路径转换失败: %v", err)
+	}
+
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return errors.NewFileNotFoundError(absPath)
+	}
+
+	if err := ValidateTrashSource(f, absPath); err != nil {
+		return err
+	}
+
+	if f.OnMoveToTrash != nil {
+		if err := f.OnMoveToTrash(absPath); err != nil {
+			return err
+		}
+	}
+
+	size := info.Size()
+	if info.IsDir() {
+		size = treeSize(absPath)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Quota > 0 && f.usedLocked()+size > f.Quota {
+		return errors.NewTrashFullError()
+	}
+
+	f.nextID++
+	id := fmt.Sprintf("%06d", f.nextID)
+	trashPath := filepath.Join(f.root, "files", id+"_"+filepath.Base(absPath))
+
+	if err := renameWritable(absPath, trashPath); err != nil {
+		ctx, cancel := newOperationContext(absPath, trashPath)
+		defer cancel()
+		if copyErr := copyTree(ctx, absPath, trashPath); copyErr != nil {
+			os.RemoveAll(trashPath)
+			if err := timeoutError(ctx, absPath); err != nil {
+				return err
+			}
+			return errors.FromOS("移动到回收站失败", copyErr)
+		}
+		if err := removeAllWritable(absPath); err != nil {
+			return errors.FromOS("删除源文件失败", err)
+		}
+	}
+
+	deletedTime := f.now()
+	f.entries[id] = &fakeTrashEntry{
+		file: TrashFile{
+			ID:           id,
+			Name:         filepath.Base(absPath),
+			OriginalPath: absPath,
+			TrashPath:    trashPath,
+			Size:         size,
+			DeletedTime:  deletedTime,
+			IsDirectory:  info.IsDir(),
+			Permissions:  info.Mode().String(),
+		},
+		metadata: TrashMetadata{
+			OriginalPath: absPath,
+			DeletedTime:  deletedTime,
+			FileName:     filepath.Base(absPath),
+			Size:         size,
+			IsDirectory:  info.IsDir(),
+			Permissions:  info.Mode().String(),
+		},
+	}
+	captureFileAttributes(&f.entries[id].metadata, info)
+	if info.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Readlink(trashPath); err == nil {
+			f.entries[id].metadata.LinkTarget = target
+		}
+	}
+
+	return nil
+}
+
+// ImportFile 将sourcePath按给定元数据放入测试回收站
+func (f *FakeTrashManager) ImportFile(sourcePath string, metadata TrashMetadata) error {
+	info, err := os.Lstat(sourcePath)
+	if err != nil {
+		return errors.NewFileNotFoundError(sourcePath)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	id := fmt.Sprintf("%06d", f.nextID)
+	trashPath := filepath.Join(f.root, "files", id+"_"+metadata.FileName)
+	if err := moveIntoTrash(sourcePath, trashPath); err != nil {
+		return err
+	}
+
+	size := metadata.Size
+	if info.IsDir() {
+		size = treeSize(trashPath)
+	}
+	f.entries[id] = &fakeTrashEntry{
+		file: TrashFile{
+			ID:           id,
+			Name:         metadata.FileName,
+			OriginalPath: metadata.OriginalPath,
+			TrashPath:    trashPath,
+			Size:         size,
+			DeletedTime:  metadata.DeletedTime,
+			IsDirectory:  info.IsDir(),
+			Permissions:  metadata.Permissions,
+			Pinned:       metadata.Pinned,
+			Corrupt:      metadata.VerifyResult == VerifyCorrupt,
+		},
+		metadata: metadata,
+	}
+	return nil
+}
+
+// GetTrashPath 获取回收站路径
+func (f *FakeTrashManager) GetTrashPath() (string, error) {
+	return filepath.Join(f.root, "files"), nil
+}
+
+// ListTrashFiles 列出回收站中的文件，按删除时间排序
+func (f *FakeTrashManager) ListTrashFiles() ([]TrashFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	files := make([]TrashFile, 0, len(f.entries))
+	for _, entry := range f.entries {
+		files = append(files, entry.file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].DeletedTime.Equal(files[j].DeletedTime) {
+			return files[i].ID < files[j].ID
+		}
+		return files[i].DeletedTime.Before(files[j].DeletedTime)
+	})
+
+	return files, nil
+}
+
+// ListTrashContents 列出回收站内容（接口实现）
+func (f *FakeTrashManager) ListTrashContents() ([]TrashItem, error) {
+	files, err := f.ListTrashFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TrashItem, len(files))
+	for i, file := range files {
+		items[i] = TrashItem{
+			Name:         file.Name,
+			OriginalPath: file.OriginalPath,
+			Path:         file.TrashPath,
+			Size:         file.Size,
+			DeletedTime:  file.DeletedTime,
+			IsDirectory:  file.IsDirectory,
+			Pinned:       file.Pinned,
+			Corrupt:      file.Corrupt,
+			Compressed:   file.Compressed,
+		}
+	}
+
+	return items, nil
+}
+
+// RestoreFile 从回收站恢复文件，目标已存在时返回冲突错误
+func (f *FakeTrashManager) RestoreFile(trashFile TrashFile, targetPath string) error {
+	f.mu.Lock()
+	entry := f.findLocked(trashFile)
+	f.mu.Unlock()
+	if entry == nil {
+		return fmt.Errorf("回收站文件不存在: %s", trashFile.Name)
+	}
+
+	if targetPath == "" {
+		targetPath = entry.file.OriginalPath
+	}
+	targetPath, err := filepath.Abs(targetPath)
+	if err != nil {
+		return errors.FromOS("获取目标路径失败", err)
+	}
+
+	if err := ValidateRestoreTarget(f, targetPath); err != nil {
+		return err
+	}
+
+	if f.OnRestore != nil {
+		if err := f.OnRestore(entry.file, targetPath); err != nil {
+			return err
+		}
+	}
+
+	if existing, ok := ExistingEntry(targetPath); ok {
+		return fmt.Errorf("目标文件已存在: %s", existing)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return errors.FromOS("创建目标目录失败", err)
+	}
+
+	if err := moveOutOfTrash(entry.file.TrashPath, targetPath, &entry.metadata); err != nil {
+		return errors.FromOS("恢复文件失败", err)
+	}
+
+	if err := applyFileAttributes(targetPath, &entry.metadata); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  警告: %s: %v\n", targetPath, err)
+	}
+
+	f.mu.Lock()
+	delete(f.entries, entry.file.ID)
+	f.mu.Unlock()
+
+	return nil
+}
+
+// RestoreFromTrash 按文件名从回收站恢复文件（接口实现）
+func (f *FakeTrashManager) RestoreFromTrash(fileName string, originalPath string) error {
+	files, err := f.ListTrashFiles()
+	if err != nil {
+		return err
+	}
+
+	var matches []TrashFile
+	for _, file := range files {
+		if file.Name == fileName {
+			matches = append(matches, file)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("文件未找到: %s", fileName)
+	case 1:
+		return f.RestoreFile(matches[0], originalPath)
+	default:
+		return fmt.Errorf("找到多个匹配文件，请使用更精确的文件名或索引: %s", fileName)
+	}
+}
+
+// EmptyTrash 清空回收站
+func (f *FakeTrashManager) EmptyTrash() error {
+	if f.OnClear != nil {
+		if err := f.OnClear(); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	failures := errors.NewErrorCollector()
+	for id, entry := range f.entries {
+		if err := removeAllWritable(entry.file.TrashPath); err != nil {
+			failures.Add(entry.file.TrashPath, err)
+			continue
+		}
+		delete(f.entries, id)
+	}
+
+	return failures.Err()
+}
+
+// Clear 清空回收站（接口实现）
+func (f *FakeTrashManager) Clear() error {
+	return f.EmptyTrash()
+}
+
+// GetTrashStats 获取回收站统计信息
+func (f *FakeTrashManager) GetTrashStats() (*TrashStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := &TrashStats{}
+	var paths []string
+	for _, entry := range f.entries {
+		stats.TotalFiles++
+		stats.TotalSize += entry.file.Size
+		paths = append(paths, entry.file.TrashPath)
+		if entry.file.Pinned {
+			stats.PinnedFiles++
+			stats.PinnedSize += entry.file.Size
+		}
+		if stats.OldestFile.IsZero() || entry.file.DeletedTime.Before(stats.OldestFile) {
+			stats.OldestFile = entry.file.DeletedTime
+		}
+	}
+	stats.DiskSize = trashDiskSize(paths)
+
+	return stats, nil
+}
+
+// GetStats 获取回收站统计信息（统一接口）
+func (f *FakeTrashManager) GetStats() (*TrashStats, error) {
+	return f.GetTrashStats()
+}
+
+// IsEmpty 检查回收站是否为空
+func (f *FakeTrashManager) IsEmpty() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.entries) == 0
+}
+
+// CleanOldFiles 清理超过指定天数的文件
+func (f *FakeTrashManager) CleanOldFiles(maxDays int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	for id, entry := range f.entries {
+		if !entry.file.Pinned && isExpired(entry.file.OriginalPath, entry.file.DeletedTime, maxDays, now) {
+			if err := removeAllWritable(entry.file.TrashPath); err != nil {
+				return fmt.Errorf("删除过期文件失败 %s: %v", entry.file.TrashPath, err)
+			}
+			delete(f.entries, id)
+			MaintenanceThrottle().Pause()
+		}
+	}
+
+	return nil
+}
+
+// ValidateTrash 验证元数据与回收站中的文件一致
+func (f *FakeTrashManager) ValidateTrash() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var missing []string
+	for _, entry := range f.entries {
+		if _, err := os.Lstat(entry.file.TrashPath); err != nil {
+			missing = append(missing, entry.file.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("回收站文件缺失: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// VerifyTrash 检查文件已缺失的内存元数据和files目录中没有元数据的文件
+func (f *FakeTrashManager) VerifyTrash(repair bool) (TrashVerifyReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	report := TrashVerifyReport{OrphanedMetadata: []string{}, OrphanedFiles: []string{}}
+	known := make(map[string]bool)
+	for id, entry := range f.entries {
+		if _, err := os.Lstat(entry.file.TrashPath); err == nil {
+			known[entry.file.TrashPath] = true
+			continue
+		}
+		report.OrphanedMetadata = append(report.OrphanedMetadata, id)
+		if repair {
+			delete(f.entries, id)
+			report.RemovedMetadata++
+		}
+	}
+
+	filesDir := filepath.Join(f.root, "files")
+	dirEntries, err := os.ReadDir(filesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return report, errors.FromOS("读取回收站失败", err)
+	}
+	for _, dirEntry := range dirEntries {
+		trashPath := filepath.Join(filesDir, dirEntry.Name())
+		// 与系统回收站后端一致，跳过压缩临时文件等隐藏文件
+		if strings.HasPrefix(dirEntry.Name(), ".") {
+			continue
+		}
+		report.Checked++
+		if known[trashPath] {
+			continue
+		}
+		report.OrphanedFiles = append(report.OrphanedFiles, trashPath)
+		if !repair {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("补建元数据失败 %s: %v", trashPath, err))
+			continue
+		}
+		metadata := synthesizeMetadata(dirEntry.Name(), info)
+		f.entries[dirEntry.Name()] = &fakeTrashEntry{
+			file: TrashFile{
+				ID:          dirEntry.Name(),
+				Name:        dirEntry.Name(),
+				TrashPath:   trashPath,
+				Size:        info.Size(),
+				DeletedTime: metadata.DeletedTime,
+				IsDirectory: info.IsDir(),
+				Permissions: metadata.Permissions,
+			},
+			metadata: metadata,
+		}
+		report.CreatedMetadata++
+	}
+
+	sort.Strings(report.OrphanedMetadata)
+	sort.Strings(report.OrphanedFiles)
+	return report, nil
+}
+
+// findLocked 按ID或回收站路径查找项目，调用方需持有锁
+func (f *FakeTrashManager) findLocked(trashFile TrashFile) *fakeTrashEntry {
+	if entry, ok := f.entries[trashFile.ID]; ok {
+		return entry
+	}
+	for _, entry := range f.entries {
+		if trashFile.TrashPath != "" && entry.file.TrashPath == trashFile.TrashPath {
+			return entry
+		}
+	}
+	return nil
+}
+
+// usedLocked 计算已用容量，调用方需持有锁
+func (f *FakeTrashManager) usedLocked() int64 {
+	var used int64
+	for _, entry := range f.entries {
+		used += entry.file.Size
+	}
+	return used
+}
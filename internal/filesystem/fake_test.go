@@ -0,0 +1,124 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+func TestFakeTrashManagerRestoreConflict(t *testing.T) {
+	manager, _ := newGuardManager(t)
+	path := writeContractFile(t, "conflict.txt", "old")
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := onlyTrashFile(t, manager)
+	if err := manager.RestoreFile(file, path); err == nil {
+		t.Fatal("RestoreFile overwrote an existing file")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("existing file changed to %q", data)
+	}
+	if files, _ := manager.ListTrashFiles(); len(files) != 1 {
+		t.Errorf("item left the trash after a failed restore: %d items", len(files))
+	}
+}
+
+func TestFakeTrashManagerQuota(t *testing.T) {
+	manager, _ := newGuardManager(t)
+	manager.Quota = 6
+	if err := manager.MoveToTrash(writeContractFile(t, "a.txt", "1234")); err != nil {
+		t.Fatalf("MoveToTrash within quota: %v", err)
+	}
+	path := writeContractFile(t, "b.txt", "1234")
+	if err := manager.MoveToTrash(path); !errors.IsType(err, errors.ErrTypeTrashFull) {
+		t.Errorf("MoveToTrash over quota = %v, want a trash full error", err)
+	}
+	if _, err := os.Lstat(path); err != nil {
+		t.Errorf("source was removed although the trash is full: %v", err)
+	}
+}
+
+func TestFakeTrashManagerHooks(t *testing.T) {
+	manager, _ := newGuardManager(t)
+	manager.OnMoveToTrash = func(path string) error {
+		return errors.NewDiskFullError(path)
+	}
+	path := writeContractFile(t, "disk.txt", "x")
+	if err := manager.MoveToTrash(path); !errors.IsType(err, errors.ErrTypeDiskFull) {
+		t.Errorf("MoveToTrash = %v, want the disk full error from the hook", err)
+	}
+	if _, err := os.Lstat(path); err != nil {
+		t.Errorf("source was removed although the hook failed the move: %v", err)
+	}
+
+	manager.OnMoveToTrash = nil
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	manager.OnRestore = func(TrashFile, string) error {
+		return errors.NewError(errors.ErrTypePermissionDenied, "simulated", nil)
+	}
+	if err := manager.RestoreFile(onlyTrashFile(t, manager), path); !errors.IsType(err, errors.ErrTypePermissionDenied) {
+		t.Errorf("RestoreFile = %v, want the error from the hook", err)
+	}
+	manager.OnClear = func() error {
+		return errors.NewError(errors.ErrTypeIO, "simulated", nil)
+	}
+	if err := manager.EmptyTrash(); !errors.IsType(err, errors.ErrTypeIO) {
+		t.Errorf("EmptyTrash = %v, want the error from the hook", err)
+	}
+}
+
+func TestFakeTrashManagerMetadata(t *testing.T) {
+	manager, _ := newGuardManager(t)
+	deleted := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	manager.SetClock(func() time.Time { return deleted })
+
+	path := writeContractFile(t, "meta.txt", "metadata")
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	file := onlyTrashFile(t, manager)
+	if !file.DeletedTime.Equal(deleted) {
+		t.Errorf("DeletedTime = %v, want the injected clock %v", file.DeletedTime, deleted)
+	}
+	metadata, ok := manager.Metadata(file.ID)
+	if !ok {
+		t.Fatal("no metadata for the trashed item")
+	}
+	if metadata.OriginalPath != path || metadata.FileName != "meta.txt" || metadata.Size != 8 {
+		t.Errorf("metadata = %+v", metadata)
+	}
+
+	if err := manager.SetPinned(file.ID, true); err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	if !onlyTrashFile(t, manager).Pinned {
+		t.Error("pinned item is not listed as pinned")
+	}
+}
+
+func TestFakeTrashManagerCleanup(t *testing.T) {
+	manager, err := NewFakeTrashManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := manager.GetTrashPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Cleanup(); err != nil {
+		t.Fatalf("Cleanup: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(root)); !os.IsNotExist(err) {
+		t.Errorf("Cleanup left the temporary trash %s: %v", root, err)
+	}
+}
@@ -0,0 +1,232 @@
+package filesystem
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileFilter 按gitignore风格的模式排除删除目标中的条目
+// 模式按加入的顺序匹配，最后一个匹配的模式生效；以!开头的模式重新包含之前排除的条目，
+// 以/结尾的模式只匹配目录，不含/（末尾除外）的模式匹配任意层级的名称，其余模式相对于删除目标的目录
+type FileFilter struct {
+	rules []filterRule
+}
+
+// filterRule 一条排除模式
+type filterRule struct {
+	pattern string
+	re      *regexp.Regexp
+	negate  bool // 以!开头，匹配时重新包含
+	dirOnly bool // 以/结尾，只匹配目录
+}
+
+// NewFileFilter 创建不排除任何条目的过滤器
+func NewFileFilter() *FileFilter {
+	return &FileFilter{}
+}
+
+// Empty 过滤器中没有模式
+func (f *FileFilter) Empty() bool {
+	return f == nil || len(f.rules) == 0
+}
+
+// Add 加入一条模式，空行和以#开头的注释被忽略，\#和\!表示字面的#和!
+func (f *FileFilter) Add(pattern string) error {
+	pattern = strings.TrimRight(pattern, "\r")
+	// 末尾未转义的空格被忽略
+	for strings.HasSuffix(pattern, " ") && !strings.HasSuffix(pattern, `\ `) {
+		pattern = pattern[:len(pattern)-1]
+	}
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return nil
+	}
+
+	rule := filterRule{pattern: pattern}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\#`) || strings.HasPrefix(pattern, `\!`) {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return fmt.Errorf("无效的排除模式: %q", rule.pattern)
+	}
+
+	// 不含/的模式匹配任意层级，含/的模式相对于删除目标的目录
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	expr, err := globToRegexp(pattern)
+	if err != nil {
+		return fmt.Errorf("无效的排除模式 %q: %v", rule.pattern, err)
+	}
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	if rule.re, err = regexp.Compile("^" + expr + "$"); err != nil {
+		return fmt.Errorf("无效的排除模式 %q: %v", rule.pattern, err)
+	}
+	f.rules = append(f.rules, rule)
+	return nil
+}
+
+// LoadFile 从文件中逐行读取模式，格式与.gitignore相同
+func (f *FileFilter) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if err := f.Add(scanner.Text()); err != nil {
+			return fmt.Errorf("%s 第 %d 行: %v", path, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Excluded 判断相对于删除目标目录的路径rel（以/分隔）是否被排除
+func (f *FileFilter) Excluded(rel string, isDir bool) bool {
+	if f.Empty() {
+		return false
+	}
+	excluded := false
+	for _, rule := range f.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
+
+// globToRegexp 将gitignore风格的通配符转换为正则表达式
+// *和?不匹配/，**匹配任意层级的目录，[...]为字符集合，[!...]为取反的集合
+func globToRegexp(pattern string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**") && i+2 == len(pattern):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("缺少 ]")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return b.String(), nil
+}
+
+// ExcludePlan 按过滤器展开删除目标的结果
+type ExcludePlan struct {
+	// Targets 实际删除的路径：没有被排除内容的目录整体删除，其余目录只删除未被排除的条目
+	Targets []string
+	// Excluded 被排除而保留的条目数，被排除的目录只计一次
+	Excluded int
+}
+
+// ApplyExcludes 按过滤器展开删除目标，被排除的条目及其所在的目录保留
+// 目标本身按名称匹配，目录中的条目按相对于该目录的路径匹配；被排除的目录不再深入
+func ApplyExcludes(targets []string, filter *FileFilter) (ExcludePlan, error) {
+	plan := ExcludePlan{Targets: []string{}}
+	for _, target := range targets {
+		info, err := Lstat(target)
+		if err != nil {
+			plan.Targets = append(plan.Targets, target)
+			continue
+		}
+		if filter.Excluded(filepath.Base(target), info.IsDir()) {
+			plan.Excluded++
+			continue
+		}
+		if !info.IsDir() {
+			plan.Targets = append(plan.Targets, target)
+			continue
+		}
+		whole, err := plan.expand(target, "", filter)
+		if err != nil {
+			return plan, err
+		}
+		if whole {
+			plan.Targets = append(plan.Targets, target)
+		}
+	}
+	return plan, nil
+}
+
+// expand 检查目录dir中的条目（rel为dir相对于删除目标的路径），
+// 没有条目被排除时返回true由调用方整体删除dir，否则把未被排除的条目加入Targets
+func (p *ExcludePlan) expand(dir, rel string, filter *FileFilter) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	var deletable []string
+	whole := true
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		entryRel := entry.Name()
+		if rel != "" {
+			entryRel = rel + "/" + entry.Name()
+		}
+		isDir := entry.IsDir()
+		if filter.Excluded(entryRel, isDir) {
+			p.Excluded++
+			whole = false
+			continue
+		}
+		if !isDir {
+			deletable = append(deletable, path)
+			continue
+		}
+		subWhole, err := p.expand(path, entryRel, filter)
+		if err != nil {
+			return false, err
+		}
+		if subWhole {
+			deletable = append(deletable, path)
+		} else {
+			whole = false
+		}
+	}
+
+	if !whole {
+		p.Targets = append(p.Targets, deletable...)
+	}
+	return whole, nil
+}
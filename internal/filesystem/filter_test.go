@@ -0,0 +1,217 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newTestFilter 按顺序加入patterns创建过滤器
+func newTestFilter(t *testing.T, patterns ...string) *FileFilter {
+	t.Helper()
+	filter := NewFileFilter()
+	for _, pattern := range patterns {
+		if err := filter.Add(pattern); err != nil {
+			t.Fatalf("Add(%q): %v", pattern, err)
+		}
+	}
+	return filter
+}
+
+func TestFileFilterExcluded(t *testing.T) {
+	type check struct {
+		rel   string
+		isDir bool
+		want  bool
+	}
+	tests := []struct {
+		name     string
+		patterns []string
+		checks   []check
+	}{
+		{"name at any level", []string{"*.log"}, []check{
+			{"a.log", false, true}, {"sub/deep/a.log", false, true}, {"a.txt", false, false}, {"logs", true, false},
+		}},
+		{"directory only", []string{"build/"}, []check{
+			{"build", true, true}, {"src/build", true, true}, {"build", false, false}, {"build.txt", false, false},
+		}},
+		{"anchored with leading slash", []string{"/build"}, []check{
+			{"build", true, true}, {"build", false, true}, {"src/build", true, false},
+		}},
+		{"anchored by inner slash", []string{"docs/*.md"}, []check{
+			{"docs/a.md", false, true}, {"docs/sub/a.md", false, false}, {"other/docs/a.md", false, false},
+		}},
+		{"leading double star", []string{"**/tmp"}, []check{
+			{"tmp", true, true}, {"a/b/tmp", false, true}, {"a/tmpx", false, false},
+		}},
+		{"trailing double star", []string{"logs/**"}, []check{
+			{"logs/a", false, true}, {"logs/a/b.txt", false, true}, {"other/logs/a", false, false},
+		}},
+		{"single character", []string{"?.tmp"}, []check{
+			{"a.tmp", false, true}, {"ab.tmp", false, false},
+		}},
+		{"negated class", []string{"[!a]*.c"}, []check{
+			{"b.c", false, true}, {"a.c", false, false},
+		}},
+		{"negated directory re-includes", []string{"*", "!keep/"}, []check{
+			{"keep", true, false}, {"keep", false, true}, {"other", true, true}, {"sub/keep", true, false},
+		}},
+		{"negated file re-includes", []string{"*.txt", "!important.txt"}, []check{
+			{"a.txt", false, true}, {"important.txt", false, false}, {"sub/important.txt", false, false},
+		}},
+		{"last match wins", []string{"!keep/", "*"}, []check{
+			{"keep", true, true},
+		}},
+		{"escaped hash and bang", []string{`\#notes`, `\!bang`}, []check{
+			{"#notes", false, true}, {"!bang", false, true},
+		}},
+		{"trailing spaces ignored", []string{"foo  "}, []check{
+			{"foo", false, true}, {"foo  ", false, false},
+		}},
+		{"escaped trailing space kept", []string{`foo\ `}, []check{
+			{"foo ", false, true}, {"foo", false, false},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newTestFilter(t, tt.patterns...)
+			for _, c := range tt.checks {
+				if got := filter.Excluded(c.rel, c.isDir); got != c.want {
+					t.Errorf("%q: Excluded(%q, dir=%v) = %v, want %v", tt.patterns, c.rel, c.isDir, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileFilterIgnoresCommentsAndRejectsBadPatterns(t *testing.T) {
+	filter := newTestFilter(t, "", "# comment", "   ", "\r")
+	if !filter.Empty() || filter.Excluded("# comment", false) {
+		t.Error("comments and blank lines added rules")
+	}
+	var nilFilter *FileFilter
+	if !nilFilter.Empty() || nilFilter.Excluded("a", false) {
+		t.Error("a nil filter must exclude nothing")
+	}
+
+	for _, pattern := range []string{"[abc", "/", "!", "!/"} {
+		if err := NewFileFilter().Add(pattern); err == nil {
+			t.Errorf("Add(%q) accepted an invalid pattern", pattern)
+		}
+	}
+}
+
+func TestFileFilterLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".delguardignore")
+	content := "# build output\r\n*.o\r\n\r\n!keep/\r\nsecrets/\r\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filter := NewFileFilter()
+	if err := filter.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if !filter.Excluded("main.o", false) || !filter.Excluded("secrets", true) || filter.Excluded("keep", true) {
+		t.Errorf("patterns from %s were not applied: %+v", path, filter.rules)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	if err := os.WriteFile(bad, []byte("*.o\n[broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFileFilter().LoadFile(bad); err == nil || !strings.Contains(err.Error(), "第 2 行") {
+		t.Errorf("LoadFile(bad) = %v, want the line number of the broken pattern", err)
+	}
+	if err := NewFileFilter().LoadFile(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("LoadFile(missing) = %v, want a not-exist error", err)
+	}
+}
+
+// excludeTree 在root下创建文件，names以/分隔
+func excludeTree(t *testing.T, root string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestApplyExcludes(t *testing.T) {
+	root := t.TempDir()
+	excludeTree(t, root,
+		"build/a.o", "build/notes.md", "build/keep/x.txt", "build/sub/b.o", "build/sub/c.o",
+		"objs/only.o", "objs/more.o", "single.log")
+	p := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+
+	tests := []struct {
+		name         string
+		targets      []string
+		patterns     []string
+		wantTargets  []string
+		wantExcluded int
+	}{
+		{
+			name:        "no match deletes whole directory",
+			targets:     []string{p("build")},
+			patterns:    []string{"*.tmp"},
+			wantTargets: []string{p("build")},
+		},
+		{
+			name:         "excluded directory keeps its parent",
+			targets:      []string{p("build")},
+			patterns:     []string{"keep/"},
+			wantTargets:  []string{p("build/a.o"), p("build/notes.md"), p("build/sub")},
+			wantExcluded: 1,
+		},
+		{
+			name:         "everything but a negated directory",
+			targets:      []string{p("build")},
+			patterns:     []string{"*.o", "*.md", "*.txt", "!keep/", "!x.txt"},
+			wantTargets:  []string{p("build/keep")},
+			wantExcluded: 4,
+		},
+		{
+			// 目录中的条目全部被排除时目录本身也保留
+			name:         "fully excluded directory is skipped",
+			targets:      []string{p("objs"), p("build/notes.md")},
+			patterns:     []string{"*.o"},
+			wantTargets:  []string{p("build/notes.md")},
+			wantExcluded: 2,
+		},
+		{
+			name:         "nested entries",
+			targets:      []string{p("build")},
+			patterns:     []string{"sub/b.o"},
+			wantTargets:  []string{p("build/a.o"), p("build/keep"), p("build/notes.md"), p("build/sub/c.o")},
+			wantExcluded: 1,
+		},
+		{
+			name:         "target excluded by name",
+			targets:      []string{p("single.log"), p("missing.txt")},
+			patterns:     []string{"*.log"},
+			wantTargets:  []string{p("missing.txt")},
+			wantExcluded: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := ApplyExcludes(tt.targets, newTestFilter(t, tt.patterns...))
+			if err != nil {
+				t.Fatalf("ApplyExcludes: %v", err)
+			}
+			sort.Strings(plan.Targets)
+			if !reflect.DeepEqual(plan.Targets, tt.wantTargets) || plan.Excluded != tt.wantExcluded {
+				t.Errorf("ApplyExcludes() = %q, %d excluded; want %q, %d", plan.Targets, plan.Excluded, tt.wantTargets, tt.wantExcluded)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"delguard/internal/errors"
+	"delguard/internal/utils"
+)
+
+// volumeTrashNames 系统在卷根目录下创建的回收站目录名（小写）：Windows的$RECYCLE.BIN和macOS的.Trashes，
+// 只在卷根目录下才视为回收站；当前用户的XDG回收站.Trash-<uid>由volumeTrashName另外判断
+var volumeTrashNames = []string{
+	"$recycle.bin",
+	".trashes",
+}
+
+// IsInsideTrash 检查路径是否位于回收站之内：管理器和DelGuard的回收站、登记过的共享回收站，
+// 以及卷根目录下的系统回收站；普通目录中名为.trash等的子目录不算，其中的文件仍按普通文件删除
+func IsInsideTrash(manager TrashManager, path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	resolved := ResolveExistingPath(absPath)
+
+	for _, root := range trashRoots(manager) {
+		if isSubPath(root, absPath) || isSubPath(root, resolved) {
+			return true
+		}
+	}
+
+	// 各卷上的系统回收站无法逐一登记，只识别卷根目录下的回收站目录
+	return inVolumeTrash(absPath) || inVolumeTrash(resolved)
+}
+
+// ValidateRestoreTarget 检查恢复目标不在回收站内部
+func ValidateRestoreTarget(manager TrashManager, targetPath string) error {
+	if !IsInsideTrash(manager, targetPath) {
+		return nil
+	}
+	return errors.NewError(errors.ErrTypeInvalidPath,
+		fmt.Sprintf("不能恢复到回收站内部: %s，请指定回收站之外的目标路径", targetPath), nil)
+}
+
+// ValidateTrashSource 检查待删除的路径不在回收站内部
+func ValidateTrashSource(manager TrashManager, sourcePath string) error {
+	if !IsInsideTrash(manager, sourcePath) {
+		return nil
+	}
+	return errors.NewAlreadyInTrashError(sourcePath)
+}
+
+// RemoveFromTrash 永久删除位于回收站中的项目，并清理其元数据
+func RemoveFromTrash(manager TrashManager, path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("路径转换失败: %v", err)
+	}
+	if !IsInsideTrash(manager, absPath) {
+		return fmt.Errorf("文件不在回收站中: %s", absPath)
+	}
+
+	if err := removeTrashEntry(absPath); err != nil {
+		return fmt.Errorf("永久删除失败: %v", err)
+	}
+	return nil
+}
+
+// removeTrashEntry 删除回收站中的项目，成功后立即清理其.trashinfo和元数据，
+// 失败时保留元数据，项目仍可在回收站中列出
+func removeTrashEntry(path string) error {
+	if err := removeAllWritable(path); err != nil {
+		return err
+	}
+	removeTrashMetadata(path)
+	return nil
+}
+
+// removeTrashMetadata 清理回收站项目可能存在的.trashinfo和元数据文件，忽略错误
+func removeTrashMetadata(path string) {
+	name := filepath.Base(path)
+	dir := filepath.Dir(path)
+	os.Remove(filepath.Join(filepath.Dir(dir), "info", name+".trashinfo"))
+	os.Remove(filepath.Join(dir, ".metadata", name+".json"))
+	os.Remove(filepath.Join(dir, ".delguard_metadata", name+".json"))
+}
+
+// trashRoots 获取管理器使用的回收站根目录
+func trashRoots(manager TrashManager) []string {
+	var roots []string
+
+	if manager != nil {
+		if trashPath, err := manager.GetTrashPath(); err == nil && trashPath != "" {
+			roots = append(roots, trashPath)
+			// XDG结构中files与info目录同级，整个Trash目录都视为回收站
+			if filepath.Base(trashPath) == "files" {
+				roots = append(roots, filepath.Dir(trashPath))
+			}
+		}
+	}
+
+	if root, err := delguardTrashRoot(); err == nil {
+		roots = append(roots, root)
+	}
+	roots = append(roots, KnownShareTrashes()...)
+
+	result := make([]string, 0, len(roots)*2)
+	for _, root := range roots {
+		if absRoot, err := filepath.Abs(root); err == nil {
+			result = append(result, absRoot, ResolveExistingPath(absRoot))
+		}
+	}
+	return result
+}
+
+// ResolveExistingPath 解析路径中已存在部分的符号链接，不存在的部分原样拼接
+func ResolveExistingPath(path string) string {
+	path = filepath.Clean(path)
+	rest := ""
+	for current := path; ; current = filepath.Dir(current) {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+		rest = filepath.Join(filepath.Base(current), rest)
+	}
+}
+
+// ContainsPath 检查path是否等于root或位于root之下，不区分大小写的卷上忽略大小写
+func ContainsPath(root, path string) bool {
+	return isSubPath(root, path)
+}
+
+// isSubPath 检查path是否等于root或位于root之下
+func isSubPath(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		if !strings.EqualFold(filepath.VolumeName(root), filepath.VolumeName(path)) {
+			return false
+		}
+	}
+	// filepath.Rel区分大小写，不区分大小写的卷上按小写比较
+	if rel != "." && utils.CaseInsensitive(root) {
+		if folded, err := filepath.Rel(strings.ToLower(root), strings.ToLower(path)); err == nil {
+			rel = folded
+		}
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)))
+}
+
+// inVolumeTrash 检查路径是否位于所在卷根目录下的回收站中，找不到挂载点时以卷的根目录为准
+func inVolumeTrash(path string) bool {
+	root := filepath.VolumeName(path) + string(filepath.Separator)
+	if mount, err := mountLookup(path); err == nil && mount.MountPoint != "" {
+		root = mount.MountPoint
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return volumeTrashName(strings.SplitN(filepath.ToSlash(rel), "/", 2)[0])
+}
+
+// volumeTrashName 检查卷根目录下的目录名是否为回收站：系统回收站目录或当前用户的.Trash-<uid>
+func volumeTrashName(name string) bool {
+	name = strings.ToLower(name)
+	for _, trashName := range volumeTrashNames {
+		if name == trashName {
+			return true
+		}
+	}
+	uid := os.Getuid()
+	return uid >= 0 && name == fmt.Sprintf(".trash-%d", uid)
+}
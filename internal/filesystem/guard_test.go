@@ -0,0 +1,186 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"delguard/internal/errors"
+)
+
+// newGuardManager 创建回收站位于临时目录中的测试管理器，返回管理器和回收站根目录
+func newGuardManager(t *testing.T) (*FakeTrashManager, string) {
+	t.Helper()
+	useTempHome(t)
+	root := t.TempDir()
+	manager, err := NewFakeTrashManager(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager, root
+}
+
+func TestIsInsideTrash(t *testing.T) {
+	manager, root := newGuardManager(t)
+	outside := t.TempDir()
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{root, true},
+		{filepath.Join(root, "files", "000001_report.txt"), true},
+		{filepath.Join(root, "files", "dir", "nested", "deep.txt"), true},
+		{filepath.Join(outside, ".delguard_metadata", "x.json"), false},
+		{filepath.Join(outside, ".Trash-1000", "files", "a"), false},
+		{filepath.Join(outside, "$RECYCLE.BIN", "S-1-5", "a"), false},
+		{filepath.Join(outside, "report.txt"), false},
+		{root + "-sibling", false},
+	}
+	for _, tt := range tests {
+		if got := IsInsideTrash(manager, tt.path); got != tt.want {
+			t.Errorf("IsInsideTrash(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIsInsideTrashVolumeRoots(t *testing.T) {
+	manager, _ := newGuardManager(t)
+	volume := t.TempDir()
+	stubMounts(t, func(path string) (MountInfo, error) {
+		if isSubPath(volume, path) {
+			return MountInfo{MountPoint: volume, FSType: "vfat"}, nil
+		}
+		return MountInfo{MountPoint: string(filepath.Separator), FSType: "ext4"}, nil
+	})
+	userTrash := fmt.Sprintf(".Trash-%d", os.Getuid())
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(volume, "$RECYCLE.BIN", "S-1-5-21", "$R1.txt"), true},
+		{filepath.Join(volume, ".Trashes", "501", "a"), true},
+		{filepath.Join(volume, "photos", "$RECYCLE.BIN", "a"), false},
+		{filepath.Join(volume, ".trash", "note.md"), false},
+		{filepath.Join(volume, "vault", ".trash", "note.md"), false},
+		{filepath.Join(volume, "vault", ".Trash-1000", "files", "a"), false},
+		{filepath.Join(volume, ".Trash-99999", "files", "a"), false},
+		{volume, false},
+	}
+	if runtime.GOOS != "windows" {
+		tests = append(tests, struct {
+			path string
+			want bool
+		}{filepath.Join(volume, userTrash, "files", "a"), true})
+	}
+	for _, tt := range tests {
+		if got := IsInsideTrash(manager, tt.path); got != tt.want {
+			t.Errorf("IsInsideTrash(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestUserTrashDirectoryIsNotTrash 笔记软件等在普通目录中使用的.trash目录不是回收站，
+// 其中的文件按普通文件移入回收站，不会被永久删除，旁边的info目录也不受影响
+func TestUserTrashDirectoryIsNotTrash(t *testing.T) {
+	manager, _ := newGuardManager(t)
+	vault := t.TempDir()
+	note := filepath.Join(vault, ".trash", "note.md")
+	info := filepath.Join(vault, "info", "note.md.trashinfo")
+	for _, path := range []string{note, info} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("keep me"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ValidateTrashSource(manager, note); err != nil {
+		t.Errorf("ValidateTrashSource(%s) = %v, want an ordinary file", note, err)
+	}
+	if err := RemoveFromTrash(manager, note); err == nil {
+		t.Error("RemoveFromTrash permanently deleted a file in a user directory named .trash")
+	}
+	for _, path := range []string{note, info} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+
+	if err := manager.MoveToTrash(note); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	if file := onlyTrashFile(t, manager); file.OriginalPath != note {
+		t.Errorf("trashed %s, want %s", file.OriginalPath, note)
+	}
+}
+
+func TestIsInsideTrashThroughSymlink(t *testing.T) {
+	manager, root := newGuardManager(t)
+	link := filepath.Join(t.TempDir(), "trash-link")
+	if err := os.Symlink(filepath.Join(root, "files"), link); err != nil {
+		t.Skipf("symlinks not available: %v", err)
+	}
+	if !IsInsideTrash(manager, filepath.Join(link, "item.txt")) {
+		t.Error("a path reached through a symlink into the trash was not detected")
+	}
+}
+
+func TestRestoreIntoTrashIsRejected(t *testing.T) {
+	manager, root := newGuardManager(t)
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	file := onlyTrashFile(t, manager)
+
+	for _, target := range []string{
+		filepath.Join(root, "files", "restored.txt"),
+		filepath.Join(root, "files", ".delguard_metadata", "restored.txt"),
+	} {
+		err := manager.RestoreFile(file, target)
+		if !errors.IsType(err, errors.ErrTypeInvalidPath) {
+			t.Errorf("RestoreFile(%s) = %v, want an invalid path error", target, err)
+		}
+	}
+	if err := manager.RestoreFile(file, path); err != nil {
+		t.Errorf("RestoreFile to the original path: %v", err)
+	}
+}
+
+func TestMoveToTrashRefusesTrashedSource(t *testing.T) {
+	manager, root := newGuardManager(t)
+	inside := filepath.Join(root, "files", "already.txt")
+	if err := os.WriteFile(inside, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.MoveToTrash(inside); !errors.IsType(err, errors.ErrTypeAlreadyInTrash) {
+		t.Errorf("MoveToTrash of a trashed item = %v, want an already-in-trash error", err)
+	}
+	if err := RemoveFromTrash(manager, inside); err != nil {
+		t.Fatalf("RemoveFromTrash: %v", err)
+	}
+	if _, err := os.Lstat(inside); !os.IsNotExist(err) {
+		t.Errorf("RemoveFromTrash left the item behind: %v", err)
+	}
+}
+
+func TestRemoveFromTrashRefusesOutsidePaths(t *testing.T) {
+	manager, _ := newGuardManager(t)
+	path := filepath.Join(t.TempDir(), "keep.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveFromTrash(manager, path); err == nil {
+		t.Error("RemoveFromTrash deleted a file outside the trash")
+	}
+	if _, err := os.Lstat(path); err != nil {
+		t.Errorf("file outside the trash is gone: %v", err)
+	}
+}
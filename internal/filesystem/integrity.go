@@ -0,0 +1,182 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 回收站项目内容校验的结果
+const (
+	VerifyOK       = "ok"
+	VerifyCorrupt  = "corrupt"
+	VerifyUnhashed = "unhashed" // 目录、链接等没有内容哈希的项目，或删除时未记录哈希的文件
+)
+
+// IntegrityOptions 内容校验选项
+type IntegrityOptions struct {
+	// All 忽略上次校验时间，重新校验所有项目
+	All bool
+	// Interval 上次校验距今超过此时长的项目才会再次校验
+	Interval time.Duration
+	// Workers 并行计算哈希的数量，小于1时按1处理
+	Workers int
+}
+
+// IntegrityReport 内容校验结果，OK/Corrupt/Unhashed统计回收站中所有项目，
+// 本次跳过的项目按上次校验的结果计入
+type IntegrityReport struct {
+	Checked  int      `json:"checked"` // 本次重新计算哈希的项目数
+	Skipped  int      `json:"skipped"` // 在校验间隔内已校验过而跳过的项目数
+	OK       int      `json:"ok"`
+	Corrupt  []string `json:"corrupt"` // 哈希不一致的项目在回收站中的路径
+	Unhashed int      `json:"unhashed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// integrityJob 一个待校验的项目
+type integrityJob struct {
+	file     TrashFile
+	metadata TrashMetadata
+	result   string
+	hash     string
+	err      error
+}
+
+// VerifyIntegrity 重新计算回收站项目的SHA256并与元数据中的哈希比对，结果和时间写回元数据
+// 删除时没有记录哈希的文件在首次校验时记录基线哈希，之后的校验与基线比对
+// 哈希计算按维护任务限速器限速
+func VerifyIntegrity(manager TrashManager, opts IntegrityOptions) (IntegrityReport, error) {
+	report := IntegrityReport{Corrupt: []string{}}
+
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return report, err
+	}
+
+	now := time.Now()
+	var due []*integrityJob
+	for _, file := range files {
+		metadata := LoadMetadata(manager, file)
+		if !opts.All && metadata.LastVerified != nil && now.Sub(*metadata.LastVerified) < opts.Interval {
+			report.Skipped++
+			report.count(metadata.VerifyResult, file.TrashPath)
+			continue
+		}
+		due = append(due, &integrityJob{file: file, metadata: metadata})
+	}
+
+	runIntegrityJobs(due, opts.Workers)
+
+	// 元数据按顺序写回，避免并发写入同一目录
+	for _, job := range due {
+		if job.err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", job.file.Name, job.err))
+			continue
+		}
+		report.Checked++
+		report.count(job.result, job.file.TrashPath)
+
+		metadata := job.metadata
+		if metadata.Hash == "" && job.hash != "" {
+			metadata.Hash = job.hash
+		}
+		verified := now
+		metadata.LastVerified = &verified
+		metadata.VerifyResult = job.result
+		if err := saveMetadata(manager, job.file, metadata); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", job.file.Name, err))
+		}
+	}
+
+	sort.Strings(report.Corrupt)
+	return report, nil
+}
+
+// runIntegrityJobs 用workers个并行任务计算各项目的哈希并与元数据比对，workers小于1时按1处理
+// 哈希计算按维护任务限速器限速
+func runIntegrityJobs(due []*integrityJob, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan *integrityJob)
+	var wg sync.WaitGroup
+	throttle := MaintenanceThrottle()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.result, job.hash, job.err = checkIntegrity(job.file.TrashPath, job.metadata)
+				throttle.Pause()
+			}
+		}()
+	}
+	for _, job := range due {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// count 按校验结果计数
+func (r *IntegrityReport) count(result string, trashPath string) {
+	switch result {
+	case VerifyOK:
+		r.OK++
+	case VerifyCorrupt:
+		r.Corrupt = append(r.Corrupt, trashPath)
+	default:
+		r.Unhashed++
+	}
+}
+
+// checkIntegrity 计算回收站项目的哈希并与元数据比对，返回结果和计算出的哈希
+// 只校验普通文件，目录、符号链接和特殊文件的占位项目视为没有哈希
+func checkIntegrity(trashPath string, metadata TrashMetadata) (string, string, error) {
+	info, err := os.Lstat(trashPath)
+	if err != nil {
+		return "", "", err
+	}
+	if !info.Mode().IsRegular() || metadata.SpecialType != "" || metadata.LinkTarget != "" {
+		return VerifyUnhashed, "", nil
+	}
+
+	hash, err := hashFileThrottled(trashPath, &metadata)
+	if err != nil {
+		return "", "", err
+	}
+	switch {
+	case metadata.Hash == "":
+		return VerifyUnhashed, hash, nil
+	case metadata.Hash == hash:
+		return VerifyOK, hash, nil
+	default:
+		return VerifyCorrupt, hash, nil
+	}
+}
+
+// hashFileThrottled 按维护任务限速器读取文件并计算SHA256，已压缩的项目计算解压后内容的哈希
+func hashFileThrottled(path string, metadata *TrashMetadata) (string, error) {
+	file, err := openPayload(path, metadata)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, MaintenanceThrottle().Reader(file)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// saveMetadata 将元数据写回回收站项目
+func saveMetadata(manager TrashManager, file TrashFile, metadata TrashMetadata) error {
+	return manager.WriteItemMetadata(file, metadata)
+}
@@ -0,0 +1,178 @@
+package filesystem
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// compressibleContent 足够大且可压缩的内容，trash compact会压缩它
+var compressibleContent = strings.Repeat("delguard keeps deleted files ", 400)
+
+// sha256Hex 返回content的SHA256
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// gzipBytes 返回content压缩后的gzip数据
+func gzipBytes(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// trashFileNamed 返回回收站中名为name的项目
+func trashFileNamed(t *testing.T, manager TrashManager, name string) TrashFile {
+	t.Helper()
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		t.Fatalf("ListTrashFiles: %v", err)
+	}
+	for _, file := range files {
+		if file.Name == name {
+			return file
+		}
+	}
+	t.Fatalf("%s is not in the trash", name)
+	return TrashFile{}
+}
+
+// compactAll 压缩回收站中所有可压缩的项目
+func compactAll(t *testing.T, manager TrashManager) CompactReport {
+	t.Helper()
+	report, err := CompactTrash(manager, CompactOptions{OlderThan: -time.Hour, Level: gzip.BestSpeed})
+	if err != nil {
+		t.Fatalf("CompactTrash: %v", err)
+	}
+	if len(report.Errors) > 0 {
+		t.Fatalf("CompactTrash errors: %v", report.Errors)
+	}
+	return report
+}
+
+func TestVerifyIntegrityDetectsChangedContent(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			if err := manager.MoveToTrash(writeContractFile(t, "notes.txt", "original")); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+
+			// 删除时没有记录哈希的后端在首次校验时记录基线
+			report, err := VerifyIntegrity(manager, IntegrityOptions{All: true})
+			if err != nil {
+				t.Fatalf("VerifyIntegrity: %v", err)
+			}
+			if report.Checked != 1 || len(report.Corrupt) != 0 || len(report.Errors) != 0 {
+				t.Fatalf("first VerifyIntegrity = %+v", report)
+			}
+			file := onlyTrashFile(t, manager)
+			metadata := LoadMetadata(manager, file)
+			if metadata.Hash != sha256Hex("original") || metadata.LastVerified == nil {
+				t.Fatalf("metadata after verify = hash %q, verified %v", metadata.Hash, metadata.LastVerified)
+			}
+
+			// 在校验间隔内再次运行时跳过，并按上次的结果计数
+			report, err = VerifyIntegrity(manager, IntegrityOptions{Interval: time.Hour})
+			if err != nil {
+				t.Fatalf("VerifyIntegrity: %v", err)
+			}
+			if report.Checked != 0 || report.Skipped != 1 {
+				t.Errorf("VerifyIntegrity within the interval = %+v, want the item skipped", report)
+			}
+
+			if err := os.WriteFile(file.TrashPath, []byte("bit rot"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			report, err = VerifyIntegrity(manager, IntegrityOptions{All: true})
+			if err != nil {
+				t.Fatalf("VerifyIntegrity: %v", err)
+			}
+			if len(report.Corrupt) != 1 || report.Corrupt[0] != file.TrashPath || report.OK != 0 {
+				t.Errorf("VerifyIntegrity after the change = %+v, want %s corrupt", report, file.TrashPath)
+			}
+			metadata = LoadMetadata(manager, onlyTrashFile(t, manager))
+			if metadata.VerifyResult != VerifyCorrupt || metadata.Hash != sha256Hex("original") {
+				t.Errorf("metadata = result %q, hash %q; want corrupt with the baseline hash kept", metadata.VerifyResult, metadata.Hash)
+			}
+		})
+	}
+}
+
+func TestVerifyIntegrityHashesCompressedPayload(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			if err := manager.MoveToTrash(writeContractFile(t, "big.log", compressibleContent)); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+			if report := compactAll(t, manager); report.Compacted != 1 {
+				t.Fatalf("CompactTrash = %+v, want one item compacted", report)
+			}
+
+			// 压缩时记录的是压缩前内容的哈希，校验时透明解压
+			report, err := VerifyIntegrity(manager, IntegrityOptions{All: true})
+			if err != nil {
+				t.Fatalf("VerifyIntegrity: %v", err)
+			}
+			if report.OK != 1 || len(report.Corrupt) != 0 || len(report.Errors) != 0 {
+				t.Fatalf("VerifyIntegrity of the compressed item = %+v, want ok", report)
+			}
+
+			file := onlyTrashFile(t, manager)
+			if err := os.WriteFile(file.TrashPath, gzipBytes(t, compressibleContent+"tampered"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			report, err = VerifyIntegrity(manager, IntegrityOptions{All: true})
+			if err != nil {
+				t.Fatalf("VerifyIntegrity: %v", err)
+			}
+			if len(report.Corrupt) != 1 {
+				t.Errorf("VerifyIntegrity of the changed compressed item = %+v, want corrupt", report)
+			}
+		})
+	}
+}
+
+func TestVerifyIntegrityLeavesDirectoriesUnhashed(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			dir := filepath.Join(t.TempDir(), "photos")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "a.jpg"), []byte("jpeg"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := manager.MoveToTrash(dir); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+
+			report, err := VerifyIntegrity(manager, IntegrityOptions{All: true})
+			if err != nil {
+				t.Fatalf("VerifyIntegrity: %v", err)
+			}
+			if report.Unhashed != 1 || report.OK != 0 || len(report.Corrupt) != 0 {
+				t.Errorf("VerifyIntegrity = %+v, want the directory unhashed", report)
+			}
+			if metadata := LoadMetadata(manager, onlyTrashFile(t, manager)); metadata.Hash != "" || metadata.VerifyResult != VerifyUnhashed {
+				t.Errorf("metadata = hash %q, result %q; want no hash recorded", metadata.Hash, metadata.VerifyResult)
+			}
+		})
+	}
+}
@@ -4,30 +4,91 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"delguard/internal/errors"
+	"delguard/internal/paths"
 )
 
 // LinuxTrashManager Linux Trash管理器
 type LinuxTrashManager struct {
-	trashPath string
-	infoPath  string
+	trashPath      string
+	infoPath       string
+	preserveXattrs bool
+	// networkTrash 网络文件系统上的项目使用的回收站: NetworkTrashShare或NetworkTrashLocal
+	networkTrash string
+	// share 管理的是网络共享挂载点下的.Trash-<uid>，不再转交其他回收站
+	share bool
 }
 
 // NewLinuxTrashManager 创建Linux Trash管理器
 func NewLinuxTrashManager() *LinuxTrashManager {
-	homeDir, _ := os.UserHomeDir()
+	// 遵循XDG Trash规范，主回收站位于 $XDG_DATA_HOME/Trash
+	trashDir := paths.TrashDir()
+	trashPath := filepath.Join(trashDir, "files")
+	infoPath := filepath.Join(trashDir, "info")
+
+	return &LinuxTrashManager{
+		trashPath:      trashPath,
+		infoPath:       infoPath,
+		preserveXattrs: true,
+		networkTrash:   NetworkTrashShare,
+	}
+}
 
-	// 遵循XDG Trash规范
-	trashPath := filepath.Join(homeDir, ".local", "share", "Trash", "files")
-	infoPath := filepath.Join(homeDir, ".local", "share", "Trash", "info")
+// shareTrashRoot 返回网络文件系统上的path使用的共享回收站，即挂载点下XDG规范的$topdir/.Trash-<uid>
+// 按trash.network_trash使用本地回收站，或回收站本身就在同一文件系统上时返回false
+func (l *LinuxTrashManager) shareTrashRoot(path string) (string, bool) {
+	if l.share || l.networkTrash != NetworkTrashShare {
+		return "", false
+	}
+	mount, remote := RemoteMount(path)
+	if !remote {
+		return "", false
+	}
+	if trashMount, err := MountOf(nearestExisting(l.trashPath)); err == nil && sameMountPoint(trashMount.MountPoint, mount.MountPoint) {
+		return "", false
+	}
+	return filepath.Join(mount.MountPoint, fmt.Sprintf(".Trash-%d", os.Getuid())), true
+}
 
+// shareTrash 返回管理共享回收站root的管理器
+func (l *LinuxTrashManager) shareTrash(root string) *LinuxTrashManager {
 	return &LinuxTrashManager{
-		trashPath: trashPath,
-		infoPath:  infoPath,
+		trashPath:      filepath.Join(root, "files"),
+		infoPath:       filepath.Join(root, "info"),
+		preserveXattrs: l.preserveXattrs,
+		share:          true,
+	}
+}
+
+// openShareTrash 按XDG规范以0700创建共享回收站并记录其位置，之后列出回收站时一并列出
+func (l *LinuxTrashManager) openShareTrash(root string) (*LinuxTrashManager, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	if err := rememberShareTrash(root); err != nil {
+		return nil, err
 	}
+	return l.shareTrash(root), nil
+}
+
+// shareTrashOf 返回trashPath所在的共享回收站的管理器，不在已记录的共享回收站中时返回nil
+func (l *LinuxTrashManager) shareTrashOf(trashPath string) *LinuxTrashManager {
+	if l.share {
+		return nil
+	}
+	for _, root := range KnownShareTrashes() {
+		if isSubPath(filepath.Join(root, "files"), trashPath) {
+			return l.shareTrash(root)
+		}
+	}
+	return nil
 }
 
 // MoveToTrash 将文件移动到Linux Trash
@@ -38,57 +99,160 @@ func (l *LinuxTrashManager) MoveToTrash(filePath string) error {
 		return fmt.Errorf("路径转换失败: %v", err)
 	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("文件不存在: %s", absPath)
+	// 检查文件是否存在，失效的符号链接同样可以删除
+	if _, err := os.Lstat(absPath); os.IsNotExist(err) {
+		return errors.NewFileNotFoundError(absPath)
+	}
+
+	// 拒绝重复删除回收站中的文件
+	if err := ValidateTrashSource(l, absPath); err != nil {
+		return err
 	}
 
+	// 网络文件系统上的项目放入同一共享上的回收站，只需重命名，不跨网络复制到本地
+	if root, ok := l.shareTrashRoot(absPath); ok {
+		share, err := l.openShareTrash(root)
+		if err == nil {
+			return share.MoveToTrash(absPath)
+		}
+		if moveErr := l.moveToLocalTrash(absPath); moveErr != nil {
+			return moveErr
+		}
+		return shareFallbackWarning(absPath, root, err)
+	}
+	return l.moveToLocalTrash(absPath)
+}
+
+// moveToLocalTrash 将已检查过的absPath移入本回收站，跨文件系统时复制后删除源文件；
+// 复制完成但源文件只删除了一部分时保留回收站中的完整副本，返回部分失败的错误
+func (l *LinuxTrashManager) moveToLocalTrash(absPath string) error {
 	// 确保Trash目录存在
 	if err := os.MkdirAll(l.trashPath, 0755); err != nil {
-		return fmt.Errorf("创建Trash目录失败: %v", err)
+		return errors.FromOS("创建Trash目录失败", err)
 	}
 	if err := os.MkdirAll(l.infoPath, 0755); err != nil {
-		return fmt.Errorf("创建Trash info目录失败: %v", err)
+		return errors.FromOS("创建Trash info目录失败", err)
 	}
 
-	// 生成唯一的文件名
-	fileName := filepath.Base(absPath)
-	targetPath := filepath.Join(l.trashPath, fileName)
-	infoFilePath := filepath.Join(l.infoPath, fileName+".trashinfo")
+	// 按XDG规范以O_EXCL创建.trashinfo来预留名称，回收站中的文件名由ID生成
+	trashName, err := reserveTrashName(l.trashPath, filepath.Base(absPath), func(name string) string {
+		return filepath.Join(l.infoPath, name+".trashinfo")
+	})
+	if err != nil {
+		return err
+	}
+	targetPath := filepath.Join(l.trashPath, trashName)
+	infoFilePath := filepath.Join(l.infoPath, trashName+".trashinfo")
 
-	// 如果目标文件已存在，添加时间戳
-	counter := 1
-	originalFileName := fileName
-	for {
-		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
-			if _, err := os.Stat(infoFilePath); os.IsNotExist(err) {
-				break
-			}
+	// 记录权限、所有者和时间戳，.trashinfo无法保存这些信息
+	fileInfo, err := os.Lstat(absPath)
+	if err != nil {
+		os.Remove(infoFilePath)
+		return errors.FromOS("获取文件信息失败", err)
+	}
+	metadata := TrashMetadata{
+		ID:           trashName,
+		OriginalPath: absPath,
+		DeletedTime:  time.Now(),
+		FileName:     filepath.Base(absPath),
+		Size:         fileInfo.Size(),
+		IsDirectory:  fileInfo.IsDir(),
+		Permissions:  fileInfo.Mode().String(),
+	}
+	captureFileAttributes(&metadata, fileInfo)
+	captureLinkTarget(&metadata, absPath, fileInfo)
+	annotateNetworkFS(&metadata, absPath)
+	if l.preserveXattrs {
+		if err := captureExtendedAttributes(&metadata, absPath); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  警告: %s: %v\n", absPath, err)
 		}
-
-		ext := filepath.Ext(originalFileName)
-		nameWithoutExt := originalFileName[:len(originalFileName)-len(ext)]
-		fileName = fmt.Sprintf("%s_%d%s", nameWithoutExt, counter, ext)
-		targetPath = filepath.Join(l.trashPath, fileName)
-		infoFilePath = filepath.Join(l.infoPath, fileName+".trashinfo")
-		counter++
 	}
 
 	// 移动文件到Trash
-	if err := os.Rename(absPath, targetPath); err != nil {
-		return fmt.Errorf("移动到Trash失败: %v", err)
+	var removeErr error
+	if err := renameWritable(absPath, targetPath); err != nil {
+		if !errors.IsCrossDevice(err) {
+			os.Remove(infoFilePath)
+			if isPermissionDenied(err) {
+				return err
+			}
+			return errors.FromOS("移动到Trash失败", err)
+		}
+		// 跨文件系统（如Btrfs子卷之间）时回退到复制，优先使用reflink
+		ctx, cancel := newOperationContext(absPath, targetPath)
+		defer cancel()
+		if copyErr := copyTree(ctx, absPath, targetPath); copyErr != nil {
+			os.RemoveAll(targetPath)
+			os.Remove(infoFilePath)
+			if err := timeoutError(ctx, absPath); err != nil {
+				return err
+			}
+			return errors.FromOS("移动到Trash失败", copyErr)
+		}
+		// 复制完成后回收站中的是唯一完整的副本，删除源文件失败时不能再删除它
+		removeErr = removeMovedSource(absPath)
 	}
 
 	// 创建.trashinfo文件
-	err = l.createTrashInfo(infoFilePath, absPath)
+	err = l.createTrashInfo(infoFilePath, absPath, metadata.DeletedTime)
 	if err != nil {
 		// 如果创建info文件失败，尝试恢复原文件
 		if err := os.Rename(targetPath, absPath); err != nil {
 			log.Printf("恢复原文件失败: %v", err)
 		}
-		return fmt.Errorf("创建Trash信息文件失败: %v", err)
+		os.Remove(infoFilePath)
+		return errors.FromOS("创建Trash信息文件失败", err)
+	}
+
+	// 元数据写入失败不影响删除，恢复时按现有行为处理
+	metadataDir := filepath.Join(l.trashPath, ".delguard_metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err == nil {
+		if err := l.writeJSONMetadata(filepath.Join(metadataDir, trashName+".json"), metadata); err != nil {
+			log.Printf("写入元数据失败: %v", err)
+		}
 	}
 
+	if removeErr != nil {
+		return errors.NewPartialMoveError(absPath, targetPath, removeErr)
+	}
+	return nil
+}
+
+// ImportFile 将sourcePath按给定元数据放入Linux Trash
+func (l *LinuxTrashManager) ImportFile(sourcePath string, metadata TrashMetadata) error {
+	if err := os.MkdirAll(l.trashPath, 0755); err != nil {
+		return errors.FromOS("创建Trash目录失败", err)
+	}
+	if err := os.MkdirAll(l.infoPath, 0755); err != nil {
+		return errors.FromOS("创建Trash info目录失败", err)
+	}
+
+	trashName, err := reserveTrashName(l.trashPath, metadata.FileName, func(name string) string {
+		return filepath.Join(l.infoPath, name+".trashinfo")
+	})
+	if err != nil {
+		return err
+	}
+	targetPath := filepath.Join(l.trashPath, trashName)
+	infoFilePath := filepath.Join(l.infoPath, trashName+".trashinfo")
+
+	if err := moveIntoTrash(sourcePath, targetPath); err != nil {
+		os.Remove(infoFilePath)
+		return err
+	}
+	if err := l.createTrashInfo(infoFilePath, metadata.OriginalPath, metadata.DeletedTime); err != nil {
+		os.RemoveAll(targetPath)
+		os.Remove(infoFilePath)
+		return errors.FromOS("创建Trash信息文件失败", err)
+	}
+
+	metadata.ID = trashName
+	metadataDir := filepath.Join(l.trashPath, ".delguard_metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err == nil {
+		if err := l.writeJSONMetadata(filepath.Join(metadataDir, trashName+".json"), metadata); err != nil {
+			log.Printf("写入元数据失败: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -108,6 +272,9 @@ func (l *LinuxTrashManager) ListTrashContents() ([]TrashItem, error) {
 			Size:         file.Size,
 			DeletedTime:  file.DeletedTime,
 			IsDirectory:  file.IsDirectory,
+			Pinned:       file.Pinned,
+			Corrupt:      file.Corrupt,
+			Compressed:   file.Compressed,
 		}
 	}
 
@@ -121,8 +288,9 @@ func (l *LinuxTrashManager) RestoreFromTrash(fileName string, originalPath strin
 		return err
 	}
 
+	// 优先按原始文件名匹配，其次按回收站中的文件名匹配
 	for _, file := range files {
-		if file.Name == fileName {
+		if file.Name == fileName || file.ID == fileName {
 			targetPath := originalPath
 			if targetPath == "" {
 				targetPath = file.OriginalPath
@@ -148,12 +316,19 @@ func (l *LinuxTrashManager) GetStats() (*TrashStats, error) {
 
 	if len(files) > 0 {
 		stats.OldestFile = files[0].DeletedTime
+		paths := make([]string, 0, len(files))
 		for _, file := range files {
 			stats.TotalSize += file.Size
+			paths = append(paths, file.Path)
+			if file.Pinned {
+				stats.PinnedFiles++
+				stats.PinnedSize += file.Size
+			}
 			if file.DeletedTime.Before(stats.OldestFile) {
 				stats.OldestFile = file.DeletedTime
 			}
 		}
+		stats.DiskSize = trashDiskSize(paths)
 	}
 
 	return stats, nil
@@ -166,18 +341,22 @@ func (l *LinuxTrashManager) GetTrashStats() (*TrashStats, error) {
 
 // CleanOldFiles 清理过期文件
 func (l *LinuxTrashManager) CleanOldFiles(maxDays int) error {
+	throttle := MaintenanceThrottle()
 	files, err := l.ListTrashFiles()
 	if err != nil {
 		return err
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -maxDays)
+	// 按原始位置匹配保留规则，未匹配时使用maxDays
+	now := time.Now()
 
 	for _, file := range files {
-		if file.DeletedTime.Before(cutoffTime) {
-			if err := os.RemoveAll(file.TrashPath); err != nil {
+		if !file.Pinned && isExpired(file.OriginalPath, file.DeletedTime, maxDays, now) {
+			// 同时清理.trashinfo和JSON元数据
+			if err := RemoveFromTrash(l, file.TrashPath); err != nil {
 				return fmt.Errorf("清理过期文件失败 %s: %v", file.TrashPath, err)
 			}
+			throttle.Pause()
 		}
 	}
 
@@ -186,36 +365,48 @@ func (l *LinuxTrashManager) CleanOldFiles(maxDays int) error {
 
 // ValidateTrash 验证回收站完整性
 func (l *LinuxTrashManager) ValidateTrash() error {
-	// 检查回收站目录是否存在，不存在则创建
-	if _, err := os.Stat(l.trashPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(l.trashPath, 0755); err != nil {
-			return fmt.Errorf("创建回收站目录失败: %v", err)
-		}
+	// 创建files、info和元数据目录（trashPath本身即files目录）
+	if err := os.MkdirAll(l.trashPath, 0755); err != nil {
+		return errors.FromOS("创建回收站目录失败", err)
 	}
 
-	// 创建必要的子目录
-	filesDir := filepath.Join(l.trashPath, "files")
-	infoDir := filepath.Join(l.trashPath, "info")
-	metadataDir := filepath.Join(l.trashPath, ".delguard_metadata")
-
-	if err := os.MkdirAll(filesDir, 0755); err != nil {
-		return fmt.Errorf("创建files目录失败: %v", err)
+	if err := os.MkdirAll(l.infoPath, 0755); err != nil {
+		return errors.FromOS("创建info目录失败", err)
 	}
 
-	if err := os.MkdirAll(infoDir, 0755); err != nil {
-		return fmt.Errorf("创建info目录失败: %v", err)
+	metadataDir := filepath.Join(l.trashPath, ".delguard_metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return errors.FromOS("创建元数据目录失败", err)
 	}
 
-	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("创建元数据目录失败: %v", err)
+	// 检查目录权限
+	testFile := filepath.Join(l.trashPath, ".delguard_test")
+	file, err := os.Create(testFile)
+	if err != nil {
+		return fmt.Errorf("回收站目录无写权限: %s", l.trashPath)
 	}
+	file.Close()
+	os.Remove(testFile)
 
 	return nil
 }
 
+// VerifyTrash 检查没有对应文件的.trashinfo和JSON元数据，以及两者都缺少的文件
+func (l *LinuxTrashManager) VerifyTrash(repair bool) (TrashVerifyReport, error) {
+	return verifyTrashLayout(trashLayout{
+		filesDir: l.trashPath,
+		sources: []metadataSource{
+			{dir: filepath.Join(l.trashPath, ".delguard_metadata"), suffix: ".json"},
+			{dir: l.infoPath, suffix: ".trashinfo"},
+		},
+		checkFiles: true,
+		write:      l.writeJSONMetadata,
+	}, repair)
+}
+
 // writeJSONMetadata 写入JSON格式的元数据文件
 func (l *LinuxTrashManager) writeJSONMetadata(metadataFile string, metadata TrashMetadata) error {
-	data, err := json.MarshalIndent(metadata, "", "  ")
+	data, err := json.MarshalIndent(withPortablePath(metadata), "", "  ")
 	if err != nil {
 		return fmt.Errorf("序列化元数据失败: %v", err)
 	}
@@ -235,7 +426,7 @@ func (l *LinuxTrashManager) readJSONMetadata(metadataFile string) (string, time.
 		return "", time.Time{}
 	}
 	
-	return metadata.OriginalPath, metadata.DeletedTime
+	return metadata.nativeOriginalPath(), metadata.DeletedTime
 }
 
 // createJSONMetadata 创建JSON格式的元数据文件
@@ -263,13 +454,13 @@ func (l *LinuxTrashManager) Clear() error {
 
 	entries, err := os.ReadDir(trashPath)
 	if err != nil {
-		return fmt.Errorf("读取回收站失败: %v", err)
+		return errors.FromOS("读取回收站失败", err)
 	}
 
 	// 删除所有文件和目录
 	for _, entry := range entries {
 		fullPath := filepath.Join(trashPath, entry.Name())
-		err := os.RemoveAll(fullPath)
+		err := removeAllWritable(fullPath)
 		if err != nil {
 			return fmt.Errorf("删除文件失败 %s: %v", fullPath, err)
 		}
@@ -285,20 +476,15 @@ func (l *LinuxTrashManager) IsEmpty() bool {
 		return true
 	}
 
-	entries, err := os.ReadDir(trashPath)
-	if err != nil {
-		return true
-	}
-
-	return len(entries) == 0
+	return trashDirEmpty(trashPath)
 }
 
 // createTrashInfo 创建Trash信息文件
-func (l *LinuxTrashManager) createTrashInfo(infoPath, originalPath string) error {
-	// 创建符合XDG Trash规范的.trashinfo文件
+func (l *LinuxTrashManager) createTrashInfo(infoPath, originalPath string, deletedTime time.Time) error {
+	// 创建符合XDG Trash规范的.trashinfo文件，路径按规范进行URL编码，换行和无效UTF-8字节不会破坏文件格式
 	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
-		originalPath,
-		time.Now().Format("2006-01-02T15:04:05"))
+		(&url.URL{Path: originalPath}).EscapedPath(),
+		deletedTime.Format("2006-01-02T15:04:05"))
 
 	return os.WriteFile(infoPath, []byte(content), 0644)
 }
@@ -310,17 +496,22 @@ func (l *LinuxTrashManager) GetTrashPath() (string, error) {
 
 // ListTrashFiles 列出Linux Trash中的文件
 func (l *LinuxTrashManager) ListTrashFiles() ([]TrashFile, error) {
-	// 检查Trash目录是否存在
-	if _, err := os.Stat(l.trashPath); os.IsNotExist(err) {
-		return []TrashFile{}, nil // 返回空列表
-	}
+	return l.listTrash(true)
+}
 
+// listTrashIndex 只按.trashinfo列出回收站项目，不读取DelGuard元数据中的固定状态和校验结果
+func (l *LinuxTrashManager) listTrashIndex() ([]TrashFile, error) {
+	return l.listTrash(false)
+}
+
+// listTrash 列出回收站项目，withFlags为false时跳过每个项目的DelGuard元数据
+func (l *LinuxTrashManager) listTrash(withFlags bool) ([]TrashFile, error) {
 	entries, err := os.ReadDir(l.trashPath)
-	if err != nil {
-		return nil, fmt.Errorf("读取Trash失败: %v", err)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.FromOS("读取Trash失败", err)
 	}
 
-	var trashFiles []TrashFile
+	trashFiles := []TrashFile{}
 	for _, entry := range entries {
 		// 跳过隐藏文件和元数据目录
 		if entry.Name() == ".delguard_metadata" || strings.HasPrefix(entry.Name(), ".") {
@@ -343,18 +534,44 @@ func (l *LinuxTrashManager) ListTrashFiles() ([]TrashFile, error) {
 			originalPath, deletionTime = l.readJSONMetadata(metadataFile)
 		}
 
+		// 其他系统上删除的文件（如共享给WSL的Windows路径）转换为本机路径
+		if native, err := NativePath(originalPath); err == nil {
+			originalPath = native
+		}
+
+		// 显示原始文件名，回收站中的文件名仅作为ID
+		displayName := entry.Name()
+		if originalPath != "" {
+			displayName = path.Base(NewPortablePath(originalPath).Path)
+		}
+
 		trashFile := TrashFile{
-			Name:         entry.Name(),
+			ID:           entry.Name(),
+			Name:         displayName,
 			OriginalPath: originalPath,
 			TrashPath:    fullPath,
 			Size:         info.Size(),
 			DeletedTime:  deletionTime,
 			IsDirectory:  entry.IsDir(),
 		}
+		if withFlags {
+			if metadata, err := readTrashMetadata(filepath.Join(l.trashPath, ".delguard_metadata", entry.Name()+".json")); err == nil {
+				applyListFlags(&trashFile, metadata)
+			}
+		}
 
 		trashFiles = append(trashFiles, trashFile)
 	}
 
+	// 同时列出网络共享上的回收站，共享未挂载时跳过
+	if !l.share && l.networkTrash == NetworkTrashShare {
+		for _, root := range existingShareTrashes() {
+			if files, err := l.shareTrash(root).listTrash(withFlags); err == nil {
+				trashFiles = append(trashFiles, files...)
+			}
+		}
+	}
+
 	return trashFiles, nil
 }
 
@@ -372,7 +589,11 @@ func (l *LinuxTrashManager) readTrashInfo(infoPath string) (string, time.Time) {
 	// 简单解析.trashinfo文件
 	for _, line := range lines {
 		if len(line) > 5 && line[:5] == "Path=" {
+			// 路径按XDG规范经过URL编码；旧版本写入的未编码路径无法解码时原样使用
 			originalPath = line[5:]
+			if decoded, err := url.PathUnescape(originalPath); err == nil {
+				originalPath = decoded
+			}
 		} else if len(line) > 13 && line[:13] == "DeletionDate=" {
 			if t, err := time.Parse("2006-01-02T15:04:05", line[13:]); err == nil {
 				deletionTime = t
@@ -385,6 +606,10 @@ func (l *LinuxTrashManager) readTrashInfo(infoPath string) (string, time.Time) {
 
 // RestoreFile 从Linux Trash恢复文件
 func (l *LinuxTrashManager) RestoreFile(trashFile TrashFile, targetPath string) error {
+	if share := l.shareTrashOf(trashFile.TrashPath); share != nil {
+		return share.RestoreFile(trashFile, targetPath)
+	}
+
 	// 如果没有指定目标路径，使用原始路径
 	if targetPath == "" {
 		if trashFile.OriginalPath != "" {
@@ -394,40 +619,63 @@ func (l *LinuxTrashManager) RestoreFile(trashFile TrashFile, targetPath string)
 		}
 	}
 
+	// 不允许恢复到回收站内部
+	if err := ValidateRestoreTarget(l, targetPath); err != nil {
+		return err
+	}
+
 	// 确保目标目录存在
 	targetDir := filepath.Dir(targetPath)
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("创建目标目录失败: %v", err)
+		return errors.FromOS("创建目标目录失败", err)
 	}
 
-	// 检查目标文件是否已存在
-	if _, err := os.Stat(targetPath); err == nil {
-		return fmt.Errorf("目标文件已存在: %s", targetPath)
+	// 检查目标文件是否已存在，不区分大小写的文件系统上只有大小写不同的文件也算
+	if existing, ok := ExistingEntry(targetPath); ok {
+		return fmt.Errorf("目标文件已存在: %s", existing)
 	}
 
+	metadataFile := filepath.Join(l.trashPath, ".delguard_metadata", filepath.Base(trashFile.TrashPath)+".json")
+	metadata, _ := readTrashMetadata(metadataFile)
+
 	// 移动文件从Trash到目标位置
-	err := os.Rename(trashFile.TrashPath, targetPath)
+	err := moveOutOfTrash(trashFile.TrashPath, targetPath, metadata)
 	if err != nil {
-		return fmt.Errorf("恢复文件失败: %v", err)
+		return errors.FromOS("恢复文件失败", err)
 	}
 
-	// 删除对应的.trashinfo文件
+	// 恢复权限、所有者和时间戳
+	if err := applyFileAttributes(targetPath, metadata); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  警告: %s: %v\n", targetPath, err)
+	}
+
+	// 删除对应的.trashinfo文件和元数据
 	infoFilePath := filepath.Join(l.infoPath, filepath.Base(trashFile.TrashPath)+".trashinfo")
 	os.Remove(infoFilePath) // 忽略删除错误
+	os.Remove(metadataFile)
 
 	return nil
 }
 
-// EmptyTrash 清空Linux Trash
+// EmptyTrash 清空Linux Trash，逐个删除项目及其.trashinfo和元数据，单个项目失败时继续
+// 全部删除成功后再清理info目录中没有对应文件的.trashinfo
 func (l *LinuxTrashManager) EmptyTrash() error {
-	// 清空files目录
-	if err := l.emptyDirectory(l.trashPath); err != nil {
-		return fmt.Errorf("清空Trash文件失败: %v", err)
+	if err := emptyTrashDir(l.trashPath, isMetadataDir); err != nil {
+		return err
 	}
 
 	// 清空info目录
 	if err := l.emptyDirectory(l.infoPath); err != nil {
-		return fmt.Errorf("清空Trash信息失败: %v", err)
+		return errors.FromOS("清空Trash信息失败", err)
+	}
+
+	// 网络共享上的回收站一并清空
+	if !l.share && l.networkTrash == NetworkTrashShare {
+		for _, root := range existingShareTrashes() {
+			if err := l.shareTrash(root).EmptyTrash(); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -448,7 +696,7 @@ func (l *LinuxTrashManager) emptyDirectory(dirPath string) error {
 	// 删除所有文件和目录
 	for _, entry := range entries {
 		fullPath := filepath.Join(dirPath, entry.Name())
-		err := os.RemoveAll(fullPath)
+		err := removeAllWritable(fullPath)
 		if err != nil {
 			return fmt.Errorf("删除文件失败 %s: %v", fullPath, err)
 		}
@@ -456,3 +704,27 @@ func (l *LinuxTrashManager) emptyDirectory(dirPath string) error {
 
 	return nil
 }
+
+
+// systemBinUnavailable 专用回收站可以将清理的项目交给XDG系统回收站，当前已是系统回收站时返回错误
+func (l *LinuxTrashManager) systemBinUnavailable() error {
+	if l.trashPath == NewLinuxTrashManager().trashPath {
+		return fmt.Errorf("当前回收站已是系统回收站")
+	}
+	return nil
+}
+
+// moveToSystemBin 将专用回收站中的项目移入XDG系统回收站，.trashinfo记录原始路径
+func (l *LinuxTrashManager) moveToSystemBin(file TrashFile, metadata TrashMetadata) error {
+	return NewLinuxTrashManager().ImportFile(file.TrashPath, metadata)
+}
+
+// ReadItemMetadata 读取回收站项目旁的JSON元数据
+func (l *LinuxTrashManager) ReadItemMetadata(file TrashFile) (TrashMetadata, bool) {
+	return readItemMetadata(file)
+}
+
+// WriteItemMetadata 将元数据写入回收站项目旁的JSON元数据文件
+func (l *LinuxTrashManager) WriteItemMetadata(file TrashFile, metadata TrashMetadata) error {
+	return storeMetadata(l, file, metadata)
+}
@@ -0,0 +1,31 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNewLinuxTrashManagerFollowsXDGDataHome(t *testing.T) {
+	home := useTempHome(t)
+	tests := []struct {
+		name     string
+		dataHome string
+		want     string
+	}{
+		{"unset", "", filepath.Join(home, ".local", "share", "Trash")},
+		{"set", "/xdg/data", "/xdg/data/Trash"},
+		{"relative ignored", "data", filepath.Join(home, ".local", "share", "Trash")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", tt.dataHome)
+			manager := NewLinuxTrashManager()
+			if got, want := manager.trashPath, filepath.Join(tt.want, "files"); got != want {
+				t.Errorf("trash path = %s, want %s", got, want)
+			}
+			if got, want := manager.infoPath, filepath.Join(tt.want, "info"); got != want {
+				t.Errorf("info path = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package filesystem
+
+// indexLister 能够只读取回收站索引列出项目的管理器，不读取每个项目的DelGuard元数据
+type indexLister interface {
+	listTrashIndex() ([]TrashFile, error)
+}
+
+// ListTrashIndex 列出回收站项目，管理器支持时只读取索引（如.trashinfo），
+// 此时返回的Pinned、Corrupt和Compressed未填充，已压缩项目的Size为压缩后的大小，flagsLoaded为false，需要显示时对要显示的项目调用LoadListFlags
+func ListTrashIndex(manager TrashManager) (files []TrashFile, flagsLoaded bool, err error) {
+	if lister, ok := manager.(indexLister); ok {
+		files, err = lister.listTrashIndex()
+		return files, false, err
+	}
+	files, err = manager.ListTrashFiles()
+	return files, true, err
+}
+
+// LoadListFlags 读取项目的DelGuard元数据，填充固定状态、内容校验结果和压缩状态
+func LoadListFlags(manager TrashManager, files []TrashFile) {
+	for i := range files {
+		metadata := LoadMetadata(manager, files[i])
+		applyListFlags(&files[i], &metadata)
+	}
+}
@@ -0,0 +1,344 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"delguard/internal/utils"
+)
+
+// TrashMetadata 回收站元数据结构
+type TrashMetadata struct {
+	ID           string    `json:"id,omitempty"`
+	OriginalPath string    `json:"original_path"`
+	DeletedTime  time.Time `json:"deleted_time"`
+	FileName     string    `json:"file_name"`
+	Size         int64     `json:"size"`
+	IsDirectory  bool      `json:"is_directory"`
+	Permissions  string    `json:"permissions"`
+	Hash         string    `json:"hash,omitempty"`
+	SystemTrash  bool      `json:"system_trash,omitempty"`
+	// DisplayName 文件名含控制字符或无效UTF-8时转义后的名称，用于显示；JSON无法保存无效UTF-8字节，FileName中的这些字节会被替换
+	DisplayName string `json:"display_name,omitempty"`
+	// LinkTarget 删除的是符号链接时记录的链接指向，恢复时重建链接而不是复制目标内容
+	LinkTarget string `json:"link_target,omitempty"`
+	// Pinned 由trash pin固定，过期清理、轮转和清空回收站时跳过
+	Pinned bool `json:"pinned,omitempty"`
+	// Synthesized 由trash verify --repair为缺少元数据的文件补建，原始路径未知
+	Synthesized bool `json:"synthesized,omitempty"`
+	// SpecialType 删除的是命名管道、套接字或设备文件时记录其类型，回收站中只有空的占位项目
+	SpecialType string `json:"special_type,omitempty"`
+	// LastVerified trash verify最近一次校验内容的时间，VerifyResult为该次校验的结果
+	LastVerified *time.Time `json:"last_verified,omitempty"`
+	VerifyResult string     `json:"verify_result,omitempty"`
+	// Compressed 内容已被trash compact用gzip压缩，Size仍为压缩前的大小，恢复和校验时透明解压
+	Compressed bool `json:"compressed,omitempty"`
+	// PortablePath 与平台无关的原始路径，用于在其他系统上还原；旧版本元数据中不存在
+	PortablePath *PortablePath `json:"portable_path,omitempty"`
+	// FSType 原始位置所在的网络文件系统类型（如nfs4、cifs），本地文件系统上的项目不记录
+	FSType string `json:"fs_type,omitempty"`
+
+	// 以下字段用于恢复文件属性，旧版本元数据中不存在
+	Mode       *uint32    `json:"mode,omitempty"`
+	UID        *int       `json:"uid,omitempty"`
+	GID        *int       `json:"gid,omitempty"`
+	ModTime    *time.Time `json:"mod_time,omitempty"`
+	AccessTime *time.Time `json:"access_time,omitempty"`
+
+	// Xattrs 扩展属性，值为base64编码
+	Xattrs map[string]string `json:"xattrs,omitempty"`
+	// Streams NTFS备用数据流（如Zone.Identifier），值为base64编码
+	Streams map[string]string `json:"streams,omitempty"`
+	// ACL 禁止继承或含有显式项的Windows DACL，SDDL格式；POSIX ACL作为扩展属性记录在Xattrs中
+	ACL string `json:"acl,omitempty"`
+}
+
+// 扩展属性的大小限制，避免资源分支等大属性撑大元数据文件
+const (
+	maxXattrValueSize = 64 * 1024
+	maxXattrTotalSize = 256 * 1024
+)
+
+// restorableModeBits 恢复时应用的权限位
+const restorableModeBits = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+
+// 恢复时是否写回时间戳和所有者，由restore.preserve_times和restore.preserve_owner设置
+var (
+	preserveTimes = true
+	preserveOwner = true
+)
+
+// SetRestoreAttributes 设置恢复时是否写回记录的时间戳和所有者，权限位总是写回
+func SetRestoreAttributes(times, owner bool) {
+	preserveTimes = times
+	preserveOwner = owner
+}
+
+// captureFileAttributes 将文件的权限、所有者和时间戳记录到元数据，文件名无法原样显示时同时记录转义后的名称
+func captureFileAttributes(metadata *TrashMetadata, info os.FileInfo) {
+	if name := utils.SanitizeName(metadata.FileName); name != metadata.FileName {
+		metadata.DisplayName = name
+	}
+
+	mode := uint32(info.Mode() & restorableModeBits)
+	metadata.Mode = &mode
+
+	modTime := info.ModTime()
+	metadata.ModTime = &modTime
+	if accessTime, ok := fileAccessTime(info); ok {
+		metadata.AccessTime = &accessTime
+	}
+
+	if uid, gid, ok := fileOwner(info); ok {
+		metadata.UID = &uid
+		metadata.GID = &gid
+	}
+}
+
+// captureLinkTarget 删除的是符号链接时记录链接指向
+func captureLinkTarget(metadata *TrashMetadata, path string, info os.FileInfo) {
+	if info.Mode()&os.ModeSymlink == 0 {
+		return
+	}
+	if target, err := os.Readlink(path); err == nil {
+		metadata.LinkTarget = target
+	}
+}
+
+// moveOutOfTrash 将回收站项目移动到targetPath，目标位于其他文件系统时复制后删除回收站中的项目
+// 元数据记录为符号链接时用os.Symlink按原指向重建链接，不会复制链接目标的内容
+func moveOutOfTrash(trashPath, targetPath string, metadata *TrashMetadata) error {
+	if metadata != nil && metadata.SpecialType != "" {
+		return specialRecordError(metadata)
+	}
+	if metadata != nil && metadata.Compressed {
+		return expandPayload(trashPath, targetPath)
+	}
+	if metadata == nil || metadata.LinkTarget == "" {
+		return moveAcrossDevices(trashPath, targetPath)
+	}
+	if err := os.Symlink(metadata.LinkTarget, targetPath); err != nil {
+		return err
+	}
+	return os.Remove(trashPath)
+}
+
+// captureExtendedAttributes 将文件的扩展属性、备用数据流和显式ACL记录到元数据，
+// 跨文件系统复制到回收站时这些属性会丢失，恢复时由applyFileAttributes写回
+// 返回的错误只用于提示被跳过的属性，不影响删除
+func captureExtendedAttributes(metadata *TrashMetadata, path string) error {
+	attrs, skipped, err := listExtendedAttributes(path)
+	if err != nil {
+		return fmt.Errorf("读取扩展属性失败: %v", err)
+	}
+	if len(attrs) > 0 {
+		metadata.Xattrs = attrs
+	}
+
+	streams, skippedStreams, err := listAlternateStreams(path)
+	if err != nil {
+		return fmt.Errorf("读取备用数据流失败: %v", err)
+	}
+	if len(streams) > 0 {
+		metadata.Streams = streams
+	}
+	skipped = append(skipped, skippedStreams...)
+
+	acl, err := explicitACL(path)
+	if err != nil {
+		return fmt.Errorf("读取访问控制列表失败: %v", err)
+	}
+	metadata.ACL = acl
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("以下扩展属性过大或无法读取，恢复时将丢失: %s", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// applyFileAttributes 将元数据中记录的属性应用到已恢复的文件
+// 返回的错误只用于提示，文件本身已恢复成功
+func applyFileAttributes(path string, metadata *TrashMetadata) error {
+	if metadata == nil {
+		return nil
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	// 符号链接的属性无法可靠地跨平台设置，保持原样
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	var problems []string
+
+	// 写入备用数据流会更新修改时间，需在恢复时间戳之前写回
+	if len(metadata.Streams) > 0 {
+		if err := restoreAlternateStreams(path, metadata.Streams); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if metadata.ModTime != nil && preserveTimes {
+		accessTime := *metadata.ModTime
+		if metadata.AccessTime != nil {
+			accessTime = *metadata.AccessTime
+		}
+		if err := os.Chtimes(path, accessTime, *metadata.ModTime); err != nil {
+			problems = append(problems, fmt.Sprintf("恢复时间戳失败: %v", err))
+		}
+	}
+
+	// 扩展属性需在设置只读权限之前写回
+	if len(metadata.Xattrs) > 0 {
+		if err := restoreExtendedAttributes(path, metadata.Xattrs); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	// Windows上os.Chmod只会设置或清除只读属性；旧版本元数据没有Mode时按Permissions字符串恢复
+	mode, hasMode := os.FileMode(0), false
+	if metadata.Mode != nil {
+		mode, hasMode = os.FileMode(*metadata.Mode), true
+	} else if parsed, ok := parsePermissions(metadata.Permissions); ok {
+		mode, hasMode = parsed, true
+	}
+	if hasMode {
+		if err := os.Chmod(path, mode&restorableModeBits); err != nil {
+			problems = append(problems, fmt.Sprintf("恢复权限失败: %v", err))
+		}
+	}
+	if metadata.ACL != "" {
+		if err := restoreACL(path, metadata.ACL); err != nil {
+			problems = append(problems, fmt.Sprintf("恢复访问控制列表失败: %v", err))
+		}
+	}
+
+	if metadata.UID != nil && metadata.GID != nil && preserveOwner && runtime.GOOS != "windows" {
+		if uid, gid, ok := fileOwner(info); !ok || uid != *metadata.UID || gid != *metadata.GID {
+			if os.Geteuid() == 0 {
+				if err := os.Lchown(path, *metadata.UID, *metadata.GID); err != nil {
+					problems = append(problems, fmt.Sprintf("恢复所有者失败: %v", err))
+				}
+			} else {
+				problems = append(problems, fmt.Sprintf("需要root权限才能恢复所有者 (uid=%d, gid=%d)", *metadata.UID, *metadata.GID))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// parsePermissions 解析os.FileMode.String()格式的权限字符串（如 -rwxr-xr-x、dtrwxrwxrwx），
+// 也接受ls格式（如 drwxrwxrwt），只取最后9位权限及setuid/setgid/sticky标记，无法解析时返回false
+func parsePermissions(permissions string) (os.FileMode, bool) {
+	if len(permissions) < 9 {
+		return 0, false
+	}
+	bits := permissions[len(permissions)-9:]
+	var mode os.FileMode
+	// os.FileMode.String()将setuid、setgid和sticky写在权限位之前的类型字母中
+	for _, c := range permissions[:len(permissions)-9] {
+		switch c {
+		case 'u':
+			mode |= os.ModeSetuid
+		case 'g':
+			mode |= os.ModeSetgid
+		case 't':
+			mode |= os.ModeSticky
+		}
+	}
+	for i, c := range bits {
+		bit := os.FileMode(1) << uint(8-i)
+		switch {
+		case c == '-':
+		case c == rune("rwxrwxrwx"[i]):
+			mode |= bit
+		case i == 2 && (c == 's' || c == 'S'):
+			mode |= os.ModeSetuid
+		case i == 5 && (c == 's' || c == 'S'):
+			mode |= os.ModeSetgid
+		case i == 8 && (c == 't' || c == 'T'):
+			mode |= os.ModeSticky
+		default:
+			return 0, false
+		}
+		if (c == 's' || c == 't') && i%3 == 2 {
+			mode |= bit
+		}
+	}
+	return mode, true
+}
+
+// readTrashMetadata 读取JSON格式的元数据文件
+func readTrashMetadata(metadataFile string) (*TrashMetadata, error) {
+	data, err := os.ReadFile(metadataFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata TrashMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("解析元数据失败: %v", err)
+	}
+
+	return &metadata, nil
+}
+
+// applyListFlags 按DelGuard元数据填充列表项目的固定状态、内容校验结果和压缩状态，
+// 已压缩的项目使用元数据中压缩前的大小
+func applyListFlags(file *TrashFile, metadata *TrashMetadata) {
+	file.Pinned = metadata.Pinned
+	file.Corrupt = metadata.VerifyResult == VerifyCorrupt
+	if metadata.Compressed {
+		file.Compressed = true
+		if metadata.Size > 0 {
+			file.Size = metadata.Size
+		}
+	}
+}
+
+// readItemMetadata 读取回收站项目旁的JSON元数据文件
+// Linux/macOS的元数据在.delguard_metadata下，Windows专用回收站在.metadata下
+func readItemMetadata(file TrashFile) (TrashMetadata, bool) {
+	dir, base := filepath.Dir(file.TrashPath), filepath.Base(file.TrashPath)
+	for _, metadataDir := range []string{".delguard_metadata", ".metadata"} {
+		if stored, err := readTrashMetadata(filepath.Join(dir, metadataDir, base+".json")); err == nil {
+			return *stored, true
+		}
+	}
+	return TrashMetadata{}, false
+}
+
+// LoadMetadata 获取回收站项目的完整元数据，没有DelGuard元数据时由列表信息生成
+func LoadMetadata(manager TrashManager, file TrashFile) TrashMetadata {
+	metadata := TrashMetadata{
+		ID:          file.ID,
+		FileName:    file.Name,
+		Size:        file.Size,
+		IsDirectory: file.IsDirectory,
+		Permissions: file.Permissions,
+	}
+	if stored, ok := manager.ReadItemMetadata(file); ok {
+		metadata = stored
+	}
+
+	// 列表中的原始路径和删除时间来自.trashinfo等权威来源，优先使用
+	if file.OriginalPath != "" {
+		metadata.OriginalPath = file.OriginalPath
+	}
+	if !file.DeletedTime.IsZero() {
+		metadata.DeletedTime = file.DeletedTime
+	}
+	return metadata
+}
@@ -0,0 +1,165 @@
+package filesystem
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// attributeFixture 返回测试使用的权限：Windows上只有只读属性可以恢复
+func attributeFixture() os.FileMode {
+	if runtime.GOOS == "windows" {
+		return 0444
+	}
+	return 0640
+}
+
+// setAttributes 设置文件的权限和时间戳
+func setAttributes(t *testing.T, path string, mode os.FileMode, modTime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	// 只读文件在测试结束时也要能被删除
+	t.Cleanup(func() { os.Chmod(path, 0644) })
+}
+
+// assertAttributes 检查文件的权限和修改时间
+func assertAttributes(t *testing.T, path string, mode os.FileMode, modTime time.Time) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != mode {
+		t.Errorf("%s: mode = %v, want %v", path, got, mode)
+	}
+	if got := info.ModTime(); !got.Equal(modTime) {
+		t.Errorf("%s: mtime = %v, want %v", path, got, modTime)
+	}
+}
+
+func TestApplyFileAttributesRoundTrip(t *testing.T) {
+	mode, modTime := attributeFixture(), time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	path := writeContractFile(t, "attrs.txt", "attributes")
+	setAttributes(t, path, mode, modTime)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := TrashMetadata{FileName: "attrs.txt"}
+	captureFileAttributes(&metadata, info)
+
+	// 模拟跨文件系统复制后丢失的属性
+	setAttributes(t, path, 0644, time.Now())
+	if err := applyFileAttributes(path, &metadata); err != nil {
+		t.Fatalf("applyFileAttributes: %v", err)
+	}
+	assertAttributes(t, path, mode, modTime)
+}
+
+func TestApplyFileAttributesLegacyMetadata(t *testing.T) {
+	path := writeContractFile(t, "legacy.txt", "legacy")
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 旧版本元数据只有Permissions字符串，没有Mode、时间戳和所有者
+	metadata := TrashMetadata{FileName: "legacy.txt", Permissions: before.Mode().String()}
+	if err := applyFileAttributes(path, &metadata); err != nil {
+		t.Errorf("applyFileAttributes with legacy metadata: %v", err)
+	}
+	if err := applyFileAttributes(path, nil); err != nil {
+		t.Errorf("applyFileAttributes without metadata: %v", err)
+	}
+	assertAttributes(t, path, before.Mode().Perm(), before.ModTime())
+}
+
+func TestRestoreKeepsModeAndTimes(t *testing.T) {
+	mode, modTime := attributeFixture(), time.Date(2022, 8, 9, 10, 11, 12, 0, time.UTC)
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			path := writeContractFile(t, "kept.txt", "kept")
+			setAttributes(t, path, mode, modTime)
+			if err := manager.MoveToTrash(path); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+			if err := manager.RestoreFile(onlyTrashFile(t, manager), path); err != nil {
+				t.Fatalf("RestoreFile: %v", err)
+			}
+			assertAttributes(t, path, mode, modTime)
+		})
+	}
+}
+
+func TestParsePermissions(t *testing.T) {
+	for _, mode := range []os.FileMode{0644, 0755, 0600, 0777, 0, os.ModeDir | 0755, os.ModeDir | os.ModeSticky | 0777, os.ModeSetuid | 0755, os.ModeSetgid | 0750, os.ModeSetuid | 0644} {
+		got, ok := parsePermissions(mode.String())
+		if want := mode & restorableModeBits; !ok || got != want {
+			t.Errorf("parsePermissions(%q) = %v, %v; want %v", mode.String(), got, ok, want)
+		}
+	}
+	// ls格式把setuid、setgid和sticky写在执行位上
+	for permissions, want := range map[string]os.FileMode{
+		"-rwsr-xr-x": os.ModeSetuid | 0755,
+		"-rwSr--r--": os.ModeSetuid | 0644,
+		"-rwxr-s---": os.ModeSetgid | 0750,
+		"drwxrwxrwt": os.ModeSticky | 0777,
+	} {
+		if got, ok := parsePermissions(permissions); !ok || got != want {
+			t.Errorf("parsePermissions(%q) = %v, %v; want %v", permissions, got, ok, want)
+		}
+	}
+	for _, permissions := range []string{"", "rwx", "-rwxr-xr-q", "-rwzr-xr-x"} {
+		if got, ok := parsePermissions(permissions); ok {
+			t.Errorf("parsePermissions(%q) = %v, want failure", permissions, got)
+		}
+	}
+}
+
+func TestApplyFileAttributesFromPermissionsString(t *testing.T) {
+	mode := attributeFixture()
+	path := writeContractFile(t, "legacy-mode.txt", "legacy")
+	setAttributes(t, path, 0644, time.Now())
+
+	metadata := TrashMetadata{FileName: "legacy-mode.txt", Permissions: mode.String()}
+	if err := applyFileAttributes(path, &metadata); err != nil {
+		t.Fatalf("applyFileAttributes: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != mode {
+		t.Errorf("mode = %v, want %v from the Permissions string", got, mode)
+	}
+}
+
+func TestSetRestoreAttributesSkipsTimes(t *testing.T) {
+	SetRestoreAttributes(false, false)
+	t.Cleanup(func() { SetRestoreAttributes(true, true) })
+
+	mode, modTime := attributeFixture(), time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	path := writeContractFile(t, "times.txt", "times")
+	setAttributes(t, path, mode, modTime)
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := TrashMetadata{FileName: "times.txt"}
+	captureFileAttributes(&metadata, info)
+
+	restoredAt := time.Date(2024, 6, 7, 8, 9, 10, 0, time.UTC)
+	setAttributes(t, path, 0644, restoredAt)
+	if err := applyFileAttributes(path, &metadata); err != nil {
+		t.Fatalf("applyFileAttributes: %v", err)
+	}
+	// 权限位总是写回，时间戳保持恢复时的值
+	assertAttributes(t, path, mode, restoredAt)
+}
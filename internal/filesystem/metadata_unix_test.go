@@ -0,0 +1,56 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestApplyFileAttributesRestoresOwner(t *testing.T) {
+	path := writeContractFile(t, "owned.txt", "owned")
+	uid, gid := 1, 1
+	metadata := TrashMetadata{FileName: "owned.txt", UID: &uid, GID: &gid}
+
+	err := applyFileAttributes(path, &metadata)
+	if os.Geteuid() != 0 {
+		if err == nil || !strings.Contains(err.Error(), "root") {
+			t.Errorf("applyFileAttributes() as non-root = %v, want a hint that root is required", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("applyFileAttributes: %v", err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotUID, gotGID, ok := fileOwner(info); !ok || gotUID != uid || gotGID != gid {
+		t.Errorf("owner = %d:%d, want %d:%d", gotUID, gotGID, uid, gid)
+	}
+}
+
+func TestApplyFileAttributesOwnerDisabled(t *testing.T) {
+	SetRestoreAttributes(true, false)
+	t.Cleanup(func() { SetRestoreAttributes(true, true) })
+
+	path := writeContractFile(t, "unowned.txt", "unowned")
+	before, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid, gid := 1, 1
+	if err := applyFileAttributes(path, &TrashMetadata{FileName: "unowned.txt", UID: &uid, GID: &gid}); err != nil {
+		t.Fatalf("applyFileAttributes with preserve_owner off: %v", err)
+	}
+	after, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beforeUID, beforeGID, _ := fileOwner(before)
+	if afterUID, afterGID, _ := fileOwner(after); afterUID != beforeUID || afterGID != beforeGID {
+		t.Errorf("owner changed to %d:%d with preserve_owner off", afterUID, afterGID)
+	}
+}
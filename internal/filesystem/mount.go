@@ -0,0 +1,72 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MountInfo 路径所在的文件系统
+type MountInfo struct {
+	MountPoint string // 挂载点或卷根目录
+	FSType     string // 文件系统类型
+	Source     string // 挂载来源，如 server:/export 或 //server/share
+	Remote     bool   // 是否为NFS、SMB等远程文件系统
+}
+
+// mountLookup 查找绝对路径所在的文件系统，测试时可替换为桩函数
+var mountLookup = lookupMount
+
+// remoteFSTypes 视为远程文件系统的类型
+var remoteFSTypes = []string{
+	"nfs", "nfs4", "cifs", "smb", "smb2", "smb3", "smbfs", "ncpfs", "afs", "9p",
+	"ceph", "glusterfs", "lustre", "gpfs", "davfs", "webdav", "afpfs",
+	"fuse.sshfs", "fuse.rclone", "fuse.glusterfs", "fuse.davfs2", "fuse.s3fs",
+}
+
+// MountOf 返回路径所在的文件系统，路径本身是符号链接时按链接所在的位置判断
+func MountOf(path string) (MountInfo, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return MountInfo{}, err
+	}
+	// 上级目录中的符号链接可能指向其他文件系统，按真实路径匹配挂载点
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(absPath)); err == nil {
+		absPath = filepath.Join(dir, filepath.Base(absPath))
+	}
+	return mountLookup(absPath)
+}
+
+// RemoteMount 路径位于远程文件系统时返回其挂载信息，无法判断时视为本地
+func RemoteMount(path string) (MountInfo, bool) {
+	mount, err := MountOf(path)
+	if err != nil || !mount.Remote {
+		return MountInfo{}, false
+	}
+	return mount, true
+}
+
+// isRemoteFSType 检查文件系统类型是否为远程文件系统
+func isRemoteFSType(fsType string) bool {
+	fsType = strings.ToLower(fsType)
+	for _, remote := range remoteFSTypes {
+		if fsType == remote {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestExisting 返回路径自身或最近的已存在上级目录
+func nearestExisting(path string) string {
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
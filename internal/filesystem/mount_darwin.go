@@ -0,0 +1,29 @@
+//go:build darwin
+
+package filesystem
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// lookupMount 通过statfs获取路径所在的文件系统，没有MNT_LOCAL标志的视为远程文件系统
+func lookupMount(path string) (MountInfo, error) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(nearestExisting(path), &fs); err != nil {
+		return MountInfo{}, fmt.Errorf("获取 %s 的文件系统信息失败: %v", path, err)
+	}
+	fsType := unix.ByteSliceToString(fs.Fstypename[:])
+	return MountInfo{
+		MountPoint: unix.ByteSliceToString(fs.Mntonname[:]),
+		FSType:     fsType,
+		Source:     unix.ByteSliceToString(fs.Mntfromname[:]),
+		Remote:     fs.Flags&unix.MNT_LOCAL == 0 || isRemoteFSType(fsType),
+	}, nil
+}
+
+// mountPoints 设备号已能识别macOS上的挂载点，不另外读取挂载表
+func mountPoints() ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,98 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lookupMount 在/proc/mounts中查找包含路径的最长挂载点
+func lookupMount(path string) (MountInfo, error) {
+	mounts, err := readMounts()
+	if err != nil {
+		return MountInfo{}, err
+	}
+	return longestMount(mounts, path)
+}
+
+// longestMount 在按挂载顺序排列的mounts中查找包含路径的最长挂载点
+func longestMount(mounts []MountInfo, path string) (MountInfo, error) {
+	var best MountInfo
+	found := false
+	for _, mount := range mounts {
+		if !isSubPath(mount.MountPoint, path) || (found && len(mount.MountPoint) < len(best.MountPoint)) {
+			continue
+		}
+		// 同一挂载点被多次挂载时，后出现的覆盖前面的
+		best = mount
+		found = true
+	}
+	if !found {
+		return MountInfo{}, fmt.Errorf("找不到 %s 所在的挂载点", path)
+	}
+	return best, nil
+}
+
+// mountPoints 返回挂载表中的所有挂载点，包括同一文件系统的绑定挂载
+func mountPoints() ([]string, error) {
+	mounts, err := readMounts()
+	if err != nil {
+		return nil, err
+	}
+	points := make([]string, 0, len(mounts))
+	for _, mount := range mounts {
+		points = append(points, mount.MountPoint)
+	}
+	return points, nil
+}
+
+// readMounts 按出现顺序读取/proc/mounts中的挂载项
+func readMounts() ([]MountInfo, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, fmt.Errorf("读取挂载信息失败: %v", err)
+	}
+	defer file.Close()
+
+	var mounts []MountInfo
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		mounts = append(mounts, MountInfo{
+			MountPoint: unescapeMountField(fields[1]),
+			FSType:     fields[2],
+			Source:     unescapeMountField(fields[0]),
+			Remote:     isRemoteFSType(fields[2]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取挂载信息失败: %v", err)
+	}
+	return mounts, nil
+}
+
+// unescapeMountField 还原/proc/mounts中以八进制转义的空格、制表符、换行和反斜杠
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if value, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLongestMount(t *testing.T) {
+	mounts := []MountInfo{
+		{MountPoint: "/", FSType: "ext4"},
+		{MountPoint: "/mnt/data", FSType: "xfs"},
+		{MountPoint: "/mnt/data/share", FSType: "nfs4", Remote: true},
+		{MountPoint: "/mnt/data2", FSType: "cifs", Remote: true},
+		{MountPoint: "/media", FSType: "ext4"},
+		{MountPoint: "/media", FSType: "fuse.sshfs", Remote: true},
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/home/user/file", "ext4"},
+		{"/mnt/data/file", "xfs"},
+		{"/mnt/data/share", "nfs4"},
+		{"/mnt/data/share/deep/file", "nfs4"},
+		{"/mnt/data2/file", "cifs"},
+		{"/mnt/datafile", "ext4"},
+		{"/media/usb/file", "fuse.sshfs"},
+	}
+	for _, tt := range tests {
+		mount, err := longestMount(mounts, tt.path)
+		if err != nil || mount.FSType != tt.want {
+			t.Errorf("longestMount(%q) = %+v, %v; want %s", tt.path, mount, err, tt.want)
+		}
+	}
+	if _, err := longestMount(mounts[1:2], "/home/user"); err == nil {
+		t.Error("longestMount without a covering mount point succeeded")
+	}
+}
+
+func TestUnescapeMountField(t *testing.T) {
+	tests := map[string]string{
+		`/mnt/plain`:              "/mnt/plain",
+		`/mnt/My\040Share`:        "/mnt/My Share",
+		`/mnt/tab\011and\134back`: "/mnt/tab\tand\\back",
+		`//server/share\040x`:     "//server/share x",
+		`/mnt/bad\09`:             `/mnt/bad\09`,
+	}
+	for field, want := range tests {
+		if got := unescapeMountField(field); got != want {
+			t.Errorf("unescapeMountField(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestNestedMountsDetectsOtherDevices(t *testing.T) {
+	other := otherDeviceDir(t)
+	shm := filepath.Dir(other)
+
+	// 挂载表不可用时按设备号识别挂载点
+	stubMountTable(t)
+	mounts, err := NestedMounts(shm)
+	if err != nil {
+		t.Fatalf("NestedMounts: %v", err)
+	}
+	if len(mounts) != 1 || mounts[0] != shm {
+		t.Errorf("NestedMounts(%s) = %q, want the mount point itself", shm, mounts)
+	}
+
+	// 指向其他文件系统的符号链接不会被跟随
+	root := t.TempDir()
+	if err := os.Symlink(other, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if mounts, err := NestedMounts(root); err != nil || len(mounts) != 0 {
+		t.Errorf("NestedMounts through a symlink = %q, %v; want none", mounts, err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !windows
+
+package filesystem
+
+import "fmt"
+
+// lookupMount 当前平台不支持获取挂载信息
+func lookupMount(path string) (MountInfo, error) {
+	return MountInfo{}, fmt.Errorf("当前平台不支持获取挂载信息")
+}
+
+// mountPoints 当前平台不读取挂载表
+func mountPoints() ([]string, error) {
+	return nil, nil
+}
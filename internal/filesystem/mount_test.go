@@ -0,0 +1,80 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stubMounts 在测试期间用lookup替换挂载点查找
+func stubMounts(t *testing.T, lookup func(path string) (MountInfo, error)) {
+	t.Helper()
+	saved := mountLookup
+	mountLookup = lookup
+	t.Cleanup(func() { mountLookup = saved })
+}
+
+func TestIsRemoteFSType(t *testing.T) {
+	for fsType, want := range map[string]bool{
+		"nfs4":       true,
+		"CIFS":       true,
+		"fuse.sshfs": true,
+		"9p":         true,
+		"ext4":       false,
+		"btrfs":      false,
+		"fuse":       false,
+		"tmpfs":      false,
+		"":           false,
+	} {
+		if got := isRemoteFSType(fsType); got != want {
+			t.Errorf("isRemoteFSType(%q) = %v, want %v", fsType, got, want)
+		}
+	}
+}
+
+func TestMountOfResolvesSymlinkedParent(t *testing.T) {
+	dir := t.TempDir()
+	share := filepath.Join(dir, "share")
+	if err := os.MkdirAll(share, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "shortcut")
+	if err := os.Symlink(share, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	realShare, err := filepath.EvalSymlinks(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var looked string
+	stubMounts(t, func(path string) (MountInfo, error) {
+		looked = path
+		if isSubPath(realShare, path) {
+			return MountInfo{MountPoint: realShare, FSType: "nfs", Remote: true}, nil
+		}
+		return MountInfo{MountPoint: "/", FSType: "ext4"}, nil
+	})
+
+	mount, remote := RemoteMount(filepath.Join(link, "report.txt"))
+	if !remote || mount.FSType != "nfs" {
+		t.Errorf("RemoteMount through a symlinked parent = %+v, %v; want the nfs share", mount, remote)
+	}
+	if want := filepath.Join(realShare, "report.txt"); looked != want {
+		t.Errorf("looked up %q, want %q", looked, want)
+	}
+	// 链接本身位于本地目录中，删除的是链接而不是共享中的内容
+	if _, remote := RemoteMount(link); remote {
+		t.Error("a local symlink pointing into a share was reported as remote")
+	}
+}
+
+func TestRemoteMountTreatsLookupFailureAsLocal(t *testing.T) {
+	stubMounts(t, func(path string) (MountInfo, error) {
+		return MountInfo{}, fmt.Errorf("no mount table")
+	})
+	if mount, remote := RemoteMount(t.TempDir()); remote {
+		t.Errorf("RemoteMount = %+v, want local when the lookup fails", mount)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// lookupMount 按卷根目录判断，UNC路径和映射的网络驱动器视为远程文件系统
+func lookupMount(path string) (MountInfo, error) {
+	volume := filepath.VolumeName(path)
+	if volume == "" {
+		return MountInfo{}, fmt.Errorf("无法确定 %s 所在的卷", path)
+	}
+	root := volume + `\`
+	if strings.HasPrefix(volume, `\\`) {
+		return MountInfo{MountPoint: root, FSType: "SMB", Source: volume, Remote: true}, nil
+	}
+
+	rootPtr, err := windows.UTF16PtrFromString(root)
+	if err != nil {
+		return MountInfo{}, err
+	}
+	mount := MountInfo{
+		MountPoint: root,
+		Source:     volume,
+		Remote:     windows.GetDriveType(rootPtr) == windows.DRIVE_REMOTE,
+	}
+	fsName := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumeInformation(rootPtr, nil, 0, nil, nil, nil, &fsName[0], uint32(len(fsName))); err == nil {
+		mount.FSType = windows.UTF16ToString(fsName)
+	}
+	return mount, nil
+}
+
+// mountPoints 文件夹中挂载的卷按卷序列号识别，不另外读取挂载表
+func mountPoints() ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,68 @@
+package filesystem
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// mountTable 读取挂载表中的挂载点，测试时可替换为桩函数
+var mountTable = mountPoints
+
+// NestedMounts 返回目录树root中挂载了其他文件系统的位置，root本身是挂载点时也包括在内
+// 按设备号（Windows上为卷序列号）判断跨越文件系统的目录，Linux上另按挂载表识别同一文件系统的绑定挂载
+// 不跟随符号链接，遇到挂载点时不再深入，无法读取的子目录被跳过
+func NestedMounts(root string) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	rootDevice, err := deviceID(root)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(map[string]bool)
+	if points, err := mountTable(); err == nil {
+		for _, point := range points {
+			if isSubPath(root, point) {
+				table[filepath.Clean(point)] = true
+			}
+		}
+	}
+
+	var mounts []string
+	if table[root] || isMountPoint(root, rootDevice) {
+		mounts = append(mounts, root)
+	}
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if path == root || !entry.IsDir() {
+			return nil
+		}
+		if table[path] {
+			mounts = append(mounts, path)
+			return filepath.SkipDir
+		}
+		if device, err := deviceID(path); err == nil && device != rootDevice {
+			mounts = append(mounts, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return mounts, nil
+}
+
+// isMountPoint 判断目录的设备号是否与上级目录不同
+func isMountPoint(path string, device uint64) bool {
+	parent := filepath.Dir(path)
+	if parent == path {
+		return true
+	}
+	parentDevice, err := deviceID(parent)
+	return err == nil && parentDevice != device
+}
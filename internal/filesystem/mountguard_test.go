@@ -0,0 +1,59 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// stubMountTable 在测试期间用points替换挂载表
+func stubMountTable(t *testing.T, points ...string) {
+	t.Helper()
+	saved := mountTable
+	mountTable = func() ([]string, error) { return points, nil }
+	t.Cleanup(func() { mountTable = saved })
+}
+
+func TestNestedMountsFromMountTable(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"data/share/inner", "data/plain", "cache"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	share := filepath.Join(root, "data", "share")
+	cache := filepath.Join(root, "cache")
+	tests := []struct {
+		name   string
+		points []string
+		target string
+		want   []string
+	}{
+		{"no mounts", nil, root, nil},
+		{"nested bind mounts", []string{"/", share, cache}, root, []string{cache, share}},
+		// 遇到挂载点时不再深入，挂载点下的挂载点不单独列出
+		{"mount inside a mount", []string{share, filepath.Join(share, "inner")}, root, []string{share}},
+		{"target is a mount point", []string{share}, share, []string{share}},
+		{"mount outside the target", []string{cache}, filepath.Join(root, "data"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubMountTable(t, tt.points...)
+			got, err := NestedMounts(tt.target)
+			if err != nil {
+				t.Fatalf("NestedMounts: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NestedMounts(%s) = %q, want %q", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNestedMountsMissingTarget(t *testing.T) {
+	stubMountTable(t)
+	if _, err := NestedMounts(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("NestedMounts of a missing directory succeeded")
+	}
+}
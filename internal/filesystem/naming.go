@@ -0,0 +1,133 @@
+package filesystem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"delguard/internal/utils"
+)
+
+// maxReserveAttempts 生成唯一名称的最大尝试次数
+const maxReserveAttempts = 16
+
+// trashIDCounter 进程内单调递增的序号
+var trashIDCounter uint64
+
+// newTrashID 生成回收站项目ID，格式为 <时间戳>-<序号>-<随机后缀>
+func newTrashID() string {
+	seq := atomic.AddUint64(&trashIDCounter, 1)
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// 随机数不可用时退化为纳秒时间，唯一性仍由O_EXCL保证
+		return fmt.Sprintf("%s-%04d-%x", time.Now().Format("20060102150405"), seq, time.Now().UnixNano()&0xffffffff)
+	}
+
+	return fmt.Sprintf("%s-%04d-%s", time.Now().Format("20060102150405"), seq, hex.EncodeToString(suffix))
+}
+
+// trashPayloadName 由ID和原始扩展名组成回收站中的文件名
+// 原始文件名只保存在元数据中，避免特殊文件名（如".env"）导致的截断问题
+// 扩展名末尾的点被去掉，回收站中不会出现Windows无法正常访问的名称
+func trashPayloadName(id, originalName string) string {
+	ext := filepath.Ext(originalName)
+	if ext == originalName || strings.ContainsAny(ext, ` /\`) {
+		ext = ""
+	}
+	return id + strings.TrimRight(ext, ".")
+}
+
+// reserveTrashName 以O_EXCL方式创建占位文件来预留回收站中的唯一名称
+// trashDir为存放文件本体的目录，reservePath根据名称返回占位文件（元数据/info文件）路径
+func reserveTrashName(trashDir, originalName string, reservePath func(name string) string) (string, error) {
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		name := trashPayloadName(newTrashID(), originalName)
+
+		file, err := os.OpenFile(reservePath(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return "", fmt.Errorf("预留回收站文件名失败: %v", err)
+		}
+		file.Close()
+
+		// 文件本体位置已被占用（如遗留文件）时换一个名称
+		if _, err := os.Lstat(filepath.Join(trashDir, name)); err == nil {
+			os.Remove(reservePath(name))
+			continue
+		}
+
+		return name, nil
+	}
+
+	return "", fmt.Errorf("无法生成唯一的回收站文件名: %s", originalName)
+}
+
+// renamePattern 恢复时目标已存在所使用的改名模式
+var renamePattern = utils.DefaultRenamePattern
+
+// SetRenamePattern 设置恢复冲突的改名模式（restore.rename_pattern），无效的模式被忽略
+func SetRenamePattern(pattern string) {
+	if utils.ValidateRenamePattern(pattern) == nil {
+		renamePattern = pattern
+	}
+}
+
+// ConflictFreePath 按改名模式为已存在的path生成新路径，序号从1递增，
+// 直到路径不存在且taken（可为nil）不认为它已被占用
+// 隐藏文件（如".env"）整个名称作为{name}，扩展名为空
+func ConflictFreePath(path string, taken func(string) bool) string {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	name := base[:len(base)-len(ext)]
+	if name == "" {
+		name, ext = base, ""
+	}
+
+	now := time.Now()
+	for n := 1; ; n++ {
+		candidate := filepath.Join(dir, utils.ExpandRenamePattern(renamePattern, name, ext, n, now))
+		if _, exists := ExistingEntry(candidate); !exists && (taken == nil || !taken(candidate)) {
+			return candidate
+		}
+	}
+}
+
+// caseInsensitiveFS 判断路径所在的文件系统是否不区分大小写，测试时可替换为桩函数
+var caseInsensitiveFS = utils.CaseInsensitive
+
+// ExistingEntry 返回path位置上已存在的条目，不跟随符号链接
+// 目标目录所在的文件系统不区分大小写时，只有大小写不同的条目（如readme.txt之于ReadMe.txt）
+// 也视为同一位置，返回磁盘上的实际路径；区分大小写时只认完全相同的名称
+func ExistingEntry(path string) (string, bool) {
+	_, err := Lstat(path)
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	if !caseInsensitiveFS(dir) {
+		return path, err == nil
+	}
+
+	// 按目录项比较名称，得到实际的大小写，并覆盖按路径查找时不折叠大小写的文件系统
+	entries, readErr := os.ReadDir(dir)
+	if readErr != nil {
+		return path, err == nil
+	}
+	for _, entry := range entries {
+		if entry.Name() == name {
+			return path, true
+		}
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Name(), name) {
+			return filepath.Join(dir, entry.Name()), true
+		}
+	}
+	return path, err == nil
+}
@@ -0,0 +1,141 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTrashPayloadName(t *testing.T) {
+	tests := []struct {
+		original string
+		want     string
+	}{
+		{"report.txt", "ID.txt"},
+		{"archive.tar.gz", "ID.gz"},
+		{".env", "ID"},
+		{".", "ID"},
+		{"noext", "ID"},
+		{"trailing.", "ID"},
+		{"odd.ext with space", "ID"},
+	}
+	for _, tt := range tests {
+		if got := trashPayloadName("ID", tt.original); got != tt.want {
+			t.Errorf("trashPayloadName(%q) = %q, want %q", tt.original, got, tt.want)
+		}
+	}
+}
+
+func TestReserveTrashNameConcurrent(t *testing.T) {
+	const workers = 64
+	dir := t.TempDir()
+	reservePath := func(name string) string { return filepath.Join(dir, name+".trashinfo") }
+
+	var wg sync.WaitGroup
+	names := make([]string, workers)
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			names[i], errs[i] = reserveTrashName(dir, ".env", reservePath)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, workers)
+	for i, name := range names {
+		if errs[i] != nil {
+			t.Fatalf("reserveTrashName: %v", errs[i])
+		}
+		if seen[name] {
+			t.Errorf("name %q reserved twice", name)
+		}
+		seen[name] = true
+		if _, err := os.Stat(reservePath(name)); err != nil {
+			t.Errorf("reservation for %q missing: %v", name, err)
+		}
+	}
+}
+
+func TestReserveTrashNameSkipsOccupiedPayload(t *testing.T) {
+	dir := t.TempDir()
+
+	// 第一个候选名称的本体位置上放一个遗留文件
+	var leftover string
+	reservePath := func(name string) string {
+		if leftover == "" {
+			leftover = name
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return filepath.Join(dir, name+".trashinfo")
+	}
+
+	name, err := reserveTrashName(dir, "file.txt", reservePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name == leftover {
+		t.Fatalf("reserved %q although a leftover payload occupies it", name)
+	}
+	if !strings.HasSuffix(name, ".txt") {
+		t.Errorf("name %q lost the original extension", name)
+	}
+	if _, err := os.Lstat(reservePath(leftover)); !os.IsNotExist(err) {
+		t.Errorf("reservation for the occupied name was not released: %v", err)
+	}
+}
+
+func TestConcurrentMovesOfSameName(t *testing.T) {
+	const files = 16
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, files)
+			for i := 0; i < files; i++ {
+				path := writeContractFile(t, ".env", fmt.Sprintf("secret %d", i))
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := manager.MoveToTrash(path); err != nil {
+						errs <- err
+					}
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+
+			list, err := manager.ListTrashFiles()
+			if err != nil {
+				t.Fatalf("ListTrashFiles: %v", err)
+			}
+			if len(list) != files {
+				t.Fatalf("ListTrashFiles returned %d items, want %d", len(list), files)
+			}
+			contents := make(map[string]bool, files)
+			for _, file := range list {
+				if file.Name != ".env" {
+					t.Errorf("listed name = %q, want the original name .env", file.Name)
+				}
+				data, err := os.ReadFile(file.TrashPath)
+				if err != nil {
+					t.Fatalf("ReadFile: %v", err)
+				}
+				contents[string(data)] = true
+			}
+			if len(contents) != files {
+				t.Errorf("%d distinct payloads in the trash, want %d", len(contents), files)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package filesystem
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"delguard/internal/paths"
+)
+
+// 网络文件系统上的项目使用的回收站位置
+const (
+	// NetworkTrashShare 使用同一共享上的回收站，删除时只需重命名，不经过网络复制
+	NetworkTrashShare = "share"
+	// NetworkTrashLocal 使用本地回收站，需要把内容跨网络复制到本机
+	NetworkTrashLocal = "local"
+)
+
+// shareTrashPrefix Windows网络共享根目录下DelGuard回收站目录名的前缀，后接用户名
+const shareTrashPrefix = ".delguard-trash-"
+
+// shareTrashListName 状态目录中记录已使用的共享回收站目录的文件，列出回收站时只读取其中的目录，
+// 不逐个访问所有网络挂载，避免无响应的共享阻塞列表
+const shareTrashListName = "share_trashes"
+
+var (
+	networkMu      sync.RWMutex
+	networkTimeout time.Duration
+)
+
+// SetNetworkTimeout 设置网络文件系统上单个文件操作的超时时间，短于performance.timeout时使用后者，0表示不限制
+func SetNetworkTimeout(timeout time.Duration) {
+	networkMu.Lock()
+	defer networkMu.Unlock()
+	networkTimeout = timeout
+}
+
+// NetworkTimeout 获取网络文件系统上文件操作的超时时间
+func NetworkTimeout() time.Duration {
+	networkMu.RLock()
+	defer networkMu.RUnlock()
+	return networkTimeout
+}
+
+// operationTimeoutFor 返回涉及paths的文件操作的超时时间，任一路径位于网络文件系统时使用较长的网络超时
+func operationTimeoutFor(paths ...string) time.Duration {
+	timeout := OperationTimeout()
+	if timeout <= 0 {
+		return 0
+	}
+	for _, path := range paths {
+		if _, remote := RemoteMount(nearestExisting(path)); !remote {
+			continue
+		}
+		network := NetworkTimeout()
+		if network <= 0 {
+			return 0
+		}
+		if network > timeout {
+			return network
+		}
+		break
+	}
+	return timeout
+}
+
+// shareTrasher 支持把网络文件系统上的项目放入同一共享上的回收站的管理器
+type shareTrasher interface {
+	shareTrashRoot(path string) (string, bool)
+}
+
+// NetworkPlan 网络文件系统上的项目进入回收站的方式（降级模式）
+type NetworkPlan struct {
+	Mount      MountInfo     // 项目所在的网络文件系统
+	ShareTrash string        // 同一共享上的回收站，为空时进入管理器的回收站
+	Copy       bool          // 需要跨网络复制到其他文件系统上的回收站
+	Timeout    time.Duration // 文件操作的超时时间，0表示不限制
+}
+
+// PlanNetwork 判断path是否位于网络文件系统，是时返回删除到manager时进入哪个回收站以及使用的超时时间
+func PlanNetwork(manager TrashManager, path string) (NetworkPlan, bool) {
+	mount, remote := RemoteMount(path)
+	if !remote {
+		return NetworkPlan{}, false
+	}
+	plan := NetworkPlan{Mount: mount, Timeout: operationTimeoutFor(path)}
+	if trasher, ok := manager.(shareTrasher); ok {
+		if root, ok := trasher.shareTrashRoot(path); ok {
+			plan.ShareTrash = root
+			return plan, true
+		}
+	}
+	plan.Copy = true
+	if trashPath, err := manager.GetTrashPath(); err == nil {
+		if trashMount, err := MountOf(nearestExisting(trashPath)); err == nil {
+			plan.Copy = !sameMountPoint(trashMount.MountPoint, mount.MountPoint)
+		}
+	}
+	return plan, true
+}
+
+// sameMountPoint 两个挂载点是否相同，不区分大小写的卷上忽略大小写
+func sameMountPoint(a, b string) bool {
+	return isSubPath(a, b) && isSubPath(b, a)
+}
+
+// shareTrashListFile 返回记录共享回收站目录的文件路径
+func shareTrashListFile() (string, error) {
+	dir, err := paths.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, shareTrashListName), nil
+}
+
+// KnownShareTrashes 返回曾经使用过的共享回收站目录，按记录顺序排列
+func KnownShareTrashes() []string {
+	listFile, err := shareTrashListFile()
+	if err != nil {
+		return nil
+	}
+	file, err := os.Open(listFile)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var roots []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if root := strings.TrimSpace(scanner.Text()); root != "" {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// rememberShareTrash 记录使用过的共享回收站目录，已记录时不重复写入
+func rememberShareTrash(root string) error {
+	for _, known := range KnownShareTrashes() {
+		if known == root {
+			return nil
+		}
+	}
+	listFile, err := shareTrashListFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(listFile), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(listFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(root + "\n")
+	return err
+}
+
+// existingShareTrashes 返回仍然存在的共享回收站目录，共享未挂载时跳过
+func existingShareTrashes() []string {
+	var roots []string
+	for _, root := range KnownShareTrashes() {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// annotateNetworkFS 源路径位于网络文件系统时在元数据中记录文件系统类型
+func annotateNetworkFS(metadata *TrashMetadata, path string) {
+	if mount, remote := RemoteMount(path); remote {
+		metadata.FSType = mount.FSType
+	}
+}
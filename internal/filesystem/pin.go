@@ -0,0 +1,61 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"delguard/internal/errors"
+)
+
+// metadataWriter 能按平台规则写入JSON元数据的回收站管理器
+type metadataWriter interface {
+	writeJSONMetadata(metadataFile string, metadata TrashMetadata) error
+}
+
+// SetPinned 固定或取消固定回收站项目，固定状态保存在项目的元数据中
+// 固定的项目不会被过期清理、轮转或清空回收站删除，除非明确要求包含固定项目
+func SetPinned(manager TrashManager, file TrashFile, pinned bool) error {
+	// 系统回收站中由其他程序删除的项目没有DelGuard元数据，按列表信息补建
+	metadata := LoadMetadata(manager, file)
+	metadata.Pinned = pinned
+	return manager.WriteItemMetadata(file, metadata)
+}
+
+// storeMetadata 将元数据写入回收站项目的JSON元数据文件，元数据目录不存在时创建
+func storeMetadata(writer metadataWriter, file TrashFile, metadata TrashMetadata) error {
+	metadataFile := pinMetadataFile(file.TrashPath)
+	if err := os.MkdirAll(filepath.Dir(metadataFile), 0755); err != nil {
+		return errors.FromOS("创建元数据目录失败", err)
+	}
+	if err := writer.writeJSONMetadata(metadataFile, metadata); err != nil {
+		return errors.FromOS("写入元数据失败", err)
+	}
+	return nil
+}
+
+// pinMetadataFile 返回回收站项目的元数据文件路径，与readItemMetadata的查找顺序一致
+func pinMetadataFile(trashPath string) string {
+	dir, base := filepath.Dir(trashPath), filepath.Base(trashPath)
+	for _, metadataDir := range []string{".delguard_metadata", ".metadata"} {
+		path := filepath.Join(dir, metadataDir, base+".json")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(dir, ".metadata", base+".json")
+	}
+	return filepath.Join(dir, ".delguard_metadata", base+".json")
+}
+
+// unpinnedFiles 过滤掉已固定的项目
+func unpinnedFiles(files []TrashFile) []TrashFile {
+	var result []TrashFile
+	for _, file := range files {
+		if !file.Pinned {
+			result = append(result, file)
+		}
+	}
+	return result
+}
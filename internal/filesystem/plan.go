@@ -0,0 +1,155 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+
+	"delguard/internal/security"
+)
+
+// maxPlanEntries 统计删除计划时最多遍历的条目数，超出后停止遍历并标记为不完整
+const maxPlanEntries = 100000
+
+// maxPlanIssues 删除计划中最多列出的问题数，其余只计数
+const maxPlanIssues = 50
+
+// 删除计划中的问题类型
+const (
+	IssueCritical     = "critical"      // 受保护路径或系统关键路径
+	IssueUnreadable   = "unreadable"    // 无法读取，统计中不包含其内容
+	IssueSpecial      = "special"       // 设备、管道或套接字等特殊文件
+	IssueExternalLink = "external_link" // 指向删除目标之外的符号链接，链接本身会被删除
+	IssueTrailingName = "trailing_name" // Windows上名称以点或空格结尾
+)
+
+// FileIssue 删除目标中需要注意的文件
+type FileIssue struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// PlanTarget 单个删除目标的统计
+type PlanTarget struct {
+	Path        string `json:"path"`
+	IsDirectory bool   `json:"is_directory"`
+	Files       int64  `json:"files"`
+	Directories int64  `json:"directories"`
+	Size        int64  `json:"size"`
+	DiskSize    int64  `json:"disk_size"` // 实际占用的磁盘空间，即删除后可回收的空间
+}
+
+// DeletionPlan 删除前对目标的展开统计，供确认前展示
+type DeletionPlan struct {
+	Targets     []PlanTarget `json:"targets"`
+	Files       int64        `json:"files"`
+	Directories int64        `json:"directories"`
+	TotalSize   int64        `json:"total_size"`
+	DiskSize    int64        `json:"disk_size"` // 按已分配的块计算，同一操作中的硬链接只计一次
+	Issues      []FileIssue  `json:"issues,omitempty"`
+	IssueCount  int          `json:"issue_count"`
+	Critical    int          `json:"critical"`            // 目标中的受保护路径和系统关键路径数
+	Truncated   bool         `json:"truncated,omitempty"` // 条目数超过上限，统计不完整
+}
+
+// PlanDeletion 遍历删除目标，统计文件数、目录数、总大小和实际占用的磁盘空间，并收集需要注意的文件
+// 目标中的受保护路径和系统关键路径只记录，不再深入遍历；遍历的条目数有上限
+func PlanDeletion(paths []string) (*DeletionPlan, error) {
+	plan := &DeletionPlan{Targets: []PlanTarget{}}
+	validator := security.NewPathValidator()
+	usage := newDiskUsage()
+	entries := 0
+
+	for _, path := range paths {
+		absPath, err := AbsPath(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := Lstat(absPath)
+		if err != nil {
+			return nil, err
+		}
+
+		target := PlanTarget{Path: absPath, IsDirectory: info.IsDir()}
+		if !info.IsDir() {
+			target.Files = 1
+			target.Size, target.DiskSize = usage.add(absPath, info)
+			plan.inspect(absPath, absPath, info, validator)
+		} else if !plan.Truncated {
+			filepath.Walk(absPath, func(current string, info os.FileInfo, err error) error {
+				if err != nil {
+					if trailingNameOnWindows(current) {
+						plan.addIssue(current, IssueTrailingName, trailingNameMessage)
+					} else {
+						plan.addIssue(current, IssueUnreadable, err.Error())
+					}
+					return nil
+				}
+				entries++
+				if entries > maxPlanEntries {
+					plan.Truncated = true
+					return filepath.SkipAll
+				}
+				if current != absPath && plan.inspect(absPath, current, info, validator) && info.IsDir() {
+					return filepath.SkipDir
+				}
+				if info.IsDir() {
+					target.Directories++
+				} else {
+					apparent, onDisk := usage.add(current, info)
+					target.Files++
+					target.Size += apparent
+					target.DiskSize += onDisk
+				}
+				return nil
+			})
+		}
+
+		plan.Targets = append(plan.Targets, target)
+		plan.Files += target.Files
+		plan.Directories += target.Directories
+		plan.TotalSize += target.Size
+		plan.DiskSize += target.DiskSize
+	}
+
+	return plan, nil
+}
+
+// inspect 检查单个条目，返回true表示该条目是关键路径，不应再深入遍历
+// 目标本身位于关键路径下时其中的条目都会匹配同一前缀，只标记目标内部的关键路径
+func (p *DeletionPlan) inspect(root, path string, info os.FileInfo, validator *security.PathValidator) bool {
+	if path != root && validator.IsCriticalPath(path) && !validator.IsCriticalPath(root) {
+		p.Critical++
+		p.addIssue(path, IssueCritical, "受保护路径或系统关键路径")
+		return true
+	}
+	if trailingNameOnWindows(path) {
+		p.addIssue(path, IssueTrailingName, trailingNameMessage)
+	}
+
+	mode := info.Mode()
+	switch {
+	case mode&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return false
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		if !isSubPath(root, filepath.Clean(target)) {
+			p.addIssue(path, IssueExternalLink, "指向 "+target)
+		}
+	case IsSpecialFile(mode):
+		p.addIssue(path, IssueSpecial, SpecialFileKind(mode))
+	}
+	return false
+}
+
+// addIssue 记录一个问题，超过maxPlanIssues后只计数
+func (p *DeletionPlan) addIssue(path, kind, message string) {
+	p.IssueCount++
+	if len(p.Issues) < maxPlanIssues {
+		p.Issues = append(p.Issues, FileIssue{Path: path, Kind: kind, Message: message})
+	}
+}
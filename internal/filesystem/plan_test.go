@@ -0,0 +1,154 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"delguard/internal/security"
+)
+
+// writeSized 创建大小为size字节的文件
+func writeSized(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// issuesOf 按类型返回删除计划中的问题路径
+func issuesOf(plan *DeletionPlan, kind string) []string {
+	var paths []string
+	for _, issue := range plan.Issues {
+		if issue.Kind == kind {
+			paths = append(paths, issue.Path)
+		}
+	}
+	return paths
+}
+
+func TestPlanDeletionMixedTree(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+	writeSized(t, filepath.Join(root, "a.txt"), 10)
+	writeSized(t, filepath.Join(root, "sub", "b.txt"), 20)
+	if err := os.MkdirAll(filepath.Join(root, "sub", "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	single := filepath.Join(dir, "single.txt")
+	writeSized(t, single, 5)
+	outside := filepath.Join(dir, "outside.txt")
+	writeSized(t, outside, 1)
+
+	if err := os.Symlink("a.txt", filepath.Join(root, "inner")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "sub", "outer")); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanDeletion([]string{root, single})
+	if err != nil {
+		t.Fatalf("PlanDeletion: %v", err)
+	}
+	if len(plan.Targets) != 2 {
+		t.Fatalf("plan has %d targets, want 2", len(plan.Targets))
+	}
+	// 符号链接按链接本身计为文件
+	tree, file := plan.Targets[0], plan.Targets[1]
+	if !tree.IsDirectory || tree.Files != 4 || tree.Directories != 3 {
+		t.Errorf("tree target = %+v, want 4 files in 3 directories", tree)
+	}
+	if file.IsDirectory || file.Files != 1 || file.Size != 5 {
+		t.Errorf("file target = %+v, want one 5 byte file", file)
+	}
+	if plan.Files != 5 || plan.Directories != 3 || plan.TotalSize < 35 || plan.TotalSize != tree.Size+file.Size {
+		t.Errorf("plan totals = %d files, %d directories, %d bytes", plan.Files, plan.Directories, plan.TotalSize)
+	}
+
+	external := issuesOf(plan, IssueExternalLink)
+	if len(external) != 1 || filepath.Base(external[0]) != "outer" {
+		t.Errorf("external link issues = %q, want only sub/outer", external)
+	}
+	// 目标位于临时目录下时，其中的条目与目标本身匹配同一个系统路径前缀，不应逐个标记
+	if plan.Critical != 0 || plan.IssueCount != len(external) {
+		t.Errorf("plan issues = %+v, want only the external link", plan.Issues)
+	}
+}
+
+func TestPlanDeletionCountsHardLinksOnce(t *testing.T) {
+	root := t.TempDir()
+	first := filepath.Join(root, "first.bin")
+	writeSized(t, first, 64*1024)
+	if err := os.Link(first, filepath.Join(root, "second.bin")); err != nil {
+		t.Skipf("hard links unavailable: %v", err)
+	}
+
+	plan, err := PlanDeletion([]string{root})
+	if err != nil {
+		t.Fatalf("PlanDeletion: %v", err)
+	}
+	if plan.Files != 2 || plan.TotalSize != 128*1024 {
+		t.Errorf("plan = %d files of %d bytes, want 2 files of %d bytes", plan.Files, plan.TotalSize, 128*1024)
+	}
+	if plan.DiskSize <= 0 || plan.DiskSize >= plan.TotalSize {
+		t.Errorf("DiskSize = %d, want the shared data counted once", plan.DiskSize)
+	}
+}
+
+func TestPlanDeletionCapsListedIssues(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "links")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	total := maxPlanIssues + 10
+	for i := 0; i < total; i++ {
+		if err := os.Symlink(dir, filepath.Join(root, fmt.Sprintf("link%02d", i))); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	plan, err := PlanDeletion([]string{root})
+	if err != nil {
+		t.Fatalf("PlanDeletion: %v", err)
+	}
+	if plan.IssueCount != total || len(plan.Issues) != maxPlanIssues {
+		t.Errorf("IssueCount = %d with %d listed, want %d with %d listed", plan.IssueCount, len(plan.Issues), total, maxPlanIssues)
+	}
+}
+
+func TestPlanDeletionFlagsProtectedPathsInside(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "home")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if security.NewPathValidator().IsCriticalPath(home) {
+		t.Skipf("temporary directory %s is itself a system path", home)
+	}
+	writeSized(t, filepath.Join(home, "Documents", "thesis.tex"), 100)
+	writeSized(t, filepath.Join(home, "scratch.txt"), 10)
+
+	plan, err := PlanDeletion([]string{home})
+	if err != nil {
+		t.Fatalf("PlanDeletion: %v", err)
+	}
+	critical := issuesOf(plan, IssueCritical)
+	if plan.Critical != 1 || len(critical) != 1 || filepath.Base(critical[0]) != "Documents" {
+		t.Errorf("critical issues = %q, want Documents", critical)
+	}
+	// 关键路径本身不再深入遍历，其中的文件不计入统计
+	if plan.Files != 1 || plan.TotalSize != 10 {
+		t.Errorf("plan = %d files of %d bytes, want only scratch.txt", plan.Files, plan.TotalSize)
+	}
+}
+
+func TestPlanDeletionMissingTarget(t *testing.T) {
+	if _, err := PlanDeletion([]string{filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("PlanDeletion of a missing path succeeded")
+	}
+}
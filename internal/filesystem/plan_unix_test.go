@@ -0,0 +1,41 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestPlanDeletionFlagsSpecialAndUnreadableEntries(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "tree")
+	writeSized(t, filepath.Join(root, "regular.txt"), 3)
+	if err := syscall.Mkfifo(filepath.Join(root, "pipe"), 0644); err != nil {
+		t.Fatalf("Mkfifo: %v", err)
+	}
+	locked := filepath.Join(root, "locked")
+	writeSized(t, filepath.Join(locked, "hidden.txt"), 50)
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+	plan, err := PlanDeletion([]string{root})
+	if err != nil {
+		t.Fatalf("PlanDeletion: %v", err)
+	}
+	if special := issuesOf(plan, IssueSpecial); len(special) != 1 || filepath.Base(special[0]) != "pipe" {
+		t.Errorf("special issues = %q, want the pipe", special)
+	}
+	if os.Geteuid() == 0 {
+		return // root可以读取任何目录
+	}
+	if unreadable := issuesOf(plan, IssueUnreadable); len(unreadable) != 1 || unreadable[0] != locked {
+		t.Errorf("unreadable issues = %q, want %s", unreadable, locked)
+	}
+	if plan.TotalSize != 3 {
+		t.Errorf("TotalSize = %d, want only the readable file", plan.TotalSize)
+	}
+}
@@ -0,0 +1,118 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PortablePath 与平台无关的路径表示，元数据在Windows与Linux/WSL之间共享时用于还原本机路径
+type PortablePath struct {
+	// Volume Windows盘符（如"C:"）或UNC共享（如"//server/share"），Unix路径为空
+	Volume string `json:"volume,omitempty"`
+	// Path 卷内以/分隔的绝对路径
+	Path string `json:"path"`
+}
+
+// NewPortablePath 将任意平台的绝对路径转换为可移植形式
+// 以盘符或\\开头的路径按Windows路径解析，其余按Unix路径解析（Unix路径中的反斜杠是合法字符，不做转换）
+func NewPortablePath(native string) PortablePath {
+	if isDrivePath(native) {
+		return PortablePath{
+			Volume: strings.ToUpper(native[:2]),
+			Path:   path.Clean("/" + strings.ReplaceAll(native[2:], `\`, "/")),
+		}
+	}
+	if strings.HasPrefix(native, `\\`) || (runtime.GOOS == "windows" && strings.HasPrefix(native, "//")) {
+		parts := strings.SplitN(strings.TrimLeft(strings.ReplaceAll(native, `\`, "/"), "/"), "/", 3)
+		if len(parts) >= 2 {
+			rest := "/"
+			if len(parts) == 3 {
+				rest = path.Clean("/" + parts[2])
+			}
+			return PortablePath{Volume: "//" + parts[0] + "/" + parts[1], Path: rest}
+		}
+	}
+	return PortablePath{Path: native}
+}
+
+// isDrivePath 检查路径是否以Windows盘符开头，如C:\或C:/
+func isDrivePath(p string) bool {
+	if len(p) < 3 || (p[2] != '\\' && p[2] != '/') || p[1] != ':' {
+		return false
+	}
+	c := p[0]
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// Native 返回当前系统上的本机路径
+// Linux上的盘符路径映射到WSL挂载点/mnt/<盘符>，Windows上的/mnt/<盘符>路径映射回盘符
+func (p PortablePath) Native() (string, error) {
+	if runtime.GOOS == "windows" {
+		switch {
+		case p.Volume != "":
+			return p.Volume + filepath.FromSlash(p.Path), nil
+		case isWSLMountPath(p.Path):
+			drive := strings.ToUpper(p.Path[5:6]) + ":"
+			rest := strings.TrimPrefix(p.Path[6:], "/")
+			return drive + `\` + filepath.FromSlash(rest), nil
+		default:
+			return "", fmt.Errorf("原始路径 %s 来自Unix系统，在Windows上没有对应位置，请使用 --to 指定恢复位置", p.Path)
+		}
+	}
+
+	switch {
+	case p.Volume == "":
+		return p.Path, nil
+	case len(p.Volume) == 2:
+		mount := "/mnt/" + strings.ToLower(p.Volume[:1])
+		if _, err := os.Stat(mount); err != nil {
+			return "", fmt.Errorf("原始卷 %s 在当前系统上不存在 (未找到 %s)，请使用 --to 指定恢复位置", p.Volume, mount)
+		}
+		return path.Join(mount, p.Path), nil
+	default:
+		return "", fmt.Errorf("原始卷 %s 是网络共享，在当前系统上无法访问，请使用 --to 指定恢复位置", p.Volume)
+	}
+}
+
+// isWSLMountPath 检查是否为WSL下的盘符挂载路径，如/mnt/c/Users
+func isWSLMountPath(p string) bool {
+	if !strings.HasPrefix(p, "/mnt/") || len(p) < 6 {
+		return false
+	}
+	c := p[5]
+	return (('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')) && (len(p) == 6 || p[6] == '/')
+}
+
+// NativePath 将元数据中保存的原始路径转换为当前系统的本机路径
+// 旧版本元数据只有本机路径，同样按其格式解析
+func NativePath(originalPath string) (string, error) {
+	if originalPath == "" {
+		return "", nil
+	}
+	return NewPortablePath(originalPath).Native()
+}
+
+// nativeOriginalPath 优先使用可移植路径还原原始路径，无法还原时返回保存的原始路径
+func (m *TrashMetadata) nativeOriginalPath() string {
+	portable := NewPortablePath(m.OriginalPath)
+	if m.PortablePath != nil {
+		portable = *m.PortablePath
+	}
+	if native, err := portable.Native(); err == nil {
+		return native
+	}
+	return m.OriginalPath
+}
+
+// withPortablePath 为元数据补充可移植形式的原始路径
+func withPortablePath(metadata TrashMetadata) TrashMetadata {
+	if metadata.PortablePath == nil && metadata.OriginalPath != "" {
+		portable := NewPortablePath(metadata.OriginalPath)
+		metadata.PortablePath = &portable
+	}
+	return metadata
+}
@@ -0,0 +1,93 @@
+package filesystem
+
+import (
+	"fmt"
+	"time"
+)
+
+// systemBinMover 使用DelGuard专用回收站的管理器，可以将清理出的项目交给系统回收站
+type systemBinMover interface {
+	// systemBinUnavailable 返回系统回收站不可用的原因，当前回收站已是系统回收站时同样返回错误
+	systemBinUnavailable() error
+	// moveToSystemBin 将回收站项目移入系统回收站，metadata中的原始路径用于之后从系统回收站还原
+	moveToSystemBin(file TrashFile, metadata TrashMetadata) error
+}
+
+// PruneResult 清理回收站项目的结果
+type PruneResult struct {
+	ToSystemBin int   // 移入系统回收站的项目数
+	Destroyed   int   // 永久删除的项目数
+	Size        int64 // 清理的总大小
+	// Checks 启用verify_before_prune且项目被永久删除时各项目的校验结果，否则为nil
+	Checks PruneChecks
+}
+
+// PruneItems 将项目移出回收站，两种情况下都会清理对应的元数据
+// toSystemBin为true时把项目移入系统回收站，由系统的保留策略最终决定何时删除；
+// 系统回收站不可用时所有项目都保留在回收站中并返回错误，不会改为永久删除；
+// 永久删除前按verify_before_prune校验，内容损坏的项目移入隔离目录，无法校验的项目保留在回收站中
+func PruneItems(manager TrashManager, files []TrashFile, toSystemBin bool) (PruneResult, error) {
+	var result PruneResult
+	var mover systemBinMover
+	if toSystemBin {
+		m, ok := manager.(systemBinMover)
+		if !ok {
+			return result, fmt.Errorf("无法移入系统回收站，项目保留在回收站中: 当前回收站后端不支持系统回收站")
+		}
+		if err := m.systemBinUnavailable(); err != nil {
+			return result, fmt.Errorf("无法移入系统回收站，项目保留在回收站中: %v", err)
+		}
+		mover = m
+	}
+
+	// 移入系统回收站的项目仍可找回，只在永久删除时校验
+	checks := make(PruneChecks, len(files))
+	for i, file := range files {
+		checks[i] = &PruneCheck{File: file}
+	}
+	if mover == nil {
+		var verified bool
+		if checks, verified = checkBeforeDestroy(manager, files); verified {
+			result.Checks = checks
+		}
+	}
+
+	throttle := MaintenanceThrottle()
+	for _, check := range checks {
+		if check.Outcome != "" {
+			continue
+		}
+		file := check.File
+		if mover != nil {
+			metadata := LoadMetadata(manager, file)
+			// 系统回收站按移入的时间计算保留期限，固定标记只对DelGuard回收站有效
+			metadata.DeletedTime = time.Now()
+			metadata.Pinned = false
+			// 系统回收站不认识压缩后的内容，移入前先原地解压
+			if metadata.Compressed {
+				if err := expandPayload(file.TrashPath, file.TrashPath); err != nil {
+					return result, fmt.Errorf("解压失败 %s: %v", file.Name, err)
+				}
+				metadata.Compressed = false
+			}
+			if err := mover.moveToSystemBin(file, metadata); err != nil {
+				return result, fmt.Errorf("移入系统回收站失败 %s: %v", file.Name, err)
+			}
+		}
+
+		// 已移入系统回收站的项目这里只清理元数据
+		if err := RemoveFromTrash(manager, file.TrashPath); err != nil {
+			check.Outcome, check.Err = PruneKept, err
+			return result, err
+		}
+		if mover != nil {
+			result.ToSystemBin++
+		} else {
+			check.Outcome = PruneDestroyed
+			result.Destroyed++
+		}
+		result.Size += file.Size
+		throttle.Pause()
+	}
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// systemBinStub 可以把项目交给系统回收站的测试回收站，unavailable非nil时系统回收站不可用
+type systemBinStub struct {
+	*FakeTrashManager
+	unavailable error
+	moveErr     error
+	moved       []TrashMetadata
+	contents    []string
+}
+
+func (s *systemBinStub) systemBinUnavailable() error {
+	return s.unavailable
+}
+
+func (s *systemBinStub) moveToSystemBin(file TrashFile, metadata TrashMetadata) error {
+	if s.moveErr != nil {
+		return s.moveErr
+	}
+	data, err := os.ReadFile(file.TrashPath)
+	if err != nil {
+		return err
+	}
+	s.moved = append(s.moved, metadata)
+	s.contents = append(s.contents, string(data))
+	return nil
+}
+
+// newSystemBinStub 创建含有一个已固定项目的测试回收站
+func newSystemBinStub(t *testing.T, content string) (*systemBinStub, TrashFile) {
+	t.Helper()
+	fake, err := NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.MoveToTrash(writeContractFile(t, "expired.log", content)); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	file := onlyTrashFile(t, fake)
+	if err := SetPinned(fake, file, true); err != nil {
+		t.Fatal(err)
+	}
+	return &systemBinStub{FakeTrashManager: fake}, onlyTrashFile(t, fake)
+}
+
+// assertKept 检查项目的内容和列表项都还在回收站中
+func assertKept(t *testing.T, manager TrashManager, file TrashFile) {
+	t.Helper()
+	if _, err := os.Lstat(file.TrashPath); err != nil {
+		t.Errorf("trash content removed: %v", err)
+	}
+	if files, err := manager.ListTrashFiles(); err != nil || len(files) != 1 {
+		t.Errorf("ListTrashFiles = %d items, %v; want the item kept", len(files), err)
+	}
+}
+
+func TestPruneItemsKeepsItemsWithoutSystemBin(t *testing.T) {
+	unsupported, err := NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unsupported.MoveToTrash(writeContractFile(t, "expired.log", "old")); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	unavailable, _ := newSystemBinStub(t, "old")
+	unavailable.unavailable = fmt.Errorf("当前回收站已是系统回收站")
+
+	for name, manager := range map[string]TrashManager{"unsupported": unsupported, "unavailable": unavailable} {
+		t.Run(name, func(t *testing.T) {
+			// 即使启用了删除前校验，也不能改为永久删除
+			useVerifyBeforePrune(t)
+			file := onlyTrashFile(t, manager)
+
+			result, err := PruneItems(manager, []TrashFile{file}, true)
+			if err == nil || !strings.Contains(err.Error(), "保留在回收站中") {
+				t.Errorf("PruneItems err = %v, want the items reported as kept", err)
+			}
+			if result.Destroyed != 0 || result.ToSystemBin != 0 || result.Checks != nil {
+				t.Errorf("PruneItems = %+v, want nothing pruned", result)
+			}
+			assertKept(t, manager, file)
+		})
+	}
+}
+
+func TestPruneItemsMovesToSystemBin(t *testing.T) {
+	stub, file := newSystemBinStub(t, "old")
+	before := time.Now()
+
+	result, err := PruneItems(stub, []TrashFile{file}, true)
+	if err != nil {
+		t.Fatalf("PruneItems: %v", err)
+	}
+	if result.ToSystemBin != 1 || result.Destroyed != 0 || result.Checks != nil {
+		t.Errorf("PruneItems = %+v, want one item moved to the system bin", result)
+	}
+	if len(stub.moved) != 1 {
+		t.Fatalf("moved %d items, want 1", len(stub.moved))
+	}
+	// 系统回收站按移入的时间计算保留期限，固定标记不随项目转移
+	if moved := stub.moved[0]; moved.Pinned || moved.DeletedTime.Before(before) {
+		t.Errorf("moved metadata = pinned %v, deleted %v; want unpinned and deleted now", moved.Pinned, moved.DeletedTime)
+	}
+	if _, err := os.Lstat(file.TrashPath); !os.IsNotExist(err) {
+		t.Errorf("DelGuard trash still holds the moved item: %v", err)
+	}
+}
+
+func TestPruneItemsExpandsCompressedItemsForSystemBin(t *testing.T) {
+	stub, _ := newSystemBinStub(t, compressibleContent)
+	compactAll(t, stub)
+	file := onlyTrashFile(t, stub)
+	if !isGzipFile(file.TrashPath) {
+		t.Fatal("item was not compacted")
+	}
+
+	if _, err := PruneItems(stub, []TrashFile{file}, true); err != nil {
+		t.Fatalf("PruneItems: %v", err)
+	}
+	if len(stub.moved) != 1 || stub.moved[0].Compressed || stub.contents[0] != compressibleContent {
+		t.Errorf("system bin received compressed %v content of %d bytes, want the original", stub.moved[0].Compressed, len(stub.contents[0]))
+	}
+}
+
+func TestPruneItemsKeepsItemWhenMoveFails(t *testing.T) {
+	stub, file := newSystemBinStub(t, "old")
+	stub.moveErr = fmt.Errorf("disk full")
+
+	result, err := PruneItems(stub, []TrashFile{file}, true)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("PruneItems err = %v, want the move failure", err)
+	}
+	if result.ToSystemBin != 0 || result.Destroyed != 0 {
+		t.Errorf("PruneItems = %+v, want nothing pruned", result)
+	}
+	assertKept(t, stub, file)
+}
@@ -0,0 +1,171 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// QuarantineDirName 永久删除前校验发现内容损坏的项目移入的隔离目录，位于项目所在的回收站目录下
+// 以点开头，不会出现在回收站列表中，也不会被清理和轮转删除
+const QuarantineDirName = ".quarantine"
+
+// 永久删除前校验后对项目的处理
+const (
+	PruneDestroyed   = "destroyed"
+	PruneQuarantined = "quarantined"
+	PruneKept        = "kept" // 校验、隔离或删除失败，项目仍留在回收站中
+)
+
+var (
+	pruneVerifyMu      sync.RWMutex
+	pruneVerify        bool
+	pruneVerifyWorkers int
+)
+
+// SetVerifyBeforePrune 设置轮转和清理永久删除项目前是否重新计算哈希并与元数据比对
+// workers为并行计算哈希的数量，0表示按回收站所在的存储自动选择
+func SetVerifyBeforePrune(enabled bool, workers int) {
+	pruneVerifyMu.Lock()
+	defer pruneVerifyMu.Unlock()
+	pruneVerify = enabled
+	pruneVerifyWorkers = workers
+}
+
+// VerifyBeforePrune 轮转和清理永久删除项目前是否先校验内容
+func VerifyBeforePrune() bool {
+	pruneVerifyMu.RLock()
+	defer pruneVerifyMu.RUnlock()
+	return pruneVerify
+}
+
+// PruneCheck 永久删除前对一个项目的校验和处理结果
+type PruneCheck struct {
+	File TrashFile
+	// Result 校验结果: VerifyOK、VerifyCorrupt或VerifyUnhashed，校验出错时为空
+	Result string
+	// Hash 元数据中记录的哈希
+	Hash string
+	// Outcome PruneDestroyed、PruneQuarantined或PruneKept，尚未处理时为空
+	Outcome string
+	// Quarantine 内容损坏的项目移入隔离目录后的路径
+	Quarantine string
+	// Err 校验、隔离或删除失败的原因
+	Err error
+}
+
+// PruneChecks 一次轮转或清理中所有项目的校验结果
+type PruneChecks []*PruneCheck
+
+// Verified 哈希与元数据一致的项目数
+func (c PruneChecks) Verified() int {
+	count := 0
+	for _, check := range c {
+		if check.Result == VerifyOK {
+			count++
+		}
+	}
+	return count
+}
+
+// Quarantined 内容损坏而移入隔离目录的项目
+func (c PruneChecks) Quarantined() []*PruneCheck {
+	var quarantined []*PruneCheck
+	for _, check := range c {
+		if check.Outcome == PruneQuarantined {
+			quarantined = append(quarantined, check)
+		}
+	}
+	return quarantined
+}
+
+// checkBeforeDestroy 为即将永久删除的files逐个生成处理结果，顺序与files一致
+// 启用verify_before_prune时先并行重新计算哈希：内容损坏的项目移入隔离目录，无法校验的项目保留，
+// 两者的Outcome已确定，调用方只删除Outcome为空的项目；未启用时verified为false，所有项目都待删除
+func checkBeforeDestroy(manager TrashManager, files []TrashFile) (checks PruneChecks, verified bool) {
+	checks = make(PruneChecks, len(files))
+	for i, file := range files {
+		checks[i] = &PruneCheck{File: file}
+	}
+	pruneVerifyMu.RLock()
+	enabled, workers := pruneVerify, pruneVerifyWorkers
+	pruneVerifyMu.RUnlock()
+	if !enabled || len(files) == 0 {
+		return checks, false
+	}
+
+	if workers <= 0 {
+		workers = 1
+		if trashPath, err := manager.GetTrashPath(); err == nil {
+			workers, _ = AdaptiveWorkers(trashPath)
+		}
+	}
+	jobs := make([]*integrityJob, len(files))
+	for i, file := range files {
+		jobs[i] = &integrityJob{file: file, metadata: LoadMetadata(manager, file)}
+	}
+	runIntegrityJobs(jobs, workers)
+
+	for i, job := range jobs {
+		check := checks[i]
+		check.Hash = job.metadata.Hash
+		if job.err != nil {
+			// 无法读取的内容同样可能意味着磁盘故障，不在此时删除
+			check.Outcome = PruneKept
+			check.Err = fmt.Errorf("校验失败: %v", job.err)
+			continue
+		}
+		check.Result = job.result
+		if job.result != VerifyCorrupt {
+			continue
+		}
+		quarantine, err := quarantineItem(job.file, job.metadata)
+		if err != nil {
+			check.Outcome = PruneKept
+			check.Err = err
+			continue
+		}
+		check.Outcome = PruneQuarantined
+		check.Quarantine = quarantine
+	}
+	return checks, true
+}
+
+// quarantineItem 将内容损坏的回收站项目移入同一回收站目录下的隔离目录，
+// 元数据写在旁边的同名.json文件中并标记为损坏，原有的.trashinfo和元数据被清理
+func quarantineItem(file TrashFile, metadata TrashMetadata) (string, error) {
+	dir := filepath.Join(filepath.Dir(file.TrashPath), QuarantineDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", errors.FromOS("创建隔离目录失败", err)
+	}
+	taken := func(path string) bool {
+		_, err := os.Lstat(path + ".json")
+		return err == nil
+	}
+	dest := filepath.Join(dir, filepath.Base(file.TrashPath))
+	if _, exists := ExistingEntry(dest); exists || taken(dest) {
+		dest = ConflictFreePath(dest, taken)
+	}
+
+	verified := time.Now()
+	metadata.LastVerified = &verified
+	metadata.VerifyResult = VerifyCorrupt
+	data, err := json.MarshalIndent(withPortablePath(metadata), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化元数据失败: %v", err)
+	}
+	if err := os.WriteFile(dest+".json", data, 0600); err != nil {
+		return "", errors.FromOS("写入隔离项目的元数据失败", err)
+	}
+	if err := os.Rename(file.TrashPath, dest); err != nil {
+		os.Remove(dest + ".json")
+		return "", errors.FromOS("移入隔离目录失败", err)
+	}
+	removeTrashMetadata(file.TrashPath)
+	return dest, nil
+}
@@ -0,0 +1,259 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useVerifyBeforePrune 启用永久删除前校验，测试结束后恢复
+func useVerifyBeforePrune(t *testing.T) {
+	t.Helper()
+	SetVerifyBeforePrune(true, 1)
+	t.Cleanup(func() { SetVerifyBeforePrune(false, 0) })
+}
+
+// checkFor 返回名为name的项目的校验结果
+func checkFor(t *testing.T, checks PruneChecks, name string) *PruneCheck {
+	t.Helper()
+	for _, check := range checks {
+		if check.File.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no prune check for %s", name)
+	return nil
+}
+
+func TestPruneQuarantinesCorruptItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		compact  bool
+		tamper   func(t *testing.T, path string)
+		contents string
+	}{
+		{
+			name:     "plain",
+			contents: "quarterly numbers",
+			tamper: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("bit rot"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name:     "compressed",
+			compact:  true,
+			contents: compressibleContent,
+			tamper: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, gzipBytes(t, compressibleContent+"bit rot"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useVerifyBeforePrune(t)
+			manager := osRotationManager(t)
+			for _, name := range []string{"good.txt", "bad.txt"} {
+				if err := manager.MoveToTrash(writeContractFile(t, name, tt.contents)); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+			}
+			if tt.compact {
+				if report := compactAll(t, manager); report.Compacted != 2 {
+					t.Fatalf("CompactTrash = %+v, want both items compacted", report)
+				}
+			}
+			if _, err := VerifyIntegrity(manager, IntegrityOptions{All: true}); err != nil {
+				t.Fatalf("VerifyIntegrity: %v", err)
+			}
+			bad := trashFileNamed(t, manager, "bad.txt")
+			tt.tamper(t, bad.TrashPath)
+
+			files, err := manager.ListTrashFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := PruneItems(manager, files, false)
+			if err != nil {
+				t.Fatalf("PruneItems: %v", err)
+			}
+			if result.Destroyed != 1 || result.Checks.Verified() != 1 {
+				t.Errorf("PruneItems = %+v, want only the verified item destroyed", result)
+			}
+
+			if good := checkFor(t, result.Checks, "good.txt"); good.Result != VerifyOK || good.Outcome != PruneDestroyed {
+				t.Errorf("good.txt = result %q, outcome %q; want ok and destroyed", good.Result, good.Outcome)
+			}
+			check := checkFor(t, result.Checks, "bad.txt")
+			if check.Result != VerifyCorrupt || check.Outcome != PruneQuarantined {
+				t.Fatalf("bad.txt = result %q, outcome %q (%v); want corrupt and quarantined", check.Result, check.Outcome, check.Err)
+			}
+			if quarantined := result.Checks.Quarantined(); len(quarantined) != 1 || quarantined[0] != check {
+				t.Errorf("Quarantined() = %v, want bad.txt", quarantined)
+			}
+
+			// 损坏的内容原样保留在隔离目录中，不再出现在回收站列表里
+			if filepath.Base(filepath.Dir(check.Quarantine)) != QuarantineDirName {
+				t.Errorf("quarantined to %s, want a %s directory", check.Quarantine, QuarantineDirName)
+			}
+			if _, err := os.Lstat(check.Quarantine); err != nil {
+				t.Errorf("quarantined content missing: %v", err)
+			}
+			data, err := os.ReadFile(check.Quarantine + ".json")
+			if err != nil {
+				t.Fatalf("quarantine metadata: %v", err)
+			}
+			var metadata TrashMetadata
+			if err := json.Unmarshal(data, &metadata); err != nil {
+				t.Fatal(err)
+			}
+			if metadata.VerifyResult != VerifyCorrupt || metadata.Hash != check.Hash || metadata.Compressed != tt.compact {
+				t.Errorf("quarantine metadata = result %q, hash %q, compressed %v", metadata.VerifyResult, metadata.Hash, metadata.Compressed)
+			}
+			if found := trashEntriesFor(t, manager, bad.TrashPath); len(found) != 2 {
+				t.Errorf("entries for bad.txt = %q, want only the quarantined content and metadata", found)
+			}
+			if remaining, err := manager.ListTrashFiles(); err != nil || len(remaining) != 0 {
+				t.Errorf("ListTrashFiles after prune = %v, %v; want empty", remaining, err)
+			}
+		})
+	}
+}
+
+func TestPruneDestroysItemsWithoutHash(t *testing.T) {
+	useVerifyBeforePrune(t)
+	manager := osRotationManager(t)
+	if err := manager.MoveToTrash(writeContractFile(t, "plain.txt", "no baseline")); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	// 删除时没有记录哈希的项目无从比对，不能当作损坏隔离
+	file := onlyTrashFile(t, manager)
+	metadata := LoadMetadata(manager, file)
+	metadata.Hash = ""
+	if err := manager.WriteItemMetadata(file, metadata); err != nil {
+		t.Fatalf("WriteItemMetadata: %v", err)
+	}
+
+	result, err := PruneItems(manager, []TrashFile{file}, false)
+	if err != nil {
+		t.Fatalf("PruneItems: %v", err)
+	}
+	check := checkFor(t, result.Checks, "plain.txt")
+	if check.Result != VerifyUnhashed || check.Outcome != PruneDestroyed || result.Destroyed != 1 {
+		t.Errorf("plain.txt = result %q, outcome %q; want unhashed and destroyed", check.Result, check.Outcome)
+	}
+}
+
+func TestPruneKeepsItemsThatCannotBeVerified(t *testing.T) {
+	useVerifyBeforePrune(t)
+	manager := osRotationManager(t)
+	if err := manager.MoveToTrash(writeContractFile(t, "report.txt", "keep me")); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	file := onlyTrashFile(t, manager)
+	// 列表之后内容消失，校验读取失败时不删除元数据
+	missing := file
+	missing.TrashPath = file.TrashPath + ".gone"
+
+	result, err := PruneItems(manager, []TrashFile{missing}, false)
+	if err != nil {
+		t.Fatalf("PruneItems: %v", err)
+	}
+	check := checkFor(t, result.Checks, "report.txt")
+	if check.Outcome != PruneKept || check.Err == nil || result.Destroyed != 0 {
+		t.Errorf("report.txt = outcome %q, err %v; want kept with the reason", check.Outcome, check.Err)
+	}
+	if _, err := os.Lstat(file.TrashPath); err != nil {
+		t.Errorf("trash content removed: %v", err)
+	}
+}
+
+func TestPruneWithoutVerificationDestroysCorruptItems(t *testing.T) {
+	manager := osRotationManager(t)
+	if err := manager.MoveToTrash(writeContractFile(t, "bad.txt", "original")); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	if _, err := VerifyIntegrity(manager, IntegrityOptions{All: true}); err != nil {
+		t.Fatalf("VerifyIntegrity: %v", err)
+	}
+	file := onlyTrashFile(t, manager)
+	if err := os.WriteFile(file.TrashPath, []byte("bit rot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := PruneItems(manager, []TrashFile{file}, false)
+	if err != nil {
+		t.Fatalf("PruneItems: %v", err)
+	}
+	if result.Destroyed != 1 || result.Checks != nil {
+		t.Errorf("PruneItems = %+v, want the item destroyed without checks", result)
+	}
+}
+
+func TestRotateTrashQuarantinesCorruptItems(t *testing.T) {
+	useVerifyBeforePrune(t)
+	manager := osRotationManager(t)
+	for _, name := range []string{"first.txt", "second.txt"} {
+		if err := manager.MoveToTrash(writeContractFile(t, name, "original")); err != nil {
+			t.Fatalf("MoveToTrash: %v", err)
+		}
+	}
+	if _, err := VerifyIntegrity(manager, IntegrityOptions{All: true}); err != nil {
+		t.Fatalf("VerifyIntegrity: %v", err)
+	}
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if err := os.WriteFile(file.TrashPath, []byte("bit rot"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	useRotationPolicy(t, CountRotation{MaxItems: 1})
+
+	result, err := RotateTrash(manager, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("RotateTrash: %v", err)
+	}
+	if len(result.Removed) != 0 || len(result.Checks.Quarantined()) != 1 {
+		t.Errorf("RotateTrash removed %d, quarantined %d; want the corrupt item quarantined", len(result.Removed), len(result.Checks.Quarantined()))
+	}
+	if remaining, err := manager.ListTrashFiles(); err != nil || len(remaining) != 1 {
+		t.Errorf("ListTrashFiles after rotation = %v, %v; want the newer item kept", remaining, err)
+	}
+}
+
+func TestQuarantineItemAvoidsNameConflicts(t *testing.T) {
+	filesDir := filepath.Join(t.TempDir(), "files")
+	quarantineDir := filepath.Join(filesDir, QuarantineDirName)
+	if err := os.MkdirAll(quarantineDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	trashPath := filepath.Join(filesDir, "dup.txt")
+	if err := os.WriteFile(trashPath, []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(quarantineDir, "dup.txt"), []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest, err := quarantineItem(TrashFile{Name: "dup.txt", TrashPath: trashPath}, TrashMetadata{FileName: "dup.txt"})
+	if err != nil {
+		t.Fatalf("quarantineItem: %v", err)
+	}
+	if dest == filepath.Join(quarantineDir, "dup.txt") || filepath.Dir(dest) != quarantineDir {
+		t.Errorf("quarantined to %s, want a new name in %s", dest, quarantineDir)
+	}
+	if data, err := os.ReadFile(filepath.Join(quarantineDir, "dup.txt")); err != nil || string(data) != "first" {
+		t.Errorf("earlier quarantined item = %q, %v; want it untouched", data, err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "second" {
+		t.Errorf("quarantined content = %q, %v", data, err)
+	}
+}
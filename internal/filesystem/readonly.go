@@ -0,0 +1,94 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"delguard/internal/errors"
+)
+
+// ownerWritable 所有者写权限位
+const ownerWritable = 0200
+
+// renameWritable 重命名文件或目录，因只读权限失败时临时加上所有者写权限后重试
+// 移动完成后在回收站中的副本上恢复原权限，恢复时文件仍保持只读
+func renameWritable(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !os.IsPermission(err) {
+		return err
+	}
+
+	info, statErr := os.Lstat(src)
+	if statErr != nil || info.Mode()&os.ModeSymlink != 0 || info.Mode().Perm()&ownerWritable != 0 {
+		return errors.NewError(errors.ErrTypePermissionDenied, fmt.Sprintf("权限不足: %s", src), err)
+	}
+
+	mode := info.Mode() & restorableModeBits
+	if chmodErr := os.Chmod(src, mode|ownerWritable); chmodErr != nil {
+		return errors.NewError(errors.ErrTypePermissionDenied, fmt.Sprintf("权限不足: %s", src), err)
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		os.Chmod(src, mode)
+		return errors.NewError(errors.ErrTypePermissionDenied, fmt.Sprintf("权限不足: %s", src), err)
+	}
+
+	os.Chmod(dst, mode)
+	return nil
+}
+
+// removeAllWritable 删除文件或目录树，遇到只读文件或目录时先加上所有者写权限
+// 仍无法删除的项目逐个汇总后返回，不会在第一个失败处中止
+func removeAllWritable(path string) error {
+	if err := os.RemoveAll(path); err == nil {
+		return nil
+	}
+
+	// 目录需要读、写、执行权限才能列出并删除其中的项目
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		perm := info.Mode() & restorableModeBits
+		want := perm | ownerWritable
+		if info.IsDir() {
+			want |= 0700
+		}
+		if want != perm {
+			os.Chmod(p, want)
+		}
+		return nil
+	})
+
+	if err := os.RemoveAll(path); err == nil {
+		return nil
+	}
+
+	// 从最深处开始逐个删除，收集每个失败的项目
+	var paths []string
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		paths = append(paths, p)
+		return nil
+	})
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+
+	collector := errors.NewErrorCollector()
+	blocked := make(map[string]bool)
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			// 子项删除失败导致的目录非空不再重复报告
+			if !blocked[p] {
+				collector.Add(p, err)
+			}
+			blocked[filepath.Dir(p)] = true
+		}
+	}
+	return collector.Err()
+}
+
+// isPermissionDenied 检查错误是否为权限不足
+func isPermissionDenied(err error) bool {
+	return errors.IsType(err, errors.ErrTypePermissionDenied) || os.IsPermission(err)
+}
@@ -0,0 +1,230 @@
+package filesystem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"delguard/internal/errors"
+)
+
+// 回收站项目的来源
+const (
+	SourceDelGuard = "delguard" // DelGuard管理的回收站
+	SourceSystem   = "system"   // Windows系统回收站($Recycle.Bin)，由资源管理器等其他程序删除
+)
+
+// recycleInfo Windows回收站$I文件中记录的原始路径、大小和删除时间
+type recycleInfo struct {
+	OriginalPath string
+	Size         int64
+	DeletedTime  time.Time
+}
+
+// filetimeEpochOffset 1601-01-01与1970-01-01之间相差的100纳秒间隔数
+const filetimeEpochOffset = 116444736000000000
+
+// parseRecycleInfo 解析$I文件，支持Vista到Windows 8使用的版本1（固定260个字符的路径）
+// 和Windows 10起使用的版本2（路径前记录长度）
+func parseRecycleInfo(data []byte) (recycleInfo, error) {
+	const header = 24
+	if len(data) < header {
+		return recycleInfo{}, fmt.Errorf("$I文件过短: %d 字节", len(data))
+	}
+	version := binary.LittleEndian.Uint64(data[0:8])
+	info := recycleInfo{
+		Size:        int64(binary.LittleEndian.Uint64(data[8:16])),
+		DeletedTime: filetimeToTime(binary.LittleEndian.Uint64(data[16:24])),
+	}
+
+	var name []byte
+	switch version {
+	case 1:
+		name = data[header:]
+		if len(name) > 520 {
+			name = name[:520]
+		}
+	case 2:
+		if len(data) < header+4 {
+			return recycleInfo{}, fmt.Errorf("$I文件缺少路径长度")
+		}
+		chars := int(binary.LittleEndian.Uint32(data[header : header+4]))
+		name = data[header+4:]
+		if chars*2 > len(name) {
+			return recycleInfo{}, fmt.Errorf("$I文件路径长度 %d 超出文件大小", chars)
+		}
+		name = name[:chars*2]
+	default:
+		return recycleInfo{}, fmt.Errorf("不支持的$I文件版本: %d", version)
+	}
+
+	info.OriginalPath = decodeUTF16(name)
+	if info.OriginalPath == "" {
+		return recycleInfo{}, fmt.Errorf("$I文件中没有原始路径")
+	}
+	return info, nil
+}
+
+// filetimeToTime 将Windows FILETIME（自1601年起的100纳秒间隔数）转换为时间
+func filetimeToTime(filetime uint64) time.Time {
+	if filetime < filetimeEpochOffset {
+		return time.Time{}
+	}
+	intervals := int64(filetime - filetimeEpochOffset)
+	return time.Unix(intervals/10000000, intervals%10000000*100)
+}
+
+// decodeUTF16 解码小端UTF-16字符串，到第一个NUL字符为止
+func decodeUTF16(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i : i+2])
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+	return string(utf16.Decode(units))
+}
+
+// listRecycleDir 列出一个系统回收站目录（<驱动器>\$Recycle.Bin\<SID>）中成对的$I/$R项目
+// 缺少$R文件或无法解析的$I文件会被跳过
+func listRecycleDir(dir string) ([]TrashFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []TrashFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "$I") {
+			continue
+		}
+		dataName := "$R" + name[2:]
+		dataPath := filepath.Join(dir, dataName)
+		dataInfo, err := os.Lstat(dataPath)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		info, err := parseRecycleInfo(data)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, TrashFile{
+			ID:           dataName,
+			Name:         filepath.Base(strings.ReplaceAll(info.OriginalPath, `\`, "/")),
+			OriginalPath: info.OriginalPath,
+			TrashPath:    dataPath,
+			Size:         info.Size,
+			DeletedTime:  info.DeletedTime,
+			IsDirectory:  dataInfo.IsDir(),
+			Permissions:  dataInfo.Mode().String(),
+			Source:       SourceSystem,
+		})
+	}
+	return files, nil
+}
+
+// SystemBinFiles 列出当前用户在各个驱动器的Windows系统回收站中的项目，Source为SourceSystem
+// 只读取$I/$R文件，不修改系统回收站；其他平台返回空列表
+func SystemBinFiles() ([]TrashFile, error) {
+	var files []TrashFile
+	for _, dir := range systemRecycleDirs() {
+		items, err := listRecycleDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return files, errors.FromOS(fmt.Sprintf("读取系统回收站 %s 失败", dir), err)
+		}
+		files = append(files, items...)
+	}
+	return files, nil
+}
+
+// Origin 返回项目来源，未标记来源的项目属于DelGuard回收站
+func (f TrashFile) Origin() string {
+	if f.Source == "" {
+		return SourceDelGuard
+	}
+	return f.Source
+}
+
+// restoreFromSystemBin 恢复Windows系统回收站中的项目
+// 恢复到空闲的原位置时通过Shell的undelete动词恢复，资源管理器的回收站视图随之更新；
+// 恢复到其他位置、原位置已被占用或Shell调用失败时直接移出$R文件并删除对应的$I文件
+func (w *WindowsTrashManager) restoreFromSystemBin(file TrashFile, targetPath string) error {
+	if _, err := os.Lstat(file.TrashPath); err != nil {
+		return errors.FromOS("系统回收站中的项目不存在", err)
+	}
+	if targetPath == "" {
+		targetPath = file.OriginalPath
+	}
+	targetPath, err := filepath.Abs(targetPath)
+	if err != nil {
+		return errors.FromOS("获取目标路径失败", err)
+	}
+	if err := w.validatePath(targetPath); err != nil {
+		return fmt.Errorf("目标路径验证失败: %v", err)
+	}
+	if err := ValidateRestoreTarget(w, targetPath); err != nil {
+		return err
+	}
+
+	_, exists := ExistingEntry(targetPath)
+	if strings.EqualFold(targetPath, filepath.Clean(file.OriginalPath)) && !exists {
+		if err := w.undeleteWithShell(file.TrashPath); err == nil {
+			return nil
+		}
+	}
+
+	if exists && !w.forceOverwrite {
+		targetPath = ConflictFreePath(targetPath, nil)
+	}
+	if err := CreateDirIfNotExists(filepath.Dir(targetPath)); err != nil {
+		return errors.FromOS("创建目标目录失败", err)
+	}
+	if _, err := w.moveFileWithProgress(file.TrashPath, targetPath); err != nil {
+		return errors.FromOS("恢复文件失败", err)
+	}
+	dir, name := filepath.Split(file.TrashPath)
+	os.Remove(filepath.Join(dir, "$I"+strings.TrimPrefix(name, "$R")))
+	return nil
+}
+
+// undeleteWithShell 通过Shell.Application在回收站(ssfBITBUCKET)中找到$R路径对应的项目并执行undelete
+func (w *WindowsTrashManager) undeleteWithShell(trashPath string) error {
+	psScript := `& {
+param($ItemPath)
+$ErrorActionPreference = "Stop"
+$shell = New-Object -ComObject Shell.Application
+$bin = $shell.Namespace(10)
+foreach ($item in $bin.Items()) {
+    if ($item.Path -eq $ItemPath) {
+        $item.InvokeVerb("undelete")
+        exit 0
+    }
+}
+Write-Error "回收站中没有找到项目: $ItemPath"
+exit 1
+} ` + "'" + strings.ReplaceAll(trashPath, "'", "''") + "'"
+
+	output, err := w.runner.Run("powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", psScript)
+	if err != nil {
+		return classifyShellError("通过Shell恢复失败", err, output)
+	}
+	if _, err := os.Lstat(trashPath); err == nil {
+		return fmt.Errorf("Shell恢复后项目仍在回收站中: %s", trashPath)
+	}
+	return nil
+}
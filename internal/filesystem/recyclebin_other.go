@@ -0,0 +1,8 @@
+//go:build !windows
+
+package filesystem
+
+// systemRecycleDirs 只有Windows有$Recycle.Bin，其他平台返回空
+func systemRecycleDirs() []string {
+	return nil
+}
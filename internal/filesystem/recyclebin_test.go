@@ -0,0 +1,109 @@
+package filesystem
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// recycleRecord 按Windows的格式生成$I文件内容，version为1时路径固定占260个字符
+func recycleRecord(version uint64, path string, size int64, deleted time.Time) []byte {
+	units := append(utf16.Encode([]rune(path)), 0)
+	data := make([]byte, 24)
+	binary.LittleEndian.PutUint64(data[0:8], version)
+	binary.LittleEndian.PutUint64(data[8:16], uint64(size))
+	binary.LittleEndian.PutUint64(data[16:24], uint64(deleted.UnixNano()/100)+filetimeEpochOffset)
+	switch version {
+	case 1:
+		units = append(units, make([]uint16, 260-len(units))...)
+	default:
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(units)))
+	}
+	for _, unit := range units {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+	return data
+}
+
+func TestParseRecycleInfo(t *testing.T) {
+	deleted := time.Date(2024, 5, 6, 7, 8, 9, 123456700, time.UTC)
+	path := `C:\Users\李雷\Documents\季度报告.xlsx`
+	for _, version := range []uint64{1, 2} {
+		info, err := parseRecycleInfo(recycleRecord(version, path, 4096, deleted))
+		if err != nil {
+			t.Fatalf("version %d: parseRecycleInfo: %v", version, err)
+		}
+		if info.OriginalPath != path || info.Size != 4096 || !info.DeletedTime.Equal(deleted) {
+			t.Errorf("version %d: parseRecycleInfo() = %+v, want %s, 4096 bytes, %v", version, info, path, deleted)
+		}
+	}
+}
+
+func TestParseRecycleInfoRejectsMalformedRecords(t *testing.T) {
+	valid := recycleRecord(2, `D:\data\a.txt`, 1, time.Now())
+	tooLong := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint32(tooLong[24:28], 1000)
+	unknownVersion := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint64(unknownVersion[0:8], 3)
+
+	tests := map[string][]byte{
+		"short header":       valid[:20],
+		"missing length":     valid[:26],
+		"length past end":    tooLong,
+		"unknown version":    unknownVersion,
+		"empty version 1":    recycleRecord(1, "", 0, time.Now()),
+		"empty version 2":    recycleRecord(2, "", 0, time.Now()),
+		"truncated version2": valid[:len(valid)-4],
+	}
+	for name, data := range tests {
+		if info, err := parseRecycleInfo(data); err == nil {
+			t.Errorf("%s: parseRecycleInfo() = %+v, want an error", name, info)
+		}
+	}
+}
+
+func TestFiletimeToTime(t *testing.T) {
+	if got := filetimeToTime(filetimeEpochOffset); !got.Equal(time.Unix(0, 0)) {
+		t.Errorf("filetimeToTime(epoch) = %v, want 1970-01-01", got)
+	}
+	if got := filetimeToTime(1); !got.IsZero() {
+		t.Errorf("filetimeToTime before 1970 = %v, want zero time", got)
+	}
+}
+
+func TestListRecycleDir(t *testing.T) {
+	dir := t.TempDir()
+	deleted := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	write := func(name string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("$IABC123.txt", recycleRecord(2, `C:\Users\alice\notes.txt`, 5, deleted))
+	write("$RABC123.txt", []byte("notes"))
+	// 没有对应$R文件和无法解析的$I文件都被跳过
+	write("$IORPHAN.txt", recycleRecord(2, `C:\Users\alice\orphan.txt`, 1, deleted))
+	write("$IBROKEN.txt", []byte("garbage"))
+	write("$RBROKEN.txt", []byte("x"))
+	write("desktop.ini", []byte("[.ShellClassInfo]"))
+
+	files, err := listRecycleDir(dir)
+	if err != nil {
+		t.Fatalf("listRecycleDir: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("listRecycleDir() = %+v, want only the complete pair", files)
+	}
+	file := files[0]
+	if file.ID != "$RABC123.txt" || file.Name != "notes.txt" || file.OriginalPath != `C:\Users\alice\notes.txt` ||
+		file.TrashPath != filepath.Join(dir, "$RABC123.txt") || file.Size != 5 || !file.DeletedTime.Equal(deleted) {
+		t.Errorf("listRecycleDir() = %+v", file)
+	}
+	if file.Origin() != SourceSystem || (TrashFile{}).Origin() != SourceDelGuard {
+		t.Errorf("Origin() = %q and %q, want system and delguard", file.Origin(), (TrashFile{}).Origin())
+	}
+}
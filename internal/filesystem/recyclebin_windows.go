@@ -0,0 +1,39 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// systemRecycleDirs 返回本机固定磁盘和可移动磁盘上当前用户的 $Recycle.Bin\<SID> 目录
+func systemRecycleDirs() []string {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil
+	}
+	sid := user.User.Sid.String()
+	drives, err := windows.GetLogicalDrives()
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for i := 0; i < 26; i++ {
+		if drives&(1<<uint(i)) == 0 {
+			continue
+		}
+		root := string(rune('A'+i)) + `:\`
+		rootPtr, err := windows.UTF16PtrFromString(root)
+		if err != nil {
+			continue
+		}
+		switch windows.GetDriveType(rootPtr) {
+		case windows.DRIVE_FIXED, windows.DRIVE_REMOVABLE:
+			dirs = append(dirs, filepath.Join(root, "$Recycle.Bin", sid))
+		}
+	}
+	return dirs
+}
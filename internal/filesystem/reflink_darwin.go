@@ -0,0 +1,19 @@
+//go:build darwin
+
+package filesystem
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile 使用clonefile克隆文件（APFS）
+func cloneFile(src, dst string) error {
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
+
+// isReflinkUnsupported 判断错误是否表示目标文件系统不支持reflink
+func isReflinkUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOSYS)
+}
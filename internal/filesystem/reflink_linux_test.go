@@ -0,0 +1,14 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"os"
+	"testing"
+)
+
+// TestCloneFileFICLONE 在DELGUARD_REFLINK_DIR（如Btrfs、XFS上的目录）或临时目录中通过FICLONE克隆文件，
+// 文件系统不支持时跳过
+func TestCloneFileFICLONE(t *testing.T) {
+	testCloneIn(t, os.Getenv("DELGUARD_REFLINK_DIR"))
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !windows
+
+package filesystem
+
+import "errors"
+
+// errReflinkUnsupported 当前平台不支持reflink
+var errReflinkUnsupported = errors.New("当前平台不支持reflink")
+
+// cloneFile 当前平台不支持reflink，总是返回错误
+func cloneFile(src, dst string) error {
+	return errReflinkUnsupported
+}
+
+// isReflinkUnsupported 判断错误是否表示目标文件系统不支持reflink
+func isReflinkUnsupported(err error) bool {
+	return errors.Is(err, errReflinkUnsupported)
+}
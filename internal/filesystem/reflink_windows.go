@@ -0,0 +1,165 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileSupportsBlockRefcounting 卷支持块克隆（ReFS）时GetVolumeInformationByHandle返回的文件系统标志
+const fileSupportsBlockRefcounting = 0x08000000
+
+// fsctlSetIntegrityInformation 设置文件完整性流的控制码，x/sys/windows中没有定义
+const fsctlSetIntegrityInformation = 0x0009C280
+
+// cloneChunkSize 单次FSCTL_DUPLICATE_EXTENTS_TO_FILE克隆的字节数，必须小于4GB且是簇大小的整数倍
+const cloneChunkSize = 1 << 30
+
+var (
+	// errReflinkUnsupported 目标卷不支持块克隆
+	errReflinkUnsupported = errors.New("目标卷不支持块克隆")
+	// errSourceNotCloneable 源文件所在的卷不支持块克隆，与目标无关，不缓存
+	errSourceNotCloneable = errors.New("源文件所在的卷不支持块克隆")
+	// errNotSameVolume 块克隆只能在同一个卷内进行，与目标无关，不缓存
+	errNotSameVolume = errors.New("源文件和目标不在同一个卷上")
+)
+
+// integrityInformation FSCTL_GET_INTEGRITY_INFORMATION_BUFFER
+type integrityInformation struct {
+	ChecksumAlgorithm        uint16
+	Reserved                 uint16
+	Flags                    uint32
+	ChecksumChunkSizeInBytes uint32
+	ClusterSizeInBytes       uint32
+}
+
+// setIntegrityInformation FSCTL_SET_INTEGRITY_INFORMATION_BUFFER
+type setIntegrityInformation struct {
+	ChecksumAlgorithm uint16
+	Reserved          uint16
+	Flags             uint32
+}
+
+// duplicateExtentsData DUPLICATE_EXTENTS_DATA，32位系统上句柄之后按LARGE_INTEGER对齐补齐
+type duplicateExtentsData struct {
+	FileHandle       windows.Handle
+	_                [8 - unsafe.Sizeof(windows.Handle(0))]byte
+	SourceFileOffset int64
+	TargetFileOffset int64
+	ByteCount        int64
+}
+
+// handleVolume 返回已打开文件所在卷的序列号和文件系统标志
+func handleVolume(handle windows.Handle) (serial, flags uint32, err error) {
+	err = windows.GetVolumeInformationByHandle(handle, nil, 0, &serial, nil, &flags, nil, 0)
+	return serial, flags, err
+}
+
+// cloneFile 使用FSCTL_DUPLICATE_EXTENTS_TO_FILE块克隆文件（ReFS、Dev Drive）
+// 目标按源文件设置稀疏属性和完整性流，克隆范围按簇向上取整，最后一个不完整的簇随文件末尾一起克隆
+func cloneFile(src, dst string) (err error) {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	srcHandle := windows.Handle(srcFile.Fd())
+
+	srcSerial, flags, err := handleVolume(srcHandle)
+	if err != nil {
+		return err
+	}
+	if flags&fileSupportsBlockRefcounting == 0 {
+		return errSourceNotCloneable
+	}
+
+	var srcInfo windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(srcHandle, &srcInfo); err != nil {
+		return err
+	}
+	var integrity integrityInformation
+	var returned uint32
+	if err := windows.DeviceIoControl(srcHandle, windows.FSCTL_GET_INTEGRITY_INFORMATION, nil, 0,
+		(*byte)(unsafe.Pointer(&integrity)), uint32(unsafe.Sizeof(integrity)), &returned, nil); err != nil {
+		return err
+	}
+	info, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := dstFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	dstHandle := windows.Handle(dstFile.Fd())
+
+	dstSerial, flags, err := handleVolume(dstHandle)
+	if err != nil {
+		return err
+	}
+	if flags&fileSupportsBlockRefcounting == 0 {
+		return errReflinkUnsupported
+	}
+	if dstSerial != srcSerial {
+		return errNotSameVolume
+	}
+
+	if srcInfo.FileAttributes&windows.FILE_ATTRIBUTE_SPARSE_FILE != 0 {
+		if err := windows.DeviceIoControl(dstHandle, windows.FSCTL_SET_SPARSE, nil, 0, nil, 0, &returned, nil); err != nil {
+			return err
+		}
+	}
+	setIntegrity := setIntegrityInformation{ChecksumAlgorithm: integrity.ChecksumAlgorithm, Flags: integrity.Flags}
+	if err := windows.DeviceIoControl(dstHandle, fsctlSetIntegrityInformation,
+		(*byte)(unsafe.Pointer(&setIntegrity)), uint32(unsafe.Sizeof(setIntegrity)), nil, 0, &returned, nil); err != nil {
+		return err
+	}
+
+	size := info.Size()
+	if err := windows.SetFileInformationByHandle(dstHandle, windows.FileEndOfFileInfo,
+		(*byte)(unsafe.Pointer(&size)), uint32(unsafe.Sizeof(size))); err != nil {
+		return err
+	}
+
+	cluster := int64(integrity.ClusterSizeInBytes)
+	if cluster <= 0 {
+		return errReflinkUnsupported
+	}
+	for offset := int64(0); offset < size; {
+		count := (size - offset + cluster - 1) / cluster * cluster
+		if count > cloneChunkSize {
+			count = cloneChunkSize
+		}
+		extents := duplicateExtentsData{
+			FileHandle:       srcHandle,
+			SourceFileOffset: offset,
+			TargetFileOffset: offset,
+			ByteCount:        count,
+		}
+		if err := windows.DeviceIoControl(dstHandle, windows.FSCTL_DUPLICATE_EXTENTS_TO_FILE,
+			(*byte)(unsafe.Pointer(&extents)), uint32(unsafe.Sizeof(extents)), nil, 0, &returned, nil); err != nil {
+			return err
+		}
+		offset += count
+	}
+	return nil
+}
+
+// isReflinkUnsupported 判断错误是否表示目标卷不支持块克隆
+func isReflinkUnsupported(err error) bool {
+	return errors.Is(err, errReflinkUnsupported) ||
+		errors.Is(err, windows.ERROR_INVALID_FUNCTION) || errors.Is(err, windows.ERROR_NOT_SUPPORTED)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCloneFileBlockCloning 在DELGUARD_REFLINK_DIR（如ReFS卷或Dev Drive上的目录）或临时目录中
+// 通过FSCTL_DUPLICATE_EXTENTS_TO_FILE克隆文件，卷不支持块克隆时跳过
+func TestCloneFileBlockCloning(t *testing.T) {
+	testCloneIn(t, os.Getenv("DELGUARD_REFLINK_DIR"))
+}
+
+func TestCloneFileRejectsVolumeWithoutBlockCloning(t *testing.T) {
+	dir := t.TempDir()
+	src, _ := writeCloneSource(t, dir)
+	err := cloneFile(src, filepath.Join(dir, "clone.bin"))
+	if err == nil {
+		t.Skip("the temporary directory is on a volume with block cloning")
+	}
+	if !errors.Is(err, errSourceNotCloneable) && !isReflinkUnsupported(err) {
+		t.Errorf("cloneFile on NTFS = %v, want an unsupported error so the copy falls back", err)
+	}
+}
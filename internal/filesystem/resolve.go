@@ -0,0 +1,48 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"delguard/internal/errors"
+)
+
+// ResolveStatError 找出访问路径失败的真正原因
+// 从根目录开始逐级检查，某一级目录无法访问时返回指明该目录的权限错误，
+// 只有父目录可访问且路径本身确实不存在时才返回文件未找到错误
+func ResolveStatError(path string, statErr error) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return statErr
+	}
+
+	volume := filepath.VolumeName(absPath)
+	current := volume + string(filepath.Separator)
+	rest := strings.TrimPrefix(absPath[len(volume):], string(filepath.Separator))
+	if rest != "" {
+		for _, component := range strings.Split(rest, string(filepath.Separator)) {
+			next := filepath.Join(current, component)
+			if _, err := os.Lstat(next); err != nil {
+				switch {
+				case os.IsPermission(err):
+					return errors.NewComponentPermissionError(absPath, current)
+				case os.IsNotExist(err):
+					return errors.NewFileNotFoundError(absPath)
+				default:
+					return statErr
+				}
+			}
+			current = next
+		}
+	}
+
+	// 每一级都可访问时，错误来自路径本身（例如符号链接指向无权访问的位置）
+	if os.IsPermission(statErr) {
+		return errors.NewPermissionDeniedError(absPath)
+	}
+	if os.IsNotExist(statErr) {
+		return errors.NewFileNotFoundError(absPath)
+	}
+	return statErr
+}
@@ -0,0 +1,114 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/utils"
+)
+
+var (
+	retentionMu    sync.RWMutex
+	retentionRules []config.RetentionRule
+)
+
+// SetRetentionRules 设置按原始位置匹配的保留规则，清理过期文件时按顺序匹配
+func SetRetentionRules(rules []config.RetentionRule) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	retentionRules = rules
+}
+
+// RetentionRules 获取当前保留规则
+func RetentionRules() []config.RetentionRule {
+	retentionMu.RLock()
+	defer retentionMu.RUnlock()
+	return retentionRules
+}
+
+// PruneCandidate 按保留规则已到期的回收站项目
+type PruneCandidate struct {
+	File TrashFile
+	// Rule 命中的规则，未命中任何规则时为trash.max_days
+	Rule string
+	Days int
+}
+
+// RetentionFor 返回原始路径适用的保留天数和规则说明，第一个匹配的规则生效
+// 原始路径未知或没有规则匹配时使用defaultDays
+func RetentionFor(originalPath string, defaultDays int) (int, string) {
+	if originalPath != "" {
+		for _, rule := range RetentionRules() {
+			if matchRetentionPattern(rule.Path, originalPath) {
+				return rule.Days, rule.Path
+			}
+		}
+	}
+	return defaultDays, "trash.max_days"
+}
+
+// isExpired 判断回收站项目是否已超过适用的保留天数
+func isExpired(originalPath string, deletedTime time.Time, defaultDays int, now time.Time) bool {
+	days, _ := RetentionFor(originalPath, defaultDays)
+	return deletedTime.Before(now.AddDate(0, 0, -days))
+}
+
+// PlanPrune 列出按保留规则已到期的回收站项目，按删除时间排序
+// includePinned为false时跳过已固定的项目，与CleanOldFiles一致
+func PlanPrune(manager TrashManager, defaultDays int, now time.Time, includePinned bool) ([]PruneCandidate, error) {
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []PruneCandidate
+	for _, file := range files {
+		if (file.Pinned && !includePinned) || !isExpired(file.OriginalPath, file.DeletedTime, defaultDays, now) {
+			continue
+		}
+		days, rule := RetentionFor(file.OriginalPath, defaultDays)
+		candidates = append(candidates, PruneCandidate{File: file, Rule: rule, Days: days})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].File.DeletedTime.Before(candidates[j].File.DeletedTime)
+	})
+	return candidates, nil
+}
+
+// matchRetentionPattern 模式匹配路径本身或其任一上级目录时返回true
+func matchRetentionPattern(pattern, path string) bool {
+	if strings.HasPrefix(pattern, "~") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			pattern = filepath.Join(homeDir, strings.TrimPrefix(pattern, "~"))
+		}
+	}
+	pattern = filepath.Clean(pattern)
+	path = filepath.Clean(path)
+	if utils.CaseInsensitive(path) {
+		pattern = strings.ToLower(pattern)
+		path = strings.ToLower(path)
+	}
+
+	for current := path; ; {
+		if matched, err := filepath.Match(pattern, current); err == nil && matched {
+			return true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return false
+		}
+		current = parent
+	}
+}
+
+// String 返回规则说明，用于--explain输出
+func (c PruneCandidate) String() string {
+	return fmt.Sprintf("%s (%d 天)", c.Rule, c.Days)
+}
@@ -0,0 +1,177 @@
+package filesystem
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/utils"
+)
+
+// RotationPolicy 回收站轮转策略，回收站超出限制时选出需要永久删除的最旧项目
+// 与按保留天数清理的CleanOldFiles不同，轮转在每次删除后按数量或容量触发
+type RotationPolicy interface {
+	// Name 策略名称，用于输出
+	Name() string
+	// Select 从按删除时间由旧到新排序的回收站项目中选出需要删除的项目
+	Select(files []TrashFile) []TrashFile
+}
+
+// CountRotation 项目数超过MaxItems时删除最旧的项目
+type CountRotation struct {
+	MaxItems int
+}
+
+// Name 返回策略名称
+func (r CountRotation) Name() string {
+	return "count"
+}
+
+// Select 选出超出数量上限的最旧项目
+func (r CountRotation) Select(files []TrashFile) []TrashFile {
+	if r.MaxItems <= 0 || len(files) <= r.MaxItems {
+		return nil
+	}
+	return files[:len(files)-r.MaxItems]
+}
+
+// SizeRotation 总大小超过MaxSize时从最旧的项目开始删除，直到不超过上限
+type SizeRotation struct {
+	MaxSize int64
+}
+
+// Name 返回策略名称
+func (r SizeRotation) Name() string {
+	return "size"
+}
+
+// Select 选出需要删除的最旧项目，使剩余项目的总大小不超过上限
+func (r SizeRotation) Select(files []TrashFile) []TrashFile {
+	if r.MaxSize <= 0 {
+		return nil
+	}
+	var total int64
+	for _, file := range files {
+		total += file.Size
+	}
+
+	var selected []TrashFile
+	for _, file := range files {
+		if total <= r.MaxSize {
+			break
+		}
+		selected = append(selected, file)
+		total -= file.Size
+	}
+	return selected
+}
+
+var (
+	rotationMu     sync.RWMutex
+	rotationPolicy RotationPolicy
+)
+
+// SetRotationPolicy 设置删除后使用的轮转策略，nil表示不轮转
+func SetRotationPolicy(policy RotationPolicy) {
+	rotationMu.Lock()
+	defer rotationMu.Unlock()
+	rotationPolicy = policy
+}
+
+// CurrentRotationPolicy 获取当前轮转策略
+func CurrentRotationPolicy() RotationPolicy {
+	rotationMu.RLock()
+	defer rotationMu.RUnlock()
+	return rotationPolicy
+}
+
+// NewRotationPolicy 根据trash.rotation创建轮转策略，未启用轮转时返回nil
+// count使用trash.max_items作为数量上限，size使用trash.max_size作为容量上限
+func NewRotationPolicy(cfg config.TrashConfig) (RotationPolicy, error) {
+	switch strings.ToLower(cfg.Rotation) {
+	case "", "none":
+		return nil, nil
+	case "count":
+		if cfg.MaxItems <= 0 {
+			return nil, fmt.Errorf("按数量轮转需要设置大于0的trash.max_items")
+		}
+		return CountRotation{MaxItems: cfg.MaxItems}, nil
+	case "size":
+		maxSize, err := utils.ParseSize(cfg.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("解析trash.max_size失败: %v", err)
+		}
+		return SizeRotation{MaxSize: maxSize}, nil
+	default:
+		return nil, fmt.Errorf("未知的轮转策略: %s", cfg.Rotation)
+	}
+}
+
+// RotationResult 一次轮转的结果
+type RotationResult struct {
+	Policy  string
+	Removed []TrashFile
+	Bytes   int64
+	Errors  []string
+	// Checks 启用verify_before_prune时各选中项目的校验结果，未启用时为nil
+	Checks PruneChecks
+}
+
+// RotateTrash 按当前轮转策略永久删除最旧的回收站项目，同时清理其元数据
+// since之后放入回收站的项目（即本次操作删除的项目）计入总量但不会被删除
+// 启用verify_before_prune时先校验选中的项目，内容损坏的项目移入隔离目录而不是删除
+func RotateTrash(manager TrashManager, since time.Time) (RotationResult, error) {
+	policy := CurrentRotationPolicy()
+	if policy == nil {
+		return RotationResult{}, nil
+	}
+	result := RotationResult{Policy: policy.Name()}
+
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return result, err
+	}
+	// 已固定的项目不会被轮转删除，也不计入数量和容量上限
+	files = unpinnedFiles(files)
+	if _, ok := policy.(SizeRotation); ok {
+		// 容量上限按实际占用计算，稀疏文件和硬链接不会被高估
+		measureDiskUsage(files)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].DeletedTime.Before(files[j].DeletedTime)
+	})
+
+	// 回收站元数据中的删除时间只精确到秒
+	since = since.Truncate(time.Second)
+	var selected []TrashFile
+	for _, file := range policy.Select(files) {
+		if file.DeletedTime.Before(since) {
+			selected = append(selected, file)
+		}
+	}
+
+	checks, verified := checkBeforeDestroy(manager, selected)
+	if verified {
+		result.Checks = checks
+	}
+	throttle := MaintenanceThrottle()
+	for _, check := range checks {
+		if check.Outcome != "" {
+			continue
+		}
+		file := check.File
+		if err := RemoveFromTrash(manager, file.TrashPath); err != nil {
+			check.Outcome, check.Err = PruneKept, err
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", file.Name, err))
+			continue
+		}
+		check.Outcome = PruneDestroyed
+		result.Removed = append(result.Removed, file)
+		result.Bytes += file.Size
+		throttle.Pause()
+	}
+	return result, nil
+}
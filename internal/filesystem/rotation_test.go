@@ -0,0 +1,249 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"delguard/internal/config"
+)
+
+// rotationFiles 按由旧到新的顺序生成大小依次为sizes的回收站项目
+func rotationFiles(sizes ...int64) []TrashFile {
+	files := make([]TrashFile, len(sizes))
+	for i, size := range sizes {
+		files[i] = TrashFile{Name: string(rune('a' + i)), Size: size}
+	}
+	return files
+}
+
+func trashFileNames(files []TrashFile) []string {
+	var result []string
+	for _, file := range files {
+		result = append(result, file.Name)
+	}
+	return result
+}
+
+func TestRotationPolicySelectsOldest(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RotationPolicy
+		files  []TrashFile
+		want   []string
+	}{
+		{"count under limit", CountRotation{MaxItems: 3}, rotationFiles(1, 1, 1), nil},
+		{"count over limit", CountRotation{MaxItems: 2}, rotationFiles(1, 1, 1, 1), []string{"a", "b"}},
+		{"count disabled", CountRotation{}, rotationFiles(1, 1), nil},
+		{"size under limit", SizeRotation{MaxSize: 30}, rotationFiles(10, 10, 10), nil},
+		{"size over limit", SizeRotation{MaxSize: 25}, rotationFiles(10, 10, 10), []string{"a"}},
+		{"size removes large old items first", SizeRotation{MaxSize: 15}, rotationFiles(5, 100, 10), []string{"a", "b"}},
+		{"size newest alone too large", SizeRotation{MaxSize: 5}, rotationFiles(1, 10), []string{"a", "b"}},
+		{"size disabled", SizeRotation{}, rotationFiles(10, 10), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trashFileNames(tt.policy.Select(tt.files)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRotationPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.TrashConfig
+		want    RotationPolicy
+		wantErr bool
+	}{
+		{"default", config.TrashConfig{}, nil, false},
+		{"none", config.TrashConfig{Rotation: "none", MaxItems: 5}, nil, false},
+		{"count", config.TrashConfig{Rotation: "Count", MaxItems: 5}, CountRotation{MaxItems: 5}, false},
+		{"count without limit", config.TrashConfig{Rotation: "count"}, nil, true},
+		{"size", config.TrashConfig{Rotation: "size", MaxSize: "2MB"}, SizeRotation{MaxSize: 2 * 1024 * 1024}, false},
+		{"size unparsable", config.TrashConfig{Rotation: "size", MaxSize: "lots"}, nil, true},
+		{"unknown", config.TrashConfig{Rotation: "time"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRotationPolicy(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRotationPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewRotationPolicy() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// useRotationPolicy 在测试期间使用policy，结束后恢复为不轮转
+func useRotationPolicy(t *testing.T, policy RotationPolicy) {
+	t.Helper()
+	SetRotationPolicy(policy)
+	t.Cleanup(func() { SetRotationPolicy(nil) })
+}
+
+// trashEntriesFor 返回回收站目录下属于trashPath对应项目的文件：内容本身、.trashinfo和元数据
+func trashEntriesFor(t *testing.T, manager TrashManager, trashPath string) []string {
+	t.Helper()
+	root, err := manager.GetTrashPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Base(trashPath)
+	var found []string
+	filepath.Walk(filepath.Dir(root), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		switch info.Name() {
+		case base, base + ".trashinfo", base + ".json":
+			found = append(found, path)
+		}
+		return nil
+	})
+	return found
+}
+
+// osRotationManager 返回使用临时目录的系统回收站后端
+// 轮转通过删除磁盘上的内容和元数据生效，内存中记录元数据的FakeTrashManager无法体现
+func osRotationManager(t *testing.T) TrashManager {
+	t.Helper()
+	return contractBackends(t)[runtime.GOOS](t)
+}
+
+func TestRotateTrashRemovesOldestWithMetadata(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy func(files []TrashFile) RotationPolicy
+		check  func(t *testing.T, remaining []TrashFile)
+	}{
+		{
+			name:   "count",
+			policy: func([]TrashFile) RotationPolicy { return CountRotation{MaxItems: 1} },
+			check: func(t *testing.T, remaining []TrashFile) {
+				if len(remaining) != 1 {
+					t.Errorf("%d items left, want 1", len(remaining))
+				}
+			},
+		},
+		{
+			// 上限取单个项目的实际占用，轮转后至多剩下一个项目
+			name: "size",
+			policy: func(files []TrashFile) RotationPolicy {
+				measureDiskUsage(files)
+				return SizeRotation{MaxSize: files[0].Size}
+			},
+			check: func(t *testing.T, remaining []TrashFile) {
+				if len(remaining) > 1 {
+					t.Errorf("%d items left, want at most 1", len(remaining))
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := osRotationManager(t)
+			for _, name := range []string{"first.txt", "second.txt", "third.txt"} {
+				if err := manager.MoveToTrash(writeContractFile(t, name, "rotate me")); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+			}
+			before, err := manager.ListTrashFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			useRotationPolicy(t, tt.policy(append([]TrashFile(nil), before...)))
+
+			result, err := RotateTrash(manager, time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatalf("RotateTrash: %v", err)
+			}
+			if len(result.Errors) > 0 {
+				t.Fatalf("RotateTrash errors: %v", result.Errors)
+			}
+			remaining, err := manager.ListTrashFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, remaining)
+			if len(result.Removed)+len(remaining) != len(before) {
+				t.Errorf("removed %d and kept %d of %d items", len(result.Removed), len(remaining), len(before))
+			}
+			for _, file := range result.Removed {
+				if found := trashEntriesFor(t, manager, file.TrashPath); len(found) > 0 {
+					t.Errorf("rotated %s left behind %q", file.Name, found)
+				}
+			}
+			for _, file := range remaining {
+				if found := trashEntriesFor(t, manager, file.TrashPath); len(found) < 2 {
+					t.Errorf("kept %s has only %q, want content and metadata", file.Name, found)
+				}
+			}
+		})
+	}
+}
+
+func TestRotateTrashKeepsCurrentRunAndPinnedItems(t *testing.T) {
+	manager := osRotationManager(t)
+	for _, name := range []string{"pinned.txt", "older.txt", "newer.txt"} {
+		if err := manager.MoveToTrash(writeContractFile(t, name, name)); err != nil {
+			t.Fatalf("MoveToTrash: %v", err)
+		}
+	}
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file.Name == "pinned.txt" {
+			if err := SetPinned(manager, file, true); err != nil {
+				t.Fatalf("SetPinned: %v", err)
+			}
+		}
+	}
+	useRotationPolicy(t, CountRotation{MaxItems: 1})
+
+	// 所有项目都在since之后放入回收站，视为本次操作删除的项目
+	result, err := RotateTrash(manager, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("RotateTrash: %v", err)
+	}
+	if len(result.Removed) != 0 {
+		t.Errorf("rotated items of the current run: %q", trashFileNames(result.Removed))
+	}
+
+	result, err = RotateTrash(manager, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("RotateTrash: %v", err)
+	}
+	if len(result.Removed) != 1 || result.Removed[0].Name == "pinned.txt" {
+		t.Errorf("rotated %q, want one unpinned item", trashFileNames(result.Removed))
+	}
+	remaining, err := manager.ListTrashFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 2 {
+		t.Errorf("%d items left, want the pinned item and one other", len(remaining))
+	}
+}
+
+func TestRotateTrashWithoutPolicy(t *testing.T) {
+	manager := osRotationManager(t)
+	if err := manager.MoveToTrash(writeContractFile(t, "kept.txt", "kept")); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	useRotationPolicy(t, nil)
+
+	result, err := RotateTrash(manager, time.Now().Add(time.Hour))
+	if err != nil || len(result.Removed) != 0 || result.Policy != "" {
+		t.Errorf("RotateTrash() = %+v, %v; want no-op", result, err)
+	}
+	onlyTrashFile(t, manager)
+}
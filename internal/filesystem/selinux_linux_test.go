@@ -0,0 +1,70 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+const testSELinuxLabel = "system_u:object_r:shadow_t:s0"
+
+// labelFile 为文件写入SELinux标签，内核或文件系统不允许写入security.selinux时跳过测试
+func labelFile(t *testing.T, path, label string) {
+	t.Helper()
+	if err := unix.Lsetxattr(path, "security.selinux", []byte(label+"\x00"), 0); err != nil {
+		t.Skipf("cannot set security.selinux here: %v", err)
+	}
+	if got, ok := SELinuxContext(path); !ok || got != label {
+		t.Skipf("security.selinux reads back as %q, the active policy rewrote the label", got)
+	}
+}
+
+func TestSELinuxContext(t *testing.T) {
+	path := writeContractFile(t, "shadow", "root:*:19000::::::")
+	if context, ok := SELinuxContext(path); ok {
+		t.Skipf("SELinux is enabled and already labelled the file %q", context)
+	}
+	if _, ok := SELinuxContext(filepath.Join(filepath.Dir(path), "missing")); ok {
+		t.Error("SELinuxContext reported a label for a missing file")
+	}
+
+	labelFile(t, path, testSELinuxLabel)
+	context, ok := SELinuxContext(path)
+	if !ok || context != testSELinuxLabel {
+		t.Errorf("SELinuxContext() = %q, %v; want %q without the trailing NUL", context, ok, testSELinuxLabel)
+	}
+}
+
+func TestSELinuxLabelRestoredFromMetadata(t *testing.T) {
+	factory, ok := lookupBackend("linux")
+	if !ok {
+		t.Fatal("no linux backend")
+	}
+	root := t.TempDir()
+	manager, err := factory(BackendOptions{TrashDir: root, TrashRoot: root, PreserveXattrs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeContractFile(t, "shadow", "root:*:19000::::::")
+	labelFile(t, path, testSELinuxLabel)
+
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	item := onlyTrashFile(t, manager)
+	// 去掉回收站中内容的标签，恢复后的标签只能来自元数据
+	if err := unix.Lremovexattr(item.TrashPath, "security.selinux"); err != nil {
+		t.Skipf("cannot remove security.selinux here: %v", err)
+	}
+	if err := manager.RestoreFile(item, ""); err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("restored file: %v", err)
+	}
+	if context, ok := SELinuxContext(path); !ok || context != testSELinuxLabel {
+		t.Errorf("restored label = %q, %v; want %q", context, ok, testSELinuxLabel)
+	}
+}
@@ -0,0 +1,145 @@
+package filesystem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"delguard/internal/errors"
+)
+
+// DefaultShredPasses 默认覆写遍数
+const DefaultShredPasses = 3
+
+// shredBufferSize 覆写时每次写入的块大小
+const shredBufferSize = 64 * 1024
+
+// ShredOptions 粉碎选项
+type ShredOptions struct {
+	// Passes 覆写遍数，最后一遍写入零
+	Passes int
+	// FollowLinks 为true时粉碎符号链接指向的文件并删除链接本身
+	FollowLinks bool
+}
+
+// ShredPath 覆写文件内容后永久删除，不经过回收站
+// 目录会递归粉碎其中的普通文件，目录内的符号链接只删除链接本身
+func ShredPath(path string, opts ShredOptions) error {
+	if opts.Passes <= 0 {
+		opts.Passes = DefaultShredPasses
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return errors.FromOS("获取文件信息失败", err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		if !opts.FollowLinks {
+			return fmt.Errorf("拒绝粉碎符号链接: %s (使用 --shred-links 粉碎其指向的文件)", path)
+		}
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return fmt.Errorf("解析符号链接失败: %v", err)
+		}
+		if err := ShredPath(target, ShredOptions{Passes: opts.Passes}); err != nil {
+			return err
+		}
+		return os.Remove(path)
+	}
+
+	if info.IsDir() {
+		return shredTree(path, opts.Passes)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("不支持粉碎特殊文件: %s", path)
+	}
+	return shredFile(path, info.Size(), opts.Passes)
+}
+
+// shredTree 粉碎目录中的所有普通文件后删除整个目录
+func shredTree(root string, passes int) error {
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return shredFile(path, info.Size(), passes)
+	})
+	if err != nil {
+		return err
+	}
+	return removeAllWritable(root)
+}
+
+// shredFile 依次以随机数据覆写文件并刷盘，最后一遍写零，然后改名、截断并删除
+func shredFile(path string, size int64, passes int) error {
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&ownerWritable == 0 {
+		os.Chmod(path, info.Mode().Perm()|ownerWritable)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.FromOS("打开文件失败", err)
+	}
+
+	for pass := 1; pass <= passes; pass++ {
+		source := io.Reader(rand.Reader)
+		if pass == passes {
+			source = zeroReader{}
+		}
+		if err := overwrite(file, size, source); err != nil {
+			file.Close()
+			return fmt.Errorf("第 %d 遍覆写失败: %v", pass, err)
+		}
+	}
+
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return errors.FromOS("截断文件失败", err)
+	}
+	file.Close()
+
+	// 改为随机名称，避免原文件名残留在目录项中
+	target := path
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err == nil {
+		renamed := filepath.Join(filepath.Dir(path), hex.EncodeToString(suffix))
+		if err := os.Rename(path, renamed); err == nil {
+			target = renamed
+		}
+	}
+
+	if err := os.Remove(target); err != nil {
+		return errors.FromOS("删除文件失败", err)
+	}
+	return nil
+}
+
+// overwrite 从文件开头写入size字节并刷到磁盘
+func overwrite(file *os.File, size int64, source io.Reader) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	buf := make([]byte, shredBufferSize)
+	if _, err := io.CopyBuffer(file, io.LimitReader(source, size), buf); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// zeroReader 产生无限个零字节
+type zeroReader struct{}
+
+// Read 实现io.Reader接口
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
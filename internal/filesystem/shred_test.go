@@ -0,0 +1,49 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShredTreeLeavesLinkTargetsAlone(t *testing.T) {
+	outside := writeContractFile(t, "outside.txt", "keep me")
+	root := filepath.Join(t.TempDir(), "tree")
+	if err := os.MkdirAll(filepath.Join(root, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "nested", "inner.txt"), []byte("secret"), 0444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if err := ShredPath(root, ShredOptions{Passes: 1}); err != nil {
+		t.Fatalf("ShredPath: %v", err)
+	}
+	if _, err := os.Lstat(root); !os.IsNotExist(err) {
+		t.Errorf("tree still exists: %v", err)
+	}
+	if data, err := os.ReadFile(outside); err != nil || string(data) != "keep me" {
+		t.Errorf("link target = %q, %v; shredding a tree must only remove links inside it", data, err)
+	}
+}
+
+func TestShredPathRefusesSymlinkByDefault(t *testing.T) {
+	target := writeContractFile(t, "target.txt", "keep me")
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if err := ShredPath(link, ShredOptions{}); err == nil {
+		t.Fatal("ShredPath shredded a symlink without FollowLinks")
+	}
+	if _, err := os.Lstat(link); err != nil {
+		t.Errorf("link was removed: %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "keep me" {
+		t.Errorf("target was modified: %q", data)
+	}
+}
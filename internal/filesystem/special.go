@@ -0,0 +1,86 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// IsSpecialFile 是否为命名管道、套接字或设备文件，这类文件没有可以复制的内容
+func IsSpecialFile(mode os.FileMode) bool {
+	return mode&(os.ModeNamedPipe|os.ModeSocket|os.ModeDevice) != 0
+}
+
+// SpecialFileKind 特殊文件的类型名称，不是特殊文件时返回空字符串
+func SpecialFileKind(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeNamedPipe != 0:
+		return "命名管道"
+	case mode&os.ModeSocket != 0:
+		return "套接字"
+	case mode&os.ModeDevice != 0:
+		return "设备文件"
+	default:
+		return ""
+	}
+}
+
+// specialFileError rename失败后，特殊文件不能像普通文件一样复制到回收站，不是特殊文件时返回nil
+// 复制命名管道会一直阻塞在读取上，套接字和设备文件则无法打开或没有意义
+func specialFileError(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || !IsSpecialFile(info.Mode()) {
+		return nil
+	}
+	return errors.NewSpecialFileError(path, SpecialFileKind(info.Mode()))
+}
+
+// TrashSpecialFile 直接删除特殊文件，回收站中只保留一个空的占位项目和记录文件类型的元数据，
+// 列表中仍能看到删除了什么，恢复时不会重新创建该文件
+func TrashSpecialFile(manager TrashManager, path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("路径转换失败: %v", err)
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return errors.FromOS("获取文件信息失败", err)
+	}
+	kind := SpecialFileKind(info.Mode())
+	if kind == "" {
+		return fmt.Errorf("不是命名管道、套接字或设备文件: %s", absPath)
+	}
+
+	metadata := TrashMetadata{
+		OriginalPath: absPath,
+		DeletedTime:  time.Now(),
+		FileName:     filepath.Base(absPath),
+		Permissions:  info.Mode().String(),
+		SpecialType:  kind,
+	}
+	captureFileAttributes(&metadata, info)
+
+	placeholder, err := os.CreateTemp("", "delguard-special-*")
+	if err != nil {
+		return errors.FromOS("创建占位文件失败", err)
+	}
+	placeholder.Close()
+	defer os.Remove(placeholder.Name())
+
+	// 先删除源文件：删除失败时不留下记录，记录写入失败时丢失的也只是没有内容的特殊文件
+	if err := os.Remove(absPath); err != nil {
+		return errors.FromOS(fmt.Sprintf("删除%s失败", kind), err)
+	}
+	return manager.ImportFile(placeholder.Name(), metadata)
+}
+
+// specialRecordError 恢复只有删除记录的特殊文件时返回的错误
+func specialRecordError(metadata *TrashMetadata) error {
+	err := errors.NewError(errors.ErrTypeSpecialFile,
+		fmt.Sprintf("回收站中只有%s %s 的删除记录", metadata.SpecialType, metadata.FileName), nil)
+	err.Hint = fmt.Sprintf("%s没有可以恢复的内容，恢复不会重新创建该文件", metadata.SpecialType)
+	return err
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !windows
+
+package filesystem
+
+import (
+	"os"
+	"time"
+)
+
+// fileOwner 当前平台不支持获取所有者
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}
+
+// fileAccessTime 当前平台不支持获取访问时间
+func fileAccessTime(info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,216 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatsBucket 回收站统计中的一个分组
+type StatsBucket struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// UnknownOrigin 无法确定原始目录时使用的分组名
+const UnknownOrigin = "(未知)"
+
+// rateWindowDays 估算删除速率时统计的天数
+const rateWindowDays = 30
+
+// maxForecastDays 预测达到容量上限的最远天数
+const maxForecastDays = 10 * 365
+
+// maxDirectoryBuckets 按目录分组时最多列出的目录数，其余合并为"其他"
+const maxDirectoryBuckets = 10
+
+// ageBuckets 删除时长分组，按上限天数递增
+var ageBuckets = []struct {
+	name    string
+	maxDays int
+}{
+	{"1天内", 1},
+	{"1-7天", 7},
+	{"7-30天", 30},
+	{"30-90天", 90},
+	{"90天以上", -1},
+}
+
+// ComputeTrashStats 根据回收站列表计算分组统计
+// 分组大小来自列表中记录的值；容量使用情况按项目实际占用的磁盘空间计算，quota为0表示不限制容量
+func ComputeTrashStats(manager TrashManager, quota int64, now time.Time) (*TrashStats, error) {
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return nil, err
+	}
+	return buildTrashStats(files, quota, now), nil
+}
+
+// buildTrashStats 汇总回收站项目
+func buildTrashStats(files []TrashFile, quota int64, now time.Time) *TrashStats {
+	stats := &TrashStats{QuotaSize: quota}
+
+	byDirectory := make(map[string]*StatsBucket)
+	byExtension := make(map[string]*StatsBucket)
+	byAge := make([]StatsBucket, len(ageBuckets))
+	for i, bucket := range ageBuckets {
+		byAge[i].Name = bucket.name
+	}
+
+	var recentSize int64
+	windowStart := now.AddDate(0, 0, -rateWindowDays)
+	usage := newDiskUsage()
+
+	for _, file := range files {
+		stats.TotalFiles++
+		stats.TotalSize += file.Size
+		onDisk := file.Size
+		if file.TrashPath != "" {
+			_, onDisk = usage.tree(file.TrashPath)
+		}
+		stats.DiskSize += onDisk
+		if file.Pinned {
+			stats.PinnedFiles++
+			stats.PinnedSize += file.Size
+		}
+		if file.IsDirectory {
+			stats.Directories++
+		} else {
+			stats.Files++
+		}
+		if !file.DeletedTime.IsZero() && (stats.OldestFile.IsZero() || file.DeletedTime.Before(stats.OldestFile)) {
+			stats.OldestFile = file.DeletedTime
+		}
+
+		addToBucket(byDirectory, originDirectory(file), file.Size)
+
+		ext := "(目录)"
+		if !file.IsDirectory {
+			ext = strings.ToLower(filepath.Ext(file.Name))
+			if ext == "" || ext == strings.ToLower(file.Name) {
+				ext = "(无扩展名)"
+			}
+		}
+		addToBucket(byExtension, ext, file.Size)
+
+		if !file.DeletedTime.IsZero() {
+			bucket := &byAge[ageBucketIndex(now.Sub(file.DeletedTime))]
+			bucket.Count++
+			bucket.Size += file.Size
+
+			if file.DeletedTime.After(windowStart) {
+				recentSize += onDisk
+			}
+		}
+	}
+
+	stats.ByDirectory = sortedBuckets(byDirectory, maxDirectoryBuckets)
+	stats.ByExtension = sortedBuckets(byExtension, 0)
+	for _, bucket := range byAge {
+		if bucket.Count > 0 {
+			stats.ByAge = append(stats.ByAge, bucket)
+		}
+	}
+
+	stats.DailyRate = recentSize / rateWindowDays
+	if quota > 0 {
+		stats.QuotaUsedPercent = float64(stats.DiskSize) / float64(quota) * 100
+		if stats.DiskSize < quota && stats.DailyRate > 0 {
+			// 超过预测上限的日期没有参考意义，保持为零值
+			days := (quota - stats.DiskSize + stats.DailyRate - 1) / stats.DailyRate
+			if days <= maxForecastDays {
+				stats.QuotaFullDate = now.AddDate(0, 0, int(days))
+			}
+		}
+	}
+
+	return stats
+}
+
+// TrashUsageByOrigin 按原始所在目录汇总回收站占用的字节数
+// 目录项目统计其全部内容的大小；缺少元数据、无法确定原始路径的项目归入"(未知)"
+func TrashUsageByOrigin(manager TrashManager) (map[string]int64, error) {
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]int64)
+	for _, file := range files {
+		size := file.Size
+		if file.IsDirectory && file.TrashPath != "" {
+			size = treeSize(file.TrashPath)
+		}
+		usage[originDirectory(file)] += size
+	}
+	return usage, nil
+}
+
+// originDirectory 返回项目删除前所在的目录
+func originDirectory(file TrashFile) string {
+	if file.OriginalPath == "" {
+		return UnknownOrigin
+	}
+	return filepath.Dir(file.OriginalPath)
+}
+
+// treeSize 计算文件或目录的总大小
+func treeSize(root string) int64 {
+	var size int64
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// ageBucketIndex 返回删除时长所属的分组
+func ageBucketIndex(age time.Duration) int {
+	days := int(age.Hours() / 24)
+	for i, bucket := range ageBuckets {
+		if bucket.maxDays < 0 || days < bucket.maxDays {
+			return i
+		}
+	}
+	return len(ageBuckets) - 1
+}
+
+// addToBucket 累加分组的数量和大小
+func addToBucket(buckets map[string]*StatsBucket, name string, size int64) {
+	bucket, ok := buckets[name]
+	if !ok {
+		bucket = &StatsBucket{Name: name}
+		buckets[name] = bucket
+	}
+	bucket.Count++
+	bucket.Size += size
+}
+
+// sortedBuckets 按大小降序排列分组，limit大于0时把超出部分合并为"其他"
+func sortedBuckets(buckets map[string]*StatsBucket, limit int) []StatsBucket {
+	result := make([]StatsBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, *bucket)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Size != result[j].Size {
+			return result[i].Size > result[j].Size
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	if limit > 0 && len(result) > limit {
+		other := StatsBucket{Name: "(其他)"}
+		for _, bucket := range result[limit:] {
+			other.Count += bucket.Count
+			other.Size += bucket.Size
+		}
+		result = append(result[:limit], other)
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// listedManager 只返回固定列表的回收站，用于构造缺少元数据的项目
+type listedManager struct {
+	TrashManager
+	files []TrashFile
+}
+
+func (m listedManager) ListTrashFiles() ([]TrashFile, error) {
+	return m.files, nil
+}
+
+func TestTrashUsageByOrigin(t *testing.T) {
+	manager, err := NewFakeTrashManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	origins := map[string][]int{
+		"photos":  {100, 250},
+		"build":   {4000},
+		"scratch": {1, 2, 3},
+	}
+	want := make(map[string]int64)
+	for dir, sizes := range origins {
+		origin := filepath.Join(root, dir)
+		if err := os.MkdirAll(origin, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for i, size := range sizes {
+			path := filepath.Join(origin, strings.Repeat("f", i+1)+".bin")
+			if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := manager.MoveToTrash(path); err != nil {
+				t.Fatalf("MoveToTrash: %v", err)
+			}
+			want[origin] += int64(size)
+		}
+	}
+
+	// 目录项目按全部内容计算，归入目录自身的上级目录
+	tree := filepath.Join(root, "build", "out")
+	if err := os.MkdirAll(filepath.Join(tree, "obj"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.o", filepath.Join("obj", "b.o")} {
+		if err := os.WriteFile(filepath.Join(tree, name), make([]byte, 500), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := manager.MoveToTrash(tree); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	want[filepath.Join(root, "build")] += 1000
+
+	got, err := TrashUsageByOrigin(manager)
+	if err != nil {
+		t.Fatalf("TrashUsageByOrigin: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TrashUsageByOrigin = %v, want %v", got, want)
+	}
+}
+
+func TestTrashUsageByOriginBucketsMissingMetadata(t *testing.T) {
+	manager := listedManager{files: []TrashFile{
+		{Name: "orphan", Size: 70},
+		{Name: "another orphan", Size: 30},
+		{Name: "known", Size: 5, OriginalPath: filepath.Join("home", "user", "known")},
+	}}
+
+	got, err := TrashUsageByOrigin(manager)
+	if err != nil {
+		t.Fatalf("TrashUsageByOrigin: %v", err)
+	}
+	want := map[string]int64{UnknownOrigin: 100, filepath.Join("home", "user"): 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TrashUsageByOrigin = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package filesystem
+
+import "fmt"
+
+// 存储类型，用于决定并行处理文件的数量
+const (
+	StorageSSD     = "ssd"
+	StorageHDD     = "hdd"
+	StorageNetwork = "network"
+	StorageUnknown = "unknown"
+)
+
+// storageWorkers 各存储类型默认的并发数：机械硬盘并行随机读写会反复寻道，网络文件系统并行时容易超时
+var storageWorkers = map[string]int{
+	StorageSSD:     8,
+	StorageHDD:     2,
+	StorageNetwork: 1,
+	StorageUnknown: 4,
+}
+
+// StorageInfo 路径所在存储的类型及判断依据
+type StorageInfo struct {
+	Class  string
+	Reason string
+}
+
+// rotationalLookup 判断路径所在的本地磁盘是否为机械硬盘，第二个返回值为false表示无法判断，测试时可替换为桩函数
+var rotationalLookup = isRotational
+
+// DetectStorage 判断路径所在存储的类型：远程文件系统按挂载类型判断，本地磁盘按是否有寻道开销判断
+func DetectStorage(path string) StorageInfo {
+	mount, err := MountOf(path)
+	if err == nil && mount.Remote {
+		return StorageInfo{Class: StorageNetwork, Reason: fmt.Sprintf("网络文件系统 %s", mount.FSType)}
+	}
+	rotational, known := rotationalLookup(path, mount)
+	switch {
+	case !known:
+		return StorageInfo{Class: StorageUnknown, Reason: "无法判断存储类型"}
+	case rotational:
+		return StorageInfo{Class: StorageHDD, Reason: "检测到机械硬盘"}
+	default:
+		return StorageInfo{Class: StorageSSD, Reason: "检测到固态硬盘"}
+	}
+}
+
+// AdaptiveWorkers 按路径所在存储的类型返回默认的并发数
+func AdaptiveWorkers(path string) (int, StorageInfo) {
+	storage := DetectStorage(path)
+	return storageWorkers[storage.Class], storage
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// sysfsRoot sysfs的挂载位置，测试时可指向伪造的目录
+var sysfsRoot = "/sys"
+
+// isRotational 读取路径所在块设备的 queue/rotational
+// 先按文件的设备号查找；Btrfs子卷等使用匿名设备号时改按挂载来源（如/dev/nvme0n1p2）查找
+func isRotational(path string, mount MountInfo) (bool, bool) {
+	var st unix.Stat_t
+	if err := unix.Stat(nearestExisting(path), &st); err == nil {
+		dev := fmt.Sprintf("%d:%d", unix.Major(uint64(st.Dev)), unix.Minor(uint64(st.Dev)))
+		if rotational, ok := rotationalFlag(filepath.Join(sysfsRoot, "dev", "block", dev)); ok {
+			return rotational, true
+		}
+	}
+
+	if !strings.HasPrefix(mount.Source, "/dev/") {
+		return false, false
+	}
+	// /dev/mapper/xxx、/dev/disk/by-uuid/xxx 等是指向 /dev/dm-0、/dev/sda1 的链接
+	source, err := filepath.EvalSymlinks(mount.Source)
+	if err != nil {
+		source = mount.Source
+	}
+	return rotationalFlag(filepath.Join(sysfsRoot, "class", "block", filepath.Base(source)))
+}
+
+// rotationalFlag 读取sysfs中块设备的rotational标志，分区使用所在磁盘的标志
+func rotationalFlag(device string) (bool, bool) {
+	dir, err := filepath.EvalSymlinks(device)
+	if err != nil {
+		return false, false
+	}
+	if _, err := os.Stat(filepath.Join(dir, "partition")); err == nil {
+		dir = filepath.Dir(dir)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "queue", "rotational"))
+	if err != nil {
+		return false, false
+	}
+	return strings.TrimSpace(string(data)) == "1", true
+}
@@ -0,0 +1,94 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// fakeSysfs 在临时目录中伪造sysfs，测试期间替换sysfsRoot
+func fakeSysfs(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	saved := sysfsRoot
+	sysfsRoot = root
+	t.Cleanup(func() { sysfsRoot = saved })
+	return root
+}
+
+// fakeBlockDevice 伪造磁盘disk及其分区part，rotational为磁盘的queue/rotational内容
+// 与真实sysfs相同，class/block下的条目是指向devices目录的链接，分区目录位于磁盘目录之下
+func fakeBlockDevice(t *testing.T, sysfs, disk, part, rotational string) string {
+	t.Helper()
+	diskDir := filepath.Join(sysfs, "devices", "virtual", "block", disk)
+	partDir := filepath.Join(diskDir, part)
+	for _, dir := range []string{filepath.Join(diskDir, "queue"), partDir, filepath.Join(sysfs, "class", "block"), filepath.Join(sysfs, "dev", "block")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(diskDir, "queue", "rotational"), []byte(rotational+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(partDir, "partition"), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{disk: diskDir, part: partDir} {
+		if err := os.Symlink(target, filepath.Join(sysfs, "class", "block", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return partDir
+}
+
+// deviceNumber 返回文件所在设备的 major:minor
+func deviceNumber(t *testing.T, path string) string {
+	t.Helper()
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("%d:%d", unix.Major(uint64(st.Dev)), unix.Minor(uint64(st.Dev)))
+}
+
+func TestIsRotationalByDeviceNumber(t *testing.T) {
+	for _, tt := range []struct {
+		flag string
+		want bool
+	}{{"1", true}, {"0", false}} {
+		t.Run("rotational="+tt.flag, func(t *testing.T) {
+			sysfs := fakeSysfs(t)
+			partDir := fakeBlockDevice(t, sysfs, "sda", "sda1", tt.flag)
+			path := t.TempDir()
+			if err := os.Symlink(partDir, filepath.Join(sysfs, "dev", "block", deviceNumber(t, path))); err != nil {
+				t.Fatal(err)
+			}
+
+			// 文件本身尚不存在时按最近的已存在上级目录查找
+			rotational, known := isRotational(filepath.Join(path, "missing", "file"), MountInfo{})
+			if !known || rotational != tt.want {
+				t.Errorf("isRotational() = %v, %v; want %v, true", rotational, known, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRotationalByMountSource(t *testing.T) {
+	sysfs := fakeSysfs(t)
+	fakeBlockDevice(t, sysfs, "nvme0n1", "nvme0n1p2", "0")
+
+	// 设备号在sysfs中找不到时（如Btrfs子卷），按挂载来源查找
+	rotational, known := isRotational(t.TempDir(), MountInfo{Source: "/dev/nvme0n1p2"})
+	if !known || rotational {
+		t.Errorf("isRotational() = %v, %v; want SSD", rotational, known)
+	}
+
+	for _, source := range []string{"server:/export", "tmpfs", "/dev/missing0"} {
+		if _, known := isRotational(t.TempDir(), MountInfo{Source: source}); known {
+			t.Errorf("isRotational() with source %q claims to know the disk type", source)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !windows
+
+package filesystem
+
+// isRotational 当前平台无法判断磁盘类型
+func isRotational(path string, mount MountInfo) (bool, bool) {
+	return false, false
+}
@@ -0,0 +1,56 @@
+package filesystem
+
+import (
+	"errors"
+	"testing"
+)
+
+// stubRotational 在测试期间用固定结果替换磁盘类型查询
+func stubRotational(t *testing.T, rotational, known bool) {
+	t.Helper()
+	saved := rotationalLookup
+	rotationalLookup = func(string, MountInfo) (bool, bool) { return rotational, known }
+	t.Cleanup(func() { rotationalLookup = saved })
+}
+
+func TestAdaptiveWorkers(t *testing.T) {
+	local := func(string) (MountInfo, error) { return MountInfo{MountPoint: "/", FSType: "ext4"}, nil }
+	tests := []struct {
+		name        string
+		mount       func(string) (MountInfo, error)
+		rotational  bool
+		known       bool
+		wantClass   string
+		wantWorkers int
+	}{
+		{"ssd", local, false, true, StorageSSD, 8},
+		{"hdd", local, true, true, StorageHDD, 2},
+		{"unknown disk", local, false, false, StorageUnknown, 4},
+		{
+			// 网络文件系统不再查询磁盘类型
+			name: "network",
+			mount: func(string) (MountInfo, error) {
+				return MountInfo{MountPoint: "/mnt/share", FSType: "nfs4", Remote: true}, nil
+			},
+			rotational: false, known: true, wantClass: StorageNetwork, wantWorkers: 1,
+		},
+		{
+			name:       "mount lookup fails",
+			mount:      func(string) (MountInfo, error) { return MountInfo{}, errors.New("no mount table") },
+			rotational: true, known: true, wantClass: StorageHDD, wantWorkers: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubMounts(t, tt.mount)
+			stubRotational(t, tt.rotational, tt.known)
+			workers, storage := AdaptiveWorkers(t.TempDir())
+			if storage.Class != tt.wantClass || workers != tt.wantWorkers {
+				t.Errorf("AdaptiveWorkers() = %d, %+v; want %d workers for %s", workers, storage, tt.wantWorkers, tt.wantClass)
+			}
+			if storage.Reason == "" {
+				t.Error("storage decision has no reason")
+			}
+		})
+	}
+}
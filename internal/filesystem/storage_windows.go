@@ -0,0 +1,62 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// IOCTL_STORAGE_QUERY_PROPERTY 及查询寻道开销所用的属性
+const (
+	ioctlStorageQueryProperty        = 0x2D1400
+	storageDeviceSeekPenaltyProperty = 7
+	propertyStandardQuery            = 0
+)
+
+// storagePropertyQuery STORAGE_PROPERTY_QUERY
+type storagePropertyQuery struct {
+	PropertyID           uint32
+	QueryType            uint32
+	AdditionalParameters [1]byte
+}
+
+// seekPenaltyDescriptor DEVICE_SEEK_PENALTY_DESCRIPTOR
+type seekPenaltyDescriptor struct {
+	Version           uint32
+	Size              uint32
+	IncursSeekPenalty byte
+}
+
+// isRotational 通过DeviceIoControl查询卷所在磁盘是否有寻道开销，有则为机械硬盘
+func isRotational(path string, mount MountInfo) (bool, bool) {
+	volume := filepath.VolumeName(path)
+	if volume == "" || strings.HasPrefix(volume, `\\`) {
+		return false, false
+	}
+	devicePtr, err := windows.UTF16PtrFromString(`\\.\` + volume)
+	if err != nil {
+		return false, false
+	}
+	// 查询设备属性不需要读写权限
+	handle, err := windows.CreateFile(devicePtr, 0, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE,
+		nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return false, false
+	}
+	defer windows.CloseHandle(handle)
+
+	query := storagePropertyQuery{PropertyID: storageDeviceSeekPenaltyProperty, QueryType: propertyStandardQuery}
+	var descriptor seekPenaltyDescriptor
+	var returned uint32
+	err = windows.DeviceIoControl(handle, ioctlStorageQueryProperty,
+		(*byte)(unsafe.Pointer(&query)), uint32(unsafe.Sizeof(query)),
+		(*byte)(unsafe.Pointer(&descriptor)), uint32(unsafe.Sizeof(descriptor)), &returned, nil)
+	if err != nil || returned < uint32(unsafe.Offsetof(descriptor.IncursSeekPenalty))+1 {
+		return false, false
+	}
+	return descriptor.IncursSeekPenalty != 0, true
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package filesystem
+
+// listAlternateStreams 备用数据流只存在于NTFS，其他平台上的命名属性由扩展属性记录
+func listAlternateStreams(path string) (map[string]string, []string, error) {
+	return nil, nil, nil
+}
+
+// restoreAlternateStreams 当前平台不支持备用数据流
+func restoreAlternateStreams(path string, streams map[string]string) error {
+	return nil
+}
+
+// explicitACL Linux上的POSIX ACL保存在system.posix_acl_*扩展属性中，随Xattrs一起记录
+func explicitACL(path string) (string, error) {
+	return "", nil
+}
+
+// restoreACL 当前平台不记录单独的ACL
+func restoreACL(path, sddl string) error {
+	return nil
+}
@@ -0,0 +1,156 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// kernel32.dll中枚举备用数据流的函数
+var (
+	findFirstStream = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindFirstStreamW")
+	findNextStream  = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindNextStreamW")
+)
+
+// findStreamData WIN32_FIND_STREAM_DATA
+type findStreamData struct {
+	StreamSize int64
+	StreamName [windows.MAX_PATH + 36]uint16
+}
+
+// listAlternateStreams 读取文件的NTFS备用数据流（如浏览器写入的Zone.Identifier），不包括文件内容本身
+// 超过大小限制的流会被跳过，并通过skipped返回其名称
+func listAlternateStreams(path string) (streams map[string]string, skipped []string, err error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var data findStreamData
+	handle, _, callErr := findFirstStream.Call(uintptr(unsafe.Pointer(pathPtr)), 0, uintptr(unsafe.Pointer(&data)), 0)
+	if windows.Handle(handle) == windows.InvalidHandle {
+		// 没有任何数据流（例如没有备用数据流的目录）或文件系统不支持数据流
+		if callErr == windows.ERROR_HANDLE_EOF || callErr == windows.ERROR_INVALID_PARAMETER {
+			return nil, nil, nil
+		}
+		return nil, nil, callErr
+	}
+	defer windows.FindClose(windows.Handle(handle))
+
+	total := 0
+	for {
+		// 流名称形如 ":Zone.Identifier:$DATA"，文件内容本身为 "::$DATA"
+		name := strings.TrimSuffix(strings.TrimPrefix(windows.UTF16ToString(data.StreamName[:]), ":"), ":$DATA")
+		if name != "" {
+			if data.StreamSize > maxXattrValueSize || total+int(data.StreamSize) > maxXattrTotalSize {
+				skipped = append(skipped, name)
+			} else if value, err := os.ReadFile(path + ":" + name); err != nil {
+				skipped = append(skipped, name)
+			} else {
+				if streams == nil {
+					streams = make(map[string]string)
+				}
+				total += len(value)
+				streams[name] = base64.StdEncoding.EncodeToString(value)
+			}
+		}
+
+		ok, _, callErr := findNextStream.Call(handle, uintptr(unsafe.Pointer(&data)))
+		if ok == 0 {
+			if callErr == windows.ERROR_HANDLE_EOF {
+				break
+			}
+			return streams, skipped, callErr
+		}
+	}
+	return streams, skipped, nil
+}
+
+// restoreAlternateStreams 将记录的备用数据流写回文件，内容未变化的流跳过
+func restoreAlternateStreams(path string, streams map[string]string) error {
+	var failed []string
+	for name, encoded := range streams {
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			failed = append(failed, name)
+			continue
+		}
+
+		streamPath := path + ":" + name
+		// 同一卷内移动时数据流会被保留
+		if current, err := os.ReadFile(streamPath); err == nil && bytes.Equal(current, value) {
+			continue
+		}
+		if err := os.WriteFile(streamPath, value, 0644); err != nil {
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("无法恢复备用数据流: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// explicitACL 返回文件的DACL（SDDL格式），只有禁止继承或含有显式访问控制项时才返回，
+// 完全继承自上级目录的权限在恢复位置会重新继承，不需要记录
+func explicitACL(path string) (string, error) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return "", err
+	}
+	sddl := sd.String()
+	if !hasExplicitDACL(sddl) {
+		return "", nil
+	}
+	return sddl, nil
+}
+
+// hasExplicitDACL 判断SDDL中的DACL是否禁止继承（D:P）或含有不带ID标志的访问控制项
+func hasExplicitDACL(sddl string) bool {
+	_, dacl, ok := strings.Cut(sddl, "D:")
+	if !ok {
+		return false
+	}
+	flags, aces, _ := strings.Cut(dacl, "(")
+	if strings.Contains(flags, "P") {
+		return true
+	}
+	for _, ace := range strings.Split(aces, "(") {
+		fields := strings.Split(strings.TrimSuffix(ace, ")"), ";")
+		if len(fields) > 1 && !strings.Contains(fields[1], "ID") {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreACL 将记录的DACL写回文件，当前DACL与记录相同时跳过
+func restoreACL(path, sddl string) error {
+	if current, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION); err == nil && current.String() == sddl {
+		return nil
+	}
+
+	sd, err := windows.SecurityDescriptorFromString(sddl)
+	if err != nil {
+		return err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	info := windows.SECURITY_INFORMATION(windows.DACL_SECURITY_INFORMATION)
+	if control, _, err := sd.Control(); err == nil && control&windows.SE_DACL_PROTECTED != 0 {
+		info |= windows.PROTECTED_DACL_SECURITY_INFORMATION
+	} else {
+		info |= windows.UNPROTECTED_DACL_SECURITY_INFORMATION
+	}
+	return windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, info, nil, nil, dacl, nil)
+}
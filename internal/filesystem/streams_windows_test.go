@@ -0,0 +1,52 @@
+package filesystem
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+)
+
+func TestHasExplicitDACL(t *testing.T) {
+	tests := []struct {
+		name string
+		sddl string
+		want bool
+	}{
+		{"inherited only", "O:BAG:SYD:AI(A;ID;FA;;;SY)(A;ID;FA;;;BA)", false},
+		{"protected", "O:BAG:SYD:PAI(A;ID;FA;;;SY)", true},
+		{"explicit entry", "O:BAG:SYD:AI(A;;FR;;;WD)(A;ID;FA;;;SY)", true},
+		{"no dacl", "O:BAG:SY", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasExplicitDACL(tt.sddl); got != tt.want {
+				t.Errorf("hasExplicitDACL(%q) = %v, want %v", tt.sddl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlternateStreamRoundTrip(t *testing.T) {
+	path := writeContractFile(t, "download.exe", "binary")
+	zone := "[ZoneTransfer]\r\nZoneId=3\r\n"
+	if err := os.WriteFile(path+":Zone.Identifier", []byte(zone), 0644); err != nil {
+		t.Skipf("volume does not support alternate data streams: %v", err)
+	}
+
+	streams, skipped, err := listAlternateStreams(path)
+	if err != nil || len(skipped) > 0 {
+		t.Fatalf("listAlternateStreams() skipped %q, error %v", skipped, err)
+	}
+	if got := streams["Zone.Identifier"]; got != base64.StdEncoding.EncodeToString([]byte(zone)) {
+		t.Fatalf("streams = %v, want Zone.Identifier only", streams)
+	}
+
+	// 复制到其他卷时只保留文件内容
+	copied := writeContractFile(t, "copied.exe", "binary")
+	if err := restoreAlternateStreams(copied, streams); err != nil {
+		t.Fatalf("restoreAlternateStreams: %v", err)
+	}
+	if value, err := os.ReadFile(copied + ":Zone.Identifier"); err != nil || string(value) != zone {
+		t.Errorf("restored stream = %q, %v; want %q", value, err, zone)
+	}
+}
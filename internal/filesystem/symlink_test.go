@@ -0,0 +1,59 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSymlinksAreTrashedAndRestoredAsLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		target func(t *testing.T) string
+	}{
+		{"link to a file", func(t *testing.T) string { return writeContractFile(t, "target.txt", "keep") }},
+		{"link to a directory", func(t *testing.T) string { return t.TempDir() }},
+		{"broken link", func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing") }},
+	}
+	for name, newManager := range contractBackends(t) {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				manager := newManager(t)
+				target := tt.target(t)
+				link := filepath.Join(t.TempDir(), "link")
+				if err := os.Symlink(target, link); err != nil {
+					t.Skipf("symlinks unavailable: %v", err)
+				}
+				targetBefore, targetErr := os.Lstat(target)
+
+				if err := manager.MoveToTrash(link); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+				if _, err := os.Lstat(link); !os.IsNotExist(err) {
+					t.Fatalf("link still exists: %v", err)
+				}
+				// 只移动链接本身，目标保持不变
+				if targetAfter, err := os.Lstat(target); (err == nil) != (targetErr == nil) ||
+					err == nil && (targetAfter.Size() != targetBefore.Size() || targetAfter.IsDir() != targetBefore.IsDir()) {
+					t.Errorf("trashing the link changed its target: %v", err)
+				}
+
+				item := onlyTrashFile(t, manager)
+				if info, err := os.Lstat(item.TrashPath); err != nil || info.Mode()&os.ModeSymlink == 0 {
+					t.Fatalf("trash payload is not a symlink: %v", err)
+				}
+
+				if err := manager.RestoreFile(item, ""); err != nil {
+					t.Fatalf("RestoreFile: %v", err)
+				}
+				info, err := os.Lstat(link)
+				if err != nil || info.Mode()&os.ModeSymlink == 0 {
+					t.Fatalf("restore did not recreate a symlink: %v", err)
+				}
+				if got, err := os.Readlink(link); err != nil || got != target {
+					t.Errorf("restored link points to %q (%v), want %q", got, err, target)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"delguard/internal/paths"
+)
+
+// CheckSystemTrash 检查当前平台的系统回收站是否可访问，返回检查的路径
+func CheckSystemTrash() (string, error) {
+	var path string
+	switch runtime.GOOS {
+	case "windows":
+		drive := os.Getenv("SystemDrive")
+		if drive == "" {
+			drive = "C:"
+		}
+		path = filepath.Join(drive+`\`, "$Recycle.Bin")
+	case "darwin":
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("无法获取用户主目录: %v", err)
+		}
+		path = filepath.Join(homeDir, ".Trash")
+	case "linux":
+		path = paths.TrashDir()
+	default:
+		return "", fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return path, fmt.Errorf("系统回收站不存在: %s", path)
+		}
+		return path, fmt.Errorf("无法访问系统回收站: %v", err)
+	}
+	if !info.IsDir() {
+		return path, fmt.Errorf("系统回收站不是目录: %s", path)
+	}
+	// macOS上没有完全磁盘访问权限时可以stat但无法列出~/.Trash
+	if _, err := os.ReadDir(path); err != nil {
+		return path, fmt.Errorf("无法读取系统回收站: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,98 @@
+package filesystem
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"delguard/internal/utils"
+)
+
+// 删除目标在去重时被忽略的原因
+const (
+	TargetDuplicate = "duplicate" // 与前面的参数是同一个路径
+	TargetNested    = "nested"    // 位于另一个要删除的目录之中，随该目录一起删除
+)
+
+// SkippedTarget 去重时被忽略的删除目标
+type SkippedTarget struct {
+	Path   string
+	Kept   string // 代替它被处理的目标
+	Reason string
+}
+
+// DedupeTargets 规范化删除目标并去掉重复项，保持输入的先后顺序
+// 经过不同的符号链接目录或大小写到达同一位置的路径只保留第一个；
+// 位于另一个目标目录之中的项目不再单独处理，避免目录移入回收站后再删除其中的文件时报告文件不存在
+// 最后一级不解析符号链接：两个指向同一文件的链接是两个不同的删除目标
+func DedupeTargets(paths []string) ([]string, []SkippedTarget) {
+	keys := make([]string, len(paths))
+	owner := make(map[string]string, len(paths))
+	var kept []string
+	var skipped []SkippedTarget
+	for i, path := range paths {
+		keys[i] = canonicalTarget(path)
+		if first, seen := owner[keys[i]]; seen {
+			skipped = append(skipped, SkippedTarget{Path: path, Kept: first, Reason: TargetDuplicate})
+			keys[i] = ""
+			continue
+		}
+		owner[keys[i]] = path
+	}
+
+	for i, path := range paths {
+		if keys[i] == "" {
+			continue
+		}
+		if parent, nested := nestedIn(keys[i], owner); nested {
+			skipped = append(skipped, SkippedTarget{Path: path, Kept: parent, Reason: TargetNested})
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept, skipped
+}
+
+// canonicalTarget 返回用于比较的路径：解析父目录中的符号链接，不区分大小写的卷上转为小写
+func canonicalTarget(path string) string {
+	path = filepath.Clean(path)
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		path = filepath.Join(dir, filepath.Base(path))
+	}
+	if utils.CaseInsensitive(path) {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// nestedIn 检查key的某一级上级目录是否也是删除目标，返回该目标的原始参数
+func nestedIn(key string, owner map[string]string) (string, bool) {
+	dir := filepath.Dir(key)
+	for {
+		if parent, ok := owner[dir]; ok {
+			return parent, true
+		}
+		next := filepath.Dir(dir)
+		if next == dir {
+			return "", false
+		}
+		dir = next
+	}
+}
+
+// IsEmptyDir 判断目录是否为空，只读取第一个目录项
+func IsEmptyDir(path string) (bool, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer dir.Close()
+
+	if _, err := dir.Readdirnames(1); err == io.EOF {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return false, nil
+}
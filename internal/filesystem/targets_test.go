@@ -0,0 +1,151 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// targetTree 创建 a.txt、ab.txt、b.log、dir/inner.txt 和名称以dir开头的兄弟目录 dir2/other.txt
+func targetTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "ab.txt", "b.log", filepath.Join("dir", "inner.txt"), filepath.Join("dir2", "other.txt")} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// globAll 依次展开多个模式并拼接结果，与shell展开多个重叠的通配符参数相同
+func globAll(t *testing.T, root string, patterns ...string) []string {
+	t.Helper()
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+func TestDedupeTargets(t *testing.T) {
+	root := targetTree(t)
+	p := func(name string) string { return filepath.Join(root, name) }
+	// filepath.Join会清理路径，这里手工拼接保留 ".."
+	unclean := p("dir") + string(filepath.Separator) + ".." + string(filepath.Separator) + "a.txt"
+
+	tests := []struct {
+		name        string
+		paths       []string
+		wantKept    []string
+		wantSkipped []SkippedTarget
+	}{
+		{
+			name:     "distinct",
+			paths:    []string{p("a.txt"), p("b.log")},
+			wantKept: []string{p("a.txt"), p("b.log")},
+		},
+		{
+			name:        "same argument twice",
+			paths:       []string{p("a.txt"), p("a.txt")},
+			wantKept:    []string{p("a.txt")},
+			wantSkipped: []SkippedTarget{{Path: p("a.txt"), Kept: p("a.txt"), Reason: TargetDuplicate}},
+		},
+		{
+			name:        "unclean spelling",
+			paths:       []string{p("a.txt"), unclean},
+			wantKept:    []string{p("a.txt")},
+			wantSkipped: []SkippedTarget{{Path: unclean, Kept: p("a.txt"), Reason: TargetDuplicate}},
+		},
+		{
+			name:     "overlapping globs",
+			paths:    globAll(t, root, "*.txt", "a*"),
+			wantKept: []string{p("a.txt"), p("ab.txt")},
+			wantSkipped: []SkippedTarget{
+				{Path: p("a.txt"), Kept: p("a.txt"), Reason: TargetDuplicate},
+				{Path: p("ab.txt"), Kept: p("ab.txt"), Reason: TargetDuplicate},
+			},
+		},
+		{
+			name:        "file inside deleted directory",
+			paths:       []string{p("dir"), p(filepath.Join("dir", "inner.txt"))},
+			wantKept:    []string{p("dir")},
+			wantSkipped: []SkippedTarget{{Path: p(filepath.Join("dir", "inner.txt")), Kept: p("dir"), Reason: TargetNested}},
+		},
+		{
+			// 文件排在所在目录之前时同样随目录一起删除
+			name:        "nested target listed first",
+			paths:       []string{p(filepath.Join("dir", "inner.txt")), p("a.txt"), p("dir")},
+			wantKept:    []string{p("a.txt"), p("dir")},
+			wantSkipped: []SkippedTarget{{Path: p(filepath.Join("dir", "inner.txt")), Kept: p("dir"), Reason: TargetNested}},
+		},
+		{
+			name:     "sibling sharing a name prefix",
+			paths:    []string{p("dir"), p(filepath.Join("dir2", "other.txt"))},
+			wantKept: []string{p("dir"), p(filepath.Join("dir2", "other.txt"))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, skipped := DedupeTargets(tt.paths)
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("kept = %q, want %q", kept, tt.wantKept)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %+v, want %+v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestDedupeTargetsThroughSymlinks(t *testing.T) {
+	root := targetTree(t)
+	link := filepath.Join(root, "link-to-dir")
+	if err := os.Symlink(filepath.Join(root, "dir"), link); err != nil {
+		t.Skipf("cannot create symlinks here: %v", err)
+	}
+	fileLink := filepath.Join(root, "link-to-a")
+	if err := os.Symlink(filepath.Join(root, "a.txt"), fileLink); err != nil {
+		t.Fatal(err)
+	}
+
+	// 经过符号链接目录到达的同一文件只处理一次
+	viaLink := filepath.Join(link, "inner.txt")
+	direct := filepath.Join(root, "dir", "inner.txt")
+	kept, skipped := DedupeTargets([]string{viaLink, direct})
+	if !reflect.DeepEqual(kept, []string{viaLink}) || len(skipped) != 1 || skipped[0].Reason != TargetDuplicate {
+		t.Errorf("DedupeTargets() = %q, %+v; want the second spelling skipped as a duplicate", kept, skipped)
+	}
+
+	// 链接本身和它指向的文件是两个不同的删除目标
+	paths := []string{fileLink, filepath.Join(root, "a.txt")}
+	kept, skipped = DedupeTargets(paths)
+	if !reflect.DeepEqual(kept, paths) || len(skipped) != 0 {
+		t.Errorf("DedupeTargets() = %q, %+v; want the link and its target both kept", kept, skipped)
+	}
+}
+
+func TestIsEmptyDir(t *testing.T) {
+	root := targetTree(t)
+	empty := filepath.Join(root, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]bool{empty: true, filepath.Join(root, "dir"): false} {
+		if got, err := IsEmptyDir(path); err != nil || got != want {
+			t.Errorf("IsEmptyDir(%s) = %v, %v; want %v", path, got, err, want)
+		}
+	}
+	if _, err := IsEmptyDir(filepath.Join(root, "missing")); err == nil {
+		t.Error("IsEmptyDir on a missing directory returned no error")
+	}
+}
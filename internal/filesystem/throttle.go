@@ -0,0 +1,42 @@
+package filesystem
+
+import (
+	"sync"
+
+	"delguard/internal/utils"
+)
+
+var (
+	throttleMu          sync.RWMutex
+	maintenanceThrottle *utils.Throttle
+	copyThrottle        *utils.Throttle
+)
+
+// SetMaintenanceThrottle 设置后台维护任务（清理、校验、去重、导出）使用的限速器，nil表示不限速
+func SetMaintenanceThrottle(throttle *utils.Throttle) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	maintenanceThrottle = throttle
+}
+
+// MaintenanceThrottle 获取当前维护任务限速器
+func MaintenanceThrottle() *utils.Throttle {
+	throttleMu.RLock()
+	defer throttleMu.RUnlock()
+	return maintenanceThrottle
+}
+
+// SetCopyThrottle 设置跨设备移动到回收站和从回收站恢复时复制文件内容的限速器，nil表示不限速
+// 同时进行的复制共用同一个令牌桶，总吞吐量不超过设定值
+func SetCopyThrottle(throttle *utils.Throttle) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	copyThrottle = throttle
+}
+
+// CopyThrottle 获取当前复制限速器
+func CopyThrottle() *utils.Throttle {
+	throttleMu.RLock()
+	defer throttleMu.RUnlock()
+	return copyThrottle
+}
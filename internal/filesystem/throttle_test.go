@@ -0,0 +1,67 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"delguard/internal/utils"
+)
+
+// useCopyThrottle 在测试期间使用throttle限制复制速率
+func useCopyThrottle(t *testing.T, throttle *utils.Throttle) {
+	t.Helper()
+	saved := CopyThrottle()
+	SetCopyThrottle(throttle)
+	t.Cleanup(func() { SetCopyThrottle(saved) })
+}
+
+func TestStreamCopyRespectsCopyRate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures throughput for about a second")
+	}
+	const rate = 4 * 1024 * 1024
+	useCopyThrottle(t, utils.NewThrottle(rate, 0))
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "large.bin")
+	payload := bytes.Repeat([]byte("delguard"), rate/8)
+	if err := os.WriteFile(src, payload, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	if err := streamCopyFile(context.Background(), src, filepath.Join(dir, "copy.bin"), 0644); err != nil {
+		t.Fatalf("streamCopyFile: %v", err)
+	}
+	elapsed := time.Since(started)
+
+	throughput := float64(len(payload)) / elapsed.Seconds()
+	if throughput < rate*0.8 || throughput > rate*1.2 {
+		t.Errorf("copied at %.0f B/s, want within 20%% of %d B/s (took %v)", throughput, rate, elapsed)
+	}
+	copied, err := os.ReadFile(filepath.Join(dir, "copy.bin"))
+	if err != nil || !bytes.Equal(copied, payload) {
+		t.Errorf("copy differs from the source: %v", err)
+	}
+}
+
+func TestStreamCopyUnthrottledByDefault(t *testing.T) {
+	useCopyThrottle(t, nil)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "large.bin")
+	if err := os.WriteFile(src, make([]byte, 8*1024*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	if err := streamCopyFile(context.Background(), src, filepath.Join(dir, "copy.bin"), 0644); err != nil {
+		t.Fatalf("streamCopyFile: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("unthrottled copy of 8 MB took %v", elapsed)
+	}
+}
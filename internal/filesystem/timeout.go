@@ -0,0 +1,83 @@
+package filesystem
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// copyChunkSize 可中止复制时每块的大小，每块之间检查一次超时
+const copyChunkSize = 1024 * 1024
+
+var (
+	timeoutMu        sync.RWMutex
+	operationTimeout time.Duration
+)
+
+// SetOperationTimeout 设置单个文件操作（移动到回收站、恢复、跨设备复制）的超时时间，0表示不限制
+func SetOperationTimeout(timeout time.Duration) {
+	timeoutMu.Lock()
+	defer timeoutMu.Unlock()
+	operationTimeout = timeout
+}
+
+// OperationTimeout 获取当前文件操作超时时间
+func OperationTimeout() time.Duration {
+	timeoutMu.RLock()
+	defer timeoutMu.RUnlock()
+	return operationTimeout
+}
+
+// timeoutKey 上下文中记录本次操作使用的超时时间
+type timeoutKey struct{}
+
+// newOperationContext 创建带超时的文件操作上下文，paths中任一路径位于网络文件系统时使用网络超时
+// 超时只能在复制的分块之间生效，单个阻塞的系统调用（如rename）无法被中断
+func newOperationContext(paths ...string) (context.Context, context.CancelFunc) {
+	timeout := operationTimeoutFor(paths...)
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	ctx := context.WithValue(context.Background(), timeoutKey{}, timeout)
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError 上下文因超时结束时返回超时错误，否则返回nil
+func timeoutError(ctx context.Context, path string) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		timeout, _ := ctx.Value(timeoutKey{}).(time.Duration)
+		return errors.NewTimeoutError(path, timeout)
+	}
+	return nil
+}
+
+// copyWithContext 分块复制数据，每块之间检查上下文，超时或取消时返回ctx.Err()
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader) (int64, error) {
+	buf := make([]byte, copyChunkSize)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			w, err := dst.Write(buf[:n])
+			written += int64(w)
+			if err != nil {
+				return written, err
+			}
+			if w != n {
+				return written, io.ErrShortWrite
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"delguard/internal/errors"
+	"delguard/internal/utils"
+)
+
+// slowReader 每次读取少量数据后停顿，模拟卡住的网络挂载
+type slowReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	if len(p) > 4096 {
+		p = p[:4096]
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestCopyWithContextStopsSlowReader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	src := &slowReader{data: make([]byte, 1024*1024), delay: 20 * time.Millisecond}
+
+	var dst bytes.Buffer
+	started := time.Now()
+	written, err := copyWithContext(ctx, &dst, src)
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("copyWithContext error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("copy took %v after a 100ms timeout", elapsed)
+	}
+	if written == 0 || written >= 1024*1024 || int(written) != dst.Len() {
+		t.Errorf("written = %d (buffer %d), want a partial copy", written, dst.Len())
+	}
+}
+
+func TestCopyWithContextCompletes(t *testing.T) {
+	data := bytes.Repeat([]byte("delguard"), 300000)
+	var dst bytes.Buffer
+	written, err := copyWithContext(context.Background(), &dst, bytes.NewReader(data))
+	if err != nil || written != int64(len(data)) || !bytes.Equal(dst.Bytes(), data) {
+		t.Errorf("copyWithContext = %d, %v", written, err)
+	}
+}
+
+func TestCopyTreeTimesOutBetweenChunks(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "large.bin")
+	if err := os.WriteFile(src, make([]byte, 4*1024*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dstDir := filepath.Join(dir, "trash")
+	if err := os.Mkdir(dstDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dstDir, "large.bin")
+
+	// 禁用reflink，限速到1MB/s使复制需要数秒
+	reflinkMu.Lock()
+	reflinkSupport[dstDir] = false
+	reflinkMu.Unlock()
+	forgetReflinkSupport(t, dstDir)
+	SetCopyThrottle(utils.NewThrottle(1024*1024, 0))
+	SetOperationTimeout(150 * time.Millisecond)
+	t.Cleanup(func() {
+		SetCopyThrottle(nil)
+		SetOperationTimeout(0)
+	})
+
+	ctx, cancel := newOperationContext(src, dst)
+	defer cancel()
+	started := time.Now()
+	if err := copyTree(ctx, src, dst); err == nil {
+		t.Fatal("copyTree finished a throttled 4MB copy within 150ms")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("copy aborted after %v, want shortly after the timeout", elapsed)
+	}
+
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial destination was left behind: %v", err)
+	}
+	if info, err := os.Stat(src); err != nil || info.Size() != 4*1024*1024 {
+		t.Errorf("source changed after an aborted copy: %v", err)
+	}
+
+	err := timeoutError(ctx, src)
+	var delErr *errors.DelGuardError
+	if !stderrors.As(err, &delErr) || delErr.Type != errors.ErrTypeTimeout || delErr.Path != src {
+		t.Fatalf("timeoutError = %v, want a timeout error for %s", err, src)
+	}
+	if got := errors.ExitCode(err); got != errors.ExitTempFail {
+		t.Errorf("exit code = %d, want %d", got, errors.ExitTempFail)
+	}
+}
+
+func TestNewOperationContextWithoutTimeout(t *testing.T) {
+	SetOperationTimeout(0)
+	ctx, cancel := newOperationContext(t.TempDir())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("operation context has a deadline although performance.timeout is 0")
+	}
+	cancel()
+	if err := timeoutError(ctx, "x"); err != nil {
+		t.Errorf("cancellation reported as timeout: %v", err)
+	}
+}
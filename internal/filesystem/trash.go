@@ -1,11 +1,16 @@
 package filesystem
 
 import (
+	stderrors "errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/utils"
 )
 
 // TrashManager 回收站管理器接口
@@ -36,6 +41,47 @@ type TrashManager interface {
 	CleanOldFiles(maxDays int) error
 	// ValidateTrash 验证回收站完整性
 	ValidateTrash() error
+	// VerifyTrash 检查孤立的元数据和缺少元数据的文件，repair为true时修复
+	VerifyTrash(repair bool) (TrashVerifyReport, error)
+	// ImportFile 将sourcePath按给定元数据放入回收站，保留原始路径和删除时间
+	ImportFile(sourcePath string, metadata TrashMetadata) error
+	// ReadItemMetadata 读取回收站项目保存的DelGuard元数据，项目没有元数据时返回false
+	ReadItemMetadata(file TrashFile) (TrashMetadata, bool)
+	// WriteItemMetadata 保存回收站项目的DelGuard元数据，元数据位置由各平台的管理器决定
+	WriteItemMetadata(file TrashFile, metadata TrashMetadata) error
+}
+
+// Warning 项目已经移入回收站，但过程中出现了需要告知用户的情况，例如共享回收站不可用时改用了本地回收站
+// MoveToTrash返回Warning时操作本身是成功的，调用方用AsWarning区分
+type Warning struct {
+	Path    string
+	Message string
+	Err     error
+}
+
+func (w *Warning) Error() string {
+	if w.Err == nil {
+		return fmt.Sprintf("%s: %s", w.Path, w.Message)
+	}
+	return fmt.Sprintf("%s: %s: %v", w.Path, w.Message, w.Err)
+}
+
+func (w *Warning) Unwrap() error {
+	return w.Err
+}
+
+// AsWarning 判断err是否只是警告，是时返回警告内容
+func AsWarning(err error) (*Warning, bool) {
+	var warning *Warning
+	if stderrors.As(err, &warning) {
+		return warning, true
+	}
+	return nil, false
+}
+
+// shareFallbackWarning 共享回收站不可用、项目改为移入本地回收站时返回的警告
+func shareFallbackWarning(path, root string, cause error) *Warning {
+	return &Warning{Path: path, Message: fmt.Sprintf("无法使用共享回收站 %s，已改为移动到本地回收站", root), Err: cause}
 }
 
 // TrashFile 回收站文件信息
@@ -48,6 +94,10 @@ type TrashFile struct {
 	DeletedTime  time.Time // 删除时间
 	IsDirectory  bool      // 是否为目录
 	Permissions  string    // 文件权限
+	Pinned       bool      // 是否已固定
+	Corrupt      bool      // 最近一次内容校验发现哈希不一致
+	Compressed   bool      // 内容已被trash compact压缩，Size为压缩前的大小
+	Source       string    // 项目来源: SourceDelGuard或SourceSystem，为空时为DelGuard回收站
 }
 
 // TrashItem 通用回收站项目信息（用于接口统一）
@@ -58,27 +108,132 @@ type TrashItem struct {
 	Size         int64     // 文件大小
 	DeletedTime  time.Time // 删除时间
 	IsDirectory  bool      // 是否为目录
+	Pinned       bool      // 是否已固定
+	Corrupt      bool      // 最近一次内容校验发现哈希不一致
+	Compressed   bool      // 内容已被trash compact压缩，Size为压缩前的大小
+	Source       string    // 项目来源: SourceDelGuard或SourceSystem，为空时为DelGuard回收站
 }
 
 // TrashStats 回收站统计信息
 type TrashStats struct {
-	TotalFiles int64     // 总文件数
-	TotalSize  int64     // 总大小
-	OldestFile time.Time // 最旧文件时间
+	TotalFiles int64     `json:"total_files"` // 总文件数
+	TotalSize  int64     `json:"total_size"`  // 总大小
+	DiskSize   int64     `json:"disk_size"`   // 实际占用的磁盘空间，稀疏文件按已分配的块计算，硬链接只计一次
+	OldestFile time.Time `json:"oldest_file"` // 最旧文件时间
+	// 已固定的项目不会被自动清理，不计入可回收空间
+	PinnedFiles int64 `json:"pinned_files"`
+	PinnedSize  int64 `json:"pinned_size"`
+
+	// 以下字段由ComputeTrashStats填充
+	Files            int64         `json:"files"`                     // 文件数量
+	Directories      int64         `json:"directories"`               // 目录数量
+	ByDirectory      []StatsBucket `json:"by_directory,omitempty"`    // 按原始所在目录分组
+	ByExtension      []StatsBucket `json:"by_extension,omitempty"`    // 按扩展名分组
+	ByAge            []StatsBucket `json:"by_age,omitempty"`          // 按删除时长分组
+	QuotaSize        int64         `json:"quota_size,omitempty"`      // 回收站容量上限
+	QuotaUsedPercent float64       `json:"quota_used_percent"`        // 容量使用百分比
+	DailyRate        int64         `json:"daily_rate"`                // 近30天平均每天删除的字节数
+	QuotaFullDate    time.Time     `json:"quota_full_date,omitempty"` // 按近期速率预计达到上限的日期
 }
 
-// GetTrashManager 根据操作系统获取对应的回收站管理器
-func GetTrashManager() (TrashManager, error) {
-	switch runtime.GOOS {
-	case "windows":
-		return NewWindowsTrashManager(), nil
-	case "darwin":
-		return NewDarwinTrashManager(), nil
-	case "linux":
-		return NewLinuxTrashManager(), nil
-	default:
-		return nil, fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
+// NewTrashManager 根据配置和操作系统创建回收站管理器
+// trash.use_system_trash为false时，所有平台都改用~/.delguard/trash下的DelGuard专用回收站
+func NewTrashManager(cfg *config.Config) (TrashManager, error) {
+	return NewTrashManagerAt(cfg, "")
+}
+
+// NewTrashManagerAt 创建使用指定回收站目录的管理器，trashDir为空时使用配置决定的位置
+// 指定目录时总是使用DelGuard专用回收站的目录结构，不经过系统回收站
+func NewTrashManagerAt(cfg *config.Config, trashDir string) (TrashManager, error) {
+	useSystemTrash := true
+	preserveXattrs := true
+	hashOnDelete := true
+	networkTrash := NetworkTrashShare
+	networkHash := false
+	if cfg != nil {
+		useSystemTrash = cfg.Trash.UseSystemTrash
+		preserveXattrs = cfg.Trash.PreserveXattrs
+		hashOnDelete = cfg.Trash.HashOnDelete
+		networkHash = cfg.Trash.NetworkHash
+		if cfg.Trash.NetworkTrash != "" {
+			networkTrash = strings.ToLower(cfg.Trash.NetworkTrash)
+		}
+	}
+
+	trashRoot := trashDir
+	if trashDir != "" {
+		useSystemTrash = false
+	} else if !useSystemTrash {
+		root, err := delguardTrashRoot()
+		if err != nil {
+			return nil, err
+		}
+		trashRoot = root
 	}
+
+	name := os.Getenv(BackendEnvVar)
+	if name == "" {
+		name = runtime.GOOS
+	}
+	factory, ok := lookupBackend(name)
+	if !ok {
+		if name == runtime.GOOS {
+			return nil, fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
+		}
+		if name == MemoryBackend {
+			return nil, fmt.Errorf("%s=%s 只能用于以 -tags delguard_memory 构建的测试版本", BackendEnvVar, name)
+		}
+		return nil, fmt.Errorf("未知的回收站后端 %s=%s，可用: %s", BackendEnvVar, name, strings.Join(BackendNames(), ", "))
+	}
+	return factory(BackendOptions{
+		UseSystemTrash: useSystemTrash,
+		PreserveXattrs: preserveXattrs,
+		HashOnDelete:   hashOnDelete,
+		NetworkTrash:   networkTrash,
+		NetworkHash:    networkHash,
+		TrashDir:       trashDir,
+		TrashRoot:      trashRoot,
+	})
+}
+
+// delguardTrashRoot 获取DelGuard专用回收站根目录
+func delguardTrashRoot() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取用户主目录: %v", err)
+	}
+	return filepath.Join(homeDir, ".delguard", "trash"), nil
+}
+
+// FindTrashed 查找原始路径为originalPath的最近一次删除的回收站项目
+func FindTrashed(manager TrashManager, originalPath string) (TrashFile, bool) {
+	files, err := manager.ListTrashFiles()
+	if err != nil {
+		return TrashFile{}, false
+	}
+
+	var found TrashFile
+	ok := false
+	for _, file := range files {
+		if utils.SamePath(originalPath, file.OriginalPath) && (!ok || file.DeletedTime.After(found.DeletedTime)) {
+			found = file
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+// TrashBackend 返回回收站路径所属的后端名称：DelGuard专用回收站或系统回收站
+func TrashBackend(trashPath string) string {
+	if root, err := delguardTrashRoot(); err == nil && isSubPath(root, trashPath) {
+		return "DelGuard专用回收站"
+	}
+	return "系统回收站"
+}
+
+// GetTrashManager 根据操作系统获取对应的回收站管理器，使用全局配置
+func GetTrashManager() (TrashManager, error) {
+	return NewTrashManager(config.Current())
 }
 
 // FormatFileSize 格式化文件大小显示
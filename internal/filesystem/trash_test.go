@@ -0,0 +1,103 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"delguard/internal/config"
+)
+
+// useTempHome 将用户主目录指向临时目录，DelGuard专用回收站随之位于其中
+func useTempHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv(BackendEnvVar, "")
+	return home
+}
+
+func TestNewTrashManagerHonorsUseSystemTrash(t *testing.T) {
+	home := useTempHome(t)
+	var cfg config.Config
+	cfg.Trash.UseSystemTrash = false
+
+	manager, err := NewTrashManager(&cfg)
+	if err != nil {
+		t.Fatalf("NewTrashManager: %v", err)
+	}
+	trashPath, err := manager.GetTrashPath()
+	if err != nil {
+		t.Fatalf("GetTrashPath: %v", err)
+	}
+	if root := filepath.Join(home, ".delguard", "trash"); !isSubPath(root, trashPath) {
+		t.Errorf("use_system_trash=false: trash path %s is not under %s", trashPath, root)
+	}
+}
+
+func TestNewTrashManagerAtUsesTrashDir(t *testing.T) {
+	useTempHome(t)
+	trashDir := t.TempDir()
+
+	for _, cfg := range []*config.Config{nil, {}} {
+		manager, err := NewTrashManagerAt(cfg, trashDir)
+		if err != nil {
+			t.Fatalf("NewTrashManagerAt: %v", err)
+		}
+		trashPath, err := manager.GetTrashPath()
+		if err != nil {
+			t.Fatalf("GetTrashPath: %v", err)
+		}
+		if !isSubPath(trashDir, trashPath) {
+			t.Errorf("--trash-dir %s: trash path is %s", trashDir, trashPath)
+		}
+	}
+}
+
+func TestTrashDirOverrideRoundTrip(t *testing.T) {
+	home := useTempHome(t)
+	trashDir := filepath.Join(t.TempDir(), ".trash")
+	if err := os.Mkdir(trashDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewTrashManagerAt(&config.Config{}, trashDir)
+	if err != nil {
+		t.Fatalf("NewTrashManagerAt: %v", err)
+	}
+	path := writeContractFile(t, "draft.txt", "draft")
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+
+	// 下一次调用使用同一个--trash-dir时列出和恢复的是同一个回收站
+	again, err := NewTrashManagerAt(&config.Config{}, trashDir)
+	if err != nil {
+		t.Fatalf("NewTrashManagerAt: %v", err)
+	}
+	item := onlyTrashFile(t, again)
+	if item.OriginalPath != path || !isSubPath(trashDir, item.TrashPath) {
+		t.Fatalf("listed %+v, want %s stored under %s", item, path, trashDir)
+	}
+	if err := again.RestoreFile(item, ""); err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "draft" {
+		t.Errorf("restored content = %q, %v", data, err)
+	}
+
+	// 默认的回收站没有被使用
+	if _, err := os.Stat(filepath.Join(home, ".delguard", "trash")); !os.IsNotExist(err) {
+		t.Errorf("the default trash was created: %v", err)
+	}
+}
+
+func TestNewTrashManagerRejectsUnknownBackend(t *testing.T) {
+	useTempHome(t)
+	t.Setenv(BackendEnvVar, "no-such-backend")
+	if _, err := NewTrashManager(nil); err == nil {
+		t.Error("NewTrashManager accepted an unknown backend")
+	}
+}
@@ -0,0 +1,147 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// inFlightGrace 比这更新的元数据视为正在进行的删除，不判定为孤立
+const inFlightGrace = time.Minute
+
+// TrashVerifyReport 回收站元数据校验结果
+type TrashVerifyReport struct {
+	Checked          int      `json:"checked"`           // 检查的回收站文件数
+	OrphanedMetadata []string `json:"orphaned_metadata"` // 没有对应文件的元数据
+	OrphanedFiles    []string `json:"orphaned_files"`    // 没有任何元数据的文件
+	RemovedMetadata  int      `json:"removed_metadata"`  // 修复时删除的过期元数据数
+	CreatedMetadata  int      `json:"created_metadata"`  // 修复时补建的元数据数
+	Errors           []string `json:"errors,omitempty"`  // 修复过程中的错误
+	// Integrity 内容校验结果，只检查元数据时为空
+	Integrity *IntegrityReport `json:"integrity,omitempty"`
+}
+
+// Healthy 没有发现孤立的元数据或文件时返回true
+func (r TrashVerifyReport) Healthy() bool {
+	return len(r.OrphanedMetadata) == 0 && len(r.OrphanedFiles) == 0
+}
+
+// metadataSource 回收站中一类元数据文件所在的目录和后缀
+type metadataSource struct {
+	dir    string
+	suffix string
+}
+
+// trashLayout 描述回收站的文件目录与元数据目录，供校验使用
+type trashLayout struct {
+	filesDir string
+	// sources 元数据来源，补建的元数据写入第一个来源
+	sources []metadataSource
+	// checkFiles 为false时不检查缺少元数据的文件（系统回收站中其他程序放入的文件本就没有DelGuard元数据）
+	checkFiles bool
+	write      func(metadataFile string, metadata TrashMetadata) error
+}
+
+// verifyTrashLayout 比对回收站中的文件与元数据，repair为true时删除过期元数据并为缺少元数据的文件补建最小元数据
+func verifyTrashLayout(layout trashLayout, repair bool) (TrashVerifyReport, error) {
+	report := TrashVerifyReport{OrphanedMetadata: []string{}, OrphanedFiles: []string{}}
+
+	files := make(map[string]os.FileInfo)
+	entries, err := os.ReadDir(layout.filesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return report, errors.FromOS("读取回收站失败", err)
+	}
+	for _, entry := range entries {
+		// 与列出回收站时一致，跳过隐藏文件和元数据目录
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files[entry.Name()] = info
+	}
+	report.Checked = len(files)
+
+	described := make(map[string]bool)
+	for _, source := range layout.sources {
+		entries, err := os.ReadDir(source.dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return report, errors.FromOS("读取元数据目录失败", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), source.suffix) {
+				continue
+			}
+			name := strings.TrimSuffix(entry.Name(), source.suffix)
+			if _, ok := files[name]; ok {
+				described[name] = true
+				continue
+			}
+			// 删除时先预留元数据再移动文件，刚创建的元数据可能属于正在进行的删除
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) < inFlightGrace {
+				continue
+			}
+
+			metadataFile := filepath.Join(source.dir, entry.Name())
+			report.OrphanedMetadata = append(report.OrphanedMetadata, metadataFile)
+			if repair {
+				if err := os.Remove(metadataFile); err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("删除过期元数据失败 %s: %v", metadataFile, err))
+				} else {
+					report.RemovedMetadata++
+				}
+			}
+		}
+	}
+
+	if layout.checkFiles {
+		for name, info := range files {
+			if described[name] {
+				continue
+			}
+			trashPath := filepath.Join(layout.filesDir, name)
+			report.OrphanedFiles = append(report.OrphanedFiles, trashPath)
+			if repair && len(layout.sources) > 0 && layout.write != nil {
+				metadataFile := filepath.Join(layout.sources[0].dir, name+layout.sources[0].suffix)
+				err := os.MkdirAll(layout.sources[0].dir, 0755)
+				if err == nil {
+					err = layout.write(metadataFile, synthesizeMetadata(name, info))
+				}
+				if err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("补建元数据失败 %s: %v", trashPath, err))
+				} else {
+					report.CreatedMetadata++
+				}
+			}
+		}
+	}
+
+	sort.Strings(report.OrphanedMetadata)
+	sort.Strings(report.OrphanedFiles)
+	return report, nil
+}
+
+// synthesizeMetadata 为缺少元数据的回收站文件生成最小元数据，删除时间取文件修改时间，原始路径未知
+func synthesizeMetadata(name string, info os.FileInfo) TrashMetadata {
+	metadata := TrashMetadata{
+		ID:          name,
+		DeletedTime: info.ModTime(),
+		FileName:    name,
+		Size:        info.Size(),
+		IsDirectory: info.IsDir(),
+		Permissions: info.Mode().String(),
+		Synthesized: true,
+	}
+	captureFileAttributes(&metadata, info)
+	return metadata
+}
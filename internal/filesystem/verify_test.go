@@ -0,0 +1,136 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ageTree 将root下所有条目的修改时间改为两小时前，使元数据不再被视为正在进行的删除
+func ageTree(t *testing.T, root string) {
+	t.Helper()
+	old := time.Now().Add(-2 * time.Hour)
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil {
+			os.Chtimes(path, old, old)
+		}
+		return nil
+	})
+}
+
+func TestVerifyTrashFindsAndRepairsOrphans(t *testing.T) {
+	for name, newManager := range contractBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+			for _, file := range []string{"kept.txt", "lost.txt"} {
+				if err := manager.MoveToTrash(writeContractFile(t, file, file)); err != nil {
+					t.Fatalf("MoveToTrash: %v", err)
+				}
+			}
+			files, err := manager.ListTrashFiles()
+			if err != nil {
+				t.Fatalf("ListTrashFiles: %v", err)
+			}
+			var kept, lost TrashFile
+			for _, file := range files {
+				switch file.Name {
+				case "kept.txt":
+					kept = file
+				case "lost.txt":
+					lost = file
+				}
+			}
+
+			// 文件本体丢失的元数据，以及没有元数据的文件
+			if err := os.Remove(lost.TrashPath); err != nil {
+				t.Fatal(err)
+			}
+			stray := filepath.Join(filepath.Dir(kept.TrashPath), "stray.bin")
+			if err := os.WriteFile(stray, []byte("no metadata"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			trashPath, err := manager.GetTrashPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ageTree(t, filepath.Dir(trashPath))
+
+			// 后端可能为每个项目保存多份元数据（如.trashinfo和JSON），它们都应被报告
+			var orphanedMetadata int
+			for i := 0; i < 2; i++ {
+				report, err := manager.VerifyTrash(false)
+				if err != nil {
+					t.Fatalf("VerifyTrash: %v", err)
+				}
+				if len(report.OrphanedMetadata) == 0 || len(report.OrphanedFiles) != 1 {
+					t.Fatalf("report = %+v, want orphaned metadata and one orphaned file", report)
+				}
+				orphanedMetadata = len(report.OrphanedMetadata)
+				if !strings.HasSuffix(report.OrphanedFiles[0], "stray.bin") {
+					t.Errorf("orphaned file = %q, want stray.bin", report.OrphanedFiles[0])
+				}
+				if report.RemovedMetadata != 0 || report.CreatedMetadata != 0 {
+					t.Errorf("verify without --repair changed the trash: %+v", report)
+				}
+			}
+
+			report, err := manager.VerifyTrash(true)
+			if err != nil {
+				t.Fatalf("VerifyTrash(repair): %v", err)
+			}
+			if report.RemovedMetadata != orphanedMetadata || report.CreatedMetadata != 1 || len(report.Errors) != 0 {
+				t.Errorf("repair report = %+v", report)
+			}
+
+			report, err = manager.VerifyTrash(false)
+			if err != nil || !report.Healthy() {
+				t.Errorf("after repair: %+v, %v", report, err)
+			}
+			files, err = manager.ListTrashFiles()
+			if err != nil {
+				t.Fatalf("ListTrashFiles: %v", err)
+			}
+			names := make(map[string]bool)
+			for _, file := range files {
+				names[file.Name] = true
+			}
+			if len(files) != 2 || !names["kept.txt"] || !names["stray.bin"] {
+				t.Errorf("after repair the trash lists %v, want kept.txt and stray.bin", names)
+			}
+		})
+	}
+}
+
+func TestVerifyTrashLayoutSkipsFreshMetadata(t *testing.T) {
+	root := t.TempDir()
+	filesDir := filepath.Join(root, "files")
+	infoDir := filepath.Join(root, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 删除时先预留元数据再移动文件，刚写入的元数据不算孤立
+	fresh := filepath.Join(infoDir, "in-flight.trashinfo")
+	if err := os.WriteFile(fresh, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	layout := trashLayout{
+		filesDir:   filesDir,
+		sources:    []metadataSource{{dir: infoDir, suffix: ".trashinfo"}},
+		checkFiles: true,
+	}
+	report, err := verifyTrashLayout(layout, true)
+	if err != nil {
+		t.Fatalf("verifyTrashLayout: %v", err)
+	}
+	if !report.Healthy() || report.RemovedMetadata != 0 {
+		t.Errorf("report = %+v, want fresh metadata left alone", report)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh metadata was removed: %v", err)
+	}
+}
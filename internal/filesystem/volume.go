@@ -0,0 +1,34 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Volume 路径所在的卷及其可用空间
+type Volume struct {
+	ID   string // 卷标识，同一卷上的路径相同
+	Free uint64 // 当前用户可用的字节数
+}
+
+// VolumeOf 获取path所在的卷，path不存在时使用最近的已存在上级目录
+func VolumeOf(path string) (Volume, error) {
+	existing := filepath.Clean(path)
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return Volume{}, fmt.Errorf("找不到已存在的上级目录: %s", path)
+		}
+		existing = parent
+	}
+	return volumeOf(existing)
+}
+
+// TreeSize 计算文件或目录中所有文件的总大小
+func TreeSize(path string) int64 {
+	return treeSize(path)
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package filesystem
+
+import "fmt"
+
+// volumeOf 当前平台不支持获取可用空间
+func volumeOf(path string) (Volume, error) {
+	return Volume{}, fmt.Errorf("当前平台不支持获取磁盘可用空间")
+}
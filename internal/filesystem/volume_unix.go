@@ -0,0 +1,25 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// volumeOf 以设备号标识卷，通过statfs获取可用空间
+func volumeOf(path string) (Volume, error) {
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return Volume{}, err
+	}
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err != nil {
+		return Volume{}, err
+	}
+	return Volume{
+		ID:   fmt.Sprintf("dev:%d", uint64(stat.Dev)),
+		Free: uint64(fs.Bavail) * uint64(fs.Bsize),
+	}, nil
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// volumeOf 以盘符或UNC共享标识卷，通过GetDiskFreeSpaceEx获取可用空间
+func volumeOf(path string) (Volume, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Volume{}, err
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &free, nil, nil); err != nil {
+		return Volume{}, err
+	}
+	return Volume{
+		ID:   strings.ToUpper(filepath.VolumeName(path)),
+		Free: free,
+	}, nil
+}
@@ -1,37 +1,27 @@
 package filesystem
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
-)
 
-// TrashMetadata 回收站元数据结构
-type TrashMetadata struct {
-	OriginalPath string    `json:"original_path"`
-	DeletedTime  time.Time `json:"deleted_time"`
-	FileName     string    `json:"file_name"`
-	Size         int64     `json:"size"`
-	IsDirectory  bool      `json:"is_directory"`
-	Permissions  string    `json:"permissions"`
-	Hash         string    `json:"hash,omitempty"`
-	SystemTrash  bool      `json:"system_trash,omitempty"`
-}
+	"delguard/internal/errors"
+	"delguard/internal/utils"
+)
 
 // copyDirectoryAndRemove 递归复制目录后删除源目录
-func (w *WindowsTrashManager) copyDirectoryAndRemove(src, dst string) error {
+func (w *WindowsTrashManager) copyDirectoryAndRemove(ctx context.Context, src, dst string) error {
 	// 确保源目录存在
 	srcInfo, err := os.Stat(src)
 	if err != nil {
-		return fmt.Errorf("无法访问源目录: %v", err)
+		return errors.FromOS("无法访问源目录", err)
 	}
 	if !srcInfo.IsDir() {
 		return fmt.Errorf("源路径不是目录: %s", src)
@@ -39,13 +29,13 @@ func (w *WindowsTrashManager) copyDirectoryAndRemove(src, dst string) error {
 
 	// 创建目标目录
 	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return fmt.Errorf("创建目标目录失败: %v", err)
+		return errors.FromOS("创建目标目录失败", err)
 	}
 
 	// 读取源目录内容
 	entries, err := os.ReadDir(src)
 	if err != nil {
-		return fmt.Errorf("读取源目录失败: %v", err)
+		return errors.FromOS("读取源目录失败", err)
 	}
 
 	// 递归复制每个条目
@@ -54,11 +44,11 @@ func (w *WindowsTrashManager) copyDirectoryAndRemove(src, dst string) error {
 		dstPath := filepath.Join(dst, entry.Name())
 
 		if entry.IsDir() {
-			if err := w.copyDirectoryAndRemove(srcPath, dstPath); err != nil {
+			if err := w.copyDirectoryAndRemove(ctx, srcPath, dstPath); err != nil {
 				return err
 			}
 		} else {
-			if err := w.copyAndRemove(srcPath, dstPath); err != nil {
+			if _, err := w.copyAndRemove(ctx, srcPath, dstPath); err != nil {
 				return err
 			}
 		}
@@ -71,11 +61,30 @@ func (w *WindowsTrashManager) copyDirectoryAndRemove(src, dst string) error {
 // WindowsTrashManager Windows回收站管理器
 type WindowsTrashManager struct {
 	forceOverwrite bool
+	useSystemTrash bool
+	runner         utils.CommandRunner
+	// trashDir 覆盖DelGuard专用回收站的位置，为空时使用%USERPROFILE%\.delguard\trash
+	trashDir string
+	// preserveXattrs 删除时记录备用数据流和显式ACL，恢复时写回
+	preserveXattrs bool
+	// hashOnDelete 同一驱动器上重命名到专用回收站的文件也计算内容哈希，跨驱动器复制时总是随复制计算
+	hashOnDelete bool
+	// networkHash 网络驱动器上的文件也按hashOnDelete计算哈希，关闭时避免再通过网络读取一遍
+	networkHash bool
+	// networkTrash 网络驱动器上的项目使用的回收站: NetworkTrashShare或NetworkTrashLocal
+	networkTrash string
+	// share 管理的是网络共享根目录下的专用回收站，不再转交其他回收站
+	share bool
 }
 
 // NewWindowsTrashManager 创建Windows回收站管理器
 func NewWindowsTrashManager() *WindowsTrashManager {
-	return &WindowsTrashManager{forceOverwrite: false}
+	return &WindowsTrashManager{forceOverwrite: false, useSystemTrash: true, preserveXattrs: true, hashOnDelete: true, networkTrash: NetworkTrashShare, runner: utils.ExecRunner{}}
+}
+
+// SetCommandRunner 替换执行PowerShell/wscript的命令执行器，用于测试
+func (w *WindowsTrashManager) SetCommandRunner(runner utils.CommandRunner) {
+	w.runner = runner
 }
 
 // SetForceOverwrite 设置是否强制覆盖已存在文件
@@ -85,8 +94,8 @@ func (w *WindowsTrashManager) SetForceOverwrite(force bool) {
 
 // MoveToTrash 将文件移动到Windows回收站
 func (w *WindowsTrashManager) MoveToTrash(filePath string) error {
-	// 转换为绝对路径
-	absPath, err := filepath.Abs(filePath)
+	// 转换为绝对路径，保留名称末尾的点和空格
+	absPath, err := AbsPath(filePath)
 	if err != nil {
 		return fmt.Errorf("路径转换失败: %v", err)
 	}
@@ -96,13 +105,35 @@ func (w *WindowsTrashManager) MoveToTrash(filePath string) error {
 		return fmt.Errorf("路径验证失败: %v", err)
 	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("文件不存在: %s", absPath)
+	// 检查文件是否存在，失效的符号链接同样可以删除
+	if _, err := Lstat(absPath); os.IsNotExist(err) {
+		return errors.NewFileNotFoundError(absPath)
 	}
 
-	// 尝试使用系统回收站
-	if w.CanUseSystemRecycleBin() {
+	// 拒绝重复删除回收站中的文件
+	if err := ValidateTrashSource(w, absPath); err != nil {
+		return err
+	}
+
+	// 网络驱动器上的项目放入同一共享上的专用回收站：系统回收站不支持网络驱动器，
+	// 本地回收站需要跨网络复制
+	if root, ok := w.shareTrashRoot(absPath); ok {
+		share, err := w.openShareTrash(root)
+		if err == nil {
+			return share.moveToDelGuardTrash(absPath)
+		}
+		if moveErr := w.moveToLocalTrash(absPath); moveErr != nil {
+			return moveErr
+		}
+		return shareFallbackWarning(absPath, root, err)
+	}
+	return w.moveToLocalTrash(absPath)
+}
+
+// moveToLocalTrash 将已检查过的absPath移入系统回收站，不可用时移入DelGuard专用回收站
+func (w *WindowsTrashManager) moveToLocalTrash(absPath string) error {
+	// 尝试使用系统回收站，名称以点或空格结尾的文件Shell无法处理，直接放入专用回收站
+	if w.useSystemTrash && !trailingNameOnWindows(absPath) && w.CanUseSystemRecycleBin() {
 		// 优先使用PowerShell方法
 		if err := w.moveToSystemRecycleBin(absPath); err == nil {
 			return nil
@@ -135,7 +166,7 @@ func (w *WindowsTrashManager) moveToSystemRecycleBin(filePath string) error {
 	
 	// 首先检查文件是否存在
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("文件不存在: %s", filePath)
+		return errors.NewFileNotFoundError(filePath)
 	}
 	
 	// 尝试使用系统回收站 - 使用cmd.exe的move命令作为临时解决方案
@@ -166,7 +197,7 @@ func (w *WindowsTrashManager) moveToRecycleBinWithShellAPI(filePath string) erro
 	// 使用Windows Shell API (SHFileOperationW) 的Go实现
 	// 检查文件是否存在
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("文件不存在: %s", filePath)
+		return errors.NewFileNotFoundError(filePath)
 	}
 	
 		// 使用VBS脚本调用Windows Shell API
@@ -186,10 +217,7 @@ objFile.InvokeVerb("delete")
 	defer os.Remove(tempVBS)
 	
 	// 执行VBS脚本
-	cmd := exec.Command("wscript", tempVBS)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	
-	if err := cmd.Run(); err != nil {
+	if _, err := w.runner.Run("wscript", tempVBS); err != nil {
 		return fmt.Errorf("Shell API移动失败: %v", err)
 	}
 	
@@ -216,7 +244,7 @@ func (w *WindowsTrashManager) moveToRecycleBinWithPowerShell(filePath string) er
 	
 	// 检查文件是否存在
 	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("文件不存在: %s", absPath)
+		return errors.NewFileNotFoundError(absPath)
 	}
 	
 	// 使用更安全的方式构建PowerShell命令参数
@@ -286,18 +314,25 @@ try {
 }
 `
 	
-	// 执行PowerShell命令，将路径作为参数传递
-	// 使用更安全的参数传递方式，避免命令注入
-	cmd := exec.Command("powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", psScript)
-	cmd.Args = append(cmd.Args, "-FilePath", absPath)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true} // 隐藏窗口
-	
-	// 设置超时防止进程挂起
-	cmd.Env = append(os.Environ(), "COMSPEC=cmd.exe")
-	
-	output, err := cmd.CombinedOutput()
+	// Shell COM对象忙时调用会暂时失败，按短间隔重试
+	attempt := 0
+	err = errors.WithRetryContext(context.Background(), recycleBinRetry, func() error {
+		attempt++
+		// 上一次调用报错但实际已完成移动时不再重复执行
+		if _, statErr := os.Lstat(absPath); attempt > 1 && os.IsNotExist(statErr) {
+			return nil
+		}
+
+		// 执行PowerShell命令，将路径作为参数传递
+		// 使用更安全的参数传递方式，避免命令注入
+		output, err := w.runner.Run("powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", psScript, "-FilePath", absPath)
+		if err != nil {
+			return classifyShellError("PowerShell移动失败", err, output)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("PowerShell移动失败: %v, 输出: %s", err, string(output))
+		return err
 	}
 	
 	// 验证文件是否已被删除
@@ -315,75 +350,139 @@ func (w *WindowsTrashManager) executeRecycleCommand(filePath string) error {
 
 // moveToDelGuardTrash 使用DelGuard专用回收站
 func (w *WindowsTrashManager) moveToDelGuardTrash(filePath string) error {
-	// 获取用户回收站路径
-	userProfile := os.Getenv("USERPROFILE")
-	if userProfile == "" {
-		return fmt.Errorf("无法获取用户配置目录")
-	}
-
 	// 创建DelGuard专用回收站目录
-	delguardTrash := filepath.Join(userProfile, ".delguard", "trash")
+	delguardTrash, err := w.delguardTrashDir()
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(delguardTrash, 0755); err != nil {
-		return fmt.Errorf("创建DelGuard回收站目录失败: %v", err)
+		return errors.FromOS("创建DelGuard回收站目录失败", err)
 	}
 
 	// 创建回收站元数据目录
 	metadataDir := filepath.Join(delguardTrash, ".metadata")
 	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("创建回收站元数据目录失败: %v", err)
+		return errors.FromOS("创建回收站元数据目录失败", err)
 	}
 
-	// 获取文件信息
-	fileInfo, err := os.Stat(filePath)
+	// 获取文件信息，符号链接记录链接本身
+	fileInfo, err := Lstat(filePath)
 	if err != nil {
-		return fmt.Errorf("获取文件信息失败: %v", err)
+		return errors.FromOS("获取文件信息失败", err)
 	}
 
-	// 使用原始文件名，保持文件名不变
+	// 回收站中的文件名由ID生成，原始文件名只保存在元数据中，
+	// 名称末尾的点和空格只保留在元数据里，恢复时通过扩展路径按原名重建
 	fileName := filepath.Base(filePath)
-	targetPath := filepath.Join(delguardTrash, fileName)
-
-	// 如果目标文件已存在，添加时间戳
-	counter := 1
-	for {
-		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
-			break
-		}
-		timestamp := time.Now().Format("20060102_150405")
-		targetPath = filepath.Join(delguardTrash, fmt.Sprintf("%s_%s_%d%s", 
-			fileName[:len(fileName)-len(filepath.Ext(fileName))], timestamp, counter, filepath.Ext(fileName)))
-		counter++
-	}
-
-	// 计算文件哈希值（用于完整性验证）
-	fileHash, err := w.calculateFileHash(filePath)
+	sourcePath := OSPath(filePath)
+	trashName, err := reserveTrashName(delguardTrash, fileName, func(name string) string {
+		return filepath.Join(metadataDir, name+".json")
+	})
 	if err != nil {
-		fileHash = "" // 如果无法计算哈希，留空但不中断操作
+		return err
 	}
+	targetPath := filepath.Join(delguardTrash, trashName)
+	metadataFile := filepath.Join(metadataDir, trashName+".json")
 
 	// 创建元数据
 	metadata := TrashMetadata{
+		ID:           trashName,
 		OriginalPath: filePath,
 		DeletedTime:  time.Now(),
 		FileName:     fileName,
 		Size:         fileInfo.Size(),
 		IsDirectory:  fileInfo.IsDir(),
 		Permissions:  fileInfo.Mode().String(),
-		Hash:         fileHash,
 		SystemTrash:  false, // 标记为DelGuard专用回收站
 	}
+	captureFileAttributes(&metadata, fileInfo)
+	captureLinkTarget(&metadata, sourcePath, fileInfo)
+	annotateNetworkFS(&metadata, filePath)
+	if w.preserveXattrs && fileInfo.Mode()&os.ModeSymlink == 0 {
+		if err := captureExtendedAttributes(&metadata, sourcePath); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  警告: %s: %v\n", filePath, err)
+		}
+	}
 	
-	metadataFile := filepath.Join(metadataDir, filepath.Base(targetPath)+".json")
 	if err := w.writeJSONMetadata(metadataFile, metadata); err != nil {
-		return fmt.Errorf("创建元数据文件失败: %v", err)
+		os.Remove(metadataFile)
+		return errors.FromOS("创建元数据文件失败", err)
 	}
 
 	// 使用更可靠的移动方法处理跨驱动器情况
-	return w.moveFileWithProgress(filePath, targetPath)
+	fileHash, err := w.moveFileWithProgress(sourcePath, targetPath)
+	if err != nil {
+		// 目录按文件逐个移动，超时中止时已移入回收站的部分保留元数据以便恢复
+		if _, statErr := os.Lstat(targetPath); statErr != nil || !errors.IsType(err, errors.ErrTypeTimeout) {
+			os.Remove(metadataFile)
+		}
+		return err
+	}
+
+	// 记录文件哈希值（用于完整性验证）：跨驱动器复制时已随复制计算，
+	// 重命名或克隆时按hashOnDelete从回收站中的文件计算，无法计算时留空但不中断操作；
+	// 网络驱动器上的文件除非设置了trash.network_hash，否则不再通过网络读取一遍
+	if fileHash == "" && w.hashOnDelete && (metadata.FSType == "" || w.networkHash) && fileInfo.Mode().IsRegular() {
+		if hash, err := w.calculateFileHash(targetPath); err == nil {
+			fileHash = hash
+		}
+	}
+	if fileHash != "" {
+		metadata.Hash = fileHash
+		if err := w.writeJSONMetadata(metadataFile, metadata); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  警告: 记录 %s 的哈希失败: %v\n", filePath, err)
+		}
+	}
+
+	return nil
+}
+
+// ImportFile 将sourcePath按给定元数据放入DelGuard专用回收站，系统回收站不支持指定原始路径
+func (w *WindowsTrashManager) ImportFile(sourcePath string, metadata TrashMetadata) error {
+	delguardTrash, err := w.GetTrashPath()
+	if err != nil {
+		return err
+	}
+	metadataDir := filepath.Join(delguardTrash, ".metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return errors.FromOS("创建回收站元数据目录失败", err)
+	}
+
+	trashName, err := reserveTrashName(delguardTrash, metadata.FileName, func(name string) string {
+		return filepath.Join(metadataDir, name+".json")
+	})
+	if err != nil {
+		return err
+	}
+	targetPath := filepath.Join(delguardTrash, trashName)
+	metadataFile := filepath.Join(metadataDir, trashName+".json")
+
+	// 哈希按本机算法重新计算，用于恢复时的完整性验证
+	metadata.ID = trashName
+	metadata.SystemTrash = false
+	metadata.Hash = ""
+	if !metadata.IsDirectory {
+		if fileHash, err := w.calculateFileHash(sourcePath); err == nil {
+			metadata.Hash = fileHash
+		}
+	}
+	if err := w.writeJSONMetadata(metadataFile, metadata); err != nil {
+		os.Remove(metadataFile)
+		return errors.FromOS("创建元数据文件失败", err)
+	}
+	if err := moveIntoTrash(sourcePath, targetPath); err != nil {
+		os.Remove(metadataFile)
+		return err
+	}
+	return nil
 }
 
 // GetTrashPath 获取Windows回收站路径
 func (w *WindowsTrashManager) GetTrashPath() (string, error) {
+	if w.trashDir != "" {
+		return w.trashDir, nil
+	}
+
 	// 优先使用DelGuard专用回收站目录
 	userProfile := os.Getenv("USERPROFILE")
 	if userProfile == "" {
@@ -394,6 +493,82 @@ func (w *WindowsTrashManager) GetTrashPath() (string, error) {
 	return filepath.Join(userProfile, ".delguard", "trash"), nil
 }
 
+// delguardTrashDir 获取DelGuard专用回收站目录，未覆盖位置且无法获取用户配置目录时返回错误
+func (w *WindowsTrashManager) delguardTrashDir() (string, error) {
+	if w.trashDir == "" && os.Getenv("USERPROFILE") == "" {
+		return "", fmt.Errorf("无法获取用户配置目录")
+	}
+	return w.GetTrashPath()
+}
+
+// shareTrashRoot 返回网络驱动器上的path使用的共享回收站，即共享根目录下按用户区分的.delguard-trash-<用户名>
+// 按trash.network_trash使用本地回收站、指定了--trash-dir，或回收站本身就在同一共享上时返回false
+func (w *WindowsTrashManager) shareTrashRoot(path string) (string, bool) {
+	if w.share || w.networkTrash != NetworkTrashShare || w.trashDir != "" {
+		return "", false
+	}
+	mount, remote := RemoteMount(path)
+	if !remote {
+		return "", false
+	}
+	if trashPath, err := w.GetTrashPath(); err == nil {
+		if trashMount, err := MountOf(nearestExisting(trashPath)); err == nil && sameMountPoint(trashMount.MountPoint, mount.MountPoint) {
+			return "", false
+		}
+	}
+	user := os.Getenv("USERNAME")
+	if user == "" {
+		user = "user"
+	}
+	return filepath.Join(mount.MountPoint, shareTrashPrefix+user), true
+}
+
+// shareTrash 返回管理共享回收站root的管理器
+func (w *WindowsTrashManager) shareTrash(root string) *WindowsTrashManager {
+	return &WindowsTrashManager{
+		forceOverwrite: w.forceOverwrite,
+		runner:         w.runner,
+		trashDir:       root,
+		preserveXattrs: w.preserveXattrs,
+		hashOnDelete:   w.hashOnDelete,
+		networkHash:    w.networkHash,
+		share:          true,
+	}
+}
+
+// openShareTrash 创建共享回收站并记录其位置，之后列出回收站时一并列出
+func (w *WindowsTrashManager) openShareTrash(root string) (*WindowsTrashManager, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	if err := rememberShareTrash(root); err != nil {
+		return nil, err
+	}
+	return w.shareTrash(root), nil
+}
+
+// shareTrashOf 返回trashPath所在的共享回收站的管理器，不在已记录的共享回收站中时返回nil
+func (w *WindowsTrashManager) shareTrashOf(trashPath string) *WindowsTrashManager {
+	if w.share {
+		return nil
+	}
+	for _, root := range KnownShareTrashes() {
+		if isSubPath(root, trashPath) {
+			return w.shareTrash(root)
+		}
+	}
+	return nil
+}
+
+// metadataFile 返回回收站项目对应的元数据文件路径
+func (w *WindowsTrashManager) metadataFile(id string) (string, error) {
+	dir, err := w.delguardTrashDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ".metadata", id+".json"), nil
+}
+
 // GetSystemRecycleBinPath 获取Windows系统回收站路径
 func (w *WindowsTrashManager) GetSystemRecycleBinPath() (string, error) {
 	// 获取系统回收站路径
@@ -420,8 +595,25 @@ func (w *WindowsTrashManager) CanUseSystemRecycleBin() bool {
 	return true
 }
 
-// ListTrashFiles 列出Windows回收站中的文件
+// ListTrashFiles 列出Windows回收站中的文件，包括网络共享上的回收站
 func (w *WindowsTrashManager) ListTrashFiles() ([]TrashFile, error) {
+	files, err := w.listDelGuardTrash()
+	if err != nil {
+		return nil, err
+	}
+	// 共享未连接时跳过
+	if !w.share && w.networkTrash == NetworkTrashShare {
+		for _, root := range existingShareTrashes() {
+			if shared, err := w.shareTrash(root).listDelGuardTrash(); err == nil {
+				files = append(files, shared...)
+			}
+		}
+	}
+	return files, nil
+}
+
+// listDelGuardTrash 列出DelGuard专用回收站目录中的文件
+func (w *WindowsTrashManager) listDelGuardTrash() ([]TrashFile, error) {
 	trashPath, err := w.GetTrashPath()
 	if err != nil {
 		return nil, err
@@ -437,7 +629,7 @@ func (w *WindowsTrashManager) ListTrashFiles() ([]TrashFile, error) {
 
 	entries, err := os.ReadDir(trashPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取回收站失败: %v", err)
+		return nil, errors.FromOS("读取回收站失败", err)
 	}
 
 	var trashFiles []TrashFile
@@ -457,10 +649,16 @@ func (w *WindowsTrashManager) ListTrashFiles() ([]TrashFile, error) {
 		metadataFile := filepath.Join(metadataDir, entry.Name()+".json")
 		var originalPath string
 		var deletedTime time.Time
+		displayName := entry.Name()
+		var metadata *TrashMetadata
 		
-		if metadata, err := w.readJSONMetadata(metadataFile); err == nil {
-			originalPath = metadata.OriginalPath
+		if stored, err := w.readJSONMetadata(metadataFile); err == nil {
+			metadata = stored
+			originalPath = metadata.nativeOriginalPath()
 			deletedTime = metadata.DeletedTime
+			if metadata.FileName != "" {
+				displayName = metadata.FileName
+			}
 		} else {
 			// 如果没有元数据，使用文件修改时间
 			deletedTime = info.ModTime()
@@ -472,7 +670,7 @@ func (w *WindowsTrashManager) ListTrashFiles() ([]TrashFile, error) {
 
 		trashFile := TrashFile{
 			ID:           entry.Name(),
-			Name:         entry.Name(),
+			Name:         displayName,
 			OriginalPath: originalPath,
 			TrashPath:    fullPath,
 			Size:         info.Size(),
@@ -480,6 +678,9 @@ func (w *WindowsTrashManager) ListTrashFiles() ([]TrashFile, error) {
 			IsDirectory:  entry.IsDir(),
 			Permissions:  info.Mode().String(),
 		}
+		if metadata != nil {
+			applyListFlags(&trashFile, metadata)
+		}
 
 		trashFiles = append(trashFiles, trashFile)
 	}
@@ -489,6 +690,13 @@ func (w *WindowsTrashManager) ListTrashFiles() ([]TrashFile, error) {
 
 // RestoreFile 从Windows回收站恢复文件
 func (w *WindowsTrashManager) RestoreFile(trashFile TrashFile, targetPath string) error {
+	if trashFile.Source == SourceSystem {
+		return w.restoreFromSystemBin(trashFile, targetPath)
+	}
+	if share := w.shareTrashOf(trashFile.TrashPath); share != nil {
+		return share.RestoreFile(trashFile, targetPath)
+	}
+
 	// 验证回收站文件路径
 	if err := w.validatePath(trashFile.TrashPath); err != nil {
 		return fmt.Errorf("回收站文件路径验证失败: %v", err)
@@ -504,11 +712,11 @@ func (w *WindowsTrashManager) RestoreFile(trashFile TrashFile, targetPath string
 		targetPath = trashFile.OriginalPath
 	}
 	
-	// 确保使用绝对路径
+	// 确保使用绝对路径，保留名称末尾的点和空格
 	var err error
-	targetPath, err = filepath.Abs(targetPath)
+	targetPath, err = AbsPath(targetPath)
 	if err != nil {
-		return fmt.Errorf("获取目标路径失败: %v", err)
+		return errors.FromOS("获取目标路径失败", err)
 	}
 
 	// 验证目标路径安全性
@@ -516,58 +724,73 @@ func (w *WindowsTrashManager) RestoreFile(trashFile TrashFile, targetPath string
 		return fmt.Errorf("目标路径验证失败: %v", err)
 	}
 
+	// 不允许恢复到回收站内部
+	if err := ValidateRestoreTarget(w, targetPath); err != nil {
+		return err
+	}
+
 	// 确保目标目录存在
 	targetDir := filepath.Dir(targetPath)
 	if err := CreateDirIfNotExists(targetDir); err != nil {
-		return fmt.Errorf("创建目标目录失败: %v", err)
+		return errors.FromOS("创建目标目录失败", err)
 	}
 
-	// 检查目标文件是否已存在
-	if _, err := os.Stat(targetPath); err == nil {
+	// 检查目标文件是否已存在，只有大小写不同的文件也算
+	if _, exists := ExistingEntry(targetPath); exists {
 		if !w.forceOverwrite {
-			// 如果目标文件存在，添加后缀
-			ext := filepath.Ext(targetPath)
-			base := targetPath[:len(targetPath)-len(ext)]
-			counter := 1
-			for {
-				newPath := fmt.Sprintf("%s_%d%s", base, counter, ext)
-				if _, err := os.Stat(newPath); os.IsNotExist(err) {
-					targetPath = newPath
-					break
-				}
-				counter++
-			}
+			// 如果目标文件存在，按restore.rename_pattern改名
+			targetPath = ConflictFreePath(targetPath, nil)
 		}
 	}
 
 	// 从元数据获取文件信息以验证完整性
-	userProfile := os.Getenv("USERPROFILE")
+	metadataFile, metadataErr := w.metadataFile(trashFile.ID)
 	var expectedHash string
-	if userProfile != "" {
-		metadataFile := filepath.Join(userProfile, ".delguard", "trash", ".metadata", trashFile.ID+".json")
+	var savedMetadata *TrashMetadata
+	if metadataErr == nil {
 		if metadata, err := w.readJSONMetadata(metadataFile); err == nil {
 			expectedHash = metadata.Hash
+			savedMetadata = metadata
 		}
 	}
 
-	// 移动文件从回收站到目标位置
-	err = w.moveFileWithProgress(trashFile.TrashPath, targetPath)
+	// 移动文件从回收站到目标位置，符号链接按原指向重建，已压缩的文件解压
+	// 跨驱动器复制时返回复制过程中计算的哈希，校验时不必再读取一遍
+	// 名称以点或空格结尾时通过扩展路径按原名重建
+	var copiedHash string
+	restorePath := OSPath(targetPath)
+	if savedMetadata != nil && (savedMetadata.LinkTarget != "" || savedMetadata.Compressed) {
+		err = moveOutOfTrash(trashFile.TrashPath, restorePath, savedMetadata)
+	} else {
+		copiedHash, err = w.moveFileWithProgress(trashFile.TrashPath, restorePath)
+	}
 	if err != nil {
-		return fmt.Errorf("恢复文件失败: %v", err)
+		return errors.FromOS("恢复文件失败", err)
+	}
+	if restorePath != targetPath {
+		fmt.Fprintf(os.Stderr, "⚠️  警告: %s 的名称以点或空格结尾，资源管理器和大多数程序仍无法打开、重命名或删除它\n", targetPath)
 	}
 
 	// 验证文件完整性
 		if expectedHash != "" {
-			if !w.verifyFileIntegrity(targetPath, expectedHash) {
+			intact := copiedHash == expectedHash
+			if copiedHash == "" {
+				intact = w.verifyFileIntegrity(restorePath, expectedHash)
+			}
+			if !intact {
 				// 文件完整性验证失败，但仍然返回成功，只是记录警告
 				// 使用标准错误输出而不是fmt.Printf
 				fmt.Fprintf(os.Stderr, "⚠️  警告: 文件完整性验证失败，文件可能在传输过程中损坏: %s\n", targetPath)
 			}
 		}
 
+	// 恢复只读属性和时间戳
+	if err := applyFileAttributes(restorePath, savedMetadata); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  警告: %s: %v\n", targetPath, err)
+	}
+
 	// 清理对应的元数据文件
-	if userProfile != "" {
-		metadataFile := filepath.Join(userProfile, ".delguard", "trash", ".metadata", trashFile.ID+".json")
+	if metadataErr == nil {
 		os.Remove(metadataFile)
 	}
 
@@ -586,38 +809,21 @@ func (w *WindowsTrashManager) EmptyTrash() error {
 		return fmt.Errorf("回收站路径验证失败: %v", err)
 	}
 
-	// 检查回收站目录是否存在
-	if _, err := os.Stat(trashPath); os.IsNotExist(err) {
-		return nil // 回收站已经是空的
-	}
-
-	entries, err := os.ReadDir(trashPath)
-	if err != nil {
-		return fmt.Errorf("读取回收站失败: %v", err)
+	// 逐个删除文件和目录及其元数据，跳过元数据目录和隐藏文件
+	if err := emptyTrashDir(trashPath, func(name string) bool {
+		return strings.HasPrefix(name, ".")
+	}); err != nil {
+		return err
 	}
 
-	// 删除所有文件和目录，但跳过隐藏目录和元数据目录
-	for _, entry := range entries {
-		name := entry.Name()
-		
-		// 跳过元数据目录和隐藏文件
-		if name == ".metadata" || strings.HasPrefix(name, ".") {
-			continue
-		}
-		
-		fullPath := filepath.Join(trashPath, name)
-		
-		// 验证要删除的文件路径
-		if err := w.validatePath(fullPath); err != nil {
-			return fmt.Errorf("要删除的文件路径验证失败: %v", err)
-		}
-		
-		err := os.RemoveAll(fullPath)
-		if err != nil {
-			return fmt.Errorf("删除文件失败 %s: %v", fullPath, err)
+	// 网络共享上的回收站一并清空
+	if !w.share && w.networkTrash == NetworkTrashShare {
+		for _, root := range existingShareTrashes() {
+			if err := w.shareTrash(root).EmptyTrash(); err != nil {
+				return err
+			}
 		}
 	}
-
 	return nil
 }
 
@@ -637,6 +843,10 @@ func (w *WindowsTrashManager) ListTrashContents() ([]TrashItem, error) {
 			Size:         file.Size,
 			DeletedTime:  file.DeletedTime,
 			IsDirectory:  file.IsDirectory,
+			Pinned:       file.Pinned,
+			Corrupt:      file.Corrupt,
+			Compressed:   file.Compressed,
+			Source:       file.Source,
 		}
 	}
 
@@ -729,15 +939,30 @@ try {
     exit 1
 }
 `
-	cmd := exec.Command("powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", psScript)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("清空系统回收站失败: %v, 输出: %s", err, string(output))
+	return errors.WithRetryContext(context.Background(), recycleBinRetry, func() error {
+		output, err := w.runner.Run("powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", psScript)
+		if err != nil {
+			return classifyShellError("清空系统回收站失败", err, output)
+		}
+		return nil
+	})
+}
+
+// recycleBinRetry 回收站PowerShell/COM调用的重试策略，Shell COM对象忙通常很快恢复
+var recycleBinRetry = errors.RetryPolicy{Attempts: 3, Delay: 200 * time.Millisecond, MaxDelay: time.Second}
+
+// permissionMarkers PowerShell/COM输出中表示权限不足的关键字
+var permissionMarkers = []string{"Access is denied", "UnauthorizedAccess", "PermissionDenied", "0x80070005", "拒绝访问"}
+
+// classifyShellError 对PowerShell/COM调用失败分类：权限不足不重试，其余按COM对象忙等暂时性错误处理
+func classifyShellError(message string, err error, output []byte) error {
+	cause := fmt.Errorf("%v, 输出: %s", err, strings.TrimSpace(string(output)))
+	for _, marker := range permissionMarkers {
+		if strings.Contains(string(output), marker) {
+			return errors.NewError(errors.ErrTypePermissionDenied, message, cause)
+		}
 	}
-	
-	return nil
+	return errors.NewTransientError(message, cause)
 }
 
 // IsEmpty 检查回收站是否为空
@@ -763,12 +988,19 @@ func (w *WindowsTrashManager) GetTrashStats() (*TrashStats, error) {
 
 	if len(files) > 0 {
 		stats.OldestFile = files[0].DeletedTime
+		paths := make([]string, 0, len(files))
 		for _, file := range files {
 			stats.TotalSize += file.Size
+			paths = append(paths, file.TrashPath)
+			if file.Pinned {
+				stats.PinnedFiles++
+				stats.PinnedSize += file.Size
+			}
 			if file.DeletedTime.Before(stats.OldestFile) {
 				stats.OldestFile = file.DeletedTime
 			}
 		}
+		stats.DiskSize = trashDiskSize(paths)
 	}
 
 	return stats, nil
@@ -776,6 +1008,7 @@ func (w *WindowsTrashManager) GetTrashStats() (*TrashStats, error) {
 
 // CleanOldFiles 清理过期文件
 func (w *WindowsTrashManager) CleanOldFiles(maxDays int) error {
+	throttle := MaintenanceThrottle()
 	if maxDays < 0 {
 		return fmt.Errorf("清理天数不能为负数")
 	}
@@ -785,25 +1018,25 @@ func (w *WindowsTrashManager) CleanOldFiles(maxDays int) error {
 		return err
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -maxDays)
+	// 按原始位置匹配保留规则，未匹配时使用maxDays
+	now := time.Now()
 
 	for _, file := range files {
-		if file.DeletedTime.Before(cutoffTime) {
+		if !file.Pinned && isExpired(file.OriginalPath, file.DeletedTime, maxDays, now) {
 			// 验证要删除的文件路径
 			if err := w.validatePath(file.TrashPath); err != nil {
 				return fmt.Errorf("要清理的文件路径验证失败: %v", err)
 			}
 			
-			if err := os.RemoveAll(file.TrashPath); err != nil {
+			if err := removeAllWritable(file.TrashPath); err != nil {
 				return fmt.Errorf("清理过期文件失败 %s: %v", file.TrashPath, err)
 			}
 			
 			// 清理对应的元数据文件
-			userProfile := os.Getenv("USERPROFILE")
-			if userProfile != "" {
-				metadataFile := filepath.Join(userProfile, ".delguard", "trash", ".metadata", file.ID+".json")
+			if metadataFile, err := w.metadataFile(file.ID); err == nil {
 				os.Remove(metadataFile)
 			}
+			throttle.Pause()
 		}
 	}
 
@@ -817,20 +1050,20 @@ func (w *WindowsTrashManager) writeJSONMetadata(metadataFile string, metadata Tr
 		return fmt.Errorf("元数据文件路径验证失败: %v", err)
 	}
 	
-	data, err := json.MarshalIndent(metadata, "", "  ")
+	data, err := json.MarshalIndent(withPortablePath(metadata), "", "  ")
 	if err != nil {
 		return fmt.Errorf("序列化元数据失败: %v", err)
 	}
 	
 	// 确保目录存在
 	if err := os.MkdirAll(filepath.Dir(metadataFile), 0755); err != nil {
-		return fmt.Errorf("创建元数据目录失败: %v", err)
+		return errors.FromOS("创建元数据目录失败", err)
 	}
 	
 	// 使用临时文件和原子写入，防止数据损坏
 	tempFile := metadataFile + ".tmp"
 	if err := os.WriteFile(tempFile, data, 0644); err != nil {
-		return fmt.Errorf("写入临时元数据文件失败: %v", err)
+		return errors.FromOS("写入临时元数据文件失败", err)
 	}
 	
 	return os.Rename(tempFile, metadataFile)
@@ -845,12 +1078,12 @@ func (w *WindowsTrashManager) readJSONMetadata(metadataFile string) (*TrashMetad
 	
 	// 检查文件是否存在且可读
 	if _, err := os.Stat(metadataFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("元数据文件不存在: %v", err)
+		return nil, errors.FromOS("元数据文件不存在", err)
 	}
 	
 	data, err := os.ReadFile(metadataFile)
 	if err != nil {
-		return nil, fmt.Errorf("读取元数据文件失败: %v", err)
+		return nil, errors.FromOS("读取元数据文件失败", err)
 	}
 	
 	// 验证JSON数据大小，防止内存耗尽
@@ -920,14 +1153,14 @@ func (w *WindowsTrashManager) ValidateTrash() error {
 	// 检查回收站目录是否存在，不存在则创建
 	if _, err := os.Stat(trashPath); os.IsNotExist(err) {
 		if err := os.MkdirAll(trashPath, 0755); err != nil {
-			return fmt.Errorf("创建回收站目录失败: %v", err)
+			return errors.FromOS("创建回收站目录失败", err)
 		}
 	}
 
 	// 创建必要的子目录
 	metadataDir := filepath.Join(trashPath, ".metadata")
 	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("创建元数据目录失败: %v", err)
+		return errors.FromOS("创建元数据目录失败", err)
 	}
 
 	// 检查目录权限
@@ -938,6 +1171,20 @@ func (w *WindowsTrashManager) ValidateTrash() error {
 	return nil
 }
 
+// VerifyTrash 检查DelGuard专用回收站中孤立的元数据和缺少元数据的文件
+func (w *WindowsTrashManager) VerifyTrash(repair bool) (TrashVerifyReport, error) {
+	trashPath, err := w.GetTrashPath()
+	if err != nil {
+		return TrashVerifyReport{}, err
+	}
+	return verifyTrashLayout(trashLayout{
+		filesDir:   trashPath,
+		sources:    []metadataSource{{dir: filepath.Join(trashPath, ".metadata"), suffix: ".json"}},
+		checkFiles: true,
+		write:      w.writeJSONMetadata,
+	}, repair)
+}
+
 // hasWritePermission 检查写权限
 func (w *WindowsTrashManager) hasWritePermission(path string) bool {
 	testFile := filepath.Join(path, ".delguard_test")
@@ -953,12 +1200,12 @@ func (w *WindowsTrashManager) hasWritePermission(path string) bool {
 // validateMetadataPath 验证元数据文件路径
 func (w *WindowsTrashManager) validateMetadataPath(metadataFile string) error {
 	// 确保元数据文件在DelGuard回收站目录下
-	userProfile := os.Getenv("USERPROFILE")
-	if userProfile == "" {
-		return fmt.Errorf("无法获取用户配置目录")
+	trashDir, err := w.delguardTrashDir()
+	if err != nil {
+		return err
 	}
 
-	expectedDir := filepath.Join(userProfile, ".delguard", "trash", ".metadata")
+	expectedDir := filepath.Join(trashDir, ".metadata")
 	absExpectedDir, err := filepath.Abs(expectedDir)
 	if err != nil {
 		return fmt.Errorf("无法获取期望目录的绝对路径: %v", err)
@@ -994,8 +1241,8 @@ func (w *WindowsTrashManager) validateMetadataContent(metadata *TrashMetadata) e
 		return fmt.Errorf("元数据不能为空")
 	}
 	
-	// 验证原始路径
-	if metadata.OriginalPath == "" {
+	// 验证原始路径，补建的元数据没有原始路径
+	if metadata.OriginalPath == "" && !metadata.Synthesized {
 		return fmt.Errorf("原始路径不能为空")
 	}
 	
@@ -1100,83 +1347,102 @@ func (w *WindowsTrashManager) validatePath(path string) error {
 	return nil
 }
 
-// moveFileWithProgress 带进度显示的文件移动
-func (w *WindowsTrashManager) moveFileWithProgress(src, dst string) error {
+// moveFileWithProgress 带进度显示的文件移动，经过流式复制的单个文件返回复制时计算的SHA256，
+// 重命名、克隆或移动目录时返回空
+func (w *WindowsTrashManager) moveFileWithProgress(src, dst string) (string, error) {
 	// 确保源文件存在
-	_, err := os.Stat(src)
+	_, err := os.Lstat(src)
 	if err != nil {
-		return fmt.Errorf("源文件不存在: %v", err)
+		return "", errors.FromOS("源文件不存在", err)
 	}
 
 	// 确保目标目录存在
 	dstDir := filepath.Dir(dst)
 	if err := os.MkdirAll(dstDir, 0755); err != nil {
-		return fmt.Errorf("创建目标目录失败: %v", err)
+		return "", errors.FromOS("创建目标目录失败", err)
 	}
 
 	// 如果源和目标在同一驱动器，直接重命名
-	srcDrive := filepath.VolumeName(src)
-	dstDrive := filepath.VolumeName(dst)
+	srcDrive := filepath.VolumeName(plainPath(src))
+	dstDrive := filepath.VolumeName(plainPath(dst))
 	
 	if srcDrive == dstDrive {
 		// 先尝试重命名
-		if err := os.Rename(src, dst); err == nil {
-			return nil
+		if err := renameWritable(src, dst); err == nil {
+			return "", nil
 		}
 		// 重命名失败，回退到复制+删除
 	}
 
-	// 跨驱动器移动或重命名失败，使用复制+删除
-	return w.copyAndRemove(src, dst)
+	// 跨驱动器移动或重命名失败，使用复制+删除，超过performance.timeout（网络文件系统上为performance.network_timeout）时中止
+	ctx, cancel := newOperationContext(src, dst)
+	defer cancel()
+	hash, err := w.copyAndRemove(ctx, src, dst)
+	if err != nil {
+		if timeoutErr := timeoutError(ctx, src); timeoutErr != nil {
+			return "", timeoutErr
+		}
+		return "", err
+	}
+	return hash, nil
 }
 
-// copyAndRemove 复制文件后删除源文件
-func (w *WindowsTrashManager) copyAndRemove(src, dst string) error {
+// copyAndRemove 复制文件后删除源文件，流式复制单个文件时同时计算SHA256并返回，避免再读取一遍
+// 复制目录或以块克隆复制时返回空
+func (w *WindowsTrashManager) copyAndRemove(ctx context.Context, src, dst string) (string, error) {
 	// 获取源文件信息
 	info, err := os.Stat(src)
 	if err != nil {
-		return fmt.Errorf("无法访问源文件: %v", err)
+		return "", errors.FromOS("无法访问源文件", err)
 	}
 
 	// 如果是目录，使用递归复制
 	if info.IsDir() {
-		return w.copyDirectoryAndRemove(src, dst)
+		return "", w.copyDirectoryAndRemove(ctx, src, dst)
+	}
+
+	// 支持块克隆的文件系统上直接克隆
+	if tryReflink(src, dst) {
+		return "", os.Remove(src)
 	}
 
 	// 文件复制
 	srcFile, err := os.Open(src)
 	if err != nil {
-		return fmt.Errorf("无法打开源文件: %v", err)
+		return "", errors.FromOS("无法打开源文件", err)
 	}
 	defer srcFile.Close()
 
 	// 确保目标目录存在
 	dstDir := filepath.Dir(dst)
 	if err := os.MkdirAll(dstDir, 0755); err != nil {
-		return fmt.Errorf("创建目标目录失败: %v", err)
+		return "", errors.FromOS("创建目标目录失败", err)
 	}
 
 	// 创建目标文件
 	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
 	if err != nil {
-		return fmt.Errorf("无法创建目标文件: %v", err)
+		return "", errors.FromOS("无法创建目标文件", err)
 	}
 	defer dstFile.Close()
 
-	// 复制文件内容
-	written, err := io.Copy(dstFile, srcFile)
+	// 分块复制文件内容并同时计算哈希，按performance.copy_rate限速，超时中止时删除不完整的目标文件
+	hasher := sha256.New()
+	written, err := copyWithContext(ctx, io.MultiWriter(dstFile, hasher), CopyThrottle().Reader(srcFile))
 	if err != nil {
-		return fmt.Errorf("文件复制失败: %v", err)
+		dstFile.Close()
+		os.Remove(dst)
+		return "", errors.FromOS("文件复制失败", err)
 	}
 
 	// 验证文件大小
 	if written != info.Size() {
-		return fmt.Errorf("文件复制不完整: 期望 %d 字节, 实际 %d 字节", info.Size(), written)
+		return "", fmt.Errorf("文件复制不完整: 期望 %d 字节, 实际 %d 字节", info.Size(), written)
 	}
 
 	// 确保数据写入磁盘
 	if err := dstFile.Sync(); err != nil {
-		return fmt.Errorf("数据同步失败: %v", err)
+		return "", errors.FromOS("数据同步失败", err)
 	}
 
 	// 关闭文件句柄确保数据写入
@@ -1185,13 +1451,40 @@ func (w *WindowsTrashManager) copyAndRemove(src, dst string) error {
 
 	// 验证目标文件是否创建成功
 	if _, err := os.Stat(dst); os.IsNotExist(err) {
-		return fmt.Errorf("目标文件创建失败")
+		return "", fmt.Errorf("目标文件创建失败")
 	}
 
 	// 删除源文件
 	if err := os.Remove(src); err != nil {
-		return fmt.Errorf("删除源文件失败: %v", err)
+		return "", errors.FromOS("删除源文件失败", err)
 	}
 
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// systemBinUnavailable 返回系统回收站不可用的原因
+func (w *WindowsTrashManager) systemBinUnavailable() error {
+	if !w.CanUseSystemRecycleBin() {
+		return fmt.Errorf("系统回收站不可用")
+	}
 	return nil
 }
+
+// moveToSystemBin 将专用回收站中的项目移入系统回收站，回收站中显示的原位置为项目在专用回收站中的路径
+// 不使用moveToSystemRecycleBin，它在失败时会回退到DelGuard专用回收站
+func (w *WindowsTrashManager) moveToSystemBin(file TrashFile, metadata TrashMetadata) error {
+	if err := w.moveToRecycleBinWithPowerShell(file.TrashPath); err == nil {
+		return nil
+	}
+	return w.moveToRecycleBinWithShellAPI(file.TrashPath)
+}
+
+// ReadItemMetadata 读取回收站项目旁的JSON元数据
+func (w *WindowsTrashManager) ReadItemMetadata(file TrashFile) (TrashMetadata, bool) {
+	return readItemMetadata(file)
+}
+
+// WriteItemMetadata 将元数据写入回收站项目旁的JSON元数据文件
+func (w *WindowsTrashManager) WriteItemMetadata(file TrashFile, metadata TrashMetadata) error {
+	return storeMetadata(w, file, metadata)
+}
@@ -0,0 +1,143 @@
+package filesystem
+
+import (
+	stderrors "errors"
+	"os"
+	"testing"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// scriptedRunner 按顺序返回预设结果的命令执行器，结果用完后一直成功
+type scriptedRunner struct {
+	results []scriptedResult
+	calls   [][]string
+}
+
+type scriptedResult struct {
+	output string
+	err    error
+	// effect 在返回结果前执行，模拟命令的副作用
+	effect func()
+}
+
+func (r *scriptedRunner) Run(name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	if len(r.results) == 0 {
+		return nil, nil
+	}
+	result := r.results[0]
+	r.results = r.results[1:]
+	if result.effect != nil {
+		result.effect()
+	}
+	return []byte(result.output), result.err
+}
+
+// fastRecycleBinRetry 测试期间缩短重试等待
+func fastRecycleBinRetry(t *testing.T) {
+	saved := recycleBinRetry
+	recycleBinRetry = errors.RetryPolicy{Attempts: saved.Attempts, Delay: time.Millisecond, MaxDelay: time.Millisecond}
+	t.Cleanup(func() { recycleBinRetry = saved })
+}
+
+var errExit1 = stderrors.New("exit status 1")
+
+func busy() scriptedResult {
+	return scriptedResult{output: "Exception from HRESULT: 0x8001010A (RPC_E_SERVERCALL_RETRYLATER)", err: errExit1}
+}
+
+func TestClearSystemRecycleBinRetriesTransientFailures(t *testing.T) {
+	fastRecycleBinRetry(t)
+	runner := &scriptedRunner{results: []scriptedResult{busy(), busy(), {output: "系统回收站已清空"}}}
+	manager := NewWindowsTrashManager()
+	manager.SetCommandRunner(runner)
+
+	if err := manager.clearSystemRecycleBin(); err != nil {
+		t.Fatalf("clearSystemRecycleBin: %v", err)
+	}
+	if len(runner.calls) != 3 {
+		t.Errorf("powershell ran %d times, want 3", len(runner.calls))
+	}
+}
+
+func TestClearSystemRecycleBinGivesUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    scriptedResult
+		wantType  errors.ErrorType
+		wantCalls int
+	}{
+		{
+			name:      "permission denied is not retried",
+			result:    scriptedResult{output: "Access is denied. (Exception from HRESULT: 0x80070005)", err: errExit1},
+			wantType:  errors.ErrTypePermissionDenied,
+			wantCalls: 1,
+		},
+		{
+			name:      "transient failures stop after the last attempt",
+			result:    busy(),
+			wantType:  errors.ErrTypeTransient,
+			wantCalls: recycleBinRetry.Attempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fastRecycleBinRetry(t)
+			runner := &scriptedRunner{}
+			for i := 0; i < 5; i++ {
+				runner.results = append(runner.results, tt.result)
+			}
+			manager := NewWindowsTrashManager()
+			manager.SetCommandRunner(runner)
+
+			err := manager.clearSystemRecycleBin()
+			if !errors.IsType(err, tt.wantType) {
+				t.Errorf("error = %v, want type %v", err, tt.wantType)
+			}
+			if len(runner.calls) != tt.wantCalls {
+				t.Errorf("powershell ran %d times, want %d", len(runner.calls), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestMoveToRecycleBinWithPowerShellRetries(t *testing.T) {
+	fastRecycleBinRetry(t)
+	path := writeContractFile(t, "busy.txt", "data")
+	moved := scriptedResult{effect: func() { os.Remove(path) }}
+	runner := &scriptedRunner{results: []scriptedResult{busy(), busy(), moved}}
+	manager := NewWindowsTrashManager()
+	manager.SetCommandRunner(runner)
+
+	if err := manager.moveToRecycleBinWithPowerShell(path); err != nil {
+		t.Fatalf("moveToRecycleBinWithPowerShell: %v", err)
+	}
+	if len(runner.calls) != 3 {
+		t.Fatalf("powershell ran %d times, want 3", len(runner.calls))
+	}
+	for _, call := range runner.calls {
+		if call[0] != "powershell" || call[len(call)-2] != "-FilePath" || call[len(call)-1] != path {
+			t.Errorf("call = %q, want the path passed as -FilePath", call)
+		}
+	}
+}
+
+func TestMoveToRecycleBinWithPowerShellDoesNotRepeatCompletedMove(t *testing.T) {
+	fastRecycleBinRetry(t)
+	path := writeContractFile(t, "moved.txt", "data")
+	// 命令报错但文件实际已进入回收站
+	movedButFailed := busy()
+	movedButFailed.effect = func() { os.Remove(path) }
+	runner := &scriptedRunner{results: []scriptedResult{movedButFailed}}
+	manager := NewWindowsTrashManager()
+	manager.SetCommandRunner(runner)
+
+	if err := manager.moveToRecycleBinWithPowerShell(path); err != nil {
+		t.Fatalf("moveToRecycleBinWithPowerShell: %v", err)
+	}
+	if len(runner.calls) != 1 {
+		t.Errorf("powershell ran %d times after the file was already moved, want 1", len(runner.calls))
+	}
+}
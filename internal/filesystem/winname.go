@@ -0,0 +1,69 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// trailingNameMessage 说明名称以点或空格结尾为什么难以处理
+const trailingNameMessage = `名称以点或空格结尾，Windows的普通API会去掉这些字符，资源管理器无法删除或重命名它；将通过\\?\扩展路径按原名处理`
+
+// HasTrailingDotOrSpace 名称是否以点或空格结尾（"."和".."除外）
+// 这样的文件通常由有缺陷的程序或经SMB访问的Linux创建
+func HasTrailingDotOrSpace(name string) bool {
+	if name == "." || name == ".." {
+		return false
+	}
+	return strings.TrimRight(name, ". ") != name
+}
+
+// trailingNameOnWindows 在Windows上path的最后一级名称是否以点或空格结尾
+func trailingNameOnWindows(path string) bool {
+	return runtime.GOOS == "windows" && HasTrailingDotOrSpace(filepath.Base(path))
+}
+
+// AbsPath 与filepath.Abs相同，但在Windows上保留最后一级名称末尾的点和空格
+// filepath.Abs经由GetFullPathName会去掉它们，得到的是另一个文件的路径
+func AbsPath(path string) (string, error) {
+	if !trailingNameOnWindows(path) {
+		return filepath.Abs(path)
+	}
+	if strings.HasPrefix(path, `\\?\`) {
+		return path, nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	return dir + string(filepath.Separator) + filepath.Base(path), nil
+}
+
+// OSPath 返回传给文件系统调用的路径：Windows上名称以点或空格结尾时使用\\?\扩展路径，
+// 系统按原样解析其中的名称；其他情况原样返回
+func OSPath(path string) string {
+	if !trailingNameOnWindows(path) || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	if abs, err := AbsPath(path); err == nil {
+		path = abs
+	}
+	if strings.HasPrefix(path, `\\`) {
+		return `\\?\UNC\` + path[2:]
+	}
+	return `\\?\` + path
+}
+
+// plainPath 去掉\\?\扩展路径前缀，用于比较卷名和显示
+func plainPath(path string) string {
+	if strings.HasPrefix(path, `\\?\UNC\`) {
+		return `\\` + path[len(`\\?\UNC\`):]
+	}
+	return strings.TrimPrefix(path, `\\?\`)
+}
+
+// Lstat 与os.Lstat相同，Windows上名称以点或空格结尾时通过扩展路径访问
+func Lstat(path string) (os.FileInfo, error) {
+	return os.Lstat(OSPath(path))
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package filesystem
+
+// listExtendedAttributes 当前平台不支持扩展属性
+func listExtendedAttributes(path string) (map[string]string, []string, error) {
+	return nil, nil, nil
+}
+
+// restoreExtendedAttributes 当前平台不支持扩展属性
+func restoreExtendedAttributes(path string, attrs map[string]string) error {
+	return nil
+}
+
+// SELinuxContext 当前平台没有SELinux
+func SELinuxContext(path string) (string, bool) {
+	return "", false
+}
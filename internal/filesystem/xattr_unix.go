@@ -0,0 +1,107 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// listExtendedAttributes 读取文件的扩展属性（包括Finder标签、资源分支等）
+// 超过大小限制的属性会被跳过，并通过skipped返回其名称
+func listExtendedAttributes(path string) (attrs map[string]string, skipped []string, err error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil || size <= 0 {
+		return nil, nil, err
+	}
+
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attrs = make(map[string]string)
+	total := 0
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if name == "" {
+			continue
+		}
+
+		value, err := getExtendedAttribute(path, name)
+		if err != nil {
+			skipped = append(skipped, name)
+			continue
+		}
+		if len(value) > maxXattrValueSize || total+len(value) > maxXattrTotalSize {
+			skipped = append(skipped, name)
+			continue
+		}
+
+		total += len(value)
+		attrs[name] = base64.StdEncoding.EncodeToString(value)
+	}
+
+	return attrs, skipped, nil
+}
+
+// getExtendedAttribute 读取单个扩展属性的值
+func getExtendedAttribute(path, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return []byte{}, nil
+	}
+	if size > maxXattrValueSize {
+		return nil, fmt.Errorf("扩展属性过大: %s", name)
+	}
+
+	value := make([]byte, size)
+	size, err = unix.Lgetxattr(path, name, value)
+	if err != nil {
+		return nil, err
+	}
+	return value[:size], nil
+}
+
+// restoreExtendedAttributes 将记录的扩展属性写回文件，值未变化的属性跳过
+func restoreExtendedAttributes(path string, attrs map[string]string) error {
+	var failed []string
+	for name, encoded := range attrs {
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			failed = append(failed, name)
+			continue
+		}
+
+		// 同一文件系统内移动时属性会被保留，避免对只读属性（如SELinux标签）重复写入
+		if current, err := getExtendedAttribute(path, name); err == nil && bytes.Equal(current, value) {
+			continue
+		}
+
+		if err := unix.Lsetxattr(path, name, value, 0); err != nil {
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("无法恢复扩展属性: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// SELinuxContext 读取文件的SELinux安全上下文（security.selinux），不跟随符号链接
+// 未启用SELinux或文件没有标签时返回false
+func SELinuxContext(path string) (string, bool) {
+	value, err := getExtendedAttribute(path, "security.selinux")
+	if err != nil || len(value) == 0 {
+		return "", false
+	}
+	return strings.TrimRight(string(value), "\x00"), true
+}
@@ -0,0 +1,71 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"encoding/base64"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// setUserXattr 写入自定义扩展属性，文件系统不支持user.*属性时跳过测试
+func setUserXattr(t *testing.T, path, name string, value []byte) {
+	t.Helper()
+	if err := unix.Lsetxattr(path, name, value, 0); err != nil {
+		t.Skipf("cannot set %s here: %v", name, err)
+	}
+}
+
+func TestUserXattrSurvivesTrashRoundTrip(t *testing.T) {
+	factory, ok := lookupBackend(runtime.GOOS)
+	if !ok {
+		t.Skipf("no backend for %s", runtime.GOOS)
+	}
+	root := t.TempDir()
+	manager, err := factory(BackendOptions{TrashDir: root, TrashRoot: root, PreserveXattrs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeContractFile(t, "tagged.txt", "tagged")
+	setUserXattr(t, path, "user.delguard.test", []byte("kept"))
+
+	if err := manager.MoveToTrash(path); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	item := onlyTrashFile(t, manager)
+	metadata := LoadMetadata(manager, item)
+	if got := metadata.Xattrs["user.delguard.test"]; got != base64.StdEncoding.EncodeToString([]byte("kept")) {
+		t.Errorf("metadata xattr = %q, want base64 of %q", got, "kept")
+	}
+	// 模拟跨文件系统复制丢失属性，恢复后的属性只能来自元数据
+	if err := unix.Lremovexattr(item.TrashPath, "user.delguard.test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.RestoreFile(item, ""); err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	value, err := getExtendedAttribute(path, "user.delguard.test")
+	if err != nil || string(value) != "kept" {
+		t.Errorf("restored xattr = %q, %v; want %q", value, err, "kept")
+	}
+}
+
+func TestRestoreExtendedAttributesReportsFailures(t *testing.T) {
+	path := writeContractFile(t, "restore.txt", "restore")
+	setUserXattr(t, path, "user.probe", []byte("probe"))
+
+	err := restoreExtendedAttributes(path, map[string]string{
+		"user.good":    base64.StdEncoding.EncodeToString([]byte("good")),
+		"user.corrupt": "not base64!",
+	})
+	if err == nil || !strings.Contains(err.Error(), "user.corrupt") || strings.Contains(err.Error(), "user.good") {
+		t.Errorf("restoreExtendedAttributes() = %v, want only user.corrupt reported", err)
+	}
+	if value, err := getExtendedAttribute(path, "user.good"); err != nil || string(value) != "good" {
+		t.Errorf("user.good = %q, %v; want it written despite the other failure", value, err)
+	}
+}
@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 支持的界面语言
+const (
+	LangZH = "zh-CN"
+	LangEN = "en-US"
+)
+
+// PluralCategory CLDR复数类别，中文和英文只需要one和other
+type PluralCategory string
+
+const (
+	// PluralOne 单数（英文中数量为1）
+	PluralOne PluralCategory = "one"
+	// PluralOther 其他数量
+	PluralOther PluralCategory = "other"
+)
+
+// Message 按复数类别区分的消息模板，One为空时使用Other
+type Message struct {
+	One   string
+	Other string
+}
+
+var (
+	langMu  sync.RWMutex
+	current = LangZH
+)
+
+// SetLanguage 设置界面语言，不支持的语言回退到中文
+func SetLanguage(lang string) {
+	langMu.Lock()
+	defer langMu.Unlock()
+	current = normalize(lang)
+}
+
+// Language 获取当前界面语言
+func Language() string {
+	langMu.RLock()
+	defer langMu.RUnlock()
+	return current
+}
+
+// normalize 将en、en_US、EN-us等写法统一为支持的语言代码
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	if lang == "en" || strings.HasPrefix(lang, "en-") {
+		return LangEN
+	}
+	return LangZH
+}
+
+// Category 返回数量在指定语言下的复数类别
+func Category(lang string, count int) PluralCategory {
+	if normalize(lang) == LangEN && (count == 1 || count == -1) {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// Plural 按当前语言和数量格式化消息，模板中第一个占位符为数量，其余依次为args
+// 当前语言缺少该消息时使用中文模板
+func Plural(key string, count int, args ...interface{}) string {
+	return PluralIn(Language(), key, count, args...)
+}
+
+// PluralIn 按指定语言和数量格式化消息
+func PluralIn(lang string, key string, count int, args ...interface{}) string {
+	lang = normalize(lang)
+	message, ok := catalog[lang][key]
+	if !ok {
+		if message, ok = catalog[LangZH][key]; !ok {
+			return fmt.Sprintf("%s: %d", key, count)
+		}
+		lang = LangZH
+	}
+
+	template := message.Other
+	if Category(lang, count) == PluralOne && message.One != "" {
+		template = message.One
+	}
+	return fmt.Sprintf(template, append([]interface{}{count}, args...)...)
+}
@@ -0,0 +1,97 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestPluralCounts(t *testing.T) {
+	tests := []struct {
+		lang  string
+		key   string
+		count int
+		args  []interface{}
+		want  string
+	}{
+		{LangZH, "delete.done", 0, nil, "成功删除 0 个项目到回收站"},
+		{LangZH, "delete.done", 1, nil, "成功删除 1 个项目到回收站"},
+		{LangZH, "delete.done", 2, nil, "成功删除 2 个项目到回收站"},
+		{LangEN, "delete.done", 0, nil, "Moved 0 items to the trash"},
+		{LangEN, "delete.done", 1, nil, "Moved 1 item to the trash"},
+		{LangEN, "delete.done", 2, nil, "Moved 2 items to the trash"},
+		{LangZH, "restore.done", 1, nil, "成功恢复 1 个文件"},
+		{LangEN, "restore.done", 0, nil, "Restored 0 files"},
+		{LangEN, "restore.done", 1, nil, "Restored 1 file"},
+		{LangEN, "restore.done", 2, nil, "Restored 2 files"},
+		{LangZH, "empty.done", 2, []interface{}{"1.0 KB"}, "成功清空回收站，删除了 2 个项目 (1.0 KB)"},
+		{LangEN, "empty.done", 0, []interface{}{"0 B"}, "Emptied the trash, deleted 0 items (0 B)"},
+		{LangEN, "empty.done", 1, []interface{}{"12 B"}, "Emptied the trash, deleted 1 item (12 B)"},
+		{LangEN, "prune.done", 2, []interface{}{"3 MB"}, "Permanently deleted 2 items past their retention period (3 MB)"},
+		{LangEN, "shred.done", 1, []interface{}{3}, "Shredded 1 item, overwrite passes: 3"},
+	}
+	for _, tt := range tests {
+		if got := PluralIn(tt.lang, tt.key, tt.count, tt.args...); got != tt.want {
+			t.Errorf("PluralIn(%s, %s, %d) = %q, want %q", tt.lang, tt.key, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		lang  string
+		count int
+		want  PluralCategory
+	}{
+		{"en", 1, PluralOne},
+		{"en_US", -1, PluralOne},
+		{"EN-gb", 0, PluralOther},
+		{LangEN, 2, PluralOther},
+		{LangZH, 1, PluralOther},
+		{"fr", 1, PluralOther},
+	}
+	for _, tt := range tests {
+		if got := Category(tt.lang, tt.count); got != tt.want {
+			t.Errorf("Category(%q, %d) = %s, want %s", tt.lang, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestPluralFallsBackToChinese(t *testing.T) {
+	catalog[LangZH]["test.only_zh"] = Message{Other: "仅有中文 %d"}
+	defer delete(catalog[LangZH], "test.only_zh")
+
+	if got := PluralIn(LangEN, "test.only_zh", 3); got != "仅有中文 3" {
+		t.Errorf("missing English message = %q", got)
+	}
+	if got := PluralIn(LangEN, "test.missing", 3); got != "test.missing: 3" {
+		t.Errorf("missing message = %q", got)
+	}
+}
+
+// verbPattern 匹配fmt占位符，%%除外
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z]`)
+
+func TestCatalogLocalesAgree(t *testing.T) {
+	for key, zh := range catalog[LangZH] {
+		en, ok := catalog[LangEN][key]
+		if !ok {
+			t.Errorf("%s has no English message", key)
+			continue
+		}
+		// 翻译可以调整语序，但必须使用同样多的参数
+		want := len(verbPattern.FindAllString(zh.Other, -1))
+		for name, template := range map[string]string{"en.one": en.One, "en.other": en.Other} {
+			if template == "" {
+				continue
+			}
+			if got := len(verbPattern.FindAllString(template, -1)); got != want {
+				t.Errorf("%s %s has %d placeholders, the Chinese message has %d", key, name, got, want)
+			}
+		}
+	}
+	for key := range catalog[LangEN] {
+		if _, ok := catalog[LangZH][key]; !ok {
+			t.Errorf("%s has no Chinese message", key)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package i18n
+
+// catalog 带数量的消息模板，按语言和消息键索引
+// 中文没有单复数之分，只需要Other；新增语言时只需补充对应的模板
+var catalog = map[string]map[string]Message{
+	LangZH: {
+		"delete.preview_shred": {Other: "预览模式 - 以下文件将被覆写 %d 遍后永久删除:"},
+		"delete.confirm":       {Other: "将要删除 %d 个项目到回收站，确认吗? [y/N]: "},
+		"delete.batch":         {Other: "正在批量处理 %d 个文件..."},
+		"delete.done":          {Other: "成功删除 %d 个项目到回收站"},
+		"shred.confirm":        {Other: "将要覆写 %[2]d 遍并永久删除 %[1]d 个项目，此操作无法恢复！确认吗? [y/N]: "},
+		"shred.confirm_word":   {Other: "将要覆写 %[2]d 遍并永久删除 %[1]d 个项目，此操作无法恢复！输入 DELETE 确认: "},
+		"shred.file":           {Other: "已粉碎: %[2]s (覆写 %[1]d 遍)"},
+		"shred.done":           {Other: "成功粉碎 %d 个项目，覆写遍数: %d"},
+		"purge.confirm":        {Other: "将要永久删除 %d 个项目，不经过回收站，此操作无法恢复！输入 DELETE 确认: "},
+		"purge.done":           {Other: "成功永久删除 %d 个项目"},
+		"git.uncommitted":      {Other: "要删除的 %[2]d 个项目中有 %[1]d 个在仓库 %[3]s 中有未提交的修改"},
+		"remote.purge":         {Other: "%d 个项目位于远程文件系统，按 security.remote_filesystems 配置将永久删除，不复制到本地回收站"},
+		"restore.confirm":      {Other: "将要恢复 %d 个文件，确认吗? [y/N]: "},
+		"restore.batch":        {Other: "正在批量恢复 %d 个文件..."},
+		"restore.done":         {Other: "成功恢复 %d 个文件"},
+		"restore.failed":       {Other: "%d 个文件恢复失败"},
+		"empty.preview":        {Other: "预览模式 - 将要永久删除 %d 个项目:"},
+		"empty.warning":        {Other: "警告: 即将永久删除回收站中的 %d 个项目"},
+		"empty.done":           {Other: "成功清空回收站，删除了 %d 个项目 (%s)"},
+		"list.total":           {Other: "总计: %d 个项目"},
+		"prune.preview":        {Other: "预览模式 - 以下 %d 个项目 (%s) 将被永久删除:"},
+		"prune.confirm":        {Other: "以下 %d 个项目 (%s) 将被永久删除:"},
+		"prune.done":           {Other: "已永久删除 %d 个超过保留期限的项目 (%s)"},
+		"prune.done_bin":       {Other: "已清理 %d 个超过保留期限的项目 (%s)：%d 个移入系统回收站，%d 个永久删除"},
+		"prune.verified":       {Other: "永久删除前重新校验了 %d 个项目：%d 个与记录的哈希一致，%d 个内容已损坏并移入隔离目录"},
+		"verify.checked":       {Other: "已检查 %d 个回收站项目"},
+		"verify.orphan_meta":   {Other: "%d 个元数据没有对应的文件:"},
+		"verify.orphan_files":  {Other: "%d 个文件没有元数据:"},
+		"verify.removed":       {Other: "已删除 %d 个过期元数据"},
+		"verify.created":       {Other: "已补建 %d 个元数据"},
+		"verify.repair_errors": {Other: "修复过程中有 %d 个错误"},
+		"verify.problems":      {Other: "发现 %d 个问题，使用 --repair 修复"},
+		"verify.corrupt":       {Other: "%d 个项目的内容与记录的哈希不一致"},
+		"archive.exported":     {Other: "已导出 %d 个项目到 %s"},
+		"archive.imported":     {Other: "已导入 %d 个项目到回收站"},
+		"pin.done":             {Other: "已固定 %d 个项目，自动清理和清空回收站时将跳过"},
+		"unpin.done":           {Other: "已取消固定 %d 个项目"},
+		"pin.kept":             {Other: "保留了 %d 个已固定的项目，使用 --include-pinned 一并删除"},
+		"preview.conflicts":    {Other: "%d 个冲突"},
+		"du.header":            {Other: "回收站占用 (共 %[2]s，来自 %[1]d 个目录):"},
+		"du.more":              {Other: "... 还有 %d 个目录，使用 --top 0 显示全部"},
+		"plugins.count":        {Other: "共 %d 个插件:"},
+		"errors.summary":       {Other: "%d 个项目失败 (%s)"},
+		"errors.more":          {Other: "... 另有 %d 个"},
+		"config.errors_found":  {Other: "发现 %d 个错误"},
+		"count.items_more":     {Other: "... 还有 %d 个项目"},
+		"count.files":          {Other: "文件: %d个"},
+		"count.dirs":           {Other: "目录: %d个"},
+		"count.errors":         {Other: "%d 个错误"},
+		"count.warnings":       {Other: "%d 个警告"},
+		"count.hints":          {Other: "%d 个提示"},
+		"plan.issues":          {Other: "%d 个项目需要注意:"},
+		"doctor.failed":        {Other: "%d 项检查未通过"},
+		"rotate.done":          {Other: "回收站已轮转，永久删除了 %d 个最旧的项目 (%s，策略: %s)"},
+	},
+	LangEN: {
+		"delete.preview_shred": {One: "Preview - the following files will be overwritten %d time and permanently deleted:", Other: "Preview - the following files will be overwritten %d times and permanently deleted:"},
+		"delete.confirm":       {One: "Move %d item to the trash? [y/N]: ", Other: "Move %d items to the trash? [y/N]: "},
+		"delete.batch":         {One: "Processing %d file...", Other: "Processing %d files..."},
+		"delete.done":          {One: "Moved %d item to the trash", Other: "Moved %d items to the trash"},
+		"shred.confirm":        {One: "Permanently delete %[1]d item after %[2]d overwrite passes? This cannot be undone! [y/N]: ", Other: "Permanently delete %[1]d items after %[2]d overwrite passes? This cannot be undone! [y/N]: "},
+		"shred.confirm_word":   {One: "Permanently delete %[1]d item after %[2]d overwrite passes? This cannot be undone! Type DELETE to confirm: ", Other: "Permanently delete %[1]d items after %[2]d overwrite passes? This cannot be undone! Type DELETE to confirm: "},
+		"shred.file":           {One: "Shredded: %[2]s (%[1]d pass)", Other: "Shredded: %[2]s (%[1]d passes)"},
+		"shred.done":           {One: "Shredded %d item, overwrite passes: %d", Other: "Shredded %d items, overwrite passes: %d"},
+		"purge.confirm":        {One: "Permanently delete %d item without using the trash? This cannot be undone! Type DELETE to confirm: ", Other: "Permanently delete %d items without using the trash? This cannot be undone! Type DELETE to confirm: "},
+		"purge.done":           {One: "Permanently deleted %d item", Other: "Permanently deleted %d items"},
+		"git.uncommitted":      {One: "%d of these %d items has uncommitted changes in repo %s", Other: "%d of these %d items have uncommitted changes in repo %s"},
+		"remote.purge":         {One: "%d item is on a remote filesystem and will be deleted permanently instead of copied to the local trash (security.remote_filesystems)", Other: "%d items are on a remote filesystem and will be deleted permanently instead of copied to the local trash (security.remote_filesystems)"},
+		"restore.confirm":      {One: "Restore %d file? [y/N]: ", Other: "Restore %d files? [y/N]: "},
+		"restore.batch":        {One: "Restoring %d file...", Other: "Restoring %d files..."},
+		"restore.done":         {One: "Restored %d file", Other: "Restored %d files"},
+		"restore.failed":       {One: "Failed to restore %d file", Other: "Failed to restore %d files"},
+		"empty.preview":        {One: "Preview - %d item will be permanently deleted:", Other: "Preview - %d items will be permanently deleted:"},
+		"empty.warning":        {One: "Warning: about to permanently delete %d item from the trash", Other: "Warning: about to permanently delete %d items from the trash"},
+		"empty.done":           {One: "Emptied the trash, deleted %d item (%s)", Other: "Emptied the trash, deleted %d items (%s)"},
+		"list.total":           {One: "Total: %d item", Other: "Total: %d items"},
+		"prune.preview":        {One: "Preview - the following %d item (%s) will be permanently deleted:", Other: "Preview - the following %d items (%s) will be permanently deleted:"},
+		"prune.confirm":        {One: "The following %d item (%s) will be permanently deleted:", Other: "The following %d items (%s) will be permanently deleted:"},
+		"prune.done":           {One: "Permanently deleted %d item past its retention period (%s)", Other: "Permanently deleted %d items past their retention period (%s)"},
+		"prune.done_bin":       {One: "Pruned %d item past its retention period (%s): %d moved to the system bin, %d permanently deleted", Other: "Pruned %d items past their retention period (%s): %d moved to the system bin, %d permanently deleted"},
+		"prune.verified":       {One: "Re-verified %d item before permanent deletion: %d matched the recorded hash, %d corrupt and quarantined", Other: "Re-verified %d items before permanent deletion: %d matched the recorded hash, %d corrupt and quarantined"},
+		"verify.checked":       {One: "Checked %d trash item", Other: "Checked %d trash items"},
+		"verify.orphan_meta":   {One: "%d metadata file has no matching trash file:", Other: "%d metadata files have no matching trash file:"},
+		"verify.orphan_files":  {One: "%d file has no metadata:", Other: "%d files have no metadata:"},
+		"verify.removed":       {One: "Removed %d stale metadata file", Other: "Removed %d stale metadata files"},
+		"verify.created":       {One: "Recreated metadata for %d file", Other: "Recreated metadata for %d files"},
+		"verify.repair_errors": {One: "%d error occurred during repair", Other: "%d errors occurred during repair"},
+		"verify.problems":      {One: "Found %d problem, run with --repair to fix it", Other: "Found %d problems, run with --repair to fix them"},
+		"verify.corrupt":       {One: "%d item no longer matches its recorded hash", Other: "%d items no longer match their recorded hashes"},
+		"archive.exported":     {One: "Exported %d item to %s", Other: "Exported %d items to %s"},
+		"archive.imported":     {One: "Imported %d item into the trash", Other: "Imported %d items into the trash"},
+		"pin.done":             {One: "Pinned %d item, it will be kept by automatic cleanup and when emptying the trash", Other: "Pinned %d items, they will be kept by automatic cleanup and when emptying the trash"},
+		"unpin.done":           {One: "Unpinned %d item", Other: "Unpinned %d items"},
+		"pin.kept":             {One: "Kept %d pinned item, use --include-pinned to delete it too", Other: "Kept %d pinned items, use --include-pinned to delete them too"},
+		"preview.conflicts":    {One: "%d conflict", Other: "%d conflicts"},
+		"du.header":            {One: "Trash usage (%[2]s total, from %[1]d directory):", Other: "Trash usage (%[2]s total, from %[1]d directories):"},
+		"du.more":              {One: "... and %d more directory, use --top 0 to show all", Other: "... and %d more directories, use --top 0 to show all"},
+		"plugins.count":        {One: "%d plugin:", Other: "%d plugins:"},
+		"errors.summary":       {One: "%d item failed (%s)", Other: "%d items failed (%s)"},
+		"errors.more":          {Other: "... and %d more"},
+		"config.errors_found":  {One: "Found %d error", Other: "Found %d errors"},
+		"count.items_more":     {One: "... and %d more item", Other: "... and %d more items"},
+		"count.files":          {One: "%d file", Other: "%d files"},
+		"count.dirs":           {One: "%d directory", Other: "%d directories"},
+		"count.errors":         {One: "%d error", Other: "%d errors"},
+		"count.warnings":       {One: "%d warning", Other: "%d warnings"},
+		"count.hints":          {One: "%d hint", Other: "%d hints"},
+		"plan.issues":          {One: "%d item needs attention:", Other: "%d items need attention:"},
+		"doctor.failed":        {One: "%d check failed", Other: "%d checks failed"},
+		"rotate.done":          {One: "Rotated the trash, permanently deleted %d oldest item (%s, policy: %s)", Other: "Rotated the trash, permanently deleted %d oldest items (%s, policy: %s)"},
+	},
+}
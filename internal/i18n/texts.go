@@ -0,0 +1,172 @@
+package i18n
+
+import "fmt"
+
+// texts 不带数量的消息模板，按语言和消息键索引，图标由调用方添加
+var texts = map[string]map[string]string{
+	LangZH: {
+		"common.cancelled":    "操作已取消",
+		"common.input_failed": "读取输入失败，操作已取消",
+		"common.trash_empty":  "回收站是空的",
+
+		"delete.moved":          "已移动到回收站: %s",
+		"delete.moved_to":       "已移动到回收站: %s -> %s",
+		"delete.failed":         "删除失败 '%s': %v",
+		"delete.prompt":         "删除 '%s'? [y/N]: ",
+		"delete.input_skip":     "读取输入失败，跳过此文件",
+		"delete.skipped":        "跳过: %s",
+		"restore.restored":      "已恢复: %s",
+		"restore.restored_to":   "已恢复: %s -> %s",
+		"restore.failed":        "恢复失败 '%s': %v",
+		"restore.prompt":        "恢复 '%s' 到 '%s'? [y/N]: ",
+		"restore.skipped":       "跳过: %s",
+		"restore.skipped_input": "跳过: %s (输入错误)",
+		"restore.renamed":       "文件已存在，重命名为: %s",
+		"restore.case_conflict": "%s 与已存在的 %s 只有大小写不同，目标文件系统不区分大小写，按同名文件处理",
+
+		"install.title":         "DelGuard 安装程序",
+		"install.os":            "操作系统: %s %s",
+		"install.already":       "DelGuard已经安装",
+		"install.path":          "安装路径: %s",
+		"install.use_force":     "如需重新安装，请使用 --force 参数",
+		"install.config":        "安装配置:",
+		"install.type":          "  安装类型: %s",
+		"install.install_path":  "  安装路径: %s",
+		"install.backup_path":   "  备份路径: %s",
+		"install.commands":      "  目标命令: %v",
+		"install.type_system":   "系统级安装",
+		"install.type_user":     "用户级安装",
+		"install.confirm":       "是否继续安装？ (y/N): ",
+		"install.cancelled":     "安装已取消",
+		"install.start":         "开始安装...",
+		"install.done":          "安装完成！",
+		"install.usage":         "使用说明:",
+		"install.usage_intro":   "  现在您可以使用以下命令安全删除文件:",
+		"install.usage_del":     "    del file.txt        # 删除文件到回收站",
+		"install.usage_rmdir":   "    rmdir folder        # 删除目录到回收站",
+		"install.usage_rm":      "    rm file.txt         # 删除文件到回收站",
+		"install.usage_rm_r":    "    rm -r folder        # 删除目录到回收站",
+		"install.usage_list":    "    delguard list       # 查看回收站文件",
+		"install.usage_restore": "    delguard restore    # 恢复文件",
+		"install.notes":         "重要提示:",
+		"install.note_reload":   "  - 请重新启动终端或重新加载配置文件",
+		"install.note_no_trash": "  - 如需永久删除文件，请使用: delguard delete --no-trash",
+		"install.note_remove":   "  - 如需卸载，请使用: delguard uninstall",
+		"install.note_ps":       "  - PowerShell用户请重新启动PowerShell",
+		"install.note_profile":  "  - 或运行: . $PROFILE 重新加载配置",
+
+		"uninstall.title":           "DelGuard 卸载程序",
+		"uninstall.not_installed":   "DelGuard未安装或已被卸载",
+		"uninstall.info":            "卸载信息:",
+		"uninstall.keep_config":     "  配置文件: 将保留",
+		"uninstall.remove_config":   "  配置文件: 将删除",
+		"uninstall.warning":         "警告:",
+		"uninstall.warn_default":    "  卸载后，删除命令将恢复为系统默认行为（永久删除）",
+		"uninstall.warn_understand": "  请确保您了解这一变化的影响",
+		"uninstall.confirm":         "是否继续卸载？ (y/N): ",
+		"uninstall.cancelled":       "卸载已取消",
+		"uninstall.start":           "开始卸载...",
+		"uninstall.done":            "卸载完成！",
+		"uninstall.notes":           "重要提示:",
+		"uninstall.note_windows":    "  - 删除命令已恢复为Windows默认行为",
+		"uninstall.note_del":        "  - del、rmdir命令现在将永久删除文件",
+		"uninstall.note_ps":         "  - 请重新启动PowerShell以完全清除别名",
+		"uninstall.note_unix":       "  - 删除命令已恢复为系统默认行为",
+		"uninstall.note_rm":         "  - rm命令现在将永久删除文件",
+		"uninstall.note_reload":     "  - 请重新启动终端或重新加载shell配置",
+		"uninstall.note_reinstall":  "  - 如需重新安装，请使用: delguard install",
+		"uninstall.thanks":          "感谢使用DelGuard！",
+	},
+	LangEN: {
+		"common.cancelled":    "Operation cancelled",
+		"common.input_failed": "Failed to read input, operation cancelled",
+		"common.trash_empty":  "The trash is empty",
+
+		"delete.moved":          "Moved to the trash: %s",
+		"delete.moved_to":       "Moved to the trash: %s -> %s",
+		"delete.failed":         "Failed to delete '%s': %v",
+		"delete.prompt":         "Delete '%s'? [y/N]: ",
+		"delete.input_skip":     "Failed to read input, skipping this file",
+		"delete.skipped":        "Skipped: %s",
+		"restore.restored":      "Restored: %s",
+		"restore.restored_to":   "Restored: %s -> %s",
+		"restore.failed":        "Failed to restore '%s': %v",
+		"restore.prompt":        "Restore '%s' to '%s'? [y/N]: ",
+		"restore.skipped":       "Skipped: %s",
+		"restore.skipped_input": "Skipped: %s (invalid input)",
+		"restore.renamed":       "File already exists, renamed to: %s",
+		"restore.case_conflict": "%s differs from the existing %s only in case; the target filesystem is case-insensitive, treating them as the same file",
+
+		"install.title":         "DelGuard installer",
+		"install.os":            "Operating system: %s %s",
+		"install.already":       "DelGuard is already installed",
+		"install.path":          "Install path: %s",
+		"install.use_force":     "Use --force to reinstall",
+		"install.config":        "Install settings:",
+		"install.type":          "  Install type: %s",
+		"install.install_path":  "  Install path: %s",
+		"install.backup_path":   "  Backup path: %s",
+		"install.commands":      "  Commands: %v",
+		"install.type_system":   "system-wide",
+		"install.type_user":     "current user",
+		"install.confirm":       "Continue installing? (y/N): ",
+		"install.cancelled":     "Installation cancelled",
+		"install.start":         "Installing...",
+		"install.done":          "Installation complete!",
+		"install.usage":         "Usage:",
+		"install.usage_intro":   "  You can now delete files safely with:",
+		"install.usage_del":     "    del file.txt        # move a file to the trash",
+		"install.usage_rmdir":   "    rmdir folder        # move a directory to the trash",
+		"install.usage_rm":      "    rm file.txt         # move a file to the trash",
+		"install.usage_rm_r":    "    rm -r folder        # move a directory to the trash",
+		"install.usage_list":    "    delguard list       # list the trash",
+		"install.usage_restore": "    delguard restore    # restore files",
+		"install.notes":         "Notes:",
+		"install.note_reload":   "  - Restart your terminal or reload your shell configuration",
+		"install.note_no_trash": "  - To delete files permanently, use: delguard delete --no-trash",
+		"install.note_remove":   "  - To uninstall, use: delguard uninstall",
+		"install.note_ps":       "  - PowerShell users should restart PowerShell",
+		"install.note_profile":  "  - or run: . $PROFILE to reload the profile",
+
+		"uninstall.title":           "DelGuard uninstaller",
+		"uninstall.not_installed":   "DelGuard is not installed or was already removed",
+		"uninstall.info":            "Uninstall details:",
+		"uninstall.keep_config":     "  Configuration: kept",
+		"uninstall.remove_config":   "  Configuration: removed",
+		"uninstall.warning":         "Warning:",
+		"uninstall.warn_default":    "  After uninstalling, delete commands go back to the system default (permanent deletion)",
+		"uninstall.warn_understand": "  Make sure you understand what this changes",
+		"uninstall.confirm":         "Continue uninstalling? (y/N): ",
+		"uninstall.cancelled":       "Uninstall cancelled",
+		"uninstall.start":           "Uninstalling...",
+		"uninstall.done":            "Uninstall complete!",
+		"uninstall.notes":           "Notes:",
+		"uninstall.note_windows":    "  - Delete commands are back to the Windows defaults",
+		"uninstall.note_del":        "  - del and rmdir now delete files permanently",
+		"uninstall.note_ps":         "  - Restart PowerShell to clear the aliases completely",
+		"uninstall.note_unix":       "  - Delete commands are back to the system defaults",
+		"uninstall.note_rm":         "  - rm now deletes files permanently",
+		"uninstall.note_reload":     "  - Restart your terminal or reload your shell configuration",
+		"uninstall.note_reinstall":  "  - To reinstall, use: delguard install",
+		"uninstall.thanks":          "Thanks for using DelGuard!",
+	},
+}
+
+// T 按当前语言格式化不带数量的消息，当前语言缺少该消息时使用中文模板
+func T(key string, args ...interface{}) string {
+	return TIn(Language(), key, args...)
+}
+
+// TIn 按指定语言格式化不带数量的消息，两种语言都没有该消息时返回消息键
+func TIn(lang string, key string, args ...interface{}) string {
+	template, ok := texts[normalize(lang)][key]
+	if !ok {
+		if template, ok = texts[LangZH][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
@@ -0,0 +1,54 @@
+package i18n
+
+import "testing"
+
+func TestTInSwitchesLanguage(t *testing.T) {
+	tests := []struct {
+		lang string
+		key  string
+		args []interface{}
+		want string
+	}{
+		{LangZH, "delete.moved", []interface{}{"a.txt"}, "已移动到回收站: a.txt"},
+		{LangEN, "delete.moved", []interface{}{"a.txt"}, "Moved to the trash: a.txt"},
+		{"en", "uninstall.done", nil, "Uninstall complete!"},
+		{"fr", "common.cancelled", nil, "操作已取消"},
+		{LangEN, "test.missing", nil, "test.missing"},
+	}
+	for _, tt := range tests {
+		if got := TIn(tt.lang, tt.key, tt.args...); got != tt.want {
+			t.Errorf("TIn(%s, %s) = %q, want %q", tt.lang, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestTFollowsSetLanguage(t *testing.T) {
+	saved := Language()
+	defer SetLanguage(saved)
+
+	SetLanguage("zh")
+	zh := T("common.trash_empty")
+	SetLanguage("en_US")
+	if en := T("common.trash_empty"); en == zh || en != TIn(LangEN, "common.trash_empty") {
+		t.Errorf("T after SetLanguage(en_US) = %q, Chinese was %q", en, zh)
+	}
+}
+
+func TestTextLocalesAgree(t *testing.T) {
+	for key, zh := range texts[LangZH] {
+		en, ok := texts[LangEN][key]
+		if !ok {
+			t.Errorf("%s has no English text", key)
+			continue
+		}
+		want := len(verbPattern.FindAllString(zh, -1))
+		if got := len(verbPattern.FindAllString(en, -1)); got != want {
+			t.Errorf("%s English text has %d placeholders, the Chinese text has %d", key, got, want)
+		}
+	}
+	for key := range texts[LangEN] {
+		if _, ok := texts[LangZH][key]; !ok {
+			t.Errorf("%s has no Chinese text", key)
+		}
+	}
+}
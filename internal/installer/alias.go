@@ -0,0 +1,264 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AliasStatus 一处别名安装位置（shell配置文件或别名脚本）的状态
+type AliasStatus struct {
+	// Shell 读取该文件的程序: bash、zsh、pwsh、powershell，别名脚本为script
+	Shell string `json:"shell"`
+	// Path 配置文件或别名脚本的路径
+	Path string `json:"path"`
+	// Installed 配置文件中存在DelGuard配置块，别名脚本总是为true
+	Installed bool `json:"installed"`
+	// Target 配置块或脚本调用的DelGuard可执行文件路径
+	Target string `json:"target,omitempty"`
+	// TargetExists Target是否存在
+	TargetExists bool `json:"target_exists"`
+	// Current Target是否就是当前运行的可执行文件
+	Current bool `json:"current"`
+	// Problem 配置块缺少结束标记、重复或找不到DelGuard路径
+	Problem string `json:"problem,omitempty"`
+	// ParseError PowerShell解析配置文件时的第一条错误，仅用于PowerShell
+	ParseError string `json:"parse_error,omitempty"`
+}
+
+// Broken 已安装的别名无法正常工作
+func (s AliasStatus) Broken() bool {
+	return s.Installed && (s.Problem != "" || s.ParseError != "" || !s.TargetExists)
+}
+
+// Stale 配置块完整，只是指向的可执行文件已不存在，可以由RepairAliases重新指向
+func (s AliasStatus) Stale() bool {
+	return s.Installed && s.Problem == "" && s.Target != "" && !s.TargetExists
+}
+
+// AliasRepair 一处别名的修复结果
+type AliasRepair struct {
+	Path  string `json:"path"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Error string `json:"error,omitempty"` // 为空表示已修复
+}
+
+// shellTargetPattern 匹配shell配置块中加入PATH的安装目录
+var shellTargetPattern = regexp.MustCompile(`export PATH="([^"]+):\$PATH"`)
+
+// powerShellTargetPattern 匹配PowerShell配置块中以单引号字符串调用的DelGuard路径
+var powerShellTargetPattern = regexp.MustCompile(`&\s+'((?:[^']|'')+)'\s+delete\b`)
+
+// unixShellProfiles 各shell读取的配置文件，第一个为安装器优先写入的文件
+var unixShellProfiles = []struct {
+	Shell string
+	Files []string
+}{
+	{"bash", []string{".bashrc", ".bash_profile", ".profile"}},
+	{"zsh", []string{".zshrc", ".zprofile"}},
+}
+
+// currentExecutable 返回当前运行的可执行文件路径，无法获取时返回空字符串
+func currentExecutable() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return exe
+}
+
+// inspectTarget 记录target是否存在以及是否为当前运行的可执行文件
+func (s *AliasStatus) inspectTarget(target, current string) {
+	s.Target = target
+	if target == "" {
+		if s.Installed && s.Problem == "" {
+			s.Problem = "找不到DelGuard可执行文件路径"
+		}
+		return
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return
+	}
+	s.TargetExists = true
+	if current == "" {
+		return
+	}
+	if currentInfo, err := os.Stat(current); err == nil {
+		s.Current = os.SameFile(info, currentInfo)
+	}
+}
+
+// blockRange 返回第一个完整DelGuard配置块的起止行号（含标记行）
+func (b profileBlock) blockRange(lines []string) (start, end int, ok bool) {
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		last := profileEndMarker
+		switch {
+		case line == b.Start:
+		case line == legacyStartMarker && b.LegacyEnd != "":
+			last = b.LegacyEnd
+		default:
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == last {
+				return i, j, true
+			}
+		}
+		return 0, 0, false
+	}
+	return 0, 0, false
+}
+
+// body 返回第一个完整配置块标记之间的内容
+func (b profileBlock) body(content string) string {
+	lines := strings.Split(content, "\n")
+	start, end, ok := b.blockRange(lines)
+	if !ok {
+		return ""
+	}
+	return strings.Join(lines[start+1:end], "\n")
+}
+
+// replaceInBlock 只在第一个完整配置块内把old替换为new，配置块外的内容保持不变
+func (b profileBlock) replaceInBlock(content, old, new string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	start, end, ok := b.blockRange(lines)
+	if !ok {
+		return content, false
+	}
+	changed := false
+	for i := start + 1; i < end; i++ {
+		if replaced := strings.ReplaceAll(lines[i], old, new); replaced != lines[i] {
+			lines[i] = replaced
+			changed = true
+		}
+	}
+	return strings.Join(lines, "\n"), changed
+}
+
+// scriptAliasStatus 检查存在的别名脚本
+func scriptAliasStatus(installPath string, names []string, current string) []AliasStatus {
+	var statuses []AliasStatus
+	for _, name := range names {
+		script := filepath.Join(installPath, name)
+		if _, err := os.Stat(script); err != nil {
+			continue
+		}
+		status := AliasStatus{Shell: "script", Path: script, Installed: true}
+		status.inspectTarget(readAliasTarget(script), current)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// unixAliasStatus 检查Linux/macOS上的rm、rmdir别名脚本，以及已安装的shell读取的配置文件
+// 每个shell列出包含DelGuard配置块的配置文件，都不包含时列出其首选配置文件
+func unixAliasStatus(installPath string) []AliasStatus {
+	current := currentExecutable()
+	statuses := scriptAliasStatus(installPath, []string{"rm", "rmdir"}, current)
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return statuses
+	}
+	for _, shell := range unixShellProfiles {
+		var found []AliasStatus
+		for _, name := range shell.Files {
+			file := filepath.Join(homeDir, name)
+			content, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			problem, installed := shellProfile.check(string(content))
+			if !installed {
+				continue
+			}
+			status := AliasStatus{Shell: shell.Shell, Path: file, Installed: true, Problem: problem}
+			var target string
+			if match := shellTargetPattern.FindStringSubmatch(shellProfile.body(string(content))); match != nil {
+				target = filepath.Join(match[1], "delguard")
+			}
+			status.inspectTarget(target, current)
+			found = append(found, status)
+		}
+		if len(found) == 0 {
+			if _, err := exec.LookPath(shell.Shell); err != nil {
+				continue
+			}
+			found = append(found, AliasStatus{Shell: shell.Shell, Path: filepath.Join(homeDir, shell.Files[0])})
+		}
+		statuses = append(statuses, found...)
+	}
+	return statuses
+}
+
+// repairAliases 将statuses中指向已不存在的可执行文件的别名重新指向target
+// rewrite在文件内容中替换路径，返回替换后的内容，无法替换时返回错误
+func repairAliases(statuses []AliasStatus, target string, rewrite func(status AliasStatus, content string) (string, error)) []AliasRepair {
+	var repairs []AliasRepair
+	for _, status := range statuses {
+		if !status.Stale() {
+			continue
+		}
+		repair := AliasRepair{Path: status.Path, From: status.Target, To: target}
+		if err := rewriteFile(status.Path, func(content string) (string, error) {
+			return rewrite(status, content)
+		}); err != nil {
+			repair.Error = err.Error()
+		}
+		repairs = append(repairs, repair)
+	}
+	return repairs
+}
+
+// rewriteFile 读取文件并写回rewrite修改后的内容，保留原有权限
+func rewriteFile(path string, rewrite func(content string) (string, error)) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	updated, err := rewrite(string(content))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(updated), info.Mode().Perm())
+}
+
+// rewriteScriptTarget 替换别名脚本中调用的DelGuard路径
+func rewriteScriptTarget(content, from, to string) (string, error) {
+	old := `"` + from + `" delete`
+	if !strings.Contains(content, old) {
+		return "", fmt.Errorf("脚本中找不到 %s", from)
+	}
+	return strings.ReplaceAll(content, old, `"`+to+`" delete`), nil
+}
+
+// repairUnixAliases 把Linux/macOS上失效的别名脚本和shell配置块重新指向target
+// shell配置块通过PATH调用delguard，只能指向名为delguard的可执行文件所在的目录
+func repairUnixAliases(installPath, target string) []AliasRepair {
+	return repairAliases(unixAliasStatus(installPath), target, func(status AliasStatus, content string) (string, error) {
+		if status.Shell == "script" {
+			return rewriteScriptTarget(content, status.Target, target)
+		}
+		if filepath.Base(target) != "delguard" {
+			return "", fmt.Errorf("shell配置块通过PATH调用delguard，%s 的文件名不是delguard", target)
+		}
+		from := fmt.Sprintf(`export PATH="%s:`, filepath.Dir(status.Target))
+		to := fmt.Sprintf(`export PATH="%s:`, filepath.Dir(target))
+		updated, changed := shellProfile.replaceInBlock(content, from, to)
+		if !changed {
+			return "", fmt.Errorf("配置块中找不到 %s", filepath.Dir(status.Target))
+		}
+		return updated, nil
+	})
+}
@@ -0,0 +1,86 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Diagnosis 安装状态的诊断结果，供doctor命令使用
+type Diagnosis struct {
+	// AliasScript 找到的别名脚本路径，未安装别名时为空
+	AliasScript string `json:"alias_script,omitempty"`
+	// AliasTarget 别名脚本调用的DelGuard可执行文件路径
+	AliasTarget string `json:"alias_target,omitempty"`
+	// Profiles 包含DelGuard配置块的shell配置文件
+	Profiles []ProfileCheck `json:"profiles"`
+}
+
+// ProfileCheck 单个shell配置文件的检查结果
+type ProfileCheck struct {
+	Path    string `json:"path"`
+	Problem string `json:"problem,omitempty"` // 为空表示未发现问题
+}
+
+// aliasTargetPattern 匹配别名脚本中被调用的DelGuard路径
+var aliasTargetPattern = regexp.MustCompile(`"([^"]+)"\s+delete\b`)
+
+// readAliasTarget 读取别名脚本，返回其中调用的DelGuard路径，脚本不存在时返回空字符串
+func readAliasTarget(script string) string {
+	content, err := os.ReadFile(script)
+	if err != nil {
+		return ""
+	}
+	if match := aliasTargetPattern.FindStringSubmatch(string(content)); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// unixProfileFiles Linux/macOS上可能被安装器写入配置块的shell配置文件
+func unixProfileFiles(homeDir string) []string {
+	return []string{
+		filepath.Join(homeDir, ".zshrc"),
+		filepath.Join(homeDir, ".zprofile"),
+		filepath.Join(homeDir, ".bashrc"),
+		filepath.Join(homeDir, ".bash_profile"),
+		filepath.Join(homeDir, ".profile"),
+	}
+}
+
+// diagnoseUnix 检查Linux/macOS上的rm别名脚本和shell配置文件
+func diagnoseUnix(installPath string) Diagnosis {
+	diagnosis := Diagnosis{Profiles: []ProfileCheck{}}
+
+	script := filepath.Join(installPath, "rm")
+	if target := readAliasTarget(script); target != "" {
+		diagnosis.AliasScript = script
+		diagnosis.AliasTarget = target
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return diagnosis
+	}
+	for _, file := range unixProfileFiles(homeDir) {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if problem, found := shellProfile.check(string(content)); found {
+			diagnosis.Profiles = append(diagnosis.Profiles, ProfileCheck{Path: file, Problem: problem})
+		}
+	}
+	return diagnosis
+}
+
+// firstLine 返回文本的第一个非空行
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -26,6 +26,15 @@ type SystemInstaller interface {
 
 	// RestoreOriginalCommands 恢复原始命令
 	RestoreOriginalCommands() error
+
+	// Diagnose 检查别名脚本和shell配置文件的安装状态
+	Diagnose() Diagnosis
+
+	// Aliases 检查各shell的配置文件和别名脚本中DelGuard别名的状态
+	Aliases() []AliasStatus
+
+	// RepairAliases 将指向已不存在的可执行文件的别名重新指向target
+	RepairAliases(target string) []AliasRepair
 }
 
 // GetSystemInstaller 根据操作系统获取对应的安装器
@@ -49,6 +58,7 @@ type InstallConfig struct {
 	CreateAlias  bool   // 是否创建别名
 	SystemWide   bool   // 是否系统级安装
 	ForceInstall bool   // 是否强制安装
+	AllProfiles  bool   // 是否写入所有PowerShell配置文件，仅用于Windows
 }
 
 // GetDefaultInstallConfig 获取默认安装配置
@@ -104,6 +104,21 @@ func (l *LinuxInstaller) IsInstalled() bool {
 	return err == nil
 }
 
+// Diagnose 检查rm别名脚本和shell配置文件中的DelGuard配置块
+func (l *LinuxInstaller) Diagnose() Diagnosis {
+	return diagnoseUnix(l.config.InstallPath)
+}
+
+// Aliases 检查rm、rmdir别名脚本和bash、zsh配置文件中的DelGuard配置块
+func (l *LinuxInstaller) Aliases() []AliasStatus {
+	return unixAliasStatus(l.config.InstallPath)
+}
+
+// RepairAliases 将失效的别名脚本和shell配置块重新指向target
+func (l *LinuxInstaller) RepairAliases(target string) []AliasRepair {
+	return repairUnixAliases(l.config.InstallPath, target)
+}
+
 // GetInstallPath 获取安装路径
 func (l *LinuxInstaller) GetInstallPath() string {
 	return l.config.InstallPath
@@ -183,9 +198,7 @@ func (l *LinuxInstaller) updateShellConfig() error {
 	}
 
 	// DelGuard配置内容
-	configContent := fmt.Sprintf(`
-# DelGuard Safe Delete Tool Configuration
-# Auto-generated by DelGuard installer
+	configContent := fmt.Sprintf(`# Auto-generated by DelGuard installer
 
 # Add DelGuard to PATH
 export PATH="%s:$PATH"
@@ -218,15 +231,7 @@ func (l *LinuxInstaller) removeShellConfig() error {
 		return err
 	}
 
-	configFiles := []string{
-		filepath.Join(homeDir, ".zshrc"),
-		filepath.Join(homeDir, ".zprofile"),
-		filepath.Join(homeDir, ".bashrc"),
-		filepath.Join(homeDir, ".bash_profile"),
-		filepath.Join(homeDir, ".profile"),
-	}
-
-	for _, configFile := range configFiles {
+	for _, configFile := range unixProfileFiles(homeDir) {
 		if err := l.removeConfigFromFile(configFile); err != nil {
 			log.Printf("移除配置失败: %v", err)
 		}
@@ -243,31 +248,8 @@ func (l *LinuxInstaller) updateConfigFile(configFile, configContent string) erro
 		existingContent = string(content)
 	}
 
-	// 检查是否已经包含DelGuard配置
-	if strings.Contains(existingContent, "DelGuard Safe Delete Tool Configuration") {
-		// 替换现有配置
-		lines := strings.Split(existingContent, "\n")
-		var newLines []string
-		skipMode := false
-
-		for _, line := range lines {
-			if strings.Contains(line, "DelGuard Safe Delete Tool Configuration") {
-				skipMode = true
-				continue
-			}
-			if skipMode && strings.TrimSpace(line) == "" && len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) == "" {
-				skipMode = false
-			}
-			if !skipMode {
-				newLines = append(newLines, line)
-			}
-		}
-
-		existingContent = strings.Join(newLines, "\n")
-	}
-
-	// 写入新配置
-	finalContent := existingContent + configContent
+	// 替换已有的DelGuard配置块
+	finalContent := shellProfile.replace(existingContent, configContent)
 	return os.WriteFile(configFile, []byte(finalContent), 0644)
 }
 
@@ -279,32 +261,11 @@ func (l *LinuxInstaller) removeConfigFromFile(configFile string) error {
 	}
 
 	existingContent := string(content)
-
-	// 移除DelGuard配置
-	if strings.Contains(existingContent, "DelGuard Safe Delete Tool Configuration") {
-		lines := strings.Split(existingContent, "\n")
-		var newLines []string
-		skipMode := false
-
-		for _, line := range lines {
-			if strings.Contains(line, "DelGuard Safe Delete Tool Configuration") {
-				skipMode = true
-				continue
-			}
-			if skipMode && strings.TrimSpace(line) == "" && len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) == "" {
-				skipMode = false
-				continue
-			}
-			if !skipMode {
-				newLines = append(newLines, line)
-			}
-		}
-
-		finalContent := strings.Join(newLines, "\n")
-		return os.WriteFile(configFile, []byte(finalContent), 0644)
+	finalContent := shellProfile.remove(existingContent)
+	if finalContent == existingContent {
+		return nil
 	}
-
-	return nil
+	return os.WriteFile(configFile, []byte(finalContent), 0644)
 }
 
 // backupCommand 备份命令
@@ -0,0 +1,121 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// profileEndMarker DelGuard配置块的结束标记
+const profileEndMarker = "# End DelGuard Configuration"
+
+// legacyStartMarker 旧版本写入的配置块起始标记，旧配置块没有结束标记
+const legacyStartMarker = "# DelGuard Safe Delete Tool Configuration"
+
+// profileBlock 安装器写入shell配置文件的DelGuard配置块
+// 配置块由起止标记界定，删除时只移除标记之间的内容，不影响用户自己的配置
+type profileBlock struct {
+	// Start 起始标记行
+	Start string
+	// LegacyEnd 旧版本配置块的最后一行，用于一次性迁移没有结束标记的旧配置块
+	LegacyEnd string
+}
+
+// render 生成带起止标记的配置块，body为标记之间的内容
+func (b profileBlock) render(body string) string {
+	return "\n" + b.Start + "\n" + strings.Trim(body, "\n") + "\n" + profileEndMarker + "\n"
+}
+
+// replace 移除已有的配置块后在末尾追加新的配置块
+func (b profileBlock) replace(content, body string) string {
+	return b.remove(content) + b.render(body)
+}
+
+// remove 移除所有DelGuard配置块，其余内容保持不变
+// 缺少结束标记的配置块无法确定范围，保留原样
+func (b profileBlock) remove(content string) string {
+	content = removeMarkedRange(content, b.Start, profileEndMarker)
+	if b.LegacyEnd != "" {
+		content = removeMarkedRange(content, legacyStartMarker, b.LegacyEnd)
+	}
+	return content
+}
+
+// removeMarkedRange 删除从start行到end行（含）的内容，以及安装器写在start行之前的空行
+func removeMarkedRange(content, start, end string) string {
+	lines := strings.Split(content, "\n")
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != start {
+			kept = append(kept, lines[i])
+			continue
+		}
+
+		last := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == end {
+				last = j
+				break
+			}
+		}
+		if last < 0 {
+			kept = append(kept, lines[i])
+			continue
+		}
+
+		if len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+			kept = kept[:len(kept)-1]
+		}
+		i = last
+	}
+	return strings.Join(kept, "\n")
+}
+
+// powerShellProfile Windows PowerShell配置文件中的DelGuard配置块
+var powerShellProfile = profileBlock{
+	Start:     "# DelGuard PowerShell Configuration",
+	LegacyEnd: `Write-Host "Use 'delguard --help' for detailed help" -ForegroundColor Yellow`,
+}
+
+// shellProfile Linux/macOS shell配置文件中的DelGuard配置块
+var shellProfile = profileBlock{
+	Start:     "# DelGuard Shell Configuration",
+	LegacyEnd: `echo "Use --help for detailed help"`,
+}
+
+// check 检查配置文件中的DelGuard配置块，found表示存在配置块，problem描述发现的问题
+func (b profileBlock) check(content string) (problem string, found bool) {
+	lines := strings.Split(content, "\n")
+	blocks := 0
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		end := profileEndMarker
+		switch {
+		case line == b.Start:
+		case line == legacyStartMarker && b.LegacyEnd != "":
+			end = b.LegacyEnd
+		default:
+			continue
+		}
+
+		blocks++
+		closed := false
+		for j := i + 1; j < len(lines); j++ {
+			next := strings.TrimSpace(lines[j])
+			if next == end {
+				closed = true
+				i = j
+				break
+			}
+			if next == b.Start || next == legacyStartMarker {
+				break
+			}
+		}
+		if !closed {
+			return fmt.Sprintf("第 %d 行的DelGuard配置块缺少结束标记", i+1), true
+		}
+	}
+	if blocks > 1 {
+		return fmt.Sprintf("存在 %d 个DelGuard配置块", blocks), true
+	}
+	return "", blocks > 0
+}
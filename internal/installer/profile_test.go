@@ -0,0 +1,98 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfileBlockRemoveKeepsAdjacentUserFunctions(t *testing.T) {
+	before := "function global:Get-Weather {\n    Write-Host \"sunny\"\n}\n"
+	after := "\nfunction global:Cleanup {\n    Write-Host \"Use --help for detailed help\"\n}\n\n# user comment\n"
+	block := powerShellProfile.render(`function global:del { & 'C:\DelGuard\delguard.exe' delete @args }
+Write-Host "DelGuard loaded"`)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"block between user functions", before + block + after, before + after},
+		{"block at the start", strings.TrimPrefix(block, "\n") + after, after},
+		{"block at the end", before + block, before},
+		{"two blocks", before + block + after + block, before + after},
+		{"no block", before + after, before + after},
+		{
+			name:    "start marker without end marker is left alone",
+			content: before + "\n" + powerShellProfile.Start + "\n" + after,
+			want:    before + "\n" + powerShellProfile.Start + "\n" + after,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := powerShellProfile.remove(tt.content); got != tt.want {
+				t.Errorf("remove() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileBlockMigratesLegacyBlock(t *testing.T) {
+	user := "alias ll='ls -l'\n"
+	next := "myfunc() {\n  echo hi\n}\n"
+	legacy := "\n" + legacyStartMarker + "\nalias del='delguard delete'\n" + shellProfile.LegacyEnd + "\n"
+
+	if got := shellProfile.remove(user + legacy + next); got != user+next {
+		t.Errorf("remove() =\n%q\nwant\n%q", got, user+next)
+	}
+
+	// 找不到旧配置块的最后一行时无法确定范围，保留原样
+	truncated := user + "\n" + legacyStartMarker + "\nalias del='delguard delete'\n" + next
+	if got := shellProfile.remove(truncated); got != truncated {
+		t.Errorf("remove() changed a legacy block without its last line:\n%q", got)
+	}
+
+	// 旧标记只在有LegacyEnd的配置块中识别
+	if got := (profileBlock{Start: shellProfile.Start}).remove(user + legacy + next); got != user+legacy+next {
+		t.Errorf("block without LegacyEnd removed a legacy block:\n%q", got)
+	}
+}
+
+func TestProfileBlockReplaceIsIdempotent(t *testing.T) {
+	user := "export PATH=$HOME/bin:$PATH\n"
+	body := "alias del='delguard delete'"
+
+	once := shellProfile.replace(user, body)
+	twice := shellProfile.replace(once, body)
+	if once != twice {
+		t.Errorf("replace() is not idempotent:\n%q\n%q", once, twice)
+	}
+	if !strings.HasPrefix(once, user) || strings.Count(once, shellProfile.Start) != 1 {
+		t.Errorf("replace() = %q", once)
+	}
+	if shellProfile.remove(once) != user {
+		t.Errorf("remove(replace()) = %q, want %q", shellProfile.remove(once), user)
+	}
+}
+
+func TestProfileBlockCheck(t *testing.T) {
+	block := shellProfile.render("alias del='delguard delete'")
+	tests := []struct {
+		name        string
+		content     string
+		wantFound   bool
+		wantProblem string
+	}{
+		{"no block", "alias ll='ls -l'\n", false, ""},
+		{"one block", "x\n" + block, true, ""},
+		{"duplicate blocks", block + block, true, "存在 2 个DelGuard配置块"},
+		{"unterminated", "x\n" + shellProfile.Start + "\nalias del=x\n", true, "缺少结束标记"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem, found := shellProfile.check(tt.content)
+			if found != tt.wantFound || !strings.Contains(problem, tt.wantProblem) || (tt.wantProblem == "") != (problem == "") {
+				t.Errorf("check() = %q, %v; want %q, %v", problem, found, tt.wantProblem, tt.wantFound)
+			}
+		})
+	}
+}
@@ -6,20 +6,47 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"delguard/internal/utils"
 )
 
 // WindowsInstaller Windows系统安装器
 type WindowsInstaller struct {
 	config *InstallConfig
+	runner utils.CommandRunner
 }
 
 // NewWindowsInstaller 创建Windows安装器
 func NewWindowsInstaller() *WindowsInstaller {
 	return &WindowsInstaller{
 		config: GetDefaultInstallConfig(),
+		runner: utils.ExecRunner{},
 	}
 }
 
+// SetCommandRunner 替换执行PowerShell的命令执行器，用于测试
+func (w *WindowsInstaller) SetCommandRunner(runner utils.CommandRunner) {
+	w.runner = runner
+}
+
+// SetAllProfiles 设置是否写入所有PowerShell配置文件，而不是询问写入哪一个
+func (w *WindowsInstaller) SetAllProfiles(all bool) {
+	w.config.AllProfiles = all
+}
+
+// PowerShell配置文件的作用范围，与$PROFILE的属性名一致
+const (
+	scopeAllHosts    = "CurrentUserAllHosts"
+	scopeCurrentHost = "CurrentUserCurrentHost"
+)
+
+// powerShellProfilePath 一个PowerShell配置文件
+type powerShellProfilePath struct {
+	Host  string // 读取该配置文件的PowerShell程序，pwsh或powershell，无法确定时为空
+	Scope string
+	Path  string
+}
+
 // Install 在Windows上安装DelGuard
 func (w *WindowsInstaller) Install() error {
 	fmt.Println("🔧 开始在Windows上安装DelGuard...")
@@ -61,7 +88,7 @@ func (w *WindowsInstaller) Install() error {
 	}
 
 	// 创建PowerShell配置文件
-	if err := w.createPowerShellProfile(); err != nil {
+	if err := w.createPowerShellProfiles(targetExe); err != nil {
 		fmt.Printf("⚠️ 创建PowerShell配置文件失败: %v\n", err)
 		fmt.Println("   您可能需要手动配置PowerShell别名")
 	}
@@ -86,7 +113,7 @@ func (w *WindowsInstaller) Uninstall() error {
 	}
 
 	// 删除PowerShell配置
-	if err := w.removePowerShellProfile(); err != nil {
+	if err := w.removePowerShellProfiles(); err != nil {
 		fmt.Printf("⚠️ 删除PowerShell配置失败: %v\n", err)
 	}
 
@@ -108,6 +135,91 @@ func (w *WindowsInstaller) IsInstalled() bool {
 	return err == nil
 }
 
+// Diagnose 检查del别名脚本和PowerShell配置文件，配置块完整时再用PowerShell解析器检查语法
+func (w *WindowsInstaller) Diagnose() Diagnosis {
+	diagnosis := Diagnosis{Profiles: []ProfileCheck{}}
+
+	script := filepath.Join(w.config.InstallPath, "del.bat")
+	if target := readAliasTarget(script); target != "" {
+		diagnosis.AliasScript = script
+		diagnosis.AliasTarget = target
+	}
+
+	for _, profile := range w.powerShellProfiles() {
+		content, err := os.ReadFile(profile.Path)
+		if err != nil {
+			continue
+		}
+		problem, found := powerShellProfile.check(string(content))
+		if !found {
+			continue
+		}
+		if problem == "" {
+			problem = w.checkPowerShellSyntax(profile.Path)
+		}
+		diagnosis.Profiles = append(diagnosis.Profiles, ProfileCheck{Path: profile.Path, Problem: problem})
+	}
+	return diagnosis
+}
+
+// Aliases 检查del、rmdir别名脚本和各PowerShell配置文件，配置块完整时再用PowerShell解析器检查语法
+func (w *WindowsInstaller) Aliases() []AliasStatus {
+	current := currentExecutable()
+	statuses := scriptAliasStatus(w.config.InstallPath, []string{"del.bat", "rmdir.bat"}, current)
+
+	for _, profile := range w.powerShellProfiles() {
+		status := AliasStatus{Shell: profile.Host, Path: profile.Path}
+		if status.Shell == "" {
+			status.Shell = "powershell"
+		}
+		if content, err := os.ReadFile(profile.Path); err == nil {
+			status.Problem, status.Installed = powerShellProfile.check(string(content))
+			if status.Installed {
+				var target string
+				if match := powerShellTargetPattern.FindStringSubmatch(powerShellProfile.body(string(content))); match != nil {
+					target = strings.ReplaceAll(match[1], "''", "'")
+				}
+				status.inspectTarget(target, current)
+				if status.Problem == "" {
+					status.ParseError = w.checkPowerShellSyntax(profile.Path)
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// RepairAliases 将失效的别名脚本和PowerShell配置块重新指向target
+func (w *WindowsInstaller) RepairAliases(target string) []AliasRepair {
+	return repairAliases(w.Aliases(), target, func(status AliasStatus, content string) (string, error) {
+		if status.Shell == "script" {
+			return rewriteScriptTarget(content, status.Target, target)
+		}
+		updated, changed := powerShellProfile.replaceInBlock(content, "& "+psQuote(status.Target), "& "+psQuote(target))
+		if !changed {
+			return "", fmt.Errorf("配置块中找不到 %s", status.Target)
+		}
+		return updated, nil
+	})
+}
+
+// checkPowerShellSyntax 使用PowerShell解析器检查脚本语法，返回第一条解析错误
+// 无法运行PowerShell时不报告问题
+func (w *WindowsInstaller) checkPowerShellSyntax(path string) string {
+	script := fmt.Sprintf(`
+$errors = $null
+[void][System.Management.Automation.Language.Parser]::ParseFile(%s, [ref]$null, [ref]$errors)
+$errors | ForEach-Object { "line $($_.Extent.StartLineNumber): $($_.Message)" }
+`, psQuote(path))
+
+	output, err := w.runner.Run("powershell", "-NoProfile", "-Command", script)
+	if err != nil {
+		return ""
+	}
+	return firstLine(string(output))
+}
+
 // GetInstallPath 获取安装路径
 func (w *WindowsInstaller) GetInstallPath() string {
 	return w.config.InstallPath
@@ -152,48 +264,67 @@ REM DelGuard safe delete wrapper for 'rmdir' command
 	return nil
 }
 
-// createPowerShellProfile 创建PowerShell配置文件
-func (w *WindowsInstaller) createPowerShellProfile() error {
-	// 获取PowerShell配置文件路径
-	profilePath, err := w.getPowerShellProfilePath()
-	if err != nil {
-		return err
+// createPowerShellProfiles 将DelGuard配置块写入选定的PowerShell配置文件，写入后逐个验证del函数能否解析
+func (w *WindowsInstaller) createPowerShellProfiles(delguardPath string) error {
+	profiles := w.selectPowerShellProfiles(w.powerShellProfiles())
+	if len(profiles) == 0 {
+		return fmt.Errorf("未选择PowerShell配置文件")
 	}
 
-	// 确保配置文件目录存在
-	profileDir := filepath.Dir(profilePath)
-	if err := os.MkdirAll(profileDir, 0755); err != nil {
-		return fmt.Errorf("创建PowerShell配置目录失败: %v", err)
-	}
+	// 路径使用单引号字符串，不会展开$和`，其中的单引号按PowerShell规则写成两个
+	exe := psQuote(delguardPath)
+	configContent := fmt.Sprintf(`# Auto-generated by DelGuard installer
 
-	// DelGuard配置内容
-	delguardPath := filepath.Join(w.config.InstallPath, "delguard.exe")
-	configContent := fmt.Sprintf(`
-# DelGuard Safe Delete Tool Configuration
-# Auto-generated by DelGuard installer
+# 内置的del、rm、rmdir别名优先于同名函数，先移除
+Remove-Item Alias:del, Alias:rm, Alias:rmdir -Force -ErrorAction SilentlyContinue
 
 # DelGuard安全删除工具别名
 function del {
-    & "%s" delete $args
+    & %s delete $args
 }
 
 function rm {
-    & "%s" delete $args
+    & %s delete $args
 }
 
 function rmdir {
-    & "%s" delete $args -r
+    & %s delete $args -r
 }
 
 # DelGuard工具别名
 function delguard {
-    & "%s" $args
+    & %s $args
 }
 
 Write-Host "DelGuard Safe Delete Tool Loaded" -ForegroundColor Green
 Write-Host "Commands: del, rm, cp, copy, delguard" -ForegroundColor Cyan
 Write-Host "Use 'delguard --help' for detailed help" -ForegroundColor Yellow
-`, delguardPath, delguardPath, delguardPath, delguardPath)
+`, exe, exe, exe, exe)
+
+	var failed []string
+	for _, profile := range profiles {
+		if err := writePowerShellProfile(profile.Path, configContent); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+			failed = append(failed, profile.Path)
+			continue
+		}
+		if problem := w.verifyPowerShellProfile(profile); problem != "" {
+			fmt.Printf("⚠️ 已写入 %s，但del函数未能生效: %s\n", profile.Path, problem)
+		} else {
+			fmt.Printf("✅ 已写入 %s，del函数可用\n", profile.Path)
+		}
+	}
+	if len(failed) == len(profiles) {
+		return fmt.Errorf("写入PowerShell配置文件失败: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// writePowerShellProfile 替换配置文件中已有的DelGuard配置块，文件或目录不存在时创建
+func writePowerShellProfile(profilePath, configContent string) error {
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
+		return fmt.Errorf("创建PowerShell配置目录失败: %v", err)
+	}
 
 	// 读取现有配置文件
 	var existingContent string
@@ -201,81 +332,151 @@ Write-Host "Use 'delguard --help' for detailed help" -ForegroundColor Yellow
 		existingContent = string(content)
 	}
 
-	// 检查是否已经包含DelGuard配置
-	if strings.Contains(existingContent, "DelGuard Safe Delete Tool Configuration") {
-		// 替换现有配置
-		lines := strings.Split(existingContent, "\n")
-		var newLines []string
-		skipMode := false
-
-		for _, line := range lines {
-			if strings.Contains(line, "DelGuard Safe Delete Tool Configuration") {
-				skipMode = true
-				continue
-			}
-			if skipMode && strings.TrimSpace(line) == "" && len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) == "" {
-				skipMode = false
-			}
-			if !skipMode {
-				newLines = append(newLines, line)
-			}
-		}
+	// 替换已有的DelGuard配置块
+	finalContent := powerShellProfile.replace(existingContent, configContent)
+	if err := os.WriteFile(profilePath, []byte(finalContent), 0644); err != nil {
+		return fmt.Errorf("写入PowerShell配置文件 %s 失败: %v", profilePath, err)
+	}
+	return nil
+}
 
-		existingContent = strings.Join(newLines, "\n")
+// verifyPowerShellProfile 在不加载其他配置文件的PowerShell中执行配置文件，检查del是否解析为函数
+// 返回发现的问题，无法确定读取该配置文件的PowerShell程序时不检查
+func (w *WindowsInstaller) verifyPowerShellProfile(profile powerShellProfilePath) string {
+	if profile.Host == "" {
+		return ""
+	}
+	script := fmt.Sprintf(`. %s *> $null; $cmd = Get-Command del -ErrorAction SilentlyContinue; if ($cmd) { $cmd.CommandType } else { 'NotFound' }`, psQuote(profile.Path))
+	output, err := w.runner.Run(profile.Host, "-NoProfile", "-Command", script)
+	result := firstLine(string(output))
+	switch {
+	case err != nil && result == "":
+		return fmt.Sprintf("无法运行%s: %v", profile.Host, err)
+	case err != nil:
+		return result
+	case result == "Function":
+		return ""
+	case result == "NotFound":
+		return "找不到del命令"
+	default:
+		return fmt.Sprintf("del解析为%s而不是函数", result)
 	}
+}
 
-	// 写入新配置
-	finalContent := existingContent + configContent
-	if err := os.WriteFile(profilePath, []byte(finalContent), 0644); err != nil {
-		return fmt.Errorf("写入PowerShell配置文件失败: %v", err)
+// selectPowerShellProfiles 选择要写入的配置文件
+// 指定--all-profiles或只有一个候选时写入全部，否则询问用户，无法读取输入时使用默认的CurrentUserCurrentHost
+func (w *WindowsInstaller) selectPowerShellProfiles(profiles []powerShellProfilePath) []powerShellProfilePath {
+	if w.config.AllProfiles || len(profiles) <= 1 {
+		return profiles
 	}
 
-	return nil
+	def := 0
+	for i, profile := range profiles {
+		if profile.Scope == scopeCurrentHost {
+			def = i
+			break
+		}
+	}
+	fmt.Println("📄 找到以下PowerShell配置文件:")
+	for i, profile := range profiles {
+		fmt.Printf("   %d. [%s %s] %s\n", i+1, profile.Host, profile.Scope, profile.Path)
+	}
+	fmt.Printf("请选择要写入的配置文件，多个用逗号分隔，a表示全部 [默认 %d]: ", def+1)
+	var response string
+	if _, err := fmt.Scanln(&response); err != nil {
+		return profiles[def : def+1]
+	}
+	return pickPowerShellProfiles(profiles, response, def)
 }
 
-// removePowerShellProfile 删除PowerShell配置
-func (w *WindowsInstaller) removePowerShellProfile() error {
-	profilePath, err := w.getPowerShellProfilePath()
-	if err != nil {
-		return err
+// pickPowerShellProfiles 按用户输入的序号选择配置文件，输入为空或无效时使用默认项
+func pickPowerShellProfiles(profiles []powerShellProfilePath, response string, def int) []powerShellProfilePath {
+	response = strings.TrimSpace(response)
+	if strings.EqualFold(response, "a") || strings.EqualFold(response, "all") {
+		return profiles
 	}
 
-	// 读取现有配置文件
-	content, err := os.ReadFile(profilePath)
-	if err != nil {
-		return nil // 文件不存在，无需删除
+	var picked []powerShellProfilePath
+	seen := make(map[int]bool)
+	for _, field := range strings.Split(response, ",") {
+		var n int
+		if _, err := fmt.Sscanf(strings.TrimSpace(field), "%d", &n); err != nil || n < 1 || n > len(profiles) || seen[n] {
+			continue
+		}
+		seen[n] = true
+		picked = append(picked, profiles[n-1])
+	}
+	if len(picked) == 0 {
+		return profiles[def : def+1]
 	}
+	return picked
+}
 
-	existingContent := string(content)
+// removePowerShellProfiles 从所有PowerShell配置文件中删除DelGuard配置块
+func (w *WindowsInstaller) removePowerShellProfiles() error {
+	var failed []string
+	for _, profile := range w.powerShellProfiles() {
+		// 读取现有配置文件
+		content, err := os.ReadFile(profile.Path)
+		if err != nil {
+			continue // 文件不存在，无需删除
+		}
 
-	// 移除DelGuard配置
-	if strings.Contains(existingContent, "DelGuard Safe Delete Tool Configuration") {
-		lines := strings.Split(existingContent, "\n")
-		var newLines []string
-		skipMode := false
+		existingContent := string(content)
+		finalContent := powerShellProfile.remove(existingContent)
+		if finalContent == existingContent {
+			continue
+		}
+		if err := os.WriteFile(profile.Path, []byte(finalContent), 0644); err != nil {
+			failed = append(failed, profile.Path)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("无法修改配置文件: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
 
-		for _, line := range lines {
-			if strings.Contains(line, "DelGuard Safe Delete Tool Configuration") {
-				skipMode = true
-				continue
+// powerShellProfiles 向已安装的PowerShell查询$PROFILE的实际路径，
+// 文档目录被重定向到OneDrive等位置时也能得到正确的路径
+// 两种PowerShell都无法运行时回退到默认的文档目录
+func (w *WindowsInstaller) powerShellProfiles() []powerShellProfilePath {
+	var profiles []powerShellProfilePath
+	seen := make(map[string]bool)
+	for _, host := range []string{"pwsh", "powershell"} {
+		output, err := w.runner.Run(host, "-NoProfile", "-Command", "$PROFILE.CurrentUserAllHosts; $PROFILE.CurrentUserCurrentHost")
+		if err != nil {
+			continue
+		}
+		var paths []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				paths = append(paths, line)
 			}
-			if skipMode && strings.TrimSpace(line) == "" && len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) == "" {
-				skipMode = false
+		}
+		if len(paths) != 2 {
+			continue
+		}
+		for i, scope := range []string{scopeAllHosts, scopeCurrentHost} {
+			key := strings.ToLower(filepath.Clean(paths[i]))
+			if seen[key] {
 				continue
 			}
-			if !skipMode {
-				newLines = append(newLines, line)
-			}
+			seen[key] = true
+			profiles = append(profiles, powerShellProfilePath{Host: host, Scope: scope, Path: paths[i]})
 		}
-
-		finalContent := strings.Join(newLines, "\n")
-		return os.WriteFile(profilePath, []byte(finalContent), 0644)
+	}
+	if len(profiles) > 0 {
+		return profiles
 	}
 
-	return nil
+	if path, err := w.getPowerShellProfilePath(); err == nil {
+		profiles = append(profiles, powerShellProfilePath{Scope: scopeCurrentHost, Path: path})
+	}
+	return profiles
 }
 
-// getPowerShellProfilePath 获取PowerShell配置文件路径
+// getPowerShellProfilePath 获取默认文档目录下的PowerShell配置文件路径，无法查询$PROFILE时使用
 func (w *WindowsInstaller) getPowerShellProfilePath() (string, error) {
 	// 尝试获取PowerShell 7的配置文件路径
 	homeDir, err := os.UserHomeDir()
@@ -347,8 +548,8 @@ Write-Host "Removed from user PATH"
 `, strings.ReplaceAll(w.config.InstallPath, `\`, `\\`))
 	}
 
-	cmd := exec.Command("powershell", "-Command", script)
-	return cmd.Run()
+	_, err := w.runner.Run("powershell", "-Command", script)
+	return err
 }
 
 // addToSystemPath 添加到系统PATH（需要管理员权限）
@@ -386,8 +587,8 @@ Write-Host "Removed from system PATH"
 `, strings.ReplaceAll(w.config.InstallPath, `\`, `\\`))
 	}
 
-	cmd := exec.Command("powershell", "-Command", script)
-	return cmd.Run()
+	_, err := w.runner.Run("powershell", "-Command", script)
+	return err
 }
 
 // copyFile 复制文件
@@ -421,3 +622,8 @@ func isWindowsAdmin() bool {
 	os.Remove(tempFile)
 	return true
 }
+
+// psQuote 将字符串写成PowerShell单引号字符串，单引号字符串中只有单引号需要转义
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,198 @@
+package installer
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRunner 按程序名返回预设输出的命令执行器，没有预设的程序视为未安装
+type fakeRunner struct {
+	outputs map[string]string
+	calls   []string
+}
+
+func (r *fakeRunner) Run(name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, name)
+	output, ok := r.outputs[name]
+	if !ok {
+		return nil, stderrors.New("executable file not found in %PATH%")
+	}
+	return []byte(output), nil
+}
+
+func newTestInstaller(outputs map[string]string) (*WindowsInstaller, *fakeRunner) {
+	runner := &fakeRunner{outputs: outputs}
+	installer := NewWindowsInstaller()
+	installer.SetCommandRunner(runner)
+	return installer, runner
+}
+
+func TestPowerShellProfilesQueriesEachHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs map[string]string
+		want    []powerShellProfilePath
+	}{
+		{
+			name: "both hosts, shared all-hosts profile listed once",
+			outputs: map[string]string{
+				"pwsh":       "C:\\Docs\\PowerShell\\profile.ps1\r\nC:\\Docs\\PowerShell\\Microsoft.PowerShell_profile.ps1\r\n",
+				"powershell": "c:\\docs\\powershell\\PROFILE.ps1\r\nC:\\Docs\\WindowsPowerShell\\Microsoft.PowerShell_profile.ps1\r\n",
+			},
+			want: []powerShellProfilePath{
+				{Host: "pwsh", Scope: scopeAllHosts, Path: `C:\Docs\PowerShell\profile.ps1`},
+				{Host: "pwsh", Scope: scopeCurrentHost, Path: `C:\Docs\PowerShell\Microsoft.PowerShell_profile.ps1`},
+				{Host: "powershell", Scope: scopeCurrentHost, Path: `C:\Docs\WindowsPowerShell\Microsoft.PowerShell_profile.ps1`},
+			},
+		},
+		{
+			name: "only Windows PowerShell installed",
+			outputs: map[string]string{
+				"powershell": "D:\\OneDrive\\Documents\\WindowsPowerShell\\profile.ps1\nD:\\OneDrive\\Documents\\WindowsPowerShell\\Microsoft.PowerShell_profile.ps1\n",
+			},
+			want: []powerShellProfilePath{
+				{Host: "powershell", Scope: scopeAllHosts, Path: `D:\OneDrive\Documents\WindowsPowerShell\profile.ps1`},
+				{Host: "powershell", Scope: scopeCurrentHost, Path: `D:\OneDrive\Documents\WindowsPowerShell\Microsoft.PowerShell_profile.ps1`},
+			},
+		},
+		{
+			name: "unexpected output is ignored",
+			outputs: map[string]string{
+				"pwsh":       "only one line\n",
+				"powershell": "C:\\a\\profile.ps1\nC:\\a\\Microsoft.PowerShell_profile.ps1\n",
+			},
+			want: []powerShellProfilePath{
+				{Host: "powershell", Scope: scopeAllHosts, Path: `C:\a\profile.ps1`},
+				{Host: "powershell", Scope: scopeCurrentHost, Path: `C:\a\Microsoft.PowerShell_profile.ps1`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer, _ := newTestInstaller(tt.outputs)
+			if got := installer.powerShellProfiles(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("powerShellProfiles() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPowerShellProfilesFallsBackWithoutPowerShell(t *testing.T) {
+	installer, runner := newTestInstaller(nil)
+	for _, profile := range installer.powerShellProfiles() {
+		if profile.Host != "" || profile.Scope != scopeCurrentHost {
+			t.Errorf("fallback profile = %+v, want the default current-host profile without a host", profile)
+		}
+	}
+	if want := []string{"pwsh", "powershell"}; !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("ran %v, want %v", runner.calls, want)
+	}
+}
+
+func TestRemovePowerShellProfilesKeepsUserContent(t *testing.T) {
+	dir := t.TempDir()
+	user := "Set-Alias ll Get-ChildItem\n"
+	block := powerShellProfile.render("function del { & 'C:\\delguard.exe' delete @args }")
+	legacy := "\n" + legacyStartMarker + "\nSet-Alias del delguard\n" + powerShellProfile.LegacyEnd + "\n"
+	unterminated := "\n" + powerShellProfile.Start + "\nfunction del {}\n"
+
+	files := map[string]struct{ before, after string }{
+		"current.ps1":      {user + block, user},
+		"both.ps1":         {user + legacy + "$env:EDITOR = 'code'\n" + block, user + "$env:EDITOR = 'code'\n"},
+		"untouched.ps1":    {user, user},
+		"unterminated.ps1": {user + unterminated, user + unterminated},
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content.before), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	// 不存在的配置文件被跳过
+	paths = append(paths, filepath.Join(dir, "missing.ps1"))
+
+	installer, _ := newTestInstaller(map[string]string{
+		"pwsh":       strings.Join(paths[:2], "\n"),
+		"powershell": strings.Join(paths[2:4], "\n"),
+	})
+	if err := installer.removePowerShellProfiles(); err != nil {
+		t.Fatalf("removePowerShellProfiles: %v", err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content.after {
+			t.Errorf("%s =\n%q\nwant\n%q", name, data, content.after)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.ps1")); !os.IsNotExist(err) {
+		t.Errorf("missing profile was created: %v", err)
+	}
+}
+
+func TestVerifyPowerShellProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  *string
+		profile powerShellProfilePath
+		want    string
+	}{
+		{name: "del is the DelGuard function", output: strPtr("Function\r\n"), profile: powerShellProfilePath{Host: "pwsh"}, want: ""},
+		{name: "del still the built-in alias", output: strPtr("Alias\n"), profile: powerShellProfilePath{Host: "pwsh"}, want: "del解析为Alias而不是函数"},
+		{name: "del missing", output: strPtr("NotFound\n"), profile: powerShellProfilePath{Host: "pwsh"}, want: "找不到del命令"},
+		{name: "unknown host is not checked", profile: powerShellProfilePath{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := map[string]string{}
+			if tt.output != nil {
+				outputs["pwsh"] = *tt.output
+			}
+			installer, _ := newTestInstaller(outputs)
+			if got := installer.verifyPowerShellProfile(tt.profile); got != tt.want {
+				t.Errorf("verifyPowerShellProfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	installer, _ := newTestInstaller(nil)
+	if got := installer.verifyPowerShellProfile(powerShellProfilePath{Host: "pwsh"}); !strings.HasPrefix(got, "无法运行pwsh") {
+		t.Errorf("missing host reported %q", got)
+	}
+}
+
+func TestPickPowerShellProfiles(t *testing.T) {
+	profiles := []powerShellProfilePath{{Path: "a"}, {Path: "b"}, {Path: "c"}}
+	tests := []struct {
+		response string
+		want     []string
+	}{
+		{"", []string{"b"}},
+		{"a", []string{"a", "b", "c"}},
+		{"ALL", []string{"a", "b", "c"}},
+		{"3,1", []string{"c", "a"}},
+		{" 1 , 1 ,9", []string{"a"}},
+		{"x", []string{"b"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, profile := range pickPowerShellProfiles(profiles, tt.response, 1) {
+			got = append(got, profile.Path)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pickPowerShellProfiles(%q) = %v, want %v", tt.response, got, tt.want)
+		}
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,220 @@
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"delguard/internal/paths"
+)
+
+// HeldError 锁已被其他进程持有
+type HeldError struct {
+	Path string
+	PID  int
+}
+
+// Error 实现error接口
+func (e *HeldError) Error() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("锁已被进程 %d 持有: %s", e.PID, e.Path)
+	}
+	return fmt.Sprintf("锁已被其他进程持有: %s", e.Path)
+}
+
+// IsHeld 检查错误是否表示锁被其他进程持有，返回持有者的PID
+func IsHeld(err error) (int, bool) {
+	if held, ok := err.(*HeldError); ok {
+		return held.PID, true
+	}
+	return 0, false
+}
+
+// FileLock 基于锁文件的跨进程咨询锁
+// Unix上使用flock，Windows上使用LockFileEx，进程退出时由系统自动释放
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+// TryLock 尝试获取锁文件上的排他锁，不等待
+// 锁被占用时返回*HeldError；持有者进程已不存在时回收该锁
+func TryLock(path string) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("创建锁目录失败: %v", err)
+	}
+
+	reclaimed := false
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("打开锁文件失败: %v", err)
+		}
+
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("获取锁失败: %v", err)
+		}
+		if locked {
+			// 打开后、加锁前持有者可能已释放并删除了锁文件，锁住的是已删除的文件，
+			// 此时其他进程可以在新建的锁文件上同时加锁，需要重新打开
+			if !isCurrentFile(file, path) {
+				unlockFile(file)
+				file.Close()
+				continue
+			}
+			l := &FileLock{path: path, file: file}
+			l.writeOwner()
+			return l, nil
+		}
+
+		pid := readOwner(file)
+		file.Close()
+
+		// 持有者已退出但锁未释放（如网络文件系统），删除锁文件后重试一次
+		if pid > 0 && !processAlive(pid) && !reclaimed {
+			reclaimed = true
+			os.Remove(path)
+			continue
+		}
+		return nil, &HeldError{Path: path, PID: pid}
+	}
+}
+
+// isCurrentFile 检查已打开的文件是否仍是path指向的文件
+func isCurrentFile(file *os.File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
+
+// Lock 获取锁文件上的排他锁，最多等待timeout
+func Lock(path string, timeout time.Duration) (*FileLock, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		l, err := TryLock(path)
+		if err == nil {
+			return l, nil
+		}
+		if _, held := IsHeld(err); !held || time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TryLockPath 为待操作的路径获取锁，锁文件位于缓存目录（默认 ~/.delguard）的locks下，以路径哈希命名
+func TryLockPath(target string) (*FileLock, error) {
+	lockFile, err := lockFileFor(target)
+	if err != nil {
+		return nil, err
+	}
+	return TryLock(lockFile)
+}
+
+// Unlock 释放锁并删除锁文件
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	// 先删除再解锁，避免其他进程在已删除的文件上加锁成功
+	os.Remove(l.path)
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+// writeOwner 将当前进程PID写入锁文件
+func (l *FileLock) writeOwner() {
+	if err := l.file.Truncate(0); err != nil {
+		return
+	}
+	l.file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	l.file.Sync()
+}
+
+// readOwner 读取锁文件中记录的PID
+func readOwner(file *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := file.ReadAt(buf, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// lockFileFor 根据绝对路径的哈希生成锁文件路径
+func lockFileFor(target string) (string, error) {
+	absPath, err := filepath.Abs(target)
+	if err != nil {
+		return "", fmt.Errorf("路径转换失败: %v", err)
+	}
+
+	dir, err := locksDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(filepath.Clean(absPath)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".lock"), nil
+}
+
+// locksDir 返回路径锁所在的目录，位于缓存目录下的locks
+func locksDir() (string, error) {
+	cacheDir, err := paths.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "locks"), nil
+}
+
+// StaleLocks 列出持有进程已退出的路径锁文件，通常由被中断的删除操作遗留
+// 这些锁会在下次操作同一路径时自动回收
+func StaleLocks() ([]string, error) {
+	dir, err := locksDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取锁目录失败: %v", err)
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".lock" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		pid := readOwner(file)
+		file.Close()
+		if pid > 0 && !processAlive(pid) {
+			stale = append(stale, path)
+		}
+	}
+	return stale, nil
+}
@@ -0,0 +1,177 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// useTempCache 将路径锁目录指向临时目录
+func useTempCache(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	dir, err := locksDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// mustLock 获取锁，测试结束时释放
+func mustLock(t *testing.T, path string) *FileLock {
+	t.Helper()
+	l, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	t.Cleanup(func() { l.Unlock() })
+	return l
+}
+
+func TestTryLockContention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "item.lock")
+	holder := mustLock(t, path)
+
+	// 持有者在锁文件中记录自己的PID
+	if data, err := os.ReadFile(path); err != nil || string(data) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("lock file = %q, %v; want the holder's pid", data, err)
+	}
+
+	_, err := TryLock(path)
+	pid, held := IsHeld(err)
+	if !held || pid != os.Getpid() {
+		t.Fatalf("second TryLock = %v, want held by pid %d", err, os.Getpid())
+	}
+	// 失败的一方不能删除或改写持有者的锁文件
+	if data, err := os.ReadFile(path); err != nil || string(data) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("lock file after contention = %q, %v", data, err)
+	}
+
+	if err := holder.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file left after Unlock: %v", err)
+	}
+	mustLock(t, path)
+}
+
+func TestLockWaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.lock")
+	holder := mustLock(t, path)
+
+	start := time.Now()
+	if _, err := Lock(path, 100*time.Millisecond); err == nil {
+		t.Fatal("Lock acquired a held lock")
+	} else if _, held := IsHeld(err); !held {
+		t.Errorf("Lock = %v, want a held error", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Lock gave up after %v, want it to wait for the timeout", elapsed)
+	}
+
+	time.AfterFunc(50*time.Millisecond, func() { holder.Unlock() })
+	l, err := Lock(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Lock after release: %v", err)
+	}
+	l.Unlock()
+}
+
+func TestUnlockAfterFailedOperation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "item.lock")
+	operation := func() (err error) {
+		l, err := TryLock(path)
+		if err != nil {
+			return err
+		}
+		defer l.Unlock()
+		return errors.New("move failed")
+	}
+
+	// 操作失败后锁被释放，同一路径可以再次加锁
+	for i := 0; i < 2; i++ {
+		if err := operation(); err == nil || err.Error() != "move failed" {
+			t.Fatalf("attempt %d = %v, want the operation's error", i+1, err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file left after a failed operation: %v", err)
+	}
+}
+
+func TestUnlockWithoutLock(t *testing.T) {
+	// 加锁失败时调用方拿到nil，释放它不应出错
+	var l *FileLock
+	if err := l.Unlock(); err != nil {
+		t.Errorf("nil Unlock = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "item.lock")
+	held, err := TryLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := held.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := held.Unlock(); err != nil {
+		t.Errorf("second Unlock = %v, want a no-op", err)
+	}
+}
+
+func TestTryLockReportsSetupErrors(t *testing.T) {
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := TryLock(filepath.Join(parent, "item.lock"))
+	if err == nil || l != nil {
+		t.Fatalf("TryLock under a file = %v, %v; want an error", l, err)
+	}
+	if _, held := IsHeld(err); held {
+		t.Errorf("TryLock = %v, want a setup error rather than contention", err)
+	}
+}
+
+func TestTryLockPath(t *testing.T) {
+	dir := useTempCache(t)
+	target := filepath.Join(t.TempDir(), "report.txt")
+
+	l, err := TryLockPath(target)
+	if err != nil {
+		t.Fatalf("TryLockPath: %v", err)
+	}
+	defer l.Unlock()
+	if filepath.Dir(l.path) != dir {
+		t.Errorf("lock file %s, want it in %s", l.path, dir)
+	}
+
+	// 同一路径的不同写法使用同一把锁
+	if _, err := TryLockPath(filepath.Join(filepath.Dir(target), ".", "report.txt")); err == nil {
+		t.Error("TryLockPath locked the same path twice")
+	}
+	other, err := TryLockPath(target + ".bak")
+	if err != nil {
+		t.Fatalf("TryLockPath(other): %v", err)
+	}
+	other.Unlock()
+}
+
+func TestStaleLocksSkipsLiveHolders(t *testing.T) {
+	dir := useTempCache(t)
+	if stale, err := StaleLocks(); err != nil || stale != nil {
+		t.Errorf("StaleLocks without a lock directory = %v, %v", stale, err)
+	}
+	mustLock(t, filepath.Join(dir, "live.lock"))
+	if stale, err := StaleLocks(); err != nil || len(stale) != 0 {
+		t.Errorf("StaleLocks = %v, %v; want the live lock ignored", stale, err)
+	}
+}
@@ -0,0 +1,95 @@
+//go:build !windows
+
+package lock
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// deadPID 返回一个已退出进程的PID
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot start a process: %v", err)
+	}
+	pid := cmd.Process.Pid
+	if processAlive(pid) {
+		t.Skipf("pid %d was reused", pid)
+	}
+	return pid
+}
+
+// writeOrphanLock 写入记录了已退出进程PID的锁文件
+// 在另一个文件描述符上保持flock，模拟持有者退出后锁未被释放（如网络文件系统）
+func writeOrphanLock(t *testing.T, path string, pid int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	if locked, err := tryLockFile(file); err != nil || !locked {
+		t.Fatalf("tryLockFile = %v, %v", locked, err)
+	}
+	if _, err := file.WriteString(strconv.Itoa(pid)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTryLockReclaimsLockOfDeadProcess(t *testing.T) {
+	dir := useTempCache(t)
+	path := filepath.Join(dir, "orphan.lock")
+	writeOrphanLock(t, path, deadPID(t))
+
+	if stale, err := StaleLocks(); err != nil || !reflect.DeepEqual(stale, []string{path}) {
+		t.Errorf("StaleLocks = %v, %v; want %s", stale, err, path)
+	}
+
+	l, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock over a dead holder = %v, want the lock reclaimed", err)
+	}
+	defer l.Unlock()
+	if data, err := os.ReadFile(path); err != nil || string(data) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("reclaimed lock file = %q, %v; want the new holder's pid", data, err)
+	}
+	if stale, err := StaleLocks(); err != nil || len(stale) != 0 {
+		t.Errorf("StaleLocks after reclaiming = %v, %v; want none", stale, err)
+	}
+}
+
+func TestTryLockKeepsLockOfLiveProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "busy.lock")
+	// 持有者仍在运行时即使PID不是当前进程也不能回收
+	writeOrphanLock(t, path, os.Getppid())
+
+	_, err := TryLock(path)
+	if pid, held := IsHeld(err); !held || pid != os.Getppid() {
+		t.Fatalf("TryLock = %v, want held by pid %d", err, os.Getppid())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("live holder's lock file removed: %v", err)
+	}
+}
+
+func TestTryLockIgnoresUnlockedLeftoverFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leftover.lock")
+	// 进程被杀死后flock随之释放，只留下记录旧PID的文件
+	if err := os.WriteFile(path, []byte(strconv.Itoa(deadPID(t))), 0600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock over a leftover file: %v", err)
+	}
+	l.Unlock()
+}
@@ -5,8 +5,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// levelRank 日志级别的顺序，低于当前级别的日志不写入
+var levelRank = map[string]int{"debug": 0, "info": 1, "warn": 2, "warning": 2, "error": 3, "fatal": 4}
+
+// minRank 当前写入的最低级别，默认为info
+var minRank = levelRank["info"]
+
 var (
 	infoLogger  *log.Logger
 	errorLogger *log.Logger
@@ -37,6 +44,8 @@ func Init(logFilePath, level string, maxSize, maxAge int, compress bool) error {
 	errorLogger = log.New(logFilePtr, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile)
 	debugLogger = log.New(logFilePtr, "[DEBUG] ", log.Ldate|log.Ltime|log.Lshortfile)
 
+	SetLevel(level)
+
 	// 记录初始化信息
 	Info("日志系统初始化成功")
 	Debugf("日志文件: %s, 级别: %s", logFilePath, level)
@@ -57,23 +66,40 @@ func Close() error {
 	return nil
 }
 
+// SetLevel 设置写入日志的最低级别: debug, info, warn, error, fatal，未知的级别按info处理
+func SetLevel(level string) {
+	rank, ok := levelRank[strings.ToLower(level)]
+	if !ok {
+		rank = levelRank["info"]
+	}
+	minRank = rank
+}
+
+// EnableLevel 确保level及以上级别的日志会被写入，当前级别已经更低时不变
+// 供-v/--debug提高日志详细程度，不会因此减少配置要求记录的内容
+func EnableLevel(level string) {
+	if rank, ok := levelRank[strings.ToLower(level)]; ok && rank < minRank {
+		minRank = rank
+	}
+}
+
 // Info 记录信息日志
 func Info(msg string) {
-	if infoLogger != nil {
+	if infoLogger != nil && minRank <= levelRank["info"] {
 		infoLogger.Println(msg)
 	}
 }
 
 // Error 记录错误日志
 func Error(msg string) {
-	if errorLogger != nil {
+	if errorLogger != nil && minRank <= levelRank["error"] {
 		errorLogger.Println(msg)
 	}
 }
 
 // Debug 记录调试日志
 func Debug(msg string) {
-	if debugLogger != nil {
+	if debugLogger != nil && minRank <= levelRank["debug"] {
 		debugLogger.Println(msg)
 	}
 }
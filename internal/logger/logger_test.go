@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// logAt 以level初始化临时日志文件，写入各级别的日志后返回文件内容
+func logAt(t *testing.T, level string, enable string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "delguard.log")
+	if err := Init(path, level, 0, 0, false); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() {
+		Close()
+		SetLevel("info")
+	})
+	if enable != "" {
+		EnableLevel(enable)
+	}
+	Debug("debug message")
+	Info("info message")
+	Error("error message")
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLogLevels(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  string
+		enable string
+		want   []string
+		skip   []string
+	}{
+		{"info", "info", "", []string{"info message", "error message"}, []string{"debug message"}},
+		{"debug", "DEBUG", "", []string{"debug message", "info message", "error message"}, nil},
+		{"error", "error", "", []string{"error message"}, []string{"debug message", "info message"}},
+		{"unknown falls back to info", "chatty", "", []string{"info message"}, []string{"debug message"}},
+		{"verbose lowers error", "error", "info", []string{"info message", "error message"}, []string{"debug message"}},
+		{"debug flag lowers info", "info", "debug", []string{"debug message", "info message"}, nil},
+		// 配置的级别已经更低时，-v不会减少记录的内容
+		{"enable never raises", "debug", "info", []string{"debug message"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := logAt(t, tt.level, tt.enable)
+			for _, want := range tt.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("log is missing %q:\n%s", want, content)
+				}
+			}
+			for _, skip := range tt.skip {
+				if strings.Contains(content, skip) {
+					t.Errorf("log contains %q:\n%s", skip, content)
+				}
+			}
+		})
+	}
+}
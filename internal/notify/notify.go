@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"delguard/internal/logger"
+	"delguard/internal/utils"
+)
+
+// sendTimeout 发送单条通知的最长时间，避免通知程序卡住命令退出
+const sendTimeout = 5 * time.Second
+
+// Send 发送桌面通知
+func Send(title, message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	return send(ctx, title, message)
+}
+
+// Operation 记录一次耗时操作的进度，结束时按需发送完成通知
+type Operation struct {
+	kind      string
+	start     time.Time
+	items     int
+	bytes     int64
+	threshold time.Duration
+	enabled   bool
+	force     bool
+}
+
+// Start 开始记录操作
+// enabled对应ui.notifications，threshold为触发通知的最短耗时，force为true时无论耗时都发送
+func Start(kind string, enabled bool, threshold time.Duration, force bool) *Operation {
+	return &Operation{
+		kind:      kind,
+		start:     time.Now(),
+		threshold: threshold,
+		enabled:   enabled,
+		force:     force,
+	}
+}
+
+// Add 累加已处理的项目数和字节数
+func (o *Operation) Add(items int, bytes int64) {
+	if o == nil {
+		return
+	}
+	o.items += items
+	o.bytes += bytes
+}
+
+// Items 返回已处理的项目数
+func (o *Operation) Items() int {
+	if o == nil {
+		return 0
+	}
+	return o.items
+}
+
+// Finish 操作结束，耗时超过阈值或指定了强制通知时发送通知
+// 通知失败只记录调试日志，不影响命令结果
+func (o *Operation) Finish() {
+	if o == nil {
+		return
+	}
+
+	elapsed := time.Since(o.start)
+	if !o.force && (!o.enabled || elapsed < o.threshold) {
+		return
+	}
+
+	message := fmt.Sprintf("%s完成: %d 个项目, %s, 用时 %s",
+		o.kind, o.items, utils.FormatSize(o.bytes), elapsed.Round(time.Second))
+	if err := Send("DelGuard", message); err != nil {
+		logger.Debugf("发送通知失败: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package notify
+
+import (
+	"context"
+	"os/exec"
+)
+
+// send 通过osascript发送通知，标题和内容作为参数传入，无需转义
+func send(ctx context.Context, title, message string) error {
+	return exec.CommandContext(ctx, "osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, message).Run()
+}
@@ -0,0 +1,16 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// send 通过notify-send发送通知
+func send(ctx context.Context, title, message string) error {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return fmt.Errorf("未找到notify-send: %v", err)
+	}
+	return exec.CommandContext(ctx, path, "--app-name=DelGuard", title, message).Run()
+}
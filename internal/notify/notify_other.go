@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// send 当前平台不支持桌面通知
+func send(ctx context.Context, title, message string) error {
+	return fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"golang.org/x/sys/windows"
+)
+
+// toastScript 通过WinRT发送Toast通知，标题和内容从环境变量读取，避免转义问题
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$texts = $template.GetElementsByTagName('text')
+$texts.Item(0).AppendChild($template.CreateTextNode($env:DELGUARD_NOTIFY_TITLE)) > $null
+$texts.Item(1).AppendChild($template.CreateTextNode($env:DELGUARD_NOTIFY_MESSAGE)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('DelGuard').Show($toast)
+`
+
+// messageBeep user32.dll中的MessageBeep，Toast不可用时的回退
+var messageBeep = windows.NewLazySystemDLL("user32.dll").NewProc("MessageBeep")
+
+// send 发送Toast通知，失败时以系统提示音代替
+func send(ctx context.Context, title, message string) error {
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", toastScript)
+	cmd.Env = append(os.Environ(),
+		"DELGUARD_NOTIFY_TITLE="+title,
+		"DELGUARD_NOTIFY_MESSAGE="+message,
+	)
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	// MB_OK，返回0表示失败
+	if ok, _, beepErr := messageBeep.Call(0); ok == 0 {
+		return fmt.Errorf("发送通知失败: %v; 提示音失败: %v", err, beepErr)
+	}
+	return nil
+}
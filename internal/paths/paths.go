@@ -0,0 +1,219 @@
+package paths
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// LegacyEnv 非空时不使用XDG目录，也不迁移旧目录中的数据
+const LegacyEnv = "DELGUARD_LEGACY_DIRS"
+
+// PointerFile 迁移完成后留在旧目录中的说明文件，存在时表示旧目录中的数据已迁移
+const PointerFile = "MOVED_TO_XDG.txt"
+
+// migratedItems 从 ~/.delguard 迁移到状态目录的项目
+// 路径锁只在运行期间有效，回收站由回收站规范决定位置，二者都不迁移
+var migratedItems = []string{"reports", "telemetry.queue", "plugin_decisions.json", "share_trashes"}
+
+// LegacyDir 返回旧版本使用的数据目录 ~/.delguard
+func LegacyDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取用户主目录: %v", err)
+	}
+	return filepath.Join(homeDir, ".delguard"), nil
+}
+
+// xdgBase 返回Linux上设置的XDG基础目录，未设置、不是绝对路径或设置了DELGUARD_LEGACY_DIRS时返回空
+func xdgBase(env string) string {
+	if runtime.GOOS != "linux" || os.Getenv(LegacyEnv) != "" {
+		return ""
+	}
+	base := os.Getenv(env)
+	if !filepath.IsAbs(base) {
+		return ""
+	}
+	return base
+}
+
+// ConfigDir 返回配置目录，Linux上设置了XDG_CONFIG_HOME时为 $XDG_CONFIG_HOME/delguard，
+// 但新位置还没有配置文件而 ~/.config/delguard 中已有时继续使用后者
+func ConfigDir() string {
+	homeDir, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "DelGuard")
+	case "darwin":
+		return filepath.Join(homeDir, ".config", "delguard")
+	}
+
+	legacy := filepath.Join(homeDir, ".config", "delguard")
+	base := xdgBase("XDG_CONFIG_HOME")
+	if base == "" {
+		return legacy
+	}
+	dir := filepath.Join(base, "delguard")
+	if !exists(filepath.Join(dir, "config.yaml")) && exists(filepath.Join(legacy, "config.yaml")) {
+		return legacy
+	}
+	return dir
+}
+
+// StateDir 返回回执、统计队列等状态数据所在的目录
+// Linux上设置了XDG_STATE_HOME时为 $XDG_STATE_HOME/delguard；~/.delguard 中的数据尚未迁移时仍使用旧目录
+func StateDir() (string, error) {
+	legacy, err := LegacyDir()
+	if err != nil {
+		return "", err
+	}
+	base := xdgBase("XDG_STATE_HOME")
+	if base == "" || pendingMigration(legacy) {
+		return legacy, nil
+	}
+	return filepath.Join(base, "delguard"), nil
+}
+
+// CacheDir 返回可以随时删除的运行数据（如路径锁）所在的目录
+// Linux上设置了XDG_CACHE_HOME时为 $XDG_CACHE_HOME/delguard，否则与StateDir相同
+func CacheDir() (string, error) {
+	if base := xdgBase("XDG_CACHE_HOME"); base != "" {
+		return filepath.Join(base, "delguard"), nil
+	}
+	return StateDir()
+}
+
+// TrashDir 返回Linux上XDG Trash规范的主回收站目录，设置了XDG_DATA_HOME时为 $XDG_DATA_HOME/Trash，
+// 否则为 ~/.local/share/Trash。回收站位置由规范决定，不受DELGUARD_LEGACY_DIRS影响
+func TrashDir() string {
+	if base := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(base) {
+		return filepath.Join(base, "Trash")
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "Trash")
+}
+
+// LogDir 返回默认的日志目录，Linux上设置了XDG_STATE_HOME时为 $XDG_STATE_HOME/delguard/logs
+func LogDir() string {
+	homeDir, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "DelGuard", "logs")
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Logs", "DelGuard")
+	}
+	if base := xdgBase("XDG_STATE_HOME"); base != "" {
+		return filepath.Join(base, "delguard", "logs")
+	}
+	return filepath.Join(homeDir, ".local", "share", "delguard", "logs")
+}
+
+// pendingMigration 判断旧目录中是否有尚未迁移的数据
+func pendingMigration(legacy string) bool {
+	if exists(filepath.Join(legacy, PointerFile)) {
+		return false
+	}
+	for _, name := range migratedItems {
+		if exists(filepath.Join(legacy, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrateLegacy 设置了XDG_STATE_HOME时将 ~/.delguard 中的状态数据复制到新目录，
+// 完成后在旧目录中留下说明文件，之后不再迁移。返回本次迁移到的目录，没有需要迁移的数据时返回空
+// 复制失败时不写说明文件，继续使用旧目录并在下次启动时重试
+func MigrateLegacy() (string, error) {
+	base := xdgBase("XDG_STATE_HOME")
+	if base == "" {
+		return "", nil
+	}
+	legacy, err := LegacyDir()
+	if err != nil || !pendingMigration(legacy) {
+		return "", err
+	}
+
+	target := filepath.Join(base, "delguard")
+	for _, name := range migratedItems {
+		src := filepath.Join(legacy, name)
+		if !exists(src) {
+			continue
+		}
+		if err := copyTree(src, filepath.Join(target, name)); err != nil {
+			return "", fmt.Errorf("迁移 %s 失败: %v", src, err)
+		}
+	}
+
+	note := fmt.Sprintf("DelGuard的状态数据已复制到 %s，此目录中的副本不再更新。\n"+
+		"回收站(trash)不会迁移。设置环境变量 %s=1 可继续使用此目录。\n", target, LegacyEnv)
+	if err := os.WriteFile(filepath.Join(legacy, PointerFile), []byte(note), 0644); err != nil {
+		return "", fmt.Errorf("写入迁移说明失败: %v", err)
+	}
+	return target, nil
+}
+
+// copyTree 复制文件或目录，保留权限位和符号链接
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			os.Remove(target)
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+// copyFile 复制单个文件
+func copyFile(src, dst string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// exists 判断路径是否存在（不跟随符号链接）
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
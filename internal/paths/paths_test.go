@@ -0,0 +1,252 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// useTempHome 将主目录指向临时目录并清除XDG变量
+func useTempHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", LegacyEnv} {
+		t.Setenv(env, "")
+	}
+	return home
+}
+
+// requireLinux XDG基础目录只在Linux上生效
+func requireLinux(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG base directories only apply on Linux")
+	}
+}
+
+// touch 创建文件及其上级目录
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(filepath.Base(path)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestXDGDirectories(t *testing.T) {
+	requireLinux(t)
+	xdg := "/xdg"
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config string
+		state  string
+		cache  string
+		logs   string
+		trash  string
+	}{
+		{
+			name:   "defaults",
+			config: "~/.config/delguard",
+			state:  "~/.delguard",
+			cache:  "~/.delguard",
+			logs:   "~/.local/share/delguard/logs",
+			trash:  "~/.local/share/Trash",
+		},
+		{
+			name: "all set",
+			env: map[string]string{
+				"XDG_CONFIG_HOME": xdg + "/config",
+				"XDG_STATE_HOME":  xdg + "/state",
+				"XDG_CACHE_HOME":  xdg + "/cache",
+				"XDG_DATA_HOME":   xdg + "/data",
+			},
+			config: "/xdg/config/delguard",
+			state:  "/xdg/state/delguard",
+			cache:  "/xdg/cache/delguard",
+			logs:   "/xdg/state/delguard/logs",
+			trash:  "/xdg/data/Trash",
+		},
+		{
+			name:   "cache falls back to state",
+			env:    map[string]string{"XDG_STATE_HOME": xdg + "/state"},
+			config: "~/.config/delguard",
+			state:  "/xdg/state/delguard",
+			cache:  "/xdg/state/delguard",
+			logs:   "/xdg/state/delguard/logs",
+			trash:  "~/.local/share/Trash",
+		},
+		{
+			// 规范要求XDG变量为绝对路径，相对路径视为未设置
+			name: "relative paths ignored",
+			env: map[string]string{
+				"XDG_CONFIG_HOME": "config",
+				"XDG_STATE_HOME":  "state",
+				"XDG_CACHE_HOME":  "cache",
+				"XDG_DATA_HOME":   "data",
+			},
+			config: "~/.config/delguard",
+			state:  "~/.delguard",
+			cache:  "~/.delguard",
+			logs:   "~/.local/share/delguard/logs",
+			trash:  "~/.local/share/Trash",
+		},
+		{
+			// 旧目录设置不影响回收站，回收站位置由规范决定
+			name: "legacy dirs",
+			env: map[string]string{
+				LegacyEnv:         "1",
+				"XDG_CONFIG_HOME": xdg + "/config",
+				"XDG_STATE_HOME":  xdg + "/state",
+				"XDG_CACHE_HOME":  xdg + "/cache",
+				"XDG_DATA_HOME":   xdg + "/data",
+			},
+			config: "~/.config/delguard",
+			state:  "~/.delguard",
+			cache:  "~/.delguard",
+			logs:   "~/.local/share/delguard/logs",
+			trash:  "/xdg/data/Trash",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := useTempHome(t)
+			for env, value := range tt.env {
+				t.Setenv(env, value)
+			}
+			expand := func(path string) string {
+				if len(path) > 0 && path[0] == '~' {
+					return filepath.Join(home, path[1:])
+				}
+				return path
+			}
+
+			state, err := StateDir()
+			if err != nil {
+				t.Fatalf("StateDir: %v", err)
+			}
+			cache, err := CacheDir()
+			if err != nil {
+				t.Fatalf("CacheDir: %v", err)
+			}
+			for name, got := range map[string][2]string{
+				"ConfigDir": {ConfigDir(), tt.config},
+				"StateDir":  {state, tt.state},
+				"CacheDir":  {cache, tt.cache},
+				"LogDir":    {LogDir(), tt.logs},
+				"TrashDir":  {TrashDir(), tt.trash},
+			} {
+				if want := expand(got[1]); got[0] != want {
+					t.Errorf("%s() = %s, want %s", name, got[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigDirKeepsExistingConfig(t *testing.T) {
+	requireLinux(t)
+	home := useTempHome(t)
+	base := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", base)
+	legacy := filepath.Join(home, ".config", "delguard")
+	touch(t, filepath.Join(legacy, "config.yaml"))
+
+	if got := ConfigDir(); got != legacy {
+		t.Errorf("ConfigDir() = %s, want the existing %s", got, legacy)
+	}
+	touch(t, filepath.Join(base, "delguard", "config.yaml"))
+	if got, want := ConfigDir(), filepath.Join(base, "delguard"); got != want {
+		t.Errorf("ConfigDir() = %s, want %s once it has a config", got, want)
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	requireLinux(t)
+	home := useTempHome(t)
+	base := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", base)
+	legacy := filepath.Join(home, ".delguard")
+	touch(t, filepath.Join(legacy, "reports", "2024-03-01.json"))
+	touch(t, filepath.Join(legacy, "plugin_decisions.json"))
+	touch(t, filepath.Join(legacy, "locks", "abc.lock"))
+	touch(t, filepath.Join(legacy, "trash", "kept.txt"))
+	if err := os.Symlink("2024-03-01.json", filepath.Join(legacy, "reports", "latest.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 迁移完成前继续使用旧目录
+	if dir, err := StateDir(); err != nil || dir != legacy {
+		t.Errorf("StateDir() before migration = %s, %v; want %s", dir, err, legacy)
+	}
+
+	target := filepath.Join(base, "delguard")
+	dir, err := MigrateLegacy()
+	if err != nil || dir != target {
+		t.Fatalf("MigrateLegacy() = %s, %v; want %s", dir, err, target)
+	}
+	for _, name := range []string{"reports/2024-03-01.json", "plugin_decisions.json"} {
+		if data, err := os.ReadFile(filepath.Join(target, name)); err != nil || string(data) != filepath.Base(name) {
+			t.Errorf("%s = %q, %v; want it copied", name, data, err)
+		}
+	}
+	if link, err := os.Readlink(filepath.Join(target, "reports", "latest.json")); err != nil || link != "2024-03-01.json" {
+		t.Errorf("symlink = %q, %v; want it preserved", link, err)
+	}
+	// 路径锁和回收站不迁移
+	for _, name := range []string{"locks", "trash"} {
+		if _, err := os.Lstat(filepath.Join(target, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was migrated: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(legacy, PointerFile)); err != nil {
+		t.Errorf("pointer file missing: %v", err)
+	}
+
+	if dir, err := StateDir(); err != nil || dir != target {
+		t.Errorf("StateDir() after migration = %s, %v; want %s", dir, err, target)
+	}
+	if dir, err := MigrateLegacy(); err != nil || dir != "" {
+		t.Errorf("second MigrateLegacy() = %s, %v; want nothing to do", dir, err)
+	}
+}
+
+func TestMigrateLegacySkipped(t *testing.T) {
+	requireLinux(t)
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"without XDG_STATE_HOME", nil},
+		{"legacy dirs requested", map[string]string{LegacyEnv: "1", "XDG_STATE_HOME": "/xdg/state"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := useTempHome(t)
+			for env, value := range tt.env {
+				t.Setenv(env, value)
+			}
+			touch(t, filepath.Join(home, ".delguard", "telemetry.queue"))
+
+			if dir, err := MigrateLegacy(); err != nil || dir != "" {
+				t.Errorf("MigrateLegacy() = %s, %v; want nothing migrated", dir, err)
+			}
+			if _, err := os.Stat(filepath.Join(home, ".delguard", PointerFile)); !os.IsNotExist(err) {
+				t.Errorf("pointer file written: %v", err)
+			}
+		})
+	}
+}
+
+func TestTrashDirIgnoresRelativeDataHome(t *testing.T) {
+	home := useTempHome(t)
+	t.Setenv("XDG_DATA_HOME", "relative")
+	if got, want := TrashDir(), filepath.Join(home, ".local", "share", "Trash"); got != want {
+		t.Errorf("TrashDir() = %s, want %s", got, want)
+	}
+}
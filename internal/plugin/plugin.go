@@ -0,0 +1,279 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"delguard/internal/config"
+)
+
+// Decision 插件对一次删除的判定
+type Decision string
+
+const (
+	// DecisionAllow 允许删除（退出码0）
+	DecisionAllow Decision = "allow"
+	// DecisionBlock 阻止删除（退出码1）
+	DecisionBlock Decision = "block"
+	// DecisionConfirm 需要用户确认（退出码2）
+	DecisionConfirm Decision = "confirm"
+	// DecisionError 插件执行失败、超时或返回未知的退出码，按阻止处理
+	DecisionError Decision = "error"
+)
+
+// defaultHookTimeout 未配置时单个插件的最长执行时间
+const defaultHookTimeout = 10 * time.Second
+
+// maxReasonLength 插件输出的原因最多保留的字符数
+const maxReasonLength = 500
+
+// Plugin 插件目录中发现的保护规则插件
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Result 单个插件的判定结果
+type Result struct {
+	Plugin   string    `json:"plugin"`
+	Path     string    `json:"path"`
+	Decision Decision  `json:"decision"`
+	Reason   string    `json:"reason,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Context 通过stdin传给插件的JSON上下文
+type Context struct {
+	Operation   string `json:"operation"`
+	Path        string `json:"path"`
+	IsDirectory bool   `json:"is_directory"`
+	Size        int64  `json:"size"`
+	WorkingDir  string `json:"working_dir"`
+	PID         int    `json:"pid"`
+}
+
+// Runner 依次调用所有插件，同一路径在一次运行中只判定一次
+type Runner struct {
+	dir     string
+	timeout time.Duration
+	plugins []Plugin
+
+	mu    sync.Mutex
+	cache map[string]Result
+	last  map[string]Result
+}
+
+// NewRunner 根据配置发现插件，插件目录不存在或为空时返回nil
+func NewRunner(cfg *config.Config) (*Runner, error) {
+	dir, timeout := settings(cfg)
+	if dir == "" {
+		return nil, nil
+	}
+
+	plugins, err := Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(plugins) == 0 {
+		return nil, nil
+	}
+
+	return &Runner{
+		dir:     dir,
+		timeout: timeout,
+		plugins: plugins,
+		cache:   make(map[string]Result),
+		last:    make(map[string]Result),
+	}, nil
+}
+
+// Directory 返回配置的插件目录
+func Directory(cfg *config.Config) string {
+	dir, _ := settings(cfg)
+	return dir
+}
+
+// settings 读取插件目录和超时设置
+func settings(cfg *config.Config) (string, time.Duration) {
+	dir := ""
+	timeout := defaultHookTimeout
+	if cfg != nil {
+		dir = cfg.Integration.PluginDirectory
+		if cfg.Integration.HookTimeout > 0 {
+			timeout = time.Duration(cfg.Integration.HookTimeout) * time.Second
+		}
+	}
+	if strings.HasPrefix(dir, "~") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(homeDir, strings.TrimPrefix(dir, "~"))
+		}
+	}
+	return dir, timeout
+}
+
+// Discover 查找插件目录中的protect-*.sh和protect-*.exe，按名称排序
+func Discover(dir string) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取插件目录失败: %v", err)
+	}
+
+	var plugins []Plugin
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if entry.IsDir() || !strings.HasPrefix(name, "protect-") || (ext != ".sh" && ext != ".exe") {
+			continue
+		}
+		plugins = append(plugins, Plugin{Name: name, Path: filepath.Join(dir, name)})
+	}
+
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// Plugins 返回已发现的插件
+func (r *Runner) Plugins() []Plugin {
+	if r == nil {
+		return nil
+	}
+	return r.plugins
+}
+
+// Check 依次调用所有插件判定是否允许删除
+// 任一插件阻止或执行失败时立即返回该结果，无法确认插件的判定时不删除；有插件要求确认时返回确认结果
+func (r *Runner) Check(path string) Result {
+	if r == nil {
+		return Result{Path: path, Decision: DecisionAllow}
+	}
+
+	r.mu.Lock()
+	if cached, ok := r.cache[path]; ok {
+		r.mu.Unlock()
+		return cached
+	}
+	r.mu.Unlock()
+
+	input := newContext(path)
+	decision := Result{Path: path, Decision: DecisionAllow, Time: time.Now()}
+	for _, plugin := range r.plugins {
+		result := r.run(plugin, path, input)
+		r.mu.Lock()
+		r.last[plugin.Name] = result
+		r.mu.Unlock()
+
+		switch result.Decision {
+		case DecisionBlock, DecisionError:
+			decision = result
+		case DecisionConfirm:
+			if decision.Decision == DecisionAllow {
+				decision = result
+			}
+		}
+		if decision.Decision == DecisionBlock || decision.Decision == DecisionError {
+			break
+		}
+	}
+
+	r.mu.Lock()
+	r.cache[path] = decision
+	r.mu.Unlock()
+	return decision
+}
+
+// run 执行单个插件并解析退出码
+func (r *Runner) run(plugin Plugin, path string, input []byte) Result {
+	result := Result{Plugin: plugin.Name, Path: path, Time: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	name, args := plugin.command(path)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	// 超时后插件派生的子进程可能仍持有输出管道，不再等待
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	result.Reason = truncateReason(stdout.String())
+
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Decision = DecisionError
+		result.Reason = fmt.Sprintf("执行超时 (%v)", r.timeout)
+		return result
+	}
+	if err == nil {
+		result.Decision = DecisionAllow
+		return result
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		result.Decision = DecisionError
+		result.Reason = fmt.Sprintf("执行失败: %v", err)
+		return result
+	}
+
+	switch exitErr.ExitCode() {
+	case -1:
+		// 插件被信号终止，没有退出码
+		result.Decision = DecisionError
+		result.Reason = fmt.Sprintf("执行失败: %v", err)
+	case 1:
+		result.Decision = DecisionBlock
+	case 2:
+		result.Decision = DecisionConfirm
+	default:
+		result.Decision = DecisionError
+		result.Reason = fmt.Sprintf("未知的退出码 %d: %s", exitErr.ExitCode(), result.Reason)
+	}
+	return result
+}
+
+// command 返回执行插件的命令，Windows上或没有执行权限的.sh脚本通过sh执行
+func (p Plugin) command(path string) (string, []string) {
+	if strings.EqualFold(filepath.Ext(p.Path), ".sh") {
+		info, err := os.Stat(p.Path)
+		if runtime.GOOS == "windows" || err != nil || info.Mode().Perm()&0111 == 0 {
+			return "sh", []string{p.Path, path}
+		}
+	}
+	return p.Path, []string{path}
+}
+
+// newContext 生成传给插件的JSON上下文
+func newContext(path string) []byte {
+	ctx := Context{Operation: "delete", Path: path, PID: os.Getpid()}
+	if info, err := os.Lstat(path); err == nil {
+		ctx.IsDirectory = info.IsDir()
+		ctx.Size = info.Size()
+	}
+	ctx.WorkingDir, _ = os.Getwd()
+
+	data, _ := json.Marshal(ctx)
+	return data
+}
+
+// truncateReason 整理插件输出作为原因
+func truncateReason(output string) string {
+	reason := strings.TrimSpace(output)
+	if runes := []rune(reason); len(runes) > maxReasonLength {
+		reason = string(runes[:maxReasonLength]) + "..."
+	}
+	return reason
+}
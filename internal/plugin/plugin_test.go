@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"delguard/internal/config"
+)
+
+// writePlugin 在dir中写入可执行的shell插件
+func writePlugin(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("sh unavailable: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestRunner 加载dir中的插件
+func newTestRunner(t *testing.T, dir string) *Runner {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Integration.PluginDirectory = dir
+	runner, err := NewRunner(cfg)
+	if err != nil || runner == nil {
+		t.Fatalf("NewRunner = %v, %v", runner, err)
+	}
+	return runner
+}
+
+// writeTarget 创建待判定的文件
+func writeTarget(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "target.txt")
+	if err := os.WriteFile(path, []byte("payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"protect-b.sh", "protect-a.exe", "protect-c.py", "other.sh", "readme.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "protect-dir.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	var names []string
+	for _, p := range plugins {
+		names = append(names, p.Name)
+	}
+	if want := []string{"protect-a.exe", "protect-b.sh"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Discover = %v, want %v", names, want)
+	}
+
+	if plugins, err := Discover(filepath.Join(dir, "missing")); plugins != nil || err != nil {
+		t.Errorf("Discover(missing) = %v, %v; want nil, nil", plugins, err)
+	}
+}
+
+func TestNewRunnerWithoutPlugins(t *testing.T) {
+	if runner, err := NewRunner(&config.Config{}); runner != nil || err != nil {
+		t.Errorf("NewRunner(no directory) = %v, %v; want nil, nil", runner, err)
+	}
+	cfg := &config.Config{}
+	cfg.Integration.PluginDirectory = t.TempDir()
+	if runner, err := NewRunner(cfg); runner != nil || err != nil {
+		t.Errorf("NewRunner(empty directory) = %v, %v; want nil, nil", runner, err)
+	}
+
+	var runner *Runner
+	if result := runner.Check("/srv/data"); result.Decision != DecisionAllow {
+		t.Errorf("nil Runner.Check = %s, want allow", result.Decision)
+	}
+}
+
+func TestCheckParsesExitCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		want   Decision
+		reason string
+	}{
+		{"allow", "exit 0\n", DecisionAllow, ""},
+		{"block", "echo 'production data'\nexit 1\n", DecisionBlock, "production data"},
+		{"confirm", "echo 'large file'\nexit 2\n", DecisionConfirm, "large file"},
+		{"unknown exit code", "echo 'odd'\nexit 7\n", DecisionError, "未知的退出码 7: odd"},
+		{"crash", "kill -9 $$\n", DecisionError, "执行失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePlugin(t, dir, "protect-rule.sh", tt.body)
+
+			result := newTestRunner(t, dir).Check(writeTarget(t))
+			if result.Decision != tt.want {
+				t.Errorf("Decision = %s (%s), want %s", result.Decision, result.Reason, tt.want)
+			}
+			if !strings.HasPrefix(result.Reason, tt.reason) {
+				t.Errorf("Reason = %q, want prefix %q", result.Reason, tt.reason)
+			}
+			if tt.want != DecisionAllow && result.Plugin != "protect-rule.sh" {
+				t.Errorf("Plugin = %q, want protect-rule.sh", result.Plugin)
+			}
+		})
+	}
+}
+
+func TestCheckTruncatesReason(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "protect-noisy.sh", "head -c 2000 /dev/zero | tr '\\0' x\nexit 1\n")
+
+	result := newTestRunner(t, dir).Check(writeTarget(t))
+	if want := strings.Repeat("x", maxReasonLength) + "..."; result.Reason != want {
+		t.Errorf("Reason has %d characters, want %d", len(result.Reason), len(want))
+	}
+}
+
+func TestCheckTimeoutFailsClosed(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "protect-slow.sh", "exec sleep 5\n")
+	runner := newTestRunner(t, dir)
+	runner.timeout = 200 * time.Millisecond
+
+	start := time.Now()
+	result := runner.Check(writeTarget(t))
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Check waited %v for a plugin with a %v timeout", elapsed, runner.timeout)
+	}
+	if result.Decision != DecisionError || !strings.Contains(result.Reason, "执行超时") {
+		t.Errorf("Check = %s (%s), want a timeout error", result.Decision, result.Reason)
+	}
+}
+
+func TestCheckCombinesPlugins(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugins map[string]string
+		want    Decision
+		from    string
+	}{
+		{"all allow", map[string]string{"protect-a.sh": "exit 0\n", "protect-b.sh": "exit 0\n"}, DecisionAllow, ""},
+		{"confirm wins over allow", map[string]string{"protect-a.sh": "exit 0\n", "protect-b.sh": "exit 2\n"}, DecisionConfirm, "protect-b.sh"},
+		{"block wins over confirm", map[string]string{"protect-a.sh": "exit 2\n", "protect-b.sh": "exit 1\n"}, DecisionBlock, "protect-b.sh"},
+		{"failure is not ignored", map[string]string{"protect-a.sh": "exit 0\n", "protect-b.sh": "exit 9\n"}, DecisionError, "protect-b.sh"},
+		{"failure wins over confirm", map[string]string{"protect-a.sh": "exit 2\n", "protect-b.sh": "kill -9 $$\n"}, DecisionError, "protect-b.sh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, body := range tt.plugins {
+				writePlugin(t, dir, name, body)
+			}
+
+			result := newTestRunner(t, dir).Check(writeTarget(t))
+			if result.Decision != tt.want || result.Plugin != tt.from {
+				t.Errorf("Check = %s from %q, want %s from %q", result.Decision, result.Plugin, tt.want, tt.from)
+			}
+		})
+	}
+}
+
+func TestCheckStopsAtFirstRefusal(t *testing.T) {
+	for _, body := range []string{"exit 1\n", "exit 5\n"} {
+		dir := t.TempDir()
+		marker := filepath.Join(dir, "ran")
+		writePlugin(t, dir, "protect-a.sh", body)
+		writePlugin(t, dir, "protect-b.sh", "touch '"+marker+"'\nexit 0\n")
+
+		if result := newTestRunner(t, dir).Check(writeTarget(t)); result.Plugin != "protect-a.sh" {
+			t.Errorf("%q: Check = %s from %q, want protect-a.sh", body, result.Decision, result.Plugin)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			t.Errorf("%q: plugin after the refusal still ran", body)
+		}
+	}
+}
+
+func TestCheckCachesPerPath(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	writePlugin(t, dir, "protect-count.sh", "echo \"$1\" >> '"+calls+"'\nexit 0\n")
+	runner := newTestRunner(t, dir)
+
+	first, second := writeTarget(t), writeTarget(t)
+	for _, path := range []string{first, first, second} {
+		runner.Check(path)
+	}
+
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Fields(string(data)), []string{first, second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("plugin called for %v, want %v", got, want)
+	}
+}
+
+func TestPluginReceivesContext(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	writePlugin(t, dir, "protect-record.sh", "cat > '"+input+"'\nexit 0\n")
+
+	target := writeTarget(t)
+	newTestRunner(t, dir).Check(target)
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Context
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("plugin input %q is not JSON: %v", data, err)
+	}
+	if got.Operation != "delete" || got.Path != target || got.Size != int64(len("payload")) || got.IsDirectory || got.PID != os.Getpid() {
+		t.Errorf("plugin input = %+v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"delguard/internal/paths"
+)
+
+// stateFile 保存各插件最近一次判定的文件
+func stateFile() (string, error) {
+	stateDir, err := paths.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "plugin_decisions.json"), nil
+}
+
+// LoadDecisions 读取各插件最近一次的判定，文件不存在时返回空表
+func LoadDecisions() (map[string]Result, error) {
+	decisions := make(map[string]Result)
+
+	path, err := stateFile()
+	if err != nil {
+		return decisions, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return decisions, nil
+		}
+		return decisions, fmt.Errorf("读取插件判定记录失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &decisions); err != nil {
+		return make(map[string]Result), fmt.Errorf("解析插件判定记录失败: %v", err)
+	}
+	return decisions, nil
+}
+
+// SaveDecisions 将本次运行中各插件的最近判定合并写入记录文件
+func (r *Runner) SaveDecisions() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	if len(r.last) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	decisions, _ := LoadDecisions()
+	for name, result := range r.last {
+		decisions[name] = result
+	}
+	r.mu.Unlock()
+
+	path, err := stateFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(decisions, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("写入插件判定记录失败: %v", err)
+	}
+	return os.Rename(tempFile, path)
+}
@@ -0,0 +1,116 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useTempState 将状态目录指向临时的 ~/.delguard
+func useTempState(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("DELGUARD_LEGACY_DIRS", "1")
+	path, err := stateFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDecisionsWithoutState(t *testing.T) {
+	useTempState(t)
+	decisions, err := LoadDecisions()
+	if err != nil || decisions == nil || len(decisions) != 0 {
+		t.Errorf("LoadDecisions = %v, %v; want an empty map", decisions, err)
+	}
+}
+
+func TestLoadDecisionsRejectsCorruptState(t *testing.T) {
+	path := useTempState(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	decisions, err := LoadDecisions()
+	if err == nil {
+		t.Error("LoadDecisions accepted a corrupt state file")
+	}
+	if decisions == nil || len(decisions) != 0 {
+		t.Errorf("LoadDecisions = %v, want an empty map", decisions)
+	}
+}
+
+func TestSaveDecisionsMergesRuns(t *testing.T) {
+	path := useTempState(t)
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first := &Runner{last: map[string]Result{
+		"protect-a.sh": {Plugin: "protect-a.sh", Path: "/srv/a", Decision: DecisionBlock, Reason: "kept", Time: at},
+		"protect-b.sh": {Plugin: "protect-b.sh", Path: "/srv/a", Decision: DecisionAllow, Time: at},
+	}}
+	if err := first.SaveDecisions(); err != nil {
+		t.Fatalf("SaveDecisions: %v", err)
+	}
+	second := &Runner{last: map[string]Result{
+		"protect-b.sh": {Plugin: "protect-b.sh", Path: "/srv/b", Decision: DecisionError, Reason: "执行超时 (10s)", Time: at.Add(time.Hour)},
+	}}
+	if err := second.SaveDecisions(); err != nil {
+		t.Fatalf("SaveDecisions: %v", err)
+	}
+
+	decisions, err := LoadDecisions()
+	if err != nil {
+		t.Fatalf("LoadDecisions: %v", err)
+	}
+	if a := decisions["protect-a.sh"]; a.Decision != DecisionBlock || a.Reason != "kept" || !a.Time.Equal(at) {
+		t.Errorf("protect-a.sh = %+v, want the first run's block", a)
+	}
+	if b := decisions["protect-b.sh"]; b.Decision != DecisionError || b.Path != "/srv/b" {
+		t.Errorf("protect-b.sh = %+v, want the second run's error", b)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary state file left behind: %v", err)
+	}
+}
+
+func TestSaveDecisionsWithoutRuns(t *testing.T) {
+	path := useTempState(t)
+
+	var runner *Runner
+	if err := runner.SaveDecisions(); err != nil {
+		t.Errorf("nil Runner.SaveDecisions: %v", err)
+	}
+	if err := (&Runner{last: map[string]Result{}}).SaveDecisions(); err != nil {
+		t.Errorf("SaveDecisions: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("state file written without any decisions: %v", err)
+	}
+}
+
+func TestCheckRecordsLastDecision(t *testing.T) {
+	useTempState(t)
+	dir := t.TempDir()
+	writePlugin(t, dir, "protect-fail.sh", "exit 3\n")
+	runner := newTestRunner(t, dir)
+	target := writeTarget(t)
+	runner.Check(target)
+
+	if err := runner.SaveDecisions(); err != nil {
+		t.Fatalf("SaveDecisions: %v", err)
+	}
+	decisions, err := LoadDecisions()
+	if err != nil {
+		t.Fatalf("LoadDecisions: %v", err)
+	}
+	if got := decisions["protect-fail.sh"]; got.Decision != DecisionError || got.Path != target {
+		t.Errorf("recorded decision = %+v, want the failure for %s", got, target)
+	}
+}
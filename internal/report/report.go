@@ -0,0 +1,357 @@
+package report
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 项目处理结果
+const (
+	OutcomeTrashed  = "trashed"
+	OutcomeShredded = "shredded"
+	OutcomeDeleted  = "deleted" // 未经过回收站直接永久删除
+	OutcomeFailed   = "failed"
+	OutcomeSkipped  = "skipped"
+	// OutcomeQuarantined 永久删除前校验发现内容损坏，移入回收站的隔离目录而没有删除
+	OutcomeQuarantined = "quarantined"
+)
+
+// 回执中确认提示的回答方式
+const (
+	ConfirmedByUser    = "user"            // 用户明确回答
+	ConfirmedByTimeout = "timeout_default" // 等待输入超时后按默认回答
+)
+
+// Receipt 一次删除操作的回执，操作结束时一次性写入磁盘
+type Receipt struct {
+	ID         string    `json:"id"`
+	Operation  string    `json:"operation"` // delete、shred、purge或prune
+	User       string    `json:"user"`
+	Host       string    `json:"host,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Items      []Item    `json:"items"`
+	Summary    Summary   `json:"summary"`
+	// Confirmation 确认提示的回答方式，没有提示时为空
+	Confirmation string `json:"confirmation,omitempty"`
+}
+
+// Item 回执中的单个项目
+type Item struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	IsDirectory bool   `json:"is_directory,omitempty"`
+	Hash        string `json:"hash,omitempty"`       // 回收站元数据中记录的SHA256，不可用时为空
+	TrashPath   string `json:"trash_path,omitempty"` // 移入回收站后的位置
+	Outcome     string `json:"outcome"`
+	Reason      string `json:"reason,omitempty"`   // 失败或跳过的原因
+	Elevated    bool   `json:"elevated,omitempty"` // 是否由以管理员身份重新运行的进程处理
+	Verify      string `json:"verify,omitempty"`   // 永久删除前重新校验内容的结果: ok、corrupt或unhashed
+}
+
+// Summary 回执的汇总信息
+type Summary struct {
+	Total       int   `json:"total"`
+	Trashed     int   `json:"trashed"`
+	Shredded    int   `json:"shredded"`
+	Deleted     int   `json:"deleted"`
+	Failed      int   `json:"failed"`
+	Skipped     int   `json:"skipped"`
+	Quarantined int   `json:"quarantined,omitempty"` // 内容损坏而隔离的项目数
+	Bytes       int64 `json:"bytes"`                 // 成功处理的字节数
+}
+
+// New 创建新的操作回执
+func New(operation string) *Receipt {
+	host, _ := os.Hostname()
+	return &Receipt{
+		ID:        newID(),
+		Operation: operation,
+		User:      currentUser(),
+		Host:      host,
+		StartedAt: time.Now(),
+		Items:     []Item{},
+	}
+}
+
+// Add 记录一个项目的处理结果，receipt为nil时不做任何事
+func (r *Receipt) Add(item Item) {
+	if r == nil {
+		return
+	}
+	r.Items = append(r.Items, item)
+}
+
+// Finish 结束操作并汇总结果
+func (r *Receipt) Finish() {
+	r.FinishedAt = time.Now()
+	r.Summary = Summary{Total: len(r.Items)}
+	for _, item := range r.Items {
+		switch item.Outcome {
+		case OutcomeTrashed:
+			r.Summary.Trashed++
+			r.Summary.Bytes += item.Size
+		case OutcomeShredded:
+			r.Summary.Shredded++
+			r.Summary.Bytes += item.Size
+		case OutcomeDeleted:
+			r.Summary.Deleted++
+			r.Summary.Bytes += item.Size
+		case OutcomeFailed:
+			r.Summary.Failed++
+		case OutcomeSkipped:
+			r.Summary.Skipped++
+		case OutcomeQuarantined:
+			r.Summary.Quarantined++
+		}
+	}
+}
+
+// Write 将回执写入dir/<时间>-<ID>.json，返回文件路径
+func (r *Receipt) Write(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("创建回执目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化回执失败: %v", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", r.StartedAt.Format("20060102-150405"), r.ID))
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return "", fmt.Errorf("写入回执失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("写入回执失败: %v", err)
+	}
+	return path, nil
+}
+
+// Load 按操作ID读取回执，ID可以是唯一的前缀
+func Load(dir, id string) (*Receipt, error) {
+	paths, err := receiptFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, path := range paths {
+		if strings.HasPrefix(receiptID(path), id) {
+			matches = append(matches, path)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("未找到操作 %s 的回执", id)
+	case 1:
+		return readReceipt(matches[0])
+	default:
+		return nil, fmt.Errorf("操作ID %s 不唯一，匹配到 %d 个回执", id, len(matches))
+	}
+}
+
+// List 列出since之后开始的操作回执，按时间先后排序
+func List(dir string, since time.Time) ([]*Receipt, error) {
+	paths, err := receiptFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var receipts []*Receipt
+	for _, path := range paths {
+		receipt, err := readReceipt(path)
+		if err != nil {
+			continue
+		}
+		if receipt.StartedAt.Before(since) {
+			continue
+		}
+		receipts = append(receipts, receipt)
+	}
+	sort.Slice(receipts, func(i, j int) bool { return receipts[i].StartedAt.Before(receipts[j].StartedAt) })
+	return receipts, nil
+}
+
+// Each 按文件名（即开始时间）顺序逐个读取since之后开始的回执，每次只在内存中保留一份回执
+// 无法解析的回执被跳过；fn返回错误时停止并返回该错误
+func Each(dir string, since time.Time, fn func(*Receipt) error) error {
+	paths, err := receiptFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		receipt, err := readReceipt(path)
+		if err != nil || receipt.StartedAt.Before(since) {
+			continue
+		}
+		if err := fn(receipt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record 审计导出中的一行，对应回执中的一个项目
+type Record struct {
+	Time         time.Time `json:"time"`
+	OperationID  string    `json:"operation_id"`
+	Operation    string    `json:"operation"`
+	User         string    `json:"user"`
+	Host         string    `json:"host,omitempty"`
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	IsDirectory  bool      `json:"is_directory"`
+	Hash         string    `json:"hash,omitempty"`
+	TrashPath    string    `json:"trash_path,omitempty"`
+	Outcome      string    `json:"outcome"`
+	Reason       string    `json:"reason,omitempty"`
+	Elevated     bool      `json:"elevated"`
+	Confirmation string    `json:"confirmation,omitempty"`
+	Verify       string    `json:"verify,omitempty"`
+}
+
+// RecordHeader CSV导出的列名，与Record.Fields的顺序一致
+var RecordHeader = []string{"time", "operation_id", "operation", "user", "host", "path", "size", "is_directory", "hash", "trash_path", "outcome", "reason", "elevated", "confirmation", "verify"}
+
+// Fields 按RecordHeader的顺序返回记录的各列
+func (r Record) Fields() []string {
+	return []string{
+		r.Time.Format(time.RFC3339),
+		r.OperationID,
+		r.Operation,
+		r.User,
+		r.Host,
+		r.Path,
+		strconv.FormatInt(r.Size, 10),
+		strconv.FormatBool(r.IsDirectory),
+		r.Hash,
+		r.TrashPath,
+		r.Outcome,
+		r.Reason,
+		strconv.FormatBool(r.Elevated),
+		r.Confirmation,
+		r.Verify,
+	}
+}
+
+// Records 将回执展开为每个项目一行的审计记录
+func (r *Receipt) Records() []Record {
+	records := make([]Record, 0, len(r.Items))
+	for _, item := range r.Items {
+		records = append(records, Record{
+			Time:         r.StartedAt,
+			OperationID:  r.ID,
+			Operation:    r.Operation,
+			User:         r.User,
+			Host:         r.Host,
+			Path:         item.Path,
+			Size:         item.Size,
+			IsDirectory:  item.IsDirectory,
+			Hash:         item.Hash,
+			TrashPath:    item.TrashPath,
+			Outcome:      item.Outcome,
+			Reason:       item.Reason,
+			Elevated:     item.Elevated,
+			Confirmation: r.Confirmation,
+			Verify:       item.Verify,
+		})
+	}
+	return records
+}
+
+// Prune 删除修改时间早于maxAge天的回执，maxAge不大于0时保留全部，返回删除的数量
+func Prune(dir string, maxAge int) (int, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	paths, err := receiptFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -maxAge)
+	removed := 0
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// receiptFiles 返回目录中的所有回执文件，目录不存在时返回空列表
+func receiptFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取回执目录失败: %v", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	return paths, nil
+}
+
+// receiptID 从回执文件名中取出操作ID
+func receiptID(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".json")
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// readReceipt 读取并解析回执文件
+func readReceipt(path string) (*Receipt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取回执失败: %v", err)
+	}
+	var receipt Receipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		return nil, fmt.Errorf("解析回执 %s 失败: %v", filepath.Base(path), err)
+	}
+	return &receipt, nil
+}
+
+// newID 生成8位十六进制操作ID
+func newID() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%08x", uint32(time.Now().UnixNano()))
+	}
+	return hex.EncodeToString(buf)
+}
+
+// currentUser 返回执行操作的用户名
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeReceipt 在dir中写入一份开始于started的回执
+func writeReceipt(t *testing.T, dir, id, operation string, started time.Time, items ...Item) *Receipt {
+	t.Helper()
+	receipt := &Receipt{ID: id, Operation: operation, User: "alice", Host: "workstation", StartedAt: started, Items: items}
+	receipt.Finish()
+	if _, err := receipt.Write(dir); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return receipt
+}
+
+func TestEachStreamsReceiptsSince(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	writeReceipt(t, dir, "bbbb", "shred", now.Add(-time.Hour), Item{Path: "/data/b", Outcome: OutcomeShredded})
+	writeReceipt(t, dir, "aaaa", "delete", now.Add(-48*time.Hour), Item{Path: "/data/a", Outcome: OutcomeTrashed})
+	writeReceipt(t, dir, "cccc", "purge", now.Add(-30*time.Minute), Item{Path: "/data/c", Outcome: OutcomeDeleted})
+	if err := os.WriteFile(filepath.Join(dir, "20000101-000000-broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	err := Each(dir, now.Add(-2*time.Hour), func(r *Receipt) error {
+		ids = append(ids, r.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each: %v", err)
+	}
+	if want := []string{"bbbb", "cccc"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Each visited %q, want %q in start order", ids, want)
+	}
+
+	stop := Each(dir, time.Time{}, func(r *Receipt) error { return os.ErrClosed })
+	if stop != os.ErrClosed {
+		t.Errorf("Each returned %v, want the callback error", stop)
+	}
+	if err := Each(filepath.Join(dir, "missing"), time.Time{}, func(*Receipt) error { return nil }); err != nil {
+		t.Errorf("Each of a missing directory = %v, want no records", err)
+	}
+}
+
+func TestRecordsFlattenItems(t *testing.T) {
+	started := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	receipt := &Receipt{ID: "op1", Operation: "delete", User: "alice", Host: "ws", StartedAt: started, Confirmation: ConfirmedByUser,
+		Items: []Item{
+			{Path: "/data/a.key", Size: 12, Hash: "abc", TrashPath: "/trash/a.key", Outcome: OutcomeTrashed},
+			{Path: "/data/dir", IsDirectory: true, Outcome: OutcomeFailed, Reason: "权限不足", Elevated: true},
+		}}
+
+	records := receipt.Records()
+	if len(records) != 2 {
+		t.Fatalf("Records() returned %d records, want 2", len(records))
+	}
+	want := Record{Time: started, OperationID: "op1", Operation: "delete", User: "alice", Host: "ws",
+		Path: "/data/a.key", Size: 12, Hash: "abc", TrashPath: "/trash/a.key", Outcome: OutcomeTrashed, Confirmation: ConfirmedByUser}
+	if records[0] != want {
+		t.Errorf("record = %+v, want %+v", records[0], want)
+	}
+	if !records[1].IsDirectory || !records[1].Elevated || records[1].Reason != "权限不足" {
+		t.Errorf("record = %+v, want the failed elevated directory", records[1])
+	}
+}
+
+// TestRecordHeaderMatchesFields CSV列名、Fields和JSON字段必须一一对应
+func TestRecordHeaderMatchesFields(t *testing.T) {
+	fields := Record{}.Fields()
+	if len(fields) != len(RecordHeader) {
+		t.Fatalf("Fields() has %d columns, RecordHeader has %d", len(fields), len(RecordHeader))
+	}
+	recordType := reflect.TypeOf(Record{})
+	if recordType.NumField() != len(RecordHeader) {
+		t.Fatalf("Record has %d fields, RecordHeader has %d", recordType.NumField(), len(RecordHeader))
+	}
+	for i, column := range RecordHeader {
+		tag := strings.Split(recordType.Field(i).Tag.Get("json"), ",")[0]
+		if tag != column {
+			t.Errorf("column %d is %q, Record field %s is %q", i, column, recordType.Field(i).Name, tag)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package security
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitStatusTimeout git status的超时时间，仓库很大或git没有响应时放弃检查
+const gitStatusTimeout = 2 * time.Second
+
+// GitRepoRoot 从path自身开始向上查找含有.git的目录，返回所在工作树的根目录
+func GitRepoRoot(path string) (string, bool) {
+	dir := path
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// IsGitDir 判断path是否为Git仓库的.git目录，删除后仓库的全部提交历史都会丢失
+func IsGitDir(path string) bool {
+	if filepath.Base(path) != ".git" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(path, "HEAD"))
+	return err == nil && !info.IsDir()
+}
+
+// GitUncommitted 返回paths（root工作树中的绝对路径）中有未提交修改的已跟踪项目，目录中任一文件有修改即计入
+// 调用 git status，git不可用、root不是仓库或超过gitStatusTimeout时返回错误
+func GitUncommitted(root string, paths []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitStatusTimeout)
+	defer cancel()
+
+	// 检查整个仓库再按路径筛选，避免参数过多；GIT_OPTIONAL_LOCKS=0 使status不写入索引
+	cmd := exec.CommandContext(ctx, "git", "-C", root, "status", "--porcelain", "-z", "--untracked-files=no")
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	changed := parseGitStatus(out)
+
+	var dirty []string
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, name := range changed {
+			if rel == "." || name == rel || strings.HasPrefix(name, rel+"/") {
+				dirty = append(dirty, path)
+				break
+			}
+		}
+	}
+	return dirty, nil
+}
+
+// parseGitStatus 解析 git status --porcelain -z 的输出，返回有修改的路径（相对于仓库根目录，以/分隔）
+func parseGitStatus(out []byte) []string {
+	var names []string
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		names = append(names, entry[3:])
+		// 重命名和复制的条目之后是原路径
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return names
+}
@@ -0,0 +1,195 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/utils"
+)
+
+// MalwareScanner 恶意软件扫描器接口
+type MalwareScanner interface {
+	// Scan 扫描指定文件，检测到威胁时返回ErrTypeMalware类型的错误
+	Scan(path string) error
+}
+
+// CommandScanner 调用外部命令（如clamscan、MpCmdRun）进行扫描的扫描器
+type CommandScanner struct {
+	// Command 命令模板，{file}会被替换为待扫描文件路径
+	Command string
+	// Timeout 单次扫描超时时间
+	Timeout time.Duration
+	// MaxSize 超过此大小的文件将跳过扫描
+	MaxSize int64
+}
+
+// infectedExitCodes 已知扫描器表示"发现威胁"的退出码
+var infectedExitCodes = map[string][]int{
+	"clamscan":  {1},
+	"clamdscan": {1},
+	"mpcmdrun":  {2},
+}
+
+// NewMalwareScanner 根据配置创建扫描器，未启用扫描时返回nil
+// 启用了扫描但没有配置扫描命令、系统中也找不到时返回ErrTypeConfigError错误，由调用方决定如何提示
+func NewMalwareScanner(cfg *config.Config) (MalwareScanner, error) {
+	if cfg == nil || !cfg.Security.VirusScan {
+		return nil, nil
+	}
+
+	command := cfg.Integration.ExternalCommands["virus_scan"]
+	if command == "" {
+		command = detectScanCommand()
+	}
+	if command == "" {
+		err := errors.NewError(errors.ErrTypeConfigError, "已启用 security.virus_scan，但找不到扫描命令", nil)
+		err.Hint = "安装 clamscan，或在 integration.external_commands.virus_scan 中配置扫描命令"
+		return nil, err
+	}
+
+	timeout := time.Duration(cfg.Security.ScanTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+
+	var maxSize int64
+	if cfg.Security.ScanMaxSize != "" {
+		if size, err := utils.ParseSize(cfg.Security.ScanMaxSize); err == nil {
+			maxSize = size
+		}
+	}
+
+	return &CommandScanner{
+		Command: command,
+		Timeout: timeout,
+		MaxSize: maxSize,
+	}, nil
+}
+
+// Scan 扫描文件
+func (s *CommandScanner) Scan(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("无法访问待扫描文件: %v", err)
+	}
+
+	// 目录和超过大小限制的文件不扫描
+	if info.IsDir() {
+		return nil
+	}
+	if s.MaxSize > 0 && info.Size() > s.MaxSize {
+		return nil
+	}
+
+	args := splitCommandLine(s.Command)
+	if len(args) == 0 {
+		return fmt.Errorf("扫描命令为空")
+	}
+
+	// 替换文件占位符，没有占位符时将路径追加到末尾
+	replaced := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{file}") {
+			args[i] = strings.ReplaceAll(arg, "{file}", path)
+			replaced = true
+		}
+	}
+	if !replaced {
+		args = append(args, path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("扫描超时 (%v): %s", s.Timeout, path)
+	}
+	if err == nil {
+		return nil
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return fmt.Errorf("执行扫描命令失败: %v", err)
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0])))
+	codes, known := infectedExitCodes[name]
+	if !known {
+		codes = []int{1}
+	}
+	for _, code := range codes {
+		if exitErr.ExitCode() == code {
+			return errors.NewMalwareError(path, summarizeScanOutput(output))
+		}
+	}
+
+	return fmt.Errorf("扫描命令异常退出 (退出码 %d): %s", exitErr.ExitCode(), summarizeScanOutput(output))
+}
+
+// detectScanCommand 查找系统中可用的扫描命令
+func detectScanCommand() string {
+	if runtime.GOOS == "windows" {
+		defender := filepath.Join(os.Getenv("ProgramFiles"), "Windows Defender", "MpCmdRun.exe")
+		if _, err := os.Stat(defender); err == nil {
+			return fmt.Sprintf(`"%s" -Scan -ScanType 3 -File {file} -DisableRemediation`, defender)
+		}
+	}
+
+	if path, err := exec.LookPath("clamscan"); err == nil {
+		return fmt.Sprintf(`"%s" --no-summary {file}`, path)
+	}
+
+	return ""
+}
+
+// splitCommandLine 按空白拆分命令行，支持双引号包裹含空格的参数
+func splitCommandLine(command string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes := false
+	hasArg := false
+
+	for _, r := range command {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// summarizeScanOutput 提取扫描输出中的第一行非空内容作为说明
+func summarizeScanOutput(output []byte) string {
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			return line
+		}
+	}
+	return "未知威胁"
+}
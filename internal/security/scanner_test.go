@@ -0,0 +1,128 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"delguard/internal/config"
+	"delguard/internal/errors"
+)
+
+// fakeScannerEnv 设置时测试二进制作为假扫描器运行，见TestMain
+const fakeScannerEnv = "DELGUARD_FAKE_SCANNER"
+
+// infectedSentinel 假扫描器报告为感染的文件名
+const infectedSentinel = "eicar.com"
+
+// TestMain 作为假扫描器运行时按clamscan的约定退出：文件名为infectedSentinel时退出码1，
+// 文件名含slow时一直等待，其他文件退出码0
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeScannerEnv) == "" {
+		os.Exit(m.Run())
+	}
+	path := os.Args[len(os.Args)-1]
+	switch name := filepath.Base(path); {
+	case name == infectedSentinel:
+		fmt.Printf("%s: Eicar-Signature FOUND\n", path)
+		os.Exit(1)
+	case strings.Contains(name, "slow"):
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// fakeScanner 以测试二进制为扫描命令的CommandScanner
+func fakeScanner(t *testing.T) *CommandScanner {
+	t.Helper()
+	t.Setenv(fakeScannerEnv, "1")
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CommandScanner{Command: fmt.Sprintf(`"%s" {file}`, executable), Timeout: 10 * time.Second}
+}
+
+func writeScanFile(t *testing.T, name string, size int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommandScannerFlagsSentinel(t *testing.T) {
+	scanner := fakeScanner(t)
+
+	err := scanner.Scan(writeScanFile(t, infectedSentinel, 68))
+	if !errors.IsType(err, errors.ErrTypeMalware) {
+		t.Fatalf("Scan(%s) = %v, want a malware error", infectedSentinel, err)
+	}
+	if !strings.Contains(err.Error(), "Eicar-Signature FOUND") {
+		t.Errorf("malware error %q does not include the scanner output", err)
+	}
+
+	if err := scanner.Scan(writeScanFile(t, "clean.txt", 10)); err != nil {
+		t.Errorf("Scan(clean.txt) = %v", err)
+	}
+}
+
+func TestCommandScannerSkips(t *testing.T) {
+	scanner := fakeScanner(t)
+	scanner.MaxSize = 100
+
+	if err := scanner.Scan(writeScanFile(t, infectedSentinel, 101)); err != nil {
+		t.Errorf("files over MaxSize must not be scanned: %v", err)
+	}
+	if err := scanner.Scan(t.TempDir()); err != nil {
+		t.Errorf("directories must not be scanned: %v", err)
+	}
+}
+
+func TestCommandScannerTimeout(t *testing.T) {
+	scanner := fakeScanner(t)
+	scanner.Timeout = 200 * time.Millisecond
+
+	err := scanner.Scan(writeScanFile(t, "slow.bin", 1))
+	if err == nil || errors.IsType(err, errors.ErrTypeMalware) || !strings.Contains(err.Error(), "超时") {
+		t.Errorf("Scan of a hanging scanner = %v, want a timeout error", err)
+	}
+}
+
+func TestNewMalwareScanner(t *testing.T) {
+	var cfg config.Config
+	if scanner, err := NewMalwareScanner(&cfg); scanner != nil || err != nil {
+		t.Errorf("disabled scan: NewMalwareScanner() = %v, %v", scanner, err)
+	}
+	if scanner, err := NewMalwareScanner(nil); scanner != nil || err != nil {
+		t.Errorf("nil config: NewMalwareScanner() = %v, %v", scanner, err)
+	}
+
+	cfg.Security.VirusScan = true
+	cfg.Security.ScanMaxSize = "1KB"
+	cfg.Integration.ExternalCommands = map[string]string{"virus_scan": "scan {file}"}
+	scanner, err := NewMalwareScanner(&cfg)
+	if err != nil {
+		t.Fatalf("NewMalwareScanner: %v", err)
+	}
+	command, ok := scanner.(*CommandScanner)
+	if !ok || command.Command != "scan {file}" || command.Timeout != 60*time.Second || command.MaxSize != 1024 {
+		t.Errorf("NewMalwareScanner() = %+v", scanner)
+	}
+}
+
+func TestNewMalwareScannerWithoutCommand(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("ProgramFiles", t.TempDir())
+
+	var cfg config.Config
+	cfg.Security.VirusScan = true
+	scanner, err := NewMalwareScanner(&cfg)
+	if scanner != nil || !errors.IsType(err, errors.ErrTypeConfigError) {
+		t.Errorf("NewMalwareScanner() = %v, %v, want a config error instead of silently skipping", scanner, err)
+	}
+}
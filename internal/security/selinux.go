@@ -0,0 +1,37 @@
+package security
+
+import "strings"
+
+// protectedSELinuxTypes 删除后可能导致无法登录、无法启动或安全策略失效的SELinux类型
+var protectedSELinuxTypes = []string{
+	"shadow_t",
+	"passwd_file_t",
+	"security_t",
+	"selinux_config_t",
+	"semanage_store_t",
+	"default_context_t",
+	"file_context_t",
+	"load_policy_exec_t",
+	"boot_t",
+	"modules_object_t",
+}
+
+// SELinuxType 返回安全上下文 user:role:type[:level] 中的类型，格式不正确时返回空
+func SELinuxType(context string) string {
+	parts := strings.SplitN(context, ":", 4)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// ProtectedSELinuxType 安全上下文的类型属于受保护的系统类型时返回该类型
+func ProtectedSELinuxType(context string) (string, bool) {
+	selinuxType := SELinuxType(context)
+	for _, protected := range protectedSELinuxTypes {
+		if selinuxType == protected {
+			return selinuxType, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+package security
+
+import "testing"
+
+func TestSELinuxType(t *testing.T) {
+	tests := []struct {
+		context string
+		want    string
+	}{
+		{"system_u:object_r:shadow_t:s0", "shadow_t"},
+		{"system_u:object_r:user_home_t", "user_home_t"},
+		{"unconfined_u:object_r:user_home_t:s0:c0.c1023", "user_home_t"},
+		{"system_u:object_r", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := SELinuxType(tt.context); got != tt.want {
+			t.Errorf("SELinuxType(%q) = %q, want %q", tt.context, got, tt.want)
+		}
+	}
+}
+
+func TestProtectedSELinuxType(t *testing.T) {
+	tests := []struct {
+		context string
+		want    string
+		ok      bool
+	}{
+		{"system_u:object_r:shadow_t:s0", "shadow_t", true},
+		{"system_u:object_r:passwd_file_t:s0", "passwd_file_t", true},
+		{"system_u:object_r:boot_t:s0", "boot_t", true},
+		{"unconfined_u:object_r:user_home_t:s0", "", false},
+		{"system_u:object_r:tmp_t:s0", "", false},
+		// 只比较类型字段，用户或角色中出现受保护类型的名称不算
+		{"shadow_t:object_r:tmp_t:s0", "", false},
+		{"shadow_t", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ProtectedSELinuxType(tt.context)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ProtectedSELinuxType(%q) = %q, %v; want %q, %v", tt.context, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"delguard/internal/errors"
+	"delguard/internal/utils"
 )
 
 // PathValidator 路径验证器
@@ -25,8 +26,18 @@ func NewPathValidator() *PathValidator {
 	}
 }
 
-// ValidateDeletePath 验证删除路径是否安全
+// ValidateDeletePath 验证删除路径是否安全，符号链接按其指向的目标验证
 func (pv *PathValidator) ValidateDeletePath(path string) error {
+	return pv.validateDeletePath(path, true)
+}
+
+// ValidateDeleteLinkPath 验证删除路径是否安全，符号链接按链接本身验证，链接失效也可以删除
+func (pv *PathValidator) ValidateDeleteLinkPath(path string) error {
+	return pv.validateDeletePath(path, false)
+}
+
+// validateDeletePath 验证删除路径，followLinks为true时对符号链接的目标重新验证
+func (pv *PathValidator) validateDeletePath(path string, followLinks bool) error {
 	// 检查空路径
 	if strings.TrimSpace(path) == "" {
 		return errors.NewError(errors.ErrTypeInvalidPath, "路径不能为空", nil)
@@ -60,19 +71,19 @@ func (pv *PathValidator) ValidateDeletePath(path string) error {
 	}
 	
 	// 检查是否为符号链接
-	if info, err := os.Lstat(cleanPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+	if info, err := os.Lstat(cleanPath); followLinks && err == nil && info.Mode()&os.ModeSymlink != 0 {
 		// 获取符号链接的目标路径
 		if target, err := os.Readlink(cleanPath); err == nil {
 			// 重新验证目标路径
 			if !filepath.IsAbs(target) {
 				target = filepath.Join(filepath.Dir(cleanPath), target)
 			}
-			return pv.ValidateDeletePath(target)
+			return pv.validateDeletePath(target, true)
 		}
 	}
 
 	// 检查路径是否存在
-	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
+	if _, err := os.Lstat(cleanPath); os.IsNotExist(err) {
 		return errors.NewFileNotFoundError(cleanPath)
 	}
 
@@ -105,20 +116,27 @@ func (pv *PathValidator) ValidateDeletePath(path string) error {
 	return nil
 }
 
+// IsCriticalPath 检查路径是否为受保护路径或系统关键路径
+func (pv *PathValidator) IsCriticalPath(path string) bool {
+	cleanPath := filepath.Clean(path)
+	return pv.isProtectedPath(cleanPath) || pv.isSystemPath(cleanPath)
+}
+
 // isProtectedPath 检查是否为受保护路径
 func (pv *PathValidator) isProtectedPath(path string) bool {
-	for _, protected := range pv.protectedPaths {
-		if strings.HasPrefix(path, protected) {
-			return true
-		}
-	}
-	return false
+	return hasAnyPrefix(path, pv.protectedPaths)
 }
 
 // isSystemPath 检查是否为系统路径
 func (pv *PathValidator) isSystemPath(path string) bool {
-	for _, system := range pv.systemPaths {
-		if strings.HasPrefix(path, system) {
+	return hasAnyPrefix(path, pv.systemPaths)
+}
+
+// hasAnyPrefix 检查path是否以prefixes之一开头，path所在的卷不区分大小写时忽略大小写
+func hasAnyPrefix(path string, prefixes []string) bool {
+	fold := utils.CaseInsensitive(path)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) || fold && strings.HasPrefix(strings.ToLower(path), strings.ToLower(prefix)) {
 			return true
 		}
 	}
@@ -250,6 +268,41 @@ func (pv *PathValidator) ValidateRestorePath(targetPath string) error {
 	return nil
 }
 
+// ValidateTrashDir 验证用作回收站的目录，不存在时创建，返回清理后的绝对路径
+func (pv *PathValidator) ValidateTrashDir(dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", errors.NewError(errors.ErrTypeInvalidPath, "回收站目录不能为空", nil)
+	}
+	if len(dir) > 4096 || strings.ContainsRune(dir, 0) {
+		return "", errors.NewInvalidPathError(dir)
+	}
+
+	absPath, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.NewInvalidPathError(dir)
+	}
+	absPath = filepath.Clean(absPath)
+
+	// 不允许把文件放进系统关键目录
+	if pv.isSystemPath(absPath) {
+		return "", errors.NewError(errors.ErrTypePermissionDenied,
+			fmt.Sprintf("不能使用系统关键路径作为回收站: %s", absPath), nil)
+	}
+
+	if err := os.MkdirAll(absPath, 0700); err != nil {
+		return "", errors.NewError(errors.ErrTypeInvalidPath,
+			fmt.Sprintf("创建回收站目录失败: %s", absPath), err)
+	}
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		return "", errors.NewError(errors.ErrTypeInvalidPath,
+			fmt.Sprintf("回收站路径不是目录: %s", absPath), nil)
+	}
+	if !pv.hasWritePermission(absPath) {
+		return "", errors.NewPermissionDeniedError(absPath)
+	}
+	return absPath, nil
+}
+
 // hasWritePermission 检查是否有写权限
 func (pv *PathValidator) hasWritePermission(path string) bool {
 	// 尝试在目录中创建临时文件来测试写权限
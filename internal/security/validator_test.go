@@ -0,0 +1,59 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestValidateTrashDirRejects(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	system := "/etc/delguard-trash"
+	if runtime.GOOS == "windows" {
+		system = `C:\Windows\delguard-trash`
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+	}{
+		{"empty", "  "},
+		{"NUL byte", "trash\x00dir"},
+		{"too long", strings.Repeat("a", 4097)},
+		{"system path", system},
+		{"existing file", file},
+	}
+	pv := NewPathValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := pv.ValidateTrashDir(tt.dir); err == nil {
+				t.Errorf("ValidateTrashDir(%q) = %q, want an error", tt.dir, got)
+			}
+		})
+	}
+}
+
+func TestValidateTrashDirCreatesMissingDir(t *testing.T) {
+	pv := NewPathValidator()
+	base := t.TempDir()
+	if pv.isSystemPath(base) {
+		t.Skipf("temporary directory %s is under a protected system path", base)
+	}
+	dir := filepath.Join(base, "project", ".trash")
+
+	got, err := pv.ValidateTrashDir(dir)
+	if err != nil {
+		t.Fatalf("ValidateTrashDir: %v", err)
+	}
+	if got != filepath.Clean(dir) {
+		t.Errorf("ValidateTrashDir = %q, want %q", got, dir)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("trash dir was not created: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package telemetry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// endpoint 记录收到的请求并按statuses依次返回状态码，用完后一直返回最后一个
+type endpoint struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	types    []string
+}
+
+func newEndpoint(t *testing.T, statuses ...int) *endpoint {
+	t.Helper()
+	e := &endpoint{statuses: statuses}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		e.mu.Lock()
+		e.bodies = append(e.bodies, string(body))
+		e.types = append(e.types, r.Header.Get("Content-Type"))
+		status := e.statuses[0]
+		if len(e.statuses) > 1 {
+			e.statuses = e.statuses[1:]
+		}
+		e.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *endpoint) calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.bodies)
+}
+
+// backdateQueue 把队列中的事件改为昨天的，使StartFlush发送它们
+func backdateQueue(t *testing.T) {
+	t.Helper()
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	err := updateQueue(func(events []Event) []Event {
+		for i := range events {
+			events[i].Date = yesterday
+		}
+		return events
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFlush(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(FlushBudget + 2*time.Second):
+		t.Fatal("StartFlush did not finish within its budget")
+	}
+}
+
+func TestStartFlushSendsAnonymousPayload(t *testing.T) {
+	useTempState(t)
+	secretFile := filepath.Join(string(filepath.Separator)+"home", "alice", "tax-returns", "2026.pdf")
+	AddItems(4)
+	AddError(errors.NewPermissionDeniedError(secretFile))
+	AddError(&os.PathError{Op: "remove", Path: secretFile, Err: os.ErrPermission})
+	if err := Record("1.2.3", "delete"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	backdateQueue(t)
+	// 当天的事件仍在累计，不应发送
+	resetSession()
+	if err := Record("1.2.3", "restore"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	server := newEndpoint(t, http.StatusOK)
+	waitFlush(t, StartFlush(server.URL))
+
+	if server.calls() != 1 {
+		t.Fatalf("endpoint received %d requests, want 1", server.calls())
+	}
+	body := server.bodies[0]
+	if server.types[0] != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", server.types[0])
+	}
+	for _, secret := range []string{"alice", "tax-returns", "2026.pdf", "pdf", "remove"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("payload contains %q:\n%s", secret, body)
+		}
+	}
+	if !strings.Contains(body, `"delete"`) || strings.Contains(body, `"restore"`) {
+		t.Errorf("payload should carry only the earlier delete event:\n%s", body)
+	}
+
+	events, err := Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Command != "restore" {
+		t.Errorf("queue after flush = %+v, want only today's restore event", events)
+	}
+}
+
+func TestSendRetriesWithBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantErr   bool
+		wantCalls int
+	}{
+		{"success", []int{http.StatusNoContent}, false, 1},
+		{"server errors are retried", []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK}, false, 3},
+		{"rate limit is retried", []int{http.StatusTooManyRequests, http.StatusOK}, false, 2},
+		{"rejected payload is not retried", []int{http.StatusBadRequest}, true, 1},
+		{"gives up after the last attempt", []int{http.StatusBadGateway}, true, sendAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEndpoint(t, tt.statuses...)
+			start := time.Now()
+			err := send(server.URL, []Event{NewEvent("1.2.3", "delete", start)})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if server.calls() != tt.wantCalls {
+				t.Errorf("endpoint received %d requests, want %d", server.calls(), tt.wantCalls)
+			}
+			// 第n次尝试前共等待 sendBackoff*(2^(n-1)-1)
+			if minWait := sendBackoff * time.Duration(1<<(tt.wantCalls-1)-1); time.Since(start) < minWait {
+				t.Errorf("send() took %v, want at least %v of backoff", time.Since(start), minWait)
+			}
+		})
+	}
+}
+
+func TestSendRetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	start := time.Now()
+	if err := send(url, []Event{NewEvent("1.2.3", "delete", start)}); err == nil {
+		t.Fatal("send() to a closed endpoint succeeded")
+	}
+	if minWait := sendBackoff * 3; time.Since(start) < minWait {
+		t.Errorf("send() gave up after %v, want %d attempts with backoff", time.Since(start), sendAttempts)
+	}
+}
+
+func TestSendStopsAtFlushBudget(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	start := time.Now()
+	if err := send(server.URL, []Event{NewEvent("1.2.3", "delete", start)}); err == nil {
+		t.Fatal("send() to a hanging endpoint succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > FlushBudget+time.Second {
+		t.Errorf("send() took %v, want at most FlushBudget (%v)", elapsed, FlushBudget)
+	}
+}
@@ -0,0 +1,318 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"delguard/internal/errors"
+	"delguard/internal/lock"
+	"delguard/internal/paths"
+)
+
+// FlushBudget 后台发送统计的网络时间上限，包括所有重试
+const FlushBudget = 2 * time.Second
+
+// sendAttempts 发送失败时最多尝试的次数
+const sendAttempts = 3
+
+// sendBackoff 第一次重试前的等待时间，之后每次加倍
+const sendBackoff = 200 * time.Millisecond
+
+// maxQueuedEvents 本地队列最多保留的事件数，超出时丢弃最旧的事件
+const maxQueuedEvents = 200
+
+// queueLockTimeout 等待其他进程释放队列锁的最长时间，超时则放弃本次记录
+const queueLockTimeout = time.Second
+
+// Event 一条匿名统计，按天、版本、平台和命令聚合
+// 只包含固定的计数字段和枚举值，不包含任何路径、文件名或用户输入
+type Event struct {
+	Date    string         `json:"date"`    // 只精确到天，2006-01-02
+	Version string         `json:"version"` // DelGuard版本
+	OS      string         `json:"os"`
+	Arch    string         `json:"arch"`
+	Command string         `json:"command"`          // 子命令名，如delete、trash prune
+	Runs    int            `json:"runs"`             // 执行次数
+	Items   int            `json:"items"`            // 处理的项目总数
+	Errors  map[string]int `json:"errors,omitempty"` // 按错误类型统计的失败次数
+}
+
+// key 聚合键，相同键的调用合并为一条事件
+func (e Event) key() string {
+	return e.Date + "|" + e.Version + "|" + e.OS + "|" + e.Arch + "|" + e.Command
+}
+
+var (
+	sessionMu     sync.Mutex
+	sessionItems  int
+	sessionErrors = make(map[string]int)
+)
+
+// AddItems 累加本次调用处理的项目数
+func AddItems(n int) {
+	sessionMu.Lock()
+	sessionItems += n
+	sessionMu.Unlock()
+}
+
+// AddError 按错误类型累加本次调用的失败次数，只记录类型名称，不记录错误内容
+func AddError(err error) {
+	if err == nil {
+		return
+	}
+	sessionMu.Lock()
+	sessionErrors[errors.KindOf(err)]++
+	sessionMu.Unlock()
+}
+
+// AddCommandError 记录命令返回的错误类型，已通过AddError记录了逐项错误时不重复计数
+func AddCommandError(err error) {
+	sessionMu.Lock()
+	recorded := len(sessionErrors) > 0
+	sessionMu.Unlock()
+	if !recorded {
+		AddError(err)
+	}
+}
+
+// QueuePath 返回本地事件队列文件，位于状态目录（默认 ~/.delguard）下
+func QueuePath() (string, error) {
+	stateDir, err := paths.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "telemetry.queue"), nil
+}
+
+// NewEvent 创建当天当前平台的事件
+func NewEvent(version, command string, now time.Time) Event {
+	return Event{
+		Date:    now.Format("2006-01-02"),
+		Version: version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		Command: command,
+	}
+}
+
+// Record 将本次调用的统计合并到本地队列，调用方负责只在用户启用统计时调用
+func Record(version, command string) error {
+	sessionMu.Lock()
+	event := NewEvent(version, command, time.Now())
+	event.Runs = 1
+	event.Items = sessionItems
+	if len(sessionErrors) > 0 {
+		event.Errors = make(map[string]int, len(sessionErrors))
+		for kind, count := range sessionErrors {
+			event.Errors[kind] = count
+		}
+	}
+	sessionMu.Unlock()
+
+	return updateQueue(func(events []Event) []Event {
+		for i := range events {
+			if events[i].key() != event.key() {
+				continue
+			}
+			events[i].Runs += event.Runs
+			events[i].Items += event.Items
+			for kind, count := range event.Errors {
+				if events[i].Errors == nil {
+					events[i].Errors = make(map[string]int)
+				}
+				events[i].Errors[kind] += count
+			}
+			return events
+		}
+		return append(events, event)
+	})
+}
+
+// Pending 返回本地队列中待发送的事件
+func Pending() ([]Event, error) {
+	path, err := QueuePath()
+	if err != nil {
+		return nil, err
+	}
+	return readQueue(path)
+}
+
+// Clear 删除本地队列
+func Clear() error {
+	path, err := QueuePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除统计队列失败: %v", err)
+	}
+	return nil
+}
+
+// Payload 序列化将要发送的事件，show-pending显示的内容与实际发送的完全相同
+func Payload(events []Event) ([]byte, error) {
+	return json.MarshalIndent(events, "", "  ")
+}
+
+// StartFlush 在后台发送当天之前的事件，返回的channel在发送结束后关闭
+// 当天的事件仍在累计，不会发送；网络请求最多耗时FlushBudget，失败时静默放弃，事件留待下次发送
+func StartFlush(endpoint string) <-chan struct{} {
+	done := make(chan struct{})
+	path, err := QueuePath()
+	if endpoint == "" || err != nil {
+		close(done)
+		return done
+	}
+	events, err := readQueue(path)
+	if err != nil {
+		close(done)
+		return done
+	}
+
+	today := time.Now().Format("2006-01-02")
+	var ready []Event
+	for _, event := range events {
+		if event.Date < today {
+			ready = append(ready, event)
+		}
+	}
+	if len(ready) == 0 {
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+		if send(endpoint, ready) != nil {
+			return
+		}
+		sent := make(map[string]bool, len(ready))
+		for _, event := range ready {
+			sent[event.key()] = true
+		}
+		updateQueue(func(events []Event) []Event {
+			var remaining []Event
+			for _, event := range events {
+				if !sent[event.key()] {
+					remaining = append(remaining, event)
+				}
+			}
+			return remaining
+		})
+	}()
+	return done
+}
+
+// send 将事件以JSON数组POST到endpoint，失败时按指数退避重试，所有尝试共用FlushBudget
+func send(endpoint string, events []Event) error {
+	body, err := Payload(events)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), FlushBudget)
+	defer cancel()
+
+	backoff := sendBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := post(ctx, endpoint, body)
+		if err == nil || !retry || attempt == sendAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post 发送一次请求，失败时返回是否值得重试：网络错误、429和5xx可以重试，
+// 其他状态码说明服务器拒绝了这些数据，重试也不会成功
+func post(ctx context.Context, endpoint string, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+		return retry, fmt.Errorf("服务器返回 %s", response.Status)
+	}
+	return false, nil
+}
+
+// updateQueue 在队列锁内读取、修改并写回队列
+func updateQueue(update func([]Event) []Event) error {
+	path, err := QueuePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("创建统计目录失败: %v", err)
+	}
+	fileLock, err := lock.Lock(path+".lock", queueLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+
+	events, err := readQueue(path)
+	if err != nil {
+		// 队列损坏时重新开始，统计数据丢失无关紧要
+		events = nil
+	}
+	events = update(events)
+	if len(events) == 0 {
+		os.Remove(path)
+		return nil
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date < events[j].Date })
+	if len(events) > maxQueuedEvents {
+		events = events[len(events)-maxQueuedEvents:]
+	}
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("写入统计队列失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入统计队列失败: %v", err)
+	}
+	return nil
+}
+
+// readQueue 读取队列文件，文件不存在时返回空列表
+func readQueue(path string) ([]Event, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取统计队列失败: %v", err)
+	}
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("解析统计队列失败: %v", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,169 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"delguard/internal/errors"
+)
+
+// useTempState 将状态目录指向临时主目录，并清空本次调用已累计的统计
+func useTempState(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	resetSession()
+	t.Cleanup(resetSession)
+	return home
+}
+
+func resetSession() {
+	sessionMu.Lock()
+	sessionItems = 0
+	sessionErrors = make(map[string]int)
+	sessionMu.Unlock()
+}
+
+// TestEventSchema 统计只能包含下列字段，新增字段必须在此确认不会携带路径、文件名或用户输入
+func TestEventSchema(t *testing.T) {
+	allowed := map[string]reflect.Type{
+		"Date":    reflect.TypeOf(""),
+		"Version": reflect.TypeOf(""),
+		"OS":      reflect.TypeOf(""),
+		"Arch":    reflect.TypeOf(""),
+		"Command": reflect.TypeOf(""),
+		"Runs":    reflect.TypeOf(0),
+		"Items":   reflect.TypeOf(0),
+		"Errors":  reflect.TypeOf(map[string]int{}),
+	}
+	eventType := reflect.TypeOf(Event{})
+	if eventType.NumField() != len(allowed) {
+		t.Errorf("Event has %d fields, want %d", eventType.NumField(), len(allowed))
+	}
+	for i := 0; i < eventType.NumField(); i++ {
+		field := eventType.Field(i)
+		want, ok := allowed[field.Name]
+		if !ok {
+			t.Errorf("Event.%s is not an approved telemetry field", field.Name)
+			continue
+		}
+		if field.Type != want {
+			t.Errorf("Event.%s has type %s, want %s", field.Name, field.Type, want)
+		}
+	}
+}
+
+func TestPayloadCarriesNoPathsOrFileNames(t *testing.T) {
+	useTempState(t)
+	secretDir := filepath.Join(string(filepath.Separator)+"home", "alice", "private-projects")
+	secretFile := filepath.Join(secretDir, "salary-2026.xlsx")
+
+	AddItems(3)
+	AddError(errors.NewPermissionDeniedError(secretFile))
+	AddError(errors.FromOS("移动到回收站失败", &os.PathError{Op: "rename", Path: secretFile, Err: os.ErrPermission}))
+	AddError(&os.PathError{Op: "open", Path: secretDir, Err: os.ErrNotExist})
+	if err := Record("1.2.3", "delete"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	events, err := Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	payload, err := Payload(events)
+	if err != nil {
+		t.Fatalf("Payload: %v", err)
+	}
+	for _, secret := range []string{"alice", "private-projects", "salary", "xlsx", string(filepath.Separator) + "home"} {
+		if strings.Contains(string(payload), secret) {
+			t.Errorf("payload contains %q:\n%s", secret, payload)
+		}
+	}
+
+	kinds := map[string]bool{"unknown": true}
+	for _, errType := range errors.AllErrorTypes() {
+		kinds[errType.Kind()] = true
+	}
+	if len(events) != 1 {
+		t.Fatalf("queued %d events, want 1", len(events))
+	}
+	event := events[0]
+	for kind := range event.Errors {
+		if !kinds[kind] {
+			t.Errorf("error kind %q is not a fixed error type", kind)
+		}
+	}
+	if event.Items != 3 || event.Runs != 1 {
+		t.Errorf("event = %+v, want 3 items in 1 run", event)
+	}
+	if !regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`).MatchString(event.Date) {
+		t.Errorf("Date = %q, want day precision only", event.Date)
+	}
+}
+
+func TestRecordMergesRunsOfTheSameDay(t *testing.T) {
+	home := useTempState(t)
+	for i := 0; i < 3; i++ {
+		resetSession()
+		AddItems(2)
+		if i == 0 {
+			AddError(errors.NewTrashFullError())
+		}
+		if err := Record("1.2.3", "delete"); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	resetSession()
+	if err := Record("1.2.3", "restore"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	events, err := Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("queued %d events, want one per command: %+v", len(events), events)
+	}
+	for _, event := range events {
+		if event.Command == "delete" && (event.Runs != 3 || event.Items != 6 || len(event.Errors) != 1) {
+			t.Errorf("delete event = %+v, want 3 runs, 6 items and one error kind", event)
+		}
+	}
+
+	path, err := QueuePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".delguard", "telemetry.queue"); path != want {
+		t.Errorf("QueuePath() = %q, want %q", path, want)
+	}
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("queue still exists after Clear: %v", err)
+	}
+}
+
+func TestStartFlushWithoutEndpointSendsNothing(t *testing.T) {
+	useTempState(t)
+	if err := Record("1.2.3", "delete"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	select {
+	case <-StartFlush(""):
+	case <-time.After(time.Second):
+		t.Fatal("StartFlush without an endpoint did not return immediately")
+	}
+	if events, _ := Pending(); len(events) != 1 {
+		t.Errorf("queue has %d events after a disabled flush, want 1", len(events))
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	caseMu    sync.Mutex
+	caseCache = make(map[string]bool) // 卷标识 -> 是否不区分大小写
+)
+
+// CaseInsensitive 报告path所在的卷是否不区分文件名大小写（如NTFS、默认的APFS）
+// 每个卷在进程内只判断一次：优先按大小写互换后的名称查找路径上已存在的条目，
+// 路径中没有字母时在最近的已存在目录中创建临时探测文件；都无法判断时按平台默认值处理，且不缓存
+func CaseInsensitive(path string) bool {
+	existing := existingPath(path)
+	key, ok := volumeKey(existing)
+	if ok {
+		caseMu.Lock()
+		insensitive, cached := caseCache[key]
+		caseMu.Unlock()
+		if cached {
+			return insensitive
+		}
+	}
+
+	insensitive, probed := probeByName(existing, key, ok)
+	if !probed {
+		insensitive, probed = probeCaseInsensitive(existingDir(existing))
+	}
+	if !probed {
+		return defaultCaseInsensitive()
+	}
+	if ok {
+		caseMu.Lock()
+		caseCache[key] = insensitive
+		caseMu.Unlock()
+	}
+	return insensitive
+}
+
+// FoldPath 返回用于比较的路径形式，所在的卷不区分大小写时转为小写
+func FoldPath(path string) string {
+	if CaseInsensitive(path) {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// SamePath 按a所在卷的大小写规则比较两个路径
+func SamePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if a == b {
+		return true
+	}
+	return CaseInsensitive(a) && strings.EqualFold(a, b)
+}
+
+// probeByName 从path开始逐级向上，按大小写互换后的名称查找同一卷上的条目：
+// 找到的是同一个文件说明不区分大小写，找不到说明区分大小写
+func probeByName(path, key string, haveKey bool) (bool, bool) {
+	for current := path; ; {
+		parent := filepath.Dir(current)
+		if parent == current {
+			return false, false
+		}
+		// 挂载点的名称属于上一级卷，不能用来判断当前卷
+		if haveKey {
+			if parentKey, ok := volumeKey(parent); !ok || parentKey != key {
+				return false, false
+			}
+		}
+
+		name := filepath.Base(current)
+		if swapped := swapCase(name); swapped != name {
+			info, err := os.Lstat(current)
+			if err != nil {
+				return false, false
+			}
+			other, err := os.Lstat(filepath.Join(parent, swapped))
+			if err == nil {
+				return os.SameFile(info, other), true
+			}
+			if os.IsNotExist(err) {
+				return false, true
+			}
+		}
+		current = parent
+	}
+}
+
+// swapCase 互换名称中字母的大小写
+func swapCase(name string) string {
+	return strings.Map(func(r rune) rune {
+		if lower := unicode.ToLower(r); lower != r {
+			return lower
+		}
+		return unicode.ToUpper(r)
+	}, name)
+}
+
+// probeCaseInsensitive 在dir中创建探测文件，返回是否不区分大小写以及探测是否成功
+func probeCaseInsensitive(dir string) (bool, bool) {
+	probe, err := os.CreateTemp(dir, "delguard-case-probe-*")
+	if err != nil {
+		return false, false
+	}
+	name := probe.Name()
+	probe.Close()
+	defer os.Remove(name)
+
+	upper := filepath.Join(dir, strings.ToUpper(filepath.Base(name)))
+	_, err = os.Lstat(upper)
+	return err == nil, true
+}
+
+// existingPath 返回path本身或其上级中最近的已存在路径
+func existingPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// existingDir 返回已存在的路径本身（是目录时）或其所在的目录
+func existingDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// defaultCaseInsensitive 无法探测时的默认值：Windows和macOS的默认文件系统不区分大小写
+func defaultCaseInsensitive() bool {
+	return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package utils
+
+// volumeKey 其他平台无法标识卷，以目录本身作为缓存键
+func volumeKey(dir string) (string, bool) {
+	return dir, true
+}
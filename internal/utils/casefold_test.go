@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaseInsensitiveMatchesFilesystem(t *testing.T) {
+	dir := t.TempDir()
+	probe := filepath.Join(dir, "probe.txt")
+	if err := os.WriteFile(probe, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := os.Lstat(filepath.Join(dir, "PROBE.TXT"))
+	want := err == nil
+
+	if got := CaseInsensitive(probe); got != want {
+		t.Errorf("CaseInsensitive(%s) = %v, but looking up PROBE.TXT says %v", probe, got, want)
+	}
+	// 尚不存在的路径按最近的已存在目录判断
+	if got := CaseInsensitive(filepath.Join(dir, "missing", "file.txt")); got != want {
+		t.Errorf("CaseInsensitive(missing path) = %v, want %v", got, want)
+	}
+
+	folded := FoldPath(filepath.Join(dir, "Mixed.TXT"))
+	if want && folded != strings.ToLower(filepath.Join(dir, "Mixed.TXT")) {
+		t.Errorf("FoldPath() = %s, want the lower-case path on a case-insensitive volume", folded)
+	}
+	if !want && folded != filepath.Join(dir, "Mixed.TXT") {
+		t.Errorf("FoldPath() = %s, want the path unchanged on a case-sensitive volume", folded)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin
+
+package utils
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// volumeKey 以设备号标识目录所在的卷
+func volumeKey(dir string) (string, bool) {
+	var stat unix.Stat_t
+	if err := unix.Stat(dir, &stat); err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("dev:%d", uint64(stat.Dev)), true
+}
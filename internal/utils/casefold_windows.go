@@ -0,0 +1,17 @@
+//go:build windows
+
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// volumeKey 以盘符或UNC共享名标识目录所在的卷
+func volumeKey(dir string) (string, bool) {
+	volume := filepath.VolumeName(dir)
+	if volume == "" {
+		return "", false
+	}
+	return strings.ToUpper(volume), true
+}
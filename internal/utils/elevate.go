@@ -0,0 +1,6 @@
+package utils
+
+import "errors"
+
+// ErrElevationCancelled 用户在UAC提示中拒绝了提权
+var ErrElevationCancelled = errors.New("用户取消了管理员权限请求")
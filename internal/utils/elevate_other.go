@@ -0,0 +1,20 @@
+//go:build !windows
+
+package utils
+
+import "fmt"
+
+// ElevationSupported 当前平台是否可以通过UAC以管理员身份重新运行
+func ElevationSupported() bool {
+	return false
+}
+
+// IsElevated 非Windows平台不使用UAC提权
+func IsElevated() bool {
+	return false
+}
+
+// RunElevated 只有Windows支持以管理员身份重新运行
+func RunElevated(exe string, args []string) (int, error) {
+	return 0, fmt.Errorf("当前平台不支持以管理员身份重新运行")
+}
@@ -0,0 +1,99 @@
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var shellExecuteEx = windows.NewLazySystemDLL("shell32.dll").NewProc("ShellExecuteExW")
+
+// ShellExecuteExW的选项
+const (
+	seeMaskNoCloseProcess = 0x00000040
+	seeMaskNoAsync        = 0x00000100
+	swShowNormal          = 1
+)
+
+// shellExecuteInfo SHELLEXECUTEINFOW
+type shellExecuteInfo struct {
+	cbSize       uint32
+	fMask        uint32
+	hwnd         windows.Handle
+	lpVerb       *uint16
+	lpFile       *uint16
+	lpParameters *uint16
+	lpDirectory  *uint16
+	nShow        int32
+	hInstApp     windows.Handle
+	lpIDList     uintptr
+	lpClass      *uint16
+	hkeyClass    windows.Handle
+	dwHotKey     uint32
+	hIcon        windows.Handle
+	hProcess     windows.Handle
+}
+
+// ElevationSupported 当前平台是否可以通过UAC以管理员身份重新运行
+func ElevationSupported() bool {
+	return true
+}
+
+// IsElevated 判断当前进程是否已以管理员身份运行
+func IsElevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// RunElevated 通过ShellExecute的runas动词以管理员身份运行程序并等待其退出，返回退出码
+// 用户在UAC提示中拒绝时返回ErrElevationCancelled
+func RunElevated(exe string, args []string) (int, error) {
+	escaped := make([]string, len(args))
+	for i, arg := range args {
+		escaped[i] = windows.EscapeArg(arg)
+	}
+
+	verb, err := windows.UTF16PtrFromString("runas")
+	if err != nil {
+		return 0, err
+	}
+	file, err := windows.UTF16PtrFromString(exe)
+	if err != nil {
+		return 0, err
+	}
+	params, err := windows.UTF16PtrFromString(strings.Join(escaped, " "))
+	if err != nil {
+		return 0, err
+	}
+
+	info := shellExecuteInfo{
+		fMask:        seeMaskNoCloseProcess | seeMaskNoAsync,
+		lpVerb:       verb,
+		lpFile:       file,
+		lpParameters: params,
+		nShow:        swShowNormal,
+	}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+	if ok, _, callErr := shellExecuteEx.Call(uintptr(unsafe.Pointer(&info))); ok == 0 {
+		if callErr == windows.ERROR_CANCELLED {
+			return 0, ErrElevationCancelled
+		}
+		return 0, fmt.Errorf("以管理员身份启动失败: %v", callErr)
+	}
+	if info.hProcess == 0 {
+		return 0, fmt.Errorf("以管理员身份启动失败: 未获得进程句柄")
+	}
+	defer windows.CloseHandle(info.hProcess)
+
+	if _, err := windows.WaitForSingleObject(info.hProcess, windows.INFINITE); err != nil {
+		return 0, fmt.Errorf("等待管理员进程失败: %v", err)
+	}
+	var code uint32
+	if err := windows.GetExitCodeProcess(info.hProcess, &code); err != nil {
+		return 0, fmt.Errorf("获取管理员进程退出码失败: %v", err)
+	}
+	return int(code), nil
+}
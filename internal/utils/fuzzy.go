@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyScore 计算pattern与text的模糊匹配得分，不区分大小写
+// pattern中的字符须按顺序出现在text中，否则返回false；连续匹配和单词开头的匹配得分更高
+func FuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	needle := []rune(strings.ToLower(pattern))
+	haystack := []rune(strings.ToLower(text))
+	score, matched, previous := 0, 0, -2
+	for i, r := range haystack {
+		if matched == len(needle) {
+			break
+		}
+		if r != needle[matched] {
+			continue
+		}
+		score++
+		if i == previous+1 {
+			score += 3
+		}
+		if i == 0 || !unicode.IsLetter(haystack[i-1]) && !unicode.IsDigit(haystack[i-1]) {
+			score += 2
+		}
+		previous = i
+		matched++
+	}
+	if matched < len(needle) {
+		return 0, false
+	}
+	// 同等匹配时较短的文本更接近
+	return score*1000 - len(haystack), true
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"report.txt", "report.txt"},
+		{"报告 2026.docx", "报告 2026.docx"},
+		{"line\nbreak", `line\x0abreak`},
+		{"bell\x07\x1b[31mred", `bell\x07\x1b[31mred`},
+		{"bad\xffbyte", `bad\xffbyte`},
+		{"evil\u202etxt.exe", `evil\u202etxt.exe`},
+		{"tab\there", `tab\x09here`},
+	}
+	for _, tt := range tests {
+		if got := SanitizeName(tt.name); got != tt.want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"os"
+	"runtime"
+)
+
+// FileManagerCommand 返回在系统文件管理器中打开target的命令：
+// Windows使用explorer，macOS使用open，其他系统使用xdg-open
+func FileManagerCommand(goos, target string) (string, []string) {
+	switch goos {
+	case "windows":
+		return "explorer", []string{target}
+	case "darwin":
+		return "open", []string{target}
+	default:
+		return "xdg-open", []string{target}
+	}
+}
+
+// GUIAvailable 判断当前会话能否启动图形界面程序
+// Linux等系统需要设置DISPLAY或WAYLAND_DISPLAY，通过SSH登录时通常两者都没有
+func GUIAvailable() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return true
+	}
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
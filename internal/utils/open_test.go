@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFileManagerCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		target   string
+		wantName string
+	}{
+		{"windows", `C:\Users\alice\.delguard\trash`, "explorer"},
+		{"windows", "shell:RecycleBinFolder", "explorer"},
+		{"darwin", "/Users/alice/.Trash", "open"},
+		{"linux", "/home/alice/.local/share/Trash", "xdg-open"},
+		{"freebsd", "/home/alice/.local/share/Trash", "xdg-open"},
+	}
+	for _, tt := range tests {
+		name, args := FileManagerCommand(tt.goos, tt.target)
+		if name != tt.wantName || !reflect.DeepEqual(args, []string{tt.target}) {
+			t.Errorf("FileManagerCommand(%s, %q) = %s %q, want %s [%q]", tt.goos, tt.target, name, args, tt.wantName, tt.target)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// NormalizePathArg 规范化从浏览器、文件管理器或其他shell粘贴的路径参数
+// 依次去除包围的引号、解码file:// URL、在Unix上展开~和~user、去除末尾多余的路径分隔符（根目录除外）
+// 返回规范化后的路径和每项处理的说明，调用方负责对结果做安全校验
+func NormalizePathArg(arg string) (string, []string, error) {
+	path := arg
+	var notes []string
+
+	if len(path) >= 2 && (path[0] == '"' || path[0] == '\'') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+		notes = append(notes, "去除包围的引号")
+	}
+
+	if len(path) >= 7 && strings.EqualFold(path[:7], "file://") {
+		decoded, err := decodeFileURL(path)
+		if err != nil {
+			return arg, notes, err
+		}
+		path = decoded
+		notes = append(notes, fmt.Sprintf("解码file:// URL为 %s", path))
+	}
+
+	if runtime.GOOS != "windows" && strings.HasPrefix(path, "~") {
+		expanded, err := expandTilde(path)
+		if err != nil {
+			return arg, notes, err
+		}
+		path = expanded
+		notes = append(notes, fmt.Sprintf("展开为 %s", path))
+	}
+
+	if trimmed := trimTrailingSeparators(path); trimmed != path {
+		path = trimmed
+		notes = append(notes, "去除末尾的路径分隔符")
+	}
+
+	return path, notes, nil
+}
+
+// decodeFileURL 将file:// URL解码为本地路径，只接受本机（空主机名或localhost），Windows上其他主机转换为UNC路径
+func decodeFileURL(raw string) (string, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("无法解析URL %s: %v", raw, err)
+	}
+
+	// url.Parse已对路径做了百分号解码
+	path := parsed.Path
+	host := parsed.Host
+	if host != "" && !strings.EqualFold(host, "localhost") {
+		if runtime.GOOS != "windows" {
+			return "", fmt.Errorf("不支持其他主机上的文件: %s", raw)
+		}
+		return `\\` + host + filepath.FromSlash(path), nil
+	}
+
+	// file:///C:/dir 的路径部分为 /C:/dir
+	if runtime.GOOS == "windows" && len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	if path == "" {
+		return "", fmt.Errorf("URL中没有路径: %s", raw)
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// expandTilde 展开路径开头的~或~user
+func expandTilde(path string) (string, error) {
+	name, rest := path[1:], ""
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+
+	var homeDir string
+	if name == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("无法获取用户主目录: %v", err)
+		}
+		homeDir = dir
+	} else {
+		account, err := user.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("无法展开 ~%s: %v", name, err)
+		}
+		homeDir = account.HomeDir
+	}
+	return homeDir + rest, nil
+}
+
+// trimTrailingSeparators 去除末尾的路径分隔符，保留根目录（/ 或 C:\）
+func trimTrailingSeparators(path string) string {
+	minLen := len(filepath.VolumeName(path)) + 1
+	for len(path) > minLen && os.IsPathSeparator(path[len(path)-1]) {
+		path = path[:len(path)-1]
+	}
+	return path
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRenamePattern 默认的冲突改名模式，生成 name_1.ext、name_2.ext……
+const DefaultRenamePattern = "{name}_{n}{ext}"
+
+// renameTimestampLayout {timestamp} 占位符使用的时间格式
+const renameTimestampLayout = "20060102-150405"
+
+// renamePlaceholders 改名模式支持的占位符
+var renamePlaceholders = []string{"{name}", "{ext}", "{n}", "{timestamp}"}
+
+// ValidateRenamePattern 检查冲突改名模式：必须包含{name}，只能使用已知的占位符，
+// 不能包含路径分隔符，且生成的名称不能与原名相同
+func ValidateRenamePattern(pattern string) error {
+	if !strings.Contains(pattern, "{name}") {
+		return fmt.Errorf("改名模式 %q 必须包含 {name}", pattern)
+	}
+	rest := pattern
+	for _, placeholder := range renamePlaceholders {
+		rest = strings.ReplaceAll(rest, placeholder, "")
+	}
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("改名模式 %q 包含未知的占位符，可用的占位符: %s", pattern, strings.Join(renamePlaceholders, " "))
+	}
+	if strings.ContainsAny(rest, `/\`) {
+		return fmt.Errorf("改名模式 %q 不能包含路径分隔符", pattern)
+	}
+	if ExpandRenamePattern(pattern, "file", ".txt", 1, time.Now()) == "file.txt" {
+		return fmt.Errorf("改名模式 %q 生成的名称与原名相同", pattern)
+	}
+	return nil
+}
+
+// ExpandRenamePattern 按模式生成第n个候选名称，name为不含扩展名的文件名，ext包含点号
+// 模式中没有{n}时第一个候选不带序号，之后在扩展名前加 _n，保证重试时名称不同
+func ExpandRenamePattern(pattern, name, ext string, n int, now time.Time) string {
+	if n > 1 && !strings.Contains(pattern, "{n}") {
+		if i := strings.LastIndex(pattern, "{ext}"); i >= 0 {
+			pattern = pattern[:i] + "_{n}" + pattern[i:]
+		} else {
+			pattern += "_{n}"
+		}
+	}
+	replacer := strings.NewReplacer(
+		"{name}", name,
+		"{ext}", ext,
+		"{n}", strconv.Itoa(n),
+		"{timestamp}", now.Format(renameTimestampLayout),
+	)
+	return replacer.Replace(pattern)
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+)
+
+// CommandRunner 执行外部命令的接口，测试时可替换为不真正执行命令的实现
+type CommandRunner interface {
+	// Run 执行命令并返回标准输出和标准错误的合并内容
+	Run(name string, args ...string) ([]byte, error)
+}
+
+// ExecRunner 基于os/exec的默认实现，在Windows上不显示控制台窗口
+type ExecRunner struct{}
+
+// Run 执行命令并返回合并的输出
+func (ExecRunner) Run(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	hideWindow(cmd)
+	return cmd.CombinedOutput()
+}
+
+// LaunchRunner 启动图形界面程序的命令执行器，输出直接写到终端而不经过管道，
+// 避免被启动的程序继承管道后一直等到它退出
+type LaunchRunner struct{}
+
+// Run 执行命令并等待其退出，不返回输出
+func (LaunchRunner) Run(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	hideWindow(cmd)
+	return nil, cmd.Run()
+}
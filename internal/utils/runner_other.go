@@ -0,0 +1,8 @@
+//go:build !windows
+
+package utils
+
+import "os/exec"
+
+// hideWindow 非Windows平台没有控制台窗口需要隐藏
+func hideWindow(cmd *exec.Cmd) {}
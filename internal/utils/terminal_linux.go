@@ -0,0 +1,9 @@
+package utils
+
+import "golang.org/x/sys/unix"
+
+// ioctlReadTermios 读取终端属性的ioctl请求
+const ioctlReadTermios = unix.TCGETS
+
+// ioctlWriteTermios 设置终端属性的ioctl请求
+const ioctlWriteTermios = unix.TCSETS
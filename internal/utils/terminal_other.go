@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+
+package utils
+
+import "fmt"
+
+// IsTerminal 其他平台无法可靠检测，视为非终端
+func IsTerminal(fd uintptr) bool {
+	return false
+}
+
+// MakeRaw 当前平台不支持切换终端模式
+func MakeRaw(fd uintptr) (func() error, error) {
+	return nil, fmt.Errorf("当前平台不支持交互式终端")
+}
+
+// EnableANSI 当前平台不支持切换终端模式
+func EnableANSI(fd uintptr) (func() error, error) {
+	return nil, fmt.Errorf("当前平台不支持交互式终端")
+}
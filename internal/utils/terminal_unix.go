@@ -0,0 +1,39 @@
+//go:build linux || darwin
+
+package utils
+
+import "golang.org/x/sys/unix"
+
+// IsTerminal 检查文件描述符是否连接到终端，/dev/null等字符设备不算终端
+func IsTerminal(fd uintptr) bool {
+	_, err := unix.IoctlGetTermios(int(fd), ioctlReadTermios)
+	return err == nil
+}
+
+// MakeRaw 将终端切换为逐字节读取、不回显的原始模式，返回恢复原有模式的函数
+// 输出处理保持开启，换行仍会自动回到行首
+func MakeRaw(fd uintptr) (func() error, error) {
+	old, err := unix.IoctlGetTermios(int(fd), ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *old
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(int(fd), ioctlWriteTermios, &raw); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return unix.IoctlSetTermios(int(fd), ioctlWriteTermios, old)
+	}, nil
+}
+
+// EnableANSI Unix终端原生支持ANSI转义序列
+func EnableANSI(fd uintptr) (func() error, error) {
+	return func() error { return nil }, nil
+}
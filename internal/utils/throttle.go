@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Throttle 基于令牌桶的I/O限速器，用于后台维护任务（清理、校验、导出等）
+// nil值表示不限速，所有方法都可以在nil上安全调用
+type Throttle struct {
+	mu        sync.Mutex
+	rate      float64 // 每秒允许的字节数
+	burst     float64 // 令牌桶容量
+	tokens    float64
+	last      time.Time
+	niceDelay time.Duration
+}
+
+// NewThrottle 创建限速器
+// bytesPerSecond为0表示不限制吞吐量，niceDelay为每处理完一个文件后的休眠时间
+// 两者都为0时返回nil
+func NewThrottle(bytesPerSecond int64, niceDelay time.Duration) *Throttle {
+	if bytesPerSecond <= 0 && niceDelay <= 0 {
+		return nil
+	}
+
+	t := &Throttle{niceDelay: niceDelay}
+	if bytesPerSecond > 0 {
+		t.rate = float64(bytesPerSecond)
+		// 令牌桶容量取1/10秒的流量，避免突发读取超出限制太多
+		t.burst = t.rate / 10
+		if t.burst < 32*1024 {
+			t.burst = 32 * 1024
+		}
+		t.tokens = t.burst
+		t.last = time.Now()
+	}
+	return t
+}
+
+// Wait 阻塞直到允许处理n个字节
+func (t *Throttle) Wait(n int) {
+	if t == nil || t.rate <= 0 || n <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	t.last = now
+
+	t.tokens -= float64(n)
+	if t.tokens < 0 {
+		// 欠下的令牌按速率折算成等待时间
+		wait := time.Duration(-t.tokens / t.rate * float64(time.Second))
+		time.Sleep(wait)
+		t.tokens = 0
+		t.last = time.Now()
+	}
+}
+
+// Pause 文件之间的礼让休眠，降低对系统的影响
+func (t *Throttle) Pause() {
+	if t == nil || t.niceDelay <= 0 {
+		return
+	}
+	time.Sleep(t.niceDelay)
+}
+
+// Reader 返回按限速读取的Reader，限速器为nil时直接返回原Reader
+func (t *Throttle) Reader(r io.Reader) io.Reader {
+	if t == nil || t.rate <= 0 {
+		return r
+	}
+	return &throttledReader{reader: r, throttle: t}
+}
+
+// throttledReader 限速读取器
+type throttledReader struct {
+	reader   io.Reader
+	throttle *Throttle
+}
+
+// Read 实现io.Reader接口，单次读取不超过令牌桶容量
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if max := int(r.throttle.burst); len(p) > max {
+		p = p[:max]
+	}
+	n, err := r.reader.Read(p)
+	r.throttle.Wait(n)
+	return n, err
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestThrottleReaderStaysNearCap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures throughput for about a second")
+	}
+	const rate = 8 * 1024 * 1024
+	payload := bytes.Repeat([]byte("delguard"), rate/8)
+
+	throttle := NewThrottle(rate, 0)
+	started := time.Now()
+	n, err := io.Copy(io.Discard, throttle.Reader(bytes.NewReader(payload)))
+	elapsed := time.Since(started)
+	if err != nil || n != int64(len(payload)) {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+
+	throughput := float64(n) / elapsed.Seconds()
+	if throughput < rate*0.8 || throughput > rate*1.2 {
+		t.Errorf("throughput %.0f B/s is not within 20%% of the %d B/s cap (took %v)", throughput, rate, elapsed)
+	}
+}
+
+func TestThrottleDisabled(t *testing.T) {
+	if throttle := NewThrottle(0, 0); throttle != nil {
+		t.Fatalf("NewThrottle(0, 0) = %+v, want nil", throttle)
+	}
+	var throttle *Throttle
+	reader := bytes.NewReader([]byte("x"))
+	if got := throttle.Reader(reader); got != reader {
+		t.Error("a nil throttle must return the reader unchanged")
+	}
+	throttle.Wait(1 << 30)
+	throttle.Pause()
+
+	nice := NewThrottle(0, 10*time.Millisecond)
+	if got := nice.Reader(reader); got != reader {
+		t.Error("a throttle without a rate must not wrap the reader")
+	}
+	started := time.Now()
+	nice.Pause()
+	if elapsed := time.Since(started); elapsed < 10*time.Millisecond {
+		t.Errorf("Pause returned after %v, want at least the nice delay", elapsed)
+	}
+}
@@ -7,7 +7,10 @@ import (
 
 	"delguard/cmd"
 	"delguard/internal/config"
+	"delguard/internal/errors"
+	"delguard/internal/i18n"
 	"delguard/internal/logger"
+	"delguard/internal/paths"
 )
 
 func main() {
@@ -16,6 +19,20 @@ func main() {
 		log.Printf("初始化配置失败: %v", err)
 	}
 
+	// 设置了XDG_STATE_HOME时将 ~/.delguard 中的状态数据一次性迁移到新目录
+	if dir, err := paths.MigrateLegacy(); err != nil {
+		log.Printf("迁移数据目录失败，继续使用 ~/.delguard: %v", err)
+	} else if dir != "" {
+		fmt.Fprintf(os.Stderr, "📦 已将 ~/.delguard 中的回执和状态数据迁移到 %s\n", dir)
+	}
+
+	// 命令行标志解析前先按环境变量和配置设置语言，解析后由--lang覆盖
+	if lang := os.Getenv("DELGUARD_LANGUAGE"); lang != "" {
+		i18n.SetLanguage(lang)
+	} else if cfg := config.Current(); cfg != nil {
+		i18n.SetLanguage(cfg.UI.Language)
+	}
+
 	// 初始化日志
 	defer func() {
 		if err := logger.Close(); err != nil {
@@ -23,8 +40,8 @@ func main() {
 		}
 	}()
 
-	if config.GlobalConfig != nil && config.GlobalConfig.Logging.File != "" {
-		cfg := config.GlobalConfig.Logging
+	if current := config.Current(); current != nil && current.Logging.File != "" {
+		cfg := current.Logging
 		if err := logger.Init(cfg.File, cfg.Level, cfg.MaxSize, cfg.MaxAge, cfg.Compress); err != nil {
 			log.Printf("初始化日志失败: %v", err)
 			// 回退到默认日志配置
@@ -47,13 +64,25 @@ func main() {
 		}
 		
 		if r := recover(); r != nil {
-			fmt.Fprintf(os.Stderr, "程序发生严重错误: %v\n", r)
+			if cmd.ErrorFormatJSON() {
+				err := errors.NewError(errors.ErrTypeUnknown, fmt.Sprintf("程序发生严重错误: %v", r), nil)
+				fmt.Fprintln(os.Stderr, errors.FormatErrorJSON(err, cmd.CurrentOperation(), cmd.DebugOutput()))
+			} else {
+				fmt.Fprintf(os.Stderr, "程序发生严重错误: %v\n", r)
+			}
 			os.Exit(1)
 		}
 	}()
 
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
-		os.Exit(1)
+		if cmd.ErrorFormatJSON() {
+			fmt.Fprintln(os.Stderr, errors.FormatErrorJSON(err, cmd.CurrentOperation(), cmd.DebugOutput()))
+			os.Exit(errors.ExitCode(err))
+		}
+		fmt.Fprintf(os.Stderr, "错误: %s\n", errors.FormatErrorForDisplay(err))
+		if stack := errors.StackOf(err); stack != "" && cmd.DebugOutput() {
+			fmt.Fprintf(os.Stderr, "调用栈:\n%s", stack)
+		}
+		os.Exit(errors.ExitCode(err))
 	}
 }
\ No newline at end of file